@@ -86,13 +86,37 @@ func (dst *ClusterList) ConvertFrom(srcRaw conversion.Hub) error {
 func (src *Machine) ConvertTo(dstRaw conversion.Hub) error {
 	dst := dstRaw.(*v1alpha4.Machine)
 
-	return Convert_v1alpha3_Machine_To_v1alpha4_Machine(src, dst, nil)
+	if err := Convert_v1alpha3_Machine_To_v1alpha4_Machine(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Manually restore data.
+	restored := &v1alpha4.Machine{}
+	if ok, err := utilconversion.UnmarshalData(src, restored); err != nil || !ok {
+		return err
+	}
+
+	restoreMachineSpec(&restored.Spec, &dst.Spec)
+	dst.Status.LastOperation = restored.Status.LastOperation
+	dst.Status.DeletionStep = restored.Status.DeletionStep
+	dst.Status.DeletionStepStartTime = restored.Status.DeletionStepStartTime
+
+	return nil
 }
 
 func (dst *Machine) ConvertFrom(srcRaw conversion.Hub) error {
 	src := srcRaw.(*v1alpha4.Machine)
 
-	return Convert_v1alpha4_Machine_To_v1alpha3_Machine(src, dst, nil)
+	if err := Convert_v1alpha4_Machine_To_v1alpha3_Machine(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Preserve Hub data on down-conversion except for metadata
+	if err := utilconversion.MarshalData(src, dst); err != nil {
+		return err
+	}
+
+	return nil
 }
 
 func (src *MachineList) ConvertTo(dstRaw conversion.Hub) error {
@@ -110,13 +134,36 @@ func (dst *MachineList) ConvertFrom(srcRaw conversion.Hub) error {
 func (src *MachineSet) ConvertTo(dstRaw conversion.Hub) error {
 	dst := dstRaw.(*v1alpha4.MachineSet)
 
-	return Convert_v1alpha3_MachineSet_To_v1alpha4_MachineSet(src, dst, nil)
+	if err := Convert_v1alpha3_MachineSet_To_v1alpha4_MachineSet(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Manually restore data.
+	restored := &v1alpha4.MachineSet{}
+	if ok, err := utilconversion.UnmarshalData(src, restored); err != nil || !ok {
+		return err
+	}
+
+	restoreMachineSpec(&restored.Spec.Template.Spec, &dst.Spec.Template.Spec)
+	dst.Status.Conditions = restored.Status.Conditions
+	dst.Status.StaleReplicas = restored.Status.StaleReplicas
+
+	return nil
 }
 
 func (dst *MachineSet) ConvertFrom(srcRaw conversion.Hub) error {
 	src := srcRaw.(*v1alpha4.MachineSet)
 
-	return Convert_v1alpha4_MachineSet_To_v1alpha3_MachineSet(src, dst, nil)
+	if err := Convert_v1alpha4_MachineSet_To_v1alpha3_MachineSet(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Preserve Hub data on down-conversion except for metadata
+	if err := utilconversion.MarshalData(src, dst); err != nil {
+		return err
+	}
+
+	return nil
 }
 
 func (src *MachineSetList) ConvertTo(dstRaw conversion.Hub) error {
@@ -154,6 +201,8 @@ func (src *MachineDeployment) ConvertTo(dstRaw conversion.Hub) error {
 		dst.Spec.Strategy.RollingUpdate.DeletePolicy = restored.Spec.Strategy.RollingUpdate.DeletePolicy
 	}
 
+	restoreMachineSpec(&restored.Spec.Template.Spec, &dst.Spec.Template.Spec)
+
 	dst.Status.Conditions = restored.Status.Conditions
 	return nil
 }
@@ -206,6 +255,9 @@ func (src *MachineHealthCheck) ConvertTo(dstRaw conversion.Hub) error {
 	if restored.Spec.UnhealthyRange != nil {
 		dst.Spec.UnhealthyRange = restored.Spec.UnhealthyRange
 	}
+	dst.Spec.RemediationRateLimit = restored.Spec.RemediationRateLimit
+	dst.Spec.TargetMachinePools = restored.Spec.TargetMachinePools
+	dst.Status.RemediationRateLimit = restored.Status.RemediationRateLimit
 
 	return nil
 }
@@ -254,6 +306,11 @@ func Convert_v1alpha4_MachineHealthCheckSpec_To_v1alpha3_MachineHealthCheckSpec(
 	return autoConvert_v1alpha4_MachineHealthCheckSpec_To_v1alpha3_MachineHealthCheckSpec(in, out, s)
 }
 
+func Convert_v1alpha4_MachineHealthCheckStatus_To_v1alpha3_MachineHealthCheckStatus(in *v1alpha4.MachineHealthCheckStatus, out *MachineHealthCheckStatus, s apiconversion.Scope) error {
+	// NOTE: custom conversion func is required because status.remediationRateLimit does not exist in v1alpha3
+	return autoConvert_v1alpha4_MachineHealthCheckStatus_To_v1alpha3_MachineHealthCheckStatus(in, out, s)
+}
+
 func Convert_v1alpha3_ClusterStatus_To_v1alpha4_ClusterStatus(in *ClusterStatus, out *v1alpha4.ClusterStatus, s apiconversion.Scope) error {
 	return autoConvert_v1alpha3_ClusterStatus_To_v1alpha4_ClusterStatus(in, out, s)
 }
@@ -261,3 +318,28 @@ func Convert_v1alpha3_ClusterStatus_To_v1alpha4_ClusterStatus(in *ClusterStatus,
 func Convert_v1alpha3_ObjectMeta_To_v1alpha4_ObjectMeta(in *ObjectMeta, out *v1alpha4.ObjectMeta, s apiconversion.Scope) error {
 	return autoConvert_v1alpha3_ObjectMeta_To_v1alpha4_ObjectMeta(in, out, s)
 }
+
+func Convert_v1alpha4_MachineSpec_To_v1alpha3_MachineSpec(in *v1alpha4.MachineSpec, out *MachineSpec, s apiconversion.Scope) error {
+	// NOTE: custom conversion func is required because spec.provisioningTimeout does not exist in v1alpha3
+	return autoConvert_v1alpha4_MachineSpec_To_v1alpha3_MachineSpec(in, out, s)
+}
+
+func Convert_v1alpha4_MachineSetStatus_To_v1alpha3_MachineSetStatus(in *v1alpha4.MachineSetStatus, out *MachineSetStatus, s apiconversion.Scope) error {
+	// NOTE: custom conversion func is required because status.conditions and status.staleReplicas do not exist in v1alpha3
+	return autoConvert_v1alpha4_MachineSetStatus_To_v1alpha3_MachineSetStatus(in, out, s)
+}
+
+func Convert_v1alpha4_MachineStatus_To_v1alpha3_MachineStatus(in *v1alpha4.MachineStatus, out *MachineStatus, s apiconversion.Scope) error {
+	// NOTE: custom conversion func is required because status.lastOperation, status.deletionStep and status.deletionStepStartTime do not exist in v1alpha3
+	return autoConvert_v1alpha4_MachineStatus_To_v1alpha3_MachineStatus(in, out, s)
+}
+
+// restoreMachineSpec restores the MachineSpec fields that do not exist in v1alpha3.
+func restoreMachineSpec(restored *v1alpha4.MachineSpec, dst *v1alpha4.MachineSpec) {
+	dst.NodeVolumeDetachTimeout = restored.NodeVolumeDetachTimeout
+	dst.NodeDeletionTimeout = restored.NodeDeletionTimeout
+	dst.DeletePolicy = restored.DeletePolicy
+	dst.ProvisioningTimeout = restored.ProvisioningTimeout
+	dst.AuxiliaryInfrastructure = restored.AuxiliaryInfrastructure
+	dst.ReadinessGates = restored.ReadinessGates
+}
@@ -103,6 +103,11 @@ type ControlPlaneTopology struct {
 type WorkersTopology struct {
 	// MachineDeployments is a list of machine deployments in the cluster.
 	MachineDeployments []MachineDeploymentTopology `json:"machineDeployments,omitempty"`
+
+	// MachinePools is a list of machine pools in the cluster.
+	// NOTE: It is required to enable the MachinePool feature gate flag to use machine pools in the topology.
+	// +optional
+	MachinePools []MachinePoolTopology `json:"machinePools,omitempty"`
 }
 
 // MachineDeploymentTopology specifies the different parameters for a set of worker nodes in the topology.
@@ -129,6 +134,30 @@ type MachineDeploymentTopology struct {
 	Replicas *int `json:"replicas,omitempty"`
 }
 
+// MachinePoolTopology specifies the different parameters for a pool of worker nodes in the topology.
+// This pool of nodes is managed by a MachinePool object whose lifecycle is managed by the Cluster controller.
+type MachinePoolTopology struct {
+	Metadata ObjectMeta `json:"metadata,omitempty"`
+
+	// Class is the name of the MachinePoolClass used to create the pool of worker nodes.
+	// This should match one of the machine pool classes defined in the ClusterClass object
+	// mentioned in the `Cluster.Spec.Class` field.
+	Class string `json:"class"`
+
+	// Name is the unique identifier for this MachinePoolTopology.
+	// The value is used with other unique identifiers to create a MachinePool's Name
+	// (e.g. cluster's name, etc). In case the name is greater than the allowed maximum length,
+	// the values are hashed together.
+	Name string `json:"name"`
+
+	// Replicas is the number of worker nodes belonging to this pool.
+	// If the value is nil, the MachinePool is created without the number of Replicas (defaulting to one)
+	// and it's assumed that an external entity (like cluster autoscaler) is responsible for the management
+	// of this value.
+	// +optional
+	Replicas *int `json:"replicas,omitempty"`
+}
+
 // ANCHOR_END: ClusterSpec
 
 // ANCHOR: ClusterNetwork
@@ -105,6 +105,17 @@ func (c *Cluster) validate(old *Cluster) error {
 		)
 	}
 
+	// The control plane endpoint must be provided by the user if it is not managed by the InfraCluster.
+	if c.GetAnnotations()[ManagedEndpointAnnotation] == "false" && !c.Spec.ControlPlaneEndpoint.IsValid() {
+		allErrs = append(
+			allErrs,
+			field.Required(
+				field.NewPath("spec", "controlPlaneEndpoint"),
+				fmt.Sprintf("must be set when the %s annotation is \"false\"", ManagedEndpointAnnotation),
+			),
+		)
+	}
+
 	// Validate the managed topology, if defined.
 	if c.Spec.Topology != nil {
 		if topologyErrs := c.validateTopology(old); len(topologyErrs) > 0 {
@@ -171,6 +182,31 @@ func (c *Cluster) validateTopology(old *Cluster) field.ErrorList {
 			}
 			names.Insert(md.Name)
 		}
+
+		// MachinePool names must be unique.
+		poolNames := sets.String{}
+		for _, mp := range c.Spec.Topology.Workers.MachinePools {
+			if poolNames.Has(mp.Name) {
+				allErrs = append(allErrs,
+					field.Invalid(
+						field.NewPath("spec", "topology", "workers", "machinePools"),
+						mp,
+						fmt.Sprintf("MachinePool names should be unique. MachinePool with name %q is defined more than once.", mp.Name),
+					),
+				)
+			}
+			poolNames.Insert(mp.Name)
+		}
+
+		// MachinePools can be used only if the MachinePool feature flag is enabled.
+		if len(c.Spec.Topology.Workers.MachinePools) > 0 && !feature.Gates.Enabled(feature.MachinePool) {
+			allErrs = append(allErrs,
+				field.Forbidden(
+					field.NewPath("spec", "topology", "workers", "machinePools"),
+					"can be set only if the MachinePool feature flag is enabled",
+				),
+			)
+		}
 	}
 
 	switch old {
@@ -243,6 +279,19 @@ func (c *Cluster) validateTopology(old *Cluster) field.ErrorList {
 				),
 			)
 		}
+
+		// Version could be increased by at most one minor version at a time, according to the Kubernetes version skew policy.
+		if inVersion.NE(semver.Version{}) && oldVersion.NE(semver.Version{}) &&
+			(inVersion.Major > oldVersion.Major || (inVersion.Major == oldVersion.Major && inVersion.Minor > oldVersion.Minor+1)) {
+			allErrs = append(
+				allErrs,
+				field.Invalid(
+					field.NewPath("spec", "topology", "version"),
+					c.Spec.Topology.Version,
+					fmt.Sprintf("cannot be increased from %s by more than one minor version at a time", old.Spec.Topology.Version),
+				),
+			)
+		}
 	}
 
 	return allErrs
@@ -150,6 +150,36 @@ func TestClusterValidation(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:      "fails if the control plane endpoint is externally managed but not set",
+			expectErr: true,
+			in: &Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "foo",
+					Annotations: map[string]string{
+						ManagedEndpointAnnotation: "false",
+					},
+				},
+			},
+		},
+		{
+			name:      "should succeed if the control plane endpoint is externally managed and set",
+			expectErr: false,
+			in: &Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "foo",
+					Annotations: map[string]string{
+						ManagedEndpointAnnotation: "false",
+					},
+				},
+				Spec: ClusterSpec{
+					ControlPlaneEndpoint: APIEndpoint{
+						Host: "example.com",
+						Port: 6443,
+					},
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -166,6 +196,41 @@ func TestClusterValidation(t *testing.T) {
 	}
 }
 
+func TestClusterTopologyMachinePoolsValidation(t *testing.T) {
+	// NOTE: ClusterTopology feature flag is disabled by default, thus preventing to set Cluster.Topologies.
+	// Enabling the feature flag temporarily for this test.
+	defer utilfeature.SetFeatureGateDuringTest(t, feature.Gates, feature.ClusterTopology, true)()
+
+	newCluster := func(names ...string) *Cluster {
+		c := &Cluster{
+			Spec: ClusterSpec{
+				Topology: &Topology{
+					Class:   "foo",
+					Version: "v1.19.1",
+					Workers: &WorkersTopology{},
+				},
+			},
+		}
+		for _, name := range names {
+			c.Spec.Topology.Workers.MachinePools = append(c.Spec.Topology.Workers.MachinePools, MachinePoolTopology{Class: "bb", Name: name})
+		}
+		return c
+	}
+
+	t.Run("should return error when the MachinePool feature flag is disabled", func(t *testing.T) {
+		g := NewWithT(t)
+		g.Expect(newCluster("aa").ValidateCreate()).NotTo(Succeed())
+	})
+
+	t.Run("with the MachinePool feature flag enabled", func(t *testing.T) {
+		defer utilfeature.SetFeatureGateDuringTest(t, feature.Gates, feature.MachinePool, true)()
+
+		g := NewWithT(t)
+		g.Expect(newCluster("aa", "bb").ValidateCreate()).To(Succeed())
+		g.Expect(newCluster("aa", "aa").ValidateCreate()).NotTo(Succeed())
+	})
+}
+
 func TestClusterTopologyValidation(t *testing.T) {
 	// NOTE: ClusterTopology feature flag is disabled by default, thus preventing to set Cluster.Topologies.
 	// Enabling the feature flag temporarily for this test.
@@ -312,6 +377,72 @@ func TestClusterTopologyValidation(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:      "should return error on update when Topology version is upgraded by more than one minor version",
+			expectErr: true,
+			old: &Cluster{
+				Spec: ClusterSpec{
+					InfrastructureRef: &corev1.ObjectReference{},
+					Topology: &Topology{
+						Class:   "foo",
+						Version: "v1.19.1",
+					},
+				},
+			},
+			in: &Cluster{
+				Spec: ClusterSpec{
+					InfrastructureRef: &corev1.ObjectReference{},
+					Topology: &Topology{
+						Class:   "foo",
+						Version: "v1.21.0",
+					},
+				},
+			},
+		},
+		{
+			name:      "should return error on update when Topology version is upgraded to a new major version",
+			expectErr: true,
+			old: &Cluster{
+				Spec: ClusterSpec{
+					InfrastructureRef: &corev1.ObjectReference{},
+					Topology: &Topology{
+						Class:   "foo",
+						Version: "v1.19.1",
+					},
+				},
+			},
+			in: &Cluster{
+				Spec: ClusterSpec{
+					InfrastructureRef: &corev1.ObjectReference{},
+					Topology: &Topology{
+						Class:   "foo",
+						Version: "v2.0.0",
+					},
+				},
+			},
+		},
+		{
+			name:      "should update when Topology version is upgraded by one minor version",
+			expectErr: false,
+			old: &Cluster{
+				Spec: ClusterSpec{
+					InfrastructureRef: &corev1.ObjectReference{},
+					Topology: &Topology{
+						Class:   "foo",
+						Version: "v1.19.1",
+					},
+				},
+			},
+			in: &Cluster{
+				Spec: ClusterSpec{
+					InfrastructureRef: &corev1.ObjectReference{},
+					Topology: &Topology{
+						Class:   "foo",
+						Version: "v1.20.3",
+					},
+				},
+			},
+		},
 		{
 			name:      "should update",
 			expectErr: false,
@@ -58,6 +58,14 @@ func (in *ClusterClass) Default() {
 			in.Spec.Workers.MachineDeployments[i].Template.Infrastructure.Ref.Namespace = in.Namespace
 		}
 	}
+	for i := range in.Spec.Workers.MachinePools {
+		if len(in.Spec.Workers.MachinePools[i].Template.Bootstrap.Ref.Namespace) == 0 {
+			in.Spec.Workers.MachinePools[i].Template.Bootstrap.Ref.Namespace = in.Namespace
+		}
+		if len(in.Spec.Workers.MachinePools[i].Template.Infrastructure.Ref.Namespace) == 0 {
+			in.Spec.Workers.MachinePools[i].Template.Infrastructure.Ref.Namespace = in.Namespace
+		}
+	}
 }
 
 // ValidateCreate implements webhook.Validator so a webhook will be registered for the type.
@@ -133,6 +141,37 @@ func (in *ClusterClass) validate(old *ClusterClass) error {
 		}
 	}
 
+	for _, class := range in.Spec.Workers.MachinePools {
+		if class.Template.Bootstrap.Ref != nil && class.Template.Bootstrap.Ref.Namespace != in.Namespace {
+			allErrs = append(allErrs,
+				field.Invalid(
+					field.NewPath("spec", "workers", "machinePools", "template", "bootstrap", "ref", "namespace"),
+					class.Template.Bootstrap.Ref.Namespace,
+					"must match metadata.namespace",
+				),
+			)
+		}
+		if class.Template.Infrastructure.Ref != nil && class.Template.Infrastructure.Ref.Namespace != in.Namespace {
+			allErrs = append(allErrs,
+				field.Invalid(
+					field.NewPath("spec", "workers", "machinePools", "template", "infrastructure", "ref", "namespace"),
+					class.Template.Infrastructure.Ref.Namespace,
+					"must match metadata.namespace",
+				),
+			)
+		}
+	}
+
+	// MachinePool classes can be used only if the MachinePool feature flag is enabled.
+	if len(in.Spec.Workers.MachinePools) > 0 && !feature.Gates.Enabled(feature.MachinePool) {
+		allErrs = append(allErrs,
+			field.Forbidden(
+				field.NewPath("spec", "workers", "machinePools"),
+				"can be set only if the MachinePool feature flag is enabled",
+			),
+		)
+	}
+
 	// Ensure MachineDeployment class are unique.
 	classNames := sets.String{}
 	for _, class := range in.Spec.Workers.MachineDeployments {
@@ -148,6 +187,21 @@ func (in *ClusterClass) validate(old *ClusterClass) error {
 		classNames.Insert(class.Class)
 	}
 
+	// Ensure MachinePool class are unique.
+	poolClassNames := sets.String{}
+	for _, class := range in.Spec.Workers.MachinePools {
+		if poolClassNames.Has(class.Class) {
+			allErrs = append(allErrs,
+				field.Invalid(
+					field.NewPath("spec", "workers", "machinePools"),
+					class,
+					fmt.Sprintf("MachinePool class should be unique. MachinePool with class %q is defined more than once.", class.Class),
+				),
+			)
+		}
+		poolClassNames.Insert(class.Class)
+	}
+
 	// in case of create, we are done.
 	if old == nil {
 		if len(allErrs) > 0 {
@@ -173,6 +227,19 @@ func (in *ClusterClass) validate(old *ClusterClass) error {
 		oldClassNames.Insert(oldClass.Class)
 	}
 
+	// Makes sure all the old MachinePool classes are still there (only MachinePool class addition are allowed).
+	for _, oldClass := range old.Spec.Workers.MachinePools {
+		if !poolClassNames.Has(oldClass.Class) {
+			allErrs = append(allErrs,
+				field.Invalid(
+					field.NewPath("spec", "workers", "machinePools"),
+					in.Spec.Workers.MachinePools,
+					fmt.Sprintf("The %q MachinePool class can't be removed.", oldClass.Class),
+				),
+			)
+		}
+	}
+
 	// Makes sure no additional changes were applied.
 	if !reflect.DeepEqual(in.Spec.Infrastructure, old.Spec.Infrastructure) {
 		allErrs = append(allErrs,
@@ -208,6 +275,20 @@ func (in *ClusterClass) validate(old *ClusterClass) error {
 		}
 	}
 
+	for _, class := range in.Spec.Workers.MachinePools {
+		for _, oldClass := range old.Spec.Workers.MachinePools {
+			if class.Class == oldClass.Class && !reflect.DeepEqual(class, oldClass) {
+				allErrs = append(allErrs,
+					field.Invalid(
+						field.NewPath("spec", "workers", "machinePools"),
+						class,
+						"cannot be changed.",
+					),
+				)
+			}
+		}
+	}
+
 	if len(allErrs) > 0 {
 		return apierrors.NewInvalid(GroupVersion.WithKind("ClusterClass").GroupKind(), in.Name, allErrs)
 	}
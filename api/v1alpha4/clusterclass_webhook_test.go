@@ -716,3 +716,110 @@ func TestClusterClassValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestClusterClassMachinePoolsValidation(t *testing.T) {
+	// NOTE: ClusterTopology feature flag is disabled by default, thus preventing to create or update ClusterClasses.
+	// Enabling the feature flag temporarily for this test.
+	defer utilfeature.SetFeatureGateDuringTest(t, feature.Gates, feature.ClusterTopology, true)()
+
+	ref := &corev1.ObjectReference{
+		APIVersion: "foo",
+		Kind:       "bar",
+		Name:       "baz",
+		Namespace:  "default",
+	}
+	newClusterClass := func(classes ...string) *ClusterClass {
+		in := &ClusterClass{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+			},
+			Spec: ClusterClassSpec{
+				Infrastructure: LocalObjectTemplate{Ref: ref},
+				ControlPlane:   LocalObjectTemplate{Ref: ref},
+			},
+		}
+		for _, class := range classes {
+			in.Spec.Workers.MachinePools = append(in.Spec.Workers.MachinePools, MachinePoolClass{
+				Class: class,
+				Template: MachinePoolClassTemplate{
+					Bootstrap:      LocalObjectTemplate{Ref: ref.DeepCopy()},
+					Infrastructure: LocalObjectTemplate{Ref: ref.DeepCopy()},
+				},
+			})
+		}
+		return in
+	}
+
+	t.Run("should return error when the MachinePool feature flag is disabled", func(t *testing.T) {
+		g := NewWithT(t)
+		g.Expect(newClusterClass("aa").ValidateCreate()).NotTo(Succeed())
+	})
+
+	defer utilfeature.SetFeatureGateDuringTest(t, feature.Gates, feature.MachinePool, true)()
+
+	tests := []struct {
+		name      string
+		in        *ClusterClass
+		old       *ClusterClass
+		expectErr bool
+	}{
+		{
+			name:      "create pass with unique MachinePool classes",
+			in:        newClusterClass("aa", "bb"),
+			expectErr: false,
+		},
+		{
+			name:      "create fail with duplicated MachinePool classes",
+			in:        newClusterClass("aa", "aa"),
+			expectErr: true,
+		},
+		{
+			name: "create fail if a MachinePool template reference is in another namespace",
+			in: func() *ClusterClass {
+				in := newClusterClass("aa")
+				in.Spec.Workers.MachinePools[0].Template.Infrastructure.Ref.Namespace = "other"
+				return in
+			}(),
+			expectErr: true,
+		},
+		{
+			name:      "update pass if a MachinePool class is added",
+			old:       newClusterClass("aa"),
+			in:        newClusterClass("aa", "bb"),
+			expectErr: false,
+		},
+		{
+			name:      "update fail if a MachinePool class is removed",
+			old:       newClusterClass("aa", "bb"),
+			in:        newClusterClass("aa"),
+			expectErr: true,
+		},
+		{
+			name: "update fail if a MachinePool class is changed",
+			old:  newClusterClass("aa"),
+			in: func() *ClusterClass {
+				in := newClusterClass("aa")
+				in.Spec.Workers.MachinePools[0].Template.Bootstrap.Ref.Name = "changed"
+				return in
+			}(),
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			var err error
+			if tt.old == nil {
+				err = tt.in.ValidateCreate()
+			} else {
+				err = tt.in.ValidateUpdate(tt.old)
+			}
+			if tt.expectErr {
+				g.Expect(err).To(HaveOccurred())
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
@@ -58,6 +58,35 @@ const (
 	// older MachineSets when Machines are deleted and add the new replicas to the latest MachineSet.
 	DisableMachineCreate = "cluster.x-k8s.io/disable-machine-create"
 
+	// MachineCreationBatchSizeAnnotation is the MachineSet annotation defining the maximum number of Machines created
+	// in a single batch when scaling up, overriding the default of the controller manager; "0" means no limit.
+	MachineCreationBatchSizeAnnotation = "cluster.x-k8s.io/machine-creation-batch-size"
+
+	// MachineCreationBatchIntervalAnnotation is the MachineSet annotation defining the minimum interval between
+	// two batches of Machine creations, e.g. "30s", overriding the default of the controller manager.
+	// The interval is measured from the creation of the newest Machine, and it is jittered by up to 50%.
+	MachineCreationBatchIntervalAnnotation = "cluster.x-k8s.io/machine-creation-batch-interval"
+
+	// PropagateLabelsAnnotation is the Cluster annotation listing, comma separated, the keys of the Cluster labels
+	// to be propagated to the MachineDeployments, the Machines and the Nodes of the Cluster and kept in sync;
+	// if a listed label is removed from the Cluster, it is removed from the other objects as well.
+	PropagateLabelsAnnotation = "cluster.x-k8s.io/propagate-labels"
+
+	// PropagateAnnotationsAnnotation is the Cluster annotation listing, comma separated, the keys of the Cluster
+	// annotations to be propagated to the MachineDeployments, the Machines and the Nodes of the Cluster and kept in sync;
+	// if a listed annotation is removed from the Cluster, it is removed from the other objects as well.
+	PropagateAnnotationsAnnotation = "cluster.x-k8s.io/propagate-annotations"
+
+	// PropagatedLabelsAnnotation is the annotation recording, comma separated, the keys of the Cluster labels
+	// propagated to a MachineDeployment or a Machine, so they are removed once no longer listed in the
+	// PropagateLabelsAnnotation of the Cluster.
+	PropagatedLabelsAnnotation = "cluster.x-k8s.io/propagated-labels"
+
+	// PropagatedAnnotationsAnnotation is the annotation recording, comma separated, the keys of the Cluster annotations
+	// propagated to a MachineDeployment or a Machine, so they are removed once no longer listed in the
+	// PropagateAnnotationsAnnotation of the Cluster.
+	PropagatedAnnotationsAnnotation = "cluster.x-k8s.io/propagated-annotations"
+
 	// WatchLabel is a label othat can be applied to any Cluster API object.
 	//
 	// Controllers which allow for selective reconciliation may check this label and proceed
@@ -79,12 +108,41 @@ const (
 	// MachineSkipRemediationAnnotation is the annotation used to mark the machines that should not be considered for remediation by MachineHealthCheck reconciler.
 	MachineSkipRemediationAnnotation = "cluster.x-k8s.io/skip-remediation"
 
+	// ExtendedResourcesAnnotation is the MachineDeployment and MachineSet annotation exposing the extended resources,
+	// e.g. nvidia.com/gpu, provided by each Machine, as reported in the status.capacity of the infrastructure machine template.
+	// The value is a comma separated list of name=quantity pairs, sorted by name, e.g. "nvidia.com/gpu=2".
+	ExtendedResourcesAnnotation = "cluster.x-k8s.io/extended-resources"
+
 	// ClusterSecretType defines the type of secret created by core components.
 	ClusterSecretType corev1.SecretType = "cluster.x-k8s.io/secret" //nolint:gosec
 
+	// BootstrapDataSecretValueKey is the key of the bootstrap data secret storing the bootstrap data.
+	BootstrapDataSecretValueKey = "value"
+
+	// BootstrapDataSecretPartsKey is the key of the bootstrap data secret storing, as a JSON list, the names
+	// of the secrets with the parts of the bootstrap data, in order, when the bootstrap data is split across
+	// multiple secrets. Each part is stored in the BootstrapDataSecretValueKey key of its secret.
+	BootstrapDataSecretPartsKey = "parts"
+
+	// BootstrapDataSecretFormatKey is the key of the bootstrap data secret storing the format of the bootstrap data,
+	// e.g. cloud-config or ignition. If not set, infrastructure providers should assume cloud-config.
+	BootstrapDataSecretFormatKey = "format"
+
 	// InterruptibleLabel is the label used to mark the nodes that run on interruptible instances.
 	InterruptibleLabel = "cluster.x-k8s.io/interruptible"
 
+	// NodeInstanceTypeLabel is the label set on nodes to report the instance type of the machine, as defined by the
+	// optional status.instanceType field of the InfraMachine.
+	NodeInstanceTypeLabel = "node.cluster.x-k8s.io/instance-type"
+
+	// NodeZoneLabel is the label set on nodes to report the zone of the machine, as defined by the
+	// optional status.zone field of the InfraMachine.
+	NodeZoneLabel = "node.cluster.x-k8s.io/zone"
+
+	// NodeImageAnnotation is the annotation set on nodes to report the image the machine has been created from,
+	// as defined by the optional status.image field of the InfraMachine.
+	NodeImageAnnotation = "node.cluster.x-k8s.io/image"
+
 	// ManagedByAnnotation is an annotation that can be applied to InfraCluster resources to signify that
 	// some external system is managing the cluster infrastructure.
 	//
@@ -92,6 +150,18 @@ const (
 	// An external controller must fulfill the contract of the InfraCluster resource.
 	// External infrastructure providers should ensure that the annotation, once set, cannot be removed.
 	ManagedByAnnotation = "cluster.x-k8s.io/managed-by"
+
+	// DeletionConfirmedAnnotation is an annotation that confirms the deletion of an object protected against
+	// accidental deletion, e.g. a KubeadmControlPlane with deletion protection enabled.
+	// clusterctl move sets it on the objects deleted from the source cluster.
+	DeletionConfirmedAnnotation = "cluster.x-k8s.io/deletion-confirmed"
+
+	// ManagedEndpointAnnotation is an annotation that can be applied to Cluster resources; when set to "false"
+	// it signifies that the Cluster.Spec.ControlPlaneEndpoint is provided by the user, e.g. when using an
+	// externally managed load balancer, and not by the InfraCluster.
+	//
+	// The Cluster controller will not read the control plane endpoint from the InfraCluster for Clusters with this annotation.
+	ManagedEndpointAnnotation = "cluster.x-k8s.io/managed-endpoint"
 )
 
 var (
@@ -89,6 +89,29 @@ const (
 	// NOTE: Having the control plane machine available is a pre-condition for joining additional control planes
 	// or workers nodes.
 	WaitingForControlPlaneAvailableReason = "WaitingForControlPlaneAvailable"
+
+	// APIVersionsUpToDateCondition reports whether the cluster and its descendants, i.e. MachineDeployments,
+	// MachineSets, MachinePools and Machines, have been applied using deprecated API versions, e.g. v1alpha3,
+	// which are going to be removed in a future release.
+	// NOTE: This condition does not contribute to the cluster's Ready condition.
+	APIVersionsUpToDateCondition ConditionType = "APIVersionsUpToDate"
+
+	// DeprecatedAPIVersionsInUseReason (Severity=Warning) documents a cluster or some of its descendants
+	// being applied using deprecated API versions.
+	DeprecatedAPIVersionsInUseReason = "DeprecatedAPIVersionsInUse"
+
+	// ControlPlaneEndpointReachableCondition reports whether the API server behind the cluster's control plane
+	// endpoint answers the periodic probes of the /healthz endpoint.
+	// NOTE: This condition does not contribute to the cluster's Ready condition.
+	ControlPlaneEndpointReachableCondition ConditionType = "ControlPlaneEndpointReachable"
+
+	// WaitingForControlPlaneEndpointReason (Severity=Info) documents a cluster waiting for the control plane
+	// endpoint to be set or to be probed for the first time.
+	WaitingForControlPlaneEndpointReason = "WaitingForControlPlaneEndpoint"
+
+	// ControlPlaneEndpointUnreachableReason (Severity=Warning) documents a cluster whose control plane endpoint
+	// can't be reached; the severity is Info while the control plane is not yet initialized.
+	ControlPlaneEndpointUnreachableReason = "ControlPlaneEndpointUnreachable"
 )
 
 // Conditions and condition Reasons for the Machine object
@@ -123,6 +146,21 @@ const (
 
 	// WaitingExternalHookReason (Severity=Info) provide evidence that we are waiting for an external hook to complete.
 	WaitingExternalHookReason = "WaitingExternalHook"
+
+	// AuxiliaryInfrastructureReadyCondition reports an aggregate of the readiness of the auxiliary infrastructure
+	// objects defined for this machine. The condition is not set on machines without auxiliary infrastructure.
+	AuxiliaryInfrastructureReadyCondition ConditionType = "AuxiliaryInfrastructureReady"
+
+	// WaitingForAuxiliaryInfrastructureReason (Severity=Info) documents a machine waiting for one or more auxiliary
+	// infrastructure objects to be created or to become ready.
+	WaitingForAuxiliaryInfrastructureReason = "WaitingForAuxiliaryInfrastructure"
+
+	// ClusterFoundCondition reports whether the Cluster the machine belongs to exists. The condition is set to False
+	// when the Cluster has been deleted before its machines, e.g. after removing the Cluster finalizer manually.
+	ClusterFoundCondition ConditionType = "ClusterFound"
+
+	// ClusterNotFoundReason (Severity=Error) documents a machine orphaned by the deletion of its Cluster.
+	ClusterNotFoundReason = "ClusterNotFound"
 )
 
 const (
@@ -200,6 +238,22 @@ const (
 	// TooManyUnhealthyReason is the reason used when too many Machines are unhealthy and the MachineHealthCheck is blocked
 	// from making any further remediations.
 	TooManyUnhealthyReason = "TooManyUnhealthy"
+
+	// RemediationRateLimitedReason is the reason used when the MachineHealthCheck has reached the maximum number of
+	// remediations allowed in the current remediation rate limit window, and further remediations are deferred.
+	RemediationRateLimitedReason = "RemediationRateLimited"
+
+	// RemediationPausedDuringRolloutReason is the reason used when the remediation of some unhealthy Machines is paused
+	// because the MachineDeployment or the control plane owning them is in the middle of a rollout.
+	RemediationPausedDuringRolloutReason = "RemediationPausedDuringRollout"
+
+	// RemediationCircuitBreakerClosedCondition is set on MachineHealthChecks with a remediation rate limit to show whether
+	// remediation is paused because the rate limit has been reached in too many consecutive windows (circuit breaker open).
+	RemediationCircuitBreakerClosedCondition ConditionType = "RemediationCircuitBreakerClosed"
+
+	// CircuitBreakerOpenReason (Severity=Warning) is the reason used when the remediation circuit breaker of a
+	// MachineHealthCheck is open and all remediations are paused until the circuit breaker cooldown expires.
+	CircuitBreakerOpenReason = "CircuitBreakerOpen"
 )
 
 // Conditions and condition Reasons for  MachineDeployments
@@ -212,3 +266,24 @@ const (
 	// WaitingForAvailableMachinesReason (Severity=Warning) reflects the fact that the required minimum number of machines for a machinedeployment are not available.
 	WaitingForAvailableMachinesReason = "WaitingForAvailableMachines"
 )
+
+// Conditions and condition Reasons for MachineSets
+
+const (
+	// MachinesCreatedCondition documents that the machines controlled by the MachineSet are created.
+	// When this condition is false, it indicates that there was an error when cloning the infrastructure/bootstrap template or
+	// when generating the machine object.
+	MachinesCreatedCondition ConditionType = "MachinesCreated"
+
+	// BootstrapTemplateCloningFailedReason (Severity=Error) documents a MachineSet failing to
+	// clone the bootstrap template, e.g. because the generated object was rejected by a webhook.
+	BootstrapTemplateCloningFailedReason = "BootstrapTemplateCloningFailed"
+
+	// InfrastructureTemplateCloningFailedReason (Severity=Error) documents a MachineSet failing to
+	// clone the infrastructure template, e.g. because the generated object was rejected by a webhook.
+	InfrastructureTemplateCloningFailedReason = "InfrastructureTemplateCloningFailed"
+
+	// MachineCreationFailedReason (Severity=Error) documents a MachineSet failing to
+	// generate a machine object.
+	MachineCreationFailedReason = "MachineCreationFailed"
+)
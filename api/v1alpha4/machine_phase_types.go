@@ -62,3 +62,26 @@ const (
 	// MachinePhaseUnknown is returned if the Machine state cannot be determined.
 	MachinePhaseUnknown = MachinePhase("Unknown")
 )
+
+// MachineDeletionStep is a string representation of the step of the deletion workflow a Machine
+// in the Deleting phase is going through.
+//
+// As for MachinePhase, the value should not be interpreted by any software component
+// as a reliable indication of the actual state of the Machine.
+type MachineDeletionStep string
+
+const (
+	// MachineDeletionStepDraining is the step when the Node of the Machine is being drained.
+	MachineDeletionStepDraining = MachineDeletionStep("Draining")
+
+	// MachineDeletionStepWaitingForVolumeDetach is the step when the Machine controller
+	// waits for all the volumes to be detached from the drained Node.
+	MachineDeletionStepWaitingForVolumeDetach = MachineDeletionStep("WaitingForVolumeDetach")
+
+	// MachineDeletionStepDeletingInfrastructure is the step when the infrastructure
+	// and the bootstrap objects of the Machine are being deleted.
+	MachineDeletionStepDeletingInfrastructure = MachineDeletionStep("DeletingInfrastructure")
+
+	// MachineDeletionStepDeletingNode is the step when the Node of the Machine is being deleted.
+	MachineDeletionStepDeletingNode = MachineDeletionStep("DeletingNode")
+)
@@ -38,6 +38,10 @@ const (
 	// MachineDeploymentLabelName is the label set on machines if they're controlled by MachineDeployment.
 	MachineDeploymentLabelName = "cluster.x-k8s.io/deployment-name"
 
+	// MachineNameLabelName is the label set on nodes identifying the name of the machine the node belongs to;
+	// names longer than 63 characters are hashed, given that they are not valid label values.
+	MachineNameLabelName = "cluster.x-k8s.io/machine-name"
+
 	// PreDrainDeleteHookAnnotationPrefix annotation specifies the prefix we
 	// search each annotation for during the pre-drain.delete lifecycle hook
 	// to pause reconciliation of deletion. These hooks will prevent removal of
@@ -95,10 +99,90 @@ type MachineSpec struct {
 	// NOTE: NodeDrainTimeout is different from `kubectl drain --timeout`
 	// +optional
 	NodeDrainTimeout *metav1.Duration `json:"nodeDrainTimeout,omitempty"`
+
+	// NodeVolumeDetachTimeout is the total amount of time that the controller will spend on waiting for all volumes
+	// to be detached from the node, once the node has been drained.
+	// If not set, the controller waits for the volumes to be detached for 10 minutes; 0 means that the controller
+	// waits for the volumes to be detached without any time limitations. Volumes used by DaemonSet and mirror pods,
+	// and volumes of unreachable nodes, are not waited for.
+	// +optional
+	NodeVolumeDetachTimeout *metav1.Duration `json:"nodeVolumeDetachTimeout,omitempty"`
+
+	// NodeDeletionTimeout is the total amount of time that the controller will spend on trying to delete the node,
+	// once the infrastructure of the Machine has been deleted. After this timeout the Machine is deleted anyway.
+	// If not set, the controller tries to delete the node for 10 seconds; 0 means that the controller
+	// retries deleting the node without any time limitations.
+	// +optional
+	NodeDeletionTimeout *metav1.Duration `json:"nodeDeletionTimeout,omitempty"`
+
+	// DeletePolicy defines what happens to the infrastructure of the Machine when the Machine is deleted.
+	// Delete, the default, destroys the infrastructure; Retain asks the infrastructure provider to stop and quarantine
+	// the infrastructure instead of destroying it, e.g. for allowing post-mortem analysis of failed nodes.
+	// Retain requires the infrastructure provider to support the spec.deletePolicy field of the
+	// infrastructure machine; other providers destroy the infrastructure.
+	// +kubebuilder:validation:Enum=Delete;Retain
+	// +optional
+	DeletePolicy MachineDeletePolicy `json:"deletePolicy,omitempty"`
+
+	// ProvisioningTimeout is the total amount of time a Machine is allowed to wait for its bootstrap
+	// and infrastructure to become ready. After this timeout the Machine transitions to the Failed phase,
+	// so it can be remediated by a MachineHealthCheck instead of hanging in Provisioning forever.
+	// The default value is 0, meaning that no timeout is enforced.
+	// +optional
+	ProvisioningTimeout *metav1.Duration `json:"provisioningTimeout,omitempty"`
+
+	// AuxiliaryInfrastructure is a list of auxiliary infrastructure objects, e.g. per-machine IP address claims
+	// or secondary network devices, that must be ready before the Machine is considered provisioned.
+	// +optional
+	AuxiliaryInfrastructure []AuxiliaryInfrastructure `json:"auxiliaryInfrastructure,omitempty"`
+
+	// ReadinessGates specifies additional conditions to include when evaluating Machine availability.
+	// A Machine is counted as available by MachineSets and MachineDeployments only when its Node is ready
+	// and all the conditions listed in ReadinessGates are true on the Machine; this allows external controllers,
+	// e.g. load balancer registration or security scanning, to gate the rollout of MachineDeployments.
+	// +optional
+	ReadinessGates []MachineReadinessGate `json:"readinessGates,omitempty"`
 }
 
 // ANCHOR_END: MachineSpec
 
+// MachineDeletePolicy defines what happens to the infrastructure of a Machine when the Machine is deleted.
+type MachineDeletePolicy string
+
+const (
+	// MachineDeletePolicyDelete destroys the infrastructure of the Machine.
+	MachineDeletePolicyDelete MachineDeletePolicy = "Delete"
+
+	// MachineDeletePolicyRetain retains the infrastructure of the Machine, which is stopped and quarantined
+	// by the infrastructure provider instead of being destroyed.
+	MachineDeletePolicyRetain MachineDeletePolicy = "Retain"
+)
+
+// AuxiliaryInfrastructure defines an auxiliary infrastructure object whose readiness gates the provisioning of a Machine.
+// The referenced object is expected to report its readiness using `status.ready`.
+type AuxiliaryInfrastructure struct {
+	// Name identifies the auxiliary infrastructure object within the Machine.
+	// It must be unique within the list of auxiliary infrastructure objects.
+	Name string `json:"name"`
+
+	// TemplateRef is a reference to a template the auxiliary infrastructure object is created from.
+	// The controller clones the template and sets Ref to the created object.
+	// +optional
+	TemplateRef *corev1.ObjectReference `json:"templateRef,omitempty"`
+
+	// Ref is a reference to the auxiliary infrastructure object.
+	// When TemplateRef is set, Ref is populated by the controller and the object is controlled by the Machine;
+	// otherwise the object is only read, so it can be shared with other Machines.
+	// +optional
+	Ref *corev1.ObjectReference `json:"ref,omitempty"`
+}
+
+// MachineReadinessGate contains the type of a Machine condition to be used as a readiness gate.
+type MachineReadinessGate struct {
+	// ConditionType refers to a condition in the Machine's condition list with matching type.
+	ConditionType ConditionType `json:"conditionType"`
+}
+
 // ANCHOR: MachineStatus
 
 // MachineStatus defines the observed state of Machine.
@@ -166,6 +250,15 @@ type MachineStatus struct {
 	// +optional
 	Phase string `json:"phase,omitempty"`
 
+	// DeletionStep is the step of the deletion workflow the Machine is going through while in the Deleting phase,
+	// i.e. Draining, WaitingForVolumeDetach, DeletingInfrastructure or DeletingNode.
+	// +optional
+	DeletionStep MachineDeletionStep `json:"deletionStep,omitempty"`
+
+	// DeletionStepStartTime is the time the current deletion step started.
+	// +optional
+	DeletionStepStartTime *metav1.Time `json:"deletionStepStartTime,omitempty"`
+
 	// BootstrapReady is the state of the bootstrap provider.
 	// +optional
 	BootstrapReady bool `json:"bootstrapReady"`
@@ -181,10 +274,42 @@ type MachineStatus struct {
 	// Conditions defines current service state of the Machine.
 	// +optional
 	Conditions Conditions `json:"conditions,omitempty"`
+
+	// LastOperation describes the last operation performed by the Machine controller,
+	// e.g. a phase transition, providing a lightweight history useful for debugging.
+	// +optional
+	LastOperation *MachineOperation `json:"lastOperation,omitempty"`
 }
 
 // ANCHOR_END: MachineStatus
 
+// MachineOperationResult is the result of an operation performed by the Machine controller.
+type MachineOperationResult string
+
+const (
+	// MachineOperationSucceeded is the result of an operation completed successfully.
+	MachineOperationSucceeded = MachineOperationResult("Succeeded")
+
+	// MachineOperationFailed is the result of an operation that failed.
+	MachineOperationFailed = MachineOperationResult("Failed")
+)
+
+// MachineOperation describes an operation performed by the Machine controller.
+type MachineOperation struct {
+	// Type of the operation, e.g. the phase the Machine transitioned to.
+	Type string `json:"type"`
+
+	// Timestamp is the time the operation has been performed.
+	Timestamp metav1.Time `json:"timestamp"`
+
+	// Result of the operation, Succeeded or Failed.
+	Result MachineOperationResult `json:"result"`
+
+	// Error is a message describing why the operation failed, if any.
+	// +optional
+	Error *string `json:"error,omitempty"`
+}
+
 // SetTypedPhase sets the Phase field to the string representation of MachinePhase.
 func (m *MachineStatus) SetTypedPhase(p MachinePhase) {
 	m.Phase = string(p)
@@ -18,10 +18,12 @@ package v1alpha4
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 
 	"sigs.k8s.io/cluster-api/util/version"
 
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/validation/field"
@@ -29,6 +31,10 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 )
 
+// providerIDRegex matches a ProviderID of the form <cloudProvider>://<optional>/<segments>/<provider id>.
+// NOTE: this must be kept in sync with the regex used by noderefutil.NewProviderID, which cannot be imported here.
+var providerIDRegex = regexp.MustCompile("^[^:]+://.*[^/]$")
+
 func (m *Machine) SetupWebhookWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(m).
@@ -56,6 +62,16 @@ func (m *Machine) Default() {
 		m.Spec.InfrastructureRef.Namespace = m.Namespace
 	}
 
+	for i := range m.Spec.AuxiliaryInfrastructure {
+		aux := &m.Spec.AuxiliaryInfrastructure[i]
+		if aux.TemplateRef != nil && len(aux.TemplateRef.Namespace) == 0 {
+			aux.TemplateRef.Namespace = m.Namespace
+		}
+		if aux.Ref != nil && len(aux.Ref.Namespace) == 0 {
+			aux.Ref.Namespace = m.Namespace
+		}
+	}
+
 	if m.Spec.Version != nil && !strings.HasPrefix(*m.Spec.Version, "v") {
 		normalizedVersion := "v" + *m.Spec.Version
 		m.Spec.Version = &normalizedVersion
@@ -122,14 +138,104 @@ func (m *Machine) validate(old *Machine) error {
 		)
 	}
 
+	if old != nil {
+		allErrs = append(allErrs, validateImmutableReference(old.Spec.Bootstrap.ConfigRef, m.Spec.Bootstrap.ConfigRef, field.NewPath("spec", "bootstrap", "configRef"))...)
+		allErrs = append(allErrs, validateImmutableReference(&old.Spec.InfrastructureRef, &m.Spec.InfrastructureRef, field.NewPath("spec", "infrastructureRef"))...)
+	}
+
 	if m.Spec.Version != nil {
 		if !version.KubeSemver.MatchString(*m.Spec.Version) {
 			allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "version"), *m.Spec.Version, "must be a valid semantic version"))
 		}
 	}
 
+	// Validate the ProviderID only when it is set or changed, so Machines with a malformed ProviderID set
+	// before this validation was introduced can still be updated.
+	if m.Spec.ProviderID != nil && (old == nil || old.Spec.ProviderID == nil || *old.Spec.ProviderID != *m.Spec.ProviderID) {
+		if !providerIDRegex.MatchString(strings.TrimSpace(*m.Spec.ProviderID)) {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "providerID"), *m.Spec.ProviderID, "must be of the form <cloudProvider>://<optional>/<segments>/<provider id>"))
+		}
+	}
+
+	if m.Spec.ProvisioningTimeout != nil && m.Spec.ProvisioningTimeout.Duration < 0 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "provisioningTimeout"), m.Spec.ProvisioningTimeout.Duration.String(), "must be greater than or equal to 0"))
+	}
+
+	allErrs = append(allErrs, validateAuxiliaryInfrastructure(m.Spec.AuxiliaryInfrastructure, m.Namespace, field.NewPath("spec", "auxiliaryInfrastructure"))...)
+	allErrs = append(allErrs, validateReadinessGates(m.Spec.ReadinessGates, field.NewPath("spec", "readinessGates"))...)
+
 	if len(allErrs) == 0 {
 		return nil
 	}
 	return apierrors.NewInvalid(GroupVersion.WithKind("Machine").GroupKind(), m.Name, allErrs)
 }
+
+// validateAuxiliaryInfrastructure validates a list of auxiliary infrastructure objects; namespaced references,
+// if any, must be in the given namespace.
+func validateAuxiliaryInfrastructure(auxiliaryInfrastructure []AuxiliaryInfrastructure, namespace string, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	names := map[string]bool{}
+	for i, aux := range auxiliaryInfrastructure {
+		idxPath := fldPath.Index(i)
+		if aux.Name == "" {
+			allErrs = append(allErrs, field.Required(idxPath.Child("name"), "must be set"))
+		} else if names[aux.Name] {
+			allErrs = append(allErrs, field.Duplicate(idxPath.Child("name"), aux.Name))
+		}
+		names[aux.Name] = true
+
+		if aux.TemplateRef == nil && aux.Ref == nil {
+			allErrs = append(allErrs, field.Required(idxPath, "one of templateRef or ref must be set"))
+		}
+		if aux.TemplateRef != nil && aux.TemplateRef.Namespace != "" && aux.TemplateRef.Namespace != namespace {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("templateRef", "namespace"), aux.TemplateRef.Namespace, "must match metadata.namespace"))
+		}
+		if aux.Ref != nil && aux.Ref.Namespace != "" && aux.Ref.Namespace != namespace {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("ref", "namespace"), aux.Ref.Namespace, "must match metadata.namespace"))
+		}
+	}
+	return allErrs
+}
+
+// validateImmutableReference checks that a reference to an object owned by the Machine is not changed, because
+// the Machine controller would otherwise lose track of the object it was reconciling.
+// NOTE: the API version can change, e.g. when a provider moves to a new API version.
+func validateImmutableReference(oldRef, newRef *corev1.ObjectReference, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	switch {
+	case oldRef == nil && newRef == nil:
+	case oldRef == nil:
+		allErrs = append(allErrs, field.Forbidden(fldPath, "field cannot be set after creation"))
+	case newRef == nil:
+		allErrs = append(allErrs, field.Forbidden(fldPath, "field cannot be removed"))
+	default:
+		if oldRef.GroupVersionKind().Group != newRef.GroupVersionKind().Group || oldRef.Kind != newRef.Kind {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("kind"), newRef.Kind, "field is immutable"))
+		}
+		if oldRef.Name != newRef.Name {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("name"), newRef.Name, "field is immutable"))
+		}
+		if oldRef.Namespace != newRef.Namespace {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("namespace"), newRef.Namespace, "field is immutable"))
+		}
+	}
+	return allErrs
+}
+
+// validateReadinessGates validates a list of readiness gates; condition types must be set and unique.
+func validateReadinessGates(readinessGates []MachineReadinessGate, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	conditionTypes := map[ConditionType]bool{}
+	for i, gate := range readinessGates {
+		idxPath := fldPath.Index(i).Child("conditionType")
+		if gate.ConditionType == "" {
+			allErrs = append(allErrs, field.Required(idxPath, "must be set"))
+			continue
+		}
+		if conditionTypes[gate.ConditionType] {
+			allErrs = append(allErrs, field.Duplicate(idxPath, gate.ConditionType))
+		}
+		conditionTypes[gate.ConditionType] = true
+	}
+	return allErrs
+}
@@ -18,6 +18,7 @@ package v1alpha4
 
 import (
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 	utildefaulting "sigs.k8s.io/cluster-api/util/defaulting"
@@ -193,6 +194,96 @@ func TestMachineClusterNameImmutable(t *testing.T) {
 	}
 }
 
+func TestMachineReferencesImmutable(t *testing.T) {
+	infraRef := corev1.ObjectReference{APIVersion: "infrastructure.cluster.x-k8s.io/v1alpha3", Kind: "FooMachine", Name: "foo", Namespace: "default"}
+	configRef := &corev1.ObjectReference{APIVersion: "bootstrap.cluster.x-k8s.io/v1alpha3", Kind: "FooConfig", Name: "foo", Namespace: "default"}
+
+	tests := []struct {
+		name         string
+		newInfraRef  corev1.ObjectReference
+		oldConfigRef *corev1.ObjectReference
+		newConfigRef *corev1.ObjectReference
+		expectErr    bool
+	}{
+		{
+			name:         "when the references have not changed",
+			newInfraRef:  infraRef,
+			oldConfigRef: configRef,
+			newConfigRef: configRef,
+			expectErr:    false,
+		},
+		{
+			name:         "when the API version of the references has changed",
+			newInfraRef:  corev1.ObjectReference{APIVersion: "infrastructure.cluster.x-k8s.io/v1alpha4", Kind: "FooMachine", Name: "foo", Namespace: "default"},
+			oldConfigRef: configRef,
+			newConfigRef: &corev1.ObjectReference{APIVersion: "bootstrap.cluster.x-k8s.io/v1alpha4", Kind: "FooConfig", Name: "foo", Namespace: "default"},
+			expectErr:    false,
+		},
+		{
+			name:         "when the name of the infrastructure reference has changed",
+			newInfraRef:  corev1.ObjectReference{APIVersion: "infrastructure.cluster.x-k8s.io/v1alpha3", Kind: "FooMachine", Name: "bar", Namespace: "default"},
+			oldConfigRef: configRef,
+			newConfigRef: configRef,
+			expectErr:    true,
+		},
+		{
+			name:         "when the kind of the bootstrap reference has changed",
+			newInfraRef:  infraRef,
+			oldConfigRef: configRef,
+			newConfigRef: &corev1.ObjectReference{APIVersion: "bootstrap.cluster.x-k8s.io/v1alpha3", Kind: "BarConfig", Name: "foo", Namespace: "default"},
+			expectErr:    true,
+		},
+		{
+			name:         "when the group of the bootstrap reference has changed",
+			newInfraRef:  infraRef,
+			oldConfigRef: configRef,
+			newConfigRef: &corev1.ObjectReference{APIVersion: "bootstrap.example.com/v1alpha3", Kind: "FooConfig", Name: "foo", Namespace: "default"},
+			expectErr:    true,
+		},
+		{
+			name:         "when the bootstrap reference has been removed",
+			newInfraRef:  infraRef,
+			oldConfigRef: configRef,
+			newConfigRef: nil,
+			expectErr:    true,
+		},
+		{
+			name:         "when the bootstrap reference has been added",
+			newInfraRef:  infraRef,
+			oldConfigRef: nil,
+			newConfigRef: configRef,
+			expectErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			newMachine := &Machine{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default"},
+				Spec: MachineSpec{
+					Bootstrap:         Bootstrap{ConfigRef: tt.newConfigRef, DataSecretName: pointer.StringPtr("data")},
+					InfrastructureRef: tt.newInfraRef,
+				},
+			}
+			oldMachine := &Machine{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default"},
+				Spec: MachineSpec{
+					Bootstrap:         Bootstrap{ConfigRef: tt.oldConfigRef, DataSecretName: pointer.StringPtr("data")},
+					InfrastructureRef: infraRef,
+				},
+			}
+
+			if tt.expectErr {
+				g.Expect(newMachine.ValidateUpdate(oldMachine)).NotTo(Succeed())
+			} else {
+				g.Expect(newMachine.ValidateUpdate(oldMachine)).To(Succeed())
+			}
+		})
+	}
+}
+
 func TestMachineVersionValidation(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -247,3 +338,240 @@ func TestMachineVersionValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestMachineProvisioningTimeoutValidation(t *testing.T) {
+	tests := []struct {
+		name      string
+		timeout   *metav1.Duration
+		expectErr bool
+	}{
+		{
+			name:      "should succeed when provisioningTimeout is not set",
+			timeout:   nil,
+			expectErr: false,
+		},
+		{
+			name:      "should succeed when provisioningTimeout is positive",
+			timeout:   &metav1.Duration{Duration: 10 * time.Minute},
+			expectErr: false,
+		},
+		{
+			name:      "should succeed when provisioningTimeout is zero",
+			timeout:   &metav1.Duration{},
+			expectErr: false,
+		},
+		{
+			name:      "should return error when provisioningTimeout is negative",
+			timeout:   &metav1.Duration{Duration: -1 * time.Minute},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			m := &Machine{
+				Spec: MachineSpec{
+					Bootstrap:           Bootstrap{ConfigRef: nil, DataSecretName: pointer.StringPtr("test")},
+					ProvisioningTimeout: tt.timeout,
+				},
+			}
+
+			if tt.expectErr {
+				g.Expect(m.ValidateCreate()).NotTo(Succeed())
+				g.Expect(m.ValidateUpdate(m)).NotTo(Succeed())
+			} else {
+				g.Expect(m.ValidateCreate()).To(Succeed())
+				g.Expect(m.ValidateUpdate(m)).To(Succeed())
+			}
+		})
+	}
+}
+
+func TestMachineAuxiliaryInfrastructureValidation(t *testing.T) {
+	ref := func(namespace string) *corev1.ObjectReference {
+		return &corev1.ObjectReference{APIVersion: "ipam.cluster.x-k8s.io/v1alpha4", Kind: "IPAddressClaim", Name: "claim", Namespace: namespace}
+	}
+
+	tests := []struct {
+		name      string
+		aux       []AuxiliaryInfrastructure
+		expectErr bool
+	}{
+		{
+			name:      "should succeed when auxiliaryInfrastructure is not set",
+			expectErr: false,
+		},
+		{
+			name: "should succeed with a templateRef and a ref",
+			aux: []AuxiliaryInfrastructure{
+				{Name: "nic0", TemplateRef: ref("default")},
+				{Name: "nic1", Ref: ref("default")},
+			},
+			expectErr: false,
+		},
+		{
+			name:      "should return error when name is not set",
+			aux:       []AuxiliaryInfrastructure{{Ref: ref("default")}},
+			expectErr: true,
+		},
+		{
+			name: "should return error when names are duplicated",
+			aux: []AuxiliaryInfrastructure{
+				{Name: "nic0", Ref: ref("default")},
+				{Name: "nic0", Ref: ref("default")},
+			},
+			expectErr: true,
+		},
+		{
+			name:      "should return error when neither templateRef nor ref are set",
+			aux:       []AuxiliaryInfrastructure{{Name: "nic0"}},
+			expectErr: true,
+		},
+		{
+			name:      "should return error when ref is in a different namespace",
+			aux:       []AuxiliaryInfrastructure{{Name: "nic0", Ref: ref("other")}},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			m := &Machine{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default"},
+				Spec: MachineSpec{
+					Bootstrap:               Bootstrap{ConfigRef: nil, DataSecretName: pointer.StringPtr("test")},
+					InfrastructureRef:       corev1.ObjectReference{Namespace: "default"},
+					AuxiliaryInfrastructure: tt.aux,
+				},
+			}
+
+			if tt.expectErr {
+				g.Expect(m.ValidateCreate()).NotTo(Succeed())
+				g.Expect(m.ValidateUpdate(m)).NotTo(Succeed())
+			} else {
+				g.Expect(m.ValidateCreate()).To(Succeed())
+				g.Expect(m.ValidateUpdate(m)).To(Succeed())
+			}
+		})
+	}
+}
+
+func TestMachineReadinessGatesValidation(t *testing.T) {
+	tests := []struct {
+		name           string
+		readinessGates []MachineReadinessGate
+		expectErr      bool
+	}{
+		{
+			name:      "should succeed when readinessGates is not set",
+			expectErr: false,
+		},
+		{
+			name:           "should succeed with unique condition types",
+			readinessGates: []MachineReadinessGate{{ConditionType: "LoadBalancerRegistered"}, {ConditionType: "Scanned"}},
+			expectErr:      false,
+		},
+		{
+			name:           "should return error when conditionType is not set",
+			readinessGates: []MachineReadinessGate{{}},
+			expectErr:      true,
+		},
+		{
+			name:           "should return error when condition types are duplicated",
+			readinessGates: []MachineReadinessGate{{ConditionType: "Scanned"}, {ConditionType: "Scanned"}},
+			expectErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			m := &Machine{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default"},
+				Spec: MachineSpec{
+					Bootstrap:         Bootstrap{ConfigRef: nil, DataSecretName: pointer.StringPtr("test")},
+					InfrastructureRef: corev1.ObjectReference{Namespace: "default"},
+					ReadinessGates:    tt.readinessGates,
+				},
+			}
+
+			if tt.expectErr {
+				g.Expect(m.ValidateCreate()).NotTo(Succeed())
+				g.Expect(m.ValidateUpdate(m)).NotTo(Succeed())
+			} else {
+				g.Expect(m.ValidateCreate()).To(Succeed())
+				g.Expect(m.ValidateUpdate(m)).To(Succeed())
+			}
+		})
+	}
+}
+
+func TestMachineProviderIDValidation(t *testing.T) {
+	tests := []struct {
+		name      string
+		old       *string
+		new       *string
+		expectErr bool
+	}{
+		{
+			name:      "should succeed when providerID is not set",
+			expectErr: false,
+		},
+		{
+			name:      "should succeed when providerID is valid",
+			new:       pointer.StringPtr("aws:///us-west-1/instance-id"),
+			expectErr: false,
+		},
+		{
+			name:      "should return error when providerID has no cloud provider",
+			new:       pointer.StringPtr("instance-id"),
+			expectErr: true,
+		},
+		{
+			name:      "should return error when providerID has no id",
+			new:       pointer.StringPtr("aws:///us-west-1/"),
+			expectErr: true,
+		},
+		{
+			name:      "should succeed on update when an invalid providerID is not changed",
+			old:       pointer.StringPtr("instance-id"),
+			new:       pointer.StringPtr("instance-id"),
+			expectErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			newMachine := func(providerID *string) *Machine {
+				return &Machine{
+					Spec: MachineSpec{
+						Bootstrap:  Bootstrap{ConfigRef: nil, DataSecretName: pointer.StringPtr("test")},
+						ProviderID: providerID,
+					},
+				}
+			}
+			oldMachine := newMachine(tt.old)
+			m := newMachine(tt.new)
+
+			if tt.expectErr {
+				g.Expect(m.ValidateUpdate(oldMachine)).NotTo(Succeed())
+			} else {
+				g.Expect(m.ValidateUpdate(oldMachine)).To(Succeed())
+			}
+			if tt.old == nil {
+				if tt.expectErr {
+					g.Expect(m.ValidateCreate()).NotTo(Succeed())
+				} else {
+					g.Expect(m.ValidateCreate()).To(Succeed())
+				}
+			}
+		})
+	}
+}
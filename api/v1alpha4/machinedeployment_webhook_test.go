@@ -186,6 +186,48 @@ func TestMachineDeploymentValidation(t *testing.T) {
 	}
 }
 
+func TestMachineDeploymentMinReadySecondsValidation(t *testing.T) {
+	tests := []struct {
+		name            string
+		minReadySeconds *int32
+		expectErr       bool
+	}{
+		{
+			name:            "should succeed when minReadySeconds is not set",
+			minReadySeconds: nil,
+			expectErr:       false,
+		},
+		{
+			name:            "should succeed when minReadySeconds is positive",
+			minReadySeconds: pointer.Int32Ptr(30),
+			expectErr:       false,
+		},
+		{
+			name:            "should return error when minReadySeconds is negative",
+			minReadySeconds: pointer.Int32Ptr(-1),
+			expectErr:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			md := &MachineDeployment{
+				Spec: MachineDeploymentSpec{
+					MinReadySeconds: tt.minReadySeconds,
+				},
+			}
+			if tt.expectErr {
+				g.Expect(md.ValidateCreate()).NotTo(Succeed())
+				g.Expect(md.ValidateUpdate(md)).NotTo(Succeed())
+			} else {
+				g.Expect(md.ValidateCreate()).To(Succeed())
+				g.Expect(md.ValidateUpdate(md)).To(Succeed())
+			}
+		})
+	}
+}
+
 func TestMachineDeploymentVersionValidation(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -69,10 +69,51 @@ type MachineHealthCheckSpec struct {
 	// a controller that lives outside of Cluster API.
 	// +optional
 	RemediationTemplate *corev1.ObjectReference `json:"remediationTemplate,omitempty"`
+
+	// RemediationRateLimit limits the number of remediations this MachineHealthCheck can trigger
+	// in a time window, and optionally pauses remediation when the limit is reached repeatedly,
+	// e.g. to prevent remediation storms during infrastructure incidents.
+	// +optional
+	RemediationRateLimit *RemediationRateLimit `json:"remediationRateLimit,omitempty"`
+
+	// TargetMachinePools enables the health check of the nodes of the MachinePools matching Selector;
+	// those nodes are then counted when evaluating MaxUnhealthy and UnhealthyRange.
+	// It requires the MachinePool feature gate.
+	// +optional
+	TargetMachinePools bool `json:"targetMachinePools,omitempty"`
 }
 
 // ANCHOR_END: MachineHealthCHeckSpec
 
+// ANCHOR: RemediationRateLimit
+
+// RemediationRateLimit defines a time window based limit on the remediations triggered by a MachineHealthCheck,
+// and the circuit breaker pausing remediation when the limit is reached in consecutive windows.
+type RemediationRateLimit struct {
+	// MaxRemediations is the maximum number of remediations that can be triggered within Window.
+	// +kubebuilder:validation:Minimum=1
+	MaxRemediations int32 `json:"maxRemediations"`
+
+	// Window is the time window MaxRemediations applies to.
+	// If not set, this value is defaulted to 1 hour.
+	// +optional
+	Window *metav1.Duration `json:"window,omitempty"`
+
+	// CircuitBreakerThreshold is the number of consecutive windows in which MaxRemediations has been reached
+	// after which the circuit breaker opens and all remediations are paused for CircuitBreakerCooldown.
+	// If not set, the circuit breaker is disabled.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	CircuitBreakerThreshold *int32 `json:"circuitBreakerThreshold,omitempty"`
+
+	// CircuitBreakerCooldown is the time remediations are paused for once the circuit breaker opens.
+	// If not set, this value is defaulted to Window.
+	// +optional
+	CircuitBreakerCooldown *metav1.Duration `json:"circuitBreakerCooldown,omitempty"`
+}
+
+// ANCHOR_END: RemediationRateLimit
+
 // ANCHOR: UnhealthyCondition
 
 // UnhealthyCondition represents a Node condition type and value with a timeout
@@ -117,6 +158,10 @@ type MachineHealthCheckStatus struct {
 	// +optional
 	Targets []string `json:"targets,omitempty"`
 
+	// RemediationRateLimit reports the state of the remediation rate limiter, if configured.
+	// +optional
+	RemediationRateLimit *RemediationRateLimitStatus `json:"remediationRateLimit,omitempty"`
+
 	// Conditions defines current service state of the MachineHealthCheck.
 	// +optional
 	Conditions Conditions `json:"conditions,omitempty"`
@@ -124,6 +169,21 @@ type MachineHealthCheckStatus struct {
 
 // ANCHOR_END: MachineHealthCheckStatus
 
+// RemediationRateLimitStatus defines the observed state of the remediation rate limiter of a MachineHealthCheck.
+type RemediationRateLimitStatus struct {
+	// RecentRemediations are the times of the remediations triggered within the current window.
+	// +optional
+	RecentRemediations []metav1.Time `json:"recentRemediations,omitempty"`
+
+	// ConsecutiveBreaches is the number of consecutive windows in which MaxRemediations has been reached.
+	// +optional
+	ConsecutiveBreaches int32 `json:"consecutiveBreaches,omitempty"`
+
+	// LastBreachTime is the last time a remediation has been deferred because MaxRemediations has been reached.
+	// +optional
+	LastBreachTime *metav1.Time `json:"lastBreachTime,omitempty"`
+}
+
 // +kubebuilder:object:root=true
 // +kubebuilder:resource:path=machinehealthchecks,shortName=mhc;mhcs,scope=Namespaced,categories=cluster-api
 // +kubebuilder:storageversion
@@ -39,6 +39,8 @@ var (
 	minNodeStartupTimeout = metav1.Duration{Duration: 30 * time.Second}
 	// We allow users to disable the nodeStartupTimeout by setting the duration to 0.
 	disabledNodeStartupTimeout = ZeroDuration
+	// DefaultRemediationRateLimitWindow is the default time window of the remediation rate limit.
+	DefaultRemediationRateLimitWindow = metav1.Duration{Duration: time.Hour}
 )
 
 // SetMinNodeStartupTimeout allows users to optionally set a custom timeout
@@ -81,6 +83,15 @@ func (m *MachineHealthCheck) Default() {
 	if m.Spec.RemediationTemplate != nil && len(m.Spec.RemediationTemplate.Namespace) == 0 {
 		m.Spec.RemediationTemplate.Namespace = m.Namespace
 	}
+
+	if m.Spec.RemediationRateLimit != nil {
+		if m.Spec.RemediationRateLimit.Window == nil {
+			m.Spec.RemediationRateLimit.Window = DefaultRemediationRateLimitWindow.DeepCopy()
+		}
+		if m.Spec.RemediationRateLimit.CircuitBreakerThreshold != nil && m.Spec.RemediationRateLimit.CircuitBreakerCooldown == nil {
+			m.Spec.RemediationRateLimit.CircuitBreakerCooldown = m.Spec.RemediationRateLimit.Window.DeepCopy()
+		}
+	}
 }
 
 // ValidateCreate implements webhook.Validator so a webhook will be registered for the type.
@@ -164,6 +175,33 @@ func (m *MachineHealthCheck) validate(old *MachineHealthCheck) error {
 		)
 	}
 
+	if rateLimit := m.Spec.RemediationRateLimit; rateLimit != nil {
+		if rateLimit.MaxRemediations < 1 {
+			allErrs = append(
+				allErrs,
+				field.Invalid(field.NewPath("spec", "remediationRateLimit", "maxRemediations"), rateLimit.MaxRemediations, "must be greater than 0"),
+			)
+		}
+		if rateLimit.Window != nil && rateLimit.Window.Duration <= 0 {
+			allErrs = append(
+				allErrs,
+				field.Invalid(field.NewPath("spec", "remediationRateLimit", "window"), rateLimit.Window.Duration.String(), "must be greater than 0"),
+			)
+		}
+		if rateLimit.CircuitBreakerThreshold != nil && *rateLimit.CircuitBreakerThreshold < 1 {
+			allErrs = append(
+				allErrs,
+				field.Invalid(field.NewPath("spec", "remediationRateLimit", "circuitBreakerThreshold"), *rateLimit.CircuitBreakerThreshold, "must be greater than 0"),
+			)
+		}
+		if rateLimit.CircuitBreakerCooldown != nil && rateLimit.CircuitBreakerCooldown.Duration <= 0 {
+			allErrs = append(
+				allErrs,
+				field.Invalid(field.NewPath("spec", "remediationRateLimit", "circuitBreakerCooldown"), rateLimit.CircuitBreakerCooldown.Duration.String(), "must be greater than 0"),
+			)
+		}
+	}
+
 	if len(allErrs) == 0 {
 		return nil
 	}
@@ -266,6 +266,100 @@ func TestMachineHealthCheckMaxUnhealthy(t *testing.T) {
 	}
 }
 
+func TestMachineHealthCheckRemediationRateLimitDefault(t *testing.T) {
+	g := NewWithT(t)
+	threshold := int32(3)
+	mhc := &MachineHealthCheck{
+		Spec: MachineHealthCheckSpec{
+			Selector: metav1.LabelSelector{
+				MatchLabels: map[string]string{"foo": "bar"},
+			},
+			RemediationRateLimit: &RemediationRateLimit{
+				MaxRemediations:         5,
+				CircuitBreakerThreshold: &threshold,
+			},
+		},
+	}
+	mhc.Default()
+
+	g.Expect(mhc.Spec.RemediationRateLimit.Window).ToNot(BeNil())
+	g.Expect(*mhc.Spec.RemediationRateLimit.Window).To(Equal(metav1.Duration{Duration: time.Hour}))
+	g.Expect(mhc.Spec.RemediationRateLimit.CircuitBreakerCooldown).ToNot(BeNil())
+	g.Expect(*mhc.Spec.RemediationRateLimit.CircuitBreakerCooldown).To(Equal(metav1.Duration{Duration: time.Hour}))
+}
+
+func TestMachineHealthCheckRemediationRateLimit(t *testing.T) {
+	zero := int32(0)
+	tests := []struct {
+		name      string
+		rateLimit *RemediationRateLimit
+		expectErr bool
+	}{
+		{
+			name: "when the rate limit is valid",
+			rateLimit: &RemediationRateLimit{
+				MaxRemediations: 3,
+				Window:          &metav1.Duration{Duration: 30 * time.Minute},
+			},
+			expectErr: false,
+		},
+		{
+			name: "when maxRemediations is 0",
+			rateLimit: &RemediationRateLimit{
+				MaxRemediations: 0,
+			},
+			expectErr: true,
+		},
+		{
+			name: "when the window is negative",
+			rateLimit: &RemediationRateLimit{
+				MaxRemediations: 3,
+				Window:          &metav1.Duration{Duration: -time.Minute},
+			},
+			expectErr: true,
+		},
+		{
+			name: "when the circuit breaker threshold is 0",
+			rateLimit: &RemediationRateLimit{
+				MaxRemediations:         3,
+				CircuitBreakerThreshold: &zero,
+			},
+			expectErr: true,
+		},
+		{
+			name: "when the circuit breaker cooldown is 0",
+			rateLimit: &RemediationRateLimit{
+				MaxRemediations:        3,
+				CircuitBreakerCooldown: &metav1.Duration{},
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		g := NewWithT(t)
+
+		mhc := &MachineHealthCheck{
+			Spec: MachineHealthCheckSpec{
+				RemediationRateLimit: tt.rateLimit,
+				Selector: metav1.LabelSelector{
+					MatchLabels: map[string]string{
+						"test": "test",
+					},
+				},
+			},
+		}
+
+		if tt.expectErr {
+			g.Expect(mhc.ValidateCreate()).NotTo(Succeed())
+			g.Expect(mhc.ValidateUpdate(mhc)).NotTo(Succeed())
+		} else {
+			g.Expect(mhc.ValidateCreate()).To(Succeed())
+			g.Expect(mhc.ValidateUpdate(mhc)).To(Succeed())
+		}
+	}
+}
+
 func TestMachineHealthCheckSelectorValidation(t *testing.T) {
 	g := NewWithT(t)
 	mhc := &MachineHealthCheck{}
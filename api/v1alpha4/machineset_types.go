@@ -24,6 +24,13 @@ import (
 	capierrors "sigs.k8s.io/cluster-api/errors"
 )
 
+const (
+	// MachineSetTemplateHashAnnotation is a Machine annotation that stores the hash of the machine template of the
+	// MachineSet the Machine has been created from; it is used to identify the machines created from a previous
+	// revision of the machine template.
+	MachineSetTemplateHashAnnotation = "machineset.cluster.x-k8s.io/template-hash"
+)
+
 // ANCHOR: MachineSetSpec
 
 // MachineSetSpec defines the desired state of MachineSet.
@@ -131,6 +138,11 @@ type MachineSetStatus struct {
 	// +optional
 	AvailableReplicas int32 `json:"availableReplicas,omitempty"`
 
+	// The number of replicas created from a previous revision of the machine template of this MachineSet.
+	// Machines created before the template hash was tracked, or adopted by the MachineSet, are not considered stale.
+	// +optional
+	StaleReplicas int32 `json:"staleReplicas,omitempty"`
+
 	// ObservedGeneration reflects the generation of the most recently observed MachineSet.
 	// +optional
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
@@ -157,6 +169,10 @@ type MachineSetStatus struct {
 	FailureReason *capierrors.MachineSetStatusError `json:"failureReason,omitempty"`
 	// +optional
 	FailureMessage *string `json:"failureMessage,omitempty"`
+
+	// Conditions defines current service state of the MachineSet.
+	// +optional
+	Conditions Conditions `json:"conditions,omitempty"`
 }
 
 // ANCHOR_END: MachineSetStatus
@@ -202,6 +218,16 @@ type MachineSet struct {
 	Status MachineSetStatus `json:"status,omitempty"`
 }
 
+// GetConditions returns the set of conditions for the MachineSet.
+func (m *MachineSet) GetConditions() Conditions {
+	return m.Status.Conditions
+}
+
+// SetConditions updates the set of conditions on the MachineSet.
+func (m *MachineSet) SetConditions(conditions Conditions) {
+	m.Status.Conditions = conditions
+}
+
 // +kubebuilder:object:root=true
 
 // MachineSetList contains a list of MachineSet.
@@ -111,6 +111,23 @@ func (m *MachineSet) validate(old *MachineSet) error {
 		)
 	}
 
+	if m.Spec.MinReadySeconds < 0 {
+		allErrs = append(
+			allErrs,
+			field.Invalid(field.NewPath("spec", "minReadySeconds"), m.Spec.MinReadySeconds, "must be greater than or equal to 0"),
+		)
+	}
+
+	if m.Spec.Template.Spec.ProvisioningTimeout != nil && m.Spec.Template.Spec.ProvisioningTimeout.Duration < 0 {
+		allErrs = append(
+			allErrs,
+			field.Invalid(field.NewPath("spec", "template", "spec", "provisioningTimeout"), m.Spec.Template.Spec.ProvisioningTimeout.Duration.String(), "must be greater than or equal to 0"),
+		)
+	}
+
+	allErrs = append(allErrs, validateAuxiliaryInfrastructure(m.Spec.Template.Spec.AuxiliaryInfrastructure, m.Namespace, field.NewPath("spec", "template", "spec", "auxiliaryInfrastructure"))...)
+	allErrs = append(allErrs, validateReadinessGates(m.Spec.Template.Spec.ReadinessGates, field.NewPath("spec", "template", "spec", "readinessGates"))...)
+
 	if len(allErrs) == 0 {
 		return nil
 	}
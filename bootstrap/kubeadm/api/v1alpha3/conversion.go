@@ -20,19 +20,48 @@ import (
 	apiconversion "k8s.io/apimachinery/pkg/conversion"
 	kubeadmbootstrapv1alpha4 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
 	kubeadmbootstrapv1beta1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/types/v1beta1"
+	utilconversion "sigs.k8s.io/cluster-api/util/conversion"
 	"sigs.k8s.io/controller-runtime/pkg/conversion"
 )
 
 // ConvertTo converts this KubeadmConfig to the Hub version (v1alpha4).
 func (src *KubeadmConfig) ConvertTo(dstRaw conversion.Hub) error {
 	dst := dstRaw.(*kubeadmbootstrapv1alpha4.KubeadmConfig)
-	return Convert_v1alpha3_KubeadmConfig_To_v1alpha4_KubeadmConfig(src, dst, nil)
+
+	if err := Convert_v1alpha3_KubeadmConfig_To_v1alpha4_KubeadmConfig(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Manually restore data.
+	restored := &kubeadmbootstrapv1alpha4.KubeadmConfig{}
+	if ok, err := utilconversion.UnmarshalData(src, restored); err != nil || !ok {
+		return err
+	}
+
+	dst.Spec.RotateKubeletServerCertificates = restored.Spec.RotateKubeletServerCertificates
+	dst.Spec.DataSecretMaxSize = restored.Spec.DataSecretMaxSize
+	dst.Spec.GPU = restored.Spec.GPU
+	dst.Spec.UploadCertificates = restored.Spec.UploadCertificates
+	dst.Spec.ImagePullSecrets = restored.Spec.ImagePullSecrets
+	dst.Spec.RenderFileTemplates = restored.Spec.RenderFileTemplates
+	dst.Spec.SecretDirectories = restored.Spec.SecretDirectories
+	RestoreFilesContentFrom(dst.Spec.Files, restored.Spec.Files)
+	dst.Status.BootstrapTokenExpiration = restored.Status.BootstrapTokenExpiration
+	dst.Status.BootstrapTokenRotations = restored.Status.BootstrapTokenRotations
+
+	return nil
 }
 
 // ConvertFrom converts from the KubeadmConfig Hub version (v1alpha4) to this version.
 func (dst *KubeadmConfig) ConvertFrom(srcRaw conversion.Hub) error {
 	src := srcRaw.(*kubeadmbootstrapv1alpha4.KubeadmConfig)
-	return Convert_v1alpha4_KubeadmConfig_To_v1alpha3_KubeadmConfig(src, dst, nil)
+
+	if err := Convert_v1alpha4_KubeadmConfig_To_v1alpha3_KubeadmConfig(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Preserve Hub data on down-conversion except for metadata
+	return utilconversion.MarshalData(src, dst)
 }
 
 // ConvertTo converts this KubeadmConfigList to the Hub version (v1alpha4).
@@ -50,13 +79,39 @@ func (dst *KubeadmConfigList) ConvertFrom(srcRaw conversion.Hub) error {
 // ConvertTo converts this KubeadmConfigTemplate to the Hub version (v1alpha4).
 func (src *KubeadmConfigTemplate) ConvertTo(dstRaw conversion.Hub) error {
 	dst := dstRaw.(*kubeadmbootstrapv1alpha4.KubeadmConfigTemplate)
-	return Convert_v1alpha3_KubeadmConfigTemplate_To_v1alpha4_KubeadmConfigTemplate(src, dst, nil)
+
+	if err := Convert_v1alpha3_KubeadmConfigTemplate_To_v1alpha4_KubeadmConfigTemplate(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Manually restore data.
+	restored := &kubeadmbootstrapv1alpha4.KubeadmConfigTemplate{}
+	if ok, err := utilconversion.UnmarshalData(src, restored); err != nil || !ok {
+		return err
+	}
+
+	dst.Spec.Template.Spec.RotateKubeletServerCertificates = restored.Spec.Template.Spec.RotateKubeletServerCertificates
+	dst.Spec.Template.Spec.DataSecretMaxSize = restored.Spec.Template.Spec.DataSecretMaxSize
+	dst.Spec.Template.Spec.GPU = restored.Spec.Template.Spec.GPU
+	dst.Spec.Template.Spec.UploadCertificates = restored.Spec.Template.Spec.UploadCertificates
+	dst.Spec.Template.Spec.ImagePullSecrets = restored.Spec.Template.Spec.ImagePullSecrets
+	dst.Spec.Template.Spec.RenderFileTemplates = restored.Spec.Template.Spec.RenderFileTemplates
+	dst.Spec.Template.Spec.SecretDirectories = restored.Spec.Template.Spec.SecretDirectories
+	RestoreFilesContentFrom(dst.Spec.Template.Spec.Files, restored.Spec.Template.Spec.Files)
+
+	return nil
 }
 
 // ConvertFrom converts from the KubeadmConfigTemplate Hub version (v1alpha4) to this version.
 func (dst *KubeadmConfigTemplate) ConvertFrom(srcRaw conversion.Hub) error {
 	src := srcRaw.(*kubeadmbootstrapv1alpha4.KubeadmConfigTemplate)
-	return Convert_v1alpha4_KubeadmConfigTemplate_To_v1alpha3_KubeadmConfigTemplate(src, dst, nil)
+
+	if err := Convert_v1alpha4_KubeadmConfigTemplate_To_v1alpha3_KubeadmConfigTemplate(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Preserve Hub data on down-conversion except for metadata
+	return utilconversion.MarshalData(src, dst)
 }
 
 // ConvertTo converts this KubeadmConfigTemplateList to the Hub version (v1alpha3).
@@ -77,6 +132,38 @@ func Convert_v1alpha3_KubeadmConfigStatus_To_v1alpha4_KubeadmConfigStatus(in *Ku
 	return autoConvert_v1alpha3_KubeadmConfigStatus_To_v1alpha4_KubeadmConfigStatus(in, out, s)
 }
 
+// Convert_v1alpha4_KubeadmConfigSpec_To_v1alpha3_KubeadmConfigSpec converts from the Hub version (v1alpha4) of the KubeadmConfigSpec to this version.
+func Convert_v1alpha4_KubeadmConfigSpec_To_v1alpha3_KubeadmConfigSpec(in *kubeadmbootstrapv1alpha4.KubeadmConfigSpec, out *KubeadmConfigSpec, s apiconversion.Scope) error { //nolint
+	// NOTE: RotateKubeletServerCertificates, DataSecretMaxSize, GPU, UploadCertificates, ImagePullSecrets, RenderFileTemplates and SecretDirectories do not exist in v1alpha3, the values are preserved through annotations.
+	return autoConvert_v1alpha4_KubeadmConfigSpec_To_v1alpha3_KubeadmConfigSpec(in, out, s)
+}
+
+// Convert_v1alpha4_KubeadmConfigStatus_To_v1alpha3_KubeadmConfigStatus converts from the Hub version (v1alpha4) of the KubeadmConfigStatus to this version.
+func Convert_v1alpha4_KubeadmConfigStatus_To_v1alpha3_KubeadmConfigStatus(in *kubeadmbootstrapv1alpha4.KubeadmConfigStatus, out *KubeadmConfigStatus, s apiconversion.Scope) error { //nolint
+	// NOTE: BootstrapTokenExpiration and BootstrapTokenRotations do not exist in v1alpha3, the values are preserved through annotations.
+	return autoConvert_v1alpha4_KubeadmConfigStatus_To_v1alpha3_KubeadmConfigStatus(in, out, s)
+}
+
+// Convert_v1alpha4_FileSource_To_v1alpha3_FileSource converts from the Hub version (v1alpha4) of the FileSource to this version.
+func Convert_v1alpha4_FileSource_To_v1alpha3_FileSource(in *kubeadmbootstrapv1alpha4.FileSource, out *FileSource, s apiconversion.Scope) error { //nolint
+	// NOTE: ConfigMap does not exist in v1alpha3, the value is preserved through annotations.
+	return autoConvert_v1alpha4_FileSource_To_v1alpha3_FileSource(in, out, s)
+}
+
+// RestoreFilesContentFrom restores the ConfigMap sources of the files, which do not exist in v1alpha3, from the files
+// preserved through annotations; files are matched by index and path.
+func RestoreFilesContentFrom(files, restored []kubeadmbootstrapv1alpha4.File) {
+	for i := range files {
+		if i >= len(restored) || files[i].Path != restored[i].Path {
+			continue
+		}
+		if files[i].ContentFrom == nil || restored[i].ContentFrom == nil || restored[i].ContentFrom.ConfigMap == nil {
+			continue
+		}
+		files[i].ContentFrom.ConfigMap = restored[i].ContentFrom.ConfigMap.DeepCopy()
+	}
+}
+
 func Convert_v1alpha4_ClusterConfiguration_To_v1beta1_ClusterConfiguration(in *kubeadmbootstrapv1alpha4.ClusterConfiguration, out *kubeadmbootstrapv1beta1.ClusterConfiguration, s apiconversion.Scope) error {
 	// DNS.Type was removed in v1alpha4 because only CoreDNS is supported; the information will be left to empty (kubeadm defaults it to CoredDNS);
 	// Existing clusters using kube-dns or other DNS solutions will continue to be managed/supported via the skip-coredns annotation.
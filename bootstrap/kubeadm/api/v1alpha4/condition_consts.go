@@ -59,3 +59,16 @@ const (
 	// an error while while retrieving certificates for a joining node.
 	CertificatesCorruptedReason = "CertificatesCorrupted"
 )
+
+const (
+	// FilesAvailableCondition documents that the Secrets referenced by the files of the KubeadmConfig,
+	// i.e. files with contentFrom, secret directories and image pull secrets, exist.
+	//
+	// NOTE: The condition is set only for KubeadmConfigs referencing Secrets, and it is checked only before
+	// generating the bootstrap data.
+	FilesAvailableCondition clusterv1.ConditionType = "FilesAvailable"
+
+	// MissingFileReferencesReason (Severity=Warning) documents a KubeadmConfig waiting for one or more
+	// Secrets, or Secret keys, referenced by its files to be created.
+	MissingFileReferencesReason = "MissingFileReferences"
+)
@@ -22,12 +22,35 @@ import (
 )
 
 // Format specifies the output format of the bootstrap data
-// +kubebuilder:validation:Enum=cloud-config
+// +kubebuilder:validation:Enum=cloud-config;ignition
 type Format string
 
 const (
 	// CloudConfig make the bootstrap data to be of cloud-config format.
 	CloudConfig Format = "cloud-config"
+
+	// Ignition make the bootstrap data to be of Ignition format, e.g. for Flatcar Container Linux.
+	Ignition Format = "ignition"
+)
+
+const (
+	// SkipKubeProxyAnnotation annotation skips the installation of the kube-proxy addon by kubeadm init if set.
+	SkipKubeProxyAnnotation = "controlplane.cluster.x-k8s.io/skip-kube-proxy"
+
+	// SkipCoreDNSAnnotation annotation skips the installation of the CoreDNS addon by kubeadm init if set.
+	SkipCoreDNSAnnotation = "controlplane.cluster.x-k8s.io/skip-coredns"
+
+	// GPURuntimeHandlerLabel is the node label set by the kubelet with the name of the containerd runtime handler
+	// for the GPU container runtime, when KubeadmConfigSpec.GPU is set.
+	GPURuntimeHandlerLabel = "bootstrap.cluster.x-k8s.io/gpu-runtime-handler"
+
+	// RegistryCredentialsLabel is set on the bootstrap data secrets that contain image registry credentials,
+	// so access to them can be restricted more tightly than access to other bootstrap data secrets.
+	RegistryCredentialsLabel = "bootstrap.cluster.x-k8s.io/registry-credentials"
+
+	// DataSecretPartOfLabel is set on the secrets storing parts of split bootstrap data, with the UID of the
+	// KubeadmConfig they belong to as a value, so stale parts can be found and deleted.
+	DataSecretPartOfLabel = "bootstrap.cluster.x-k8s.io/data-secret-part-of"
 )
 
 // KubeadmConfigSpec defines the desired state of KubeadmConfig.
@@ -94,6 +117,79 @@ type KubeadmConfigSpec struct {
 	// For more information, refer to https://github.com/kubernetes-sigs/cluster-api/pull/2763#discussion_r397306055.
 	// +optional
 	UseExperimentalRetryJoin bool `json:"useExperimentalRetryJoin,omitempty"`
+
+	// RotateKubeletServerCertificates sets the kubelet rotate-server-certificates flag, so the kubelet
+	// requests its serving certificate from the certificates API of the workload cluster instead of using
+	// a self-signed certificate, and rotates it as the certificate approaches expiration.
+	// NOTE: kubelet serving certificate signing requests are not approved automatically by the kube-controller-manager,
+	// so an approver must be deployed in the workload cluster; when this field is set for a KubeadmControlPlane,
+	// the KubeadmControlPlane controller creates the RBAC rules required by the approver.
+	// +optional
+	RotateKubeletServerCertificates bool `json:"rotateKubeletServerCertificates,omitempty"`
+
+	// DataSecretMaxSize is the maximum size, in bytes, of the bootstrap data stored in a single secret.
+	// If the bootstrap data exceeds this size, it is split across multiple secrets, and the secret named
+	// status.dataSecretName lists the names of the secrets storing the parts, in order, instead of storing the data.
+	// NOTE: the infrastructure provider must support reassembling split bootstrap data, see the Machine contract.
+	// +kubebuilder:validation:Minimum=1024
+	// +optional
+	DataSecretMaxSize *int32 `json:"dataSecretMaxSize,omitempty"`
+
+	// GPU configures the node for running GPU workloads, by registering the GPU container runtime
+	// as an additional containerd runtime handler and by labeling the node accordingly.
+	// NOTE: the GPU drivers and the GPU container runtime must be already installed in the machine image,
+	// and the containerd configuration in the image must import the files in /etc/containerd/conf.d.
+	// +optional
+	GPU *GPUConfiguration `json:"gpu,omitempty"`
+
+	// UploadCertificates enables control plane joins based on the kubeadm upload-certs feature: instead of
+	// writing the control plane certificates in the bootstrap data of each joining control plane machine,
+	// the certificates are encrypted with a certificate key and uploaded to the kubeadm-certs Secret in the
+	// workload cluster, from which kubeadm join downloads them. The certificate key is generated and stored
+	// in the management cluster, and it is automatically referenced in the JoinConfiguration.
+	// NOTE: this requires Kubernetes v1.15 or newer; the kubeadm-certs Secret is deleted together with the
+	// bootstrap tokens of the joining machines.
+	// +optional
+	UploadCertificates bool `json:"uploadCertificates,omitempty"`
+
+	// ImagePullSecrets references Secrets of type kubernetes.io/dockerconfigjson, in the same namespace of the
+	// KubeadmConfig, storing the credentials for private image registries. The credentials are rendered into
+	// the containerd registry configuration of the machine, so they never have to be written in the
+	// KubeadmConfig; bootstrap data secrets containing registry credentials get the RegistryCredentialsLabel.
+	// NOTE: the containerd configuration in the image must import the files in /etc/containerd/conf.d.
+	// +optional
+	ImagePullSecrets []ImagePullSecret `json:"imagePullSecrets,omitempty"`
+
+	// RenderFileTemplates enables rendering the content of Files and SecretDirectories as Go templates, with access
+	// to the .ClusterName, .ControlPlaneEndpoint, .CACertHash and .MachineName variables, so files for
+	// agents requiring discovery material or machine specific values can be generated without duplicating values manually.
+	// NOTE: files with an encoding are not rendered.
+	// +optional
+	RenderFileTemplates bool `json:"renderFileTemplates,omitempty"`
+
+	// SecretDirectories specifies directories to be populated with all the keys of user-managed Secrets,
+	// so multiple files can be injected from a single Secret without listing each key in Files.
+	// +optional
+	SecretDirectories []SecretDirectory `json:"secretDirectories,omitempty"`
+}
+
+// ImagePullSecret references a Secret storing image registry credentials.
+type ImagePullSecret struct {
+	// Name of the Secret, in the same namespace of the KubeadmConfig.
+	Name string `json:"name"`
+}
+
+// GPUConfiguration defines the containerd runtime handler to be configured for running GPU workloads.
+type GPUConfiguration struct {
+	// RuntimeHandler is the name of the containerd runtime handler for the GPU container runtime,
+	// to be referenced by the RuntimeClass used by GPU workloads. Defaults to "nvidia".
+	// +optional
+	RuntimeHandler string `json:"runtimeHandler,omitempty"`
+
+	// RuntimeBinaryPath is the path of the GPU container runtime binary.
+	// Defaults to "/usr/bin/nvidia-container-runtime".
+	// +optional
+	RuntimeBinaryPath string `json:"runtimeBinaryPath,omitempty"`
 }
 
 // KubeadmConfigStatus defines the observed state of KubeadmConfig.
@@ -117,6 +213,15 @@ type KubeadmConfigStatus struct {
 	// +optional
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 
+	// BootstrapTokenExpiration is the time the bootstrap token used by the node to join the cluster expires at.
+	// The token is refreshed until the node joins; for MachinePools, the token is rotated to keep it valid for future scale ups.
+	// +optional
+	BootstrapTokenExpiration *metav1.Time `json:"bootstrapTokenExpiration,omitempty"`
+
+	// BootstrapTokenRotations is the number of times the bootstrap token has been rotated.
+	// +optional
+	BootstrapTokenRotations int32 `json:"bootstrapTokenRotations,omitempty"`
+
 	// Conditions defines current service state of the KubeadmConfig.
 	// +optional
 	Conditions clusterv1.Conditions `json:"conditions,omitempty"`
@@ -203,7 +308,12 @@ type File struct {
 // sources of data for target systems should add them here.
 type FileSource struct {
 	// Secret represents a secret that should populate this file.
-	Secret SecretFileSource `json:"secret"`
+	// +optional
+	Secret SecretFileSource `json:"secret,omitempty"`
+
+	// ConfigMap represents a ConfigMap that should populate this file.
+	// +optional
+	ConfigMap *ConfigMapFileSource `json:"configMap,omitempty"`
 }
 
 // SecretFileSource adapts a Secret into a FileSource.
@@ -218,6 +328,33 @@ type SecretFileSource struct {
 	Key string `json:"key"`
 }
 
+// ConfigMapFileSource adapts a ConfigMap into a FileSource.
+type ConfigMapFileSource struct {
+	// Name of the ConfigMap in the KubeadmBootstrapConfig's namespace to use.
+	Name string `json:"name"`
+
+	// Key is the key in the ConfigMap's data map for this value.
+	Key string `json:"key"`
+}
+
+// SecretDirectory defines a directory populated with all the keys of a Secret.
+type SecretDirectory struct {
+	// Path specifies the full path of the directory on disk; each key in the Secret's data map
+	// is written as a file in this directory, using the key as file name.
+	Path string `json:"path"`
+
+	// Owner specifies the ownership of the files, e.g. "root:root".
+	// +optional
+	Owner string `json:"owner,omitempty"`
+
+	// Permissions specifies the permissions to assign to the files, e.g. "0640".
+	// +optional
+	Permissions string `json:"permissions,omitempty"`
+
+	// SecretName is the name of the Secret in the KubeadmConfig's namespace to use.
+	SecretName string `json:"secretName"`
+}
+
 // User defines the input for a generated user in cloud-init.
 type User struct {
 	// Name specifies the user name
@@ -64,6 +64,71 @@ func TestClusterValidate(t *testing.T) {
 				},
 			},
 		},
+		"valid contentFrom configMap": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					Files: []File{
+						{
+							ContentFrom: &FileSource{
+								ConfigMap: &ConfigMapFileSource{
+									Name: "foo",
+									Key:  "bar",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		"invalid contentFrom with both secret and configMap": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					Files: []File{
+						{
+							ContentFrom: &FileSource{
+								Secret: SecretFileSource{
+									Name: "foo",
+									Key:  "bar",
+								},
+								ConfigMap: &ConfigMapFileSource{
+									Name: "foo",
+									Key:  "bar",
+								},
+							},
+						},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"invalid contentFrom configMap without key": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					Files: []File{
+						{
+							ContentFrom: &FileSource{
+								ConfigMap: &ConfigMapFileSource{
+									Name: "foo",
+								},
+							},
+						},
+					},
+				},
+			},
+			expectErr: true,
+		},
 		"invalid content and contentFrom": {
 			in: &KubeadmConfig{
 				ObjectMeta: metav1.ObjectMeta{
@@ -142,6 +207,90 @@ func TestClusterValidate(t *testing.T) {
 			},
 			expectErr: true,
 		},
+		"valid ignition format": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					Format: Ignition,
+					Files: []File{
+						{
+							Path:    "/etc/foo",
+							Content: "foo",
+						},
+					},
+					Users: []User{
+						{
+							Name: "foo",
+						},
+					},
+				},
+			},
+		},
+		"ignition format with unsupported fields": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					Format: Ignition,
+					Mounts: []MountPoints{
+						{"LABEL=etcd_disk", "/var/lib/etcddisk"},
+					},
+					NTP: &NTP{
+						Servers: []string{"time.example.com"},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"valid secret directory": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					SecretDirectories: []SecretDirectory{
+						{Path: "/etc/agent", SecretName: "agent-config"},
+					},
+				},
+			},
+		},
+		"invalid secret directory without path and secret name": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					SecretDirectories: []SecretDirectory{
+						{},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"invalid secret directory with a path conflicting with a file": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					Files: []File{
+						{Path: "/etc/agent", Content: "foo"},
+					},
+					SecretDirectories: []SecretDirectory{
+						{Path: "/etc/agent", SecretName: "agent-config"},
+					},
+				},
+			},
+			expectErr: true,
+		},
 	}
 
 	for name, tt := range cases {
@@ -27,10 +27,15 @@ import (
 )
 
 var (
-	conflictingFileSourceMsg = "only one of content or contentFrom may be specified for a single file"
-	missingSecretNameMsg     = "secret file source must specify non-empty secret name"
-	missingSecretKeyMsg      = "secret file source must specify non-empty secret key"
-	pathConflictMsg          = "path property must be unique among all files"
+	conflictingFileSourceMsg  = "only one of content or contentFrom may be specified for a single file"
+	missingSecretNameMsg      = "secret file source must specify non-empty secret name"
+	missingSecretKeyMsg       = "secret file source must specify non-empty secret key"
+	missingConfigMapNameMsg   = "configMap file source must specify non-empty configMap name"
+	missingConfigMapKeyMsg    = "configMap file source must specify non-empty configMap key"
+	conflictingContentFromMsg = "only one of secret or configMap may be specified for a single file source"
+	pathConflictMsg           = "path property must be unique among all files"
+	missingPathMsg            = "path must not be empty"
+	ignitionUnsupportedMsg    = "not supported when using the ignition format"
 )
 
 func (c *KubeadmConfig) SetupWebhookWithManager(mgr ctrl.Manager) error {
@@ -75,10 +80,41 @@ func (c *KubeadmConfigSpec) validate(name string) error {
 				),
 			)
 		}
-		// n.b.: if we ever add types besides Secret as a ContentFrom
-		// Source, we must add webhook validation here for one of the
-		// sources being non-nil.
-		if file.ContentFrom != nil {
+		// n.b.: if we ever add more types as a ContentFrom Source,
+		// we must add webhook validation here for only one of the
+		// sources being set.
+		if file.ContentFrom != nil && file.ContentFrom.ConfigMap != nil {
+			if file.ContentFrom.Secret != (SecretFileSource{}) {
+				allErrs = append(
+					allErrs,
+					field.Invalid(
+						field.NewPath("spec", "files", fmt.Sprintf("%d", i), "contentFrom"),
+						file,
+						conflictingContentFromMsg,
+					),
+				)
+			}
+			if file.ContentFrom.ConfigMap.Name == "" {
+				allErrs = append(
+					allErrs,
+					field.Invalid(
+						field.NewPath("spec", "files", fmt.Sprintf("%d", i), "contentFrom", "configMap", "name"),
+						file,
+						missingConfigMapNameMsg,
+					),
+				)
+			}
+			if file.ContentFrom.ConfigMap.Key == "" {
+				allErrs = append(
+					allErrs,
+					field.Invalid(
+						field.NewPath("spec", "files", fmt.Sprintf("%d", i), "contentFrom", "configMap", "key"),
+						file,
+						missingConfigMapKeyMsg,
+					),
+				)
+			}
+		} else if file.ContentFrom != nil {
 			if file.ContentFrom.Secret.Name == "" {
 				allErrs = append(
 					allErrs,
@@ -114,6 +150,32 @@ func (c *KubeadmConfigSpec) validate(name string) error {
 		knownPaths[file.Path] = struct{}{}
 	}
 
+	for i, dir := range c.SecretDirectories {
+		dirPath := field.NewPath("spec", "secretDirectories").Index(i)
+		if dir.Path == "" {
+			allErrs = append(allErrs, field.Invalid(dirPath.Child("path"), dir.Path, missingPathMsg))
+		}
+		if dir.SecretName == "" {
+			allErrs = append(allErrs, field.Invalid(dirPath.Child("secretName"), dir.SecretName, missingSecretNameMsg))
+		}
+		if _, conflict := knownPaths[dir.Path]; conflict {
+			allErrs = append(allErrs, field.Invalid(dirPath.Child("path"), dir.Path, pathConflictMsg))
+		}
+		knownPaths[dir.Path] = struct{}{}
+	}
+
+	if c.Format == Ignition {
+		if c.DiskSetup != nil {
+			allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "diskSetup"), ignitionUnsupportedMsg))
+		}
+		if len(c.Mounts) > 0 {
+			allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "mounts"), ignitionUnsupportedMsg))
+		}
+		if c.NTP != nil {
+			allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "ntp"), ignitionUnsupportedMsg))
+		}
+	}
+
 	if len(allErrs) == 0 {
 		return nil
 	}
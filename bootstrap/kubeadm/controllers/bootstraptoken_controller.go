@@ -0,0 +1,226 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
+	bsutil "sigs.k8s.io/cluster-api/bootstrap/util"
+	"sigs.k8s.io/cluster-api/controllers/remote"
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/feature"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/annotations"
+	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/cluster-api/util/predicates"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	// BootstrapTokenControllerName defines the controller used when creating clients.
+	BootstrapTokenControllerName = "bootstraptoken-controller"
+)
+
+// BootstrapTokenReconciler manages the lifecycle of the bootstrap tokens used by the nodes to join the cluster.
+// It reconciles the KubeadmConfigs with bootstrap data already generated, refreshing the bootstrap token until
+// the infrastructure of the config owner is ready, so the token embedded in the bootstrap data does not expire before
+// the node has a chance to consume it. The bootstrap token of configs owned by MachinePools is instead rotated, so the
+// bootstrap data stays valid for future scale ups; the KubeadmConfigReconciler then generates the bootstrap data again.
+type BootstrapTokenReconciler struct {
+	Client           client.Client
+	WatchFilterValue string
+
+	remoteClientGetter remote.ClusterClientGetter
+}
+
+// SetupWithManager sets up the reconciler with the Manager.
+func (r *BootstrapTokenReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, options controller.Options) error {
+	if r.remoteClientGetter == nil {
+		r.remoteClientGetter = remote.NewClusterClient
+	}
+
+	b := ctrl.NewControllerManagedBy(mgr).
+		Named(BootstrapTokenControllerName).
+		For(&bootstrapv1.KubeadmConfig{}).
+		WithOptions(options).
+		WithEventFilter(predicates.ResourceNotPausedAndHasFilterLabel(ctrl.LoggerFrom(ctx), r.WatchFilterValue)).
+		Watches(
+			&source.Kind{Type: &clusterv1.Machine{}},
+			handler.EnqueueRequestsFromMapFunc(r.machineToKubeadmConfig),
+		)
+
+	if feature.Gates.Enabled(feature.MachinePool) {
+		b = b.Watches(
+			&source.Kind{Type: &expv1.MachinePool{}},
+			handler.EnqueueRequestsFromMapFunc(r.machinePoolToKubeadmConfig),
+		)
+	}
+
+	if err := b.Complete(r); err != nil {
+		return errors.Wrap(err, "failed setting up with a controller manager")
+	}
+	return nil
+}
+
+// Reconcile handles KubeadmConfig events.
+func (r *BootstrapTokenReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, rerr error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	// Lookup the KubeadmConfig.
+	config := &bootstrapv1.KubeadmConfig{}
+	if err := r.Client.Get(ctx, req.NamespacedName, config); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	// Only the bootstrap data of a joining node embeds a bootstrap token, and the token is managed only once the
+	// bootstrap data has been generated.
+	if !config.DeletionTimestamp.IsZero() || config.Status.DataSecretName == nil {
+		return ctrl.Result{}, nil
+	}
+	if config.Spec.JoinConfiguration == nil || config.Spec.JoinConfiguration.Discovery.BootstrapToken == nil || config.Spec.JoinConfiguration.Discovery.BootstrapToken.Token == "" {
+		return ctrl.Result{}, nil
+	}
+
+	// Lookup the owner of the config.
+	configOwner, err := bsutil.GetConfigOwner(ctx, r.Client, config)
+	if apierrors.IsNotFound(err) {
+		// Could not find the owner yet, this is not an error and will rereconcile when the owner gets set.
+		return ctrl.Result{}, nil
+	}
+	if err != nil {
+		return ctrl.Result{}, errors.Wrapf(err, "failed to get owner of KubeadmConfig %s/%s", config.Namespace, config.Name)
+	}
+	if configOwner == nil {
+		return ctrl.Result{}, nil
+	}
+	log = log.WithValues("kubeadmConfig", config.Name, configOwner.GetKind(), configOwner.GetName())
+	ctx = ctrl.LoggerInto(ctx, log)
+
+	// Lookup the cluster the config owner is associated with.
+	cluster, err := util.GetClusterByName(ctx, r.Client, configOwner.GetNamespace(), configOwner.ClusterName())
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	if annotations.IsPaused(cluster, config) {
+		log.Info("Reconciliation is paused for this object")
+		return ctrl.Result{}, nil
+	}
+
+	// If the infrastructure is ready and the config owner is not a MachinePool, the token has been consumed and it will expire.
+	if configOwner.IsInfrastructureReady() && !configOwner.IsMachinePool() {
+		return ctrl.Result{}, nil
+	}
+
+	// Initialize the patch helper.
+	patchHelper, err := patch.NewHelper(config, r.Client)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	defer func() {
+		if err := patchHelper.Patch(ctx, config); err != nil {
+			log.Error(err, "Failed to patch config")
+			if rerr == nil {
+				rerr = err
+			}
+		}
+	}()
+
+	remoteClient, err := r.remoteClientGetter(ctx, BootstrapTokenControllerName, r.Client, util.ObjectKey(cluster))
+	if err != nil {
+		log.Error(err, "Error creating remote cluster client")
+		return ctrl.Result{}, err
+	}
+
+	token := config.Spec.JoinConfiguration.Discovery.BootstrapToken.Token
+	expiration, err := getTokenExpiration(ctx, remoteClient, token)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrapf(err, "failed to get bootstrap token")
+	}
+
+	if shouldRenew(expiration) {
+		if !configOwner.IsInfrastructureReady() {
+			// If the infrastructure is not ready, the token in the join config has not been consumed yet and it needs a refresh.
+			log.Info("Refreshing token until the infrastructure has a chance to consume it")
+			expiration, err = refreshToken(ctx, remoteClient, token)
+			if err != nil {
+				return ctrl.Result{}, errors.Wrapf(err, "failed to refresh bootstrap token")
+			}
+		} else {
+			// If the infrastructure is ready but the config owner is a MachinePool, the token is rotated to keep it fresh
+			// for future scale ups.
+			log.V(2).Info("Creating new bootstrap token")
+			token, err = createToken(ctx, remoteClient)
+			if err != nil {
+				return ctrl.Result{}, errors.Wrapf(err, "failed to create new bootstrap token")
+			}
+			expiration, err = getTokenExpiration(ctx, remoteClient, token)
+			if err != nil {
+				return ctrl.Result{}, errors.Wrapf(err, "failed to get bootstrap token")
+			}
+
+			config.Spec.JoinConfiguration.Discovery.BootstrapToken.Token = token
+			config.Status.BootstrapTokenRotations++
+			log.Info("Altering JoinConfiguration.Discovery.BootstrapToken", "Token", token)
+		}
+	}
+	config.Status.BootstrapTokenExpiration = &metav1.Time{Time: expiration}
+
+	return ctrl.Result{RequeueAfter: DefaultTokenTTL / 3}, nil
+}
+
+// machineToKubeadmConfig maps a Machine bootstrapped by a KubeadmConfig to the KubeadmConfig.
+func (r *BootstrapTokenReconciler) machineToKubeadmConfig(o client.Object) []ctrl.Request {
+	m, ok := o.(*clusterv1.Machine)
+	if !ok {
+		panic(fmt.Sprintf("Expected a Machine but got a %T", o))
+	}
+	return kubeadmConfigRequests(m.Namespace, m.Spec.Bootstrap.ConfigRef)
+}
+
+// machinePoolToKubeadmConfig maps a MachinePool bootstrapped by a KubeadmConfig to the KubeadmConfig.
+func (r *BootstrapTokenReconciler) machinePoolToKubeadmConfig(o client.Object) []ctrl.Request {
+	m, ok := o.(*expv1.MachinePool)
+	if !ok {
+		panic(fmt.Sprintf("Expected a MachinePool but got a %T", o))
+	}
+	return kubeadmConfigRequests(m.Namespace, m.Spec.Template.Spec.Bootstrap.ConfigRef)
+}
+
+func kubeadmConfigRequests(namespace string, configRef *corev1.ObjectReference) []ctrl.Request {
+	if !isKubeadmConfigRef(configRef) {
+		return nil
+	}
+	return []ctrl.Request{{NamespacedName: client.ObjectKey{Namespace: namespace, Name: configRef.Name}}}
+}
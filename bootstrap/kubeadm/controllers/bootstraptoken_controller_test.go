@@ -0,0 +1,222 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	bootstrapapi "k8s.io/cluster-bootstrap/token/api"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	fakeremote "sigs.k8s.io/cluster-api/controllers/remote/fake"
+	"sigs.k8s.io/cluster-api/feature"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/patch"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestBootstrapTokenReconciler_RefreshToken(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster := newCluster("cluster")
+	cluster.Status.InfrastructureReady = true
+	conditions.MarkTrue(cluster, clusterv1.ControlPlaneInitializedCondition)
+	cluster.Spec.ControlPlaneEndpoint = clusterv1.APIEndpoint{Host: "100.105.150.1", Port: 6443}
+
+	controlPlaneInitMachine := newControlPlaneMachine(cluster, "control-plane-init-machine")
+	initConfig := newControlPlaneInitKubeadmConfig(controlPlaneInitMachine, "control-plane-init-config")
+	workerMachine := newWorkerMachine(cluster)
+	workerJoinConfig := newWorkerJoinKubeadmConfig(workerMachine)
+	objects := []client.Object{
+		cluster,
+		workerMachine,
+		workerJoinConfig,
+	}
+	objects = append(objects, createSecrets(t, cluster, initConfig)...)
+	myclient := fake.NewClientBuilder().WithObjects(objects...).Build()
+
+	// Generate the bootstrap data, creating the bootstrap token.
+	k := &KubeadmConfigReconciler{
+		Client:             myclient,
+		KubeadmInitLock:    &myInitLocker{},
+		remoteClientGetter: fakeremote.NewClusterClient,
+	}
+	_, err := k.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKey{Namespace: "default", Name: "worker-join-cfg"}})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	r := &BootstrapTokenReconciler{
+		Client:             myclient,
+		remoteClientGetter: fakeremote.NewClusterClient,
+	}
+	request := ctrl.Request{NamespacedName: client.ObjectKey{Namespace: "default", Name: "worker-join-cfg"}}
+
+	// A fresh token is not refreshed, but its expiration is reported.
+	result, err := r.Reconcile(ctx, request)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result.RequeueAfter).To(Equal(DefaultTokenTTL / 3))
+
+	tokenSecret := getBootstrapTokenSecret(g, myclient)
+	cfg, err := getKubeadmConfig(myclient, "worker-join-cfg")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cfg.Status.BootstrapTokenExpiration).NotTo(BeNil())
+	g.Expect(cfg.Status.BootstrapTokenExpiration.Format(time.RFC3339)).To(Equal(string(tokenSecret.Data[bootstrapapi.BootstrapTokenExpirationKey])))
+
+	// A token past half of its TTL is refreshed until the infrastructure is ready...
+	shortenBootstrapTokenTTL(g, myclient, tokenSecret)
+	result, err = r.Reconcile(ctx, request)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result.RequeueAfter).To(Equal(DefaultTokenTTL / 3))
+
+	tokenSecret = getBootstrapTokenSecret(g, myclient)
+	expiration, err := time.Parse(time.RFC3339, string(tokenSecret.Data[bootstrapapi.BootstrapTokenExpirationKey]))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(shouldRenew(expiration)).To(BeFalse())
+	cfg, err = getKubeadmConfig(myclient, "worker-join-cfg")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cfg.Status.BootstrapTokenExpiration.Time).To(BeTemporally("==", expiration))
+	g.Expect(cfg.Status.BootstrapTokenRotations).To(BeZero())
+
+	// ...then the token is left to expire.
+	patchHelper, err := patch.NewHelper(workerMachine, myclient)
+	g.Expect(err).NotTo(HaveOccurred())
+	workerMachine.Status.InfrastructureReady = true
+	g.Expect(patchHelper.Patch(ctx, workerMachine)).To(Succeed())
+
+	shortenBootstrapTokenTTL(g, myclient, tokenSecret)
+	result, err = r.Reconcile(ctx, request)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result.RequeueAfter).To(BeZero())
+	g.Expect(getBootstrapTokenSecret(g, myclient).Data).To(Equal(tokenSecret.Data))
+}
+
+func TestBootstrapTokenReconciler_RotateMachinePoolToken(t *testing.T) {
+	_ = feature.MutableGates.Set("MachinePool=true")
+	g := NewWithT(t)
+
+	cluster := newCluster("cluster")
+	cluster.Status.InfrastructureReady = true
+	conditions.MarkTrue(cluster, clusterv1.ControlPlaneInitializedCondition)
+	cluster.Spec.ControlPlaneEndpoint = clusterv1.APIEndpoint{Host: "100.105.150.1", Port: 6443}
+
+	controlPlaneInitMachine := newControlPlaneMachine(cluster, "control-plane-init-machine")
+	initConfig := newControlPlaneInitKubeadmConfig(controlPlaneInitMachine, "control-plane-init-config")
+	workerMachinePool := newWorkerMachinePool(cluster)
+	workerMachinePool.Status.InfrastructureReady = true
+	workerJoinConfig := newWorkerPoolJoinKubeadmConfig(workerMachinePool)
+	objects := []client.Object{
+		cluster,
+		workerMachinePool,
+		workerJoinConfig,
+	}
+	objects = append(objects, createSecrets(t, cluster, initConfig)...)
+	myclient := fake.NewClientBuilder().WithObjects(objects...).Build()
+
+	// Generate the bootstrap data, creating the bootstrap token.
+	k := &KubeadmConfigReconciler{
+		Client:             myclient,
+		KubeadmInitLock:    &myInitLocker{},
+		remoteClientGetter: fakeremote.NewClusterClient,
+	}
+	configRequest := ctrl.Request{NamespacedName: client.ObjectKey{Namespace: "default", Name: "workerpool-join-cfg"}}
+	_, err := k.Reconcile(ctx, configRequest)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	r := &BootstrapTokenReconciler{
+		Client:             myclient,
+		remoteClientGetter: fakeremote.NewClusterClient,
+	}
+	request := ctrl.Request{NamespacedName: client.ObjectKey{Namespace: "default", Name: "workerpool-join-cfg"}}
+
+	// A fresh token is not rotated.
+	result, err := r.Reconcile(ctx, request)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result.RequeueAfter).To(Equal(DefaultTokenTTL / 3))
+
+	cfg, err := getKubeadmConfig(myclient, "workerpool-join-cfg")
+	g.Expect(err).NotTo(HaveOccurred())
+	oldToken := cfg.Spec.JoinConfiguration.Discovery.BootstrapToken.Token
+	g.Expect(cfg.Status.BootstrapTokenRotations).To(BeZero())
+
+	// Before the token expires, it is rotated.
+	shortenBootstrapTokenTTL(g, myclient, getBootstrapTokenSecret(g, myclient))
+	result, err = r.Reconcile(ctx, request)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result.RequeueAfter).To(Equal(DefaultTokenTTL / 3))
+
+	l := &corev1.SecretList{}
+	g.Expect(myclient.List(ctx, l, client.InNamespace(metav1.NamespaceSystem))).To(Succeed())
+	g.Expect(l.Items).To(HaveLen(2))
+
+	cfg, err = getKubeadmConfig(myclient, "workerpool-join-cfg")
+	g.Expect(err).NotTo(HaveOccurred())
+	newToken := cfg.Spec.JoinConfiguration.Discovery.BootstrapToken.Token
+	g.Expect(newToken).NotTo(Equal(oldToken))
+	g.Expect(cfg.Status.BootstrapTokenRotations).To(Equal(int32(1)))
+	g.Expect(shouldRenew(cfg.Status.BootstrapTokenExpiration.Time)).To(BeFalse())
+
+	// The bootstrap data is then generated again with the new token, as the spec of the config changed.
+	cfg.Generation = cfg.Status.ObservedGeneration + 1
+	g.Expect(myclient.Update(ctx, cfg)).To(Succeed())
+	_, err = k.Reconcile(ctx, configRequest)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	dataSecret := &corev1.Secret{}
+	g.Expect(myclient.Get(ctx, client.ObjectKey{Namespace: "default", Name: "workerpool-join-cfg"}, dataSecret)).To(Succeed())
+	g.Expect(bytes.Contains(dataSecret.Data[clusterv1.BootstrapDataSecretValueKey], []byte(newToken))).To(BeTrue())
+	g.Expect(bytes.Contains(dataSecret.Data[clusterv1.BootstrapDataSecretValueKey], []byte(oldToken))).To(BeFalse())
+}
+
+func TestBootstrapTokenReconciler_IgnoreConfigsWithoutBootstrapData(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster := newCluster("cluster")
+	workerMachine := newWorkerMachine(cluster)
+	workerJoinConfig := newWorkerJoinKubeadmConfig(workerMachine)
+	r := &BootstrapTokenReconciler{
+		Client:             fake.NewClientBuilder().WithObjects(cluster, workerMachine, workerJoinConfig).Build(),
+		remoteClientGetter: fakeremote.NewClusterClient,
+	}
+
+	result, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(workerJoinConfig)})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result).To(Equal(ctrl.Result{}))
+
+	// A missing config is ignored too.
+	result, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKey{Namespace: "default", Name: "missing"}})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result).To(Equal(ctrl.Result{}))
+}
+
+// getBootstrapTokenSecret returns the only bootstrap token secret in the cluster.
+func getBootstrapTokenSecret(g *WithT, c client.Client) *corev1.Secret {
+	l := &corev1.SecretList{}
+	g.Expect(c.List(ctx, l, client.InNamespace(metav1.NamespaceSystem))).To(Succeed())
+	g.Expect(l.Items).To(HaveLen(1))
+	return &l.Items[0]
+}
+
+// shortenBootstrapTokenTTL makes the bootstrap token past half of its TTL.
+func shortenBootstrapTokenTTL(g *WithT, c client.Client, tokenSecret *corev1.Secret) {
+	tokenSecret.Data[bootstrapapi.BootstrapTokenExpirationKey] = []byte(time.Now().UTC().Add(DefaultTokenTTL / 5).Format(time.RFC3339))
+	g.Expect(c.Update(ctx, tokenSecret)).To(Succeed())
+}
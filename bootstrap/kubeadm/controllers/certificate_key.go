@@ -0,0 +1,327 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/secret"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// kubeadmCertsSecretName is the name of the Secret in the workload cluster storing the encrypted
+	// control plane certificates, as defined by kubeadm.
+	kubeadmCertsSecretName = "kubeadm-certs"
+
+	// kubeadmCertsRoleName is the name of the Role allowing joining nodes to get the kubeadm-certs Secret, as defined by kubeadm.
+	kubeadmCertsRoleName = "kubeadm:kubeadm-certs"
+
+	// kubeadmDefaultNodeTokenGroup is the group of the bootstrap tokens used by joining nodes.
+	kubeadmDefaultNodeTokenGroup = "system:bootstrappers:kubeadm:default-node-token"
+
+	// certificateKeyDataName is the key used to store the certificate key in the secret's data field.
+	certificateKeyDataName = "value"
+
+	// certificateKeySize is the size, in bytes, of the certificate key; kubeadm uses AES-256.
+	certificateKeySize = 32
+)
+
+// lookupOrGenerateCertificateKey returns the key used for encrypting the control plane certificates uploaded
+// to the workload cluster, generating it if it does not exist yet. The key is stored in a Secret owned by the Cluster,
+// so it is shared by all the control plane machines of the Cluster.
+func (r *KubeadmConfigReconciler) lookupOrGenerateCertificateKey(ctx context.Context, cluster *clusterv1.Cluster) (string, error) {
+	s, err := secret.Get(ctx, r.Client, util.ObjectKey(cluster), secret.KubeadmCertificateKey)
+	if err == nil {
+		key := string(s.Data[certificateKeyDataName])
+		if _, err := decodeCertificateKey(key); err != nil {
+			return "", errors.Wrapf(err, "invalid certificate key in Secret %s", s.Name)
+		}
+		return key, nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return "", errors.Wrap(err, "failed to get the certificate key")
+	}
+
+	rawKey := make([]byte, certificateKeySize)
+	if _, err := rand.Read(rawKey); err != nil {
+		return "", errors.Wrap(err, "failed to generate the certificate key")
+	}
+	key := hex.EncodeToString(rawKey)
+
+	s = &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: cluster.Namespace,
+			Name:      secret.Name(cluster.Name, secret.KubeadmCertificateKey),
+			Labels: map[string]string{
+				clusterv1.ClusterLabelName: cluster.Name,
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: clusterv1.GroupVersion.String(),
+					Kind:       "Cluster",
+					Name:       cluster.Name,
+					UID:        cluster.UID,
+				},
+			},
+		},
+		Data: map[string][]byte{
+			certificateKeyDataName: []byte(key),
+		},
+		Type: clusterv1.ClusterSecretType,
+	}
+	if err := r.Client.Create(ctx, s); err != nil {
+		if apierrors.IsAlreadyExists(err) {
+			// Another control plane machine created the key in the meantime, use it.
+			return r.lookupOrGenerateCertificateKey(ctx, cluster)
+		}
+		return "", errors.Wrap(err, "failed to create the certificate key")
+	}
+	return key, nil
+}
+
+// uploadCertificates encrypts the control plane certificates with the certificate key and uploads them to the
+// kubeadm-certs Secret in the workload cluster, the same way kubeadm init --upload-certs does.
+// The Secret is owned by the bootstrap token used by the joining machine, so it is deleted by the garbage collector
+// once all the bootstrap tokens of the joining machines expire; if the Secret is already deleted when a machine
+// is still waiting to join, it is uploaded again.
+func uploadCertificates(ctx context.Context, c client.Client, certificates secret.Certificates, certificateKey, token string) error {
+	key, err := decodeCertificateKey(certificateKey)
+	if err != nil {
+		return err
+	}
+
+	tokenSecret, err := getToken(ctx, c, token)
+	if err != nil {
+		return errors.Wrap(err, "failed to get the bootstrap token owning the kubeadm-certs Secret")
+	}
+
+	contents := map[string][]byte{}
+	for _, certificate := range certificates {
+		if certificate.KeyPair == nil {
+			continue
+		}
+		certName, keyName := kubeadmCertsDataNames(certificate)
+		for name, content := range map[string][]byte{certName: certificate.KeyPair.Cert, keyName: certificate.KeyPair.Key} {
+			if name == "" || len(content) == 0 {
+				continue
+			}
+			contents[name] = content
+		}
+	}
+
+	ownerRef := metav1.OwnerReference{
+		APIVersion: "v1",
+		Kind:       "Secret",
+		Name:       tokenSecret.Name,
+		UID:        tokenSecret.UID,
+	}
+	kubeadmCerts := &corev1.Secret{}
+	err = c.Get(ctx, client.ObjectKey{Namespace: metav1.NamespaceSystem, Name: kubeadmCertsSecretName}, kubeadmCerts)
+	switch {
+	case apierrors.IsNotFound(err):
+		data, err := encryptKubeadmCerts(contents, key)
+		if err != nil {
+			return err
+		}
+		kubeadmCerts = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:       metav1.NamespaceSystem,
+				Name:            kubeadmCertsSecretName,
+				OwnerReferences: []metav1.OwnerReference{ownerRef},
+			},
+			Data: data,
+		}
+		if err := c.Create(ctx, kubeadmCerts); err != nil {
+			return errors.Wrap(err, "failed to create the kubeadm-certs Secret")
+		}
+	case err != nil:
+		return errors.Wrap(err, "failed to get the kubeadm-certs Secret")
+	default:
+		// The certificates are encrypted with a random nonce, so the decrypted contents are compared to avoid
+		// rewriting an up to date Secret.
+		upToDate := kubeadmCertsUpToDate(kubeadmCerts.Data, contents, key)
+		if upToDate && util.HasOwnerRef(kubeadmCerts.OwnerReferences, ownerRef) {
+			break
+		}
+
+		// NOTE: the Secret is owned by all the bootstrap tokens of the machines waiting to join.
+		kubeadmCerts.OwnerReferences = util.EnsureOwnerRef(kubeadmCerts.OwnerReferences, ownerRef)
+		if !upToDate {
+			data, err := encryptKubeadmCerts(contents, key)
+			if err != nil {
+				return err
+			}
+			kubeadmCerts.Data = data
+		}
+		if err := c.Update(ctx, kubeadmCerts); err != nil {
+			return errors.Wrap(err, "failed to update the kubeadm-certs Secret")
+		}
+	}
+
+	return ensureKubeadmCertsRBAC(ctx, c)
+}
+
+// ensureKubeadmCertsRBAC creates the Role and the RoleBinding allowing joining nodes to get the kubeadm-certs Secret,
+// as kubeadm init --upload-certs does.
+func ensureKubeadmCertsRBAC(ctx context.Context, c client.Client) error {
+	role := &rbacv1.Role{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: metav1.NamespaceSystem,
+			Name:      kubeadmCertsRoleName,
+		},
+		Rules: []rbacv1.PolicyRule{
+			{
+				APIGroups:     []string{""},
+				Resources:     []string{"secrets"},
+				ResourceNames: []string{kubeadmCertsSecretName},
+				Verbs:         []string{"get"},
+			},
+		},
+	}
+	if err := c.Create(ctx, role); err != nil && !apierrors.IsAlreadyExists(err) {
+		return errors.Wrapf(err, "failed to create Role %s", kubeadmCertsRoleName)
+	}
+
+	roleBinding := &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: metav1.NamespaceSystem,
+			Name:      kubeadmCertsRoleName,
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "Role",
+			Name:     kubeadmCertsRoleName,
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind: rbacv1.GroupKind,
+				Name: kubeadmDefaultNodeTokenGroup,
+			},
+		},
+	}
+	if err := c.Create(ctx, roleBinding); err != nil && !apierrors.IsAlreadyExists(err) {
+		return errors.Wrapf(err, "failed to create RoleBinding %s", kubeadmCertsRoleName)
+	}
+	return nil
+}
+
+// encryptKubeadmCerts encrypts the contents of the kubeadm-certs Secret with the certificate key.
+func encryptKubeadmCerts(contents map[string][]byte, key []byte) (map[string][]byte, error) {
+	data := map[string][]byte{}
+	for name, content := range contents {
+		encrypted, err := encryptWithCertificateKey(content, key)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to encrypt %s", name)
+		}
+		data[name] = encrypted
+	}
+	return data, nil
+}
+
+// kubeadmCertsUpToDate returns true if the data of the kubeadm-certs Secret, decrypted with the certificate key,
+// matches the given contents.
+func kubeadmCertsUpToDate(data, contents map[string][]byte, key []byte) bool {
+	if len(data) != len(contents) {
+		return false
+	}
+	for name, content := range contents {
+		decrypted, err := decryptWithCertificateKey(data[name], key)
+		if err != nil || !bytes.Equal(decrypted, content) {
+			return false
+		}
+	}
+	return true
+}
+
+// kubeadmCertsDataNames returns the names used by kubeadm in the kubeadm-certs Secret for a certificate and its key.
+func kubeadmCertsDataNames(certificate *secret.Certificate) (string, string) {
+	switch certificate.Purpose {
+	case secret.ClusterCA:
+		return "ca.crt", "ca.key"
+	case secret.ServiceAccount:
+		return "sa.pub", "sa.key"
+	case secret.FrontProxyCA:
+		return "front-proxy-ca.crt", "front-proxy-ca.key"
+	case secret.EtcdCA:
+		if certificate.External {
+			return "external-etcd-ca.crt", ""
+		}
+		return "etcd-ca.crt", "etcd-ca.key"
+	case secret.APIServerEtcdClient:
+		return "external-etcd.crt", "external-etcd.key"
+	default:
+		return "", ""
+	}
+}
+
+func decodeCertificateKey(certificateKey string) ([]byte, error) {
+	key, err := hex.DecodeString(certificateKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode the certificate key")
+	}
+	if len(key) != certificateKeySize {
+		return nil, errors.Errorf("the certificate key must be %d bytes long", certificateKeySize)
+	}
+	return key, nil
+}
+
+// encryptWithCertificateKey encrypts data using AES-GCM, prepending the nonce to the encrypted data,
+// the same way kubeadm does.
+func encryptWithCertificateKey(data, key []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, data, nil), nil
+}
+
+// decryptWithCertificateKey decrypts data encrypted by encryptWithCertificateKey.
+func decryptWithCertificateKey(data, key []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, errors.New("the encrypted data is too short")
+	}
+	return gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/util/secret"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestLookupOrGenerateCertificateKey(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster := newCluster("cluster")
+	r := &KubeadmConfigReconciler{
+		Client: fake.NewClientBuilder().WithObjects(cluster).Build(),
+	}
+
+	key, err := r.lookupOrGenerateCertificateKey(ctx, cluster)
+	g.Expect(err).NotTo(HaveOccurred())
+	_, err = decodeCertificateKey(key)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// The key is generated once and then shared by all the control plane machines.
+	sameKey, err := r.lookupOrGenerateCertificateKey(ctx, cluster)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(sameKey).To(Equal(key))
+
+	s, err := secret.Get(ctx, r.Client, client.ObjectKeyFromObject(cluster), secret.KubeadmCertificateKey)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(s.OwnerReferences).To(HaveLen(1))
+	g.Expect(s.OwnerReferences[0].Name).To(Equal(cluster.Name))
+}
+
+func TestUploadCertificates(t *testing.T) {
+	g := NewWithT(t)
+
+	certificates := secret.NewCertificatesForInitialControlPlane(&bootstrapv1.ClusterConfiguration{})
+	g.Expect(certificates.Generate()).To(Succeed())
+
+	c := fake.NewClientBuilder().Build()
+	certificateKey := strings.Repeat("ab", certificateKeySize)
+
+	token, err := createToken(ctx, c)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(uploadCertificates(ctx, c, certificates, certificateKey, token)).To(Succeed())
+
+	kubeadmCerts := &corev1.Secret{}
+	g.Expect(c.Get(ctx, client.ObjectKey{Namespace: metav1.NamespaceSystem, Name: kubeadmCertsSecretName}, kubeadmCerts)).To(Succeed())
+	g.Expect(kubeadmCerts.OwnerReferences).To(HaveLen(1))
+	g.Expect(kubeadmCerts.Data).To(HaveKey("ca.key"))
+	g.Expect(kubeadmCerts.Data).To(HaveKey("sa.pub"))
+	g.Expect(kubeadmCerts.Data).To(HaveKey("front-proxy-ca.crt"))
+	g.Expect(kubeadmCerts.Data).To(HaveKey("etcd-ca.key"))
+
+	key, err := decodeCertificateKey(certificateKey)
+	g.Expect(err).NotTo(HaveOccurred())
+	ca := certificates.GetByPurpose(secret.ClusterCA)
+	g.Expect(decryptWithCertificateKey(kubeadmCerts.Data["ca.crt"], key)).To(Equal(ca.KeyPair.Cert))
+
+	// Uploading the same certificates again does not rewrite the Secret.
+	resourceVersion := kubeadmCerts.ResourceVersion
+	g.Expect(uploadCertificates(ctx, c, certificates, certificateKey, token)).To(Succeed())
+	g.Expect(c.Get(ctx, client.ObjectKey{Namespace: metav1.NamespaceSystem, Name: kubeadmCertsSecretName}, kubeadmCerts)).To(Succeed())
+	g.Expect(kubeadmCerts.ResourceVersion).To(Equal(resourceVersion))
+
+	// Uploading the certificates for another joining machine adds a new owner.
+	otherToken, err := createToken(ctx, c)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(uploadCertificates(ctx, c, certificates, certificateKey, otherToken)).To(Succeed())
+	g.Expect(c.Get(ctx, client.ObjectKey{Namespace: metav1.NamespaceSystem, Name: kubeadmCertsSecretName}, kubeadmCerts)).To(Succeed())
+	g.Expect(kubeadmCerts.OwnerReferences).To(HaveLen(2))
+
+	g.Expect(c.Get(ctx, client.ObjectKey{Namespace: metav1.NamespaceSystem, Name: kubeadmCertsRoleName}, &rbacv1.Role{})).To(Succeed())
+	g.Expect(c.Get(ctx, client.ObjectKey{Namespace: metav1.NamespaceSystem, Name: kubeadmCertsRoleName}, &rbacv1.RoleBinding{})).To(Succeed())
+}
+
+func TestUploadCertificatesWithInvalidKey(t *testing.T) {
+	g := NewWithT(t)
+
+	c := fake.NewClientBuilder().Build()
+	token, err := createToken(ctx, c)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	g.Expect(uploadCertificates(ctx, c, secret.Certificates{}, "not-a-key", token)).NotTo(Succeed())
+	g.Expect(uploadCertificates(ctx, c, secret.Certificates{}, "abcdef", token)).NotTo(Succeed())
+}
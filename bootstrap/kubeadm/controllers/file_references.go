@@ -0,0 +1,157 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/util/conditions"
+)
+
+const (
+	// fileReferencesMinRequeueAfter is the minimum delay before checking again the Secrets referenced by files.
+	fileReferencesMinRequeueAfter = 5 * time.Second
+
+	// fileReferencesMaxRequeueAfter is the maximum delay before checking again the Secrets referenced by files.
+	fileReferencesMaxRequeueAfter = 5 * time.Minute
+)
+
+// fileReferences returns the Secrets referenced by the files of the KubeadmConfig, mapped to the keys
+// the files are read from; Secrets used as a whole, e.g. secret directories, are mapped to no keys.
+func fileReferences(cfg *bootstrapv1.KubeadmConfig) map[string][]string {
+	refs := map[string][]string{}
+	for _, f := range cfg.Spec.Files {
+		if f.ContentFrom != nil && f.ContentFrom.ConfigMap == nil {
+			refs[f.ContentFrom.Secret.Name] = append(refs[f.ContentFrom.Secret.Name], f.ContentFrom.Secret.Key)
+		}
+	}
+	for _, dir := range cfg.Spec.SecretDirectories {
+		if _, ok := refs[dir.SecretName]; !ok {
+			refs[dir.SecretName] = nil
+		}
+	}
+	for _, ref := range cfg.Spec.ImagePullSecrets {
+		if _, ok := refs[ref.Name]; !ok {
+			refs[ref.Name] = nil
+		}
+	}
+	return refs
+}
+
+// configMapFileReferences returns the ConfigMaps referenced by the files of the KubeadmConfig, mapped to the keys
+// the files are read from.
+func configMapFileReferences(cfg *bootstrapv1.KubeadmConfig) map[string][]string {
+	refs := map[string][]string{}
+	for _, f := range cfg.Spec.Files {
+		if f.ContentFrom != nil && f.ContentFrom.ConfigMap != nil {
+			refs[f.ContentFrom.ConfigMap.Name] = append(refs[f.ContentFrom.ConfigMap.Name], f.ContentFrom.ConfigMap.Key)
+		}
+	}
+	return refs
+}
+
+// missingFileReferences returns a description of the Secrets and ConfigMaps, and of their keys, referenced by
+// the files of the KubeadmConfig that do not exist, sorted.
+func (r *KubeadmConfigReconciler) missingFileReferences(ctx context.Context, cfg *bootstrapv1.KubeadmConfig) ([]string, error) {
+	missing := []string{}
+	for name, keys := range configMapFileReferences(cfg) {
+		configMap := &corev1.ConfigMap{}
+		key := types.NamespacedName{Namespace: cfg.Namespace, Name: name}
+		if err := r.Client.Get(ctx, key, configMap); err != nil {
+			if apierrors.IsNotFound(err) {
+				missing = append(missing, fmt.Sprintf("ConfigMap %s", name))
+				continue
+			}
+			return nil, errors.Wrapf(err, "failed to retrieve ConfigMap %q", key)
+		}
+		for _, k := range keys {
+			if _, ok := configMap.Data[k]; !ok {
+				missing = append(missing, fmt.Sprintf("key %s in ConfigMap %s", k, name))
+			}
+		}
+	}
+	for name, keys := range fileReferences(cfg) {
+		secret := &corev1.Secret{}
+		key := types.NamespacedName{Namespace: cfg.Namespace, Name: name}
+		if err := r.Client.Get(ctx, key, secret); err != nil {
+			if apierrors.IsNotFound(err) {
+				missing = append(missing, fmt.Sprintf("Secret %s", name))
+				continue
+			}
+			return nil, errors.Wrapf(err, "failed to retrieve Secret %q", key)
+		}
+		for _, k := range keys {
+			if _, ok := secret.Data[k]; !ok {
+				missing = append(missing, fmt.Sprintf("key %s in Secret %s", k, name))
+			}
+		}
+	}
+	sort.Strings(missing)
+	return missing, nil
+}
+
+// reconcileFileReferences checks that all the Secrets and ConfigMaps referenced by the files of the KubeadmConfig
+// exist before generating the bootstrap data, and reports the missing references with the FilesAvailable condition.
+// While references are missing, the KubeadmConfig is requeued with backoff, see fileReferencesRequeueAfter; Secrets
+// and ConfigMaps are not watched, so the management cluster Secrets and ConfigMaps are not cached.
+func (r *KubeadmConfigReconciler) reconcileFileReferences(ctx context.Context, scope *Scope) (bool, error) {
+	if len(fileReferences(scope.Config)) == 0 && len(configMapFileReferences(scope.Config)) == 0 {
+		conditions.Delete(scope.Config, bootstrapv1.FilesAvailableCondition)
+		return true, nil
+	}
+
+	missing, err := r.missingFileReferences(ctx, scope.Config)
+	if err != nil {
+		return false, err
+	}
+	if len(missing) > 0 {
+		scope.Info("Waiting for the Secrets and ConfigMaps referenced by files to be created", "missing", missing)
+		conditions.MarkFalse(scope.Config, bootstrapv1.FilesAvailableCondition, bootstrapv1.MissingFileReferencesReason, clusterv1.ConditionSeverityWarning,
+			"Missing %s", strings.Join(missing, ", "))
+		return false, nil
+	}
+
+	conditions.MarkTrue(scope.Config, bootstrapv1.FilesAvailableCondition)
+	return true, nil
+}
+
+// fileReferencesRequeueAfter returns how long to wait before checking again the Secrets referenced by the files
+// of the KubeadmConfig; the delay grows with the time already spent waiting, so the checks back off exponentially.
+func fileReferencesRequeueAfter(cfg *bootstrapv1.KubeadmConfig, now time.Time) time.Duration {
+	c := conditions.Get(cfg, bootstrapv1.FilesAvailableCondition)
+	if c == nil || c.Status != corev1.ConditionFalse {
+		return fileReferencesMinRequeueAfter
+	}
+	waiting := now.Sub(c.LastTransitionTime.Time)
+	switch {
+	case waiting < fileReferencesMinRequeueAfter:
+		return fileReferencesMinRequeueAfter
+	case waiting > fileReferencesMaxRequeueAfter:
+		return fileReferencesMaxRequeueAfter
+	}
+	return waiting
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReconcileFileReferences(t *testing.T) {
+	source := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "source"},
+		Data:       map[string][]byte{"key": []byte("foo")},
+	}
+
+	newConfig := func() *bootstrapv1.KubeadmConfig {
+		config := newKubeadmConfig(nil, "cfg")
+		config.Spec.Files = []bootstrapv1.File{
+			{
+				Path:        "/path",
+				ContentFrom: &bootstrapv1.FileSource{Secret: bootstrapv1.SecretFileSource{Name: "source", Key: "key"}},
+			},
+		}
+		config.Spec.SecretDirectories = []bootstrapv1.SecretDirectory{{Path: "/etc/dir", SecretName: "dir"}}
+		return config
+	}
+
+	t.Run("does not set the condition if no Secret is referenced", func(t *testing.T) {
+		g := NewWithT(t)
+
+		config := newKubeadmConfig(nil, "cfg")
+		r := &KubeadmConfigReconciler{Client: fake.NewClientBuilder().Build()}
+
+		ok, err := r.reconcileFileReferences(ctx, &Scope{Logger: ctrl.LoggerFrom(ctx), Config: config})
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(ok).To(BeTrue())
+		g.Expect(conditions.Has(config, bootstrapv1.FilesAvailableCondition)).To(BeFalse())
+	})
+
+	t.Run("reports missing Secrets and Secret keys", func(t *testing.T) {
+		g := NewWithT(t)
+
+		config := newConfig()
+		config.Spec.Files = append(config.Spec.Files, bootstrapv1.File{
+			Path:        "/other",
+			ContentFrom: &bootstrapv1.FileSource{Secret: bootstrapv1.SecretFileSource{Name: "source", Key: "other"}},
+		})
+		r := &KubeadmConfigReconciler{Client: fake.NewClientBuilder().WithObjects(source).Build()}
+
+		ok, err := r.reconcileFileReferences(ctx, &Scope{Logger: ctrl.LoggerFrom(ctx), Config: config})
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(ok).To(BeFalse())
+		g.Expect(conditions.IsFalse(config, bootstrapv1.FilesAvailableCondition)).To(BeTrue())
+		g.Expect(conditions.GetReason(config, bootstrapv1.FilesAvailableCondition)).To(Equal(bootstrapv1.MissingFileReferencesReason))
+		g.Expect(*conditions.GetSeverity(config, bootstrapv1.FilesAvailableCondition)).To(Equal(clusterv1.ConditionSeverityWarning))
+		g.Expect(conditions.GetMessage(config, bootstrapv1.FilesAvailableCondition)).To(Equal("Missing Secret dir, key other in Secret source"))
+	})
+
+	t.Run("reports missing ConfigMaps and ConfigMap keys", func(t *testing.T) {
+		g := NewWithT(t)
+
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "config"},
+			Data:       map[string]string{"key": "foo"},
+		}
+		config := newKubeadmConfig(nil, "cfg")
+		config.Spec.Files = []bootstrapv1.File{
+			{
+				Path:        "/path",
+				ContentFrom: &bootstrapv1.FileSource{ConfigMap: &bootstrapv1.ConfigMapFileSource{Name: "config", Key: "key"}},
+			},
+			{
+				Path:        "/other",
+				ContentFrom: &bootstrapv1.FileSource{ConfigMap: &bootstrapv1.ConfigMapFileSource{Name: "config", Key: "other"}},
+			},
+			{
+				Path:        "/missing",
+				ContentFrom: &bootstrapv1.FileSource{ConfigMap: &bootstrapv1.ConfigMapFileSource{Name: "missing", Key: "key"}},
+			},
+		}
+		r := &KubeadmConfigReconciler{Client: fake.NewClientBuilder().WithObjects(configMap).Build()}
+
+		ok, err := r.reconcileFileReferences(ctx, &Scope{Logger: ctrl.LoggerFrom(ctx), Config: config})
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(ok).To(BeFalse())
+		g.Expect(conditions.GetMessage(config, bootstrapv1.FilesAvailableCondition)).To(Equal("Missing ConfigMap missing, key other in ConfigMap config"))
+	})
+
+	t.Run("marks the files as available when all the referenced Secrets exist", func(t *testing.T) {
+		g := NewWithT(t)
+
+		dir := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "dir"}}
+		config := newConfig()
+		r := &KubeadmConfigReconciler{Client: fake.NewClientBuilder().WithObjects(source, dir).Build()}
+
+		ok, err := r.reconcileFileReferences(ctx, &Scope{Logger: ctrl.LoggerFrom(ctx), Config: config})
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(ok).To(BeTrue())
+		g.Expect(conditions.IsTrue(config, bootstrapv1.FilesAvailableCondition)).To(BeTrue())
+	})
+}
+
+func TestFileReferencesRequeueAfter(t *testing.T) {
+	g := NewWithT(t)
+
+	now := time.Now()
+	g.Expect(fileReferencesRequeueAfter(newKubeadmConfig(nil, "cfg"), now)).To(Equal(fileReferencesMinRequeueAfter))
+
+	waitingSince := func(d time.Duration) *bootstrapv1.KubeadmConfig {
+		config := newKubeadmConfig(nil, "cfg")
+		conditions.Set(config, &clusterv1.Condition{
+			Type:               bootstrapv1.FilesAvailableCondition,
+			Status:             corev1.ConditionFalse,
+			LastTransitionTime: metav1.NewTime(now.Add(-d)),
+		})
+		return config
+	}
+	g.Expect(fileReferencesRequeueAfter(waitingSince(time.Second), now)).To(Equal(fileReferencesMinRequeueAfter))
+	g.Expect(fileReferencesRequeueAfter(waitingSince(time.Minute), now)).To(Equal(time.Minute))
+	g.Expect(fileReferencesRequeueAfter(waitingSince(time.Hour), now)).To(Equal(fileReferencesMaxRequeueAfter))
+}
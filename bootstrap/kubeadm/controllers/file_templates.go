@@ -0,0 +1,99 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"text/template"
+
+	"github.com/pkg/errors"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
+	bsutil "sigs.k8s.io/cluster-api/bootstrap/util"
+	"sigs.k8s.io/cluster-api/util/secret"
+)
+
+// fileTemplateData defines the variables available in the content of files when KubeadmConfigSpec.RenderFileTemplates is set.
+// NOTE: only values that are safe to be written in the bootstrap data of any machine of the cluster must be added here.
+type fileTemplateData struct {
+	// ClusterName is the name of the Cluster.
+	ClusterName string
+
+	// ControlPlaneEndpoint is the control plane endpoint of the Cluster in the host:port format;
+	// it is empty if the endpoint is not yet set.
+	ControlPlaneEndpoint string
+
+	// CACertHash is the hash of the cluster CA certificate, in the sha256:<hex> format used
+	// by kubeadm for discovery.
+	CACertHash string
+
+	// MachineName is the name of the Machine, or of the MachinePool, owning the KubeadmConfig.
+	// NOTE: the provider ID is not available, given that the bootstrap data is generated before the
+	// infrastructure provider sets it, and it is never regenerated.
+	MachineName string
+}
+
+// newFileTemplateData returns the variables available in the content of files, or nil if rendering
+// file templates is not enabled for the given KubeadmConfig.
+func newFileTemplateData(cluster *clusterv1.Cluster, config *bootstrapv1.KubeadmConfig, owner *bsutil.ConfigOwner, certificates secret.Certificates) (*fileTemplateData, error) {
+	if !config.Spec.RenderFileTemplates {
+		return nil, nil
+	}
+
+	data := &fileTemplateData{
+		ClusterName: cluster.Name,
+	}
+	if owner != nil {
+		data.MachineName = owner.GetName()
+	}
+	if cluster.Spec.ControlPlaneEndpoint.IsValid() {
+		data.ControlPlaneEndpoint = cluster.Spec.ControlPlaneEndpoint.String()
+	}
+
+	ca := certificates.GetByPurpose(secret.ClusterCA)
+	if ca == nil || ca.KeyPair == nil {
+		return nil, errors.New("failed to get the cluster CA certificate for rendering file templates")
+	}
+	hashes, err := ca.Hashes()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the cluster CA certificate hash for rendering file templates")
+	}
+	if len(hashes) > 0 {
+		data.CACertHash = hashes[0]
+	}
+	return data, nil
+}
+
+// renderFileTemplate renders the content of a file as a Go template using the given data.
+// Files with an encoding are returned unchanged, given that their content is not plain text.
+func renderFileTemplate(file bootstrapv1.File, data *fileTemplateData) (bootstrapv1.File, error) {
+	if file.Encoding != "" {
+		return file, nil
+	}
+
+	// NOTE: the template has no custom functions, so it can only access the given data.
+	tpl, err := template.New(file.Path).Option("missingkey=error").Parse(file.Content)
+	if err != nil {
+		return file, errors.Wrapf(err, "failed to parse template for file %q", file.Path)
+	}
+	var out bytes.Buffer
+	if err := tpl.Execute(&out, data); err != nil {
+		return file, errors.Wrapf(err, "failed to render template for file %q", file.Path)
+	}
+	file.Content = out.String()
+	return file, nil
+}
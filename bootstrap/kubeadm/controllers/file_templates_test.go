@@ -0,0 +1,182 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"encoding/base64"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
+	bsutil "sigs.k8s.io/cluster-api/bootstrap/util"
+	"sigs.k8s.io/cluster-api/util/secret"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestResolveFilesWithTemplates(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster := newCluster("cluster")
+	cluster.Spec.ControlPlaneEndpoint = clusterv1.APIEndpoint{Host: "example.com", Port: 6443}
+
+	certificates := secret.NewCertificatesForWorker("")
+	g.Expect(certificates.Generate()).To(Succeed())
+	hashes, err := certificates.GetByPurpose(secret.ClusterCA).Hashes()
+	g.Expect(err).NotTo(HaveOccurred())
+
+	config := newKubeadmConfig(nil, "cfg")
+	config.Spec.RenderFileTemplates = true
+	config.Spec.Files = []bootstrapv1.File{
+		{
+			Path:    "/etc/agent/config.yaml",
+			Content: "cluster: {{ .ClusterName }}\nserver: https://{{ .ControlPlaneEndpoint }}\ncaCertHash: {{ .CACertHash }}\n",
+		},
+		{
+			Path:     "/etc/agent/encoded",
+			Encoding: bootstrapv1.Base64,
+			Content:  "e3sgLkNsdXN0ZXJOYW1lIH19",
+		},
+	}
+
+	templateData, err := newFileTemplateData(cluster, config, nil, certificates)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	r := &KubeadmConfigReconciler{
+		Client: fake.NewClientBuilder().Build(),
+	}
+	files, err := r.resolveFiles(ctx, config, templateData)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(files).To(HaveLen(2))
+	g.Expect(files[0].Content).To(Equal("cluster: cluster\nserver: https://example.com:6443\ncaCertHash: " + hashes[0] + "\n"))
+	g.Expect(files[1].Content).To(Equal("e3sgLkNsdXN0ZXJOYW1lIH19"))
+
+	// The spec must not be changed by rendering.
+	g.Expect(config.Spec.Files[0].Content).To(ContainSubstring("{{ .ClusterName }}"))
+}
+
+func TestResolveFilesWithTemplatesDisabled(t *testing.T) {
+	g := NewWithT(t)
+
+	config := newKubeadmConfig(nil, "cfg")
+	config.Spec.Files = []bootstrapv1.File{
+		{
+			Path:    "/etc/agent/config.yaml",
+			Content: "cluster: {{ .ClusterName }}",
+		},
+	}
+
+	templateData, err := newFileTemplateData(newCluster("cluster"), config, nil, secret.Certificates{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(templateData).To(BeNil())
+
+	r := &KubeadmConfigReconciler{
+		Client: fake.NewClientBuilder().Build(),
+	}
+	files, err := r.resolveFiles(ctx, config, templateData)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(files).To(HaveLen(1))
+	g.Expect(files[0].Content).To(Equal("cluster: {{ .ClusterName }}"))
+}
+
+func TestResolveFilesWithSecretDirectories(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster := newCluster("cluster")
+	certificates := secret.NewCertificatesForWorker("")
+	g.Expect(certificates.Generate()).To(Succeed())
+
+	machine := newMachine(cluster, "machine")
+	ownerObj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(machine)
+	g.Expect(err).NotTo(HaveOccurred())
+	owner := &bsutil.ConfigOwner{Unstructured: &unstructured.Unstructured{Object: ownerObj}}
+
+	agentSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: "agent"},
+		Data: map[string][]byte{
+			"config.yaml": []byte("node: {{ .MachineName }}\ncluster: {{ .ClusterName }}\n"),
+			"binary":      {0xff, 0xfe},
+			"token":       []byte("secret-token"),
+		},
+	}
+
+	config := newKubeadmConfig(nil, "cfg")
+	config.Spec.RenderFileTemplates = true
+	config.Spec.SecretDirectories = []bootstrapv1.SecretDirectory{
+		{Path: "/etc/agent", Owner: "root:root", Permissions: "0600", SecretName: "agent"},
+	}
+
+	templateData, err := newFileTemplateData(cluster, config, owner, certificates)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	r := &KubeadmConfigReconciler{
+		Client: fake.NewClientBuilder().WithObjects(agentSecret).Build(),
+	}
+	files, err := r.resolveFiles(ctx, config, templateData)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(files).To(Equal([]bootstrapv1.File{
+		{Path: "/etc/agent/binary", Owner: "root:root", Permissions: "0600", Encoding: bootstrapv1.Base64, Content: base64.StdEncoding.EncodeToString([]byte{0xff, 0xfe})},
+		{Path: "/etc/agent/config.yaml", Owner: "root:root", Permissions: "0600", Content: "node: machine\ncluster: cluster\n"},
+		{Path: "/etc/agent/token", Owner: "root:root", Permissions: "0600", Content: "secret-token"},
+	}))
+
+	// Resolving files fails if the secret does not exist.
+	r.Client = fake.NewClientBuilder().Build()
+	_, err = r.resolveFiles(ctx, config, templateData)
+	g.Expect(err).To(HaveOccurred())
+
+	// Resolving files fails if a secret key is not a plain file name, so it can't escape the directory.
+	for _, key := range []string{".", ".."} {
+		r.Client = fake.NewClientBuilder().WithObjects(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: "agent"},
+			Data:       map[string][]byte{key: []byte("content")},
+		}).Build()
+		_, err = r.resolveFiles(ctx, config, templateData)
+		g.Expect(err).To(HaveOccurred())
+	}
+}
+
+func TestRenderFileTemplateWithInvalidTemplates(t *testing.T) {
+	data := &fileTemplateData{ClusterName: "cluster"}
+
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{
+			name:    "fails for templates that can't be parsed",
+			content: "{{ .ClusterName ",
+		},
+		{
+			name:    "fails for unknown variables",
+			content: "{{ .Token }}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			_, err := renderFileTemplate(bootstrapv1.File{Path: "/file", Content: tt.content}, data)
+			g.Expect(err).To(HaveOccurred())
+		})
+	}
+}
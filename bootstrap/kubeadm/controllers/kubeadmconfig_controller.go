@@ -18,9 +18,15 @@ package controllers
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"path"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/blang/semver"
 	"github.com/go-logr/logr"
@@ -35,6 +41,7 @@ import (
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
 	"sigs.k8s.io/cluster-api/bootstrap/kubeadm/internal/cloudinit"
+	"sigs.k8s.io/cluster-api/bootstrap/kubeadm/internal/ignition"
 	"sigs.k8s.io/cluster-api/bootstrap/kubeadm/internal/locking"
 	kubeadmtypes "sigs.k8s.io/cluster-api/bootstrap/kubeadm/types"
 	bsutil "sigs.k8s.io/cluster-api/bootstrap/util"
@@ -57,8 +64,60 @@ import (
 const (
 	// KubeadmConfigControllerName defines the controller used when creating clients.
 	KubeadmConfigControllerName = "kubeadmconfig-controller"
+
+	// rotateServerCertificatesKubeletArg is the kubelet flag enabling the kubelet serving certificate bootstrap.
+	rotateServerCertificatesKubeletArg = "rotate-server-certificates"
+
+	// nodeLabelsKubeletArg is the kubelet flag setting the labels to add when registering the node.
+	nodeLabelsKubeletArg = "node-labels"
+
+	// defaultGPURuntimeHandler is the containerd runtime handler used for the GPU container runtime if not specified.
+	defaultGPURuntimeHandler = "nvidia"
+
+	// defaultGPURuntimeBinaryPath is the path of the GPU container runtime binary used if not specified.
+	defaultGPURuntimeBinaryPath = "/usr/bin/nvidia-container-runtime"
+
+	// kubeadmConfigOwnerClusterIndex is used to index the Machines and MachinePools bootstrapped with a KubeadmConfig
+	// by cluster name.
+	kubeadmConfigOwnerClusterIndex = "spec.bootstrap.kubeadmConfigRef.clusterName"
+
+	// kubeadmConfigOwnerConfigRefIndex is used to index the Machines and MachinePools bootstrapped with a KubeadmConfig
+	// by the name of the KubeadmConfig.
+	kubeadmConfigOwnerConfigRefIndex = "spec.bootstrap.configRef.name"
 )
 
+// BootstrapDataGenerator generates the bootstrap data for a KubeadmConfig in a specific format.
+type BootstrapDataGenerator interface {
+	NewInitControlPlane(input *cloudinit.ControlPlaneInput) ([]byte, error)
+	NewJoinControlPlane(input *cloudinit.ControlPlaneJoinInput) ([]byte, error)
+	NewNode(input *cloudinit.NodeInput) ([]byte, error)
+	Validate(data []byte, maxSize int) error
+}
+
+// bootstrapDataGenerators defines the BootstrapDataGenerator for each of the supported bootstrap data formats.
+var bootstrapDataGenerators = map[bootstrapv1.Format]BootstrapDataGenerator{
+	bootstrapv1.CloudConfig: cloudinit.Generator{},
+	bootstrapv1.Ignition:    ignition.Generator{},
+}
+
+// bootstrapDataFormat returns the bootstrap data format of the KubeadmConfig; if the format is not set, cloud-config is used.
+func bootstrapDataFormat(config *bootstrapv1.KubeadmConfig) bootstrapv1.Format {
+	if config.Spec.Format == "" {
+		return bootstrapv1.CloudConfig
+	}
+	return config.Spec.Format
+}
+
+// bootstrapDataGeneratorFor returns the BootstrapDataGenerator for the bootstrap data format of the KubeadmConfig.
+func bootstrapDataGeneratorFor(config *bootstrapv1.KubeadmConfig) (BootstrapDataGenerator, error) {
+	format := bootstrapDataFormat(config)
+	generator, ok := bootstrapDataGenerators[format]
+	if !ok {
+		return nil, errors.Errorf("unsupported bootstrap data format %q", format)
+	}
+	return generator, nil
+}
+
 // InitLocker is a lock that is used around kubeadm init.
 type InitLocker interface {
 	Lock(ctx context.Context, cluster *clusterv1.Cluster, machine *clusterv1.Machine) bool
@@ -75,6 +134,14 @@ type KubeadmConfigReconciler struct {
 	KubeadmInitLock  InitLocker
 	WatchFilterValue string
 
+	// BootstrapDataMaxSize is the maximum size, in bytes, of the generated bootstrap data; it should be set
+	// according to the user data size limit of the infrastructure provider. The check is skipped if zero.
+	BootstrapDataMaxSize int
+
+	// Tracker provides cached clients for the workload clusters; if not set, a new client is created
+	// for every request to a workload cluster.
+	Tracker *remote.ClusterCacheTracker
+
 	remoteClientGetter remote.ClusterClientGetter
 }
 
@@ -93,6 +160,38 @@ func (r *KubeadmConfigReconciler) SetupWithManager(ctx context.Context, mgr ctrl
 	}
 	if r.remoteClientGetter == nil {
 		r.remoteClientGetter = remote.NewClusterClient
+		if r.Tracker != nil {
+			r.remoteClientGetter = func(ctx context.Context, _ string, _ client.Client, cluster client.ObjectKey) (client.Client, error) {
+				return r.Tracker.GetClient(ctx, cluster)
+			}
+		}
+	}
+
+	if err := mgr.GetFieldIndexer().IndexField(ctx, &clusterv1.Machine{},
+		kubeadmConfigOwnerClusterIndex,
+		indexMachineByKubeadmConfigCluster,
+	); err != nil {
+		return errors.Wrap(err, "error setting index fields for Machines")
+	}
+	if err := mgr.GetFieldIndexer().IndexField(ctx, &clusterv1.Machine{},
+		kubeadmConfigOwnerConfigRefIndex,
+		indexMachineByKubeadmConfigRef,
+	); err != nil {
+		return errors.Wrap(err, "error setting index fields for Machines")
+	}
+	if feature.Gates.Enabled(feature.MachinePool) {
+		if err := mgr.GetFieldIndexer().IndexField(ctx, &expv1.MachinePool{},
+			kubeadmConfigOwnerClusterIndex,
+			indexMachinePoolByKubeadmConfigCluster,
+		); err != nil {
+			return errors.Wrap(err, "error setting index fields for MachinePools")
+		}
+		if err := mgr.GetFieldIndexer().IndexField(ctx, &expv1.MachinePool{},
+			kubeadmConfigOwnerConfigRefIndex,
+			indexMachinePoolByKubeadmConfigRef,
+		); err != nil {
+			return errors.Wrap(err, "error setting index fields for MachinePools")
+		}
 	}
 
 	b := ctrl.NewControllerManagedBy(mgr).
@@ -146,7 +245,7 @@ func (r *KubeadmConfigReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 	}
 
 	// Look up the owner of this kubeadm config if there is one
-	configOwner, err := bsutil.GetConfigOwner(ctx, r.Client, config)
+	configOwner, err := r.getConfigOwner(ctx, config)
 	if apierrors.IsNotFound(err) {
 		// Could not find the owner yet, this is not an error and will rereconcile when the owner gets set.
 		return ctrl.Result{}, nil
@@ -160,6 +259,13 @@ func (r *KubeadmConfigReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 	}
 	log = log.WithValues("kind", configOwner.GetKind(), "version", configOwner.GetResourceVersion(), "name", configOwner.GetName())
 
+	// Ignore owners bootstrapped by another provider, e.g. a KubeadmConfig left behind after the owner
+	// has been switched to a different bootstrap provider; this is not an error.
+	if configRef := configOwner.BootstrapConfigRef(); configRef != nil && !isKubeadmConfigRef(configRef) {
+		log.V(4).Info("Owner is bootstrapped by another provider, ignoring", "configRef", configRef.GroupVersionKind().GroupKind().String())
+		return ctrl.Result{}, nil
+	}
+
 	// Lookup the cluster the config owner is associated with
 	cluster, err := util.GetClusterByName(ctx, r.Client, configOwner.GetNamespace(), configOwner.ClusterName())
 	if err != nil {
@@ -201,6 +307,7 @@ func (r *KubeadmConfigReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 			conditions.WithConditions(
 				bootstrapv1.DataSecretAvailableCondition,
 				bootstrapv1.CertificatesAvailableCondition,
+				bootstrapv1.FilesAvailableCondition,
 			),
 		)
 		// Patch ObservedGeneration only if the reconciliation completed successfully
@@ -230,23 +337,34 @@ func (r *KubeadmConfigReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		conditions.MarkTrue(config, bootstrapv1.DataSecretAvailableCondition)
 		return ctrl.Result{}, nil
 	// Status is ready means a config has been generated.
+	// NOTE: the bootstrap token used for joining is kept alive by the BootstrapTokenReconciler.
 	case config.Status.Ready:
 		if config.Spec.JoinConfiguration != nil && config.Spec.JoinConfiguration.Discovery.BootstrapToken != nil {
-			if !configOwner.IsInfrastructureReady() {
-				// If the BootstrapToken has been generated for a join and the infrastructure is not ready.
-				// This indicates the token in the join config has not been consumed and it may need a refresh.
-				return r.refreshBootstrapToken(ctx, config, cluster)
+			if configOwner.IsMachinePool() && config.Status.ObservedGeneration < config.Generation {
+				// If the spec of a config owned by a MachinePool changed, e.g. because the BootstrapTokenReconciler rotated
+				// the bootstrap token, the bootstrap data is generated again for future scale ups.
+				return r.joinWorker(ctx, scope)
 			}
-			if configOwner.IsMachinePool() {
-				// If the BootstrapToken has been generated and infrastructure is ready but the configOwner is a MachinePool,
-				// we rotate the token to keep it fresh for future scale ups.
-				return r.rotateMachinePoolBootstrapToken(ctx, config, cluster, scope)
+			if !configOwner.IsInfrastructureReady() && configOwner.IsControlPlaneMachine() && config.Spec.UploadCertificates {
+				// If the control plane certificates have been uploaded for a join, the kubeadm-certs Secret is deleted
+				// together with the bootstrap token it was uploaded for, so it may need to be uploaded again.
+				if err := r.refreshUploadedCertificates(ctx, scope); err != nil {
+					return ctrl.Result{}, errors.Wrapf(err, "failed to refresh the uploaded control plane certificates")
+				}
+				return ctrl.Result{RequeueAfter: DefaultTokenTTL / 2}, nil
 			}
 		}
 		// In any other case just return as the config is already generated and need not be generated again.
 		return ctrl.Result{}, nil
 	}
 
+	// Wait for the Secrets referenced by files to exist, instead of failing the bootstrap data generation.
+	if ok, err := r.reconcileFileReferences(ctx, scope); err != nil {
+		return ctrl.Result{}, err
+	} else if !ok {
+		return ctrl.Result{RequeueAfter: fileReferencesRequeueAfter(config, time.Now())}, nil
+	}
+
 	// Note: can't use IsFalse here because we need to handle the absence of the condition as well as false.
 	if !conditions.IsTrue(cluster, clusterv1.ControlPlaneInitializedCondition) {
 		return r.handleClusterNotInitialized(ctx, scope)
@@ -273,56 +391,6 @@ func (r *KubeadmConfigReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 	return r.joinWorker(ctx, scope)
 }
 
-func (r *KubeadmConfigReconciler) refreshBootstrapToken(ctx context.Context, config *bootstrapv1.KubeadmConfig, cluster *clusterv1.Cluster) (ctrl.Result, error) {
-	log := ctrl.LoggerFrom(ctx)
-	token := config.Spec.JoinConfiguration.Discovery.BootstrapToken.Token
-
-	remoteClient, err := r.remoteClientGetter(ctx, KubeadmConfigControllerName, r.Client, util.ObjectKey(cluster))
-	if err != nil {
-		log.Error(err, "Error creating remote cluster client")
-		return ctrl.Result{}, err
-	}
-
-	log.Info("Refreshing token until the infrastructure has a chance to consume it")
-	if err := refreshToken(ctx, remoteClient, token); err != nil {
-		return ctrl.Result{}, errors.Wrapf(err, "failed to refresh bootstrap token")
-	}
-	return ctrl.Result{
-		RequeueAfter: DefaultTokenTTL / 2,
-	}, nil
-}
-
-func (r *KubeadmConfigReconciler) rotateMachinePoolBootstrapToken(ctx context.Context, config *bootstrapv1.KubeadmConfig, cluster *clusterv1.Cluster, scope *Scope) (ctrl.Result, error) {
-	log := ctrl.LoggerFrom(ctx)
-	log.V(2).Info("Config is owned by a MachinePool, checking if token should be rotated")
-	remoteClient, err := r.remoteClientGetter(ctx, KubeadmConfigControllerName, r.Client, util.ObjectKey(cluster))
-	if err != nil {
-		return ctrl.Result{}, err
-	}
-
-	token := config.Spec.JoinConfiguration.Discovery.BootstrapToken.Token
-	shouldRotate, err := shouldRotate(ctx, remoteClient, token)
-	if err != nil {
-		return ctrl.Result{}, err
-	}
-	if shouldRotate {
-		log.V(2).Info("Creating new bootstrap token")
-		token, err := createToken(ctx, remoteClient)
-		if err != nil {
-			return ctrl.Result{}, errors.Wrapf(err, "failed to create new bootstrap token")
-		}
-
-		config.Spec.JoinConfiguration.Discovery.BootstrapToken.Token = token
-		log.Info("Altering JoinConfiguration.Discovery.BootstrapToken", "Token", token)
-
-		// update the bootstrap data
-		return r.joinWorker(ctx, scope)
-	}
-	return ctrl.Result{
-		RequeueAfter: DefaultTokenTTL / 3,
-	}, nil
-}
-
 func (r *KubeadmConfigReconciler) handleClusterNotInitialized(ctx context.Context, scope *Scope) (_ ctrl.Result, reterr error) {
 	// initialize the DataSecretAvailableCondition if missing.
 	// this is required in order to avoid the condition's LastTransitionTime to flicker in case of errors surfacing
@@ -385,7 +453,18 @@ func (r *KubeadmConfigReconciler) handleClusterNotInitialized(ctx context.Contex
 			},
 		}
 	}
-	initdata, err := kubeadmtypes.MarshalInitConfigurationForVersion(scope.Config.Spec.InitConfiguration, parsedVersion)
+	initConfiguration := scope.Config.Spec.InitConfiguration
+	if scope.Config.Spec.RotateKubeletServerCertificates {
+		// NOTE: the flag is added to a copy of the InitConfiguration only, so the change is not persisted in the KubeadmConfig spec.
+		initConfiguration = initConfiguration.DeepCopy()
+		setRotateServerCertificates(&initConfiguration.NodeRegistration)
+	}
+	if scope.Config.Spec.GPU != nil {
+		// NOTE: the label is added to a copy of the InitConfiguration only, so the change is not persisted in the KubeadmConfig spec.
+		initConfiguration = initConfiguration.DeepCopy()
+		setGPUNodeLabel(&initConfiguration.NodeRegistration, scope.Config.Spec.GPU)
+	}
+	initdata, err := kubeadmtypes.MarshalInitConfigurationForVersion(initConfiguration, parsedVersion)
 	if err != nil {
 		scope.Error(err, "Failed to marshal init configuration")
 		return ctrl.Result{}, err
@@ -427,17 +506,29 @@ func (r *KubeadmConfigReconciler) handleClusterNotInitialized(ctx context.Contex
 		verbosityFlag = fmt.Sprintf("--v %s", strconv.Itoa(int(*scope.Config.Spec.Verbosity)))
 	}
 
-	files, err := r.resolveFiles(ctx, scope.Config)
+	templateData, err := newFileTemplateData(scope.Cluster, scope.Config, scope.ConfigOwner, certificates)
 	if err != nil {
 		conditions.MarkFalse(scope.Config, bootstrapv1.DataSecretAvailableCondition, bootstrapv1.DataSecretGenerationFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
 		return ctrl.Result{}, err
 	}
 
-	cloudInitData, err := cloudinit.NewInitControlPlane(&cloudinit.ControlPlaneInput{
+	files, err := r.resolveFiles(ctx, scope.Config, templateData)
+	if err != nil {
+		conditions.MarkFalse(scope.Config, bootstrapv1.DataSecretAvailableCondition, bootstrapv1.DataSecretGenerationFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+		return ctrl.Result{}, err
+	}
+
+	generator, err := bootstrapDataGeneratorFor(scope.Config)
+	if err != nil {
+		conditions.MarkFalse(scope.Config, bootstrapv1.DataSecretAvailableCondition, bootstrapv1.DataSecretGenerationFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+		return ctrl.Result{}, err
+	}
+
+	bootstrapData, err := generator.NewInitControlPlane(&cloudinit.ControlPlaneInput{
 		BaseUserData: cloudinit.BaseUserData{
 			AdditionalFiles:     files,
 			NTP:                 scope.Config.Spec.NTP,
-			PreKubeadmCommands:  scope.Config.Spec.PreKubeadmCommands,
+			PreKubeadmCommands:  preKubeadmCommands(scope.Config),
 			PostKubeadmCommands: scope.Config.Spec.PostKubeadmCommands,
 			Users:               scope.Config.Spec.Users,
 			Mounts:              scope.Config.Spec.Mounts,
@@ -447,13 +538,14 @@ func (r *KubeadmConfigReconciler) handleClusterNotInitialized(ctx context.Contex
 		InitConfiguration:    initdata,
 		ClusterConfiguration: clusterdata,
 		Certificates:         certificates,
+		KubeadmSkipPhases:    skipPhasesFlag(scope.Config),
 	})
 	if err != nil {
 		scope.Error(err, "Failed to generate cloud init for bootstrap control plane")
 		return ctrl.Result{}, err
 	}
 
-	if err := r.storeBootstrapData(ctx, scope, cloudInitData); err != nil {
+	if err := r.storeBootstrapData(ctx, scope, bootstrapData); err != nil {
 		scope.Error(err, "Failed to store bootstrap data")
 		return ctrl.Result{}, err
 	}
@@ -491,7 +583,18 @@ func (r *KubeadmConfigReconciler) joinWorker(ctx context.Context, scope *Scope)
 		return ctrl.Result{}, errors.Wrapf(err, "failed to parse kubernetes version %q", kubernetesVersion)
 	}
 
-	joinData, err := kubeadmtypes.MarshalJoinConfigurationForVersion(scope.Config.Spec.JoinConfiguration, parsedVersion)
+	joinConfiguration := scope.Config.Spec.JoinConfiguration
+	if scope.Config.Spec.RotateKubeletServerCertificates {
+		// NOTE: the flag is added to a copy of the JoinConfiguration only, so the change is not persisted in the KubeadmConfig spec.
+		joinConfiguration = joinConfiguration.DeepCopy()
+		setRotateServerCertificates(&joinConfiguration.NodeRegistration)
+	}
+	if scope.Config.Spec.GPU != nil {
+		// NOTE: the label is added to a copy of the JoinConfiguration only, so the change is not persisted in the KubeadmConfig spec.
+		joinConfiguration = joinConfiguration.DeepCopy()
+		setGPUNodeLabel(&joinConfiguration.NodeRegistration, scope.Config.Spec.GPU)
+	}
+	joinData, err := kubeadmtypes.MarshalJoinConfigurationForVersion(joinConfiguration, parsedVersion)
 	if err != nil {
 		scope.Error(err, "Failed to marshal join configuration")
 		return ctrl.Result{}, err
@@ -508,17 +611,29 @@ func (r *KubeadmConfigReconciler) joinWorker(ctx context.Context, scope *Scope)
 		verbosityFlag = fmt.Sprintf("--v %s", strconv.Itoa(int(*scope.Config.Spec.Verbosity)))
 	}
 
-	files, err := r.resolveFiles(ctx, scope.Config)
+	templateData, err := newFileTemplateData(scope.Cluster, scope.Config, scope.ConfigOwner, certificates)
+	if err != nil {
+		conditions.MarkFalse(scope.Config, bootstrapv1.DataSecretAvailableCondition, bootstrapv1.DataSecretGenerationFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+		return ctrl.Result{}, err
+	}
+
+	files, err := r.resolveFiles(ctx, scope.Config, templateData)
 	if err != nil {
 		conditions.MarkFalse(scope.Config, bootstrapv1.DataSecretAvailableCondition, bootstrapv1.DataSecretGenerationFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
 		return ctrl.Result{}, err
 	}
 
-	cloudJoinData, err := cloudinit.NewNode(&cloudinit.NodeInput{
+	generator, err := bootstrapDataGeneratorFor(scope.Config)
+	if err != nil {
+		conditions.MarkFalse(scope.Config, bootstrapv1.DataSecretAvailableCondition, bootstrapv1.DataSecretGenerationFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+		return ctrl.Result{}, err
+	}
+
+	bootstrapData, err := generator.NewNode(&cloudinit.NodeInput{
 		BaseUserData: cloudinit.BaseUserData{
 			AdditionalFiles:      files,
 			NTP:                  scope.Config.Spec.NTP,
-			PreKubeadmCommands:   scope.Config.Spec.PreKubeadmCommands,
+			PreKubeadmCommands:   preKubeadmCommands(scope.Config),
 			PostKubeadmCommands:  scope.Config.Spec.PostKubeadmCommands,
 			Users:                scope.Config.Spec.Users,
 			Mounts:               scope.Config.Spec.Mounts,
@@ -533,7 +648,7 @@ func (r *KubeadmConfigReconciler) joinWorker(ctx context.Context, scope *Scope)
 		return ctrl.Result{}, err
 	}
 
-	if err := r.storeBootstrapData(ctx, scope, cloudJoinData); err != nil {
+	if err := r.storeBootstrapData(ctx, scope, bootstrapData); err != nil {
 		scope.Error(err, "Failed to store bootstrap data")
 		return ctrl.Result{}, err
 	}
@@ -578,10 +693,40 @@ func (r *KubeadmConfigReconciler) joinControlplane(ctx context.Context, scope *S
 		return ctrl.Result{}, errors.Wrapf(err, "failed to parse kubernetes version %q", kubernetesVersion)
 	}
 
-	joinData, err := kubeadmtypes.MarshalJoinConfigurationForVersion(scope.Config.Spec.JoinConfiguration, parsedVersion)
-	if err != nil {
-		scope.Error(err, "Failed to marshal join configuration")
-		return ctrl.Result{}, err
+	joinConfiguration := scope.Config.Spec.JoinConfiguration
+	if scope.Config.Spec.RotateKubeletServerCertificates {
+		// NOTE: the flag is added to a copy of the JoinConfiguration only, so the change is not persisted in the KubeadmConfig spec.
+		joinConfiguration = joinConfiguration.DeepCopy()
+		setRotateServerCertificates(&joinConfiguration.NodeRegistration)
+	}
+	if scope.Config.Spec.GPU != nil {
+		// NOTE: the label is added to a copy of the JoinConfiguration only, so the change is not persisted in the KubeadmConfig spec.
+		joinConfiguration = joinConfiguration.DeepCopy()
+		setGPUNodeLabel(&joinConfiguration.NodeRegistration, scope.Config.Spec.GPU)
+	}
+
+	// If using uploaded certificates, upload the certificates to the workload cluster and reference the certificate key
+	// in the JoinConfiguration, instead of writing the certificates in the bootstrap data.
+	certificateFiles := certificates
+	var joinData string
+	if scope.Config.Spec.UploadCertificates {
+		certificateKey, err := r.uploadControlPlaneCertificates(ctx, scope, certificates)
+		if err != nil {
+			conditions.MarkFalse(scope.Config, bootstrapv1.DataSecretAvailableCondition, bootstrapv1.DataSecretGenerationFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+			return ctrl.Result{}, err
+		}
+		certificateFiles = secret.Certificates{}
+		joinData, err = kubeadmtypes.MarshalJoinConfigurationWithCertificateKeyForVersion(joinConfiguration, certificateKey, parsedVersion)
+		if err != nil {
+			scope.Error(err, "Failed to marshal join configuration")
+			return ctrl.Result{}, err
+		}
+	} else {
+		joinData, err = kubeadmtypes.MarshalJoinConfigurationForVersion(joinConfiguration, parsedVersion)
+		if err != nil {
+			scope.Error(err, "Failed to marshal join configuration")
+			return ctrl.Result{}, err
+		}
 	}
 
 	scope.Info("Creating BootstrapData for the join control plane")
@@ -591,19 +736,31 @@ func (r *KubeadmConfigReconciler) joinControlplane(ctx context.Context, scope *S
 		verbosityFlag = fmt.Sprintf("--v %s", strconv.Itoa(int(*scope.Config.Spec.Verbosity)))
 	}
 
-	files, err := r.resolveFiles(ctx, scope.Config)
+	templateData, err := newFileTemplateData(scope.Cluster, scope.Config, scope.ConfigOwner, certificates)
 	if err != nil {
 		conditions.MarkFalse(scope.Config, bootstrapv1.DataSecretAvailableCondition, bootstrapv1.DataSecretGenerationFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
 		return ctrl.Result{}, err
 	}
 
-	cloudJoinData, err := cloudinit.NewJoinControlPlane(&cloudinit.ControlPlaneJoinInput{
+	files, err := r.resolveFiles(ctx, scope.Config, templateData)
+	if err != nil {
+		conditions.MarkFalse(scope.Config, bootstrapv1.DataSecretAvailableCondition, bootstrapv1.DataSecretGenerationFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+		return ctrl.Result{}, err
+	}
+
+	generator, err := bootstrapDataGeneratorFor(scope.Config)
+	if err != nil {
+		conditions.MarkFalse(scope.Config, bootstrapv1.DataSecretAvailableCondition, bootstrapv1.DataSecretGenerationFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+		return ctrl.Result{}, err
+	}
+
+	bootstrapData, err := generator.NewJoinControlPlane(&cloudinit.ControlPlaneJoinInput{
 		JoinConfiguration: joinData,
-		Certificates:      certificates,
+		Certificates:      certificateFiles,
 		BaseUserData: cloudinit.BaseUserData{
 			AdditionalFiles:      files,
 			NTP:                  scope.Config.Spec.NTP,
-			PreKubeadmCommands:   scope.Config.Spec.PreKubeadmCommands,
+			PreKubeadmCommands:   preKubeadmCommands(scope.Config),
 			PostKubeadmCommands:  scope.Config.Spec.PostKubeadmCommands,
 			Users:                scope.Config.Spec.Users,
 			Mounts:               scope.Config.Spec.Mounts,
@@ -617,7 +774,7 @@ func (r *KubeadmConfigReconciler) joinControlplane(ctx context.Context, scope *S
 		return ctrl.Result{}, err
 	}
 
-	if err := r.storeBootstrapData(ctx, scope, cloudJoinData); err != nil {
+	if err := r.storeBootstrapData(ctx, scope, bootstrapData); err != nil {
 		scope.Error(err, "Failed to store bootstrap data")
 		return ctrl.Result{}, err
 	}
@@ -625,29 +782,118 @@ func (r *KubeadmConfigReconciler) joinControlplane(ctx context.Context, scope *S
 	return ctrl.Result{}, nil
 }
 
+// uploadControlPlaneCertificates uploads the control plane certificates, encrypted with the certificate key of the Cluster,
+// to the kubeadm-certs Secret in the workload cluster, and it returns the certificate key.
+func (r *KubeadmConfigReconciler) uploadControlPlaneCertificates(ctx context.Context, scope *Scope, certificates secret.Certificates) (string, error) {
+	joinConfiguration := scope.Config.Spec.JoinConfiguration
+	if joinConfiguration == nil || joinConfiguration.Discovery.BootstrapToken == nil || joinConfiguration.Discovery.BootstrapToken.Token == "" {
+		return "", errors.New("uploading certificates requires a bootstrap token discovery for the joining machine")
+	}
+
+	certificateKey, err := r.lookupOrGenerateCertificateKey(ctx, scope.Cluster)
+	if err != nil {
+		return "", err
+	}
+
+	remoteClient, err := r.remoteClientGetter(ctx, KubeadmConfigControllerName, r.Client, util.ObjectKey(scope.Cluster))
+	if err != nil {
+		return "", err
+	}
+	if err := uploadCertificates(ctx, remoteClient, certificates, certificateKey, joinConfiguration.Discovery.BootstrapToken.Token); err != nil {
+		return "", errors.Wrap(err, "failed to upload the control plane certificates")
+	}
+	return certificateKey, nil
+}
+
+// refreshUploadedCertificates uploads again the control plane certificates to the workload cluster for a control plane
+// machine waiting to join, so they are available even if the kubeadm-certs Secret has been deleted in the meantime.
+func (r *KubeadmConfigReconciler) refreshUploadedCertificates(ctx context.Context, scope *Scope) error {
+	certificates := secret.NewControlPlaneJoinCerts(scope.Config.Spec.ClusterConfiguration)
+	if err := certificates.Lookup(ctx, r.Client, util.ObjectKey(scope.Cluster)); err != nil {
+		return err
+	}
+	if err := certificates.EnsureAllExist(); err != nil {
+		return err
+	}
+	_, err := r.uploadControlPlaneCertificates(ctx, scope, certificates)
+	return err
+}
+
 // resolveFiles maps .Spec.Files into cloudinit.Files, resolving any object references
-// along the way.
-func (r *KubeadmConfigReconciler) resolveFiles(ctx context.Context, cfg *bootstrapv1.KubeadmConfig) ([]bootstrapv1.File, error) {
+// along the way; if templateData is not nil, the content of the files is rendered as a template.
+func (r *KubeadmConfigReconciler) resolveFiles(ctx context.Context, cfg *bootstrapv1.KubeadmConfig, templateData *fileTemplateData) ([]bootstrapv1.File, error) {
 	collected := make([]bootstrapv1.File, 0, len(cfg.Spec.Files))
 
 	for i := range cfg.Spec.Files {
 		in := cfg.Spec.Files[i]
 		if in.ContentFrom != nil {
-			data, err := r.resolveSecretFileContent(ctx, cfg.Namespace, in)
+			data, err := r.resolveFileSourceContent(ctx, cfg.Namespace, in)
 			if err != nil {
 				return nil, errors.Wrapf(err, "failed to resolve file source")
 			}
 			in.ContentFrom = nil
 			in.Content = string(data)
 		}
+		if templateData != nil {
+			rendered, err := renderFileTemplate(in, templateData)
+			if err != nil {
+				return nil, err
+			}
+			in = rendered
+		}
 		collected = append(collected, in)
 	}
 
+	for i := range cfg.Spec.SecretDirectories {
+		files, err := r.resolveSecretDirectory(ctx, cfg.Namespace, cfg.Spec.SecretDirectories[i])
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to resolve secret directory")
+		}
+		for _, in := range files {
+			if templateData != nil {
+				rendered, err := renderFileTemplate(in, templateData)
+				if err != nil {
+					return nil, err
+				}
+				in = rendered
+			}
+			collected = append(collected, in)
+		}
+	}
+
+	if cfg.Spec.GPU != nil {
+		collected = append(collected, gpuRuntimeFile(cfg.Spec.GPU))
+	}
+
+	if len(cfg.Spec.ImagePullSecrets) > 0 {
+		file, err := r.resolveRegistryCredentialsFile(ctx, cfg)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to resolve image pull secrets")
+		}
+		collected = append(collected, file)
+	}
+
 	return collected, nil
 }
 
-// resolveSecretFileContent returns file content fetched from a referenced secret object.
-func (r *KubeadmConfigReconciler) resolveSecretFileContent(ctx context.Context, ns string, source bootstrapv1.File) ([]byte, error) {
+// resolveFileSourceContent returns file content fetched from a referenced secret or configmap object.
+func (r *KubeadmConfigReconciler) resolveFileSourceContent(ctx context.Context, ns string, source bootstrapv1.File) ([]byte, error) {
+	if source.ContentFrom.ConfigMap != nil {
+		configMap := &corev1.ConfigMap{}
+		key := types.NamespacedName{Namespace: ns, Name: source.ContentFrom.ConfigMap.Name}
+		if err := r.Client.Get(ctx, key, configMap); err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil, errors.Wrapf(err, "configmap not found: %s", key)
+			}
+			return nil, errors.Wrapf(err, "failed to retrieve ConfigMap %q", key)
+		}
+		data, ok := configMap.Data[source.ContentFrom.ConfigMap.Key]
+		if !ok {
+			return nil, errors.Errorf("configmap references non-existent configmap key: %q", source.ContentFrom.ConfigMap.Key)
+		}
+		return []byte(data), nil
+	}
+
 	secret := &corev1.Secret{}
 	key := types.NamespacedName{Namespace: ns, Name: source.ContentFrom.Secret.Name}
 	if err := r.Client.Get(ctx, key, secret); err != nil {
@@ -663,6 +909,45 @@ func (r *KubeadmConfigReconciler) resolveSecretFileContent(ctx context.Context,
 	return data, nil
 }
 
+// resolveSecretDirectory returns a file for each key of a referenced secret object, sorted by key.
+// Values that are not valid UTF-8 are base64 encoded, so binary content is preserved.
+func (r *KubeadmConfigReconciler) resolveSecretDirectory(ctx context.Context, ns string, dir bootstrapv1.SecretDirectory) ([]bootstrapv1.File, error) {
+	secret := &corev1.Secret{}
+	key := types.NamespacedName{Namespace: ns, Name: dir.SecretName}
+	if err := r.Client.Get(ctx, key, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, errors.Wrapf(err, "secret not found: %s", key)
+		}
+		return nil, errors.Wrapf(err, "failed to retrieve Secret %q", key)
+	}
+
+	keys := make([]string, 0, len(secret.Data))
+	for k := range secret.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	files := make([]bootstrapv1.File, 0, len(keys))
+	for _, k := range keys {
+		// Each key must be a plain file name, so the file can't be written outside of the directory.
+		if k == "." || k == ".." || strings.ContainsAny(k, `/\`) {
+			return nil, errors.Errorf("secret %s has key %q, which is not a valid file name", key, k)
+		}
+		file := bootstrapv1.File{
+			Path:        path.Join(dir.Path, k),
+			Owner:       dir.Owner,
+			Permissions: dir.Permissions,
+			Content:     string(secret.Data[k]),
+		}
+		if !utf8.Valid(secret.Data[k]) {
+			file.Encoding = bootstrapv1.Base64
+			file.Content = base64.StdEncoding.EncodeToString(secret.Data[k])
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
 // ClusterToKubeadmConfigs is a handler.ToRequestsFunc to be used to enqeue
 // requests for reconciliation of KubeadmConfigs.
 func (r *KubeadmConfigReconciler) ClusterToKubeadmConfigs(o client.Object) []ctrl.Request {
@@ -673,10 +958,12 @@ func (r *KubeadmConfigReconciler) ClusterToKubeadmConfigs(o client.Object) []ctr
 		panic(fmt.Sprintf("Expected a Cluster but got a %T", o))
 	}
 
+	// Only Machines and MachinePools of the cluster bootstrapped with a KubeadmConfig are indexed,
+	// so it is not required to scan all the Machines and MachinePools of the cluster.
 	selectors := []client.ListOption{
 		client.InNamespace(c.Namespace),
-		client.MatchingLabels{
-			clusterv1.ClusterLabelName: c.Name,
+		client.MatchingFields{
+			kubeadmConfigOwnerClusterIndex: c.Name,
 		},
 	}
 
@@ -686,8 +973,7 @@ func (r *KubeadmConfigReconciler) ClusterToKubeadmConfigs(o client.Object) []ctr
 	}
 
 	for _, m := range machineList.Items {
-		if m.Spec.Bootstrap.ConfigRef != nil &&
-			m.Spec.Bootstrap.ConfigRef.GroupVersionKind().GroupKind() == bootstrapv1.GroupVersion.WithKind("KubeadmConfig").GroupKind() {
+		if m.Spec.ClusterName == c.Name && isKubeadmConfigRef(m.Spec.Bootstrap.ConfigRef) {
 			name := client.ObjectKey{Namespace: m.Namespace, Name: m.Spec.Bootstrap.ConfigRef.Name}
 			result = append(result, ctrl.Request{NamespacedName: name})
 		}
@@ -700,8 +986,7 @@ func (r *KubeadmConfigReconciler) ClusterToKubeadmConfigs(o client.Object) []ctr
 		}
 
 		for _, mp := range machinePoolList.Items {
-			if mp.Spec.Template.Spec.Bootstrap.ConfigRef != nil &&
-				mp.Spec.Template.Spec.Bootstrap.ConfigRef.GroupVersionKind().GroupKind() == bootstrapv1.GroupVersion.WithKind("KubeadmConfig").GroupKind() {
+			if mp.Spec.ClusterName == c.Name && isKubeadmConfigRef(mp.Spec.Template.Spec.Bootstrap.ConfigRef) {
 				name := client.ObjectKey{Namespace: mp.Namespace, Name: mp.Spec.Template.Spec.Bootstrap.ConfigRef.Name}
 				result = append(result, ctrl.Request{NamespacedName: name})
 			}
@@ -720,7 +1005,7 @@ func (r *KubeadmConfigReconciler) MachineToBootstrapMapFunc(o client.Object) []c
 	}
 
 	result := []ctrl.Request{}
-	if m.Spec.Bootstrap.ConfigRef != nil && m.Spec.Bootstrap.ConfigRef.GroupVersionKind() == bootstrapv1.GroupVersion.WithKind("KubeadmConfig") {
+	if isKubeadmConfigRef(m.Spec.Bootstrap.ConfigRef) {
 		name := client.ObjectKey{Namespace: m.Namespace, Name: m.Spec.Bootstrap.ConfigRef.Name}
 		result = append(result, ctrl.Request{NamespacedName: name})
 	}
@@ -737,13 +1022,113 @@ func (r *KubeadmConfigReconciler) MachinePoolToBootstrapMapFunc(o client.Object)
 
 	result := []ctrl.Request{}
 	configRef := m.Spec.Template.Spec.Bootstrap.ConfigRef
-	if configRef != nil && configRef.GroupVersionKind().GroupKind() == bootstrapv1.GroupVersion.WithKind("KubeadmConfig").GroupKind() {
+	if isKubeadmConfigRef(configRef) {
 		name := client.ObjectKey{Namespace: m.Namespace, Name: configRef.Name}
 		result = append(result, ctrl.Request{NamespacedName: name})
 	}
 	return result
 }
 
+// isKubeadmConfigRef returns true if the bootstrap config reference points to a KubeadmConfig, whatever its API version.
+func isKubeadmConfigRef(ref *corev1.ObjectReference) bool {
+	return ref != nil && ref.GroupVersionKind().GroupKind() == bootstrapv1.GroupVersion.WithKind("KubeadmConfig").GroupKind()
+}
+
+func indexMachineByKubeadmConfigCluster(o client.Object) []string {
+	machine, ok := o.(*clusterv1.Machine)
+	if !ok {
+		panic(fmt.Sprintf("Expected a Machine but got a %T", o))
+	}
+	if isKubeadmConfigRef(machine.Spec.Bootstrap.ConfigRef) {
+		return []string{machine.Spec.ClusterName}
+	}
+	return nil
+}
+
+func indexMachinePoolByKubeadmConfigCluster(o client.Object) []string {
+	machinePool, ok := o.(*expv1.MachinePool)
+	if !ok {
+		panic(fmt.Sprintf("Expected a MachinePool but got a %T", o))
+	}
+	if isKubeadmConfigRef(machinePool.Spec.Template.Spec.Bootstrap.ConfigRef) {
+		return []string{machinePool.Spec.ClusterName}
+	}
+	return nil
+}
+
+func indexMachineByKubeadmConfigRef(o client.Object) []string {
+	machine, ok := o.(*clusterv1.Machine)
+	if !ok {
+		panic(fmt.Sprintf("Expected a Machine but got a %T", o))
+	}
+	if isKubeadmConfigRef(machine.Spec.Bootstrap.ConfigRef) {
+		return []string{machine.Spec.Bootstrap.ConfigRef.Name}
+	}
+	return nil
+}
+
+func indexMachinePoolByKubeadmConfigRef(o client.Object) []string {
+	machinePool, ok := o.(*expv1.MachinePool)
+	if !ok {
+		panic(fmt.Sprintf("Expected a MachinePool but got a %T", o))
+	}
+	if isKubeadmConfigRef(machinePool.Spec.Template.Spec.Bootstrap.ConfigRef) {
+		return []string{machinePool.Spec.Template.Spec.Bootstrap.ConfigRef.Name}
+	}
+	return nil
+}
+
+// getConfigOwner returns the owner of the KubeadmConfig. If the owner reference is not set yet, the Machine or the
+// MachinePool referencing the KubeadmConfig is looked up using the kubeadmConfigOwnerConfigRefIndex, so it is not
+// required to scan all the Machines and MachinePools in the namespace.
+func (r *KubeadmConfigReconciler) getConfigOwner(ctx context.Context, config *bootstrapv1.KubeadmConfig) (*bsutil.ConfigOwner, error) {
+	configOwner, err := bsutil.GetConfigOwner(ctx, r.Client, config)
+	if err != nil || configOwner != nil {
+		return configOwner, err
+	}
+
+	selectors := []client.ListOption{
+		client.InNamespace(config.Namespace),
+		client.MatchingFields{
+			kubeadmConfigOwnerConfigRefIndex: config.Name,
+		},
+	}
+
+	machineList := &clusterv1.MachineList{}
+	if err := r.Client.List(ctx, machineList, selectors...); err != nil {
+		return nil, errors.Wrapf(err, "failed to list Machines referencing KubeadmConfig %s/%s", config.Namespace, config.Name)
+	}
+	for _, m := range machineList.Items {
+		if isKubeadmConfigRef(m.Spec.Bootstrap.ConfigRef) && m.Spec.Bootstrap.ConfigRef.Name == config.Name {
+			return bsutil.GetOwnerByRef(ctx, r.Client, &corev1.ObjectReference{
+				APIVersion: clusterv1.GroupVersion.String(),
+				Kind:       "Machine",
+				Name:       m.Name,
+				Namespace:  m.Namespace,
+			})
+		}
+	}
+
+	if feature.Gates.Enabled(feature.MachinePool) {
+		machinePoolList := &expv1.MachinePoolList{}
+		if err := r.Client.List(ctx, machinePoolList, selectors...); err != nil {
+			return nil, errors.Wrapf(err, "failed to list MachinePools referencing KubeadmConfig %s/%s", config.Namespace, config.Name)
+		}
+		for _, mp := range machinePoolList.Items {
+			if isKubeadmConfigRef(mp.Spec.Template.Spec.Bootstrap.ConfigRef) && mp.Spec.Template.Spec.Bootstrap.ConfigRef.Name == config.Name {
+				return bsutil.GetOwnerByRef(ctx, r.Client, &corev1.ObjectReference{
+					APIVersion: expv1.GroupVersion.String(),
+					Kind:       "MachinePool",
+					Name:       mp.Name,
+					Namespace:  mp.Namespace,
+				})
+			}
+		}
+	}
+
+	return nil, nil
+}
+
 // reconcileDiscovery ensures that config.JoinConfiguration.Discovery is properly set for the joining node.
 // The implementation func respect user provided discovery configurations, but in case some of them are missing, a valid BootstrapToken object
 // is automatically injected into config.JoinConfiguration.Discovery.
@@ -855,18 +1240,167 @@ func (r *KubeadmConfigReconciler) reconcileTopLevelObjectSettings(ctx context.Co
 	}
 }
 
-// storeBootstrapData creates a new secret with the data passed in as input,
+// setRotateServerCertificates sets the kubelet rotate-server-certificates flag in the given NodeRegistrationOptions,
+// unless the flag is already explicitly defined by the user in KubeletExtraArgs.
+func setRotateServerCertificates(nodeRegistration *bootstrapv1.NodeRegistrationOptions) {
+	if _, ok := nodeRegistration.KubeletExtraArgs[rotateServerCertificatesKubeletArg]; ok {
+		return
+	}
+	if nodeRegistration.KubeletExtraArgs == nil {
+		nodeRegistration.KubeletExtraArgs = map[string]string{}
+	}
+	nodeRegistration.KubeletExtraArgs[rotateServerCertificatesKubeletArg] = "true"
+}
+
+// gpuRuntimeHandler returns the name of the containerd runtime handler for the GPU container runtime.
+func gpuRuntimeHandler(gpu *bootstrapv1.GPUConfiguration) string {
+	if gpu.RuntimeHandler != "" {
+		return gpu.RuntimeHandler
+	}
+	return defaultGPURuntimeHandler
+}
+
+// gpuRuntimeFile returns the containerd configuration file registering the GPU container runtime as a runtime handler.
+func gpuRuntimeFile(gpu *bootstrapv1.GPUConfiguration) bootstrapv1.File {
+	handler := gpuRuntimeHandler(gpu)
+	binaryPath := gpu.RuntimeBinaryPath
+	if binaryPath == "" {
+		binaryPath = defaultGPURuntimeBinaryPath
+	}
+	return bootstrapv1.File{
+		Path:        fmt.Sprintf("/etc/containerd/conf.d/%s-runtime.toml", handler),
+		Owner:       "root:root",
+		Permissions: "0644",
+		Content: fmt.Sprintf(`version = 2
+[plugins."io.containerd.grpc.v1.cri".containerd.runtimes.%[1]s]
+  runtime_type = "io.containerd.runc.v2"
+  [plugins."io.containerd.grpc.v1.cri".containerd.runtimes.%[1]s.options]
+    BinaryName = "%[2]s"
+`, handler, binaryPath),
+	}
+}
+
+// preKubeadmCommands returns the commands to be run before kubeadm; if GPU or ImagePullSecrets are set, containerd
+// is restarted before the user provided commands so the GPU runtime handler and the registry credentials are available.
+func preKubeadmCommands(cfg *bootstrapv1.KubeadmConfig) []string {
+	if cfg.Spec.GPU == nil && len(cfg.Spec.ImagePullSecrets) == 0 {
+		return cfg.Spec.PreKubeadmCommands
+	}
+	return append([]string{"systemctl restart containerd"}, cfg.Spec.PreKubeadmCommands...)
+}
+
+// setGPUNodeLabel adds the GPURuntimeHandlerLabel to the node-labels kubelet flag in the given NodeRegistrationOptions,
+// preserving the labels already set by the user.
+func setGPUNodeLabel(nodeRegistration *bootstrapv1.NodeRegistrationOptions, gpu *bootstrapv1.GPUConfiguration) {
+	label := fmt.Sprintf("%s=%s", bootstrapv1.GPURuntimeHandlerLabel, gpuRuntimeHandler(gpu))
+	if nodeRegistration.KubeletExtraArgs == nil {
+		nodeRegistration.KubeletExtraArgs = map[string]string{}
+	}
+	labels := nodeRegistration.KubeletExtraArgs[nodeLabelsKubeletArg]
+	for _, l := range strings.Split(labels, ",") {
+		if strings.HasPrefix(l, bootstrapv1.GPURuntimeHandlerLabel+"=") {
+			return
+		}
+	}
+	if labels != "" {
+		label = labels + "," + label
+	}
+	nodeRegistration.KubeletExtraArgs[nodeLabelsKubeletArg] = label
+}
+
+// skipPhasesFlag returns the kubeadm init flag skipping the installation of the addons
+// that the KubeadmConfig annotations ask to skip, if any.
+func skipPhasesFlag(config *bootstrapv1.KubeadmConfig) string {
+	phases := []string{}
+	if _, ok := config.Annotations[bootstrapv1.SkipKubeProxyAnnotation]; ok {
+		phases = append(phases, "addon/kube-proxy")
+	}
+	if _, ok := config.Annotations[bootstrapv1.SkipCoreDNSAnnotation]; ok {
+		phases = append(phases, "addon/coredns")
+	}
+	if len(phases) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("--skip-phases=%s", strings.Join(phases, ","))
+}
+
+// storeBootstrapData validates the data passed in as input, creates a new secret with it and its format,
 // sets the reference in the configuration status and ready to true.
+// If the data exceeds the DataSecretMaxSize, it is split across multiple secrets,
+// and the secret referenced in the configuration status lists the names of these secrets.
 func (r *KubeadmConfigReconciler) storeBootstrapData(ctx context.Context, scope *Scope, data []byte) error {
+	// Validate the bootstrap data before storing it, so invalid data fail reconciliation with a precise error
+	// instead of silently breaking the machine at boot time.
+	generator, err := bootstrapDataGeneratorFor(scope.Config)
+	if err != nil {
+		return err
+	}
+	if err := generator.Validate(data, r.BootstrapDataMaxSize); err != nil {
+		conditions.MarkFalse(scope.Config, bootstrapv1.DataSecretAvailableCondition, bootstrapv1.DataSecretGenerationFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+		return errors.Wrapf(err, "invalid bootstrap data for KubeadmConfig %s/%s", scope.Config.Namespace, scope.Config.Name)
+	}
+
+	secretData := map[string][]byte{
+		clusterv1.BootstrapDataSecretValueKey: data,
+	}
+
+	partNames := []string{}
+	if maxSize := scope.Config.Spec.DataSecretMaxSize; maxSize != nil && len(data) > int(*maxSize) {
+		partLabels := map[string]string{
+			bootstrapv1.DataSecretPartOfLabel: string(scope.Config.UID),
+		}
+		for i, part := range splitBootstrapData(data, int(*maxSize)) {
+			partName := bootstrapDataPartName(scope.Config, i)
+			if err := r.createOrUpdateBootstrapDataSecret(ctx, scope, partName, partLabels, map[string][]byte{
+				clusterv1.BootstrapDataSecretValueKey: part,
+			}); err != nil {
+				return err
+			}
+			partNames = append(partNames, partName)
+		}
+
+		parts, err := json.Marshal(partNames)
+		if err != nil {
+			return errors.Wrapf(err, "failed to marshal bootstrap data secret parts for KubeadmConfig %s/%s", scope.Config.Namespace, scope.Config.Name)
+		}
+		secretData = map[string][]byte{
+			clusterv1.BootstrapDataSecretPartsKey: parts,
+		}
+	}
+	secretData[clusterv1.BootstrapDataSecretFormatKey] = []byte(bootstrapDataFormat(scope.Config))
+
+	if err := r.createOrUpdateBootstrapDataSecret(ctx, scope, scope.Config.Name, nil, secretData); err != nil {
+		return err
+	}
+	if err := r.deleteStaleBootstrapDataParts(ctx, scope, partNames); err != nil {
+		return err
+	}
+	scope.Config.Status.DataSecretName = pointer.StringPtr(scope.Config.Name)
+	scope.Config.Status.Ready = true
+	conditions.MarkTrue(scope.Config, bootstrapv1.DataSecretAvailableCondition)
+	return nil
+}
+
+// createOrUpdateBootstrapDataSecret creates a secret owned by the KubeadmConfig with the given name, additional labels
+// and data, or updates it if it already exists; secrets controlled by another object are never updated.
+func (r *KubeadmConfigReconciler) createOrUpdateBootstrapDataSecret(ctx context.Context, scope *Scope, name string, additionalLabels map[string]string, data map[string][]byte) error {
 	log := ctrl.LoggerFrom(ctx)
 
+	labels := map[string]string{
+		clusterv1.ClusterLabelName: scope.Cluster.Name,
+	}
+	for k, v := range additionalLabels {
+		labels[k] = v
+	}
+	if len(scope.Config.Spec.ImagePullSecrets) > 0 {
+		labels[bootstrapv1.RegistryCredentialsLabel] = "true"
+	}
+
 	secret := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      scope.Config.Name,
+			Name:      name,
 			Namespace: scope.Config.Namespace,
-			Labels: map[string]string{
-				clusterv1.ClusterLabelName: scope.Cluster.Name,
-			},
+			Labels:    labels,
 			OwnerReferences: []metav1.OwnerReference{
 				{
 					APIVersion: bootstrapv1.GroupVersion.String(),
@@ -877,9 +1411,7 @@ func (r *KubeadmConfigReconciler) storeBootstrapData(ctx context.Context, scope
 				},
 			},
 		},
-		Data: map[string][]byte{
-			"value": data,
-		},
+		Data: data,
 		Type: clusterv1.ClusterSecretType,
 	}
 
@@ -889,13 +1421,59 @@ func (r *KubeadmConfigReconciler) storeBootstrapData(ctx context.Context, scope
 		if !apierrors.IsAlreadyExists(err) {
 			return errors.Wrapf(err, "failed to create bootstrap data secret for KubeadmConfig %s/%s", scope.Config.Namespace, scope.Config.Name)
 		}
+		existing := &corev1.Secret{}
+		if err := r.Client.Get(ctx, client.ObjectKeyFromObject(secret), existing); err != nil {
+			return errors.Wrapf(err, "failed to get bootstrap data secret for KubeadmConfig %s/%s", scope.Config.Namespace, scope.Config.Name)
+		}
+		if controller := metav1.GetControllerOf(existing); controller != nil && controller.UID != scope.Config.UID {
+			return errors.Errorf("failed to update bootstrap data secret for KubeadmConfig %s/%s: secret %s is controlled by another object", scope.Config.Namespace, scope.Config.Name, secret.Name)
+		}
 		log.Info("bootstrap data secret for KubeadmConfig already exists, updating", "secret", secret.Name, "KubeadmConfig", scope.Config.Name)
+		secret.ResourceVersion = existing.ResourceVersion
 		if err := r.Client.Update(ctx, secret); err != nil {
 			return errors.Wrapf(err, "failed to update bootstrap data secret for KubeadmConfig %s/%s", scope.Config.Namespace, scope.Config.Name)
 		}
 	}
-	scope.Config.Status.DataSecretName = pointer.StringPtr(secret.Name)
-	scope.Config.Status.Ready = true
-	conditions.MarkTrue(scope.Config, bootstrapv1.DataSecretAvailableCondition)
 	return nil
 }
+
+// bootstrapDataPartName returns the name of the secret storing the i-th part of the bootstrap data of a KubeadmConfig;
+// the name includes the KubeadmConfig UID, so it does not collide with the secrets of other KubeadmConfigs.
+func bootstrapDataPartName(config *bootstrapv1.KubeadmConfig, i int) string {
+	return fmt.Sprintf("%s-part-%d-%s", config.Name, i, config.UID)
+}
+
+// deleteStaleBootstrapDataParts deletes the secrets storing parts of the bootstrap data of the KubeadmConfig which
+// are not in use anymore, e.g. after the bootstrap data has shrunk or is no longer split.
+func (r *KubeadmConfigReconciler) deleteStaleBootstrapDataParts(ctx context.Context, scope *Scope, partNames []string) error {
+	secrets := &corev1.SecretList{}
+	if err := r.Client.List(ctx, secrets, client.InNamespace(scope.Config.Namespace), client.MatchingLabels{bootstrapv1.DataSecretPartOfLabel: string(scope.Config.UID)}); err != nil {
+		return errors.Wrapf(err, "failed to list bootstrap data secret parts for KubeadmConfig %s/%s", scope.Config.Namespace, scope.Config.Name)
+	}
+
+	inUse := make(map[string]bool, len(partNames))
+	for _, name := range partNames {
+		inUse[name] = true
+	}
+	for i := range secrets.Items {
+		part := &secrets.Items[i]
+		controller := metav1.GetControllerOf(part)
+		if inUse[part.Name] || controller == nil || controller.UID != scope.Config.UID {
+			continue
+		}
+		if err := r.Client.Delete(ctx, part); err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to delete stale bootstrap data secret part %s for KubeadmConfig %s/%s", part.Name, scope.Config.Namespace, scope.Config.Name)
+		}
+	}
+	return nil
+}
+
+// splitBootstrapData splits the bootstrap data into parts of at most maxSize bytes.
+func splitBootstrapData(data []byte, maxSize int) [][]byte {
+	parts := [][]byte{}
+	for len(data) > maxSize {
+		parts = append(parts, data[:maxSize])
+		data = data[maxSize:]
+	}
+	return append(parts, data)
+}
@@ -19,6 +19,7 @@ package controllers
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"reflect"
 	"testing"
@@ -26,9 +27,9 @@ import (
 
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
-	bootstrapapi "k8s.io/cluster-bootstrap/token/api"
 	"k8s.io/utils/pointer"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
@@ -37,7 +38,6 @@ import (
 	"sigs.k8s.io/cluster-api/feature"
 	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/cluster-api/util/conditions"
-	"sigs.k8s.io/cluster-api/util/patch"
 	"sigs.k8s.io/cluster-api/util/secret"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -80,6 +80,144 @@ func TestKubeadmConfigReconciler_MachineToBootstrapMapFuncReturn(t *testing.T) {
 	}
 }
 
+// MachineToBootstrapMapFunc and MachinePoolToBootstrapMapFunc ignore owners bootstrapped by another provider.
+func TestKubeadmConfigReconciler_BootstrapMapFuncsIgnoreOtherProviders(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster := newCluster("my-cluster")
+	machine := newMachine(cluster, "my-machine")
+	machine.Spec.Bootstrap.ConfigRef = &corev1.ObjectReference{
+		Kind:       "TalosConfig",
+		APIVersion: "bootstrap.cluster.x-k8s.io/v1alpha3",
+		Name:       "my-talos-config",
+	}
+	machinePool := newMachinePool(cluster, "my-machine-pool")
+	machinePool.Spec.Template.Spec.Bootstrap.ConfigRef = machine.Spec.Bootstrap.ConfigRef.DeepCopy()
+
+	reconciler := &KubeadmConfigReconciler{
+		Client: fake.NewClientBuilder().WithObjects(cluster, machine, machinePool).Build(),
+	}
+	g.Expect(reconciler.MachineToBootstrapMapFunc(machine)).To(BeEmpty())
+	g.Expect(reconciler.MachinePoolToBootstrapMapFunc(machinePool)).To(BeEmpty())
+
+	// The configRef of a KubeadmConfig is mapped whatever its API version.
+	machine.Spec.Bootstrap.ConfigRef = &corev1.ObjectReference{
+		Kind:       "KubeadmConfig",
+		APIVersion: "bootstrap.cluster.x-k8s.io/v1alpha3",
+		Name:       "my-config",
+	}
+	g.Expect(reconciler.MachineToBootstrapMapFunc(machine)).To(ConsistOf(ctrl.Request{
+		NamespacedName: client.ObjectKey{Namespace: machine.Namespace, Name: "my-config"},
+	}))
+}
+
+// Reconcile ignores KubeadmConfigs whose owner is bootstrapped by another provider.
+func TestKubeadmConfigReconciler_Reconcile_IgnoreOwnerBootstrappedByOtherProvider(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster := newCluster("cluster")
+	cluster.Status.InfrastructureReady = true
+	machine := newWorkerMachine(cluster)
+	config := newWorkerJoinKubeadmConfig(machine)
+	machine.Spec.Bootstrap.ConfigRef = &corev1.ObjectReference{
+		Kind:       "TalosConfig",
+		APIVersion: "bootstrap.cluster.x-k8s.io/v1alpha3",
+		Name:       "my-talos-config",
+	}
+
+	myclient := fake.NewClientBuilder().WithObjects(cluster, machine, config).Build()
+	k := &KubeadmConfigReconciler{
+		Client:          myclient,
+		KubeadmInitLock: &myInitLocker{},
+	}
+
+	request := ctrl.Request{
+		NamespacedName: client.ObjectKey{
+			Namespace: config.Namespace,
+			Name:      config.Name,
+		},
+	}
+	result, err := k.Reconcile(ctx, request)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result.Requeue).To(BeFalse())
+	g.Expect(result.RequeueAfter).To(Equal(time.Duration(0)))
+
+	// The KubeadmConfig is left untouched.
+	actual := &bootstrapv1.KubeadmConfig{}
+	g.Expect(myclient.Get(ctx, client.ObjectKeyFromObject(config), actual)).To(Succeed())
+	g.Expect(actual.Status.Ready).To(BeFalse())
+	g.Expect(actual.Status.Conditions).To(BeEmpty())
+}
+
+func TestIndexMachineByKubeadmConfigCluster(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster := newCluster("my-cluster")
+	machine := newMachine(cluster, "my-machine")
+	g.Expect(indexMachineByKubeadmConfigCluster(machine)).To(ConsistOf("my-cluster"))
+
+	machine.Spec.Bootstrap.ConfigRef.Kind = "TalosConfig"
+	g.Expect(indexMachineByKubeadmConfigCluster(machine)).To(BeEmpty())
+
+	machine.Spec.Bootstrap.ConfigRef = nil
+	g.Expect(indexMachineByKubeadmConfigCluster(machine)).To(BeEmpty())
+
+	machinePool := newMachinePool(cluster, "my-machine-pool")
+	g.Expect(indexMachinePoolByKubeadmConfigCluster(machinePool)).To(ConsistOf("my-cluster"))
+
+	machinePool.Spec.Template.Spec.Bootstrap.ConfigRef.Kind = "TalosConfig"
+	g.Expect(indexMachinePoolByKubeadmConfigCluster(machinePool)).To(BeEmpty())
+}
+
+func TestIndexMachineByKubeadmConfigRef(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster := newCluster("my-cluster")
+	machine := newMachine(cluster, "my-machine")
+	machine.Spec.Bootstrap.ConfigRef.Name = "my-config"
+	g.Expect(indexMachineByKubeadmConfigRef(machine)).To(ConsistOf("my-config"))
+
+	machine.Spec.Bootstrap.ConfigRef.Kind = "TalosConfig"
+	g.Expect(indexMachineByKubeadmConfigRef(machine)).To(BeEmpty())
+
+	machine.Spec.Bootstrap.ConfigRef = nil
+	g.Expect(indexMachineByKubeadmConfigRef(machine)).To(BeEmpty())
+
+	machinePool := newMachinePool(cluster, "my-machine-pool")
+	machinePool.Spec.Template.Spec.Bootstrap.ConfigRef.Name = "my-config"
+	g.Expect(indexMachinePoolByKubeadmConfigRef(machinePool)).To(ConsistOf("my-config"))
+
+	machinePool.Spec.Template.Spec.Bootstrap.ConfigRef.Kind = "TalosConfig"
+	g.Expect(indexMachinePoolByKubeadmConfigRef(machinePool)).To(BeEmpty())
+}
+
+func TestKubeadmConfigReconciler_getConfigOwner(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster := newCluster("my-cluster")
+	machine := newMachine(cluster, "my-machine")
+	config := newKubeadmConfig(machine, "my-config")
+	// The owner reference is not set yet.
+	config.OwnerReferences = nil
+	otherMachine := newMachine(cluster, "other-machine")
+	otherMachine.Spec.Bootstrap.ConfigRef.Name = "other-config"
+
+	k := &KubeadmConfigReconciler{
+		Client: fake.NewClientBuilder().WithObjects(cluster, otherMachine, machine, config).Build(),
+	}
+
+	configOwner, err := k.getConfigOwner(ctx, config)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(configOwner).NotTo(BeNil())
+	g.Expect(configOwner.GetKind()).To(Equal("Machine"))
+	g.Expect(configOwner.GetName()).To(Equal("my-machine"))
+
+	// No owner is returned if no Machine references the config.
+	configOwner, err = k.getConfigOwner(ctx, newKubeadmConfig(nil, "orphan-config"))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(configOwner).To(BeNil())
+}
+
 // Reconcile returns early if the kubeadm config is ready because it should never re-generate bootstrap data.
 func TestKubeadmConfigReconciler_Reconcile_ReturnEarlyIfKubeadmConfigIsReady(t *testing.T) {
 	g := NewWithT(t)
@@ -709,296 +847,6 @@ func TestKubeadmConfigSecretCreatedStatusNotPatched(t *testing.T) {
 	g.Expect(cfg.Status.ObservedGeneration).NotTo(BeNil())
 }
 
-func TestBootstrapTokenTTLExtension(t *testing.T) {
-	g := NewWithT(t)
-
-	cluster := newCluster("cluster")
-	cluster.Status.InfrastructureReady = true
-	conditions.MarkTrue(cluster, clusterv1.ControlPlaneInitializedCondition)
-	cluster.Spec.ControlPlaneEndpoint = clusterv1.APIEndpoint{Host: "100.105.150.1", Port: 6443}
-
-	controlPlaneInitMachine := newControlPlaneMachine(cluster, "control-plane-init-machine")
-	initConfig := newControlPlaneInitKubeadmConfig(controlPlaneInitMachine, "control-plane-init-config")
-	workerMachine := newWorkerMachine(cluster)
-	workerJoinConfig := newWorkerJoinKubeadmConfig(workerMachine)
-	controlPlaneJoinMachine := newControlPlaneMachine(cluster, "control-plane-join-machine")
-	controlPlaneJoinConfig := newControlPlaneJoinKubeadmConfig(controlPlaneJoinMachine, "control-plane-join-cfg")
-	objects := []client.Object{
-		cluster,
-		workerMachine,
-		workerJoinConfig,
-		controlPlaneJoinMachine,
-		controlPlaneJoinConfig,
-	}
-
-	objects = append(objects, createSecrets(t, cluster, initConfig)...)
-	myclient := fake.NewClientBuilder().WithObjects(objects...).Build()
-	k := &KubeadmConfigReconciler{
-		Client:             myclient,
-		KubeadmInitLock:    &myInitLocker{},
-		remoteClientGetter: fakeremote.NewClusterClient,
-	}
-	request := ctrl.Request{
-		NamespacedName: client.ObjectKey{
-			Namespace: "default",
-			Name:      "worker-join-cfg",
-		},
-	}
-	result, err := k.Reconcile(ctx, request)
-	g.Expect(err).NotTo(HaveOccurred())
-	g.Expect(result.Requeue).To(BeFalse())
-	g.Expect(result.RequeueAfter).To(Equal(time.Duration(0)))
-
-	cfg, err := getKubeadmConfig(myclient, "worker-join-cfg")
-	g.Expect(err).NotTo(HaveOccurred())
-	g.Expect(cfg.Status.Ready).To(BeTrue())
-	g.Expect(cfg.Status.DataSecretName).NotTo(BeNil())
-	g.Expect(cfg.Status.ObservedGeneration).NotTo(BeNil())
-
-	request = ctrl.Request{
-		NamespacedName: client.ObjectKey{
-			Namespace: "default",
-			Name:      "control-plane-join-cfg",
-		},
-	}
-	result, err = k.Reconcile(ctx, request)
-	g.Expect(err).NotTo(HaveOccurred())
-	g.Expect(result.Requeue).To(BeFalse())
-	g.Expect(result.RequeueAfter).To(Equal(time.Duration(0)))
-
-	cfg, err = getKubeadmConfig(myclient, "control-plane-join-cfg")
-	g.Expect(err).NotTo(HaveOccurred())
-	g.Expect(cfg.Status.Ready).To(BeTrue())
-	g.Expect(cfg.Status.DataSecretName).NotTo(BeNil())
-	g.Expect(cfg.Status.ObservedGeneration).NotTo(BeNil())
-
-	l := &corev1.SecretList{}
-	err = myclient.List(ctx, l, client.ListOption(client.InNamespace(metav1.NamespaceSystem)))
-	g.Expect(err).NotTo(HaveOccurred())
-	g.Expect(len(l.Items)).To(Equal(2))
-
-	// ensure that the token is refreshed...
-	tokenExpires := make([][]byte, len(l.Items))
-
-	for i, item := range l.Items {
-		tokenExpires[i] = item.Data[bootstrapapi.BootstrapTokenExpirationKey]
-	}
-
-	<-time.After(1 * time.Second)
-
-	for _, req := range []ctrl.Request{
-		{
-			NamespacedName: client.ObjectKey{
-				Namespace: "default",
-				Name:      "worker-join-cfg",
-			},
-		},
-		{
-			NamespacedName: client.ObjectKey{
-				Namespace: "default",
-				Name:      "control-plane-join-cfg",
-			},
-		},
-	} {
-		result, err := k.Reconcile(ctx, req)
-		g.Expect(err).NotTo(HaveOccurred())
-		g.Expect(result.RequeueAfter).NotTo(BeNumerically(">=", DefaultTokenTTL))
-	}
-
-	l = &corev1.SecretList{}
-	err = myclient.List(ctx, l, client.ListOption(client.InNamespace(metav1.NamespaceSystem)))
-	g.Expect(err).NotTo(HaveOccurred())
-	g.Expect(len(l.Items)).To(Equal(2))
-
-	for i, item := range l.Items {
-		g.Expect(bytes.Equal(tokenExpires[i], item.Data[bootstrapapi.BootstrapTokenExpirationKey])).To(BeFalse())
-		tokenExpires[i] = item.Data[bootstrapapi.BootstrapTokenExpirationKey]
-	}
-
-	// ...until the infrastructure is marked "ready"
-	patchHelper, err := patch.NewHelper(workerMachine, myclient)
-	g.Expect(err).ShouldNot(HaveOccurred())
-	workerMachine.Status.InfrastructureReady = true
-	g.Expect(patchHelper.Patch(ctx, workerMachine)).To(Succeed())
-
-	patchHelper, err = patch.NewHelper(controlPlaneJoinMachine, myclient)
-	g.Expect(err).ShouldNot(HaveOccurred())
-	controlPlaneJoinMachine.Status.InfrastructureReady = true
-	g.Expect(patchHelper.Patch(ctx, controlPlaneJoinMachine)).To(Succeed())
-
-	<-time.After(1 * time.Second)
-
-	for _, req := range []ctrl.Request{
-		{
-			NamespacedName: client.ObjectKey{
-				Namespace: "default",
-				Name:      "worker-join-cfg",
-			},
-		},
-		{
-			NamespacedName: client.ObjectKey{
-				Namespace: "default",
-				Name:      "control-plane-join-cfg",
-			},
-		},
-	} {
-		result, err := k.Reconcile(ctx, req)
-		g.Expect(err).NotTo(HaveOccurred())
-		g.Expect(result.Requeue).To(BeFalse())
-		g.Expect(result.RequeueAfter).To(Equal(time.Duration(0)))
-	}
-
-	l = &corev1.SecretList{}
-	err = myclient.List(ctx, l, client.ListOption(client.InNamespace(metav1.NamespaceSystem)))
-	g.Expect(err).NotTo(HaveOccurred())
-	g.Expect(len(l.Items)).To(Equal(2))
-
-	for i, item := range l.Items {
-		g.Expect(bytes.Equal(tokenExpires[i], item.Data[bootstrapapi.BootstrapTokenExpirationKey])).To(BeTrue())
-	}
-}
-
-func TestBootstrapTokenRotationMachinePool(t *testing.T) {
-	_ = feature.MutableGates.Set("MachinePool=true")
-	g := NewWithT(t)
-
-	cluster := newCluster("cluster")
-	cluster.Status.InfrastructureReady = true
-	conditions.MarkTrue(cluster, clusterv1.ControlPlaneInitializedCondition)
-	cluster.Spec.ControlPlaneEndpoint = clusterv1.APIEndpoint{Host: "100.105.150.1", Port: 6443}
-
-	controlPlaneInitMachine := newControlPlaneMachine(cluster, "control-plane-init-machine")
-	initConfig := newControlPlaneInitKubeadmConfig(controlPlaneInitMachine, "control-plane-init-config")
-	workerMachinePool := newWorkerMachinePool(cluster)
-	workerJoinConfig := newWorkerPoolJoinKubeadmConfig(workerMachinePool)
-	objects := []client.Object{
-		cluster,
-		workerMachinePool,
-		workerJoinConfig,
-	}
-
-	objects = append(objects, createSecrets(t, cluster, initConfig)...)
-	myclient := fake.NewClientBuilder().WithObjects(objects...).Build()
-	k := &KubeadmConfigReconciler{
-		Client:             myclient,
-		KubeadmInitLock:    &myInitLocker{},
-		remoteClientGetter: fakeremote.NewClusterClient,
-	}
-	request := ctrl.Request{
-		NamespacedName: client.ObjectKey{
-			Namespace: "default",
-			Name:      "workerpool-join-cfg",
-		},
-	}
-	result, err := k.Reconcile(ctx, request)
-	g.Expect(err).NotTo(HaveOccurred())
-	g.Expect(result.Requeue).To(BeFalse())
-	g.Expect(result.RequeueAfter).To(Equal(time.Duration(0)))
-
-	cfg, err := getKubeadmConfig(myclient, "workerpool-join-cfg")
-	g.Expect(err).NotTo(HaveOccurred())
-	g.Expect(cfg.Status.Ready).To(BeTrue())
-	g.Expect(cfg.Status.DataSecretName).NotTo(BeNil())
-	g.Expect(cfg.Status.ObservedGeneration).NotTo(BeNil())
-
-	l := &corev1.SecretList{}
-	err = myclient.List(ctx, l, client.ListOption(client.InNamespace(metav1.NamespaceSystem)))
-	g.Expect(err).NotTo(HaveOccurred())
-	g.Expect(len(l.Items)).To(Equal(1))
-
-	// ensure that the token is refreshed...
-	tokenExpires := make([][]byte, len(l.Items))
-
-	for i, item := range l.Items {
-		tokenExpires[i] = item.Data[bootstrapapi.BootstrapTokenExpirationKey]
-	}
-
-	<-time.After(1 * time.Second)
-
-	for _, req := range []ctrl.Request{
-		{
-			NamespacedName: client.ObjectKey{
-				Namespace: "default",
-				Name:      "workerpool-join-cfg",
-			},
-		},
-	} {
-		result, err := k.Reconcile(ctx, req)
-		g.Expect(err).NotTo(HaveOccurred())
-		g.Expect(result.RequeueAfter).NotTo(BeNumerically(">=", DefaultTokenTTL))
-	}
-
-	l = &corev1.SecretList{}
-	err = myclient.List(ctx, l, client.ListOption(client.InNamespace(metav1.NamespaceSystem)))
-	g.Expect(err).NotTo(HaveOccurred())
-	g.Expect(len(l.Items)).To(Equal(1))
-
-	for i, item := range l.Items {
-		g.Expect(bytes.Equal(tokenExpires[i], item.Data[bootstrapapi.BootstrapTokenExpirationKey])).To(BeFalse())
-		tokenExpires[i] = item.Data[bootstrapapi.BootstrapTokenExpirationKey]
-	}
-
-	// ...until the infrastructure is marked "ready"
-	patchHelper, err := patch.NewHelper(workerMachinePool, myclient)
-	g.Expect(err).ShouldNot(HaveOccurred())
-	workerMachinePool.Status.InfrastructureReady = true
-	g.Expect(patchHelper.Patch(ctx, workerMachinePool, patch.WithStatusObservedGeneration{})).To(Succeed())
-
-	<-time.After(1 * time.Second)
-
-	request = ctrl.Request{
-		NamespacedName: client.ObjectKey{
-			Namespace: "default",
-			Name:      "workerpool-join-cfg",
-		},
-	}
-	result, err = k.Reconcile(ctx, request)
-	g.Expect(err).NotTo(HaveOccurred())
-	g.Expect(result.RequeueAfter).To(Equal(DefaultTokenTTL / 3))
-
-	l = &corev1.SecretList{}
-	err = myclient.List(ctx, l, client.ListOption(client.InNamespace(metav1.NamespaceSystem)))
-	g.Expect(err).NotTo(HaveOccurred())
-	g.Expect(len(l.Items)).To(Equal(1))
-
-	for i, item := range l.Items {
-		g.Expect(bytes.Equal(tokenExpires[i], item.Data[bootstrapapi.BootstrapTokenExpirationKey])).To(BeTrue())
-	}
-
-	// before token expires, it should rotate it
-	tokenExpires[0] = []byte(time.Now().UTC().Add(DefaultTokenTTL / 5).Format(time.RFC3339))
-	l.Items[0].Data[bootstrapapi.BootstrapTokenExpirationKey] = tokenExpires[0]
-	err = myclient.Update(ctx, &l.Items[0])
-	g.Expect(err).NotTo(HaveOccurred())
-
-	request = ctrl.Request{
-		NamespacedName: client.ObjectKey{
-			Namespace: "default",
-			Name:      "workerpool-join-cfg",
-		},
-	}
-	result, err = k.Reconcile(ctx, request)
-	g.Expect(err).NotTo(HaveOccurred())
-	g.Expect(result.RequeueAfter).To(Equal(time.Duration(0)))
-
-	l = &corev1.SecretList{}
-	err = myclient.List(ctx, l, client.ListOption(client.InNamespace(metav1.NamespaceSystem)))
-	g.Expect(err).NotTo(HaveOccurred())
-	g.Expect(len(l.Items)).To(Equal(2))
-	foundOld := false
-	foundNew := true
-	for _, item := range l.Items {
-		if bytes.Equal(item.Data[bootstrapapi.BootstrapTokenExpirationKey], tokenExpires[0]) {
-			foundOld = true
-		} else {
-			g.Expect(string(item.Data[bootstrapapi.BootstrapTokenExpirationKey])).To(Equal(time.Now().UTC().Add(DefaultTokenTTL).Format(time.RFC3339)))
-			foundNew = true
-		}
-	}
-	g.Expect(foundOld).To(BeTrue())
-	g.Expect(foundNew).To(BeTrue())
-}
-
 // Ensure the discovery portion of the JoinConfiguration gets generated correctly.
 func TestKubeadmConfigReconciler_Reconcile_DiscoveryReconcileBehaviors(t *testing.T) {
 	k := &KubeadmConfigReconciler{
@@ -1544,6 +1392,15 @@ func TestKubeadmConfigReconciler_ResolveFiles(t *testing.T) {
 		},
 	}
 
+	testConfigMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "source",
+		},
+		Data: map[string]string{
+			"key": "bar",
+		},
+	}
+
 	cases := map[string]struct {
 		cfg     *bootstrapv1.KubeadmConfig
 		objects []client.Object
@@ -1599,19 +1456,13 @@ func TestKubeadmConfigReconciler_ResolveFiles(t *testing.T) {
 			},
 			objects: []client.Object{testSecret},
 		},
-		"multiple files should work correctly": {
+		"contentFrom a ConfigMap should convert correctly": {
 			cfg: &bootstrapv1.KubeadmConfig{
 				Spec: bootstrapv1.KubeadmConfigSpec{
 					Files: []bootstrapv1.File{
-						{
-							Content:     "bar",
-							Path:        "/bar",
-							Owner:       "root:root",
-							Permissions: "0600",
-						},
 						{
 							ContentFrom: &bootstrapv1.FileSource{
-								Secret: bootstrapv1.SecretFileSource{
+								ConfigMap: &bootstrapv1.ConfigMapFileSource{
 									Name: "source",
 									Key:  "key",
 								},
@@ -1626,7 +1477,41 @@ func TestKubeadmConfigReconciler_ResolveFiles(t *testing.T) {
 			expect: []bootstrapv1.File{
 				{
 					Content:     "bar",
-					Path:        "/bar",
+					Path:        "/path",
+					Owner:       "root:root",
+					Permissions: "0600",
+				},
+			},
+			objects: []client.Object{testConfigMap},
+		},
+		"multiple files should work correctly": {
+			cfg: &bootstrapv1.KubeadmConfig{
+				Spec: bootstrapv1.KubeadmConfigSpec{
+					Files: []bootstrapv1.File{
+						{
+							Content:     "bar",
+							Path:        "/bar",
+							Owner:       "root:root",
+							Permissions: "0600",
+						},
+						{
+							ContentFrom: &bootstrapv1.FileSource{
+								Secret: bootstrapv1.SecretFileSource{
+									Name: "source",
+									Key:  "key",
+								},
+							},
+							Path:        "/path",
+							Owner:       "root:root",
+							Permissions: "0600",
+						},
+					},
+				},
+			},
+			expect: []bootstrapv1.File{
+				{
+					Content:     "bar",
+					Path:        "/bar",
 					Owner:       "root:root",
 					Permissions: "0600",
 				},
@@ -1662,7 +1547,7 @@ func TestKubeadmConfigReconciler_ResolveFiles(t *testing.T) {
 				}
 			}
 
-			files, err := k.resolveFiles(ctx, tc.cfg)
+			files, err := k.resolveFiles(ctx, tc.cfg, nil)
 			g.Expect(err).NotTo(HaveOccurred())
 			g.Expect(files).To(Equal(tc.expect))
 			for _, file := range tc.cfg.Spec.Files {
@@ -1675,6 +1560,455 @@ func TestKubeadmConfigReconciler_ResolveFiles(t *testing.T) {
 	}
 }
 
+func TestSetRotateServerCertificates(t *testing.T) {
+	tests := []struct {
+		name             string
+		nodeRegistration bootstrapv1.NodeRegistrationOptions
+		want             map[string]string
+	}{
+		{
+			name:             "sets the flag when KubeletExtraArgs is nil",
+			nodeRegistration: bootstrapv1.NodeRegistrationOptions{},
+			want:             map[string]string{"rotate-server-certificates": "true"},
+		},
+		{
+			name: "preserves existing KubeletExtraArgs",
+			nodeRegistration: bootstrapv1.NodeRegistrationOptions{
+				KubeletExtraArgs: map[string]string{"cloud-provider": "external"},
+			},
+			want: map[string]string{"cloud-provider": "external", "rotate-server-certificates": "true"},
+		},
+		{
+			name: "respects the value set by the user",
+			nodeRegistration: bootstrapv1.NodeRegistrationOptions{
+				KubeletExtraArgs: map[string]string{"rotate-server-certificates": "false"},
+			},
+			want: map[string]string{"rotate-server-certificates": "false"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			setRotateServerCertificates(&tt.nodeRegistration)
+			g.Expect(tt.nodeRegistration.KubeletExtraArgs).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestKubeadmConfigReconciler_Reconcile_RotateKubeletServerCertificates(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster := newCluster("cluster")
+	cluster.Status.InfrastructureReady = true
+	conditions.MarkTrue(cluster, clusterv1.ControlPlaneInitializedCondition)
+	cluster.Spec.ControlPlaneEndpoint = clusterv1.APIEndpoint{Host: "100.105.150.1", Port: 6443}
+
+	workerMachine := newWorkerMachine(cluster)
+	workerJoinConfig := newWorkerJoinKubeadmConfig(workerMachine)
+	workerJoinConfig.Spec.RotateKubeletServerCertificates = true
+
+	objects := []client.Object{
+		cluster,
+		workerMachine,
+		workerJoinConfig,
+	}
+	objects = append(objects, createSecrets(t, cluster, workerJoinConfig)...)
+	myclient := fake.NewClientBuilder().WithObjects(objects...).Build()
+	k := &KubeadmConfigReconciler{
+		Client:             myclient,
+		KubeadmInitLock:    &myInitLocker{},
+		remoteClientGetter: fakeremote.NewClusterClient,
+	}
+
+	request := ctrl.Request{
+		NamespacedName: client.ObjectKey{
+			Namespace: workerJoinConfig.GetNamespace(),
+			Name:      "worker-join-cfg",
+		},
+	}
+	result, err := k.Reconcile(ctx, request)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result.Requeue).To(BeFalse())
+
+	cfg, err := getKubeadmConfig(myclient, "worker-join-cfg")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cfg.Status.Ready).To(BeTrue())
+
+	// The flag is added to the generated bootstrap data only, and not persisted in the KubeadmConfig spec.
+	g.Expect(cfg.Spec.JoinConfiguration.NodeRegistration.KubeletExtraArgs).ToNot(HaveKey("rotate-server-certificates"))
+
+	dataSecret := &corev1.Secret{}
+	g.Expect(myclient.Get(ctx, client.ObjectKey{Namespace: cfg.Namespace, Name: *cfg.Status.DataSecretName}, dataSecret)).To(Succeed())
+	g.Expect(string(dataSecret.Data["value"])).To(ContainSubstring("rotate-server-certificates: \"true\""))
+}
+
+func TestSetGPUNodeLabel(t *testing.T) {
+	tests := []struct {
+		name             string
+		nodeRegistration bootstrapv1.NodeRegistrationOptions
+		gpu              *bootstrapv1.GPUConfiguration
+		want             map[string]string
+	}{
+		{
+			name:             "sets the default runtime handler label when KubeletExtraArgs is nil",
+			nodeRegistration: bootstrapv1.NodeRegistrationOptions{},
+			gpu:              &bootstrapv1.GPUConfiguration{},
+			want:             map[string]string{"node-labels": bootstrapv1.GPURuntimeHandlerLabel + "=nvidia"},
+		},
+		{
+			name: "appends to the labels set by the user",
+			nodeRegistration: bootstrapv1.NodeRegistrationOptions{
+				KubeletExtraArgs: map[string]string{"node-labels": "foo=bar"},
+			},
+			gpu:  &bootstrapv1.GPUConfiguration{RuntimeHandler: "custom"},
+			want: map[string]string{"node-labels": "foo=bar," + bootstrapv1.GPURuntimeHandlerLabel + "=custom"},
+		},
+		{
+			name: "respects the value set by the user",
+			nodeRegistration: bootstrapv1.NodeRegistrationOptions{
+				KubeletExtraArgs: map[string]string{"node-labels": bootstrapv1.GPURuntimeHandlerLabel + "=other"},
+			},
+			gpu:  &bootstrapv1.GPUConfiguration{},
+			want: map[string]string{"node-labels": bootstrapv1.GPURuntimeHandlerLabel + "=other"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			setGPUNodeLabel(&tt.nodeRegistration, tt.gpu)
+			g.Expect(tt.nodeRegistration.KubeletExtraArgs).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestKubeadmConfigReconciler_Reconcile_GPU(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster := newCluster("cluster")
+	cluster.Status.InfrastructureReady = true
+	conditions.MarkTrue(cluster, clusterv1.ControlPlaneInitializedCondition)
+	cluster.Spec.ControlPlaneEndpoint = clusterv1.APIEndpoint{Host: "100.105.150.1", Port: 6443}
+
+	workerMachine := newWorkerMachine(cluster)
+	workerJoinConfig := newWorkerJoinKubeadmConfig(workerMachine)
+	workerJoinConfig.Spec.GPU = &bootstrapv1.GPUConfiguration{}
+	workerJoinConfig.Spec.PreKubeadmCommands = []string{"echo hello"}
+
+	objects := []client.Object{
+		cluster,
+		workerMachine,
+		workerJoinConfig,
+	}
+	objects = append(objects, createSecrets(t, cluster, workerJoinConfig)...)
+	myclient := fake.NewClientBuilder().WithObjects(objects...).Build()
+	k := &KubeadmConfigReconciler{
+		Client:             myclient,
+		KubeadmInitLock:    &myInitLocker{},
+		remoteClientGetter: fakeremote.NewClusterClient,
+	}
+
+	request := ctrl.Request{
+		NamespacedName: client.ObjectKey{
+			Namespace: workerJoinConfig.GetNamespace(),
+			Name:      "worker-join-cfg",
+		},
+	}
+	result, err := k.Reconcile(ctx, request)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result.Requeue).To(BeFalse())
+
+	cfg, err := getKubeadmConfig(myclient, "worker-join-cfg")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cfg.Status.Ready).To(BeTrue())
+
+	// The GPU configuration is added to the generated bootstrap data only, and not persisted in the KubeadmConfig spec.
+	g.Expect(cfg.Spec.JoinConfiguration.NodeRegistration.KubeletExtraArgs).ToNot(HaveKey("node-labels"))
+	g.Expect(cfg.Spec.PreKubeadmCommands).To(Equal([]string{"echo hello"}))
+
+	dataSecret := &corev1.Secret{}
+	g.Expect(myclient.Get(ctx, client.ObjectKey{Namespace: cfg.Namespace, Name: *cfg.Status.DataSecretName}, dataSecret)).To(Succeed())
+	data := string(dataSecret.Data["value"])
+	g.Expect(data).To(ContainSubstring("/etc/containerd/conf.d/nvidia-runtime.toml"))
+	g.Expect(data).To(ContainSubstring("BinaryName = \"/usr/bin/nvidia-container-runtime\""))
+	g.Expect(data).To(ContainSubstring("systemctl restart containerd"))
+	g.Expect(data).To(ContainSubstring(bootstrapv1.GPURuntimeHandlerLabel + "=nvidia"))
+}
+
+func TestSkipPhasesFlag(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        string
+	}{
+		{
+			name: "no phases to skip",
+			want: "",
+		},
+		{
+			name:        "skip kube-proxy",
+			annotations: map[string]string{bootstrapv1.SkipKubeProxyAnnotation: ""},
+			want:        "--skip-phases=addon/kube-proxy",
+		},
+		{
+			name:        "skip kube-proxy and CoreDNS",
+			annotations: map[string]string{bootstrapv1.SkipKubeProxyAnnotation: "", bootstrapv1.SkipCoreDNSAnnotation: ""},
+			want:        "--skip-phases=addon/kube-proxy,addon/coredns",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			config := &bootstrapv1.KubeadmConfig{ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations}}
+			g.Expect(skipPhasesFlag(config)).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestSplitBootstrapData(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(splitBootstrapData([]byte("abc"), 3)).To(Equal([][]byte{[]byte("abc")}))
+	g.Expect(splitBootstrapData([]byte("abcdefg"), 3)).To(Equal([][]byte{[]byte("abc"), []byte("def"), []byte("g")}))
+}
+
+func TestKubeadmConfigReconciler_Reconcile_SplitBootstrapData(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster := newCluster("cluster")
+	cluster.Status.InfrastructureReady = true
+	conditions.MarkTrue(cluster, clusterv1.ControlPlaneInitializedCondition)
+	cluster.Spec.ControlPlaneEndpoint = clusterv1.APIEndpoint{Host: "100.105.150.1", Port: 6443}
+
+	workerMachine := newWorkerMachine(cluster)
+	workerJoinConfig := newWorkerJoinKubeadmConfig(workerMachine)
+	workerJoinConfig.UID = "worker-join-cfg-uid"
+	workerJoinConfig.Spec.DataSecretMaxSize = pointer.Int32Ptr(1024)
+	workerJoinConfig.Spec.Files = []bootstrapv1.File{
+		{Path: "/etc/large-file", Content: string(bytes.Repeat([]byte("a"), 2048))},
+	}
+
+	objects := []client.Object{
+		cluster,
+		workerMachine,
+		workerJoinConfig,
+	}
+	objects = append(objects, createSecrets(t, cluster, workerJoinConfig)...)
+	myclient := fake.NewClientBuilder().WithObjects(objects...).Build()
+	k := &KubeadmConfigReconciler{
+		Client:             myclient,
+		KubeadmInitLock:    &myInitLocker{},
+		remoteClientGetter: fakeremote.NewClusterClient,
+	}
+
+	request := ctrl.Request{
+		NamespacedName: client.ObjectKey{
+			Namespace: workerJoinConfig.GetNamespace(),
+			Name:      "worker-join-cfg",
+		},
+	}
+	result, err := k.Reconcile(ctx, request)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result.Requeue).To(BeFalse())
+
+	cfg, err := getKubeadmConfig(myclient, "worker-join-cfg")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cfg.Status.Ready).To(BeTrue())
+
+	dataSecret := &corev1.Secret{}
+	g.Expect(myclient.Get(ctx, client.ObjectKey{Namespace: cfg.Namespace, Name: *cfg.Status.DataSecretName}, dataSecret)).To(Succeed())
+	g.Expect(dataSecret.Data).ToNot(HaveKey(clusterv1.BootstrapDataSecretValueKey))
+	g.Expect(dataSecret.Data).To(HaveKey(clusterv1.BootstrapDataSecretPartsKey))
+
+	partSecret := &corev1.Secret{}
+	g.Expect(myclient.Get(ctx, client.ObjectKey{Namespace: cfg.Namespace, Name: cfg.Name + "-part-0-" + string(cfg.UID)}, partSecret)).To(Succeed())
+	g.Expect(partSecret.Data[clusterv1.BootstrapDataSecretValueKey]).To(HaveLen(1024))
+	g.Expect(partSecret.Labels).To(HaveKeyWithValue(bootstrapv1.DataSecretPartOfLabel, string(cfg.UID)))
+	g.Expect(metav1.GetControllerOf(partSecret).UID).To(Equal(cfg.UID))
+
+	data, err := secret.GetBootstrapData(ctx, myclient, client.ObjectKey{Namespace: cfg.Namespace, Name: *cfg.Status.DataSecretName})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(len(data)).To(BeNumerically(">", 1024))
+	g.Expect(string(data)).To(HavePrefix("## template: jinja\n#cloud-config\n"))
+
+	// Storing bootstrap data which fits in a single secret deletes the stale parts.
+	scope := &Scope{
+		Logger:  ctrl.LoggerFrom(ctx),
+		Config:  cfg,
+		Cluster: cluster,
+	}
+	g.Expect(k.storeBootstrapData(ctx, scope, []byte("## template: jinja\n#cloud-config\n"))).To(Succeed())
+
+	g.Expect(myclient.Get(ctx, client.ObjectKey{Namespace: cfg.Namespace, Name: *cfg.Status.DataSecretName}, dataSecret)).To(Succeed())
+	g.Expect(dataSecret.Data).To(HaveKey(clusterv1.BootstrapDataSecretValueKey))
+	g.Expect(dataSecret.Data).ToNot(HaveKey(clusterv1.BootstrapDataSecretPartsKey))
+
+	parts := &corev1.SecretList{}
+	g.Expect(myclient.List(ctx, parts, client.MatchingLabels{bootstrapv1.DataSecretPartOfLabel: string(cfg.UID)})).To(Succeed())
+	g.Expect(parts.Items).To(BeEmpty())
+}
+
+func TestKubeadmConfigReconciler_StoreBootstrapData_SecretControlledByAnotherObject(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster := newCluster("cluster")
+	config := newKubeadmConfig(nil, "cfg")
+	config.UID = "cfg-uid"
+
+	// A secret with the same name as the bootstrap data secret, controlled by another object.
+	otherSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: config.Namespace,
+			Name:      config.Name,
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: bootstrapv1.GroupVersion.String(),
+					Kind:       "KubeadmConfig",
+					Name:       "other",
+					UID:        "other-uid",
+					Controller: pointer.BoolPtr(true),
+				},
+			},
+		},
+		Data: map[string][]byte{
+			clusterv1.BootstrapDataSecretValueKey: []byte("other"),
+		},
+	}
+
+	myclient := fake.NewClientBuilder().WithObjects(cluster, config, otherSecret).Build()
+	k := &KubeadmConfigReconciler{
+		Client: myclient,
+	}
+	scope := &Scope{
+		Logger:  ctrl.LoggerFrom(ctx),
+		Config:  config,
+		Cluster: cluster,
+	}
+	g.Expect(k.storeBootstrapData(ctx, scope, []byte("## template: jinja\n#cloud-config\n"))).NotTo(Succeed())
+
+	got := &corev1.Secret{}
+	g.Expect(myclient.Get(ctx, client.ObjectKeyFromObject(otherSecret), got)).To(Succeed())
+	g.Expect(got.Data[clusterv1.BootstrapDataSecretValueKey]).To(Equal([]byte("other")))
+}
+
+func TestKubeadmConfigReconciler_Reconcile_ValidateBootstrapData(t *testing.T) {
+	tests := []struct {
+		name                 string
+		files                []bootstrapv1.File
+		bootstrapDataMaxSize int
+		errString            string
+	}{
+		{
+			name:      "fails if a file is written more than once",
+			files:     []bootstrapv1.File{{Path: "/run/kubeadm/kubeadm-join-config.yaml", Content: "foo"}},
+			errString: "duplicate paths /run/kubeadm/kubeadm-join-config.yaml",
+		},
+		{
+			name:                 "fails if bootstrap data exceeds the maximum size",
+			files:                []bootstrapv1.File{{Path: "/etc/large-file", Content: string(bytes.Repeat([]byte("a"), 2048))}},
+			bootstrapDataMaxSize: 1024,
+			errString:            "exceeds the maximum size of 1024 bytes",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			cluster := newCluster("cluster")
+			cluster.Status.InfrastructureReady = true
+			conditions.MarkTrue(cluster, clusterv1.ControlPlaneInitializedCondition)
+			cluster.Spec.ControlPlaneEndpoint = clusterv1.APIEndpoint{Host: "100.105.150.1", Port: 6443}
+
+			workerMachine := newWorkerMachine(cluster)
+			workerJoinConfig := newWorkerJoinKubeadmConfig(workerMachine)
+			workerJoinConfig.Spec.Files = tt.files
+
+			objects := []client.Object{
+				cluster,
+				workerMachine,
+				workerJoinConfig,
+			}
+			objects = append(objects, createSecrets(t, cluster, workerJoinConfig)...)
+			myclient := fake.NewClientBuilder().WithObjects(objects...).Build()
+			k := &KubeadmConfigReconciler{
+				Client:               myclient,
+				KubeadmInitLock:      &myInitLocker{},
+				BootstrapDataMaxSize: tt.bootstrapDataMaxSize,
+				remoteClientGetter:   fakeremote.NewClusterClient,
+			}
+
+			request := ctrl.Request{
+				NamespacedName: client.ObjectKey{
+					Namespace: workerJoinConfig.GetNamespace(),
+					Name:      "worker-join-cfg",
+				},
+			}
+			_, err := k.Reconcile(ctx, request)
+			g.Expect(err).To(MatchError(ContainSubstring(tt.errString)))
+
+			cfg, err := getKubeadmConfig(myclient, "worker-join-cfg")
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(cfg.Status.Ready).To(BeFalse())
+			g.Expect(cfg.Status.DataSecretName).To(BeNil())
+			g.Expect(conditions.GetReason(cfg, bootstrapv1.DataSecretAvailableCondition)).To(Equal(bootstrapv1.DataSecretGenerationFailedReason))
+
+			dataSecret := &corev1.Secret{}
+			err = myclient.Get(ctx, client.ObjectKey{Namespace: cfg.Namespace, Name: cfg.Name}, dataSecret)
+			g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+	}
+}
+
+func TestKubeadmConfigReconciler_Reconcile_IgnitionFormat(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster := newCluster("cluster")
+	cluster.Status.InfrastructureReady = true
+	conditions.MarkTrue(cluster, clusterv1.ControlPlaneInitializedCondition)
+	cluster.Spec.ControlPlaneEndpoint = clusterv1.APIEndpoint{Host: "100.105.150.1", Port: 6443}
+
+	workerMachine := newWorkerMachine(cluster)
+	workerJoinConfig := newWorkerJoinKubeadmConfig(workerMachine)
+	workerJoinConfig.Spec.Format = bootstrapv1.Ignition
+
+	objects := []client.Object{
+		cluster,
+		workerMachine,
+		workerJoinConfig,
+	}
+	objects = append(objects, createSecrets(t, cluster, workerJoinConfig)...)
+	myclient := fake.NewClientBuilder().WithObjects(objects...).Build()
+	k := &KubeadmConfigReconciler{
+		Client:             myclient,
+		KubeadmInitLock:    &myInitLocker{},
+		remoteClientGetter: fakeremote.NewClusterClient,
+	}
+
+	request := ctrl.Request{
+		NamespacedName: client.ObjectKey{
+			Namespace: workerJoinConfig.GetNamespace(),
+			Name:      "worker-join-cfg",
+		},
+	}
+	result, err := k.Reconcile(ctx, request)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result.Requeue).To(BeFalse())
+
+	cfg, err := getKubeadmConfig(myclient, "worker-join-cfg")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cfg.Status.Ready).To(BeTrue())
+
+	dataSecret := &corev1.Secret{}
+	g.Expect(myclient.Get(ctx, client.ObjectKey{Namespace: cfg.Namespace, Name: *cfg.Status.DataSecretName}, dataSecret)).To(Succeed())
+	g.Expect(dataSecret.Data[clusterv1.BootstrapDataSecretFormatKey]).To(Equal([]byte(bootstrapv1.Ignition)))
+
+	ignitionConfig := map[string]interface{}{}
+	g.Expect(json.Unmarshal(dataSecret.Data[clusterv1.BootstrapDataSecretValueKey], &ignitionConfig)).To(Succeed())
+	g.Expect(ignitionConfig).To(HaveKey("ignition"))
+}
+
 // test utils
 
 // newCluster return a CAPI cluster object.
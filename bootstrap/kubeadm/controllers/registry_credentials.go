@@ -0,0 +1,144 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
+)
+
+const (
+	// registryCredentialsFilePath is the containerd configuration file storing the image registry credentials.
+	registryCredentialsFilePath = "/etc/containerd/conf.d/registry-credentials.toml"
+)
+
+// dockerConfigJSON is the content of the .dockerconfigjson key of a Secret of type kubernetes.io/dockerconfigjson.
+type dockerConfigJSON struct {
+	Auths map[string]dockerConfigEntry `json:"auths"`
+}
+
+// dockerConfigEntry holds the credentials for a single image registry.
+type dockerConfigEntry struct {
+	Username      string `json:"username,omitempty"`
+	Password      string `json:"password,omitempty"`
+	Auth          string `json:"auth,omitempty"`
+	IdentityToken string `json:"identitytoken,omitempty"`
+}
+
+// resolveRegistryCredentialsFile returns the containerd configuration file with the credentials read from the
+// image pull secrets referenced by the KubeadmConfig. If the same registry is defined in more than one secret,
+// the credentials from the last secret are used.
+func (r *KubeadmConfigReconciler) resolveRegistryCredentialsFile(ctx context.Context, cfg *bootstrapv1.KubeadmConfig) (bootstrapv1.File, error) {
+	auths := map[string]dockerConfigEntry{}
+	for _, ref := range cfg.Spec.ImagePullSecrets {
+		secret := &corev1.Secret{}
+		key := types.NamespacedName{Namespace: cfg.Namespace, Name: ref.Name}
+		if err := r.Client.Get(ctx, key, secret); err != nil {
+			if apierrors.IsNotFound(err) {
+				return bootstrapv1.File{}, errors.Wrapf(err, "secret not found: %s", key)
+			}
+			return bootstrapv1.File{}, errors.Wrapf(err, "failed to retrieve Secret %q", key)
+		}
+		if secret.Type != corev1.SecretTypeDockerConfigJson {
+			return bootstrapv1.File{}, errors.Errorf("secret %q must be of type %s", key, corev1.SecretTypeDockerConfigJson)
+		}
+
+		config := &dockerConfigJSON{}
+		if err := json.Unmarshal(secret.Data[corev1.DockerConfigJsonKey], config); err != nil {
+			return bootstrapv1.File{}, errors.Wrapf(err, "failed to parse the %s key of Secret %q", corev1.DockerConfigJsonKey, key)
+		}
+		for registry, entry := range config.Auths {
+			auths[registryHost(registry)] = entry
+		}
+	}
+
+	content, err := registryCredentialsConfig(auths)
+	if err != nil {
+		return bootstrapv1.File{}, err
+	}
+	return bootstrapv1.File{
+		Path:        registryCredentialsFilePath,
+		Owner:       "root:root",
+		Permissions: "0600",
+		Content:     content,
+	}, nil
+}
+
+// registryHost returns the host of a registry as defined in a docker config file, which could be
+// a URL like https://registry.example.com/v1/.
+func registryHost(registry string) string {
+	host := strings.TrimPrefix(strings.TrimPrefix(registry, "https://"), "http://")
+	if i := strings.Index(host, "/"); i >= 0 {
+		host = host[:i]
+	}
+	return host
+}
+
+// registryCredentialsConfig renders the containerd CRI registry configuration for the given credentials.
+func registryCredentialsConfig(auths map[string]dockerConfigEntry) (string, error) {
+	hosts := make([]string, 0, len(auths))
+	for host := range auths {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+
+	var b strings.Builder
+	b.WriteString("version = 2\n")
+	for _, host := range hosts {
+		entry := auths[host]
+		quotedHost, err := tomlString(host)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, "[plugins.\"io.containerd.grpc.v1.cri\".registry.configs.%s.auth]\n", quotedHost)
+		for _, field := range []struct{ name, value string }{
+			{"username", entry.Username},
+			{"password", entry.Password},
+			{"auth", entry.Auth},
+			{"identitytoken", entry.IdentityToken},
+		} {
+			if field.value == "" {
+				continue
+			}
+			value, err := tomlString(field.value)
+			if err != nil {
+				return "", err
+			}
+			fmt.Fprintf(&b, "  %s = %s\n", field.name, value)
+		}
+	}
+	return b.String(), nil
+}
+
+// tomlString returns s as a TOML basic string; the JSON string escaping is a subset of the TOML one,
+// so credentials containing quotes or control characters cannot break the configuration file.
+func tomlString(s string) (string, error) {
+	quoted, err := json.Marshal(s)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to quote string")
+	}
+	return string(quoted), nil
+}
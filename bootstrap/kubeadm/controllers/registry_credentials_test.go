@@ -0,0 +1,84 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestResolveRegistryCredentialsFile(t *testing.T) {
+	g := NewWithT(t)
+
+	first := newImagePullSecret("first", `{"auths":{"https://registry.example.com/v1/":{"username":"foo","password":"b\"ar"}}}`)
+	second := newImagePullSecret("second", `{"auths":{"other.example.com:5000":{"auth":"Zm9vOmJhcg=="}}}`)
+	config := newKubeadmConfig(nil, "cfg")
+	config.Spec.ImagePullSecrets = []bootstrapv1.ImagePullSecret{{Name: "first"}, {Name: "second"}}
+
+	r := &KubeadmConfigReconciler{
+		Client: fake.NewClientBuilder().WithObjects(first, second).Build(),
+	}
+
+	file, err := r.resolveRegistryCredentialsFile(ctx, config)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(file.Path).To(Equal(registryCredentialsFilePath))
+	g.Expect(file.Permissions).To(Equal("0600"))
+	g.Expect(file.Content).To(Equal(`version = 2
+[plugins."io.containerd.grpc.v1.cri".registry.configs."other.example.com:5000".auth]
+  auth = "Zm9vOmJhcg=="
+[plugins."io.containerd.grpc.v1.cri".registry.configs."registry.example.com".auth]
+  username = "foo"
+  password = "b\"ar"
+`))
+}
+
+func TestResolveRegistryCredentialsFileWithInvalidSecrets(t *testing.T) {
+	g := NewWithT(t)
+
+	opaque := newImagePullSecret("opaque", `{"auths":{}}`)
+	opaque.Type = corev1.SecretTypeOpaque
+	malformed := newImagePullSecret("malformed", "not-json")
+
+	r := &KubeadmConfigReconciler{
+		Client: fake.NewClientBuilder().WithObjects(opaque, malformed).Build(),
+	}
+
+	for _, name := range []string{"opaque", "malformed", "missing"} {
+		config := newKubeadmConfig(nil, "cfg")
+		config.Spec.ImagePullSecrets = []bootstrapv1.ImagePullSecret{{Name: name}}
+		_, err := r.resolveRegistryCredentialsFile(ctx, config)
+		g.Expect(err).To(HaveOccurred(), name)
+	}
+}
+
+func newImagePullSecret(name, dockerConfig string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      name,
+		},
+		Type: corev1.SecretTypeDockerConfigJson,
+		Data: map[string][]byte{
+			corev1.DockerConfigJsonKey: []byte(dockerConfig),
+		},
+	}
+}
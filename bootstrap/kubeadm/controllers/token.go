@@ -91,27 +91,31 @@ func getToken(ctx context.Context, c client.Client, token string) (*corev1.Secre
 	return secret, nil
 }
 
-// refreshToken extends the TTL for an existing token.
-func refreshToken(ctx context.Context, c client.Client, token string) error {
+// refreshToken extends the TTL for an existing token, returning the new expiration time.
+func refreshToken(ctx context.Context, c client.Client, token string) (time.Time, error) {
 	secret, err := getToken(ctx, c, token)
 	if err != nil {
-		return err
+		return time.Time{}, err
 	}
-	secret.Data[bootstrapapi.BootstrapTokenExpirationKey] = []byte(time.Now().UTC().Add(DefaultTokenTTL).Format(time.RFC3339))
+	expiration := time.Now().UTC().Add(DefaultTokenTTL).Truncate(time.Second)
+	secret.Data[bootstrapapi.BootstrapTokenExpirationKey] = []byte(expiration.Format(time.RFC3339))
 
-	return c.Update(ctx, secret)
+	if err := c.Update(ctx, secret); err != nil {
+		return time.Time{}, err
+	}
+	return expiration, nil
 }
 
-// shouldRotate returns true if an existing token is past half of its TTL and should to be rotated.
-func shouldRotate(ctx context.Context, c client.Client, token string) (bool, error) {
+// getTokenExpiration returns the expiration time of an existing token.
+func getTokenExpiration(ctx context.Context, c client.Client, token string) (time.Time, error) {
 	secret, err := getToken(ctx, c, token)
 	if err != nil {
-		return false, err
+		return time.Time{}, err
 	}
+	return time.Parse(time.RFC3339, string(secret.Data[bootstrapapi.BootstrapTokenExpirationKey]))
+}
 
-	expiration, err := time.Parse(time.RFC3339, string(secret.Data[bootstrapapi.BootstrapTokenExpirationKey]))
-	if err != nil {
-		return false, err
-	}
-	return expiration.Before(time.Now().UTC().Add(DefaultTokenTTL / 2)), nil
+// shouldRenew returns true if a token expiring at the given time is past half of its TTL and should be refreshed or rotated.
+func shouldRenew(expiration time.Time) bool {
+	return expiration.Before(time.Now().UTC().Add(DefaultTokenTTL / 2))
 }
@@ -57,6 +57,29 @@ type BaseUserData struct {
 	SentinelFileCommand  string
 }
 
+// Generator generates bootstrap data in the cloud-config format.
+type Generator struct{}
+
+// NewInitControlPlane returns the cloud-config user data to be used on the first control plane instance.
+func (Generator) NewInitControlPlane(input *ControlPlaneInput) ([]byte, error) {
+	return NewInitControlPlane(input)
+}
+
+// NewJoinControlPlane returns the cloud-config user data to be used on a new control plane instance.
+func (Generator) NewJoinControlPlane(input *ControlPlaneJoinInput) ([]byte, error) {
+	return NewJoinControlPlane(input)
+}
+
+// NewNode returns the cloud-config user data to be used on a node instance.
+func (Generator) NewNode(input *NodeInput) ([]byte, error) {
+	return NewNode(input)
+}
+
+// Validate checks that the cloud-config user data will be accepted by cloud-init at boot time.
+func (Generator) Validate(userData []byte, maxSize int) error {
+	return Validate(userData, maxSize)
+}
+
 func (input *BaseUserData) prepare() error {
 	input.Header = cloudConfigHeader
 	input.WriteFiles = append(input.WriteFiles, input.AdditionalFiles...)
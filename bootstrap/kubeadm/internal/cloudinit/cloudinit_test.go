@@ -116,6 +116,25 @@ func TestNewInitControlPlaneCommands(t *testing.T) {
 	}
 }
 
+func TestNewInitControlPlaneSkipPhases(t *testing.T) {
+	g := NewWithT(t)
+
+	cpinput := &ControlPlaneInput{
+		BaseUserData: BaseUserData{
+			Header:           "test",
+			KubeadmVerbosity: "--v 5",
+		},
+		Certificates:         secret.Certificates{},
+		ClusterConfiguration: "my-cluster-config",
+		InitConfiguration:    "my-init-config",
+		KubeadmSkipPhases:    "--skip-phases=addon/kube-proxy",
+	}
+
+	out, err := NewInitControlPlane(cpinput)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(out)).To(ContainSubstring("kubeadm init --config /run/kubeadm/kubeadm.yaml --v 5 --skip-phases=addon/kube-proxy && "))
+}
+
 func TestNewInitControlPlaneDiskMounts(t *testing.T) {
 	g := NewWithT(t)
 
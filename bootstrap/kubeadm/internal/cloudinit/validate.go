@@ -0,0 +1,62 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudinit
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+// Validate checks that the generated cloud-init user data will be accepted by cloud-init at boot time;
+// the user data must be a valid YAML document, must not exceed maxSize bytes (the check is skipped if maxSize
+// is zero or negative), and must not write the same path more than once.
+func Validate(userData []byte, maxSize int) error {
+	if maxSize > 0 && len(userData) > maxSize {
+		return errors.Errorf("cloud-init user data size is %d bytes, which exceeds the maximum size of %d bytes", len(userData), maxSize)
+	}
+
+	var cloudConfig struct {
+		WriteFiles []struct {
+			Path string `json:"path"`
+		} `json:"write_files"`
+	}
+	if err := yaml.Unmarshal(userData, &cloudConfig); err != nil {
+		return errors.Wrap(err, "cloud-init user data is not a valid YAML document")
+	}
+
+	paths := map[string]int{}
+	for i, f := range cloudConfig.WriteFiles {
+		if f.Path == "" {
+			return errors.Errorf("cloud-init user data is invalid: write_files[%d] has an empty path", i)
+		}
+		paths[f.Path]++
+	}
+	duplicates := []string{}
+	for path, count := range paths {
+		if count > 1 {
+			duplicates = append(duplicates, path)
+		}
+	}
+	if len(duplicates) > 0 {
+		sort.Strings(duplicates)
+		return errors.Errorf("cloud-init user data is invalid: write_files contains duplicate paths %s", strings.Join(duplicates, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudinit
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		userData  []byte
+		maxSize   int
+		wantErr   bool
+		errString string
+	}{
+		{
+			name:     "valid user data",
+			userData: []byte(cloudConfigHeader + "write_files:\n-   path: /etc/foo\n    content: foo\n-   path: /etc/bar\n    content: bar\n"),
+		},
+		{
+			name:     "valid user data within the maximum size",
+			userData: []byte(cloudConfigHeader + "runcmd:\n  - echo foo\n"),
+			maxSize:  1024,
+		},
+		{
+			name:      "user data exceeding the maximum size",
+			userData:  []byte(cloudConfigHeader + "runcmd:\n  - echo foo\n"),
+			maxSize:   16,
+			wantErr:   true,
+			errString: "exceeds the maximum size of 16 bytes",
+		},
+		{
+			name:      "invalid YAML",
+			userData:  []byte(cloudConfigHeader + "runcmd:\n  - echo foo\n bar: [\n"),
+			wantErr:   true,
+			errString: "not a valid YAML document",
+		},
+		{
+			name:      "write_files with an empty path",
+			userData:  []byte(cloudConfigHeader + "write_files:\n-   content: foo\n"),
+			wantErr:   true,
+			errString: "write_files[0] has an empty path",
+		},
+		{
+			name:      "write_files with duplicate paths",
+			userData:  []byte(cloudConfigHeader + "write_files:\n-   path: /etc/foo\n-   path: /etc/bar\n-   path: /etc/foo\n"),
+			wantErr:   true,
+			errString: "duplicate paths /etc/foo",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			err := Validate(tt.userData, tt.maxSize)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring(tt.errString))
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+		})
+	}
+}
+
+func TestValidateGeneratedUserData(t *testing.T) {
+	g := NewWithT(t)
+
+	out, err := NewNode(&NodeInput{
+		BaseUserData: BaseUserData{
+			AdditionalFiles: []bootstrapv1.File{
+				{Path: "/etc/foo.conf", Content: "foo"},
+			},
+		},
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(Validate(out, 0)).To(Succeed())
+
+	out, err = NewNode(&NodeInput{
+		BaseUserData: BaseUserData{
+			AdditionalFiles: []bootstrapv1.File{
+				{Path: "/run/kubeadm/kubeadm-join-config.yaml", Content: "foo"},
+			},
+		},
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(Validate(out, 0)).To(MatchError(ContainSubstring("duplicate paths /run/kubeadm/kubeadm-join-config.yaml")))
+}
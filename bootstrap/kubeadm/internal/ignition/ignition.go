@@ -0,0 +1,270 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ignition
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/bootstrap/kubeadm/internal/cloudinit"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// specVersion is the version of the Ignition config specification used for the generated bootstrap data.
+	specVersion = "3.1.0"
+
+	// kubeadmScriptPath is the path of the script running the pre kubeadm commands, the kubeadm command
+	// and the post kubeadm commands.
+	kubeadmScriptPath = "/etc/kubeadm.sh"
+
+	// kubeadmUnitName is the name of the systemd unit running the kubeadm script at boot time.
+	kubeadmUnitName = "kubeadm.service"
+
+	kubeadmUnit = `[Unit]
+Description=kubeadm
+# Run only once; kubelet.conf is created by both kubeadm init and kubeadm join.
+ConditionPathExists=!/etc/kubernetes/kubelet.conf
+Wants=network-online.target
+After=network-online.target
+
+[Service]
+Type=oneshot
+ExecStart=` + kubeadmScriptPath + `
+
+[Install]
+WantedBy=multi-user.target
+`
+)
+
+// Generator generates bootstrap data in the Ignition format.
+//
+// The bootstrap data is generated by converting the cloud-config generated for the same input, so the files
+// and the commands are the same for both formats; the commands are run by a systemd unit at boot time.
+// NOTE: disk setup, mounts and NTP are not supported.
+type Generator struct{}
+
+// NewInitControlPlane returns the Ignition config to be used on the first control plane instance.
+func (Generator) NewInitControlPlane(input *cloudinit.ControlPlaneInput) ([]byte, error) {
+	userData, err := cloudinit.NewInitControlPlane(input)
+	if err != nil {
+		return nil, err
+	}
+	return convert(userData)
+}
+
+// NewJoinControlPlane returns the Ignition config to be used on a new control plane instance.
+func (Generator) NewJoinControlPlane(input *cloudinit.ControlPlaneJoinInput) ([]byte, error) {
+	userData, err := cloudinit.NewJoinControlPlane(input)
+	if err != nil {
+		return nil, err
+	}
+	return convert(userData)
+}
+
+// NewNode returns the Ignition config to be used on a node instance.
+func (Generator) NewNode(input *cloudinit.NodeInput) ([]byte, error) {
+	userData, err := cloudinit.NewNode(input)
+	if err != nil {
+		return nil, err
+	}
+	return convert(userData)
+}
+
+// Validate checks that the Ignition config will be accepted by Ignition at boot time; the config must be
+// a valid JSON document, must not exceed maxSize bytes (the check is skipped if maxSize is zero or negative),
+// and must not write the same path more than once.
+func (Generator) Validate(userData []byte, maxSize int) error {
+	if maxSize > 0 && len(userData) > maxSize {
+		return errors.Errorf("Ignition config size is %d bytes, which exceeds the maximum size of %d bytes", len(userData), maxSize)
+	}
+
+	config := &Config{}
+	if err := json.Unmarshal(userData, config); err != nil {
+		return errors.Wrap(err, "Ignition config is not a valid JSON document")
+	}
+	if config.Ignition.Version != specVersion {
+		return errors.Errorf("Ignition config is invalid: unsupported version %q", config.Ignition.Version)
+	}
+
+	paths := map[string]int{}
+	for i, f := range config.Storage.Files {
+		if f.Path == "" {
+			return errors.Errorf("Ignition config is invalid: storage.files[%d] has an empty path", i)
+		}
+		paths[f.Path]++
+	}
+	duplicates := []string{}
+	for path, count := range paths {
+		if count > 1 {
+			duplicates = append(duplicates, path)
+		}
+	}
+	if len(duplicates) > 0 {
+		sort.Strings(duplicates)
+		return errors.Errorf("Ignition config is invalid: storage.files contains duplicate paths %s", strings.Join(duplicates, ", "))
+	}
+	return nil
+}
+
+// cloudConfig defines the subset of the cloud-config generated by the cloudinit package that can be
+// converted to Ignition.
+type cloudConfig struct {
+	WriteFiles []struct {
+		Path        string `json:"path"`
+		Owner       string `json:"owner"`
+		Permissions string `json:"permissions"`
+		Encoding    string `json:"encoding"`
+		Content     string `json:"content"`
+	} `json:"write_files"`
+	RunCmd []string `json:"runcmd"`
+	Users  []struct {
+		Name              string   `json:"name"`
+		Passwd            string   `json:"passwd"`
+		Gecos             string   `json:"gecos"`
+		Groups            string   `json:"groups"`
+		HomeDir           string   `json:"homedir"`
+		Shell             string   `json:"shell"`
+		PrimaryGroup      string   `json:"primary_group"`
+		Sudo              string   `json:"sudo"`
+		SSHAuthorizedKeys []string `json:"ssh_authorized_keys"`
+	} `json:"users"`
+
+	// Unsupported cloud-config modules.
+	NTP       interface{} `json:"ntp"`
+	DiskSetup interface{} `json:"disk_setup"`
+	FSSetup   interface{} `json:"fs_setup"`
+	Mounts    interface{} `json:"mounts"`
+}
+
+// convert converts the cloud-config user data into an Ignition config.
+func convert(userData []byte) ([]byte, error) {
+	in := &cloudConfig{}
+	if err := yaml.Unmarshal(userData, in); err != nil {
+		return nil, errors.Wrap(err, "failed to parse cloud-config user data")
+	}
+	if in.NTP != nil || in.DiskSetup != nil || in.FSSetup != nil || in.Mounts != nil {
+		return nil, errors.New("disk setup, mounts and NTP are not supported by the Ignition format")
+	}
+
+	out := &Config{Ignition: Ignition{Version: specVersion}}
+
+	for _, f := range in.WriteFiles {
+		file, err := newFile(f.Path, f.Owner, f.Permissions, f.Encoding, f.Content)
+		if err != nil {
+			return nil, err
+		}
+		out.Storage.Files = append(out.Storage.Files, file)
+	}
+
+	for _, u := range in.Users {
+		user := PasswdUser{
+			Name:              u.Name,
+			SSHAuthorizedKeys: u.SSHAuthorizedKeys,
+			Gecos:             u.Gecos,
+			HomeDir:           u.HomeDir,
+			PrimaryGroup:      u.PrimaryGroup,
+			Shell:             u.Shell,
+		}
+		if u.Passwd != "" {
+			passwordHash := u.Passwd
+			user.PasswordHash = &passwordHash
+		}
+		for _, group := range strings.Split(u.Groups, ",") {
+			if group = strings.TrimSpace(group); group != "" {
+				user.Groups = append(user.Groups, group)
+			}
+		}
+		out.Passwd.Users = append(out.Passwd.Users, user)
+
+		// Ignition does not support sudo rules, so they are written into a sudoers file.
+		if u.Sudo != "" {
+			file, err := newFile(fmt.Sprintf("/etc/sudoers.d/%s", u.Name), "root:root", "0440", "", fmt.Sprintf("%s %s\n", u.Name, u.Sudo))
+			if err != nil {
+				return nil, err
+			}
+			out.Storage.Files = append(out.Storage.Files, file)
+		}
+	}
+
+	// The commands are run in order by a systemd unit, as cloud-init does for runcmd.
+	script, err := newFile(kubeadmScriptPath, "root:root", "0755", "", "#!/bin/bash\n"+strings.Join(in.RunCmd, "\n")+"\n")
+	if err != nil {
+		return nil, err
+	}
+	out.Storage.Files = append(out.Storage.Files, script)
+	out.Systemd.Units = append(out.Systemd.Units, Unit{
+		Name:     kubeadmUnitName,
+		Enabled:  true,
+		Contents: kubeadmUnit,
+	})
+
+	data, err := json.Marshal(out)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal Ignition config")
+	}
+	return data, nil
+}
+
+// newFile returns an Ignition file for a cloud-config write_files entry.
+func newFile(path, owner, permissions, encoding, content string) (File, error) {
+	file := File{
+		Path:      path,
+		Overwrite: true,
+	}
+
+	if owner != "" {
+		ownerParts := strings.SplitN(owner, ":", 2)
+		file.User = &NodeUser{Name: ownerParts[0]}
+		if len(ownerParts) == 2 {
+			file.Group = &NodeGroup{Name: ownerParts[1]}
+		}
+	}
+
+	if permissions != "" {
+		mode, err := strconv.ParseInt(permissions, 8, 32)
+		if err != nil {
+			return File{}, errors.Wrapf(err, "invalid permissions %q for file %s", permissions, path)
+		}
+		m := int(mode)
+		file.Mode = &m
+	}
+
+	// Contents are always stored base64 encoded; contents already base64 encoded are used as is.
+	switch bootstrapv1.Encoding(encoding) {
+	case "":
+		file.Contents.Source = "data:;base64," + base64.StdEncoding.EncodeToString([]byte(content))
+	case bootstrapv1.Base64:
+		file.Contents.Source = "data:;base64," + strings.TrimSpace(content)
+	case bootstrapv1.Gzip:
+		file.Contents.Compression = "gzip"
+		file.Contents.Source = "data:;base64," + base64.StdEncoding.EncodeToString([]byte(content))
+	case bootstrapv1.GzipBase64:
+		file.Contents.Compression = "gzip"
+		file.Contents.Source = "data:;base64," + strings.TrimSpace(content)
+	default:
+		return File{}, errors.Errorf("unsupported encoding %q for file %s", encoding, path)
+	}
+
+	return file, nil
+}
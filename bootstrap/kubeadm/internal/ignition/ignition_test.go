@@ -0,0 +1,157 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ignition
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/utils/pointer"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/bootstrap/kubeadm/internal/cloudinit"
+)
+
+func TestGeneratorNewNode(t *testing.T) {
+	g := NewWithT(t)
+
+	out, err := Generator{}.NewNode(&cloudinit.NodeInput{
+		BaseUserData: cloudinit.BaseUserData{
+			AdditionalFiles: []bootstrapv1.File{
+				{Path: "/etc/foo.conf", Owner: "root:root", Permissions: "0644", Content: "foo"},
+				{Path: "/etc/bar.conf", Encoding: bootstrapv1.Base64, Content: base64.StdEncoding.EncodeToString([]byte("bar"))},
+			},
+			PreKubeadmCommands:  []string{"systemctl enable --now containerd"},
+			PostKubeadmCommands: []string{"echo done"},
+			Users: []bootstrapv1.User{
+				{
+					Name:              "core",
+					Groups:            pointer.StringPtr("docker, wheel"),
+					Sudo:              pointer.StringPtr("ALL=(ALL) NOPASSWD:ALL"),
+					SSHAuthorizedKeys: []string{"ssh-rsa foo"},
+				},
+			},
+		},
+		JoinConfiguration: "apiVersion: kubeadm.k8s.io/v1beta2\nkind: JoinConfiguration\n",
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(Generator{}.Validate(out, 0)).To(Succeed())
+
+	config := &Config{}
+	g.Expect(json.Unmarshal(out, config)).To(Succeed())
+	g.Expect(config.Ignition.Version).To(Equal(specVersion))
+
+	files := map[string]File{}
+	for _, f := range config.Storage.Files {
+		files[f.Path] = f
+	}
+	g.Expect(files).To(HaveKey("/etc/foo.conf"))
+	g.Expect(files["/etc/foo.conf"].User).To(Equal(&NodeUser{Name: "root"}))
+	g.Expect(files["/etc/foo.conf"].Group).To(Equal(&NodeGroup{Name: "root"}))
+	g.Expect(*files["/etc/foo.conf"].Mode).To(Equal(0644))
+	g.Expect(decode(g, files["/etc/foo.conf"])).To(Equal("foo\n"))
+	g.Expect(files).To(HaveKey("/etc/bar.conf"))
+	g.Expect(decode(g, files["/etc/bar.conf"])).To(Equal("bar"))
+	g.Expect(files).To(HaveKey("/run/kubeadm/kubeadm-join-config.yaml"))
+	g.Expect(decode(g, files["/run/kubeadm/kubeadm-join-config.yaml"])).To(ContainSubstring("kind: JoinConfiguration"))
+	g.Expect(files).To(HaveKey("/etc/sudoers.d/core"))
+	g.Expect(decode(g, files["/etc/sudoers.d/core"])).To(Equal("core ALL=(ALL) NOPASSWD:ALL\n"))
+
+	g.Expect(files).To(HaveKey(kubeadmScriptPath))
+	script := decode(g, files[kubeadmScriptPath])
+	g.Expect(strings.Split(strings.TrimSpace(script), "\n")).To(Equal([]string{
+		"#!/bin/bash",
+		"systemctl enable --now containerd",
+		"kubeadm join --config /run/kubeadm/kubeadm-join-config.yaml  && echo success > /run/cluster-api/bootstrap-success.complete",
+		"echo done",
+	}))
+
+	g.Expect(config.Systemd.Units).To(ConsistOf(Unit{Name: kubeadmUnitName, Enabled: true, Contents: kubeadmUnit}))
+
+	g.Expect(config.Passwd.Users).To(ConsistOf(PasswdUser{
+		Name:              "core",
+		Groups:            []string{"docker", "wheel"},
+		SSHAuthorizedKeys: []string{"ssh-rsa foo"},
+	}))
+}
+
+func TestGeneratorUnsupportedFields(t *testing.T) {
+	g := NewWithT(t)
+
+	_, err := Generator{}.NewNode(&cloudinit.NodeInput{
+		BaseUserData: cloudinit.BaseUserData{
+			NTP: &bootstrapv1.NTP{Servers: []string{"time.example.com"}},
+		},
+	})
+	g.Expect(err).To(MatchError(ContainSubstring("not supported by the Ignition format")))
+}
+
+func TestGeneratorValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		userData  string
+		maxSize   int
+		errString string
+	}{
+		{
+			name:     "valid config",
+			userData: `{"ignition":{"version":"3.1.0"},"storage":{"files":[{"path":"/etc/foo","contents":{"source":"data:,foo"}}]}}`,
+		},
+		{
+			name:      "config exceeding the maximum size",
+			userData:  `{"ignition":{"version":"3.1.0"}}`,
+			maxSize:   16,
+			errString: "exceeds the maximum size of 16 bytes",
+		},
+		{
+			name:      "invalid JSON",
+			userData:  `{"ignition":`,
+			errString: "not a valid JSON document",
+		},
+		{
+			name:      "unsupported version",
+			userData:  `{"ignition":{"version":"2.3.0"}}`,
+			errString: "unsupported version",
+		},
+		{
+			name:      "duplicate paths",
+			userData:  `{"ignition":{"version":"3.1.0"},"storage":{"files":[{"path":"/etc/foo","contents":{"source":"data:,foo"}},{"path":"/etc/foo","contents":{"source":"data:,bar"}}]}}`,
+			errString: "duplicate paths /etc/foo",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			err := Generator{}.Validate([]byte(tt.userData), tt.maxSize)
+			if tt.errString != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tt.errString)))
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+		})
+	}
+}
+
+func decode(g *WithT, file File) string {
+	g.Expect(file.Contents.Source).To(HavePrefix("data:;base64,"))
+	content, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(file.Contents.Source, "data:;base64,"))
+	g.Expect(err).NotTo(HaveOccurred())
+	return string(content)
+}
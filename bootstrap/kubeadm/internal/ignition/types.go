@@ -0,0 +1,93 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ignition
+
+// NOTE: The following types define the subset of the Ignition config specification v3.1.0 used
+// for the kubeadm bootstrap data; see https://coreos.github.io/ignition/configuration-v3_1/.
+
+// Config is the root of an Ignition config.
+type Config struct {
+	Ignition Ignition `json:"ignition"`
+	Passwd   Passwd   `json:"passwd,omitempty"`
+	Storage  Storage  `json:"storage,omitempty"`
+	Systemd  Systemd  `json:"systemd,omitempty"`
+}
+
+// Ignition defines metadata about the Ignition config.
+type Ignition struct {
+	Version string `json:"version"`
+}
+
+// Passwd defines the users to be added to the system.
+type Passwd struct {
+	Users []PasswdUser `json:"users,omitempty"`
+}
+
+// PasswdUser defines a user to be added to the system.
+type PasswdUser struct {
+	Name              string   `json:"name"`
+	PasswordHash      *string  `json:"passwordHash,omitempty"`
+	SSHAuthorizedKeys []string `json:"sshAuthorizedKeys,omitempty"`
+	Gecos             string   `json:"gecos,omitempty"`
+	HomeDir           string   `json:"homeDir,omitempty"`
+	PrimaryGroup      string   `json:"primaryGroup,omitempty"`
+	Groups            []string `json:"groups,omitempty"`
+	Shell             string   `json:"shell,omitempty"`
+}
+
+// Storage defines the files to be written to the system.
+type Storage struct {
+	Files []File `json:"files,omitempty"`
+}
+
+// File defines a file to be written to the system.
+type File struct {
+	Path      string       `json:"path"`
+	Overwrite bool         `json:"overwrite,omitempty"`
+	User      *NodeUser    `json:"user,omitempty"`
+	Group     *NodeGroup   `json:"group,omitempty"`
+	Mode      *int         `json:"mode,omitempty"`
+	Contents  FileContents `json:"contents"`
+}
+
+// NodeUser defines the owner of a file.
+type NodeUser struct {
+	Name string `json:"name"`
+}
+
+// NodeGroup defines the group of a file.
+type NodeGroup struct {
+	Name string `json:"name"`
+}
+
+// FileContents defines the contents of a file.
+type FileContents struct {
+	Source      string `json:"source"`
+	Compression string `json:"compression,omitempty"`
+}
+
+// Systemd defines the systemd units to be written to the system.
+type Systemd struct {
+	Units []Unit `json:"units,omitempty"`
+}
+
+// Unit defines a systemd unit.
+type Unit struct {
+	Name     string `json:"name"`
+	Enabled  bool   `json:"enabled,omitempty"`
+	Contents string `json:"contents,omitempty"`
+}
@@ -74,6 +74,7 @@ var (
 	watchNamespace              string
 	profilerAddress             string
 	kubeadmConfigConcurrency    int
+	bootstrapDataMaxSize        int
 	syncPeriod                  time.Duration
 	webhookPort                 int
 	webhookCertDir              string
@@ -106,6 +107,9 @@ func InitFlags(fs *pflag.FlagSet) {
 	fs.IntVar(&kubeadmConfigConcurrency, "kubeadmconfig-concurrency", 10,
 		"Number of kubeadm configs to process simultaneously")
 
+	fs.IntVar(&bootstrapDataMaxSize, "bootstrap-data-max-size", 0,
+		"The maximum size, in bytes, of the generated bootstrap data; set it according to the user data size limit of the infrastructure provider. If zero, the size is not checked.")
+
 	fs.DurationVar(&syncPeriod, "sync-period", 10*time.Minute,
 		"The minimum interval at which watched resources are reconciled (e.g. 15m)")
 
@@ -197,13 +201,48 @@ func setupChecks(mgr ctrl.Manager) {
 }
 
 func setupReconcilers(ctx context.Context, mgr ctrl.Manager) {
-	if err := (&kubeadmbootstrapcontrollers.KubeadmConfigReconciler{
+	// Set up a ClusterCacheTracker and ClusterCacheReconciler to provide to controllers
+	// requiring a connection to a remote cluster
+	tracker, err := remote.NewClusterCacheTracker(mgr, remote.ClusterCacheTrackerOptions{
+		Log:            ctrl.Log.WithName("remote").WithName("ClusterCacheTracker"),
+		ControllerName: "capi-kubeadm-bootstrap-controller-manager",
+		// The bootstrap token Secrets are read right after being created or updated, and Secrets
+		// must not be cached for all the workload clusters, so they are always read from the API server.
+		ClientUncachedObjects: []client.Object{
+			&corev1.Secret{},
+			&corev1.ConfigMap{},
+		},
+	})
+	if err != nil {
+		setupLog.Error(err, "unable to create cluster cache tracker")
+		os.Exit(1)
+	}
+	if err := (&remote.ClusterCacheReconciler{
 		Client:           mgr.GetClient(),
+		Log:              ctrl.Log.WithName("remote").WithName("ClusterCacheReconciler"),
+		Tracker:          tracker,
 		WatchFilterValue: watchFilterValue,
+	}).SetupWithManager(ctx, mgr, concurrency(kubeadmConfigConcurrency)); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ClusterCacheReconciler")
+		os.Exit(1)
+	}
+
+	if err := (&kubeadmbootstrapcontrollers.KubeadmConfigReconciler{
+		Client:               mgr.GetClient(),
+		Tracker:              tracker,
+		WatchFilterValue:     watchFilterValue,
+		BootstrapDataMaxSize: bootstrapDataMaxSize,
 	}).SetupWithManager(ctx, mgr, concurrency(kubeadmConfigConcurrency)); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "KubeadmConfig")
 		os.Exit(1)
 	}
+	if err := (&kubeadmbootstrapcontrollers.BootstrapTokenReconciler{
+		Client:           mgr.GetClient(),
+		WatchFilterValue: watchFilterValue,
+	}).SetupWithManager(ctx, mgr, concurrency(kubeadmConfigConcurrency)); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "BootstrapToken")
+		os.Exit(1)
+	}
 }
 
 func setupWebhooks(mgr ctrl.Manager) {
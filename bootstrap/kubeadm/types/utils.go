@@ -111,7 +111,29 @@ func MarshalJoinConfigurationForVersion(obj *bootstrapv1.JoinConfiguration, vers
 	return marshalForVersion(obj, version, joinConfigurationVersionTypeMap)
 }
 
-func marshalForVersion(obj conversion.Hub, version semver.Version, kubeadmObjVersionTypeMap map[schema.GroupVersion]conversion.Convertible) (string, error) {
+// MarshalJoinConfigurationWithCertificateKeyForVersion converts a Cluster API JoinConfiguration type to the kubeadm API type
+// for the given Kubernetes Version, setting the key used by kubeadm join for decrypting the control plane certificates
+// downloaded from the kubeadm-certs Secret.
+// NOTE: The certificate key does not exist in the Cluster API JoinConfiguration type, because it is a secret value
+// which should not be stored in the KubeadmConfig spec; also, it is not supported by the kubeadm API version v1beta1.
+func MarshalJoinConfigurationWithCertificateKeyForVersion(obj *bootstrapv1.JoinConfiguration, certificateKey string, version semver.Version) (string, error) {
+	if obj.ControlPlane == nil {
+		return "", errors.New("failed to set the certificate key: the JoinConfiguration is not for a control plane node")
+	}
+	return marshalForVersion(obj, version, joinConfigurationVersionTypeMap, func(kubeadmObj conversion.Convertible) error {
+		switch joinConfiguration := kubeadmObj.(type) {
+		case *v1beta2.JoinConfiguration:
+			joinConfiguration.ControlPlane.CertificateKey = certificateKey
+		case *v1beta3.JoinConfiguration:
+			joinConfiguration.ControlPlane.CertificateKey = certificateKey
+		default:
+			return errors.Errorf("the certificate key is not supported by the KubeadmAPI type %T", kubeadmObj)
+		}
+		return nil
+	})
+}
+
+func marshalForVersion(obj conversion.Hub, version semver.Version, kubeadmObjVersionTypeMap map[schema.GroupVersion]conversion.Convertible, mutators ...func(conversion.Convertible) error) (string, error) {
 	kubeadmAPIGroupVersion, err := KubeVersionToKubeadmAPIGroupVersion(version)
 	if err != nil {
 		return "", err
@@ -126,6 +148,11 @@ func marshalForVersion(obj conversion.Hub, version semver.Version, kubeadmObjVer
 	if err := targetKubeadmObj.ConvertFrom(obj); err != nil {
 		return "", errors.Wrapf(err, "failed to convert to KubeadmAPI type for version %s", kubeadmAPIGroupVersion)
 	}
+	for _, mutate := range mutators {
+		if err := mutate(targetKubeadmObj); err != nil {
+			return "", err
+		}
+	}
 
 	codecs, err := getCodecsFor(kubeadmAPIGroupVersion, targetKubeadmObj)
 	if err != nil {
@@ -331,6 +331,64 @@ func TestMarshalJoinConfigurationForVersion(t *testing.T) {
 	}
 }
 
+func TestMarshalJoinConfigurationWithCertificateKeyForVersion(t *testing.T) {
+	type args struct {
+		capiObj *bootstrapv1.JoinConfiguration
+		version semver.Version
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "Fails for a v1beta1 kubeadm configuration",
+			args: args{
+				capiObj: &bootstrapv1.JoinConfiguration{ControlPlane: &bootstrapv1.JoinControlPlane{}},
+				version: semver.MustParse("1.14.9"),
+			},
+			wantErr: true,
+		},
+		{
+			name: "Fails for a worker join configuration",
+			args: args{
+				capiObj: &bootstrapv1.JoinConfiguration{},
+				version: semver.MustParse("1.15.0"),
+			},
+			wantErr: true,
+		},
+		{
+			name: "Generates a v1beta2 kubeadm configuration",
+			args: args{
+				capiObj: &bootstrapv1.JoinConfiguration{ControlPlane: &bootstrapv1.JoinControlPlane{}},
+				version: semver.MustParse("1.15.0"),
+			},
+			want: "apiVersion: kubeadm.k8s.io/v1beta2\n" + "" +
+				"controlPlane:\n" +
+				"  certificateKey: abcdef\n" +
+				"  localAPIEndpoint: {}\n" +
+				"discovery: {}\n" +
+				"kind: JoinConfiguration\n" +
+				"nodeRegistration: {}\n",
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got, err := MarshalJoinConfigurationWithCertificateKeyForVersion(tt.args.capiObj, "abcdef", tt.args.version)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want), cmp.Diff(tt.want, got))
+		})
+	}
+}
+
 func TestUnmarshalClusterConfiguration(t *testing.T) {
 	type args struct {
 		yaml string
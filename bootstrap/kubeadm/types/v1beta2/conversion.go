@@ -81,7 +81,7 @@ func Convert_v1beta2_NodeRegistrationOptions_To_v1alpha4_NodeRegistrationOptions
 }
 
 func Convert_v1beta2_JoinControlPlane_To_v1alpha4_JoinControlPlane(in *JoinControlPlane, out *bootstrapv1.JoinControlPlane, s apimachineryconversion.Scope) error {
-	// JoinControlPlane.CertificateKey exists in v1beta2 types but not in bootstrapv1.JoinControlPlane (Cluster API does not uses automatic copy certs). Ignoring when converting.
+	// JoinControlPlane.CertificateKey exists in v1beta2 types but not in bootstrapv1.JoinControlPlane (the certificate key is a secret value, set only when marshalling the JoinConfiguration). Ignoring when converting.
 	return autoConvert_v1beta2_JoinControlPlane_To_v1alpha4_JoinControlPlane(in, out, s)
 }
 
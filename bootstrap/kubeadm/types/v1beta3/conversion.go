@@ -74,6 +74,6 @@ func Convert_v1beta3_NodeRegistrationOptions_To_v1alpha4_NodeRegistrationOptions
 }
 
 func Convert_v1beta3_JoinControlPlane_To_v1alpha4_JoinControlPlane(in *JoinControlPlane, out *bootstrapv1.JoinControlPlane, s apimachineryconversion.Scope) error {
-	// JoinControlPlane.CertificateKey exists in v1beta3 types but not in bootstrapv1.JoinControlPlane (Cluster API does not uses automatic copy certs). Ignoring when converting.
+	// JoinControlPlane.CertificateKey exists in v1beta3 types but not in bootstrapv1.JoinControlPlane (the certificate key is a secret value, set only when marshalling the JoinConfiguration). Ignoring when converting.
 	return autoConvert_v1beta3_JoinControlPlane_To_v1alpha4_JoinControlPlane(in, out, s)
 }
@@ -24,6 +24,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 	"sigs.k8s.io/cluster-api/controllers/external"
@@ -64,6 +65,25 @@ func (co ConfigOwner) DataSecretName() *string {
 	return &dataSecretName
 }
 
+// BootstrapConfigRef extracts the bootstrap config reference from the config owner, i.e. spec.bootstrap.configRef
+// for Machines and spec.template.spec.bootstrap.configRef for MachinePools.
+func (co ConfigOwner) BootstrapConfigRef() *corev1.ObjectReference {
+	fields := []string{"spec", "bootstrap", "configRef"}
+	if co.IsMachinePool() {
+		fields = []string{"spec", "template", "spec", "bootstrap", "configRef"}
+	}
+
+	ref, exist, err := unstructured.NestedMap(co.Object, fields...)
+	if err != nil || !exist {
+		return nil
+	}
+	configRef := &corev1.ObjectReference{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(ref, configRef); err != nil {
+		return nil
+	}
+	return configRef
+}
+
 // IsControlPlaneMachine checks if an unstructured object is Machine with the control plane role.
 func (co ConfigOwner) IsControlPlaneMachine() bool {
 	if co.GetKind() != "Machine" {
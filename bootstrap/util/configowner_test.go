@@ -21,6 +21,7 @@ import (
 
 	. "github.com/onsi/gomega"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/pointer"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
@@ -76,6 +77,46 @@ func TestGetConfigOwner(t *testing.T) {
 		g.Expect(configOwner.IsMachinePool()).To(BeFalse())
 		g.Expect(configOwner.KubernetesVersion()).To(Equal("v1.19.6"))
 		g.Expect(*configOwner.DataSecretName()).To(BeEquivalentTo("my-data-secret"))
+		g.Expect(configOwner.BootstrapConfigRef()).To(BeNil())
+	})
+
+	t.Run("should get the bootstrap config reference of the owner", func(t *testing.T) {
+		g := NewWithT(t)
+		myMachine := &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "my-machine",
+				Namespace: "my-ns",
+			},
+			Spec: clusterv1.MachineSpec{
+				ClusterName: "my-cluster",
+				Bootstrap: clusterv1.Bootstrap{
+					ConfigRef: &corev1.ObjectReference{
+						Kind:       "TalosConfig",
+						APIVersion: "bootstrap.cluster.x-k8s.io/v1alpha3",
+						Name:       "my-talos-config",
+					},
+				},
+			},
+		}
+
+		c := fake.NewClientBuilder().WithObjects(myMachine).Build()
+		obj := &bootstrapv1.KubeadmConfig{
+			ObjectMeta: metav1.ObjectMeta{
+				OwnerReferences: []metav1.OwnerReference{
+					{
+						Kind:       "Machine",
+						APIVersion: clusterv1.GroupVersion.String(),
+						Name:       "my-machine",
+					},
+				},
+				Namespace: "my-ns",
+				Name:      "my-resource-owned-by-machine",
+			},
+		}
+		configOwner, err := GetConfigOwner(ctx, c, obj)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(configOwner).ToNot(BeNil())
+		g.Expect(configOwner.BootstrapConfigRef()).To(Equal(myMachine.Spec.Bootstrap.ConfigRef))
 	})
 
 	t.Run("should get the owner when present (MachinePool)", func(t *testing.T) {
@@ -19,4 +19,28 @@ package v1alpha3
 const (
 	// CertManagerVersionAnnotation reports the cert manager version installed by clusterctl.
 	CertManagerVersionAnnotation = "cert-manager.clusterctl.cluster.x-k8s.io/version"
+
+	// MoveHookTimeoutAnnotation can be set on CRDs with the move-hooks label to define how long clusterctl move waits for
+	// the provider to complete a move hook, e.g. "10m"; if not set, the default timeout is used.
+	MoveHookTimeoutAnnotation = "clusterctl.cluster.x-k8s.io/move-hook-timeout"
+
+	// PreMoveHookAnnotation is set by clusterctl move on the objects with move hooks in the source cluster, before
+	// moving them, to request the provider to execute the pre-move actions; the value identifies the move request.
+	PreMoveHookAnnotation = "clusterctl.cluster.x-k8s.io/pre-move"
+
+	// PreMoveHookCompletedAnnotation must be set by the provider once the pre-move actions are completed,
+	// using the value of the PreMoveHookAnnotation.
+	PreMoveHookCompletedAnnotation = "clusterctl.cluster.x-k8s.io/pre-move-completed"
+
+	// PostMoveHookAnnotation is set by clusterctl move on the objects with move hooks in the target cluster, after
+	// moving them, to request the provider to execute the post-move actions; the value identifies the move request.
+	PostMoveHookAnnotation = "clusterctl.cluster.x-k8s.io/post-move"
+
+	// PostMoveHookCompletedAnnotation must be set by the provider once the post-move actions are completed,
+	// using the value of the PostMoveHookAnnotation.
+	PostMoveHookCompletedAnnotation = "clusterctl.cluster.x-k8s.io/post-move-completed"
+
+	// MoveHookFailedAnnotation can be set by the provider if a move hook fails; the value is a message describing the failure.
+	// NOTE: clusterctl move stops waiting for the hook and reports the failure.
+	MoveHookFailedAnnotation = "clusterctl.cluster.x-k8s.io/move-hook-failed"
 )
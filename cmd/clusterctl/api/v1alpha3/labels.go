@@ -36,6 +36,10 @@ const (
 
 	// ClusterctlMoveHierarchyLabelName can be set on CRDs that providers wish to move with their entire hierarchy, but that are not part of a Cluster.
 	ClusterctlMoveHierarchyLabelName = "clusterctl.cluster.x-k8s.io/move-hierarchy"
+
+	// ClusterctlMoveHooksLabelName can be set on CRDs whose objects require pre-move and post-move actions to be executed
+	// by the provider, e.g. for quiescing a controller before the objects are moved.
+	ClusterctlMoveHooksLabelName = "clusterctl.cluster.x-k8s.io/move-hooks"
 )
 
 // ManifestLabel returns the cluster.x-k8s.io/provider label value for a provider/type.
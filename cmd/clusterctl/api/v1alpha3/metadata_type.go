@@ -31,6 +31,14 @@ type Metadata struct {
 
 	// +optional
 	ReleaseSeries []ReleaseSeries `json:"releaseSeries"`
+
+	// Variables describes the variables used by the cluster templates of the provider.
+	// +optional
+	Variables []VariableDefinition `json:"variables,omitempty"`
+
+	// Flavors describes the flavors of the cluster templates of the provider.
+	// +optional
+	Flavors []FlavorDefinition `json:"flavors,omitempty"`
 }
 
 // ReleaseSeries maps a provider release series (major/minor) with a API Version of Cluster API (contract).
@@ -45,6 +53,41 @@ type ReleaseSeries struct {
 	//
 	// The value is an API Version, e.g. `v1alpha3`.
 	Contract string `json:"contract,omitempty"`
+
+	// UpgradeNotes are shown by clusterctl upgrade plan when proposing to upgrade the provider from an older release series
+	// to this release series or to a newer one, e.g. breaking changes or requirements like
+	// "requires Kubernetes >= v1.19 on the management cluster".
+	// +optional
+	UpgradeNotes []string `json:"upgradeNotes,omitempty"`
+}
+
+// VariableDefinition describes a variable used by the cluster templates of a provider.
+type VariableDefinition struct {
+	// Name of the variable, e.g. `AWS_REGION`.
+	Name string `json:"name"`
+
+	// Type of the variable value. Valid values are `string`, `integer`, `number` and `boolean`;
+	// if not specified, `string` is assumed.
+	// +optional
+	Type string `json:"type,omitempty"`
+
+	// Description of the variable.
+	// +optional
+	Description string `json:"description,omitempty"`
+
+	// Enum lists the allowed values of the variable, if any.
+	// +optional
+	Enum []string `json:"enum,omitempty"`
+}
+
+// FlavorDefinition describes a flavor of the cluster templates of a provider.
+type FlavorDefinition struct {
+	// Name of the flavor, e.g. `machinepool` for the `cluster-template-machinepool.yaml` template.
+	Name string `json:"name"`
+
+	// Description of the flavor.
+	// +optional
+	Description string `json:"description,omitempty"`
 }
 
 func (rs ReleaseSeries) newer(release ReleaseSeries) bool {
@@ -24,6 +24,21 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FlavorDefinition) DeepCopyInto(out *FlavorDefinition) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FlavorDefinition.
+func (in *FlavorDefinition) DeepCopy() *FlavorDefinition {
+	if in == nil {
+		return nil
+	}
+	out := new(FlavorDefinition)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Metadata) DeepCopyInto(out *Metadata) {
 	*out = *in
@@ -32,6 +47,20 @@ func (in *Metadata) DeepCopyInto(out *Metadata) {
 	if in.ReleaseSeries != nil {
 		in, out := &in.ReleaseSeries, &out.ReleaseSeries
 		*out = make([]ReleaseSeries, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Variables != nil {
+		in, out := &in.Variables, &out.Variables
+		*out = make([]VariableDefinition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Flavors != nil {
+		in, out := &in.Flavors, &out.Flavors
+		*out = make([]FlavorDefinition, len(*in))
 		copy(*out, *in)
 	}
 }
@@ -114,6 +143,11 @@ func (in *ProviderList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ReleaseSeries) DeepCopyInto(out *ReleaseSeries) {
 	*out = *in
+	if in.UpgradeNotes != nil {
+		in, out := &in.UpgradeNotes, &out.UpgradeNotes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReleaseSeries.
@@ -125,3 +159,23 @@ func (in *ReleaseSeries) DeepCopy() *ReleaseSeries {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VariableDefinition) DeepCopyInto(out *VariableDefinition) {
+	*out = *in
+	if in.Enum != nil {
+		in, out := &in.Enum, &out.Enum
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VariableDefinition.
+func (in *VariableDefinition) DeepCopy() *VariableDefinition {
+	if in == nil {
+		return nil
+	}
+	out := new(VariableDefinition)
+	in.DeepCopyInto(out)
+	return out
+}
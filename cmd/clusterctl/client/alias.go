@@ -38,6 +38,9 @@ type ComponentsOptions repository.ComponentsOptions
 // Template wraps a YAML file that defines the cluster objects (Cluster, Machines etc.).
 type Template repository.Template
 
+// Flavor describes a flavor of the cluster templates hosted on a provider repository.
+type Flavor repository.Flavor
+
 // UpgradePlan defines a list of possible upgrade targets for a management cluster.
 type UpgradePlan cluster.UpgradePlan
 
@@ -45,6 +48,9 @@ type UpgradePlan cluster.UpgradePlan
 // upgraded to a different version.
 type CertManagerUpgradePlan cluster.CertManagerUpgradePlan
 
+// CertManagerInfo describes the cert-manager release clusterctl installs.
+type CertManagerInfo cluster.CertManagerInfo
+
 // Kubeconfig is a type that specifies inputs related to the actual kubeconfig.
 type Kubeconfig cluster.Kubeconfig
 
@@ -30,6 +30,9 @@ type Client interface {
 	// GetProvidersConfig returns the list of providers configured for this instance of clusterctl.
 	GetProvidersConfig() ([]Provider, error)
 
+	// GetCertManagerInfo returns the cert-manager release installed by clusterctl, as defined by the clusterctl configuration.
+	GetCertManagerInfo() (CertManagerInfo, error)
+
 	// GetProviderComponents returns the provider components for a given provider with options including targetNamespace.
 	GetProviderComponents(provider string, providerType clusterctlv1.ProviderType, options ComponentsOptions) (Components, error)
 
@@ -42,12 +45,21 @@ type Client interface {
 	// GetClusterTemplate returns a workload cluster template.
 	GetClusterTemplate(options GetClusterTemplateOptions) (Template, error)
 
+	// ListFlavors returns the flavors of the workload cluster templates available in a provider repository.
+	ListFlavors(options ListFlavorsOptions) ([]Flavor, error)
+
 	// GetKubeconfig returns the kubeconfig of the workload cluster.
 	GetKubeconfig(options GetKubeconfigOptions) (string, error)
 
+	// GetClusters returns a summary of the status of the workload clusters in a management cluster.
+	GetClusters(options GetClustersOptions) ([]ClusterSummary, error)
+
 	// Delete deletes providers from a management cluster.
 	Delete(options DeleteOptions) error
 
+	// DeleteCluster deletes a workload cluster and waits for the cascading deletion of the objects belonging to it.
+	DeleteCluster(options DeleteClusterOptions) error
+
 	// Move moves all the Cluster API objects existing in a namespace (or from all the namespaces if empty) to a target management cluster.
 	Move(options MoveOptions) error
 
@@ -89,6 +101,10 @@ type AlphaClient interface {
 	RolloutResume(options RolloutOptions) error
 	// RolloutUndo provides rollout rollback of cluster-api resources
 	RolloutUndo(options RolloutOptions) error
+	// SupportBundle collects sanitized troubleshooting information from a management cluster into a local tarball.
+	SupportBundle(options SupportBundleOptions) error
+	// Validate validates a rendered cluster template against the CRDs and webhooks installed in a management cluster.
+	Validate(options ValidateOptions) ([]ValidationIssue, error)
 }
 
 // YamlPrinter exposes methods that prints the processed template and
@@ -18,6 +18,7 @@ package client
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -76,6 +77,10 @@ func (f fakeClient) GetProvidersConfig() ([]Provider, error) {
 	return f.internalClient.GetProvidersConfig()
 }
 
+func (f fakeClient) GetCertManagerInfo() (CertManagerInfo, error) {
+	return f.internalClient.GetCertManagerInfo()
+}
+
 func (f fakeClient) GetProviderComponents(provider string, providerType clusterctlv1.ProviderType, options ComponentsOptions) (Components, error) {
 	return f.internalClient.GetProviderComponents(provider, providerType, options)
 }
@@ -84,10 +89,18 @@ func (f fakeClient) GetClusterTemplate(options GetClusterTemplateOptions) (Templ
 	return f.internalClient.GetClusterTemplate(options)
 }
 
+func (f fakeClient) ListFlavors(options ListFlavorsOptions) ([]Flavor, error) {
+	return f.internalClient.ListFlavors(options)
+}
+
 func (f fakeClient) GetKubeconfig(options GetKubeconfigOptions) (string, error) {
 	return f.internalClient.GetKubeconfig(options)
 }
 
+func (f fakeClient) GetClusters(options GetClustersOptions) ([]ClusterSummary, error) {
+	return f.internalClient.GetClusters(options)
+}
+
 func (f fakeClient) Init(options InitOptions) ([]Components, error) {
 	return f.internalClient.Init(options)
 }
@@ -100,6 +113,10 @@ func (f fakeClient) Delete(options DeleteOptions) error {
 	return f.internalClient.Delete(options)
 }
 
+func (f fakeClient) DeleteCluster(options DeleteClusterOptions) error {
+	return f.internalClient.DeleteCluster(options)
+}
+
 func (f fakeClient) Move(options MoveOptions) error {
 	return f.internalClient.Move(options)
 }
@@ -148,6 +165,14 @@ func (f fakeClient) RolloutUndo(options RolloutOptions) error {
 	return f.internalClient.RolloutUndo(options)
 }
 
+func (f fakeClient) SupportBundle(options SupportBundleOptions) error {
+	return f.internalClient.SupportBundle(options)
+}
+
+func (f fakeClient) Validate(options ValidateOptions) ([]ValidationIssue, error) {
+	return f.internalClient.Validate(options)
+}
+
 // newFakeClient returns a clusterctl client that allows to execute tests on a set of fake config, fake repositories and fake clusters.
 // you can use WithCluster and WithRepository to prepare for the test case.
 func newFakeClient(configClient config.Client) *fakeClient {
@@ -259,6 +284,10 @@ func (p *fakeCertManagerClient) Images() ([]string, error) {
 	return p.images, p.imagesError
 }
 
+func (p *fakeCertManagerClient) Info() (cluster.CertManagerInfo, error) {
+	return cluster.CertManagerInfo{Images: p.images}, p.imagesError
+}
+
 func (p *fakeCertManagerClient) WithCertManagerPlan(plan CertManagerUpgradePlan) *fakeCertManagerClient {
 	p.certManagerPlan = cluster.CertManagerUpgradePlan(plan)
 	return p
@@ -318,6 +347,10 @@ func (f *fakeClusterClient) WorkloadCluster() cluster.WorkloadCluster {
 	return f.internalclient.WorkloadCluster()
 }
 
+func (f *fakeClusterClient) SupportBundle() cluster.SupportBundleCollector {
+	return f.internalclient.SupportBundle()
+}
+
 func (f *fakeClusterClient) WithObjs(objs ...client.Object) *fakeClusterClient {
 	f.fakeProxy.WithObjs(objs...)
 	return f
@@ -380,6 +413,14 @@ func (f fakeConfigClient) ImageMeta() config.ImageMetaClient {
 	return f.internalclient.ImageMeta()
 }
 
+func (f fakeConfigClient) SignatureVerification() config.SignatureVerificationClient {
+	return f.internalclient.SignatureVerification()
+}
+
+func (f fakeConfigClient) Overlays() config.OverlaysClient {
+	return f.internalclient.Overlays()
+}
+
 func (f *fakeConfigClient) WithVar(key, value string) *fakeConfigClient {
 	f.fakeReader.WithVar(key, value)
 	return f
@@ -506,6 +547,27 @@ func (f *fakeTemplateClient) Get(flavor, targetNamespace string, skipTemplatePro
 	})
 }
 
+func (f *fakeTemplateClient) ListFlavors() ([]repository.Flavor, error) {
+	version := f.version
+	if version == "" {
+		version = f.fakeRepository.DefaultVersion()
+	}
+	files, err := f.fakeRepository.ListFiles(version)
+	if err != nil {
+		return nil, err
+	}
+	flavors := []repository.Flavor{}
+	for _, file := range files {
+		if !strings.HasPrefix(file, "cluster-template") || !strings.HasSuffix(file, ".yaml") {
+			continue
+		}
+		flavors = append(flavors, repository.Flavor{
+			Name: strings.TrimPrefix(strings.TrimSuffix(strings.TrimPrefix(file, "cluster-template"), ".yaml"), "-"),
+		})
+	}
+	return flavors, nil
+}
+
 // fakeMetadataClient provides a super simple MetadataClient (e.g. without support for local overrides/embedded metadata).
 type fakeMetadataClient struct {
 	version        string
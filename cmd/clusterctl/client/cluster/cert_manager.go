@@ -60,6 +60,18 @@ type CertManagerUpgradePlan struct {
 	ShouldUpgrade     bool
 }
 
+// CertManagerInfo describes the cert-manager release clusterctl installs.
+type CertManagerInfo struct {
+	// URL of the cert-manager manifest.
+	URL string
+	// Version of cert-manager.
+	Version string
+	// Timeout for cert-manager to start.
+	Timeout string
+	// Images required for installing cert-manager.
+	Images []string
+}
+
 // CertManagerClient has methods to work with cert-manager components in the cluster.
 type CertManagerClient interface {
 	// EnsureInstalled makes sure cert-manager is running and its API is available.
@@ -76,6 +88,10 @@ type CertManagerClient interface {
 
 	// Images return the list of images required for installing the cert-manager.
 	Images() ([]string, error)
+
+	// Info returns the cert-manager release clusterctl installs, as defined by the clusterctl configuration;
+	// the management cluster is not accessed.
+	Info() (CertManagerInfo, error)
 }
 
 // certManagerClient implements CertManagerClient .
@@ -128,6 +144,34 @@ func (cm *certManagerClient) Images() ([]string, error) {
 	return images, nil
 }
 
+// Info returns the cert-manager release clusterctl installs, as defined by the clusterctl configuration;
+// the management cluster is not accessed.
+func (cm *certManagerClient) Info() (CertManagerInfo, error) {
+	config, err := cm.configClient.CertManager().Get()
+	if err != nil {
+		return CertManagerInfo{}, err
+	}
+	if err := validateCertManagerVersion(config.Version()); err != nil {
+		return CertManagerInfo{}, err
+	}
+
+	objs, err := cm.getManifestObjs(config)
+	if err != nil {
+		return CertManagerInfo{}, err
+	}
+	images, err := util.InspectImages(objs)
+	if err != nil {
+		return CertManagerInfo{}, err
+	}
+
+	return CertManagerInfo{
+		URL:     config.URL(),
+		Version: config.Version(),
+		Timeout: cm.getWaitTimeout().String(),
+		Images:  images,
+	}, nil
+}
+
 func (cm *certManagerClient) certManagerNamespaceExists() (bool, error) {
 	ns := &corev1.Namespace{}
 	key := client.ObjectKey{Name: certManagerNamespace}
@@ -153,7 +197,7 @@ func (cm *certManagerClient) EnsureInstalled() error {
 	// Checking if a version of cert manager supporting cert-manager-test-resources.yaml is already installed and properly working.
 	if err := cm.waitForAPIReady(ctx, false); err == nil {
 		log.Info("Skipping installing cert-manager as it is already installed")
-		return nil
+		return cm.checkInstalledVersion()
 	}
 
 	// Otherwise install cert manager.
@@ -169,15 +213,20 @@ func (cm *certManagerClient) install() error {
 	if err != nil {
 		return err
 	}
+	if err := validateCertManagerVersion(config.Version()); err != nil {
+		return err
+	}
 	log.Info("Installing cert-manager", "Version", config.Version())
 
 	// Gets the cert-manager components from the repository.
+	log.V(1).Info("Fetching cert-manager manifest", "URL", config.URL())
 	objs, err := cm.getManifestObjs(config)
 	if err != nil {
 		return err
 	}
 
 	// Install all cert-manager manifests
+	log.V(1).Info("Creating cert-manager components", "Count", len(objs))
 	createCertManagerBackoff := newWriteBackoff()
 	objs = utilresource.SortForCreate(objs)
 	for i := range objs {
@@ -192,7 +241,53 @@ func (cm *certManagerClient) install() error {
 	}
 
 	// Wait for the cert-manager API to be ready to accept requests
-	return cm.waitForAPIReady(ctx, true)
+	if err := cm.waitForAPIReady(ctx, true); err != nil {
+		return err
+	}
+	log.Info("Cert-manager is available", "Version", config.Version())
+	return nil
+}
+
+// checkInstalledVersion checks the cert-manager version installed by clusterctl meets the minimum supported version;
+// the version of a cert-manager externally managed can not be detected, so it is not checked.
+func (cm *certManagerClient) checkInstalledVersion() error {
+	log := logf.Log
+
+	objs, err := cm.proxy.ListResources(map[string]string{clusterctlv1.ClusterctlCoreLabelName: clusterctlv1.ClusterctlCoreLabelCertManagerValue}, certManagerNamespace)
+	if err != nil {
+		return errors.Wrap(err, "failed get cert manager components")
+	}
+	if len(objs) == 0 {
+		log.V(5).Info("Skipping cert-manager version check because externally managed")
+		return nil
+	}
+
+	for i := range objs {
+		obj := objs[i]
+		objVersion, ok := obj.GetAnnotations()[clusterctlv1.CertManagerVersionAnnotation]
+		if !ok {
+			objVersion, ok = obj.GetAnnotations()[certManagerVersionAnnotation]
+		}
+		if !ok {
+			continue
+		}
+		if err := validateCertManagerVersion(objVersion); err != nil {
+			return errors.Wrap(err, "the installed cert-manager must be upgraded using clusterctl upgrade")
+		}
+	}
+	return nil
+}
+
+// validateCertManagerVersion checks a cert-manager version meets the minimum version supported by clusterctl.
+func validateCertManagerVersion(v string) error {
+	semVersion, err := version.ParseSemantic(v)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse cert-manager version %q", v)
+	}
+	if semVersion.LessThan(version.MustParseSemantic(config.CertManagerMinimumVersion)) {
+		return errors.Errorf("cert-manager version %s is not supported, the minimum supported version is %s", v, config.CertManagerMinimumVersion)
+	}
+	return nil
 }
 
 // PlanUpgrade retruns a CertManagerUpgradePlan with information regarding
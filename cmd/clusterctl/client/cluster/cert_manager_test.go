@@ -657,6 +657,119 @@ func Test_certManagerClient_EnsureLatestVersion(t *testing.T) {
 	}
 }
 
+func Test_validateCertManagerVersion(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(validateCertManagerVersion(config.CertManagerDefaultVersion)).To(Succeed())
+	g.Expect(validateCertManagerVersion(config.CertManagerMinimumVersion)).To(Succeed())
+	g.Expect(validateCertManagerVersion("v0.16.1")).ToNot(Succeed())
+	g.Expect(validateCertManagerVersion("foo")).ToNot(Succeed())
+}
+
+func Test_certManagerClient_checkInstalledVersion(t *testing.T) {
+	certManagerDeployment := func(version string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			TypeMeta: metav1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "cert-manager",
+				Namespace:   certManagerNamespace,
+				Labels:      map[string]string{clusterctlv1.ClusterctlCoreLabelName: clusterctlv1.ClusterctlCoreLabelCertManagerValue},
+				Annotations: map[string]string{clusterctlv1.CertManagerVersionAnnotation: version},
+			},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		proxy   Proxy
+		wantErr bool
+	}{
+		{
+			name:    "pass if cert-manager is externally managed",
+			proxy:   test.NewFakeProxy(),
+			wantErr: false,
+		},
+		{
+			name:    "pass if the installed cert-manager meets the minimum version",
+			proxy:   test.NewFakeProxy().WithObjs(certManagerDeployment(config.CertManagerDefaultVersion)),
+			wantErr: false,
+		},
+		{
+			name:    "fails if the installed cert-manager is older than the minimum version",
+			proxy:   test.NewFakeProxy().WithObjs(certManagerDeployment("v0.16.1")),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			cm := &certManagerClient{
+				proxy: tt.proxy,
+			}
+
+			err := cm.checkInstalledVersion()
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+		})
+	}
+}
+
+func Test_certManagerClient_Info(t *testing.T) {
+	fakeRepository := test.NewFakeRepository().
+		WithPaths("root", "components.yaml").
+		WithDefaultVersion(config.CertManagerDefaultVersion).
+		WithFile(config.CertManagerDefaultVersion, "components.yaml", utilyaml.JoinYaml(certManagerNamespaceYaml, certManagerDeploymentYaml)).
+		WithFile("v0.16.1", "components.yaml", utilyaml.JoinYaml(certManagerNamespaceYaml, certManagerDeploymentYaml))
+
+	tests := []struct {
+		name    string
+		config  *fakeConfigClient
+		want    CertManagerInfo
+		wantErr bool
+	}{
+		{
+			name:   "returns the default cert-manager release",
+			config: newFakeConfig(),
+			want: CertManagerInfo{
+				URL:     config.CertManagerDefaultURL,
+				Version: config.CertManagerDefaultVersion,
+				Timeout: config.CertManagerDefaultTimeout.String(),
+				Images:  []string{"quay.io/jetstack/cert-manager:v1.1.0"},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "fails if the configured version is older than the minimum version",
+			config:  newFakeConfig().WithCertManager("", "v0.16.1", ""),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			cm := &certManagerClient{
+				configClient: tt.config,
+				repositoryClientFactory: func(provider config.Provider, configClient config.Client, options ...repository.Option) (repository.Client, error) {
+					return repository.New(provider, configClient, repository.InjectRepository(fakeRepository))
+				},
+			}
+
+			got, err := cm.Info()
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
+
 func newFakeConfig() *fakeConfigClient {
 	fakeReader := test.NewFakeReader()
 
@@ -690,6 +803,14 @@ func (f fakeConfigClient) ImageMeta() config.ImageMetaClient {
 	return f.internalclient.ImageMeta()
 }
 
+func (f fakeConfigClient) SignatureVerification() config.SignatureVerificationClient {
+	return f.internalclient.SignatureVerification()
+}
+
+func (f fakeConfigClient) Overlays() config.OverlaysClient {
+	return f.internalclient.Overlays()
+}
+
 func (f *fakeConfigClient) WithVar(key, value string) *fakeConfigClient {
 	f.fakeReader.WithVar(key, value)
 	return f
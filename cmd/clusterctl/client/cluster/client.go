@@ -89,6 +89,10 @@ type Client interface {
 
 	// WorkloadCluster has methods for fetching kubeconfig of workload cluster from management cluster.
 	WorkloadCluster() WorkloadCluster
+
+	// SupportBundle returns a SupportBundleCollector that can be used for collecting troubleshooting information
+	// from the management cluster.
+	SupportBundle() SupportBundleCollector
 }
 
 // PollImmediateWaiter tries a condition func until it returns true, an error, or the timeout is reached.
@@ -150,6 +154,10 @@ func (c *clusterClient) WorkloadCluster() WorkloadCluster {
 	return newWorkloadCluster(c.proxy)
 }
 
+func (c *clusterClient) SupportBundle() SupportBundleCollector {
+	return newSupportBundleCollector(c.proxy, c.ProviderInventory())
+}
+
 // Option is a configuration option supplied to New.
 type Option func(*clusterClient)
 
@@ -18,10 +18,12 @@ package cluster
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -48,6 +50,15 @@ type DeleteOptions struct {
 	IncludeCRDs      bool
 }
 
+// ComponentsDiff lists the provider components that are added, changed or removed when replacing the components
+// of a provider with a new set of components; each component is identified as Kind/namespace/name, or Kind/name
+// for cluster resources.
+type ComponentsDiff struct {
+	Added   []string
+	Changed []string
+	Removed []string
+}
+
 // ComponentsClient has methods to work with provider components in the cluster.
 type ComponentsClient interface {
 	// Create creates the provider components in the management cluster.
@@ -62,6 +73,12 @@ type ComponentsClient interface {
 	// DeleteWebhookNamespace deletes the core provider webhook namespace (eg. capi-webhook-system).
 	// This is required when upgrading to v1alpha4 where webhooks are included in the controller itself.
 	DeleteWebhookNamespace() error
+
+	// Diff compares the components of a provider in the management cluster with a new set of components, e.g. the
+	// components of the next version of the provider; the new components are submitted to the management cluster
+	// in dry-run mode, so the comparison takes into account defaulting and mutating webhooks.
+	// NOTE: the provider namespace and CRDs are never reported as removed, given that they are preserved on upgrades.
+	Diff(provider clusterctlv1.Provider, objs []unstructured.Unstructured) (*ComponentsDiff, error)
 }
 
 // providerComponents implements ComponentsClient.
@@ -130,13 +147,7 @@ func (p *providerComponents) Delete(options DeleteOptions) error {
 
 	// Fetch all the components belonging to a provider.
 	// We want that the delete operation is able to clean-up everything.
-	labels := map[string]string{
-		clusterctlv1.ClusterctlLabelName: "",
-		clusterv1.ProviderLabelName:      options.Provider.ManifestLabel(),
-	}
-
-	namespaces := []string{options.Provider.Namespace}
-	resources, err := p.proxy.ListResources(labels, namespaces...)
+	resources, err := p.listProviderResources(options.Provider)
 	if err != nil {
 		return err
 	}
@@ -144,7 +155,6 @@ func (p *providerComponents) Delete(options DeleteOptions) error {
 	// Filter the resources according to the delete options
 	resourcesToDelete := []unstructured.Unstructured{}
 	namespacesToDelete := sets.NewString()
-	instanceNamespacePrefix := fmt.Sprintf("%s-", options.Provider.Namespace)
 	for _, obj := range resources {
 		// If the CRDs should NOT be deleted, skip it;
 		// NB. Skipping CRDs deletion ensures that also the objects of Kind defined in the CRDs Kind are not deleted.
@@ -153,34 +163,15 @@ func (p *providerComponents) Delete(options DeleteOptions) error {
 			continue
 		}
 
-		// If the resource is a namespace
-		isNamespace := obj.GroupVersionKind().Kind == namespaceKind
-		if isNamespace {
-			// Skip all the namespaces not related to the provider instance being processed.
-			if obj.GetName() != options.Provider.Namespace {
-				continue
-			}
-			// If the  Namespace should NOT be deleted, skip it, otherwise keep track of the namespaces we are deleting;
-			// NB. Skipping Namespaces deletion ensures that also the objects hosted in the namespace but without the "clusterctl.cluster.x-k8s.io" and the "cluster.x-k8s.io/provider" label are not deleted.
+		// If the  Namespace should NOT be deleted, skip it, otherwise keep track of the namespaces we are deleting;
+		// NB. Skipping Namespaces deletion ensures that also the objects hosted in the namespace but without the "clusterctl.cluster.x-k8s.io" and the "cluster.x-k8s.io/provider" label are not deleted.
+		if obj.GroupVersionKind().Kind == namespaceKind {
 			if !options.IncludeNamespace {
 				continue
 			}
 			namespacesToDelete.Insert(obj.GetName())
 		}
 
-		// If the resource is a cluster resource, skip it if the resource name does not start with the instance prefix.
-		// This is required because there are cluster resources like e.g. ClusterRoles and ClusterRoleBinding, which are instance specific;
-		// During the installation, clusterctl adds the instance namespace prefix to such resources (see fixRBAC), and so we can rely
-		// on that for deleting only the global resources belonging the the instance we are processing.
-		// NOTE: namespace and CRD are special case managed above; webhook instead goes hand by hand with the controller they
-		// should always be deleted.
-		isWebhook := obj.GroupVersionKind().Kind == validatingWebhookConfigurationKind || obj.GroupVersionKind().Kind == mutatingWebhookConfigurationKind
-		if util.IsClusterResource(obj.GetKind()) &&
-			!isNamespace && !isCRD && !isWebhook &&
-			!strings.HasPrefix(obj.GetName(), instanceNamespacePrefix) {
-			continue
-		}
-
 		resourcesToDelete = append(resourcesToDelete, obj)
 	}
 
@@ -215,6 +206,132 @@ func (p *providerComponents) Delete(options DeleteOptions) error {
 	return kerrors.NewAggregate(errList)
 }
 
+// listProviderResources returns all the components belonging to a provider instance.
+func (p *providerComponents) listProviderResources(provider clusterctlv1.Provider) ([]unstructured.Unstructured, error) {
+	labels := map[string]string{
+		clusterctlv1.ClusterctlLabelName: "",
+		clusterv1.ProviderLabelName:      provider.ManifestLabel(),
+	}
+
+	namespaces := []string{provider.Namespace}
+	resources, err := p.proxy.ListResources(labels, namespaces...)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := []unstructured.Unstructured{}
+	instanceNamespacePrefix := fmt.Sprintf("%s-", provider.Namespace)
+	for _, obj := range resources {
+		isCRD := obj.GroupVersionKind().Kind == customResourceDefinitionKind
+
+		// Skip all the namespaces not related to the provider instance being processed.
+		isNamespace := obj.GroupVersionKind().Kind == namespaceKind
+		if isNamespace && obj.GetName() != provider.Namespace {
+			continue
+		}
+
+		// If the resource is a cluster resource, skip it if the resource name does not start with the instance prefix.
+		// This is required because there are cluster resources like e.g. ClusterRoles and ClusterRoleBinding, which are instance specific;
+		// During the installation, clusterctl adds the instance namespace prefix to such resources (see fixRBAC), and so we can rely
+		// on that for processing only the global resources belonging the the instance we are processing.
+		// NOTE: namespace and CRD are special case managed above; webhook instead goes hand by hand with the controller they
+		// should always be processed.
+		isWebhook := obj.GroupVersionKind().Kind == validatingWebhookConfigurationKind || obj.GroupVersionKind().Kind == mutatingWebhookConfigurationKind
+		if util.IsClusterResource(obj.GetKind()) &&
+			!isNamespace && !isCRD && !isWebhook &&
+			!strings.HasPrefix(obj.GetName(), instanceNamespacePrefix) {
+			continue
+		}
+
+		ret = append(ret, obj)
+	}
+	return ret, nil
+}
+
+func (p *providerComponents) Diff(provider clusterctlv1.Provider, objs []unstructured.Unstructured) (*ComponentsDiff, error) {
+	resources, err := p.listProviderResources(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := p.proxy.NewClient()
+	if err != nil {
+		return nil, err
+	}
+
+	current := map[string]unstructured.Unstructured{}
+	for _, obj := range resources {
+		current[componentRef(obj)] = obj
+	}
+
+	diff := &ComponentsDiff{}
+	desired := sets.NewString()
+	for i := range objs {
+		obj := objs[i].DeepCopy()
+		ref := componentRef(*obj)
+		desired.Insert(ref)
+
+		currentObj, ok := current[ref]
+		if !ok {
+			diff.Added = append(diff.Added, ref)
+			continue
+		}
+
+		// Submits the new object in dry-run mode, so the object returned by the API server can be compared with the current one.
+		// NOTE: the object is updated, not patched, because upgrades replace the provider objects; this way fields
+		// existing only in the current object are reported as changes too.
+		obj.SetResourceVersion(currentObj.GetResourceVersion())
+		if err := c.Update(ctx, obj, client.DryRunAll); err != nil {
+			// Objects that can't be updated, e.g. because of changes to immutable fields, are replaced on upgrades.
+			if apierrors.IsInvalid(err) {
+				diff.Changed = append(diff.Changed, ref)
+				continue
+			}
+			return nil, errors.Wrapf(err, "failed to update provider object %s in dry-run mode", ref)
+		}
+		if !equality.Semantic.DeepEqual(comparableComponent(currentObj), comparableComponent(*obj)) {
+			diff.Changed = append(diff.Changed, ref)
+		}
+	}
+
+	for ref, obj := range current {
+		if desired.Has(ref) {
+			continue
+		}
+		if kind := obj.GroupVersionKind().Kind; kind == customResourceDefinitionKind || kind == namespaceKind {
+			continue
+		}
+		diff.Removed = append(diff.Removed, ref)
+	}
+
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Changed)
+	sort.Strings(diff.Removed)
+	return diff, nil
+}
+
+// componentRef returns the Kind/namespace/name reference of a provider component; the API version is ignored,
+// so components moving to a new API version are not reported as removed and added.
+func componentRef(obj unstructured.Unstructured) string {
+	if obj.GetNamespace() == "" {
+		return fmt.Sprintf("%s/%s", obj.GetKind(), obj.GetName())
+	}
+	return fmt.Sprintf("%s/%s/%s", obj.GetKind(), obj.GetNamespace(), obj.GetName())
+}
+
+// comparableComponent returns the content of a provider component without the fields set by the API server.
+func comparableComponent(obj unstructured.Unstructured) map[string]interface{} {
+	content := obj.DeepCopy().UnstructuredContent()
+	delete(content, "apiVersion")
+	delete(content, "status")
+	unstructured.RemoveNestedField(content, "metadata", "resourceVersion")
+	unstructured.RemoveNestedField(content, "metadata", "generation")
+	unstructured.RemoveNestedField(content, "metadata", "managedFields")
+	unstructured.RemoveNestedField(content, "metadata", "creationTimestamp")
+	unstructured.RemoveNestedField(content, "metadata", "uid")
+	return content
+}
+
 func (p *providerComponents) DeleteWebhookNamespace() error {
 	const webhookNamespaceName = "capi-webhook-system"
 
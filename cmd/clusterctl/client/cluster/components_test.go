@@ -260,6 +260,69 @@ func Test_providerComponents_Delete(t *testing.T) {
 	}
 }
 
+func Test_providerComponents_Diff(t *testing.T) {
+	g := NewWithT(t)
+
+	labels := map[string]string{
+		clusterv1.ProviderLabelName: "infrastructure-infra",
+	}
+	newConfigMap := func(name, value string) *unstructured.Unstructured {
+		cm := &unstructured.Unstructured{}
+		cm.SetAPIVersion("v1")
+		cm.SetKind("ConfigMap")
+		cm.SetNamespace("ns1")
+		cm.SetName(name)
+		cm.SetLabels(labels)
+		g.Expect(unstructured.SetNestedField(cm.Object, value, "data", "key")).To(Succeed())
+		return cm
+	}
+	// Fields existing only in the current objects are reported as changes, given that upgrades replace the objects.
+	withExtraKey := func(cm *unstructured.Unstructured) *unstructured.Unstructured {
+		g.Expect(unstructured.SetNestedField(cm.Object, "foo", "data", "other")).To(Succeed())
+		return cm
+	}
+
+	crd := unstructured.Unstructured{}
+	crd.SetAPIVersion("apiextensions.k8s.io/v1beta1")
+	crd.SetKind("CustomResourceDefinition")
+	crd.SetName("crd1")
+	crd.SetLabels(labels)
+
+	initObjs := []client.Object{
+		&corev1.Namespace{
+			TypeMeta: metav1.TypeMeta{
+				Kind: "Namespace",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "ns1",
+				Labels: labels,
+			},
+		},
+		&crd,
+		newConfigMap("unchanged", "foo"),
+		newConfigMap("changed", "foo"),
+		newConfigMap("removed", "foo"),
+		withExtraKey(newConfigMap("removed-key", "foo")),
+	}
+
+	// The namespace and the CRD are not part of the new components, but they are preserved on upgrades.
+	objs := []unstructured.Unstructured{
+		*newConfigMap("unchanged", "foo"),
+		*newConfigMap("changed", "bar"),
+		*newConfigMap("added", "foo"),
+		*newConfigMap("removed-key", "foo"),
+	}
+
+	provider := clusterctlv1.Provider{ObjectMeta: metav1.ObjectMeta{Name: "infrastructure-infra", Namespace: "ns1"}, ProviderName: "infra", Type: string(clusterctlv1.InfrastructureProviderType)}
+
+	c := newComponentsClient(test.NewFakeProxy().WithObjs(initObjs...))
+	diff, err := c.Diff(provider, objs)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(diff.Added).To(Equal([]string{"ConfigMap/ns1/added"}))
+	g.Expect(diff.Changed).To(Equal([]string{"ConfigMap/ns1/changed", "ConfigMap/ns1/removed-key"}))
+	g.Expect(diff.Removed).To(Equal([]string{"ConfigMap/ns1/removed"}))
+}
+
 func Test_providerComponents_DeleteCoreProviderWebhookNamespace(t *testing.T) {
 	t.Run("deletes capi-webhook-system namespace", func(t *testing.T) {
 		g := NewWithT(t)
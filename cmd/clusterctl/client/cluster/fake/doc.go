@@ -0,0 +1,22 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package fake provides fakes for unit testing provider integrations with clusterctl, e.g. clusterctl move
+// or clusterctl generate cluster, without a real management cluster or provider repository.
+//
+// The fakes are the same used for testing clusterctl itself; they are exported as type aliases,
+// so they can be used with the clusterctl client library, e.g. with cluster.InjectProxy.
+package fake
@@ -0,0 +1,69 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake_test
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster/fake"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/repository"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestProxyWithObjects(t *testing.T) {
+	g := NewWithT(t)
+
+	proxy := fake.NewProxy().
+		WithObjs(fake.CRDList()...).
+		WithObjs(fake.NewCluster("ns1", "cluster1").
+			WithMachineDeployments(
+				fake.NewMachineDeployment("md1").
+					WithMachineSets(
+						fake.NewMachineSet("ms1").
+							WithMachines(fake.NewMachine("m1")),
+					),
+			).
+			Objs()...)
+
+	c, err := proxy.NewClient()
+	g.Expect(err).NotTo(HaveOccurred())
+
+	clusters := &clusterv1.ClusterList{}
+	g.Expect(c.List(context.Background(), clusters, client.InNamespace("ns1"))).To(Succeed())
+	g.Expect(clusters.Items).To(HaveLen(1))
+
+	machines := &clusterv1.MachineList{}
+	g.Expect(c.List(context.Background(), machines, client.InNamespace("ns1"))).To(Succeed())
+	g.Expect(machines.Items).To(HaveLen(1))
+	g.Expect(machines.Items[0].Spec.ClusterName).To(Equal("cluster1"))
+}
+
+func TestRepository(t *testing.T) {
+	g := NewWithT(t)
+
+	var repo repository.Repository = fake.NewRepository().
+		WithPaths("root", "components.yaml").
+		WithDefaultVersion("v1.0.0").
+		WithFile("v1.0.0", "components.yaml", []byte("components"))
+
+	g.Expect(repo.DefaultVersion()).To(Equal("v1.0.0"))
+	g.Expect(repo.GetVersions()).To(ConsistOf("v1.0.0"))
+	g.Expect(repo.GetFile("v1.0.0", "components.yaml")).To(Equal([]byte("components")))
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+	fakeinfrastructure "sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test/providers/infrastructure"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Cluster is a builder for a Cluster and all the related objects, e.g. the control plane, the Machines
+// and the secrets, to be used with Proxy.WithObjs.
+type Cluster = test.FakeCluster
+
+// NewCluster returns a builder for a Cluster.
+func NewCluster(namespace, name string) *Cluster {
+	return test.NewFakeCluster(namespace, name)
+}
+
+// ControlPlane is a builder for a control plane object and its Machines.
+type ControlPlane = test.FakeControlPlane
+
+// NewControlPlane returns a builder for a control plane object.
+func NewControlPlane(name string) *ControlPlane {
+	return test.NewFakeControlPlane(name)
+}
+
+// MachineDeployment is a builder for a MachineDeployment and its MachineSets.
+type MachineDeployment = test.FakeMachineDeployment
+
+// NewMachineDeployment returns a builder for a MachineDeployment.
+func NewMachineDeployment(name string) *MachineDeployment {
+	return test.NewFakeMachineDeployment(name)
+}
+
+// MachineSet is a builder for a MachineSet and its Machines.
+type MachineSet = test.FakeMachineSet
+
+// NewMachineSet returns a builder for a MachineSet.
+func NewMachineSet(name string) *MachineSet {
+	return test.NewFakeMachineSet(name)
+}
+
+// Machine is a builder for a Machine and its bootstrap and infrastructure objects.
+type Machine = test.FakeMachine
+
+// NewMachine returns a builder for a Machine.
+func NewMachine(name string) *Machine {
+	return test.NewFakeMachine(name)
+}
+
+// MachinePool is a builder for a MachinePool and its bootstrap and infrastructure objects.
+type MachinePool = test.FakeMachinePool
+
+// NewMachinePool returns a builder for a MachinePool.
+func NewMachinePool(name string) *MachinePool {
+	return test.NewFakeMachinePool(name)
+}
+
+// ClusterResourceSet is a builder for a ClusterResourceSet and the resources it applies.
+type ClusterResourceSet = test.FakeClusterResourceSet
+
+// NewClusterResourceSet returns a builder for a ClusterResourceSet.
+func NewClusterResourceSet(namespace, name string) *ClusterResourceSet {
+	return test.NewFakeClusterResourceSet(namespace, name)
+}
+
+// ExternalObject is a builder for a namespaced object, not owned by a Cluster, that must be moved
+// by clusterctl move.
+type ExternalObject = test.FakeExternalObject
+
+// NewExternalObject returns a builder for a namespaced external object.
+func NewExternalObject(namespace, name string) *ExternalObject {
+	return test.NewFakeExternalObject(namespace, name)
+}
+
+// ClusterExternalObject is a builder for a global object, not owned by a Cluster, that must be moved
+// by clusterctl move.
+type ClusterExternalObject = test.FakeClusterExternalObject
+
+// NewClusterExternalObject returns a builder for a global external object.
+func NewClusterExternalObject(name string) *ClusterExternalObject {
+	return test.NewFakeClusterExternalObject(name)
+}
+
+// ClusterInfrastructureIdentity is a builder for a global infrastructure identity object and its secret.
+type ClusterInfrastructureIdentity = test.FakeClusterInfrastructureIdentity
+
+// NewClusterInfrastructureIdentity returns a builder for a global infrastructure identity object.
+func NewClusterInfrastructureIdentity(name string) *ClusterInfrastructureIdentity {
+	return test.NewFakeClusterInfrastructureIdentity(name)
+}
+
+// NewInfrastructureTemplate returns an infrastructure machine template, to be used with
+// MachineDeployment.WithInfrastructureTemplate or MachineSet.WithInfrastructureTemplate.
+func NewInfrastructureTemplate(name string) *fakeinfrastructure.GenericInfrastructureMachineTemplate {
+	return test.NewFakeInfrastructureTemplate(name)
+}
+
+// CRDList returns the CustomResourceDefinitions for the types of the fake providers used
+// by the object builders in this package; they are required by clusterctl move for discovering the objects to move.
+func CRDList() []client.Object {
+	objs := []client.Object{}
+	for _, crd := range test.FakeCRDList() {
+		objs = append(objs, crd)
+	}
+	return objs
+}
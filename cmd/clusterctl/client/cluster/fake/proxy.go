@@ -0,0 +1,37 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+)
+
+var _ cluster.Proxy = &Proxy{}
+
+// Scheme is the scheme used by Proxy; it includes the Kubernetes, Cluster API and clusterctl types,
+// and the types of the fake providers used by the object builders in this package.
+// Providers using their own types with Proxy must add them to this scheme.
+var Scheme = test.FakeScheme
+
+// Proxy is a cluster.Proxy backed by a fake controller-runtime client.
+type Proxy = test.FakeProxy
+
+// NewProxy returns a Proxy for an empty management cluster.
+func NewProxy() *Proxy {
+	return test.NewFakeProxy()
+}
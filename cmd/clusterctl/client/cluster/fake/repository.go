@@ -0,0 +1,44 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/repository"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+)
+
+var (
+	_ repository.Repository = &Repository{}
+	_ config.Reader         = &ConfigReader{}
+)
+
+// Repository is an in memory provider repository, to be used with repository.InjectRepository.
+type Repository = test.FakeRepository
+
+// NewRepository returns an empty in memory provider repository.
+func NewRepository() *Repository {
+	return test.NewFakeRepository()
+}
+
+// ConfigReader is an in memory clusterctl configuration reader, to be used with config.InjectReader.
+type ConfigReader = test.FakeReader
+
+// NewConfigReader returns an empty in memory clusterctl configuration reader.
+func NewConfigReader() *ConfigReader {
+	return test.NewFakeReader()
+}
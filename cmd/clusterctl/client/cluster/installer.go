@@ -18,6 +18,7 @@ package cluster
 
 import (
 	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/version"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
@@ -27,6 +28,44 @@ import (
 	logf "sigs.k8s.io/cluster-api/cmd/clusterctl/log"
 )
 
+// InstallPhase defines a phase of the installation of the provider components.
+type InstallPhase string
+
+const (
+	// InstallCRDsPhase installs the CustomResourceDefinitions of the providers only; this phase usually requires
+	// elevated privileges on the management cluster.
+	InstallCRDsPhase InstallPhase = "crds"
+
+	// InstallComponentsPhase installs all the provider components except CustomResourceDefinitions, e.g. the
+	// controllers and the RBAC rules, and adds the providers to the inventory.
+	InstallComponentsPhase InstallPhase = "components"
+)
+
+// InstallPhases lists all the phases of the installation of the provider components, in order of execution.
+var InstallPhases = []InstallPhase{InstallCRDsPhase, InstallComponentsPhase}
+
+// InstallOptions defines the options for installing the provider components.
+type InstallOptions struct {
+	// Phases defines the installation phases to execute; if empty, all the phases are executed.
+	Phases []InstallPhase
+
+	// DryRun reports the changes to the provider components instead of applying them; it is supported by upgrades only.
+	DryRun bool
+}
+
+// HasPhase returns true if the given installation phase should be executed.
+func (o InstallOptions) HasPhase(phase InstallPhase) bool {
+	if len(o.Phases) == 0 {
+		return true
+	}
+	for _, p := range o.Phases {
+		if p == phase {
+			return true
+		}
+	}
+	return false
+}
+
 // ProviderInstaller defines methods for enforcing consistency rules for provider installation.
 type ProviderInstaller interface {
 	// Add adds a provider to the install queue.
@@ -34,8 +73,9 @@ type ProviderInstaller interface {
 	// before actually starting the installation of new providers.
 	Add(repository.Components)
 
-	// Install performs the installation of the providers ready in the install queue.
-	Install() ([]repository.Components, error)
+	// Install performs the installation of the providers ready in the install queue, executing the
+	// installation phases defined in the options.
+	Install(options InstallOptions) ([]repository.Components, error)
 
 	// Validate performs steps to validate a management cluster by looking at the current state and the providers in the queue.
 	// The following checks are performed in order to ensure a fully operational cluster:
@@ -43,6 +83,10 @@ type ProviderInstaller interface {
 	// - All the providers in must support the same API Version of Cluster API (contract)
 	Validate() error
 
+	// CheckPermissions checks the current identity is allowed to create all the objects of the providers ready in the
+	// install queue for the installation phases defined in the options, reporting all the missing permissions at once.
+	CheckPermissions(options InstallOptions) error
+
 	// Images returns the list of images required for installing the providers ready in the install queue.
 	Images() []string
 }
@@ -63,10 +107,10 @@ func (i *providerInstaller) Add(components repository.Components) {
 	i.installQueue = append(i.installQueue, components)
 }
 
-func (i *providerInstaller) Install() ([]repository.Components, error) {
+func (i *providerInstaller) Install(options InstallOptions) ([]repository.Components, error) {
 	ret := make([]repository.Components, 0, len(i.installQueue))
 	for _, components := range i.installQueue {
-		if err := installComponentsAndUpdateInventory(components, i.providerComponents, i.providerInventory); err != nil {
+		if err := installComponentsAndUpdateInventory(components, i.providerComponents, i.providerInventory, options); err != nil {
 			return nil, err
 		}
 
@@ -75,21 +119,57 @@ func (i *providerInstaller) Install() ([]repository.Components, error) {
 	return ret, nil
 }
 
-func installComponentsAndUpdateInventory(components repository.Components, providerComponents ComponentsClient, providerInventory InventoryClient) error {
+func installComponentsAndUpdateInventory(components repository.Components, providerComponents ComponentsClient, providerInventory InventoryClient, options InstallOptions) error {
 	log := logf.Log
-	log.Info("Installing", "Provider", components.ManifestLabel(), "Version", components.Version(), "TargetNamespace", components.TargetNamespace())
+	log.Info("Installing", "Provider", components.ManifestLabel(), "Version", components.Version(), "TargetNamespace", components.TargetNamespace(), "Phases", options.Phases)
 
 	inventoryObject := components.InventoryObject()
+	objs := componentsObjsForPhases(components, options)
 
 	log.V(1).Info("Creating objects", "Provider", components.ManifestLabel(), "Version", components.Version(), "TargetNamespace", components.TargetNamespace())
-	if err := providerComponents.Create(components.Objs()); err != nil {
+	if err := providerComponents.Create(objs); err != nil {
 		return err
 	}
 
+	// The provider is added to the inventory only when its components are installed, so a provider with only the
+	// CRDs in place is not considered as installed.
+	if !options.HasPhase(InstallComponentsPhase) {
+		return nil
+	}
+
 	log.V(1).Info("Creating inventory entry", "Provider", components.ManifestLabel(), "Version", components.Version(), "TargetNamespace", components.TargetNamespace())
 	return providerInventory.Create(inventoryObject)
 }
 
+// componentsObjsForPhases selects the objects to be created according to the installation phases; CRDs are created in
+// the crds phase, while all the other objects are created in the components phase.
+func componentsObjsForPhases(components repository.Components, options InstallOptions) []unstructured.Unstructured {
+	objs := []unstructured.Unstructured{}
+	for _, obj := range components.Objs() {
+		isCRD := obj.GetKind() == customResourceDefinitionKind
+		if (isCRD && options.HasPhase(InstallCRDsPhase)) || (!isCRD && options.HasPhase(InstallComponentsPhase)) {
+			objs = append(objs, obj)
+		}
+	}
+	return objs
+}
+
+func (i *providerInstaller) CheckPermissions(options InstallOptions) error {
+	required := []resourceAccess{}
+	for _, components := range i.installQueue {
+		// Provider objects are created, or patched if already existing.
+		for _, obj := range componentsObjsForPhases(components, options) {
+			required = append(required, newResourceAccess(obj.GroupVersionKind(), obj.GetNamespace(), "get", "create", "patch"))
+		}
+
+		// The provider is added to the inventory in the components phase.
+		if options.HasPhase(InstallComponentsPhase) {
+			required = append(required, newResourceAccess(clusterctlv1.GroupVersion.WithKind("Provider"), components.TargetNamespace(), "get", "create", "patch"))
+		}
+	}
+	return checkPermissions(i.proxy, required)
+}
+
 func (i *providerInstaller) Validate() error {
 	// Get the list of providers currently in the cluster.
 	providerList, err := i.providerInventory.List()
@@ -21,11 +21,14 @@ import (
 
 	. "github.com/onsi/gomega"
 
+	authorizationv1 "k8s.io/api/authorization/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/repository"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 func Test_providerInstaller_Validate(t *testing.T) {
@@ -257,13 +260,164 @@ func Test_providerInstaller_Validate(t *testing.T) {
 	}
 }
 
+func Test_installComponentsAndUpdateInventory(t *testing.T) {
+	crd := unstructured.Unstructured{}
+	crd.SetAPIVersion("apiextensions.k8s.io/v1")
+	crd.SetKind(customResourceDefinitionKind)
+	crd.SetName("infra1clusters.infrastructure.cluster.x-k8s.io")
+
+	configMap := unstructured.Unstructured{}
+	configMap.SetAPIVersion("v1")
+	configMap.SetKind("ConfigMap")
+	configMap.SetNamespace("infra1-system")
+	configMap.SetName("infra1-config")
+
+	tests := []struct {
+		name          string
+		phases        []InstallPhase
+		wantCRD       bool
+		wantConfigMap bool
+		wantInventory bool
+	}{
+		{
+			name:          "install all the phases if none is specified",
+			phases:        nil,
+			wantCRD:       true,
+			wantConfigMap: true,
+			wantInventory: true,
+		},
+		{
+			name:          "install only CRDs",
+			phases:        []InstallPhase{InstallCRDsPhase},
+			wantCRD:       true,
+			wantConfigMap: false,
+			wantInventory: false,
+		},
+		{
+			name:          "install only components",
+			phases:        []InstallPhase{InstallComponentsPhase},
+			wantCRD:       false,
+			wantConfigMap: true,
+			wantInventory: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			proxy := test.NewFakeProxy()
+			components := newFakeComponents("infra1", clusterctlv1.InfrastructureProviderType, "v1.0.0", "infra1-system").(*fakeComponents)
+			components.objs = []unstructured.Unstructured{crd, configMap}
+			components.inventoryObject.ResourceVersion = "" // NB. objects with a resourceVersion can't be created
+
+			inventory := newInventoryClient(proxy, nil)
+			err := installComponentsAndUpdateInventory(components, newComponentsClient(proxy), inventory, InstallOptions{Phases: tt.phases})
+			g.Expect(err).NotTo(HaveOccurred())
+
+			c, err := proxy.NewClient()
+			g.Expect(err).NotTo(HaveOccurred())
+
+			for _, want := range []struct {
+				obj    unstructured.Unstructured
+				exists bool
+			}{{crd, tt.wantCRD}, {configMap, tt.wantConfigMap}} {
+				got := &unstructured.Unstructured{}
+				got.SetGroupVersionKind(want.obj.GroupVersionKind())
+				err := c.Get(ctx, client.ObjectKey{Namespace: want.obj.GetNamespace(), Name: want.obj.GetName()}, got)
+				if want.exists {
+					g.Expect(err).NotTo(HaveOccurred())
+				} else {
+					g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+				}
+			}
+
+			providers, err := inventory.List()
+			g.Expect(err).NotTo(HaveOccurred())
+			if tt.wantInventory {
+				g.Expect(providers.Items).To(HaveLen(1))
+			} else {
+				g.Expect(providers.Items).To(BeEmpty())
+			}
+		})
+	}
+}
+
+func Test_providerInstaller_CheckPermissions(t *testing.T) {
+	crd := unstructured.Unstructured{}
+	crd.SetAPIVersion("apiextensions.k8s.io/v1")
+	crd.SetKind(customResourceDefinitionKind)
+	crd.SetName("infra1clusters.infrastructure.cluster.x-k8s.io")
+
+	deployment := unstructured.Unstructured{}
+	deployment.SetAPIVersion("apps/v1")
+	deployment.SetKind("Deployment")
+	deployment.SetNamespace("infra1-system")
+	deployment.SetName("infra1-controller-manager")
+
+	deniedCRDs := authorizationv1.ResourceAttributes{Verb: "create", Group: "apiextensions.k8s.io", Resource: "customresourcedefinitions"}
+	deniedDeployments := authorizationv1.ResourceAttributes{Namespace: "infra1-system", Verb: "patch", Group: "apps", Resource: "deployments"}
+
+	tests := []struct {
+		name        string
+		proxy       Proxy
+		phases      []InstallPhase
+		wantErr     bool
+		wantMissing []string
+	}{
+		{
+			name:    "pass if all the permissions are granted",
+			proxy:   test.NewFakeProxy(),
+			wantErr: false,
+		},
+		{
+			name:    "report all the missing permissions",
+			proxy:   test.NewFakeProxy().WithDeniedAccess(deniedCRDs, deniedDeployments),
+			wantErr: true,
+			wantMissing: []string{
+				"- create customresourcedefinitions.apiextensions.k8s.io cluster-wide",
+				"- patch deployments.apps in namespace infra1-system",
+			},
+		},
+		{
+			name:    "ignore the permissions for the phases not executed",
+			proxy:   test.NewFakeProxy().WithDeniedAccess(deniedCRDs),
+			phases:  []InstallPhase{InstallComponentsPhase},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			components := newFakeComponents("infra1", clusterctlv1.InfrastructureProviderType, "v1.0.0", "infra1-system").(*fakeComponents)
+			components.objs = []unstructured.Unstructured{crd, deployment}
+
+			installer := &providerInstaller{
+				proxy:        tt.proxy,
+				installQueue: []repository.Components{components},
+			}
+
+			err := installer.CheckPermissions(InstallOptions{Phases: tt.phases})
+			if !tt.wantErr {
+				g.Expect(err).NotTo(HaveOccurred())
+				return
+			}
+			g.Expect(err).To(HaveOccurred())
+			for _, missing := range tt.wantMissing {
+				g.Expect(err.Error()).To(ContainSubstring(missing))
+			}
+		})
+	}
+}
+
 type fakeComponents struct {
 	config.Provider
 	inventoryObject clusterctlv1.Provider
+	objs            []unstructured.Unstructured
 }
 
 func (c *fakeComponents) Version() string {
-	panic("not implemented")
+	return c.inventoryObject.Version
 }
 
 func (c *fakeComponents) Variables() []string {
@@ -275,7 +429,7 @@ func (c *fakeComponents) Images() []string {
 }
 
 func (c *fakeComponents) TargetNamespace() string {
-	panic("not implemented")
+	return c.inventoryObject.Namespace
 }
 
 func (c *fakeComponents) InventoryObject() clusterctlv1.Provider {
@@ -283,7 +437,7 @@ func (c *fakeComponents) InventoryObject() clusterctlv1.Provider {
 }
 
 func (c *fakeComponents) Objs() []unstructured.Unstructured {
-	panic("not implemented")
+	return c.objs
 }
 
 func (c *fakeComponents) Yaml() ([]byte, error) {
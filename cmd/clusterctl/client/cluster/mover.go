@@ -21,18 +21,25 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/apimachinery/pkg/util/wait"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
 	logf "sigs.k8s.io/cluster-api/cmd/clusterctl/log"
+	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/yaml"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -41,7 +48,10 @@ import (
 // ObjectMover defines methods for moving Cluster API objects to another management cluster.
 type ObjectMover interface {
 	// Move moves all the Cluster API objects existing in a namespace (or from all the namespaces if empty) to a target management cluster.
-	Move(namespace string, toCluster Client, dryRun bool) error
+	// If toNamespace is set, the objects are moved to the toNamespace namespace in the target management cluster.
+	// If createNamespace is set, the namespaces missing in the target management cluster are created, otherwise the move fails.
+	// The kindFilter defines additional kinds to be included in, or excluded from, the move.
+	Move(namespace, toNamespace string, toCluster Client, dryRun, createNamespace bool, kindFilter KindFilter) error
 	// Backup saves all the Cluster API objects existing in a namespace (or from all the namespaces if empty) to a target management cluster.
 	Backup(namespace string, directory string) error
 	// Restore restores all the Cluster API objects existing in a configured directory to a target management cluster.
@@ -53,12 +63,33 @@ type objectMover struct {
 	fromProxy             Proxy
 	fromProviderInventory InventoryClient
 	dryRun                bool
+
+	// toNamespace, if set, is the namespace where the moved objects are created in the target management cluster.
+	toNamespace string
+
+	// createNamespace, if set, allows to create the namespaces missing in the target management cluster.
+	createNamespace bool
+
+	// moveID identifies the current move request in the move hook annotations.
+	moveID string
+
+	// kindFilter defines additional kinds to be included in, or excluded from, the move.
+	kindFilter KindFilter
 }
 
+const (
+	// defaultMoveHookTimeout is the time clusterctl move waits for the provider to complete a move hook,
+	// if not otherwise specified on the CRD with the move-hook-timeout annotation.
+	defaultMoveHookTimeout = 5 * time.Minute
+)
+
+// moveHookPollInterval is the interval used for checking if the provider completed a move hook.
+var moveHookPollInterval = 2 * time.Second
+
 // ensure objectMover implements the ObjectMover interface.
 var _ ObjectMover = &objectMover{}
 
-func (o *objectMover) Move(namespace string, toCluster Client, dryRun bool) error {
+func (o *objectMover) Move(namespace, toNamespace string, toCluster Client, dryRun, createNamespace bool, kindFilter KindFilter) error {
 	log := logf.Log
 	log.Info("Performing move...")
 	o.dryRun = dryRun
@@ -68,6 +99,17 @@ func (o *objectMover) Move(namespace string, toCluster Client, dryRun bool) erro
 		log.Info("********************************************************")
 	}
 
+	if toNamespace != "" && toNamespace != namespace {
+		if namespace == "" {
+			return errors.New("moving objects to a different namespace requires the source namespace to be specified")
+		}
+		o.toNamespace = toNamespace
+	}
+
+	o.moveID = util.RandomString(6)
+	o.kindFilter = kindFilter
+	o.createNamespace = createNamespace
+
 	// checks that all the required providers in place in the target cluster.
 	if !o.dryRun {
 		if err := o.checkTargetProviders(toCluster.ProviderInventory()); err != nil {
@@ -80,12 +122,24 @@ func (o *objectMover) Move(namespace string, toCluster Client, dryRun bool) erro
 		return errors.Wrap(err, "failed to get object graph")
 	}
 
-	// Move the objects to the target cluster.
+	// Ensure all the objects can be remapped to the target namespace before moving anything.
+	if o.toNamespace != "" {
+		if err := o.checkNamespaceRemapping(objectGraph, namespace); err != nil {
+			return err
+		}
+	}
+
 	var proxy Proxy
 	if !o.dryRun {
 		proxy = toCluster.Proxy()
 	}
 
+	// Ensure the current identity is allowed to move all the objects before moving anything.
+	if err := o.checkPermissions(objectGraph, proxy); err != nil {
+		return err
+	}
+
+	// Move the objects to the target cluster.
 	return o.move(objectGraph, proxy)
 }
 
@@ -132,8 +186,10 @@ func (o *objectMover) Restore(toCluster Client, directory string) error {
 	// Check whether nodes are not included in GVK considered for restore.
 	objectGraph.checkVirtualNode()
 
-	// Restore the objects to the target cluster.
+	// Restore the objects to the target cluster; the target namespaces are created if missing, given that
+	// restore usually targets a new management cluster.
 	proxy := toCluster.Proxy()
+	o.createNamespace = true
 
 	return o.restore(objectGraph, proxy)
 }
@@ -171,8 +227,10 @@ func (o *objectMover) filesToObjs(dir string) ([]unstructured.Unstructured, erro
 
 func (o *objectMover) getObjectGraph(namespace string) (*objectGraph, error) {
 	objectGraph := newObjectGraph(o.fromProxy, o.fromProviderInventory)
+	objectGraph.kindFilter = o.kindFilter
 
-	// Gets all the types defined by the CRDs installed by clusterctl plus the ConfigMap/Secret core types.
+	// Gets all the types defined by the CRDs installed by clusterctl plus the ConfigMap/Secret core types,
+	// taking into account the kinds included in or excluded from the move.
 	err := objectGraph.getDiscoveryTypes()
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to retrieve discovery types")
@@ -185,6 +243,11 @@ func (o *objectMover) getObjectGraph(namespace string) (*objectGraph, error) {
 		return nil, errors.Wrap(err, "failed to discover the object graph")
 	}
 
+	// Ensure none of the objects to be moved is owned by an object of an excluded kind.
+	if err := objectGraph.checkExcludedOwners(); err != nil {
+		return nil, errors.Wrap(err, "failed to check owners excluded from move")
+	}
+
 	// Checks if Cluster API has already completed the provisioning of the infrastructure for the objects involved in the move/backup operation.
 	// This is required because if the infrastructure is provisioned, then we can reasonably assume that the objects we are moving/backing up are
 	// not currently waiting for long-running reconciliation loops, and so we can safely rely on the pause field on the Cluster object
@@ -308,10 +371,20 @@ func (o *objectMover) move(graph *objectGraph, toProxy Proxy) error {
 
 	// Sets the pause field on the Cluster object in the source management cluster, so the controllers stop reconciling it.
 	log.V(1).Info("Pausing the source cluster")
-	if err := setClusterPause(o.fromProxy, clusters, true, o.dryRun); err != nil {
+	if err := setClusterPause(o.fromProxy, clusters, "", true, o.dryRun); err != nil {
 		return err
 	}
 
+	// Request the providers to execute the pre-move actions for the objects with move hooks, e.g. quiescing a controller, and wait for completion.
+	// Nb. This happens before creating anything in the target cluster, so a failure here leaves the target cluster untouched.
+	hookNodes := graph.getMoveHookNodes()
+	if len(hookNodes) > 0 {
+		log.Info("Running pre-move hooks in the source cluster", "Objects", len(hookNodes))
+		if err := o.runMoveHooks(o.fromProxy, hookNodes, false, clusterctlv1.PreMoveHookAnnotation, clusterctlv1.PreMoveHookCompletedAnnotation); err != nil {
+			return errors.Wrap(err, "failed to run pre-move hooks")
+		}
+	}
+
 	// Ensure all the expected target namespaces are in place before creating objects.
 	log.V(1).Info("Creating target namespaces, if missing")
 	if err := o.ensureNamespaces(graph, toProxy); err != nil {
@@ -325,6 +398,11 @@ func (o *objectMover) move(graph *objectGraph, toProxy Proxy) error {
 	// - then all the MachineSets, then all the Machines, etc.
 	moveSequence := getMoveSequence(graph)
 
+	// In dry-run mode, print the objects that would be moved, so the move can be validated before actually executing it.
+	if o.dryRun {
+		logMovePlan(getMovePlan(moveSequence))
+	}
+
 	// Create all objects group by group, ensuring all the ownerReferences are re-created.
 	log.Info("Creating objects in the target cluster")
 	for groupIndex := 0; groupIndex < len(moveSequence.groups); groupIndex++ {
@@ -333,6 +411,13 @@ func (o *objectMover) move(graph *objectGraph, toProxy Proxy) error {
 		}
 	}
 
+	// Verify all the ownerReferences on the target cluster point to the objects actually existing there, fixing stale UIDs if any.
+	// Nb. This happens before deleting the source objects, so a failure here leaves the source cluster untouched.
+	log.Info("Verifying owner references in the target cluster")
+	if err := o.verifyOwnerReferences(moveSequence, toProxy); err != nil {
+		return err
+	}
+
 	// Delete all objects group by group in reverse order.
 	log.Info("Deleting objects from the source cluster")
 	for groupIndex := len(moveSequence.groups) - 1; groupIndex >= 0; groupIndex-- {
@@ -341,9 +426,17 @@ func (o *objectMover) move(graph *objectGraph, toProxy Proxy) error {
 		}
 	}
 
+	// Request the providers to execute the post-move actions for the objects with move hooks in the target cluster, and wait for completion.
+	if len(hookNodes) > 0 {
+		log.Info("Running post-move hooks in the target cluster", "Objects", len(hookNodes))
+		if err := o.runMoveHooks(toProxy, hookNodes, true, clusterctlv1.PostMoveHookAnnotation, clusterctlv1.PostMoveHookCompletedAnnotation); err != nil {
+			return errors.Wrap(err, "failed to run post-move hooks")
+		}
+	}
+
 	// Reset the pause field on the Cluster object in the target management cluster, so the controllers start reconciling it.
 	log.V(1).Info("Resuming the target cluster")
-	return setClusterPause(toProxy, clusters, false, o.dryRun)
+	return setClusterPause(toProxy, clusters, o.toNamespace, false, o.dryRun)
 }
 
 func (o *objectMover) backup(graph *objectGraph, directory string) error {
@@ -354,7 +447,7 @@ func (o *objectMover) backup(graph *objectGraph, directory string) error {
 
 	// Sets the pause field on the Cluster object in the source management cluster, so the controllers stop reconciling it.
 	log.V(1).Info("Pausing the source cluster")
-	if err := setClusterPause(o.fromProxy, clusters, true, o.dryRun); err != nil {
+	if err := setClusterPause(o.fromProxy, clusters, "", true, o.dryRun); err != nil {
 		return err
 	}
 
@@ -375,7 +468,7 @@ func (o *objectMover) backup(graph *objectGraph, directory string) error {
 
 	// Reset the pause field on the Cluster object in the target management cluster, so the controllers start reconciling it.
 	log.V(1).Info("Resuming the source cluster")
-	return setClusterPause(o.fromProxy, clusters, false, o.dryRun)
+	return setClusterPause(o.fromProxy, clusters, "", false, o.dryRun)
 }
 
 func (o *objectMover) restore(graph *objectGraph, toProxy Proxy) error {
@@ -405,10 +498,18 @@ func (o *objectMover) restore(graph *objectGraph, toProxy Proxy) error {
 		}
 	}
 
+	// Verify all the ownerReferences on the target cluster point to the objects actually existing there, fixing stale UIDs if any.
+	// Nb. This is required because objects already existing in the target cluster are not restored, and thus they could
+	// still reference owners with the UIDs of a previous, partially failed, restore.
+	log.Info("Verifying owner references in the target cluster")
+	if err := o.verifyOwnerReferences(moveSequence, toProxy); err != nil {
+		return err
+	}
+
 	// Resume reconciling the Clusters after being restored from a backup.
 	// By default, during backup, Clusters are paused so they must be unpaused to be used again
 	log.V(1).Info("Resuming the target cluster")
-	return setClusterPause(toProxy, clusters, false, o.dryRun)
+	return setClusterPause(toProxy, clusters, "", false, o.dryRun)
 }
 
 // moveSequence defines a list of group of moveGroups.
@@ -438,6 +539,70 @@ func (s *moveSequence) getGroup(i int) moveGroup {
 	return s.groups[i]
 }
 
+// movePlanEntry describes an object to be moved, as printed in the move plan.
+type movePlanEntry struct {
+	// group is the index of the move group the object belongs to; groups are moved in order, starting from 1.
+	group int
+
+	// identity is the identity of the object to be moved.
+	identity corev1.ObjectReference
+
+	// softOwners are the objects the object is linked to without an OwnerReference, e.g. the Cluster
+	// a Secret is linked to by naming convention.
+	softOwners []corev1.ObjectReference
+}
+
+// getMovePlan returns the objects to be moved in the order they are moved to the target management cluster;
+// objects in the same move group are sorted by kind, namespace and name.
+func getMovePlan(moveSequence *moveSequence) []movePlanEntry {
+	plan := []movePlanEntry{}
+	for i, group := range moveSequence.groups {
+		entries := []movePlanEntry{}
+		for _, n := range group {
+			entry := movePlanEntry{group: i + 1, identity: n.identity}
+			for softOwner := range n.softOwners {
+				entry.softOwners = append(entry.softOwners, softOwner.identity)
+			}
+			sort.Slice(entry.softOwners, func(i, j int) bool {
+				return objectReferenceLess(entry.softOwners[i], entry.softOwners[j])
+			})
+			entries = append(entries, entry)
+		}
+		sort.Slice(entries, func(i, j int) bool {
+			return objectReferenceLess(entries[i].identity, entries[j].identity)
+		})
+		plan = append(plan, entries...)
+	}
+	return plan
+}
+
+func objectReferenceLess(a, b corev1.ObjectReference) bool {
+	if a.Kind != b.Kind {
+		return a.Kind < b.Kind
+	}
+	if a.Namespace != b.Namespace {
+		return a.Namespace < b.Namespace
+	}
+	return a.Name < b.Name
+}
+
+// logMovePlan prints the objects to be moved, together with the soft ownerships detected during discovery.
+func logMovePlan(plan []movePlanEntry) {
+	log := logf.Log
+	log.Info("Move plan", "Objects", len(plan))
+	for _, entry := range plan {
+		values := []interface{}{"Group", entry.group, entry.identity.Kind, entry.identity.Name, "Namespace", entry.identity.Namespace}
+		if len(entry.softOwners) > 0 {
+			softOwners := make([]string, 0, len(entry.softOwners))
+			for _, softOwner := range entry.softOwners {
+				softOwners = append(softOwners, fmt.Sprintf("%s %s/%s", softOwner.Kind, softOwner.Namespace, softOwner.Name))
+			}
+			values = append(values, "SoftOwners", strings.Join(softOwners, ", "))
+		}
+		log.Info("Object to be moved", values...)
+	}
+}
+
 // Define the move sequence by processing the ownerReference chain.
 func getMoveSequence(graph *objectGraph) *moveSequence {
 	moveSequence := &moveSequence{
@@ -487,7 +652,8 @@ func getMoveSequence(graph *objectGraph) *moveSequence {
 }
 
 // setClusterPause sets the paused field on nodes referring to Cluster objects.
-func setClusterPause(proxy Proxy, clusters []*node, value bool, dryRun bool) error {
+// If namespace is set, the Cluster objects are read from this namespace instead of the one of the nodes.
+func setClusterPause(proxy Proxy, clusters []*node, namespace string, value bool, dryRun bool) error {
 	if dryRun {
 		return nil
 	}
@@ -502,7 +668,7 @@ func setClusterPause(proxy Proxy, clusters []*node, value bool, dryRun bool) err
 
 		// Nb. The operation is wrapped in a retry loop to make setClusterPause more resilient to unexpected conditions.
 		if err := retryWithExponentialBackoff(setClusterPauseBackoff, func() error {
-			return patchCluster(proxy, cluster, patch)
+			return patchCluster(proxy, cluster, namespace, patch)
 		}); err != nil {
 			return errors.Wrapf(err, "error setting Cluster.Spec.Paused=%t", value)
 		}
@@ -511,15 +677,19 @@ func setClusterPause(proxy Proxy, clusters []*node, value bool, dryRun bool) err
 }
 
 // patchCluster applies a patch to a node referring to a Cluster object.
-func patchCluster(proxy Proxy, cluster *node, patch client.Patch) error {
+func patchCluster(proxy Proxy, cluster *node, namespace string, patch client.Patch) error {
 	cFrom, err := proxy.NewClient()
 	if err != nil {
 		return err
 	}
 
+	if namespace == "" {
+		namespace = cluster.identity.Namespace
+	}
+
 	clusterObj := &clusterv1.Cluster{}
 	clusterObjKey := client.ObjectKey{
-		Namespace: cluster.identity.Namespace,
+		Namespace: namespace,
 		Name:      cluster.identity.Name,
 	}
 
@@ -536,28 +706,285 @@ func patchCluster(proxy Proxy, cluster *node, patch client.Patch) error {
 	return nil
 }
 
-// ensureNamespaces ensures all the expected target namespaces are in place before creating objects.
-func (o *objectMover) ensureNamespaces(graph *objectGraph, toProxy Proxy) error {
+// moveHookAnnotations are the annotations used for requesting and reporting move hooks.
+var moveHookAnnotations = []string{
+	clusterctlv1.PreMoveHookAnnotation,
+	clusterctlv1.PreMoveHookCompletedAnnotation,
+	clusterctlv1.PostMoveHookAnnotation,
+	clusterctlv1.PostMoveHookCompletedAnnotation,
+	clusterctlv1.MoveHookFailedAnnotation,
+}
+
+// removeMoveHookAnnotations removes all the move hook annotations from an object.
+func removeMoveHookAnnotations(obj *unstructured.Unstructured) {
+	annotations := obj.GetAnnotations()
+	if len(annotations) == 0 {
+		return
+	}
+	for _, a := range moveHookAnnotations {
+		delete(annotations, a)
+	}
+	obj.SetAnnotations(annotations)
+}
+
+// runMoveHooks requests the providers to execute a move hook on the objects corresponding to the hook nodes, by setting the hook annotation
+// with the move ID as a value, and then waits for all the providers to report completion by setting the completed annotation to the same value.
+// If target is true, the objects are read from the target namespace instead of the one of the nodes.
+// The move hook is considered failed if the provider sets the move-hook-failed annotation or if the move hook timeout for the object type expires.
+func (o *objectMover) runMoveHooks(proxy Proxy, hookNodes map[*node]time.Duration, target bool, hookAnnotation, completedAnnotation string) error {
 	if o.dryRun {
 		return nil
 	}
 
-	ensureNamespaceBackoff := newWriteBackoff()
-	namespaces := sets.NewString()
-	for _, node := range graph.getMoveNodes() {
-		// ignore global/cluster-wide objects
-		if node.isGlobal {
+	log := logf.Log
+
+	// Sort the nodes, so hooks are requested in a predictable order.
+	nodes := make([]*node, 0, len(hookNodes))
+	for n := range hookNodes {
+		nodes = append(nodes, n)
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		a, b := nodes[i].identity, nodes[j].identity
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+
+	namespace := func(n *node) string {
+		if target {
+			return o.targetNamespace(n)
+		}
+		return n.identity.Namespace
+	}
+
+	// Request the move hook on all the objects first, so providers can execute the hooks in parallel.
+	setMoveHookBackoff := newWriteBackoff()
+	for i := range nodes {
+		hookNode := nodes[i]
+		log.V(1).Info("Requesting move hook", "Hook", hookAnnotation, hookNode.identity.Kind, hookNode.identity.Name, "Namespace", namespace(hookNode))
+
+		// Nb. The operation is wrapped in a retry loop to make move more resilient to unexpected conditions.
+		if err := retryWithExponentialBackoff(setMoveHookBackoff, func() error {
+			return o.setMoveHookAnnotation(proxy, hookNode, namespace(hookNode), hookAnnotation)
+		}); err != nil {
+			return err
+		}
+	}
+
+	// Wait for the providers to complete the move hook on all the objects, recording the results.
+	errList := []error{}
+	for i := range nodes {
+		hookNode := nodes[i]
+		start := time.Now()
+		if err := o.waitForMoveHook(proxy, hookNode, namespace(hookNode), completedAnnotation, hookNodes[hookNode]); err != nil {
+			errList = append(errList, err)
 			continue
 		}
+		log.V(1).Info("Move hook completed", "Hook", hookAnnotation, hookNode.identity.Kind, hookNode.identity.Name, "Namespace", namespace(hookNode), "Duration", time.Since(start).Round(time.Second).String())
+	}
+
+	return kerrors.NewAggregate(errList)
+}
+
+// setMoveHookAnnotation sets the hook annotation with the move ID on the object corresponding to the node, removing
+// the move-hook-failed annotation possibly left over from a previous move attempt.
+func (o *objectMover) setMoveHookAnnotation(proxy Proxy, hookNode *node, namespace string, hookAnnotation string) error {
+	c, err := proxy.NewClient()
+	if err != nil {
+		return err
+	}
+
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(hookNode.identity.APIVersion)
+	obj.SetKind(hookNode.identity.Kind)
+	objKey := client.ObjectKey{
+		Namespace: namespace,
+		Name:      hookNode.identity.Name,
+	}
+
+	if err := c.Get(ctx, objKey, obj); err != nil {
+		return errors.Wrapf(err, "error reading %q %s/%s",
+			obj.GroupVersionKind(), objKey.Namespace, objKey.Name)
+	}
+
+	patchBase := client.MergeFrom(obj.DeepCopy())
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[hookAnnotation] = o.moveID
+	delete(annotations, clusterctlv1.MoveHookFailedAnnotation)
+	obj.SetAnnotations(annotations)
+
+	if err := c.Patch(ctx, obj, patchBase); err != nil {
+		return errors.Wrapf(err, "error setting the %s annotation on %q %s/%s",
+			hookAnnotation, obj.GroupVersionKind(), obj.GetNamespace(), obj.GetName())
+	}
+	return nil
+}
+
+// waitForMoveHook waits for the provider to set the completed annotation with the move ID on the object corresponding to the node.
+func (o *objectMover) waitForMoveHook(proxy Proxy, hookNode *node, namespace string, completedAnnotation string, timeout time.Duration) error {
+	c, err := proxy.NewClient()
+	if err != nil {
+		return err
+	}
+
+	objKey := client.ObjectKey{
+		Namespace: namespace,
+		Name:      hookNode.identity.Name,
+	}
+
+	var hookErr error
+	err = wait.PollImmediate(moveHookPollInterval, timeout, func() (bool, error) {
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion(hookNode.identity.APIVersion)
+		obj.SetKind(hookNode.identity.Kind)
+		if err := c.Get(ctx, objKey, obj); err != nil {
+			// Tolerate read errors, the object is read again at the next poll.
+			hookErr = errors.Wrapf(err, "error reading %q %s/%s", obj.GroupVersionKind(), objKey.Namespace, objKey.Name)
+			return false, nil
+		}
+		hookErr = nil
+
+		annotations := obj.GetAnnotations()
+		if message, ok := annotations[clusterctlv1.MoveHookFailedAnnotation]; ok {
+			return false, errors.Errorf("move hook failed for %q %s/%s: %s", obj.GroupVersionKind(), objKey.Namespace, objKey.Name, message)
+		}
+		return annotations[completedAnnotation] == o.moveID, nil
+	})
+	if err == wait.ErrWaitTimeout {
+		if hookErr != nil {
+			return errors.Wrapf(hookErr, "timed out after %s waiting for the move hook on %s %s/%s", timeout, hookNode.identity.Kind, objKey.Namespace, objKey.Name)
+		}
+		return errors.Errorf("timed out after %s waiting for the move hook on %s %s/%s", timeout, hookNode.identity.Kind, objKey.Namespace, objKey.Name)
+	}
+	return err
+}
+
+// targetNamespace returns the namespace of the object corresponding to the object graph node in the target management cluster.
+// Nb. Objects belonging to a global hierarchy, e.g. secrets holding credentials for a global identity object, are never remapped.
+func (o *objectMover) targetNamespace(n *node) string {
+	if o.toNamespace == "" || n.isGlobal || n.isGlobalHierarchy {
+		return n.identity.Namespace
+	}
+	return o.toNamespace
+}
+
+// checkNamespaceRemapping checks all the objects to be moved can be remapped to the target namespace, rejecting object graphs
+// with objects outside of the source namespace or with references to objects in other namespaces, which can't be remapped.
+func (o *objectMover) checkNamespaceRemapping(graph *objectGraph, namespace string) error {
+	cFrom, err := o.fromProxy.NewClient()
+	if err != nil {
+		return err
+	}
 
-		namespace := node.identity.Namespace
+	errList := []error{}
+	for _, n := range graph.getMoveNodes() {
+		if n.isGlobal || n.isGlobalHierarchy {
+			continue
+		}
 
-		// If the namespace was already processed, skip it.
-		if namespaces.Has(namespace) {
+		if n.identity.Namespace != namespace {
+			errList = append(errList, errors.Errorf("%s %s/%s is not in the %s namespace", n.identity.Kind, n.identity.Namespace, n.identity.Name, namespace))
 			continue
 		}
-		namespaces.Insert(namespace)
 
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion(n.identity.APIVersion)
+		obj.SetKind(n.identity.Kind)
+		if err := cFrom.Get(ctx, client.ObjectKey{Namespace: n.identity.Namespace, Name: n.identity.Name}, obj); err != nil {
+			return errors.Wrapf(err, "error reading %q %s/%s",
+				obj.GroupVersionKind(), n.identity.Namespace, n.identity.Name)
+		}
+
+		if invalid := remapNamespaceReferences(obj.Object, namespace, o.toNamespace); len(invalid) > 0 {
+			errList = append(errList, errors.Errorf("%s %s/%s has references to objects in other namespaces: %s", n.identity.Kind, n.identity.Namespace, n.identity.Name, strings.Join(invalid, ", ")))
+		}
+	}
+
+	if len(errList) > 0 {
+		return errors.Wrapf(kerrors.NewAggregate(errList), "failed to remap objects to the %s namespace", o.toNamespace)
+	}
+
+	return nil
+}
+
+// remapNamespaceReferences changes the namespace of all the references to other objects in the from namespace, e.g. the
+// spec.infrastructureRef of a Cluster, to the to namespace; it returns the path of the references to objects in other namespaces.
+// Nb. metadata and status are not processed, given that they are not required for creating the object in the target namespace.
+func remapNamespaceReferences(obj map[string]interface{}, from, to string) []string {
+	invalid := []string{}
+	for k, v := range obj {
+		if k == "metadata" || k == "status" {
+			continue
+		}
+		invalid = append(invalid, remapNamespaceReferencesInValue(k, v, from, to)...)
+	}
+	sort.Strings(invalid)
+	return invalid
+}
+
+func remapNamespaceReferencesInValue(path string, value interface{}, from, to string) []string {
+	invalid := []string{}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		// Nb. A map with both name and namespace is considered a reference to another object, e.g. a corev1.ObjectReference.
+		if namespace, ok := v["namespace"].(string); ok {
+			if _, ok := v["name"].(string); ok {
+				switch namespace {
+				case "":
+				case from:
+					v["namespace"] = to
+				default:
+					invalid = append(invalid, path)
+				}
+			}
+		}
+		for k, item := range v {
+			invalid = append(invalid, remapNamespaceReferencesInValue(path+"."+k, item, from, to)...)
+		}
+	case []interface{}:
+		for i, item := range v {
+			invalid = append(invalid, remapNamespaceReferencesInValue(fmt.Sprintf("%s[%d]", path, i), item, from, to)...)
+		}
+	}
+	return invalid
+}
+
+// ensureNamespaces ensures all the expected target namespaces are in place before creating objects.
+// If namespace creation is not allowed, all the missing namespaces are reported before creating any object.
+func (o *objectMover) ensureNamespaces(graph *objectGraph, toProxy Proxy) error {
+	if o.dryRun {
+		return nil
+	}
+
+	namespaces := o.targetNamespaces(graph)
+
+	if !o.createNamespace {
+		missing := []string{}
+		for _, namespace := range namespaces {
+			exists, err := namespaceExists(toProxy, namespace)
+			if err != nil {
+				return err
+			}
+			if !exists {
+				missing = append(missing, namespace)
+			}
+		}
+		if len(missing) > 0 {
+			return errors.Errorf("the following namespaces do not exist in the target management cluster: %s; create them or enable namespace creation (e.g. clusterctl move --create-namespace)", strings.Join(missing, ", "))
+		}
+		return nil
+	}
+
+	ensureNamespaceBackoff := newWriteBackoff()
+	for _, namespace := range namespaces {
+		namespace := namespace
 		if err := retryWithExponentialBackoff(ensureNamespaceBackoff, func() error {
 			return o.ensureNamespace(toProxy, namespace)
 		}); err != nil {
@@ -568,16 +995,26 @@ func (o *objectMover) ensureNamespaces(graph *objectGraph, toProxy Proxy) error
 	return nil
 }
 
-// ensureNamespace ensures a target namespaces is in place before creating objects.
-func (o *objectMover) ensureNamespace(toProxy Proxy, namespace string) error {
-	log := logf.Log
+// targetNamespaces returns the list of the namespaces where the objects in the graph are created in the target management cluster.
+func (o *objectMover) targetNamespaces(graph *objectGraph) []string {
+	namespaces := sets.NewString()
+	for _, node := range graph.getMoveNodes() {
+		// ignore global/cluster-wide objects
+		if node.isGlobal {
+			continue
+		}
+		namespaces.Insert(o.targetNamespace(node))
+	}
+	return namespaces.List()
+}
 
+// namespaceExists checks if a namespace exists in the target management cluster (also dealing with RBAC restrictions).
+func namespaceExists(toProxy Proxy, namespace string) (bool, error) {
 	cs, err := toProxy.NewClient()
 	if err != nil {
-		return err
+		return false, err
 	}
 
-	// Otherwise check if namespace exists (also dealing with RBAC restrictions).
 	ns := &corev1.Namespace{}
 	key := client.ObjectKey{
 		Name: namespace,
@@ -585,20 +1022,18 @@ func (o *objectMover) ensureNamespace(toProxy Proxy, namespace string) error {
 
 	err = cs.Get(ctx, key, ns)
 	if err == nil {
-		return nil
+		return true, nil
 	}
 	if apierrors.IsForbidden(err) {
 		namespaces := &corev1.NamespaceList{}
-		namespaceExists := false
 		for {
 			if err := cs.List(ctx, namespaces, client.Continue(namespaces.Continue)); err != nil {
-				return err
+				return false, err
 			}
 
 			for _, ns := range namespaces.Items {
 				if ns.Name == namespace {
-					namespaceExists = true
-					break
+					return true, nil
 				}
 			}
 
@@ -606,16 +1041,33 @@ func (o *objectMover) ensureNamespace(toProxy Proxy, namespace string) error {
 				break
 			}
 		}
-		if namespaceExists {
-			return nil
-		}
+		return false, nil
 	}
 	if !apierrors.IsNotFound(err) {
+		return false, err
+	}
+	return false, nil
+}
+
+// ensureNamespace ensures a target namespaces is in place before creating objects.
+func (o *objectMover) ensureNamespace(toProxy Proxy, namespace string) error {
+	log := logf.Log
+
+	exists, err := namespaceExists(toProxy, namespace)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	cs, err := toProxy.NewClient()
+	if err != nil {
 		return err
 	}
 
 	// If the namespace does not exists, create it.
-	ns = &corev1.Namespace{
+	ns := &corev1.Namespace{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: "v1",
 			Kind:       "Namespace",
@@ -732,9 +1184,19 @@ func (o *objectMover) createTargetObject(nodeToCreate *node, toProxy Proxy) erro
 	// Removes current OwnerReferences
 	obj.SetOwnerReferences(nil)
 
+	// Removes the move hook annotations set in the source cluster, so they are not mistaken for requests in the target cluster.
+	removeMoveHookAnnotations(obj)
+
 	// Rebuild the owne reference chain
 	o.buildOwnerChain(obj, nodeToCreate)
 
+	// Remap the object and its references to other objects to the target namespace, if required.
+	if targetNamespace := o.targetNamespace(nodeToCreate); targetNamespace != nodeToCreate.identity.Namespace {
+		obj.SetNamespace(targetNamespace)
+		remapNamespaceReferences(obj.Object, nodeToCreate.identity.Namespace, targetNamespace)
+		objKey.Namespace = targetNamespace
+	}
+
 	// Creates the targetObj into the target management cluster.
 	cTo, err := toProxy.NewClient()
 	if err != nil {
@@ -906,6 +1368,147 @@ func (o *objectMover) buildOwnerChain(obj *unstructured.Unstructured, n *node) {
 	}
 }
 
+// ownerReferencesSummary reports the result of the verification of the ownerReferences in the target management cluster.
+type ownerReferencesSummary struct {
+	// Verified is the number of objects verified.
+	Verified int
+	// Fixed is the number of objects with ownerReferences patched to point to the current UID of their owners.
+	Fixed int
+	// Missing lists the objects that should exist in the target management cluster, but could not be found.
+	Missing []string
+}
+
+// verifyOwnerReferences re-resolves the UIDs of all the objects in the target management cluster and patches the ownerReferences
+// still pointing at stale UIDs, e.g. because of objects created by a previous, partially failed, move or restore.
+func (o *objectMover) verifyOwnerReferences(moveSequence *moveSequence, toProxy Proxy) error {
+	log := logf.Log
+
+	if o.dryRun {
+		return nil
+	}
+
+	summary := &ownerReferencesSummary{}
+	verifyOwnerReferencesBackoff := newWriteBackoff()
+	errList := []error{}
+
+	// Nb. Objects are processed following the move sequence, so the UIDs of the owners are re-resolved before verifying the objects they own.
+	for groupIndex := 0; groupIndex < len(moveSequence.groups); groupIndex++ {
+		for _, nodeToVerify := range moveSequence.getGroup(groupIndex) {
+			// Nb. The operation is wrapped in a retry loop to make move more resilient to unexpected conditions.
+			err := retryWithExponentialBackoff(verifyOwnerReferencesBackoff, func() error {
+				return o.verifyTargetObjectOwnerReferences(nodeToVerify, toProxy, summary)
+			})
+			if err != nil {
+				errList = append(errList, err)
+			}
+		}
+	}
+
+	log.Info("Owner references verified", "Objects", summary.Verified, "Fixed", summary.Fixed, "Missing", len(summary.Missing))
+
+	if len(summary.Missing) > 0 {
+		errList = append(errList, errors.Errorf("the following objects are missing in the target cluster: %s", strings.Join(summary.Missing, ", ")))
+	}
+
+	if len(errList) > 0 {
+		return kerrors.NewAggregate(errList)
+	}
+
+	return nil
+}
+
+// verifyTargetObjectOwnerReferences ensures the object in the target management cluster corresponding to the object graph node
+// has ownerReferences pointing to the current UID of all its owners, patching the object if necessary.
+func (o *objectMover) verifyTargetObjectOwnerReferences(nodeToVerify *node, toProxy Proxy, summary *ownerReferencesSummary) error {
+	log := logf.Log
+
+	cTo, err := toProxy.NewClient()
+	if err != nil {
+		return err
+	}
+
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(nodeToVerify.identity.APIVersion)
+	obj.SetKind(nodeToVerify.identity.Kind)
+	objKey := client.ObjectKey{
+		Namespace: o.targetNamespace(nodeToVerify),
+		Name:      nodeToVerify.identity.Name,
+	}
+
+	if err := cTo.Get(ctx, objKey, obj); err != nil {
+		if apierrors.IsNotFound(err) {
+			summary.Missing = append(summary.Missing, fmt.Sprintf("%s %s/%s", nodeToVerify.identity.Kind, objKey.Namespace, nodeToVerify.identity.Name))
+			return nil
+		}
+		return errors.Wrapf(err, "error reading %q %s/%s",
+			obj.GroupVersionKind(), obj.GetNamespace(), obj.GetName())
+	}
+
+	// Re-resolve the UID of the object, so the objects owned by this one are verified against the UID actually existing in the target cluster.
+	nodeToVerify.newUID = obj.GetUID()
+
+	ownerRefs, changed := reconcileOwnerReferences(obj.GetOwnerReferences(), nodeToVerify)
+	if !changed {
+		summary.Verified++
+		return nil
+	}
+
+	log.V(1).Info("Fixing owner references", nodeToVerify.identity.Kind, nodeToVerify.identity.Name, "Namespace", nodeToVerify.identity.Namespace)
+
+	patchBase := client.MergeFrom(obj.DeepCopy())
+	obj.SetOwnerReferences(ownerRefs)
+	if err := cTo.Patch(ctx, obj, patchBase); err != nil {
+		return errors.Wrapf(err, "error patching owner references for %q %s/%s",
+			obj.GroupVersionKind(), obj.GetNamespace(), obj.GetName())
+	}
+	summary.Verified++
+	summary.Fixed++
+
+	return nil
+}
+
+// reconcileOwnerReferences returns the ownerReferences for an object, ensuring there is an ownerReference with the current UID
+// for every owner of the object graph node; ownerReferences to objects not included in the object graph are preserved.
+func reconcileOwnerReferences(ownerRefs []metav1.OwnerReference, n *node) ([]metav1.OwnerReference, bool) {
+	ret := append([]metav1.OwnerReference{}, ownerRefs...)
+	changed := false
+
+	for ownerNode := range n.owners {
+		ownerGK := ownerNode.identity.GroupVersionKind().GroupKind()
+
+		found := false
+		for i := range ret {
+			refGV, err := schema.ParseGroupVersion(ret[i].APIVersion)
+			if err != nil || refGV.WithKind(ret[i].Kind).GroupKind() != ownerGK || ret[i].Name != ownerNode.identity.Name {
+				continue
+			}
+			found = true
+			if ret[i].UID != ownerNode.newUID {
+				ret[i].UID = ownerNode.newUID
+				changed = true
+			}
+		}
+		if found {
+			continue
+		}
+
+		ownerRef := metav1.OwnerReference{
+			APIVersion: ownerNode.identity.APIVersion,
+			Kind:       ownerNode.identity.Kind,
+			Name:       ownerNode.identity.Name,
+			UID:        ownerNode.newUID,
+		}
+		if attributes, ok := n.owners[ownerNode]; ok {
+			ownerRef.Controller = attributes.Controller
+			ownerRef.BlockOwnerDeletion = attributes.BlockOwnerDeletion
+		}
+		ret = append(ret, ownerRef)
+		changed = true
+	}
+
+	return ret, changed
+}
+
 // deleteGroup deletes all the Kubernetes objects from the source management cluster corresponding to the object graph nodes in a moveGroup.
 func (o *objectMover) deleteGroup(group moveGroup) error {
 	deleteSourceObjectBackoff := newWriteBackoff()
@@ -928,11 +1531,13 @@ func (o *objectMover) deleteGroup(group moveGroup) error {
 }
 
 var (
-	removeFinalizersPatch = client.RawPatch(types.MergePatchType, []byte("{\"metadata\":{\"finalizers\":[]}}"))
+	// deleteSourceObjectPatch removes all the finalizers, so the object gets immediately deleted, and confirms the deletion
+	// of objects protected against accidental deletion.
+	deleteSourceObjectPatch = client.RawPatch(types.MergePatchType, []byte(fmt.Sprintf("{\"metadata\":{\"finalizers\":[],\"annotations\":{%q:\"\"}}}", clusterv1.DeletionConfirmedAnnotation)))
 )
 
 // deleteSourceObject deletes the Kubernetes object corresponding to the node from the source management cluster, taking care of removing all the finalizers so
-// the objects gets immediately deleted (force delete).
+// the objects gets immediately deleted (force delete) and of confirming the deletion of objects with deletion protection enabled.
 func (o *objectMover) deleteSourceObject(nodeToDelete *node) error {
 	// Don't delete cluster-wide nodes or nodes that are below a hierarchy that starts with a global object (e.g. a secrets owned by a global identity object).
 	if nodeToDelete.isGlobal || nodeToDelete.isGlobalHierarchy {
@@ -970,11 +1575,9 @@ func (o *objectMover) deleteSourceObject(nodeToDelete *node) error {
 			sourceObj.GroupVersionKind(), sourceObj.GetNamespace(), sourceObj.GetName())
 	}
 
-	if len(sourceObj.GetFinalizers()) > 0 {
-		if err := cFrom.Patch(ctx, sourceObj, removeFinalizersPatch); err != nil {
-			return errors.Wrapf(err, "error removing finalizers from %q %s/%s",
-				sourceObj.GroupVersionKind(), sourceObj.GetNamespace(), sourceObj.GetName())
-		}
+	if err := cFrom.Patch(ctx, sourceObj, deleteSourceObjectPatch); err != nil {
+		return errors.Wrapf(err, "error removing finalizers from %q %s/%s",
+			sourceObj.GroupVersionKind(), sourceObj.GetNamespace(), sourceObj.GetName())
 	}
 
 	if err := cFrom.Delete(ctx, sourceObj); err != nil {
@@ -985,6 +1588,44 @@ func (o *objectMover) deleteSourceObject(nodeToDelete *node) error {
 	return nil
 }
 
+// checkPermissions checks the current identity is allowed to move the objects in the graph, reporting all the
+// missing permissions on the source and on the target management cluster before moving anything.
+func (o *objectMover) checkPermissions(graph *objectGraph, toProxy Proxy) error {
+	// Objects are read, paused and deleted, after removing finalizers, from the source management cluster;
+	// in dry-run mode objects are only read.
+	sourceVerbs := []string{"get", "list", "patch", "delete"}
+	if o.dryRun {
+		sourceVerbs = []string{"get", "list"}
+	}
+
+	source := []resourceAccess{}
+	target := []resourceAccess{}
+	for _, node := range graph.getMoveNodes() {
+		gvk := node.identity.GroupVersionKind()
+		source = append(source, newResourceAccess(gvk, node.identity.Namespace, sourceVerbs...))
+
+		// Objects are created, or updated if already existing, in the target management cluster.
+		target = append(target, newResourceAccess(gvk, o.targetNamespace(node), "get", "create", "patch"))
+	}
+
+	if err := checkPermissions(o.fromProxy, source); err != nil {
+		return errors.Wrap(err, "failed to check permissions in the source management cluster")
+	}
+
+	// In dry-run mode there is no target management cluster.
+	if toProxy == nil {
+		return nil
+	}
+
+	if o.createNamespace {
+		target = append(target, newResourceAccess(corev1.SchemeGroupVersion.WithKind("Namespace"), "", "get", "create"))
+	}
+	if err := checkPermissions(toProxy, target); err != nil {
+		return errors.Wrap(err, "failed to check permissions in the target management cluster")
+	}
+	return nil
+}
+
 // checkTargetProviders checks that all the providers installed in the source cluster exists in the target cluster as well (with a version >= of the current version).
 func (o *objectMover) checkTargetProviders(toInventory InventoryClient) error {
 	if o.dryRun {
@@ -26,6 +26,7 @@ import (
 	"time"
 
 	. "github.com/onsi/gomega"
+	authorizationv1 "k8s.io/api/authorization/v1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -937,6 +938,139 @@ func Test_objectMover_restore(t *testing.T) {
 	}
 }
 
+func Test_objectMover_verifyOwnerReferences(t *testing.T) {
+	t.Run("fixes owner references pointing to stale UIDs", func(t *testing.T) {
+		g := NewWithT(t)
+
+		// Create an objectGraph bound a source cluster with all the CRDs for the types involved in the test.
+		graph := getObjectGraphWithObjs(test.NewFakeCluster("ns1", "foo").Objs())
+
+		// Get all the types to be considered for discovery
+		g.Expect(getFakeDiscoveryTypes(graph)).To(Succeed())
+
+		// trigger discovery the content of the source cluster
+		g.Expect(graph.Discovery("")).To(Succeed())
+
+		// Gets a fakeProxy to a cluster with the same objects, but where the Cluster has a different UID, like e.g.
+		// when it is re-created by a partially failed restore, so all the owned objects are referencing a stale UID.
+		toObjs := test.NewFakeCluster("ns1", "foo").Objs()
+		for _, o := range toObjs {
+			if o.GetObjectKind().GroupVersionKind().Kind == "Cluster" {
+				o.SetUID("new-cluster-uid")
+			}
+		}
+		toProxy := getFakeProxyWithCRDs().WithObjs(toObjs...)
+
+		mover := objectMover{
+			fromProxy: graph.proxy,
+		}
+		g.Expect(mover.verifyOwnerReferences(getMoveSequence(graph), toProxy)).To(Succeed())
+
+		// check that all the owner references to the Cluster have been fixed in the target cluster.
+		csTo, err := toProxy.NewClient()
+		g.Expect(err).NotTo(HaveOccurred())
+
+		for _, node := range graph.getMoveNodes() {
+			oTo := &unstructured.Unstructured{}
+			oTo.SetAPIVersion(node.identity.APIVersion)
+			oTo.SetKind(node.identity.Kind)
+			key := client.ObjectKey{
+				Namespace: node.identity.Namespace,
+				Name:      node.identity.Name,
+			}
+			g.Expect(csTo.Get(ctx, key, oTo)).To(Succeed())
+
+			for _, ref := range oTo.GetOwnerReferences() {
+				if ref.Kind == "Cluster" {
+					g.Expect(ref.UID).To(BeEquivalentTo("new-cluster-uid"), "%s %s has a stale owner reference", node.identity.Kind, key)
+				}
+			}
+		}
+	})
+
+	t.Run("fails if objects are missing in the target cluster", func(t *testing.T) {
+		g := NewWithT(t)
+
+		graph := getObjectGraphWithObjs(test.NewFakeCluster("ns1", "foo").Objs())
+		g.Expect(getFakeDiscoveryTypes(graph)).To(Succeed())
+		g.Expect(graph.Discovery("")).To(Succeed())
+
+		// gets a fakeProxy to an empty cluster with all the required CRDs
+		toProxy := getFakeProxyWithCRDs()
+
+		mover := objectMover{
+			fromProxy: graph.proxy,
+		}
+		err := mover.verifyOwnerReferences(getMoveSequence(graph), toProxy)
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(ContainSubstring("Cluster ns1/foo"))
+	})
+}
+
+func Test_reconcileOwnerReferences(t *testing.T) {
+	owner := &node{
+		identity: corev1.ObjectReference{
+			APIVersion: "cluster.x-k8s.io/v1alpha4",
+			Kind:       "Cluster",
+			Name:       "foo",
+		},
+		newUID: "new-uid",
+	}
+	n := &node{
+		owners: map[*node]ownerReferenceAttributes{
+			owner: {Controller: pointer.BoolPtr(true)},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		ownerRefs   []metav1.OwnerReference
+		want        []metav1.OwnerReference
+		wantChanged bool
+	}{
+		{
+			name: "owner reference with the current UID is not changed",
+			ownerRefs: []metav1.OwnerReference{
+				{APIVersion: "cluster.x-k8s.io/v1alpha4", Kind: "Cluster", Name: "foo", UID: "new-uid", Controller: pointer.BoolPtr(true)},
+			},
+			want: []metav1.OwnerReference{
+				{APIVersion: "cluster.x-k8s.io/v1alpha4", Kind: "Cluster", Name: "foo", UID: "new-uid", Controller: pointer.BoolPtr(true)},
+			},
+			wantChanged: false,
+		},
+		{
+			name: "owner reference with a stale UID is fixed, even if using a different API version",
+			ownerRefs: []metav1.OwnerReference{
+				{APIVersion: "cluster.x-k8s.io/v1alpha3", Kind: "Cluster", Name: "foo", UID: "stale-uid", Controller: pointer.BoolPtr(true)},
+			},
+			want: []metav1.OwnerReference{
+				{APIVersion: "cluster.x-k8s.io/v1alpha3", Kind: "Cluster", Name: "foo", UID: "new-uid", Controller: pointer.BoolPtr(true)},
+			},
+			wantChanged: true,
+		},
+		{
+			name: "missing owner reference is added, other owner references are preserved",
+			ownerRefs: []metav1.OwnerReference{
+				{APIVersion: "v1", Kind: "ConfigMap", Name: "bar", UID: "bar-uid"},
+			},
+			want: []metav1.OwnerReference{
+				{APIVersion: "v1", Kind: "ConfigMap", Name: "bar", UID: "bar-uid"},
+				{APIVersion: "cluster.x-k8s.io/v1alpha4", Kind: "Cluster", Name: "foo", UID: "new-uid", Controller: pointer.BoolPtr(true)},
+			},
+			wantChanged: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got, changed := reconcileOwnerReferences(tt.ownerRefs, n)
+			g.Expect(got).To(Equal(tt.want))
+			g.Expect(changed).To(Equal(tt.wantChanged))
+		})
+	}
+}
+
 func Test_getMoveSequence(t *testing.T) {
 	// NB. we are testing the move and move sequence using the same set of moveTests, but checking the results at different stages of the move process
 	for _, tt := range moveTests {
@@ -968,6 +1102,44 @@ func Test_getMoveSequence(t *testing.T) {
 	}
 }
 
+func Test_getMovePlan(t *testing.T) {
+	// NB. we are testing the move plan using the same set of moveTests used for testing the move sequence
+	for _, tt := range moveTests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			// Create an objectGraph bound a source cluster with all the CRDs for the types involved in the test.
+			graph := getObjectGraphWithObjs(tt.fields.objs)
+
+			// Get all the types to be considered for discovery
+			g.Expect(getFakeDiscoveryTypes(graph)).To(Succeed())
+
+			// trigger discovery the content of the source cluster
+			g.Expect(graph.Discovery("")).To(Succeed())
+
+			plan := getMovePlan(getMoveSequence(graph))
+
+			wantEntries := 0
+			for _, wantGroup := range tt.wantMoveGroups {
+				wantEntries += len(wantGroup)
+			}
+			g.Expect(plan).To(HaveLen(wantEntries))
+
+			// Check all the objects are in the expected group, and groups are listed in order.
+			gotGroups := make([][]string, len(tt.wantMoveGroups))
+			lastGroup := 1
+			for _, entry := range plan {
+				g.Expect(entry.group).To(BeNumerically(">=", lastGroup))
+				lastGroup = entry.group
+				gotGroups[entry.group-1] = append(gotGroups[entry.group-1], string(entry.identity.UID))
+			}
+			for i, wantGroup := range tt.wantMoveGroups {
+				g.Expect(gotGroups[i]).To(ConsistOf(wantGroup))
+			}
+		})
+	}
+}
+
 func Test_objectMover_move_dryRun(t *testing.T) {
 	// NB. we are testing the move and move sequence using the same set of moveTests, but checking the results at different stages of the move process
 	for _, tt := range moveTests {
@@ -1061,7 +1233,8 @@ func Test_objectMover_move(t *testing.T) {
 
 			// Run move
 			mover := objectMover{
-				fromProxy: graph.proxy,
+				fromProxy:       graph.proxy,
+				createNamespace: true,
 			}
 
 			err := mover.move(graph, toProxy)
@@ -1568,7 +1741,8 @@ func Test_objectMoverService_ensureNamespaces(t *testing.T) {
 			g.Expect(graph.Discovery("")).To(Succeed())
 
 			mover := objectMover{
-				fromProxy: graph.proxy,
+				fromProxy:       graph.proxy,
+				createNamespace: true,
 			}
 
 			err := mover.ensureNamespaces(graph, tt.args.toProxy)
@@ -1607,11 +1781,123 @@ func Test_objectMoverService_ensureNamespaces(t *testing.T) {
 	}
 }
 
+func Test_objectMoverService_ensureNamespaces_withoutCreateNamespace(t *testing.T) {
+	namespace1 := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "namespace-1",
+		},
+	}
+
+	cluster1 := test.NewFakeCluster("namespace-1", "cluster-1")
+	cluster2 := test.NewFakeCluster("namespace-2", "cluster-2")
+
+	t.Run("ensureNamespaces doesn't fail if all the namespaces exist in the target", func(t *testing.T) {
+		g := NewWithT(t)
+
+		graph := getObjectGraphWithObjs(cluster1.Objs())
+		g.Expect(getFakeDiscoveryTypes(graph)).To(Succeed())
+		g.Expect(graph.Discovery("")).To(Succeed())
+
+		mover := objectMover{
+			fromProxy: graph.proxy,
+		}
+		g.Expect(mover.ensureNamespaces(graph, test.NewFakeProxy().WithObjs(namespace1))).To(Succeed())
+	})
+
+	t.Run("ensureNamespaces reports the namespaces missing in the target without creating them", func(t *testing.T) {
+		g := NewWithT(t)
+
+		graph := getObjectGraphWithObjs(append(cluster1.Objs(), cluster2.Objs()...))
+		g.Expect(getFakeDiscoveryTypes(graph)).To(Succeed())
+		g.Expect(graph.Discovery("")).To(Succeed())
+
+		mover := objectMover{
+			fromProxy: graph.proxy,
+		}
+		toProxy := test.NewFakeProxy().WithObjs(namespace1)
+		err := mover.ensureNamespaces(graph, toProxy)
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(ContainSubstring("namespace-2"))
+		g.Expect(err.Error()).NotTo(ContainSubstring("namespace-1"))
+
+		csTo, err := toProxy.NewClient()
+		g.Expect(err).ToNot(HaveOccurred())
+		namespaces := &corev1.NamespaceList{}
+		g.Expect(csTo.List(ctx, namespaces)).To(Succeed())
+		g.Expect(namespaces.Items).To(HaveLen(1))
+	})
+}
+
+func Test_objectMover_checkPermissions(t *testing.T) {
+	cluster1 := test.NewFakeCluster("ns1", "cluster-1")
+
+	tests := []struct {
+		name        string
+		fromProxy   *test.FakeProxy
+		toProxy     *test.FakeProxy
+		dryRun      bool
+		wantErr     bool
+		wantMissing string
+	}{
+		{
+			name:      "pass if all the permissions are granted",
+			fromProxy: test.NewFakeProxy(),
+			toProxy:   test.NewFakeProxy(),
+		},
+		{
+			name:        "report the permissions missing in the source cluster",
+			fromProxy:   test.NewFakeProxy().WithDeniedAccess(authorizationv1.ResourceAttributes{Namespace: "ns1", Verb: "delete", Resource: "secrets"}),
+			toProxy:     test.NewFakeProxy(),
+			wantErr:     true,
+			wantMissing: "source management cluster",
+		},
+		{
+			name:        "report the permissions missing in the target cluster",
+			fromProxy:   test.NewFakeProxy(),
+			toProxy:     test.NewFakeProxy().WithDeniedAccess(authorizationv1.ResourceAttributes{Namespace: "ns1", Verb: "create", Group: clusterv1.GroupVersion.Group, Resource: "clusters"}),
+			wantErr:     true,
+			wantMissing: "create clusters.cluster.x-k8s.io in namespace ns1",
+		},
+		{
+			name:      "ignore write permissions in the source cluster in dry-run mode",
+			fromProxy: test.NewFakeProxy().WithDeniedAccess(authorizationv1.ResourceAttributes{Namespace: "ns1", Verb: "delete", Resource: "secrets"}),
+			dryRun:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			graph := getObjectGraphWithObjs(cluster1.Objs())
+			g.Expect(getFakeDiscoveryTypes(graph)).To(Succeed())
+			g.Expect(graph.Discovery("")).To(Succeed())
+
+			mover := objectMover{
+				fromProxy: tt.fromProxy,
+				dryRun:    tt.dryRun,
+			}
+			var toProxy Proxy
+			if tt.toProxy != nil {
+				toProxy = tt.toProxy
+			}
+
+			err := mover.checkPermissions(graph, toProxy)
+			if !tt.wantErr {
+				g.Expect(err).NotTo(HaveOccurred())
+				return
+			}
+			g.Expect(err).To(HaveOccurred())
+			g.Expect(err.Error()).To(ContainSubstring(tt.wantMissing))
+		})
+	}
+}
+
 func Test_createTargetObject(t *testing.T) {
 	type args struct {
-		fromProxy Proxy
-		toProxy   Proxy
-		node      *node
+		fromProxy   Proxy
+		toProxy     Proxy
+		node        *node
+		toNamespace string
 	}
 
 	tests := []struct {
@@ -1802,6 +2088,46 @@ func Test_createTargetObject(t *testing.T) {
 				g.Expect(c.Annotations).ToNot(BeEmpty())
 			},
 		},
+		{
+			name: "creates the object in the target namespace, remapping references",
+			args: args{
+				fromProxy: test.NewFakeProxy().WithObjs(
+					&clusterv1.Cluster{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "foo",
+							Namespace: "ns1",
+						},
+						Spec: clusterv1.ClusterSpec{
+							InfrastructureRef: &corev1.ObjectReference{
+								Kind:       "GenericInfrastructureCluster",
+								Namespace:  "ns1",
+								Name:       "foo",
+								APIVersion: "infrastructure.cluster.x-k8s.io/v1alpha4",
+							},
+						},
+					},
+				),
+				toProxy: test.NewFakeProxy(),
+				node: &node{
+					identity: corev1.ObjectReference{
+						Kind:       "Cluster",
+						Namespace:  "ns1",
+						Name:       "foo",
+						APIVersion: "cluster.x-k8s.io/v1alpha4",
+					},
+				},
+				toNamespace: "ns2",
+			},
+			want: func(g *WithT, toClient client.Client) {
+				c := &clusterv1.Cluster{}
+				key := client.ObjectKey{
+					Namespace: "ns2",
+					Name:      "foo",
+				}
+				g.Expect(toClient.Get(ctx, key, c)).ToNot(HaveOccurred())
+				g.Expect(c.Spec.InfrastructureRef.Namespace).To(Equal("ns2"))
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -1809,7 +2135,8 @@ func Test_createTargetObject(t *testing.T) {
 			g := NewWithT(t)
 
 			mover := objectMover{
-				fromProxy: tt.args.fromProxy,
+				fromProxy:   tt.args.fromProxy,
+				toNamespace: tt.args.toNamespace,
 			}
 
 			err := mover.createTargetObject(tt.args.node, tt.args.toProxy)
@@ -1967,3 +2294,136 @@ func Test_deleteSourceObject(t *testing.T) {
 		})
 	}
 }
+
+func Test_objectMover_runMoveHooks(t *testing.T) {
+	hookNode := &node{
+		identity: corev1.ObjectReference{
+			Kind:       "Cluster",
+			Namespace:  "ns1",
+			Name:       "foo",
+			APIVersion: "cluster.x-k8s.io/v1alpha4",
+		},
+	}
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		wantErr     bool
+	}{
+		{
+			name: "completes when the provider sets the completed annotation with the move ID",
+			annotations: map[string]string{
+				clusterctlv1.PreMoveHookCompletedAnnotation: "move-id",
+			},
+			wantErr: false,
+		},
+		{
+			name: "times out when the completed annotation refers to another move request",
+			annotations: map[string]string{
+				clusterctlv1.PreMoveHookCompletedAnnotation: "another-move-id",
+			},
+			wantErr: true,
+		},
+		{
+			name:        "times out when the provider does not complete the move hook",
+			annotations: nil,
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			proxy := test.NewFakeProxy().WithObjs(
+				&clusterv1.Cluster{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        "foo",
+						Namespace:   "ns1",
+						Annotations: tt.annotations,
+					},
+				},
+			)
+
+			mover := objectMover{
+				fromProxy: proxy,
+				moveID:    "move-id",
+			}
+
+			hookNodes := map[*node]time.Duration{hookNode: 100 * time.Millisecond}
+			err := mover.runMoveHooks(proxy, hookNodes, false, clusterctlv1.PreMoveHookAnnotation, clusterctlv1.PreMoveHookCompletedAnnotation)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+
+			// Check the move hook is requested with the move ID.
+			c, err := proxy.NewClient()
+			g.Expect(err).NotTo(HaveOccurred())
+
+			cluster := &clusterv1.Cluster{}
+			g.Expect(c.Get(ctx, client.ObjectKey{Namespace: "ns1", Name: "foo"}, cluster)).To(Succeed())
+			g.Expect(cluster.Annotations).To(HaveKeyWithValue(clusterctlv1.PreMoveHookAnnotation, "move-id"))
+		})
+	}
+}
+
+func Test_removeMoveHookAnnotations(t *testing.T) {
+	g := NewWithT(t)
+
+	obj := &unstructured.Unstructured{}
+	obj.SetAnnotations(map[string]string{
+		clusterctlv1.PreMoveHookAnnotation:          "move-id",
+		clusterctlv1.PreMoveHookCompletedAnnotation: "move-id",
+		clusterctlv1.MoveHookFailedAnnotation:       "failure",
+		"foo":                                       "bar",
+	})
+
+	removeMoveHookAnnotations(obj)
+	g.Expect(obj.GetAnnotations()).To(Equal(map[string]string{"foo": "bar"}))
+}
+
+func Test_remapNamespaceReferences(t *testing.T) {
+	g := NewWithT(t)
+
+	obj := map[string]interface{}{
+		"apiVersion": "cluster.x-k8s.io/v1alpha4",
+		"kind":       "Cluster",
+		"metadata": map[string]interface{}{
+			"name":      "foo",
+			"namespace": "ns1",
+		},
+		"spec": map[string]interface{}{
+			"infrastructureRef": map[string]interface{}{
+				"kind":      "GenericInfrastructureCluster",
+				"name":      "foo",
+				"namespace": "ns1",
+			},
+			"controlPlaneRef": map[string]interface{}{
+				"kind":      "GenericControlPlane",
+				"name":      "foo",
+				"namespace": "ns3",
+			},
+			"secrets": []interface{}{
+				map[string]interface{}{
+					"name":      "bar",
+					"namespace": "ns1",
+				},
+				map[string]interface{}{
+					"name": "baz",
+				},
+			},
+		},
+	}
+
+	invalid := remapNamespaceReferences(obj, "ns1", "ns2")
+	g.Expect(invalid).To(Equal([]string{"spec.controlPlaneRef"}))
+
+	spec := obj["spec"].(map[string]interface{})
+	g.Expect(spec["infrastructureRef"].(map[string]interface{})["namespace"]).To(Equal("ns2"))
+	g.Expect(spec["controlPlaneRef"].(map[string]interface{})["namespace"]).To(Equal("ns3"))
+	g.Expect(spec["secrets"].([]interface{})[0].(map[string]interface{})["namespace"]).To(Equal("ns2"))
+	g.Expect(spec["secrets"].([]interface{})[1].(map[string]interface{})).ToNot(HaveKey("namespace"))
+	g.Expect(obj["metadata"].(map[string]interface{})["namespace"]).To(Equal("ns1"))
+}
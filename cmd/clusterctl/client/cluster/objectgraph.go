@@ -19,6 +19,7 @@ package cluster
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
@@ -26,11 +27,14 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
 	logf "sigs.k8s.io/cluster-api/cmd/clusterctl/log"
 	addonsv1 "sigs.k8s.io/cluster-api/exp/addons/api/v1alpha4"
+	ipamv1 "sigs.k8s.io/cluster-api/exp/ipam/api/v1alpha4"
 	secretutil "sigs.k8s.io/cluster-api/util/secret"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -79,6 +83,9 @@ type node struct {
 	// the node is linked to a object indirectly in the OwnerReference chain.
 	tenant map[*node]empty
 
+	// clusterName is the value of the cluster name label of the object, if any.
+	clusterName string
+
 	// restoreObject holds the object that is referenced when creating a node during restore from file.
 	// the object can then be referenced latter when restoring objects to a target management cluster
 	restoreObject *unstructured.Unstructured
@@ -89,6 +96,11 @@ type discoveryTypeInfo struct {
 	forceMove          bool
 	forceMoveHierarchy bool
 	scope              apiextensionsv1.ResourceScope
+
+	// moveHooks is set to true if the CRD has the "move-hooks" label attached, and moveHookTimeout defines how long
+	// clusterctl move waits for the provider to complete a move hook on objects of this type.
+	moveHooks       bool
+	moveHookTimeout time.Duration
 }
 
 // markObserved marks the fact that a node was observed as a concrete object.
@@ -118,12 +130,44 @@ func (n *node) getFilename() string {
 	return n.identity.Kind + "_" + n.identity.Namespace + "_" + n.identity.Name + ".yaml"
 }
 
+// KindFilter defines additional kinds to be included in, or excluded from, the move discovery phase.
+type KindFilter struct {
+	// Include is the list of kinds whose objects are moved even if their CRDs are not installed by clusterctl,
+	// e.g. cert-manager Certificates or custom addon objects existing in the namespace.
+	Include []schema.GroupKind
+
+	// Exclude is the list of kinds whose objects are never moved.
+	Exclude []schema.GroupKind
+}
+
+// includes returns true if the kind is explicitly included in the move discovery phase.
+func (f KindFilter) includes(gk schema.GroupKind) bool {
+	return containsGroupKind(f.Include, gk)
+}
+
+// excludes returns true if the kind is explicitly excluded from the move discovery phase.
+func (f KindFilter) excludes(gk schema.GroupKind) bool {
+	return containsGroupKind(f.Exclude, gk)
+}
+
+func containsGroupKind(list []schema.GroupKind, gk schema.GroupKind) bool {
+	for _, item := range list {
+		if item == gk {
+			return true
+		}
+	}
+	return false
+}
+
 // objectGraph manages the Kubernetes object graph that is generated during the discovery phase for the move operation.
 type objectGraph struct {
 	proxy             Proxy
 	providerInventory InventoryClient
 	uidToNode         map[types.UID]*node
 	types             map[string]*discoveryTypeInfo
+
+	// kindFilter defines the kinds included in, or excluded from, the discovery phase on top of the default ones.
+	kindFilter KindFilter
 }
 
 func newObjectGraph(proxy Proxy, providerInventory InventoryClient) *objectGraph {
@@ -239,6 +283,7 @@ func (o *objectGraph) objToNode(obj *unstructured.Unstructured) *node {
 
 func (o *objectGraph) objMetaToNode(obj *unstructured.Unstructured, n *node) {
 	n.identity.Namespace = obj.GetNamespace()
+	n.clusterName = obj.GetLabels()[clusterv1.ClusterLabelName]
 	if _, ok := obj.GetLabels()[clusterctlv1.ClusterctlMoveLabelName]; ok {
 		n.forceMove = true
 	}
@@ -263,19 +308,37 @@ func (o *objectGraph) objMetaToNode(obj *unstructured.Unstructured, n *node) {
 }
 
 // getDiscoveryTypes returns the list of TypeMeta to be considered for the the move discovery phase.
-// This list includes all the types defines by the CRDs installed by clusterctl and the ConfigMap/Secret core types.
+// This list includes all the types defines by the CRDs installed by clusterctl and the ConfigMap/Secret core types,
+// plus the types explicitly included and minus the types explicitly excluded by the kind filter.
 func (o *objectGraph) getDiscoveryTypes() error {
+	// If additional kinds are included, all the CRDs should be considered, not only the ones installed by clusterctl.
+	var selectors []client.ListOption
+	if len(o.kindFilter.Include) == 0 {
+		selectors = append(selectors, client.HasLabels{clusterctlv1.ClusterctlLabelName})
+	}
+
 	crdList := &apiextensionsv1.CustomResourceDefinitionList{}
 	getDiscoveryTypesBackoff := newReadBackoff()
 	if err := retryWithExponentialBackoff(getDiscoveryTypesBackoff, func() error {
-		return getCRDList(o.proxy, crdList)
+		return getCRDList(o.proxy, crdList, selectors...)
 	}); err != nil {
 		return err
 	}
 
 	o.types = make(map[string]*discoveryTypeInfo)
+	includedKinds := map[schema.GroupKind]bool{}
 
 	for _, crd := range crdList.Items {
+		groupKind := schema.GroupKind{Group: crd.Spec.Group, Kind: crd.Spec.Names.Kind}
+		if o.kindFilter.excludes(groupKind) {
+			continue
+		}
+		_, isClusterctlCRD := crd.Labels[clusterctlv1.ClusterctlLabelName]
+		isIncluded := o.kindFilter.includes(groupKind)
+		if !isClusterctlCRD && !isIncluded {
+			continue
+		}
+
 		for _, version := range crd.Spec.Versions {
 			if !version.Storage {
 				continue
@@ -302,6 +365,28 @@ func (o *objectGraph) getDiscoveryTypes() error {
 				forceMove = true
 			}
 
+			// If a kind is explicitly included, all the objects of this type are force moved.
+			if isIncluded {
+				forceMove = true
+				includedKinds[groupKind] = true
+			}
+
+			// If a CRD is labeled with move hooks, keep track of this so the pre-move and post-move hooks are executed for
+			// all the objects of this type.
+			moveHooks := false
+			var moveHookTimeout time.Duration
+			if _, ok := crd.Labels[clusterctlv1.ClusterctlMoveHooksLabelName]; ok {
+				moveHooks = true
+				moveHookTimeout = defaultMoveHookTimeout
+				if value, ok := crd.Annotations[clusterctlv1.MoveHookTimeoutAnnotation]; ok {
+					timeout, err := time.ParseDuration(value)
+					if err != nil || timeout <= 0 {
+						return errors.Errorf("invalid %s annotation on CRD %s: %q is not a valid timeout", clusterctlv1.MoveHookTimeoutAnnotation, crd.Name, value)
+					}
+					moveHookTimeout = timeout
+				}
+			}
+
 			typeMeta := metav1.TypeMeta{
 				Kind: crd.Spec.Names.Kind,
 				APIVersion: metav1.GroupVersion{
@@ -315,15 +400,30 @@ func (o *objectGraph) getDiscoveryTypes() error {
 				forceMove:          forceMove,
 				forceMoveHierarchy: forceMoveHierarchy,
 				scope:              crd.Spec.Scope,
+				moveHooks:          moveHooks,
+				moveHookTimeout:    moveHookTimeout,
 			}
 		}
 	}
 
-	secretTypeMeta := metav1.TypeMeta{Kind: "Secret", APIVersion: "v1"}
-	o.types[getKindAPIString(secretTypeMeta)] = &discoveryTypeInfo{typeMeta: secretTypeMeta}
+	for _, typeMeta := range []metav1.TypeMeta{{Kind: "Secret", APIVersion: "v1"}, {Kind: "ConfigMap", APIVersion: "v1"}} {
+		groupKind := typeMeta.GroupVersionKind().GroupKind()
+		if o.kindFilter.excludes(groupKind) {
+			continue
+		}
+		isIncluded := o.kindFilter.includes(groupKind)
+		if isIncluded {
+			includedKinds[groupKind] = true
+		}
+		o.types[getKindAPIString(typeMeta)] = &discoveryTypeInfo{typeMeta: typeMeta, forceMove: isIncluded}
+	}
 
-	configMapTypeMeta := metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"}
-	o.types[getKindAPIString(configMapTypeMeta)] = &discoveryTypeInfo{typeMeta: configMapTypeMeta}
+	// Ensure all the included kinds are known, so a typo does not silently skip objects expected to be moved.
+	for _, groupKind := range o.kindFilter.Include {
+		if !includedKinds[groupKind] {
+			return errors.Errorf("failed to find a CustomResourceDefinition for the included kind %q", groupKind)
+		}
+	}
 
 	return nil
 }
@@ -335,13 +435,13 @@ func getKindAPIString(typeMeta metav1.TypeMeta) string {
 	return fmt.Sprintf("%ss.%s", strings.ToLower(typeMeta.Kind), api)
 }
 
-func getCRDList(proxy Proxy, crdList *apiextensionsv1.CustomResourceDefinitionList) error {
+func getCRDList(proxy Proxy, crdList *apiextensionsv1.CustomResourceDefinitionList, selectors ...client.ListOption) error {
 	c, err := proxy.NewClient()
 	if err != nil {
 		return err
 	}
 
-	if err := c.List(ctx, crdList, client.HasLabels{clusterctlv1.ClusterctlLabelName}); err != nil {
+	if err := c.List(ctx, crdList, selectors...); err != nil {
 		return errors.Wrap(err, "failed to get the list of CRDs required for the move discovery phase")
 	}
 	return nil
@@ -484,6 +584,30 @@ func (o *objectGraph) getMoveNodes() []*node {
 	return nodes
 }
 
+// getMoveHookNodes returns the list of nodes to be moved with a type requiring move hooks, together with the move hook timeout for each node.
+func (o *objectGraph) getMoveHookNodes() map[*node]time.Duration {
+	nodes := map[*node]time.Duration{}
+	for _, node := range o.getMoveNodes() {
+		kindAPIStr := getKindAPIString(metav1.TypeMeta{Kind: node.identity.Kind, APIVersion: node.identity.APIVersion})
+		if discoveryType, ok := o.types[kindAPIStr]; ok && discoveryType.moveHooks {
+			nodes[node] = discoveryType.moveHookTimeout
+		}
+	}
+	return nodes
+}
+
+// getIPAMObjects returns the list of IPAddressClaims and IPAddresses existing in the object graph.
+func (o *objectGraph) getIPAMObjects() []*node {
+	ipamObjects := []*node{}
+	for _, node := range o.uidToNode {
+		groupKind := node.identity.GroupVersionKind().GroupKind()
+		if groupKind == ipamv1.GroupVersion.WithKind("IPAddressClaim").GroupKind() || groupKind == ipamv1.GroupVersion.WithKind("IPAddress").GroupKind() {
+			ipamObjects = append(ipamObjects, node)
+		}
+	}
+	return ipamObjects
+}
+
 // getMachines returns the list of Machine existing in the object graph.
 func (o *objectGraph) getMachines() []*node {
 	machines := []*node{}
@@ -495,7 +619,8 @@ func (o *objectGraph) getMachines() []*node {
 	return machines
 }
 
-// setSoftOwnership searches for soft ownership relations such as secrets linked to the cluster by a naming convention (without any explicit OwnerReference).
+// setSoftOwnership searches for soft ownership relations such as secrets linked to the cluster by a naming convention
+// or IPAM objects linked to the cluster by the cluster name label (without any explicit OwnerReference).
 func (o *objectGraph) setSoftOwnership() {
 	log := logf.Log
 	clusters := o.getClusters()
@@ -520,6 +645,23 @@ func (o *objectGraph) setSoftOwnership() {
 			}
 		}
 	}
+
+	for _, ipamObject := range o.getIPAMObjects() {
+		// If the IPAddressClaim or the IPAddress has at least one OwnerReference ignore it.
+		// NB. IPAddressClaims created by infrastructure providers are owned by the infrastructure machines, and IPAddresses
+		// are owned by the IPAddressClaim they are allocated for, while e.g. user provided claims might not have one.
+		if len(ipamObject.owners) > 0 || ipamObject.clusterName == "" {
+			continue
+		}
+
+		// If the IPAddressClaim or the IPAddress is linked to a cluster via the cluster name label, then add the cluster
+		// to the list of its softOwners.
+		for _, cluster := range clusters {
+			if ipamObject.clusterName == cluster.identity.Name && ipamObject.identity.Namespace == cluster.identity.Namespace {
+				ipamObject.addSoftOwner(cluster)
+			}
+		}
+	}
 }
 
 // setTenants identifies all the nodes linked to a parent with forceMoveHierarchy = true (e.g. Clusters or ClusterResourceSet)
@@ -543,6 +685,22 @@ func (o *objectGraph) setTenant(node, tenant *node, isGlobalHierarchy bool) {
 	}
 }
 
+// checkExcludedOwners returns an error if any of the objects to be moved is owned by an object of a kind excluded
+// by the kind filter, because its OwnerReferences would be dangling in the target management cluster.
+func (o *objectGraph) checkExcludedOwners() error {
+	errList := []error{}
+	for _, node := range o.getMoveNodes() {
+		for owner := range node.owners {
+			if !owner.virtual || !o.kindFilter.excludes(owner.identity.GroupVersionKind().GroupKind()) {
+				continue
+			}
+			errList = append(errList, errors.Errorf("%s %s/%s is owned by %s %s, whose kind is excluded from move",
+				node.identity.Kind, node.identity.Namespace, node.identity.Name, owner.identity.Kind, owner.identity.Name))
+		}
+	}
+	return kerrors.NewAggregate(errList)
+}
+
 // checkVirtualNode logs if nodes are still virtual.
 func (o *objectGraph) checkVirtualNode() {
 	log := logf.Log
@@ -20,14 +20,18 @@ import (
 	"fmt"
 	"sort"
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 	"github.com/pkg/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+	ipamv1 "sigs.k8s.io/cluster-api/exp/ipam/api/v1alpha4"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -213,12 +217,211 @@ func TestObjectGraph_getDiscoveryTypeMetaList(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "Identified move hooks label",
+			fields: fields{
+				proxy: test.NewFakeProxy().
+					WithObjs(
+						func() client.Object {
+							crd := test.FakeNamespacedCustomResourceDefinition("foo", "Bar", "v1")
+							crd.Labels[clusterctlv1.ClusterctlMoveHooksLabelName] = ""
+							return crd
+						}(),
+					),
+			},
+			want: map[string]*discoveryTypeInfo{
+				"bars.foo": {
+					typeMeta:           metav1.TypeMeta{Kind: "Bar", APIVersion: "foo/v1"},
+					forceMove:          false,
+					forceMoveHierarchy: false,
+					scope:              "Namespaced",
+					moveHooks:          true,
+					moveHookTimeout:    defaultMoveHookTimeout,
+				},
+				"secrets.v1": {
+					typeMeta:           metav1.TypeMeta{Kind: "Secret", APIVersion: "v1"},
+					forceMove:          false,
+					forceMoveHierarchy: false,
+					scope:              "",
+				},
+				"configmaps.v1": {
+					typeMeta:           metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
+					forceMove:          false,
+					forceMoveHierarchy: false,
+					scope:              "",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Identified move hook timeout annotation",
+			fields: fields{
+				proxy: test.NewFakeProxy().
+					WithObjs(
+						func() client.Object {
+							crd := test.FakeNamespacedCustomResourceDefinition("foo", "Bar", "v1")
+							crd.Labels[clusterctlv1.ClusterctlMoveHooksLabelName] = ""
+							crd.Annotations = map[string]string{clusterctlv1.MoveHookTimeoutAnnotation: "10m"}
+							return crd
+						}(),
+					),
+			},
+			want: map[string]*discoveryTypeInfo{
+				"bars.foo": {
+					typeMeta:           metav1.TypeMeta{Kind: "Bar", APIVersion: "foo/v1"},
+					forceMove:          false,
+					forceMoveHierarchy: false,
+					scope:              "Namespaced",
+					moveHooks:          true,
+					moveHookTimeout:    10 * time.Minute,
+				},
+				"secrets.v1": {
+					typeMeta:           metav1.TypeMeta{Kind: "Secret", APIVersion: "v1"},
+					forceMove:          false,
+					forceMoveHierarchy: false,
+					scope:              "",
+				},
+				"configmaps.v1": {
+					typeMeta:           metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
+					forceMove:          false,
+					forceMoveHierarchy: false,
+					scope:              "",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Fails for invalid move hook timeout annotation",
+			fields: fields{
+				proxy: test.NewFakeProxy().
+					WithObjs(
+						func() client.Object {
+							crd := test.FakeNamespacedCustomResourceDefinition("foo", "Bar", "v1")
+							crd.Labels[clusterctlv1.ClusterctlMoveHooksLabelName] = ""
+							crd.Annotations = map[string]string{clusterctlv1.MoveHookTimeoutAnnotation: "ten minutes"}
+							return crd
+						}(),
+					),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			graph := newObjectGraph(tt.fields.proxy, nil)
+			err := graph.getDiscoveryTypes()
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(graph.types).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestObjectGraph_getDiscoveryTypesWithKindFilter(t *testing.T) {
+	type fields struct {
+		proxy      Proxy
+		kindFilter KindFilter
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		want    map[string]*discoveryTypeInfo
+		wantErr bool
+	}{
+		{
+			name: "Includes CRDs not installed by clusterctl, and excludes ConfigMaps",
+			fields: fields{
+				proxy: test.NewFakeProxy().
+					WithObjs(
+						test.FakeNamespacedCustomResourceDefinition("foo", "Bar", "v1"),
+						func() client.Object {
+							crd := test.FakeNamespacedCustomResourceDefinition("cert-manager.io", "Certificate", "v1")
+							delete(crd.Labels, clusterctlv1.ClusterctlLabelName)
+							return crd
+						}(),
+						func() client.Object {
+							crd := test.FakeNamespacedCustomResourceDefinition("cert-manager.io", "Issuer", "v1")
+							delete(crd.Labels, clusterctlv1.ClusterctlLabelName)
+							return crd
+						}(),
+					),
+				kindFilter: KindFilter{
+					Include: []schema.GroupKind{{Group: "cert-manager.io", Kind: "Certificate"}},
+					Exclude: []schema.GroupKind{{Kind: "ConfigMap"}},
+				},
+			},
+			want: map[string]*discoveryTypeInfo{
+				"bars.foo": {
+					typeMeta:           metav1.TypeMeta{Kind: "Bar", APIVersion: "foo/v1"},
+					forceMove:          false,
+					forceMoveHierarchy: false,
+					scope:              "Namespaced",
+				},
+				"certificates.cert-manager.io": {
+					typeMeta:           metav1.TypeMeta{Kind: "Certificate", APIVersion: "cert-manager.io/v1"},
+					forceMove:          true,
+					forceMoveHierarchy: false,
+					scope:              "Namespaced",
+				},
+				"secrets.v1": {
+					typeMeta:           metav1.TypeMeta{Kind: "Secret", APIVersion: "v1"},
+					forceMove:          false,
+					forceMoveHierarchy: false,
+					scope:              "",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Excludes CRDs installed by clusterctl",
+			fields: fields{
+				proxy: test.NewFakeProxy().
+					WithObjs(
+						test.FakeNamespacedCustomResourceDefinition("foo", "Bar", "v1"),
+					),
+				kindFilter: KindFilter{
+					Exclude: []schema.GroupKind{{Group: "foo", Kind: "Bar"}},
+				},
+			},
+			want: map[string]*discoveryTypeInfo{
+				"secrets.v1": {
+					typeMeta:           metav1.TypeMeta{Kind: "Secret", APIVersion: "v1"},
+					forceMove:          false,
+					forceMoveHierarchy: false,
+					scope:              "",
+				},
+				"configmaps.v1": {
+					typeMeta:           metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
+					forceMove:          false,
+					forceMoveHierarchy: false,
+					scope:              "",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Fails for included kinds without a CRD",
+			fields: fields{
+				proxy: test.NewFakeProxy(),
+				kindFilter: KindFilter{
+					Include: []schema.GroupKind{{Group: "cert-manager.io", Kind: "Certificate"}},
+				},
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			g := NewWithT(t)
 
 			graph := newObjectGraph(tt.fields.proxy, nil)
+			graph.kindFilter = tt.fields.kindFilter
 			err := graph.getDiscoveryTypes()
 			if tt.wantErr {
 				g.Expect(err).To(HaveOccurred())
@@ -231,6 +434,38 @@ func TestObjectGraph_getDiscoveryTypeMetaList(t *testing.T) {
 	}
 }
 
+func TestObjectGraph_checkExcludedOwners(t *testing.T) {
+	g := NewWithT(t)
+
+	graph := newObjectGraph(nil, nil)
+	graph.kindFilter = KindFilter{
+		Exclude: []schema.GroupKind{{Group: "foo", Kind: "Bar"}},
+	}
+	graph.types = map[string]*discoveryTypeInfo{
+		"bazs.foo": {typeMeta: metav1.TypeMeta{Kind: "Baz", APIVersion: "foo/v1"}, forceMove: true},
+	}
+
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("foo/v1")
+	obj.SetKind("Baz")
+	obj.SetNamespace("ns1")
+	obj.SetName("baz")
+	obj.SetUID("baz-uid")
+	graph.addObj(obj)
+
+	// An object without owners can be moved.
+	g.Expect(graph.checkExcludedOwners()).To(Succeed())
+
+	// An object owned by an object of an excluded kind can't be moved, because its OwnerReference would be dangling.
+	obj = obj.DeepCopy()
+	obj.SetOwnerReferences([]metav1.OwnerReference{
+		{APIVersion: "foo/v1", Kind: "Bar", Name: "bar", UID: "bar-uid"},
+	})
+	graph.addObj(obj)
+
+	g.Expect(graph.checkExcludedOwners()).ToNot(Succeed())
+}
+
 type wantGraphItem struct {
 	virtual            bool
 	isGlobal           bool
@@ -1651,6 +1886,52 @@ func Test_objectGraph_setSoftOwnership(t *testing.T) {
 	}
 }
 
+func Test_objectGraph_setSoftOwnershipForIPAMObjects(t *testing.T) {
+	g := NewWithT(t)
+
+	claim := &ipamv1.IPAddressClaim{
+		TypeMeta: metav1.TypeMeta{APIVersion: ipamv1.GroupVersion.String(), Kind: "IPAddressClaim"},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns1",
+			Name:      "foo-endpoint",
+			UID:       "ipam.cluster.x-k8s.io/v1alpha4, Kind=IPAddressClaim, ns1/foo-endpoint",
+			Labels:    map[string]string{clusterv1.ClusterLabelName: "foo"},
+		},
+	}
+	address := &ipamv1.IPAddress{
+		TypeMeta: metav1.TypeMeta{APIVersion: ipamv1.GroupVersion.String(), Kind: "IPAddress"},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns1",
+			Name:      "foo-endpoint",
+			UID:       "ipam.cluster.x-k8s.io/v1alpha4, Kind=IPAddress, ns1/foo-endpoint",
+			Labels:    map[string]string{clusterv1.ClusterLabelName: "foo"},
+			OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: claim.APIVersion, Kind: claim.Kind, Name: claim.Name, UID: claim.UID},
+			},
+		},
+	}
+	otherClaim := claim.DeepCopy()
+	otherClaim.Name = "bar-endpoint"
+	otherClaim.UID = "ipam.cluster.x-k8s.io/v1alpha4, Kind=IPAddressClaim, ns1/bar-endpoint"
+	otherClaim.Labels = map[string]string{clusterv1.ClusterLabelName: "bar"}
+
+	objs := append(test.NewFakeCluster("ns1", "foo").Objs(), claim, address, otherClaim)
+	graph, err := getDetachedObjectGraphWihObjs(objs)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	graph.setSoftOwnership()
+	graph.setTenants()
+
+	// The claim without OwnerReferences is soft owned by the cluster, and the address owned by the claim is part of the
+	// cluster hierarchy, while claims for other clusters are not moved.
+	clusterNode := graph.uidToNode["cluster.x-k8s.io/v1alpha4, Kind=Cluster, ns1/foo"]
+	g.Expect(graph.uidToNode[claim.UID].isSoftOwnedBy(clusterNode)).To(BeTrue())
+	g.Expect(graph.uidToNode[address.UID].softOwners).To(BeEmpty())
+	g.Expect(graph.uidToNode[address.UID].tenant).To(HaveKey(clusterNode))
+	g.Expect(graph.uidToNode[otherClaim.UID].softOwners).To(BeEmpty())
+	g.Expect(graph.uidToNode[otherClaim.UID].tenant).To(BeEmpty())
+}
+
 func Test_objectGraph_setClusterTenants(t *testing.T) {
 	type fields struct {
 		objs []client.Object
@@ -0,0 +1,129 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// resourceAccess defines the verbs the current identity must be allowed to perform on a resource, either in a
+// namespace or cluster-wide if the namespace is empty.
+type resourceAccess struct {
+	group     string
+	resource  string
+	namespace string
+	verbs     []string
+}
+
+// newResourceAccess returns the access required for the given verbs on the resource corresponding to a kind.
+func newResourceAccess(gvk schema.GroupVersionKind, namespace string, verbs ...string) resourceAccess {
+	// NOTE: The resource is guessed from the kind, because the CRD defining the kind could be not yet installed, e.g. during init.
+	gvr, _ := meta.UnsafeGuessKindToResource(gvk)
+	return resourceAccess{
+		group:     gvr.Group,
+		resource:  gvr.Resource,
+		namespace: namespace,
+		verbs:     verbs,
+	}
+}
+
+// String returns a human readable description of the access, e.g. "create, delete secrets in namespace ns1".
+func (a resourceAccess) String() string {
+	resource := a.resource
+	if a.group != "" {
+		resource = fmt.Sprintf("%s.%s", a.resource, a.group)
+	}
+	scope := "cluster-wide"
+	if a.namespace != "" {
+		scope = fmt.Sprintf("in namespace %s", a.namespace)
+	}
+	return fmt.Sprintf("%s %s %s", strings.Join(a.verbs, ", "), resource, scope)
+}
+
+// checkPermissions verifies that the current identity is allowed to perform all the required verbs on the
+// management cluster by using SelfSubjectAccessReviews; all the missing permissions are reported at once,
+// so an operation can fail before changing anything instead of failing halfway through.
+func checkPermissions(proxy Proxy, required []resourceAccess) error {
+	c, err := proxy.NewClient()
+	if err != nil {
+		return err
+	}
+
+	// Merge the verbs required on the same resource in the same namespace, preserving the order.
+	merged := []*resourceAccess{}
+	index := map[string]*resourceAccess{}
+	for _, access := range required {
+		key := fmt.Sprintf("%s/%s/%s", access.group, access.resource, access.namespace)
+		m, ok := index[key]
+		if !ok {
+			m = &resourceAccess{group: access.group, resource: access.resource, namespace: access.namespace}
+			index[key] = m
+			merged = append(merged, m)
+		}
+		for _, verb := range access.verbs {
+			if !containsString(m.verbs, verb) {
+				m.verbs = append(m.verbs, verb)
+			}
+		}
+	}
+
+	missing := []string{}
+	for _, access := range merged {
+		denied := resourceAccess{group: access.group, resource: access.resource, namespace: access.namespace}
+		for _, verb := range access.verbs {
+			review := &authorizationv1.SelfSubjectAccessReview{
+				Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+					ResourceAttributes: &authorizationv1.ResourceAttributes{
+						Namespace: access.namespace,
+						Verb:      verb,
+						Group:     access.group,
+						Resource:  access.resource,
+					},
+				},
+			}
+			if err := c.Create(ctx, review); err != nil {
+				return errors.Wrapf(err, "failed to check if the current identity can %s %s", verb, access.resource)
+			}
+			if !review.Status.Allowed {
+				denied.verbs = append(denied.verbs, verb)
+			}
+		}
+		if len(denied.verbs) > 0 {
+			missing = append(missing, denied.String())
+		}
+	}
+
+	if len(missing) > 0 {
+		return errors.Errorf("the current identity is missing the following permissions on the management cluster:\n- %s\nplease grant the missing permissions and retry", strings.Join(missing, "\n- "))
+	}
+	return nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+)
+
+func Test_newResourceAccess(t *testing.T) {
+	g := NewWithT(t)
+
+	access := newResourceAccess(clusterv1.GroupVersion.WithKind("MachineDeployment"), "ns1", "get", "create")
+	g.Expect(access.group).To(Equal(clusterv1.GroupVersion.Group))
+	g.Expect(access.resource).To(Equal("machinedeployments"))
+	g.Expect(access.String()).To(Equal("get, create machinedeployments.cluster.x-k8s.io in namespace ns1"))
+
+	access = newResourceAccess(corev1.SchemeGroupVersion.WithKind("Namespace"), "", "create")
+	g.Expect(access.String()).To(Equal("create namespaces cluster-wide"))
+}
+
+func Test_checkPermissions(t *testing.T) {
+	required := []resourceAccess{
+		newResourceAccess(clusterv1.GroupVersion.WithKind("Cluster"), "ns1", "get", "create"),
+		newResourceAccess(corev1.SchemeGroupVersion.WithKind("Secret"), "ns1", "get", "create", "delete"),
+		newResourceAccess(clusterv1.GroupVersion.WithKind("Cluster"), "ns1", "create", "delete"),
+	}
+
+	tests := []struct {
+		name        string
+		proxy       Proxy
+		wantErr     bool
+		wantMissing []string
+	}{
+		{
+			name:    "pass if all the permissions are granted",
+			proxy:   test.NewFakeProxy(),
+			wantErr: false,
+		},
+		{
+			name: "report all the missing permissions",
+			proxy: test.NewFakeProxy().WithDeniedAccess(
+				authorizationv1.ResourceAttributes{Namespace: "ns1", Verb: "delete", Group: clusterv1.GroupVersion.Group, Resource: "clusters"},
+				authorizationv1.ResourceAttributes{Namespace: "ns1", Verb: "create", Resource: "secrets"},
+				authorizationv1.ResourceAttributes{Namespace: "ns1", Verb: "delete", Resource: "secrets"},
+			),
+			wantErr: true,
+			wantMissing: []string{
+				"- delete clusters.cluster.x-k8s.io in namespace ns1",
+				"- create, delete secrets in namespace ns1",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			err := checkPermissions(tt.proxy, required)
+			if !tt.wantErr {
+				g.Expect(err).NotTo(HaveOccurred())
+				return
+			}
+			g.Expect(err).To(HaveOccurred())
+			for _, missing := range tt.wantMissing {
+				g.Expect(err.Error()).To(ContainSubstring(missing))
+			}
+		})
+	}
+}
@@ -0,0 +1,345 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes"
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	logf "sigs.k8s.io/cluster-api/cmd/clusterctl/log"
+	"sigs.k8s.io/cluster-api/version"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// redactedValue is used to replace sensitive values in the support bundle.
+	redactedValue = "REDACTED"
+
+	// lastAppliedConfigAnnotation is removed from all the objects because it may contain a copy of sensitive values.
+	lastAppliedConfigAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+	// defaultSupportBundleLogTailLines is the default number of log lines collected for each controller container.
+	defaultSupportBundleLogTailLines = int64(1000)
+)
+
+// SupportBundleOptions carries the options supported by SupportBundleCollector.Collect.
+type SupportBundleOptions struct {
+	// Namespace where the objects describing the workload clusters exists. If empty, all the namespaces are considered.
+	Namespace string
+
+	// LogTailLines defines the number of log lines to collect for each controller container.
+	// If zero, the default number of lines is collected; if negative, logs are not collected.
+	LogTailLines int64
+}
+
+// SupportBundleCollector defines methods for collecting troubleshooting information from a management cluster.
+type SupportBundleCollector interface {
+	// Collect writes a gzipped tarball containing sanitized Cluster API objects, the object graph,
+	// the provider inventory, version information and controller logs to the given writer.
+	// NOTE: Secret values are always redacted and nothing is ever sent outside of the local machine.
+	Collect(options SupportBundleOptions, w io.Writer) error
+}
+
+// controllerLogReader reads the logs for a container; it is defined as a func so it can be replaced in tests.
+type controllerLogReader func(namespace, pod, container string, tailLines int64) ([]byte, error)
+
+// supportBundleCollector implements SupportBundleCollector.
+type supportBundleCollector struct {
+	proxy             Proxy
+	providerInventory InventoryClient
+	logReader         controllerLogReader
+}
+
+// ensure supportBundleCollector implements SupportBundleCollector.
+var _ SupportBundleCollector = &supportBundleCollector{}
+
+func newSupportBundleCollector(proxy Proxy, providerInventory InventoryClient) *supportBundleCollector {
+	c := &supportBundleCollector{
+		proxy:             proxy,
+		providerInventory: providerInventory,
+	}
+	c.logReader = c.readControllerLogs
+	return c
+}
+
+func (s *supportBundleCollector) Collect(options SupportBundleOptions, w io.Writer) error {
+	log := logf.Log
+	log.Info("Collecting support bundle...")
+
+	if options.LogTailLines == 0 {
+		options.LogTailLines = defaultSupportBundleLogTailLines
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	if err := s.collect(options, tw); err != nil {
+		return err
+	}
+
+	if err := tw.Close(); err != nil {
+		return errors.Wrap(err, "failed to finalize the support bundle archive")
+	}
+	return gz.Close()
+}
+
+func (s *supportBundleCollector) collect(options SupportBundleOptions, tw *tar.Writer) error {
+	log := logf.Log
+
+	// Version information for clusterctl and for the management cluster.
+	log.V(1).Info("Collecting version information")
+	if err := s.collectVersions(tw); err != nil {
+		return err
+	}
+
+	// Provider inventory.
+	log.V(1).Info("Collecting provider inventory")
+	providers, err := s.providerInventory.List()
+	if err != nil {
+		return errors.Wrap(err, "failed to get provider inventory")
+	}
+	if err := writeYAMLToArchive(tw, "inventory.yaml", providers); err != nil {
+		return err
+	}
+
+	// Cluster API objects and the object graph; NB. the graph is discovered without checking for provisioning to be completed
+	// given that the bundle is most likely collected when something is not working as expected.
+	log.V(1).Info("Collecting Cluster API objects")
+	graph := newObjectGraph(s.proxy, s.providerInventory)
+	if err := graph.getDiscoveryTypes(); err != nil {
+		return errors.Wrap(err, "failed to retrieve discovery types")
+	}
+	if err := graph.Discovery(options.Namespace); err != nil {
+		return errors.Wrap(err, "failed to discover the object graph")
+	}
+	if err := s.collectObjects(graph, tw); err != nil {
+		return err
+	}
+	if err := writeToArchive(tw, "graph.txt", printObjectGraph(graph)); err != nil {
+		return err
+	}
+
+	// Controller logs.
+	if options.LogTailLines < 0 {
+		return nil
+	}
+	log.V(1).Info("Collecting controller logs")
+	return s.collectLogs(providers, options.LogTailLines, tw)
+}
+
+func (s *supportBundleCollector) collectVersions(tw *tar.Writer) error {
+	versions := map[string]interface{}{
+		"clusterctl": version.Get(),
+	}
+
+	// NB. The management cluster version is best effort, given that the bundle should be collected even if the server is not fully working.
+	if config, err := s.proxy.GetConfig(); err == nil && config != nil {
+		if cs, err := kubernetes.NewForConfig(config); err == nil {
+			if serverVersion, err := cs.Discovery().ServerVersion(); err == nil {
+				versions["managementCluster"] = serverVersion
+			}
+		}
+	}
+
+	return writeYAMLToArchive(tw, "versions.yaml", versions)
+}
+
+func (s *supportBundleCollector) collectObjects(graph *objectGraph, tw *tar.Writer) error {
+	c, err := s.proxy.NewClient()
+	if err != nil {
+		return err
+	}
+
+	for _, n := range sortedNodes(graph.getMoveNodes()) {
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion(n.identity.APIVersion)
+		obj.SetKind(n.identity.Kind)
+		objKey := client.ObjectKey{
+			Namespace: n.identity.Namespace,
+			Name:      n.identity.Name,
+		}
+
+		if err := c.Get(ctx, objKey, obj); err != nil {
+			return errors.Wrapf(err, "error reading %q %s/%s",
+				obj.GroupVersionKind(), obj.GetNamespace(), obj.GetName())
+		}
+
+		sanitizeObject(obj)
+
+		if err := writeYAMLToArchive(tw, path.Join("objects", n.getFilename()), obj.Object); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *supportBundleCollector) collectLogs(providers *clusterctlv1.ProviderList, tailLines int64, tw *tar.Writer) error {
+	log := logf.Log
+
+	c, err := s.proxy.NewClient()
+	if err != nil {
+		return err
+	}
+
+	for _, p := range providers.Items {
+		podList := &corev1.PodList{}
+		if err := c.List(ctx, podList, client.InNamespace(p.Namespace), client.MatchingLabels{clusterctlv1.ClusterctlLabelName: ""}); err != nil {
+			return errors.Wrapf(err, "failed to list controller pods for provider %s", p.InstanceName())
+		}
+
+		for _, pod := range podList.Items {
+			for _, container := range pod.Spec.Containers {
+				// NB. Logs are best effort, so failures are reported in the bundle instead of failing the whole collection.
+				logs, err := s.logReader(pod.Namespace, pod.Name, container.Name, tailLines)
+				if err != nil {
+					log.V(5).Info("Failed to read controller logs", "Pod", pod.Name, "Namespace", pod.Namespace, "Container", container.Name, "Error", err.Error())
+					logs = []byte(fmt.Sprintf("failed to read logs: %v\n", err))
+				}
+				if err := writeToArchive(tw, path.Join("logs", pod.Namespace, pod.Name, container.Name+".log"), logs); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (s *supportBundleCollector) readControllerLogs(namespace, pod, container string, tailLines int64) ([]byte, error) {
+	config, err := s.proxy.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	if config == nil {
+		return nil, errors.New("rest config for the management cluster is not available")
+	}
+
+	cs, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create the client-go client")
+	}
+
+	return cs.CoreV1().Pods(namespace).GetLogs(pod, &corev1.PodLogOptions{
+		Container: container,
+		TailLines: &tailLines,
+	}).DoRaw(ctx)
+}
+
+// sanitizeObject removes sensitive or noisy information from an object before adding it to the support bundle.
+func sanitizeObject(obj *unstructured.Unstructured) {
+	obj.SetManagedFields(nil)
+
+	if annotations := obj.GetAnnotations(); annotations != nil {
+		delete(annotations, lastAppliedConfigAnnotation)
+		obj.SetAnnotations(annotations)
+	}
+
+	if obj.GetAPIVersion() != "v1" || obj.GetKind() != "Secret" {
+		return
+	}
+
+	// Secrets are redacted by replacing all the values, but preserving the keys, which are useful for troubleshooting.
+	for _, field := range []string{"data", "stringData"} {
+		values, found, err := unstructured.NestedMap(obj.Object, field)
+		if err != nil || !found {
+			continue
+		}
+		for k := range values {
+			values[k] = redactedValue
+		}
+		_ = unstructured.SetNestedMap(obj.Object, values, field)
+	}
+}
+
+// printObjectGraph returns a textual representation of the object graph with the owner and soft owner relations for each node.
+func printObjectGraph(graph *objectGraph) []byte {
+	var b bytes.Buffer
+	for _, n := range sortedNodes(graph.getNodes()) {
+		fmt.Fprintf(&b, "%s\n", nodeDisplayName(n))
+
+		owners := []string{}
+		for owner := range n.owners {
+			owners = append(owners, nodeDisplayName(owner))
+		}
+		sort.Strings(owners)
+		for _, owner := range owners {
+			fmt.Fprintf(&b, "  owner: %s\n", owner)
+		}
+
+		softOwners := []string{}
+		for owner := range n.softOwners {
+			softOwners = append(softOwners, nodeDisplayName(owner))
+		}
+		sort.Strings(softOwners)
+		for _, owner := range softOwners {
+			fmt.Fprintf(&b, "  soft owner: %s\n", owner)
+		}
+	}
+	return b.Bytes()
+}
+
+func nodeDisplayName(n *node) string {
+	name := fmt.Sprintf("%s, %s/%s", n.identity.Kind, n.identity.Namespace, n.identity.Name)
+	if n.virtual {
+		name += " (virtual)"
+	}
+	return name
+}
+
+// sortedNodes returns a copy of the list of nodes sorted by display name, so the bundle content is deterministic.
+func sortedNodes(nodes []*node) []*node {
+	ret := append([]*node{}, nodes...)
+	sort.Slice(ret, func(i, j int) bool {
+		return nodeDisplayName(ret[i]) < nodeDisplayName(ret[j])
+	})
+	return ret
+}
+
+func writeYAMLToArchive(tw *tar.Writer, name string, obj interface{}) error {
+	data, err := yaml.Marshal(obj)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal %s", name)
+	}
+	return writeToArchive(tw, name, data)
+}
+
+func writeToArchive(tw *tar.Writer, name string, data []byte) error {
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0600,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return errors.Wrapf(err, "failed to write %s to the support bundle", name)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return errors.Wrapf(err, "failed to write %s to the support bundle", name)
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+	"sigs.k8s.io/yaml"
+)
+
+func Test_sanitizeObject(t *testing.T) {
+	tests := []struct {
+		name string
+		obj  map[string]interface{}
+		want map[string]interface{}
+	}{
+		{
+			name: "secret values are redacted",
+			obj: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Secret",
+				"metadata": map[string]interface{}{
+					"name": "foo-kubeconfig",
+				},
+				"data": map[string]interface{}{
+					"value": "c2VjcmV0",
+				},
+				"stringData": map[string]interface{}{
+					"other": "secret",
+				},
+			},
+			want: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Secret",
+				"metadata": map[string]interface{}{
+					"name": "foo-kubeconfig",
+				},
+				"data": map[string]interface{}{
+					"value": redactedValue,
+				},
+				"stringData": map[string]interface{}{
+					"other": redactedValue,
+				},
+			},
+		},
+		{
+			name: "last applied configuration and managed fields are removed",
+			obj: map[string]interface{}{
+				"apiVersion": "cluster.x-k8s.io/v1alpha4",
+				"kind":       "Cluster",
+				"metadata": map[string]interface{}{
+					"name": "foo",
+					"annotations": map[string]interface{}{
+						lastAppliedConfigAnnotation: "{}",
+						"foo":                       "bar",
+					},
+					"managedFields": []interface{}{
+						map[string]interface{}{
+							"manager": "clusterctl",
+						},
+					},
+				},
+			},
+			want: map[string]interface{}{
+				"apiVersion": "cluster.x-k8s.io/v1alpha4",
+				"kind":       "Cluster",
+				"metadata": map[string]interface{}{
+					"name": "foo",
+					"annotations": map[string]interface{}{
+						"foo": "bar",
+					},
+				},
+			},
+		},
+		{
+			name: "data is preserved for objects other than secrets",
+			obj: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata": map[string]interface{}{
+					"name": "foo",
+				},
+				"data": map[string]interface{}{
+					"value": "bar",
+				},
+			},
+			want: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata": map[string]interface{}{
+					"name": "foo",
+				},
+				"data": map[string]interface{}{
+					"value": "bar",
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			obj := &unstructured.Unstructured{Object: tt.obj}
+			sanitizeObject(obj)
+
+			g.Expect(obj.Object).To(Equal(tt.want))
+		})
+	}
+}
+
+func Test_supportBundleCollector_collectObjects(t *testing.T) {
+	g := NewWithT(t)
+
+	// Create an objectGraph bound a source cluster with all the CRDs for the types involved in the test.
+	graph := getObjectGraphWithObjs(test.NewFakeCluster("ns1", "foo").Objs())
+
+	// Get all the types to be considered for discovery
+	g.Expect(getFakeDiscoveryTypes(graph)).To(Succeed())
+
+	// trigger discovery the content of the source cluster
+	g.Expect(graph.Discovery("")).To(Succeed())
+
+	collector := newSupportBundleCollector(graph.proxy, graph.providerInventory)
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	g.Expect(collector.collectObjects(graph, tw)).To(Succeed())
+	g.Expect(tw.Close()).To(Succeed())
+
+	// Read back all the files in the archive.
+	files := map[string][]byte{}
+	tr := tar.NewReader(&buf)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		g.Expect(err).NotTo(HaveOccurred())
+
+		data, err := ioutil.ReadAll(tr)
+		g.Expect(err).NotTo(HaveOccurred())
+		files[header.Name] = data
+	}
+
+	// All the nodes selected for move are included in the bundle.
+	for _, n := range graph.getMoveNodes() {
+		g.Expect(files).To(HaveKey("objects/" + n.getFilename()))
+	}
+
+	// Secrets are included, but values are redacted.
+	secrets := 0
+	for name, data := range files {
+		if !strings.HasPrefix(name, "objects/Secret_") {
+			continue
+		}
+		secrets++
+
+		obj := &unstructured.Unstructured{}
+		g.Expect(yaml.Unmarshal(data, &obj.Object)).To(Succeed())
+
+		values, _, err := unstructured.NestedMap(obj.Object, "data")
+		g.Expect(err).NotTo(HaveOccurred())
+		for _, v := range values {
+			g.Expect(v).To(Equal(redactedValue))
+		}
+	}
+	g.Expect(secrets).To(BeNumerically(">", 0))
+}
+
+func Test_printObjectGraph(t *testing.T) {
+	g := NewWithT(t)
+
+	graph := getObjectGraphWithObjs(test.NewFakeCluster("ns1", "foo").Objs())
+	g.Expect(getFakeDiscoveryTypes(graph)).To(Succeed())
+	g.Expect(graph.Discovery("")).To(Succeed())
+
+	out := string(printObjectGraph(graph))
+
+	g.Expect(out).To(ContainSubstring("Cluster, ns1/foo\n"))
+	g.Expect(out).To(ContainSubstring("  owner: Cluster, ns1/foo\n"))
+	g.Expect(out).To(ContainSubstring("  soft owner: Cluster, ns1/foo\n"))
+}
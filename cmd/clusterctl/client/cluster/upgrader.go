@@ -17,6 +17,8 @@ limitations under the License.
 package cluster
 
 import (
+	"strings"
+
 	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/version"
@@ -34,11 +36,14 @@ type ProviderUpgrader interface {
 	//   - Upgrade to the latest version in the the v1alpha4 series: ....
 	Plan() ([]UpgradePlan, error)
 
-	// ApplyPlan executes an upgrade following an UpgradePlan generated by clusterctl.
-	ApplyPlan(clusterAPIVersion string) error
+	// ApplyPlan executes an upgrade following an UpgradePlan generated by clusterctl, executing the
+	// installation phases defined in the options; the providers in skipProviders (e.g. capa-system/aws)
+	// are held back at their current version.
+	ApplyPlan(options InstallOptions, clusterAPIVersion string, skipProviders ...string) error
 
-	// ApplyCustomPlan plan executes an upgrade using the UpgradeItems provided by the user.
-	ApplyCustomPlan(providersToUpgrade ...UpgradeItem) error
+	// ApplyCustomPlan plan executes an upgrade using the UpgradeItems provided by the user, executing the
+	// installation phases defined in the options.
+	ApplyCustomPlan(options InstallOptions, providersToUpgrade ...UpgradeItem) error
 }
 
 // UpgradePlan defines a list of possible upgrade targets for a management cluster.
@@ -61,6 +66,10 @@ func (u *UpgradePlan) isPartialUpgrade() bool {
 type UpgradeItem struct {
 	clusterctlv1.Provider
 	NextVersion string
+
+	// UpgradeNotes are the notes defined in the provider metadata for the release series included in the upgrade
+	// from the current version to the next version, e.g. breaking changes.
+	UpgradeNotes []string
 }
 
 // UpgradeRef returns a string identifying the upgrade item; this string is derived by the provider.
@@ -139,7 +148,7 @@ func (u *providerUpgrader) Plan() ([]UpgradePlan, error) {
 	return ret, nil
 }
 
-func (u *providerUpgrader) ApplyPlan(contract string) error {
+func (u *providerUpgrader) ApplyPlan(options InstallOptions, contract string, skipProviders ...string) error {
 	if contract != clusterv1.GroupVersion.Version {
 		return errors.Errorf("current version of clusterctl could only upgrade to %s contract, requested %s", clusterv1.GroupVersion.Version, contract)
 	}
@@ -158,11 +167,16 @@ func (u *providerUpgrader) ApplyPlan(contract string) error {
 		return err
 	}
 
+	// Holds back the providers the user asked to skip, if any.
+	if err := u.skipUpgradeItems(upgradePlan, skipProviders); err != nil {
+		return err
+	}
+
 	// Do the upgrade
-	return u.doUpgrade(upgradePlan)
+	return u.doUpgrade(upgradePlan, options)
 }
 
-func (u *providerUpgrader) ApplyCustomPlan(upgradeItems ...UpgradeItem) error {
+func (u *providerUpgrader) ApplyCustomPlan(options InstallOptions, upgradeItems ...UpgradeItem) error {
 	log := logf.Log
 	log.Info("Performing upgrade...")
 
@@ -174,7 +188,7 @@ func (u *providerUpgrader) ApplyCustomPlan(upgradeItems ...UpgradeItem) error {
 	}
 
 	// Do the upgrade
-	return u.doUpgrade(upgradePlan)
+	return u.doUpgrade(upgradePlan, options)
 }
 
 // getUpgradePlan returns the upgrade plan for a specific set of providers/contract
@@ -193,8 +207,9 @@ func (u *providerUpgrader) getUpgradePlan(providers []clusterctlv1.Provider, con
 
 		// Append the upgrade item for the provider/with the target contract.
 		upgradeItems = append(upgradeItems, UpgradeItem{
-			Provider:    provider,
-			NextVersion: versionTag(nextVersion),
+			Provider:     provider,
+			NextVersion:  versionTag(nextVersion),
+			UpgradeNotes: providerUpgradeInfo.getUpgradeNotes(nextVersion),
 		})
 	}
 
@@ -293,6 +308,49 @@ func (u *providerUpgrader) createCustomPlan(upgradeItems []UpgradeItem) (*Upgrad
 	return upgradePlan, nil
 }
 
+// skipUpgradeItems holds back the providers in skipProviders at their current version, ensuring that the current
+// version of each skipped provider supports the API Version of Cluster API (contract) of the upgrade plan, so the
+// providers in the management cluster are still consistent after the upgrade.
+func (u *providerUpgrader) skipUpgradeItems(upgradePlan *UpgradePlan, skipProviders []string) error {
+	log := logf.Log
+
+	for _, ref := range skipProviders {
+		refSplit := strings.Split(strings.ToLower(ref), "/")
+		if len(refSplit) != 2 || refSplit[0] == "" || refSplit[1] == "" {
+			return errors.Errorf("invalid provider name %q. Provider name should be in the form namespace/name", ref)
+		}
+		namespace, name := refSplit[0], refSplit[1]
+
+		found := false
+		for i := range upgradePlan.Providers {
+			upgradeItem := &upgradePlan.Providers[i]
+			if upgradeItem.Namespace != namespace || (upgradeItem.ProviderName != name && upgradeItem.Name != name) {
+				continue
+			}
+			found = true
+
+			// Retrieves the contract that is supported by the current version of the provider.
+			contract, err := u.getProviderContractByVersion(upgradeItem.Provider, upgradeItem.Version)
+			if err != nil {
+				return err
+			}
+			if contract != upgradePlan.Contract {
+				return errors.Errorf("unable to skip the provider %s: its current version %s supports the %s API Version of Cluster API (contract), while the management cluster is being upgraded to %s", upgradeItem.InstanceName(), upgradeItem.Version, contract, upgradePlan.Contract)
+			}
+
+			if upgradeItem.NextVersion != "" {
+				log.Info("Skipping provider upgrade", "Provider", upgradeItem.InstanceName(), "Version", upgradeItem.Version, "SkippedVersion", upgradeItem.NextVersion)
+			}
+			upgradeItem.NextVersion = ""
+			upgradeItem.UpgradeNotes = nil
+		}
+		if !found {
+			return errors.Errorf("unable to skip the provider %s: the provider is not part of the management cluster", ref)
+		}
+	}
+	return nil
+}
+
 // getProviderContractByVersion returns the contract that a provider will support if updated to the given target version.
 func (u *providerUpgrader) getProviderContractByVersion(provider clusterctlv1.Provider, targetVersion string) (string, error) {
 	targetSemVersion, err := version.ParseSemantic(targetVersion)
@@ -336,7 +394,11 @@ func (u *providerUpgrader) getUpgradeComponents(provider UpgradeItem) (repositor
 	return components, nil
 }
 
-func (u *providerUpgrader) doUpgrade(upgradePlan *UpgradePlan) error {
+func (u *providerUpgrader) doUpgrade(upgradePlan *UpgradePlan, options InstallOptions) error {
+	if options.DryRun {
+		return u.diffUpgrade(upgradePlan, options)
+	}
+
 	// Check for multiple instances of the same provider if current contract is v1alpha3.
 	if upgradePlan.Contract == clusterv1.GroupVersion.Version {
 		if err := u.providerInventory.CheckSingleProviderInstance(); err != nil {
@@ -357,22 +419,26 @@ func (u *providerUpgrader) doUpgrade(upgradePlan *UpgradePlan) error {
 		}
 
 		// Delete the provider, preserving CRD and namespace.
-		if err := u.providerComponents.Delete(DeleteOptions{
-			Provider:         upgradeItem.Provider,
-			IncludeNamespace: false,
-			IncludeCRDs:      false,
-		}); err != nil {
-			return err
+		// NOTE: This is not required when upgrading only the CRDs, given that the provider is still running
+		// the current version.
+		if options.HasPhase(InstallComponentsPhase) {
+			if err := u.providerComponents.Delete(DeleteOptions{
+				Provider:         upgradeItem.Provider,
+				IncludeNamespace: false,
+				IncludeCRDs:      false,
+			}); err != nil {
+				return err
+			}
 		}
 
 		// Install the new version of the provider components.
-		if err := installComponentsAndUpdateInventory(components, u.providerComponents, u.providerInventory); err != nil {
+		if err := installComponentsAndUpdateInventory(components, u.providerComponents, u.providerInventory, options); err != nil {
 			return err
 		}
 	}
 
 	// Delete webhook namespace since it's not needed from v1alpha4.
-	if upgradePlan.Contract == clusterv1.GroupVersion.Version {
+	if upgradePlan.Contract == clusterv1.GroupVersion.Version && options.HasPhase(InstallComponentsPhase) {
 		if err := u.providerComponents.DeleteWebhookNamespace(); err != nil {
 			return err
 		}
@@ -381,6 +447,47 @@ func (u *providerUpgrader) doUpgrade(upgradePlan *UpgradePlan) error {
 	return nil
 }
 
+// diffUpgrade reports the provider components added, changed or removed by the upgrade plan, without changing
+// the management cluster.
+func (u *providerUpgrader) diffUpgrade(upgradePlan *UpgradePlan, options InstallOptions) error {
+	log := logf.Log
+
+	for _, upgradeItem := range upgradePlan.Providers {
+		// If there is not a specified next version, skip it (we are already up-to-date).
+		if upgradeItem.NextVersion == "" {
+			continue
+		}
+
+		// Gets the provider components for the target version.
+		components, err := u.getUpgradeComponents(upgradeItem)
+		if err != nil {
+			return err
+		}
+
+		diff, err := u.providerComponents.Diff(upgradeItem.Provider, componentsObjsForPhases(components, options))
+		if err != nil {
+			return err
+		}
+		// Provider components are removed only when upgrading all the provider components.
+		if !options.HasPhase(InstallComponentsPhase) {
+			diff.Removed = nil
+		}
+
+		log.Info("Upgrade dry-run", "Provider", upgradeItem.InstanceName(), "Version", upgradeItem.Version, "TargetVersion", upgradeItem.NextVersion,
+			"Added", len(diff.Added), "Changed", len(diff.Changed), "Removed", len(diff.Removed))
+		for _, ref := range diff.Added {
+			log.Info("Component would be added", "Component", ref)
+		}
+		for _, ref := range diff.Changed {
+			log.Info("Component would be changed", "Component", ref)
+		}
+		for _, ref := range diff.Removed {
+			log.Info("Component would be removed", "Component", ref)
+		}
+	}
+	return nil
+}
+
 func newProviderUpgrader(configClient config.Client, repositoryClientFactory RepositoryClientFactory, providerInventory InventoryClient, providerComponents ComponentsClient) *providerUpgrader {
 	return &providerUpgrader{
 		configClient:            configClient,
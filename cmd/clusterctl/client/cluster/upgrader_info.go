@@ -191,6 +191,28 @@ func (i *upgradeInfo) getLatestNextVersion(contract string) *version.Version {
 	return latestNextVersion
 }
 
+// getUpgradeNotes returns the upgrade notes for the release series included in the upgrade from the current version
+// to the next version, i.e. all the release series newer than the current one and older or equal than the next one.
+func (i *upgradeInfo) getUpgradeNotes(nextVersion *version.Version) []string {
+	if nextVersion == nil {
+		return nil
+	}
+
+	var notes []string
+	for _, releaseSeries := range i.metadata.ReleaseSeries {
+		// Skip the release series if older or equal than the current version.
+		if releaseSeries.Major < i.currentVersion.Major() || (releaseSeries.Major == i.currentVersion.Major() && releaseSeries.Minor <= i.currentVersion.Minor()) {
+			continue
+		}
+		// Skip the release series if newer than the next version.
+		if releaseSeries.Major > nextVersion.Major() || (releaseSeries.Major == nextVersion.Major() && releaseSeries.Minor > nextVersion.Minor()) {
+			continue
+		}
+		notes = append(notes, releaseSeries.UpgradeNotes...)
+	}
+	return notes
+}
+
 // versionTag converts a version to a RepositoryTag.
 func versionTag(version *version.Version) string {
 	if version == nil {
@@ -426,6 +426,61 @@ func Test_upgradeInfo_getLatestNextVersion(t *testing.T) {
 	}
 }
 
+func Test_upgradeInfo_getUpgradeNotes(t *testing.T) {
+	metadata := &clusterctlv1.Metadata{
+		ReleaseSeries: []clusterctlv1.ReleaseSeries{
+			{Major: 1, Minor: 2, Contract: test.CurrentCAPIContract, UpgradeNotes: []string{"notes for v1.2"}},
+			{Major: 1, Minor: 3, Contract: test.CurrentCAPIContract, UpgradeNotes: []string{"notes for v1.3"}},
+			{Major: 2, Minor: 0, Contract: test.NextCAPIContractNotSupported, UpgradeNotes: []string{"notes for v2.0", "other notes for v2.0"}},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		currentVersion string
+		nextVersion    string
+		want           []string
+	}{
+		{
+			name:           "No notes without a next version",
+			currentVersion: "v1.2.3",
+			nextVersion:    "",
+			want:           nil,
+		},
+		{
+			name:           "No notes for an upgrade in the same release series",
+			currentVersion: "v1.2.3",
+			nextVersion:    "v1.2.5",
+			want:           nil,
+		},
+		{
+			name:           "Notes for the next release series",
+			currentVersion: "v1.2.3",
+			nextVersion:    "v1.3.1",
+			want:           []string{"notes for v1.3"},
+		},
+		{
+			name:           "Notes for all the release series included in the upgrade",
+			currentVersion: "v1.2.3",
+			nextVersion:    "v2.0.2",
+			want:           []string{"notes for v1.3", "notes for v2.0", "other notes for v2.0"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			upgradeInfo := newUpgradeInfo(metadata.DeepCopy(), version.MustParseSemantic(tt.currentVersion), nil)
+
+			var nextVersion *version.Version
+			if tt.nextVersion != "" {
+				nextVersion = version.MustParseSemantic(tt.nextVersion)
+			}
+			g.Expect(upgradeInfo.getUpgradeNotes(nextVersion)).To(Equal(tt.want))
+		})
+	}
+}
+
 func toSemanticVersions(versions []string) []version.Version {
 	semanticVersions := []version.Version{}
 	for _, v := range versions {
@@ -899,7 +899,7 @@ func Test_providerUpgrader_ApplyPlan(t *testing.T) {
 				},
 				providerInventory: newInventoryClient(tt.fields.proxy, nil),
 			}
-			err := u.ApplyPlan(tt.contract)
+			err := u.ApplyPlan(InstallOptions{}, tt.contract)
 			if tt.wantErr {
 				g.Expect(err).To(HaveOccurred())
 				g.Expect(err.Error()).Should(ContainSubstring(tt.errorMsg))
@@ -911,6 +911,109 @@ func Test_providerUpgrader_ApplyPlan(t *testing.T) {
 	}
 }
 
+func Test_providerUpgrader_skipUpgradeItems(t *testing.T) {
+	reader := test.NewFakeReader().
+		WithProvider("cluster-api", clusterctlv1.CoreProviderType, "https://somewhere.com").
+		WithProvider("infra", clusterctlv1.InfrastructureProviderType, "https://somewhere.com")
+	repositories := map[string]repository.Repository{
+		"cluster-api": test.NewFakeRepository().
+			WithVersions("v1.0.0", "v2.0.0", "v2.0.1").
+			WithMetadata("v2.0.1", &clusterctlv1.Metadata{
+				ReleaseSeries: []clusterctlv1.ReleaseSeries{
+					{Major: 1, Minor: 0, Contract: test.PreviousCAPIContractNotSupported},
+					{Major: 2, Minor: 0, Contract: test.CurrentCAPIContract},
+				},
+			}),
+		"infrastructure-infra": test.NewFakeRepository().
+			WithVersions("v2.0.0", "v3.0.0", "v3.0.1").
+			WithMetadata("v3.0.1", &clusterctlv1.Metadata{
+				ReleaseSeries: []clusterctlv1.ReleaseSeries{
+					{Major: 2, Minor: 0, Contract: test.PreviousCAPIContractNotSupported},
+					{Major: 3, Minor: 0, Contract: test.CurrentCAPIContract},
+				},
+			}),
+	}
+
+	newPlan := func(infraVersion string) *UpgradePlan {
+		return &UpgradePlan{
+			Contract: test.CurrentCAPIContract,
+			Providers: []UpgradeItem{
+				{Provider: fakeProvider("cluster-api", clusterctlv1.CoreProviderType, "v2.0.0", "cluster-api-system"), NextVersion: "v2.0.1"},
+				{Provider: fakeProvider("infra", clusterctlv1.InfrastructureProviderType, infraVersion, "infra-system"), NextVersion: "v3.0.1"},
+			},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		plan          *UpgradePlan
+		skipProviders []string
+		wantVersions  []string
+		wantErr       bool
+		errorMsg      string
+	}{
+		{
+			name:          "skips the provider when its current version supports the target contract",
+			plan:          newPlan("v3.0.0"),
+			skipProviders: []string{"infra-system/infra"},
+			wantVersions:  []string{"v2.0.1", ""},
+		},
+		{
+			name:          "skips the provider referenced by its instance name",
+			plan:          newPlan("v3.0.0"),
+			skipProviders: []string{"infra-system/infrastructure-infra"},
+			wantVersions:  []string{"v2.0.1", ""},
+		},
+		{
+			name:          "fails when the current version of the provider does not support the target contract",
+			plan:          newPlan("v2.0.0"),
+			skipProviders: []string{"infra-system/infra"},
+			wantErr:       true,
+			errorMsg:      "while the management cluster is being upgraded to",
+		},
+		{
+			name:          "fails when the provider is not part of the management cluster",
+			plan:          newPlan("v3.0.0"),
+			skipProviders: []string{"other-system/infra"},
+			wantErr:       true,
+			errorMsg:      "the provider is not part of the management cluster",
+		},
+		{
+			name:          "fails when the provider name is invalid",
+			plan:          newPlan("v3.0.0"),
+			skipProviders: []string{"infra"},
+			wantErr:       true,
+			errorMsg:      "should be in the form namespace/name",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			configClient, _ := config.New("", config.InjectReader(reader))
+
+			u := &providerUpgrader{
+				configClient: configClient,
+				repositoryClientFactory: func(provider config.Provider, configClient config.Client, options ...repository.Option) (repository.Client, error) {
+					return repository.New(provider, configClient, repository.InjectRepository(repositories[provider.ManifestLabel()]))
+				},
+			}
+			err := u.skipUpgradeItems(tt.plan, tt.skipProviders)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).Should(ContainSubstring(tt.errorMsg))
+				return
+			}
+
+			g.Expect(err).NotTo(HaveOccurred())
+			for i, upgradeItem := range tt.plan.Providers {
+				g.Expect(upgradeItem.NextVersion).To(Equal(tt.wantVersions[i]))
+			}
+		})
+	}
+}
+
 // TODO add tests  for success scenarios.
 func Test_providerUpgrader_ApplyCustomPlan(t *testing.T) {
 	type fields struct {
@@ -1035,7 +1138,7 @@ func Test_providerUpgrader_ApplyCustomPlan(t *testing.T) {
 				},
 				providerInventory: newInventoryClient(tt.fields.proxy, nil),
 			}
-			err := u.ApplyCustomPlan(tt.providersToUpgrade...)
+			err := u.ApplyCustomPlan(InstallOptions{}, tt.providersToUpgrade...)
 			if tt.wantErr {
 				g.Expect(err).To(HaveOccurred())
 				g.Expect(err.Error()).Should(ContainSubstring(tt.errorMsg))
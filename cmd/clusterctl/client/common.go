@@ -22,6 +22,7 @@ import (
 	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/util/validation"
 	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/repository"
 )
 
@@ -44,8 +45,7 @@ func (c *clusterctlClient) getComponentsByName(provider string, providerType clu
 	// Get a client for the provider repository and read the provider components;
 	// during the process, provider components will be processed performing variable substitution, customization of target
 	// namespace etc.
-	// Currently we are not supporting custom yaml processors for the provider
-	// components. So we revert to using the default SimpleYamlProcessor.
+	// NOTE: A custom yaml processor for the provider components can be set in the options, otherwise the default SimpleYamlProcessor is used.
 	repositoryClientFactory, err := c.repositoryClientFactory(RepositoryClientFactoryInput{Provider: providerConfig})
 	if err != nil {
 		return nil, err
@@ -100,3 +100,22 @@ func validateDNS1123Domanin(subdomain string) error {
 	}
 	return nil
 }
+
+// parseInstallPhases returns the install options for the given list of installation phases, e.g. crds,components.
+func parseInstallPhases(phases []string) (cluster.InstallOptions, error) {
+	options := cluster.InstallOptions{}
+	for _, phase := range phases {
+		valid := false
+		for _, p := range cluster.InstallPhases {
+			if phase == string(p) {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return cluster.InstallOptions{}, errors.Errorf("invalid installation phase %q, valid phases are %v", phase, cluster.InstallPhases)
+		}
+		options.Phases = append(options.Phases, cluster.InstallPhase(phase))
+	}
+	return options, nil
+}
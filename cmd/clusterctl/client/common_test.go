@@ -20,6 +20,7 @@ import (
 	"testing"
 
 	. "github.com/onsi/gomega"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
 )
 
 func Test_parseProviderName(t *testing.T) {
@@ -68,3 +69,43 @@ func Test_parseProviderName(t *testing.T) {
 		})
 	}
 }
+
+func Test_parseInstallPhases(t *testing.T) {
+	tests := []struct {
+		name    string
+		phases  []string
+		want    cluster.InstallOptions
+		wantErr bool
+	}{
+		{
+			name:    "no phases",
+			phases:  nil,
+			want:    cluster.InstallOptions{},
+			wantErr: false,
+		},
+		{
+			name:    "all the phases",
+			phases:  []string{"crds", "components"},
+			want:    cluster.InstallOptions{Phases: []cluster.InstallPhase{cluster.InstallCRDsPhase, cluster.InstallComponentsPhase}},
+			wantErr: false,
+		},
+		{
+			name:    "invalid phase",
+			phases:  []string{"crds", "webhooks"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got, err := parseInstallPhases(tt.phases)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
@@ -45,6 +45,17 @@ func (c *clusterctlClient) GetProvidersConfig() ([]Provider, error) {
 	return rr, nil
 }
 
+func (c *clusterctlClient) GetCertManagerInfo() (CertManagerInfo, error) {
+	// NOTE: The management cluster is not accessed, so the cluster client is created with an empty kubeconfig.
+	clusterClient, err := c.clusterClientFactory(ClusterClientFactoryInput{})
+	if err != nil {
+		return CertManagerInfo{}, err
+	}
+
+	info, err := clusterClient.CertManager().Info()
+	return CertManagerInfo(info), err
+}
+
 func (c *clusterctlClient) GetProviderComponents(provider string, providerType clusterctlv1.ProviderType, options ComponentsOptions) (Components, error) {
 	components, err := c.getComponentsByName(provider, providerType, repository.ComponentsOptions(options))
 	if err != nil {
@@ -239,6 +250,25 @@ func (c *clusterctlClient) GetClusterTemplate(options GetClusterTemplateOptions)
 		return nil, err
 	}
 
+	template, err := c.getTemplate(clusterClient, options)
+	if err != nil {
+		return nil, err
+	}
+
+	// If the template defines Clusters with a managed topology, validate them against the ClusterClass they reference.
+	// NOTE: the validation is skipped when listing variables, because the template is not processed.
+	if !options.ListVariablesOnly {
+		// NOTE: ClusterClasses not included in the template are read from the management cluster, if reachable, using
+		// either the explicit kubeconfig or the default kubeconfig discovery rules.
+		if err := validateClusterTopology(template.Objs(), clusterClassFromManagementCluster(clusterClient.Proxy())); err != nil {
+			return nil, errors.Wrap(err, "invalid cluster topology")
+		}
+	}
+	return template, nil
+}
+
+// getTemplate returns a workload cluster template from the source defined in options.
+func (c *clusterctlClient) getTemplate(clusterClient cluster.Client, options GetClusterTemplateOptions) (Template, error) {
 	// Gets the workload cluster template from the selected source
 	if options.ProviderRepositorySource != nil {
 		// Ensure this command only runs against management clusters with the current Cluster API contract.
@@ -262,6 +292,50 @@ func (c *clusterctlClient) GetClusterTemplate(options GetClusterTemplateOptions)
 	return nil, errors.New("unable to read custom template. Please specify a template source")
 }
 
+// ListFlavorsOptions carries the options supported by ListFlavors.
+type ListFlavorsOptions struct {
+	// InfrastructureProvider to list the flavors of the workload cluster templates from, in the form name[:version].
+	// If the version is unspecified, the default version of the provider repository will be used, e.g. the latest release.
+	InfrastructureProvider string
+
+	// YamlProcessor defines the yaml processor used for the cluster templates.
+	// If not defined, SimpleProcessor will be used.
+	YamlProcessor Processor
+}
+
+func (c *clusterctlClient) ListFlavors(options ListFlavorsOptions) ([]Flavor, error) {
+	if options.InfrastructureProvider == "" {
+		return nil, errors.New("invalid arguments: please specify the infrastructure provider to list the flavors from")
+	}
+
+	name, version, err := parseProviderName(options.InfrastructureProvider)
+	if err != nil {
+		return nil, err
+	}
+
+	providerConfig, err := c.configClient.Providers().Get(name, clusterctlv1.InfrastructureProviderType)
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := c.repositoryClientFactory(RepositoryClientFactoryInput{Provider: providerConfig, Processor: options.YamlProcessor})
+	if err != nil {
+		return nil, err
+	}
+
+	flavors, err := repo.Templates(version).ListFlavors()
+	if err != nil {
+		return nil, err
+	}
+
+	// Flavor is an alias for repository.Flavor; this makes the conversion.
+	aliasFlavors := make([]Flavor, len(flavors))
+	for i, f := range flavors {
+		aliasFlavors[i] = Flavor(f)
+	}
+	return aliasFlavors, nil
+}
+
 // getTemplateFromRepository returns a workload cluster template from a provider repository.
 func (c *clusterctlClient) getTemplateFromRepository(cluster cluster.Client, options GetClusterTemplateOptions) (Template, error) {
 	source := *options.ProviderRepositorySource
@@ -327,7 +401,20 @@ func (c *clusterctlClient) getTemplateFromRepository(cluster cluster.Client, opt
 		return nil, err
 	}
 
-	template, err := repo.Templates(version).Get(source.Flavor, targetNamespace, listVariablesOnly)
+	templates := repo.Templates(version)
+
+	// If the provider does not publish a template for the topology flavor, use the built-in one.
+	if source.Flavor == TopologyFlavor {
+		published, err := hasFlavor(templates, TopologyFlavor)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to list the flavors of the provider %q", name)
+		}
+		if !published {
+			return getTopologyTemplate(c.configClient.Variables(), processor, targetNamespace, listVariablesOnly)
+		}
+	}
+
+	template, err := templates.Get(source.Flavor, targetNamespace, listVariablesOnly)
 	if err != nil {
 		return nil, err
 	}
@@ -29,6 +29,10 @@ const (
 	// CertManagerDefaultVersion defines the default cert-manager version to be used by clusterctl.
 	CertManagerDefaultVersion = "v1.4.0"
 
+	// CertManagerMinimumVersion defines the minimum cert-manager version supported by clusterctl, i.e. the first
+	// version serving the cert-manager.io/v1 API used by the providers.
+	CertManagerMinimumVersion = "v1.0.0"
+
 	// CertManagerDefaultURL defines the default cert-manager repository url to be used by clusterctl.
 	// NOTE: At runtime /latest will be replaced with the CertManagerDefaultVersion or with the
 	// version defined by the user in the clusterctl configuration file.
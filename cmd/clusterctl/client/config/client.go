@@ -26,6 +26,8 @@ import (
 // 2. The configuration of the providers (name, type and URL of the provider repository)
 // 3. Variables used when installing providers/creating clusters. Variables can be read from the environment or from the config file
 // 4. The configuration about image overrides.
+// 5. The configuration for verifying the signatures of provider components and metadata.
+// 6. The kustomize overlays to be applied to the provider components.
 type Client interface {
 	// CertManager provide access to the cert-manager configurations.
 	CertManager() CertManagerClient
@@ -38,11 +40,20 @@ type Client interface {
 
 	// ImageMeta provide access to to image meta configurations.
 	ImageMeta() ImageMetaClient
+
+	// SignatureVerification provide access to the signature verification configurations.
+	SignatureVerification() SignatureVerificationClient
+
+	// Overlays provide access to the kustomize overlays of the provider components.
+	Overlays() OverlaysClient
 }
 
 // configClient implements Client.
 type configClient struct {
-	reader Reader
+	reader              Reader
+	imageOverrideValues []string
+	imageOverrides      map[string]imageMeta
+	requireSigned       bool
 }
 
 // ensure configClient implements Client.
@@ -61,7 +72,19 @@ func (c *configClient) Variables() VariablesClient {
 }
 
 func (c *configClient) ImageMeta() ImageMetaClient {
-	return newImageMetaClient(c.reader)
+	client := newImageMetaClient(c.reader)
+	client.overrides = c.imageOverrides
+	return client
+}
+
+func (c *configClient) SignatureVerification() SignatureVerificationClient {
+	client := newSignatureVerificationClient(c.reader)
+	client.requireSigned = c.requireSigned
+	return client
+}
+
+func (c *configClient) Overlays() OverlaysClient {
+	return newOverlaysClient(c.reader)
 }
 
 // Option is a configuration option supplied to New.
@@ -74,6 +97,23 @@ func InjectReader(reader Reader) Option {
 	}
 }
 
+// InjectImageOverrides allows to define image overrides, e.g. from command line flags, taking precedence over the
+// image overrides defined in the clusterctl configuration file.
+// Each override is in the form <component>[/<image>].<repository|tag>=<value>, e.g. all.repository=myorg.io/local-repo.
+func InjectImageOverrides(overrides []string) Option {
+	return func(c *configClient) {
+		c.imageOverrideValues = overrides
+	}
+}
+
+// InjectRequireSigned allows to enforce signature verification, e.g. from command line flags, regardless of
+// the signature verification configuration defined in the clusterctl configuration file.
+func InjectRequireSigned(requireSigned bool) Option {
+	return func(c *configClient) {
+		c.requireSigned = requireSigned
+	}
+}
+
 // New returns a Client for interacting with the clusterctl configuration.
 func New(path string, options ...Option) (Client, error) {
 	return newConfigClient(path, options...)
@@ -93,6 +133,12 @@ func newConfigClient(path string, options ...Option) (*configClient, error) {
 		}
 	}
 
+	imageOverrides, err := parseImageOverrides(client.imageOverrideValues)
+	if err != nil {
+		return nil, err
+	}
+	client.imageOverrides = imageOverrides
+
 	return client, nil
 }
 
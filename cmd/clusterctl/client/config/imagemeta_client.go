@@ -41,6 +41,7 @@ type ImageMetaClient interface {
 // imageMetaClient implements ImageMetaClient.
 type imageMetaClient struct {
 	reader         Reader
+	overrides      map[string]imageMeta
 	imageMetaCache map[string]*imageMeta
 }
 
@@ -86,6 +87,16 @@ func (p *imageMetaClient) getImageMeta(component, imageName string) (*imageMeta,
 		return nil, errors.Wrap(err, "failed to unmarshal image override configurations")
 	}
 
+	// Add the image overrides explicitly defined e.g. via command line flags, which take precedence over the configuration file.
+	for key, override := range p.overrides {
+		if meta == nil {
+			meta = map[string]imageMeta{}
+		}
+		m := meta[key]
+		m.Union(&override)
+		meta[key] = m
+	}
+
 	// If there are not image override configurations, return.
 	if meta == nil {
 		p.imageMetaCache[imageMetaCacheKey(component, imageName)] = nil
@@ -115,6 +126,36 @@ func (p *imageMetaClient) getImageMeta(component, imageName string) (*imageMeta,
 	return m, nil
 }
 
+// parseImageOverrides parses a list of image overrides in the form <component>[/<image>].<repository|tag>=<value>,
+// e.g. all.repository=myorg.io/local-repo or cert-manager.tag=v1.4.0.
+func parseImageOverrides(overrides []string) (map[string]imageMeta, error) {
+	meta := map[string]imageMeta{}
+	for _, o := range overrides {
+		kv := strings.SplitN(o, "=", 2)
+		if len(kv) != 2 || kv[1] == "" {
+			return nil, errors.Errorf("invalid image override %q: expected <component>[/<image>].<repository|tag>=<value>", o)
+		}
+
+		i := strings.LastIndex(kv[0], ".")
+		if i <= 0 {
+			return nil, errors.Errorf("invalid image override %q: expected <component>[/<image>].<repository|tag>=<value>", o)
+		}
+		key, field := kv[0][:i], kv[0][i+1:]
+
+		m := meta[key]
+		switch field {
+		case "repository":
+			m.Repository = kv[1]
+		case "tag":
+			m.Tag = kv[1]
+		default:
+			return nil, errors.Errorf("invalid image override %q: %q is not a valid field, valid values are repository and tag", o, field)
+		}
+		meta[key] = m
+	}
+	return meta, nil
+}
+
 func imageMetaCacheKey(component, imageName string) string {
 	return fmt.Sprintf("%s/%s", component, imageName)
 }
@@ -257,3 +257,105 @@ func Test_imageMetaClient_AlterImage(t *testing.T) {
 		})
 	}
 }
+
+func Test_imageMetaClient_AlterImageWithOverrides(t *testing.T) {
+	tests := []struct {
+		name      string
+		reader    Reader
+		overrides []string
+		image     string
+		want      string
+	}{
+		{
+			name:      "overrides without image config: images should be changed",
+			reader:    test.NewFakeReader(),
+			overrides: []string{"all.repository=foo-repository.io"},
+			image:     "quay.io/jetstack/cert-manager-cainjector:v1.1.0",
+			want:      "foo-repository.io/cert-manager-cainjector:v1.1.0",
+		},
+		{
+			name:      "overrides take precedence over the image config",
+			reader:    test.NewFakeReader().WithImageMeta(CertManagerImageComponent, "bar-repository.io", "bar-tag"),
+			overrides: []string{"cert-manager.tag=foo-tag"},
+			image:     "quay.io/jetstack/cert-manager-cainjector:v1.1.0",
+			want:      "bar-repository.io/cert-manager-cainjector:foo-tag",
+		},
+		{
+			name:      "overrides for a specific image",
+			reader:    test.NewFakeReader().WithImageMeta(allImageConfig, "bar-repository.io", ""),
+			overrides: []string{"cert-manager/cert-manager-cainjector.repository=foo-repository.io", "cert-manager/cert-manager-cainjector.tag=foo-tag"},
+			image:     "quay.io/jetstack/cert-manager-cainjector:v1.1.0",
+			want:      "foo-repository.io/cert-manager-cainjector:foo-tag",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			overrides, err := parseImageOverrides(tt.overrides)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			p := newImageMetaClient(tt.reader)
+			p.overrides = overrides
+
+			got, err := p.AlterImage(CertManagerImageComponent, tt.image)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
+
+func Test_parseImageOverrides(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides []string
+		want      map[string]imageMeta
+		wantErr   bool
+	}{
+		{
+			name:      "no overrides",
+			overrides: nil,
+			want:      map[string]imageMeta{},
+			wantErr:   false,
+		},
+		{
+			name:      "overrides for components and images",
+			overrides: []string{"all.repository=myorg.io/local-repo", "cert-manager.tag=v1.4.0", "cert-manager/cert-manager-cainjector.tag=v1.4.1"},
+			want: map[string]imageMeta{
+				"all":                                  {Repository: "myorg.io/local-repo"},
+				"cert-manager":                         {Tag: "v1.4.0"},
+				"cert-manager/cert-manager-cainjector": {Tag: "v1.4.1"},
+			},
+			wantErr: false,
+		},
+		{
+			name:      "fails if the value is missing",
+			overrides: []string{"all.repository"},
+			wantErr:   true,
+		},
+		{
+			name:      "fails if the field is missing",
+			overrides: []string{"all=myorg.io/local-repo"},
+			wantErr:   true,
+		},
+		{
+			name:      "fails if the field is not valid",
+			overrides: []string{"all.registry=myorg.io"},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got, err := parseImageOverrides(tt.overrides)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// OverlaysConfigKey defines the name of the top level config key for the kustomize overlays of the provider components.
+	OverlaysConfigKey = "overlays"
+)
+
+// OverlaysClient has methods to work with the kustomize overlays of the provider components.
+type OverlaysClient interface {
+	// Get returns the path of the local kustomize overlay directory defined for a provider,
+	// or an empty string if no overlay is defined.
+	Get(provider Provider) (string, error)
+}
+
+// overlaysClient implements OverlaysClient.
+type overlaysClient struct {
+	reader Reader
+}
+
+// ensure overlaysClient implements OverlaysClient.
+var _ OverlaysClient = &overlaysClient{}
+
+func newOverlaysClient(reader Reader) *overlaysClient {
+	return &overlaysClient{
+		reader: reader,
+	}
+}
+
+func (p *overlaysClient) Get(provider Provider) (string, error) {
+	// The overlays are defined by provider label, e.g. infrastructure-aws.
+	overlays := map[string]string{}
+	if err := p.reader.UnmarshalKey(OverlaysConfigKey, &overlays); err != nil {
+		return "", errors.Wrap(err, "failed to unmarshal overlays from the clusterctl configuration file")
+	}
+
+	path, ok := overlays[provider.ManifestLabel()]
+	if !ok || path == "" {
+		return "", nil
+	}
+
+	if strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", errors.Wrap(err, "failed to get the user home directory")
+		}
+		path = filepath.Join(home, path[2:])
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to read the overlay for provider %q", provider.ManifestLabel())
+	}
+	if !info.IsDir() {
+		return "", errors.Errorf("invalid overlay for provider %q: %q is not a directory", provider.ManifestLabel(), path)
+	}
+	return path, nil
+}
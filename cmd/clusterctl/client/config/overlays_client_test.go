@@ -0,0 +1,87 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+)
+
+func Test_overlaysClient_Get(t *testing.T) {
+	g := NewWithT(t)
+
+	dir, err := os.MkdirTemp("", "overlays")
+	g.Expect(err).NotTo(HaveOccurred())
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "kustomization.yaml")
+	g.Expect(os.WriteFile(file, []byte("resources: []"), 0600)).To(Succeed())
+
+	aws := NewProvider("aws", "url", clusterctlv1.InfrastructureProviderType)
+	tests := []struct {
+		name    string
+		reader  Reader
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "no overlays",
+			reader: test.NewFakeReader(),
+			want:   "",
+		},
+		{
+			name:   "overlay for another provider",
+			reader: test.NewFakeReader().WithVar(OverlaysConfigKey, fmt.Sprintf("infrastructure-docker: %s", dir)),
+			want:   "",
+		},
+		{
+			name:   "overlay for the provider",
+			reader: test.NewFakeReader().WithVar(OverlaysConfigKey, fmt.Sprintf("infrastructure-aws: %s", dir)),
+			want:   dir,
+		},
+		{
+			name:    "overlay is not a directory",
+			reader:  test.NewFakeReader().WithVar(OverlaysConfigKey, fmt.Sprintf("infrastructure-aws: %s", file)),
+			wantErr: true,
+		},
+		{
+			name:    "overlay does not exist",
+			reader:  test.NewFakeReader().WithVar(OverlaysConfigKey, fmt.Sprintf("infrastructure-aws: %s", filepath.Join(dir, "missing"))),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got, err := newOverlaysClient(tt.reader).Get(aws)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
@@ -0,0 +1,356 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/pkg/errors"
+	yaml3 "gopkg.in/yaml.v3"
+	"k8s.io/client-go/util/homedir"
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/yaml"
+)
+
+// Profile is a named, shareable set of clusterctl configurations: provider repositories,
+// image overrides and variables.
+// Values are stored as they are defined in the clusterctl configuration file, so references
+// to environment variables, e.g. ${AWS_REGION}, are preserved and resolved only when the values are used.
+type Profile struct {
+	// Name of the profile.
+	Name string `json:"name"`
+
+	// Providers are the provider repository configurations.
+	Providers []ProfileProvider `json:"providers,omitempty"`
+
+	// Images are the image overrides, by component.
+	Images map[string]ProfileImage `json:"images,omitempty"`
+
+	// Variables are the variables defined in the clusterctl configuration file.
+	Variables map[string]string `json:"variables,omitempty"`
+}
+
+// ProfileProvider is a provider repository configuration in a Profile.
+type ProfileProvider struct {
+	Name string                    `json:"name"`
+	URL  string                    `json:"url"`
+	Type clusterctlv1.ProviderType `json:"type"`
+}
+
+// ProfileImage is an image override in a Profile.
+type ProfileImage struct {
+	Repository string `json:"repository,omitempty"`
+	Tag        string `json:"tag,omitempty"`
+}
+
+// ProfileVariablesConfigKey defines the name of the top level key listing the variables imported from profiles;
+// references to environment variables in the values of those variables are resolved when they are read.
+const ProfileVariablesConfigKey = "profile-variables"
+
+// DefaultConfigFile returns the path of the default clusterctl configuration file.
+func DefaultConfigFile() string {
+	return filepath.Join(homedir.HomeDir(), ConfigFolder, fmt.Sprintf("%s.yaml", ConfigName))
+}
+
+// ExportProfile exports the provider repositories and the image overrides defined in a clusterctl configuration file
+// into a profile. Variables could host secrets, e.g. the github-token or provider credentials, so only the variables
+// explicitly listed are exported. Environment variables are never read, so values coming from the environment are
+// not exported and references to them are preserved.
+func ExportProfile(configFile, name string, variables []string) (*Profile, error) {
+	if name == "" {
+		return nil, errors.New("the profile name is required")
+	}
+
+	raw, err := readRawConfig(configFile)
+	if err != nil {
+		return nil, err
+	}
+	if raw == nil {
+		return nil, errors.Errorf("the clusterctl configuration file %q does not exist", configFile)
+	}
+
+	profile := &Profile{Name: name}
+	if err := convertRawValue(raw[ProvidersConfigKey], &profile.Providers); err != nil {
+		return nil, errors.Wrapf(err, "failed to read %q from the clusterctl configuration file", ProvidersConfigKey)
+	}
+	if err := convertRawValue(raw[imagesConfigKey], &profile.Images); err != nil {
+		return nil, errors.Wrapf(err, "failed to read %q from the clusterctl configuration file", imagesConfigKey)
+	}
+
+	// Only scalar values can be exported as variables; structured configurations like e.g. the
+	// cert-manager or the overlays ones are specific to the local environment and they are not exported.
+	for _, key := range variables {
+		if isReservedProfileKey(key) {
+			return nil, errors.Errorf("%q can not be exported as a variable", key)
+		}
+		switch v := raw[key].(type) {
+		case string, bool, float64:
+			if profile.Variables == nil {
+				profile.Variables = map[string]string{}
+			}
+			profile.Variables[key] = fmt.Sprint(v)
+		case nil:
+			return nil, errors.Errorf("the variable %q is not defined in the clusterctl configuration file", key)
+		default:
+			return nil, errors.Errorf("%q is not a variable, it can not be exported", key)
+		}
+	}
+	return profile, nil
+}
+
+// ReadProfile reads a profile from a file.
+func ReadProfile(path string) (*Profile, error) {
+	data, err := os.ReadFile(path) //nolint:gosec
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read the profile %q", path)
+	}
+	profile := &Profile{}
+	if err := yaml.UnmarshalStrict(data, profile); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the profile %q", path)
+	}
+	if profile.Name == "" {
+		return nil, errors.Errorf("invalid profile %q: the profile name is required", path)
+	}
+	return profile, nil
+}
+
+// ImportProfile merges a profile into a clusterctl configuration file, creating the file if it does not exist.
+// Provider repositories, image overrides and variables already defined in the configuration file are preserved,
+// unless overwrite is true. The configuration file is edited in place, so comments are preserved.
+// The names of the imported variables are recorded, so references to environment variables in their values are resolved.
+func ImportProfile(configFile string, profile *Profile, overwrite bool) error {
+	doc, err := readConfigNode(configFile)
+	if err != nil {
+		return err
+	}
+	root := doc.Content[0]
+
+	providers := mappingValue(root, ProvidersConfigKey)
+	for _, p := range profile.Providers {
+		if providers == nil {
+			providers = &yaml3.Node{Kind: yaml3.SequenceNode, Tag: "!!seq"}
+			setMappingValue(root, ProvidersConfigKey, providers)
+		}
+		node, err := toNode(p)
+		if err != nil {
+			return err
+		}
+		i, err := indexOfProviderNode(providers, p)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read %q from the clusterctl configuration file", ProvidersConfigKey)
+		}
+		switch {
+		case i < 0:
+			providers.Content = append(providers.Content, node)
+		case overwrite:
+			providers.Content[i] = node
+		}
+	}
+
+	// Sort the image overrides and the variables, so the configuration file is edited in a stable order.
+	components := make([]string, 0, len(profile.Images))
+	for component := range profile.Images {
+		components = append(components, component)
+	}
+	sort.Strings(components)
+	images := mappingValue(root, imagesConfigKey)
+	for _, component := range components {
+		if images == nil {
+			images = &yaml3.Node{Kind: yaml3.MappingNode, Tag: "!!map"}
+			setMappingValue(root, imagesConfigKey, images)
+		}
+		if mappingValue(images, component) != nil && !overwrite {
+			continue
+		}
+		node, err := toNode(profile.Images[component])
+		if err != nil {
+			return err
+		}
+		setMappingValue(images, component, node)
+	}
+
+	keys := make([]string, 0, len(profile.Variables))
+	for key := range profile.Variables {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var imported []string
+	for _, key := range keys {
+		if isReservedProfileKey(key) {
+			return errors.Errorf("invalid profile %q: %q can not be used as a variable name", profile.Name, key)
+		}
+		if mappingValue(root, key) != nil && !overwrite {
+			continue
+		}
+		setMappingValue(root, key, &yaml3.Node{Kind: yaml3.ScalarNode, Tag: "!!str", Value: profile.Variables[key]})
+		imported = append(imported, key)
+	}
+	if len(imported) > 0 {
+		profileVariables := mappingValue(root, ProfileVariablesConfigKey)
+		if profileVariables == nil {
+			profileVariables = &yaml3.Node{Kind: yaml3.SequenceNode, Tag: "!!seq"}
+			setMappingValue(root, ProfileVariablesConfigKey, profileVariables)
+		}
+		for _, key := range imported {
+			if !sequenceContains(profileVariables, key) {
+				profileVariables.Content = append(profileVariables.Content, &yaml3.Node{Kind: yaml3.ScalarNode, Tag: "!!str", Value: key})
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml3.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc); err != nil {
+		return errors.Wrap(err, "failed to marshal the clusterctl configuration")
+	}
+	if err := encoder.Close(); err != nil {
+		return errors.Wrap(err, "failed to marshal the clusterctl configuration")
+	}
+	if err := os.MkdirAll(filepath.Dir(configFile), os.ModePerm); err != nil {
+		return errors.Wrap(err, "failed to create the clusterctl configuration folder")
+	}
+	if err := os.WriteFile(configFile, buf.Bytes(), 0600); err != nil {
+		return errors.Wrapf(err, "failed to write the clusterctl configuration file %q", configFile)
+	}
+	return nil
+}
+
+// isReservedProfileKey returns true if the key is used by a clusterctl configuration which can not be a profile variable.
+func isReservedProfileKey(key string) bool {
+	switch key {
+	case ProvidersConfigKey, imagesConfigKey, ProfileVariablesConfigKey:
+		return true
+	}
+	return false
+}
+
+// readConfigNode reads a clusterctl configuration file as a YAML document, preserving comments.
+// An empty document is returned if the file does not exist.
+func readConfigNode(configFile string) (*yaml3.Node, error) {
+	data, err := os.ReadFile(configFile) //nolint:gosec
+	if err != nil && !os.IsNotExist(err) {
+		return nil, errors.Wrapf(err, "failed to read the clusterctl configuration file %q", configFile)
+	}
+	doc := &yaml3.Node{}
+	if err := yaml3.Unmarshal(data, doc); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the clusterctl configuration file %q", configFile)
+	}
+	if doc.Kind == 0 {
+		doc.Kind = yaml3.DocumentNode
+	}
+	if len(doc.Content) == 0 {
+		doc.Content = []*yaml3.Node{{Kind: yaml3.MappingNode, Tag: "!!map"}}
+	}
+	if doc.Content[0].Kind != yaml3.MappingNode {
+		return nil, errors.Errorf("failed to parse the clusterctl configuration file %q: not a map", configFile)
+	}
+	return doc, nil
+}
+
+// mappingValue returns the value of a key in a YAML mapping, or nil if the key does not exist.
+func mappingValue(mapping *yaml3.Node, key string) *yaml3.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// setMappingValue sets the value of a key in a YAML mapping, appending the key if it does not exist.
+func setMappingValue(mapping *yaml3.Node, key string, value *yaml3.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content[i+1] = value
+			return
+		}
+	}
+	mapping.Content = append(mapping.Content, &yaml3.Node{Kind: yaml3.ScalarNode, Tag: "!!str", Value: key}, value)
+}
+
+func sequenceContains(sequence *yaml3.Node, value string) bool {
+	for _, n := range sequence.Content {
+		if n.Value == value {
+			return true
+		}
+	}
+	return false
+}
+
+// toNode converts a value into a YAML node, using the JSON field names of the value.
+func toNode(value interface{}) (*yaml3.Node, error) {
+	data, err := yaml.Marshal(value)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal the profile")
+	}
+	doc := &yaml3.Node{}
+	if err := yaml3.Unmarshal(data, doc); err != nil {
+		return nil, errors.Wrap(err, "failed to marshal the profile")
+	}
+	return doc.Content[0], nil
+}
+
+func indexOfProviderNode(providers *yaml3.Node, provider ProfileProvider) (int, error) {
+	for i, n := range providers.Content {
+		var raw interface{}
+		if err := n.Decode(&raw); err != nil {
+			return -1, err
+		}
+		p := ProfileProvider{}
+		if err := convertRawValue(raw, &p); err != nil {
+			return -1, err
+		}
+		if p.Name == provider.Name && p.Type == provider.Type {
+			return i, nil
+		}
+	}
+	return -1, nil
+}
+
+// readRawConfig reads a clusterctl configuration file without resolving any value.
+// It returns nil if the file does not exist.
+func readRawConfig(configFile string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(configFile) //nolint:gosec
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "failed to read the clusterctl configuration file %q", configFile)
+	}
+	raw := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the clusterctl configuration file %q", configFile)
+	}
+	return raw, nil
+}
+
+// convertRawValue converts a value read from the raw clusterctl configuration into a typed object.
+func convertRawValue(value interface{}, out interface{}) error {
+	if value == nil {
+		return nil
+	}
+	data, err := yaml.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return yaml.Unmarshal(data, out)
+}
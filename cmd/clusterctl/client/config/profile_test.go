@@ -0,0 +1,223 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+)
+
+func TestExportProfile(t *testing.T) {
+	g := NewWithT(t)
+
+	dir, err := os.MkdirTemp("", "clusterctl")
+	g.Expect(err).NotTo(HaveOccurred())
+	defer os.RemoveAll(dir)
+
+	configFile := filepath.Join(dir, "clusterctl.yaml")
+	g.Expect(os.WriteFile(configFile, []byte(`
+providers:
+  - name: my-infra
+    url: https://example.com/my-infra/latest/infrastructure-components.yaml
+    type: InfrastructureProvider
+images:
+  all:
+    repository: myorg.io/local-repo
+AWS_REGION: ${AWS_REGION}
+EXP_CLUSTER_RESOURCE_SET: true
+github-token: secret
+cert-manager:
+  url: /tmp/cert-manager.yaml
+`), 0600)).To(Succeed())
+
+	_ = os.Setenv("AWS_REGION", "us-east-1")
+	defer os.Unsetenv("AWS_REGION")
+
+	profile, err := ExportProfile(configFile, "team", []string{"AWS_REGION", "EXP_CLUSTER_RESOURCE_SET"})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(profile).To(Equal(&Profile{
+		Name: "team",
+		Providers: []ProfileProvider{
+			{
+				Name: "my-infra",
+				URL:  "https://example.com/my-infra/latest/infrastructure-components.yaml",
+				Type: clusterctlv1.InfrastructureProviderType,
+			},
+		},
+		Images: map[string]ProfileImage{
+			"all": {Repository: "myorg.io/local-repo"},
+		},
+		// Only the requested variables are exported, e.g. the github-token is not.
+		Variables: map[string]string{
+			// The reference to the environment variable is preserved.
+			"AWS_REGION":               "${AWS_REGION}",
+			"EXP_CLUSTER_RESOURCE_SET": "true",
+		},
+	}))
+
+	profile, err = ExportProfile(configFile, "team", nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(profile.Variables).To(BeEmpty())
+
+	_, err = ExportProfile(configFile, "team", []string{"DOES_NOT_EXIST"})
+	g.Expect(err).To(HaveOccurred())
+
+	_, err = ExportProfile(configFile, "team", []string{"cert-manager"})
+	g.Expect(err).To(HaveOccurred())
+
+	_, err = ExportProfile(filepath.Join(dir, "does-not-exist.yaml"), "team", nil)
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestImportProfile(t *testing.T) {
+	profile := &Profile{
+		Name: "team",
+		Providers: []ProfileProvider{
+			{Name: "my-infra", URL: "https://example.com/v2/infrastructure-components.yaml", Type: clusterctlv1.InfrastructureProviderType},
+			{Name: "my-bootstrap", URL: "https://example.com/bootstrap-components.yaml", Type: clusterctlv1.BootstrapProviderType},
+		},
+		Images: map[string]ProfileImage{
+			"all": {Repository: "myorg.io/local-repo"},
+		},
+		Variables: map[string]string{
+			"AWS_REGION": "${AWS_REGION}",
+			"FOO":        "profile",
+		},
+	}
+	existingConfig := `
+# Local provider repositories.
+providers:
+  - name: my-infra
+    url: https://example.com/v1/infrastructure-components.yaml
+    type: InfrastructureProvider
+FOO: local
+`
+
+	tests := []struct {
+		name                 string
+		existingConfig       string
+		overwrite            bool
+		want                 *Profile
+		wantProfileVariables []string
+	}{
+		{
+			name: "Creates the configuration file if it does not exist",
+			want: &Profile{
+				Name:      "team",
+				Providers: profile.Providers,
+				Images:    profile.Images,
+				Variables: profile.Variables,
+			},
+			wantProfileVariables: []string{"AWS_REGION", "FOO"},
+		},
+		{
+			name:           "Preserves the existing configurations",
+			existingConfig: existingConfig,
+			want: &Profile{
+				Name: "team",
+				Providers: []ProfileProvider{
+					{Name: "my-infra", URL: "https://example.com/v1/infrastructure-components.yaml", Type: clusterctlv1.InfrastructureProviderType},
+					{Name: "my-bootstrap", URL: "https://example.com/bootstrap-components.yaml", Type: clusterctlv1.BootstrapProviderType},
+				},
+				Images: profile.Images,
+				Variables: map[string]string{
+					"AWS_REGION": "${AWS_REGION}",
+					"FOO":        "local",
+				},
+			},
+			wantProfileVariables: []string{"AWS_REGION"},
+		},
+		{
+			name:           "Overwrites the existing configurations",
+			existingConfig: existingConfig,
+			overwrite:      true,
+			want: &Profile{
+				Name:      "team",
+				Providers: profile.Providers,
+				Images:    profile.Images,
+				Variables: profile.Variables,
+			},
+			wantProfileVariables: []string{"AWS_REGION", "FOO"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			dir, err := os.MkdirTemp("", "clusterctl")
+			g.Expect(err).NotTo(HaveOccurred())
+			defer os.RemoveAll(dir)
+
+			configFile := filepath.Join(dir, ".cluster-api", "clusterctl.yaml")
+			if tt.existingConfig != "" {
+				g.Expect(os.MkdirAll(filepath.Dir(configFile), 0700)).To(Succeed())
+				g.Expect(os.WriteFile(configFile, []byte(tt.existingConfig), 0600)).To(Succeed())
+			}
+
+			g.Expect(ImportProfile(configFile, profile, tt.overwrite)).To(Succeed())
+
+			got, err := ExportProfile(configFile, "team", []string{"AWS_REGION", "FOO"})
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+
+			data, err := os.ReadFile(configFile)
+			g.Expect(err).NotTo(HaveOccurred())
+			if tt.existingConfig != "" {
+				g.Expect(string(data)).To(ContainSubstring("# Local provider repositories."))
+			}
+
+			var profileVariables []string
+			g.Expect(convertRawValue(mustReadRawConfig(g, configFile)[ProfileVariablesConfigKey], &profileVariables)).To(Succeed())
+			g.Expect(profileVariables).To(Equal(tt.wantProfileVariables))
+		})
+	}
+}
+
+func mustReadRawConfig(g *WithT, configFile string) map[string]interface{} {
+	raw, err := readRawConfig(configFile)
+	g.Expect(err).NotTo(HaveOccurred())
+	return raw
+}
+
+func TestReadProfile(t *testing.T) {
+	g := NewWithT(t)
+
+	dir, err := os.MkdirTemp("", "clusterctl")
+	g.Expect(err).NotTo(HaveOccurred())
+	defer os.RemoveAll(dir)
+
+	valid := filepath.Join(dir, "valid.yaml")
+	g.Expect(os.WriteFile(valid, []byte("name: team\nvariables:\n  FOO: bar\n"), 0600)).To(Succeed())
+	profile, err := ReadProfile(valid)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(profile).To(Equal(&Profile{Name: "team", Variables: map[string]string{"FOO": "bar"}}))
+
+	missingName := filepath.Join(dir, "missing-name.yaml")
+	g.Expect(os.WriteFile(missingName, []byte("variables:\n  FOO: bar\n"), 0600)).To(Succeed())
+	_, err = ReadProfile(missingName)
+	g.Expect(err).To(HaveOccurred())
+
+	unknownField := filepath.Join(dir, "unknown-field.yaml")
+	g.Expect(os.WriteFile(unknownField, []byte("name: team\nfoo: bar\n"), 0600)).To(Succeed())
+	_, err = ReadProfile(unknownField)
+	g.Expect(err).To(HaveOccurred())
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+// SignatureVerification defines the configuration for verifying the signatures of provider components and metadata,
+// as generated by `cosign sign-blob`.
+type SignatureVerification interface {
+	// Required returns true if provider components and metadata without a valid signature must be rejected.
+	Required() bool
+
+	// PublicKeys returns the PEM encoded public keys trusted for key-based signatures.
+	PublicKeys() [][]byte
+}
+
+// signatureVerification implements SignatureVerification.
+type signatureVerification struct {
+	required   bool
+	publicKeys [][]byte
+}
+
+// ensure signatureVerification implements SignatureVerification.
+var _ SignatureVerification = &signatureVerification{}
+
+func (p *signatureVerification) Required() bool {
+	return p.required
+}
+
+func (p *signatureVerification) PublicKeys() [][]byte {
+	return p.publicKeys
+}
+
+// NewSignatureVerification creates a new SignatureVerification with the given configuration.
+func NewSignatureVerification(required bool, publicKeys [][]byte) SignatureVerification {
+	return &signatureVerification{
+		required:   required,
+		publicKeys: publicKeys,
+	}
+}
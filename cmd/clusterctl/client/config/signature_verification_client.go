@@ -0,0 +1,93 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"io/ioutil"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// SignatureVerificationConfigKey defines the name of the top level config key for signature verification configuration.
+	SignatureVerificationConfigKey = "signature-verification"
+
+	pemPrefix = "-----BEGIN"
+)
+
+// SignatureVerificationClient has methods to work with signature verification configurations.
+type SignatureVerificationClient interface {
+	// Get returns the signature verification configuration.
+	Get() (SignatureVerification, error)
+}
+
+// signatureVerificationClient implements SignatureVerificationClient.
+type signatureVerificationClient struct {
+	reader        Reader
+	requireSigned bool
+}
+
+// ensure signatureVerificationClient implements SignatureVerificationClient.
+var _ SignatureVerificationClient = &signatureVerificationClient{}
+
+func newSignatureVerificationClient(reader Reader) *signatureVerificationClient {
+	return &signatureVerificationClient{
+		reader: reader,
+	}
+}
+
+// configSignatureVerification mirrors config.SignatureVerification interface and allows serialization of the corresponding info.
+// Public keys can be defined either inline, in PEM format, or as a path to a PEM file.
+type configSignatureVerification struct {
+	Required   bool     `json:"required,omitempty"`
+	PublicKeys []string `json:"publicKeys,omitempty"`
+
+	// Keyless is not supported, it is read only to reject configurations relying on it.
+	Keyless interface{} `json:"keyless,omitempty"`
+}
+
+func (p *signatureVerificationClient) Get() (SignatureVerification, error) {
+	userSignatureVerification := &configSignatureVerification{}
+	if err := p.reader.UnmarshalKey(SignatureVerificationConfigKey, &userSignatureVerification); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal signature verification from the clusterctl configuration file")
+	}
+
+	// Keyless signatures can't be trusted without verifying their inclusion in the Rekor transparency log.
+	if userSignatureVerification.Keyless != nil {
+		return nil, errors.New("invalid signature verification configuration: keyless signatures are not supported, please use publicKeys")
+	}
+
+	publicKeys := [][]byte{}
+	for _, k := range userSignatureVerification.PublicKeys {
+		key, err := readPEM(k)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read public key for signature verification")
+		}
+		publicKeys = append(publicKeys, key)
+	}
+
+	return NewSignatureVerification(p.requireSigned || userSignatureVerification.Required, publicKeys), nil
+}
+
+// readPEM returns a PEM defined inline or read from a file.
+func readPEM(value string) ([]byte, error) {
+	if strings.HasPrefix(strings.TrimSpace(value), pemPrefix) {
+		return []byte(value), nil
+	}
+	return ioutil.ReadFile(value)
+}
@@ -16,16 +16,26 @@ limitations under the License.
 
 package config
 
+import (
+	"os"
+	"regexp"
+)
+
 const (
 	// GitHubTokenVariable defines a variable hosting the GitHub access token.
 	GitHubTokenVariable = "github-token"
 )
 
+// envReferenceRegex matches the references to environment variables in the ${VAR} form.
+var envReferenceRegex = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
 // VariablesClient has methods to work with environment variables and with variables defined in the clusterctl configuration file.
 type VariablesClient interface {
 	// Get returns a variable value. If the variable is not defined an error is returned.
 	// In case the same variable is defined both within the environment variables and clusterctl configuration file,
 	// the environment variables value takes precedence.
+	// For the variables imported from a profile, references to environment variables in the ${VAR} form
+	// are resolved; references to environment variables which are not set are left as they are.
 	Get(key string) (string, error)
 
 	// Set allows to set an explicit override for a config value.
@@ -48,9 +58,45 @@ func newVariablesClient(reader Reader) *variablesClient {
 }
 
 func (p *variablesClient) Get(key string) (string, error) {
-	return p.reader.Get(key)
+	value, err := p.reader.Get(key)
+	if err != nil {
+		return "", err
+	}
+	if !p.isProfileVariable(key) {
+		return value, nil
+	}
+	return expandEnvReferences(value), nil
+}
+
+// isProfileVariable returns true if the variable value is read from the clusterctl configuration file
+// and the variable has been imported from a profile.
+func (p *variablesClient) isProfileVariable(key string) bool {
+	if _, ok := os.LookupEnv(key); ok {
+		return false
+	}
+	var profileVariables []string
+	if err := p.reader.UnmarshalKey(ProfileVariablesConfigKey, &profileVariables); err != nil {
+		return false
+	}
+	for _, v := range profileVariables {
+		if v == key {
+			return true
+		}
+	}
+	return false
 }
 
 func (p *variablesClient) Set(key, value string) {
 	p.reader.Set(key, value)
 }
+
+// expandEnvReferences resolves the references to environment variables in the ${VAR} form, e.g. the ones
+// preserved when importing a profile.
+func expandEnvReferences(value string) string {
+	return envReferenceRegex.ReplaceAllStringFunc(value, func(ref string) string {
+		if v, ok := os.LookupEnv(envReferenceRegex.FindStringSubmatch(ref)[1]); ok {
+			return v
+		}
+		return ref
+	})
+}
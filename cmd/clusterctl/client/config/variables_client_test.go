@@ -17,6 +17,7 @@ limitations under the License.
 package config
 
 import (
+	"os"
 	"testing"
 
 	. "github.com/onsi/gomega"
@@ -31,7 +32,15 @@ var _ Reader = &test.FakeReader{}
 var _ VariablesClient = &test.FakeVariableClient{}
 
 func Test_variables_Get(t *testing.T) {
-	reader := test.NewFakeReader().WithVar("foo", "bar")
+	_ = os.Setenv("VARIABLES_CLIENT_TEST_REGION", "us-east-1")
+	defer os.Unsetenv("VARIABLES_CLIENT_TEST_REGION")
+
+	reader := test.NewFakeReader().
+		WithVar("foo", "bar").
+		WithVar("region", "${VARIABLES_CLIENT_TEST_REGION}").
+		WithVar("zone", "${VARIABLES_CLIENT_TEST_REGION}a-${VARIABLES_CLIENT_TEST_UNSET}").
+		WithVar("template", "${VARIABLES_CLIENT_TEST_REGION}").
+		WithVar(ProfileVariablesConfigKey, "[region, zone]")
 
 	type args struct {
 		key string
@@ -50,6 +59,30 @@ func Test_variables_Get(t *testing.T) {
 			want:    "bar",
 			wantErr: false,
 		},
+		{
+			name: "Resolves references to environment variables in variables imported from a profile",
+			args: args{
+				key: "region",
+			},
+			want:    "us-east-1",
+			wantErr: false,
+		},
+		{
+			name: "Preserves references to environment variables which are not set",
+			args: args{
+				key: "zone",
+			},
+			want:    "us-east-1a-${VARIABLES_CLIENT_TEST_UNSET}",
+			wantErr: false,
+		},
+		{
+			name: "Does not resolve references in variables not imported from a profile",
+			args: args{
+				key: "template",
+			},
+			want:    "${VARIABLES_CLIENT_TEST_REGION}",
+			wantErr: false,
+		},
 		{
 			name: "Returns error if the variable does not exist",
 			args: args{
@@ -27,8 +27,10 @@ import (
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/utils/pointer"
 
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
@@ -990,3 +992,95 @@ type errReader struct{}
 func (e *errReader) Read(p []byte) (n int, err error) {
 	return 0, errors.New("read error")
 }
+
+func Test_clusterctlClient_GetClusterTemplate_TopologyFlavor(t *testing.T) {
+	class := &clusterv1.ClusterClass{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: clusterv1.GroupVersion.String(),
+			Kind:       "ClusterClass",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns1",
+			Name:      "class1",
+		},
+		Spec: clusterv1.ClusterClassSpec{
+			Workers: clusterv1.WorkersClass{
+				MachineDeployments: []clusterv1.MachineDeploymentClass{
+					{Class: "default-worker"},
+				},
+			},
+		},
+	}
+
+	newClient := func(mdClass string) Client {
+		config1 := newFakeConfig().
+			WithProvider(infraProviderConfig).
+			WithVar("CLUSTER_CLASS", "class1").
+			WithVar("WORKER_MACHINE_DEPLOYMENT_CLASS", mdClass)
+
+		// The repository does not publish a template for the topology flavor, so the built-in one is used.
+		repository1 := newFakeRepository(infraProviderConfig, config1).
+			WithPaths("root", "components").
+			WithDefaultVersion("v3.0.0").
+			WithFile("v3.0.0", "cluster-template.yaml", templateYAML("ns3", "${ CLUSTER_NAME }"))
+
+		cluster1 := newFakeCluster(cluster.Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"}, config1).
+			WithProviderInventory(infraProviderConfig.Name(), infraProviderConfig.Type(), "v3.0.0", "foo").
+			WithObjs(class).
+			WithObjs(test.FakeCAPISetupObjects()...)
+
+		return newFakeClient(config1).
+			WithCluster(cluster1).
+			WithRepository(repository1)
+	}
+
+	options := GetClusterTemplateOptions{
+		Kubeconfig: Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+		ProviderRepositorySource: &ProviderRepositorySourceOptions{
+			InfrastructureProvider: "infra:v3.0.0",
+			Flavor:                 TopologyFlavor,
+		},
+		ClusterName:              "test",
+		TargetNamespace:          "ns1",
+		KubernetesVersion:        "v1.21.2",
+		ControlPlaneMachineCount: pointer.Int64Ptr(3),
+		WorkerMachineCount:       pointer.Int64Ptr(2),
+	}
+
+	t.Run("generates a Cluster with a managed topology", func(t *testing.T) {
+		g := NewWithT(t)
+
+		got, err := newClient("default-worker").GetClusterTemplate(options)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(got.Objs()).To(HaveLen(1))
+
+		cluster := &clusterv1.Cluster{}
+		g.Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(got.Objs()[0].Object, cluster)).To(Succeed())
+		g.Expect(cluster.Namespace).To(Equal("ns1"))
+		g.Expect(cluster.Name).To(Equal("test"))
+		g.Expect(cluster.Spec.Topology).NotTo(BeNil())
+		g.Expect(cluster.Spec.Topology.Class).To(Equal("class1"))
+		g.Expect(cluster.Spec.Topology.Version).To(Equal("v1.21.2"))
+		g.Expect(cluster.Spec.Topology.ControlPlane.Replicas).To(Equal(3))
+		g.Expect(cluster.Spec.Topology.Workers.MachineDeployments).To(HaveLen(1))
+		g.Expect(cluster.Spec.Topology.Workers.MachineDeployments[0].Class).To(Equal("default-worker"))
+		g.Expect(*cluster.Spec.Topology.Workers.MachineDeployments[0].Replicas).To(Equal(2))
+	})
+
+	t.Run("lists the variables related to the ClusterClass", func(t *testing.T) {
+		g := NewWithT(t)
+
+		listOptions := options
+		listOptions.ListVariablesOnly = true
+		got, err := newClient("default-worker").GetClusterTemplate(listOptions)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(got.Variables()).To(ContainElements("CLUSTER_CLASS", "WORKER_MACHINE_DEPLOYMENT_CLASS"))
+	})
+
+	t.Run("fails if the MachineDeployment class is not defined in the ClusterClass", func(t *testing.T) {
+		g := NewWithT(t)
+
+		_, err := newClient("not-defined").GetClusterTemplate(options)
+		g.Expect(err).To(HaveOccurred())
+	})
+}
@@ -0,0 +1,283 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	logf "sigs.k8s.io/cluster-api/cmd/clusterctl/log"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// deleteClusterPollInterval is the interval used for checking the progress of the Cluster deletion.
+var deleteClusterPollInterval = 10 * time.Second
+
+// DeleteClusterOptions carries all the options supported by DeleteCluster.
+type DeleteClusterOptions struct {
+	// Kubeconfig defines the kubeconfig to use for accessing the management cluster. If empty,
+	// default rules for kubeconfig discovery will be used.
+	Kubeconfig Kubeconfig
+
+	// Namespace where the workload cluster exists. If empty, the current namespace will be used.
+	Namespace string
+
+	// ClusterName is the name of the workload cluster to delete.
+	ClusterName string
+
+	// Timeout defines how long to wait for the cascading deletion of the Cluster to complete.
+	// If zero, DeleteCluster returns as soon as the Cluster is marked for deletion.
+	Timeout time.Duration
+
+	// ForceRemoveFinalizers removes the finalizers from the Cluster and from the objects belonging to it
+	// still existing after Timeout.
+	// Important! This orphans all the infrastructure the providers were not able to clean up, and there
+	// might be ongoing costs incurred as a result of this.
+	ForceRemoveFinalizers bool
+}
+
+func (c *clusterctlClient) DeleteCluster(options DeleteClusterOptions) error {
+	log := logf.Log
+
+	// gets access to the management cluster
+	clusterClient, err := c.clusterClientFactory(ClusterClientFactoryInput{Kubeconfig: options.Kubeconfig})
+	if err != nil {
+		return err
+	}
+
+	// Ensure this command only runs against management clusters with the current Cluster API contract.
+	if err := clusterClient.ProviderInventory().CheckCAPIContract(); err != nil {
+		return err
+	}
+
+	if options.Namespace == "" {
+		currentNamespace, err := clusterClient.Proxy().CurrentNamespace()
+		if err != nil {
+			return err
+		}
+		if currentNamespace == "" {
+			return errors.New("failed to identify the current namespace. Please specify the namespace where the workload cluster exists")
+		}
+		options.Namespace = currentNamespace
+	}
+
+	if options.ForceRemoveFinalizers && options.Timeout <= 0 {
+		return errors.New("a timeout must be set when forcing the removal of finalizers")
+	}
+
+	c2, err := clusterClient.Proxy().NewClient()
+	if err != nil {
+		return err
+	}
+
+	cluster := &clusterv1.Cluster{}
+	clusterKey := client.ObjectKey{Namespace: options.Namespace, Name: options.ClusterName}
+	if err := c2.Get(context.TODO(), clusterKey, cluster); err != nil {
+		return errors.Wrapf(err, "failed to get Cluster %s/%s", options.Namespace, options.ClusterName)
+	}
+
+	if cluster.DeletionTimestamp.IsZero() {
+		log.Info("Deleting Cluster", "Cluster", options.ClusterName, "Namespace", options.Namespace)
+		if err := c2.Delete(context.TODO(), cluster); err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to delete Cluster %s/%s", options.Namespace, options.ClusterName)
+		}
+	} else {
+		log.Info("Cluster is already being deleted", "Cluster", options.ClusterName, "Namespace", options.Namespace)
+	}
+
+	if options.Timeout <= 0 {
+		return nil
+	}
+
+	// Waits for the cascading deletion to complete, reporting progress every time the set of remaining objects changes.
+	lastProgress := ""
+	waitErr := wait.PollImmediate(deleteClusterPollInterval, options.Timeout, func() (bool, error) {
+		if err := c2.Get(context.TODO(), clusterKey, &clusterv1.Cluster{}); err != nil {
+			if apierrors.IsNotFound(err) {
+				return true, nil
+			}
+			return false, nil
+		}
+		progress, err := deleteClusterProgress(c2, cluster)
+		if err != nil {
+			return false, nil
+		}
+		if progress != lastProgress {
+			log.Info("Waiting for the Cluster to be deleted", "Remaining", progress)
+			lastProgress = progress
+		}
+		return false, nil
+	})
+	if waitErr == nil {
+		log.Info("Cluster deleted", "Cluster", options.ClusterName, "Namespace", options.Namespace)
+		return nil
+	}
+	if waitErr != wait.ErrWaitTimeout {
+		return waitErr
+	}
+
+	if !options.ForceRemoveFinalizers {
+		return errors.Errorf("timed out waiting for Cluster %s/%s to be deleted; remaining objects: %s", options.Namespace, options.ClusterName, lastProgress)
+	}
+
+	log.Info("Timed out waiting for the Cluster to be deleted, removing finalizers", "Cluster", options.ClusterName, "Namespace", options.Namespace)
+	return forceRemoveClusterFinalizers(c2, cluster)
+}
+
+// deleteClusterProgress returns a summary of the objects belonging to a Cluster which are still waiting to be deleted.
+func deleteClusterProgress(c client.Client, cluster *clusterv1.Cluster) (string, error) {
+	selector := []client.ListOption{
+		client.InNamespace(cluster.Namespace),
+		client.MatchingLabels{clusterv1.ClusterLabelName: cluster.Name},
+	}
+
+	machineDeployments := &clusterv1.MachineDeploymentList{}
+	if err := c.List(context.TODO(), machineDeployments, selector...); err != nil {
+		return "", err
+	}
+	machineSets := &clusterv1.MachineSetList{}
+	if err := c.List(context.TODO(), machineSets, selector...); err != nil {
+		return "", err
+	}
+	machines := &clusterv1.MachineList{}
+	if err := c.List(context.TODO(), machines, selector...); err != nil {
+		return "", err
+	}
+
+	progress := fmt.Sprintf("MachineDeployments=%d, MachineSets=%d, Machines=%d", len(machineDeployments.Items), len(machineSets.Items), len(machines.Items))
+	for _, ref := range []*corev1.ObjectReference{cluster.Spec.ControlPlaneRef, cluster.Spec.InfrastructureRef} {
+		obj, err := getClusterRef(c, ref, cluster.Namespace)
+		if err != nil {
+			return "", err
+		}
+		if obj != nil {
+			progress += fmt.Sprintf(", %s=%s", obj.GetKind(), obj.GetName())
+		}
+	}
+	return progress, nil
+}
+
+// forceRemoveClusterFinalizers removes the finalizers from the Cluster and from the objects belonging to it,
+// so the API server can complete their deletion.
+func forceRemoveClusterFinalizers(c client.Client, cluster *clusterv1.Cluster) error {
+	log := logf.Log
+
+	selector := []client.ListOption{
+		client.InNamespace(cluster.Namespace),
+		client.MatchingLabels{clusterv1.ClusterLabelName: cluster.Name},
+	}
+
+	objs := []client.Object{}
+
+	machineDeployments := &clusterv1.MachineDeploymentList{}
+	if err := c.List(context.TODO(), machineDeployments, selector...); err != nil {
+		return errors.Wrap(err, "failed to list MachineDeployments")
+	}
+	for i := range machineDeployments.Items {
+		objs = append(objs, &machineDeployments.Items[i])
+	}
+
+	machineSets := &clusterv1.MachineSetList{}
+	if err := c.List(context.TODO(), machineSets, selector...); err != nil {
+		return errors.Wrap(err, "failed to list MachineSets")
+	}
+	for i := range machineSets.Items {
+		objs = append(objs, &machineSets.Items[i])
+	}
+
+	machines := &clusterv1.MachineList{}
+	if err := c.List(context.TODO(), machines, selector...); err != nil {
+		return errors.Wrap(err, "failed to list Machines")
+	}
+	for i := range machines.Items {
+		m := &machines.Items[i]
+		refs := []*corev1.ObjectReference{&m.Spec.InfrastructureRef, m.Spec.Bootstrap.ConfigRef}
+		for _, ref := range refs {
+			obj, err := getClusterRef(c, ref, m.Namespace)
+			if err != nil {
+				return err
+			}
+			if obj != nil {
+				objs = append(objs, obj)
+			}
+		}
+		objs = append(objs, m)
+	}
+
+	for _, ref := range []*corev1.ObjectReference{cluster.Spec.ControlPlaneRef, cluster.Spec.InfrastructureRef} {
+		obj, err := getClusterRef(c, ref, cluster.Namespace)
+		if err != nil {
+			return err
+		}
+		if obj != nil {
+			objs = append(objs, obj)
+		}
+	}
+
+	// The Cluster goes last, so it is not removed before the objects belonging to it.
+	current := &clusterv1.Cluster{}
+	if err := c.Get(context.TODO(), client.ObjectKeyFromObject(cluster), current); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to get Cluster %s/%s", cluster.Namespace, cluster.Name)
+		}
+	} else {
+		objs = append(objs, current)
+	}
+
+	var errList []error
+	for _, obj := range objs {
+		if len(obj.GetFinalizers()) == 0 {
+			continue
+		}
+		log.V(1).Info("Removing finalizers", "Object", fmt.Sprintf("%T", obj), "Name", obj.GetName(), "Finalizers", obj.GetFinalizers())
+		patchBase := client.MergeFrom(obj.DeepCopyObject().(client.Object))
+		obj.SetFinalizers(nil)
+		if err := c.Patch(context.TODO(), obj, patchBase); err != nil && !apierrors.IsNotFound(err) {
+			errList = append(errList, errors.Wrapf(err, "failed to remove finalizers from %s/%s", obj.GetNamespace(), obj.GetName()))
+		}
+	}
+	return kerrors.NewAggregate(errList)
+}
+
+// getClusterRef returns the object referenced by ref, or nil if ref is nil or the object does not exist.
+func getClusterRef(c client.Client, ref *corev1.ObjectReference, namespace string) (*unstructured.Unstructured, error) {
+	if ref == nil || ref.Name == "" {
+		return nil, nil
+	}
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(ref.APIVersion)
+	obj.SetKind(ref.Kind)
+	if ref.Namespace != "" {
+		namespace = ref.Namespace
+	}
+	if err := c.Get(context.TODO(), client.ObjectKey{Namespace: namespace, Name: ref.Name}, obj); err != nil {
+		if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "failed to get %s %s/%s", ref.Kind, namespace, ref.Name)
+	}
+	return obj, nil
+}
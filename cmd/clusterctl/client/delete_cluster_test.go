@@ -0,0 +1,134 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func Test_clusterctlClient_DeleteCluster(t *testing.T) {
+	deleteClusterPollInterval = 10 * time.Millisecond
+
+	tests := []struct {
+		name    string
+		objs    []client.Object
+		options DeleteClusterOptions
+		wantErr bool
+	}{
+		{
+			name: "returns error if the Cluster does not exist",
+			options: DeleteClusterOptions{
+				Kubeconfig:  Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+				ClusterName: "foo",
+			},
+			wantErr: true,
+		},
+		{
+			name: "returns error if forcing the removal of finalizers without a timeout",
+			objs: []client.Object{
+				&clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "foo"}},
+			},
+			options: DeleteClusterOptions{
+				Kubeconfig:            Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+				ClusterName:           "foo",
+				ForceRemoveFinalizers: true,
+			},
+			wantErr: true,
+		},
+		{
+			name: "deletes the Cluster and waits for it to go away",
+			objs: []client.Object{
+				&clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "foo"}},
+			},
+			options: DeleteClusterOptions{
+				Kubeconfig:  Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+				ClusterName: "foo",
+				Timeout:     time.Second,
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			config1 := newFakeConfig()
+			cluster1 := newFakeCluster(cluster.Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"}, config1).WithObjs(tt.objs...)
+			cluster1.fakeProxy.WithFakeCAPISetup()
+			c := newFakeClient(config1).WithCluster(cluster1)
+
+			err := c.DeleteCluster(tt.options)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+
+			cs, err := cluster1.Proxy().NewClient()
+			g.Expect(err).NotTo(HaveOccurred())
+			err = cs.Get(context.TODO(), client.ObjectKey{Namespace: "default", Name: tt.options.ClusterName}, &clusterv1.Cluster{})
+			g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+	}
+}
+
+func Test_forceRemoveClusterFinalizers(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster1 := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "foo"},
+	}
+	machine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:  "default",
+			Name:       "foo-machine",
+			Labels:     map[string]string{clusterv1.ClusterLabelName: "foo"},
+			Finalizers: []string{clusterv1.MachineFinalizer},
+		},
+	}
+	otherMachine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:  "default",
+			Name:       "bar-machine",
+			Labels:     map[string]string{clusterv1.ClusterLabelName: "bar"},
+			Finalizers: []string{clusterv1.MachineFinalizer},
+		},
+	}
+
+	fakeCluster := newFakeCluster(cluster.Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"}, newFakeConfig()).WithObjs(cluster1, machine, otherMachine)
+	cs, err := fakeCluster.Proxy().NewClient()
+	g.Expect(err).NotTo(HaveOccurred())
+
+	g.Expect(forceRemoveClusterFinalizers(cs, cluster1)).To(Succeed())
+
+	got := &clusterv1.Machine{}
+	g.Expect(cs.Get(context.TODO(), client.ObjectKeyFromObject(machine), got)).To(Succeed())
+	g.Expect(got.Finalizers).To(BeEmpty())
+
+	// Machines belonging to other Clusters are left untouched.
+	g.Expect(cs.Get(context.TODO(), client.ObjectKeyFromObject(otherMachine), got)).To(Succeed())
+	g.Expect(got.Finalizers).To(ConsistOf(clusterv1.MachineFinalizer))
+}
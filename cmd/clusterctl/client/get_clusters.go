@@ -0,0 +1,195 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"sort"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/controllers/external"
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// GetClustersOptions carries the options supported by GetClusters.
+type GetClustersOptions struct {
+	// Kubeconfig defines the kubeconfig to use for accessing the management cluster. If empty,
+	// default rules for kubeconfig discovery will be used.
+	Kubeconfig Kubeconfig
+
+	// Namespace where the workload clusters are located. If unspecified, the current namespace will be used.
+	Namespace string
+
+	// AllNamespaces lists the workload clusters in all the namespaces; Namespace is ignored.
+	AllNamespaces bool
+
+	// LabelSelector filters the workload clusters by label, e.g. env=prod.
+	LabelSelector string
+}
+
+// ClusterSummary summarizes the status of a workload cluster.
+type ClusterSummary struct {
+	// Namespace and Name identify the Cluster.
+	Namespace string
+	Name      string
+
+	// Phase is the phase of the Cluster, e.g. Provisioned.
+	Phase string
+
+	// ControlPlaneReady is true when the Cluster's ControlPlaneReady condition is true.
+	ControlPlaneReady bool
+
+	// ReadyWorkers and Workers are respectively the number of the ready and of all the worker machines of the
+	// Cluster, including MachinePool replicas.
+	ReadyWorkers int32
+	Workers      int32
+
+	// Version is the Kubernetes version of the Cluster, as defined by the topology or by the control plane object;
+	// empty if unknown.
+	Version string
+
+	// CreationTimestamp is the creation timestamp of the Cluster.
+	CreationTimestamp metav1.Time
+}
+
+func (c *clusterctlClient) GetClusters(options GetClustersOptions) ([]ClusterSummary, error) {
+	// gets access to the management cluster
+	clusterClient, err := c.clusterClientFactory(ClusterClientFactoryInput{Kubeconfig: options.Kubeconfig})
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure this command only runs against management clusters with the current Cluster API contract.
+	if err := clusterClient.ProviderInventory().CheckCAPIContract(); err != nil {
+		return nil, err
+	}
+
+	listOptions := []client.ListOption{}
+	if !options.AllNamespaces {
+		if options.Namespace == "" {
+			currentNamespace, err := clusterClient.Proxy().CurrentNamespace()
+			if err != nil {
+				return nil, err
+			}
+			options.Namespace = currentNamespace
+		}
+		listOptions = append(listOptions, client.InNamespace(options.Namespace))
+	}
+	if options.LabelSelector != "" {
+		selector, err := labels.Parse(options.LabelSelector)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid label selector %q", options.LabelSelector)
+		}
+		listOptions = append(listOptions, client.MatchingLabelsSelector{Selector: selector})
+	}
+
+	c2, err := clusterClient.Proxy().NewClient()
+	if err != nil {
+		return nil, err
+	}
+
+	clusters := &clusterv1.ClusterList{}
+	if err := c2.List(context.TODO(), clusters, listOptions...); err != nil {
+		return nil, errors.Wrap(err, "failed to list Clusters")
+	}
+
+	summaries := []ClusterSummary{}
+	for i := range clusters.Items {
+		summary, err := summarizeCluster(context.TODO(), c2, &clusters.Items[i])
+		if err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, summary)
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].Namespace != summaries[j].Namespace {
+			return summaries[i].Namespace < summaries[j].Namespace
+		}
+		return summaries[i].Name < summaries[j].Name
+	})
+	return summaries, nil
+}
+
+// summarizeCluster returns the ClusterSummary for a Cluster, computed from the status of the Cluster and of the
+// objects belonging to it.
+func summarizeCluster(ctx context.Context, c client.Client, cluster *clusterv1.Cluster) (ClusterSummary, error) {
+	summary := ClusterSummary{
+		Namespace:         cluster.Namespace,
+		Name:              cluster.Name,
+		Phase:             cluster.Status.Phase,
+		ControlPlaneReady: conditions.IsTrue(cluster, clusterv1.ControlPlaneReadyCondition),
+		CreationTimestamp: cluster.CreationTimestamp,
+	}
+
+	switch {
+	case cluster.Spec.Topology != nil:
+		summary.Version = cluster.Spec.Topology.Version
+	case cluster.Spec.ControlPlaneRef != nil:
+		controlPlane, err := external.Get(ctx, c, cluster.Spec.ControlPlaneRef, cluster.Namespace)
+		if err != nil && !apierrors.IsNotFound(errors.Cause(err)) {
+			return ClusterSummary{}, err
+		}
+		if controlPlane != nil {
+			summary.Version, _, _ = unstructured.NestedString(controlPlane.Object, "spec", "version")
+		}
+	}
+
+	clusterLabels := client.MatchingLabels{clusterv1.ClusterLabelName: cluster.Name}
+
+	machines := &clusterv1.MachineList{}
+	if err := c.List(ctx, machines, client.InNamespace(cluster.Namespace), clusterLabels); err != nil {
+		return ClusterSummary{}, errors.Wrapf(err, "failed to list Machines for Cluster %s/%s", cluster.Namespace, cluster.Name)
+	}
+	for i := range machines.Items {
+		m := &machines.Items[i]
+		if util.IsControlPlaneMachine(m) {
+			continue
+		}
+		summary.Workers++
+		if conditions.IsTrue(m, clusterv1.ReadyCondition) {
+			summary.ReadyWorkers++
+		}
+	}
+
+	// MachinePools are read as unstructured objects, given that the MachinePool CRD is installed only if the
+	// MachinePool feature is enabled.
+	machinePools := &unstructured.UnstructuredList{}
+	machinePools.SetGroupVersionKind(expv1.GroupVersion.WithKind("MachinePoolList"))
+	if err := c.List(ctx, machinePools, client.InNamespace(cluster.Namespace), clusterLabels); err != nil {
+		if !meta.IsNoMatchError(err) {
+			return ClusterSummary{}, errors.Wrapf(err, "failed to list MachinePools for Cluster %s/%s", cluster.Namespace, cluster.Name)
+		}
+	}
+	for _, mp := range machinePools.Items {
+		replicas, _, _ := unstructured.NestedInt64(mp.Object, "status", "replicas")
+		readyReplicas, _, _ := unstructured.NestedInt64(mp.Object, "status", "readyReplicas")
+		summary.Workers += int32(replicas)
+		summary.ReadyWorkers += int32(readyReplicas)
+	}
+
+	return summary, nil
+}
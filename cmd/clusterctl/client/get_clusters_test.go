@@ -0,0 +1,150 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1alpha4"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func Test_clusterctlClient_GetClusters(t *testing.T) {
+	newCluster := func(namespace, name string, labels map[string]string) *clusterv1.Cluster {
+		return &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: labels},
+			Spec: clusterv1.ClusterSpec{
+				Topology: &clusterv1.Topology{Version: "v1.21.2"},
+			},
+			Status: clusterv1.ClusterStatus{
+				Phase: string(clusterv1.ClusterPhaseProvisioned),
+				Conditions: clusterv1.Conditions{
+					{Type: clusterv1.ControlPlaneReadyCondition, Status: corev1.ConditionTrue},
+				},
+			},
+		}
+	}
+	newMachine := func(name string, labels map[string]string, ready bool) *clusterv1.Machine {
+		m := &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: name, Labels: labels},
+			Spec:       clusterv1.MachineSpec{ClusterName: "foo"},
+		}
+		if ready {
+			m.Status.Conditions = clusterv1.Conditions{{Type: clusterv1.ReadyCondition, Status: corev1.ConditionTrue}}
+		}
+		return m
+	}
+	clusterLabels := map[string]string{clusterv1.ClusterLabelName: "foo"}
+
+	objs := []client.Object{
+		newCluster("ns1", "foo", map[string]string{"env": "prod"}),
+		newCluster("ns1", "bar", nil),
+		newCluster("ns2", "baz", map[string]string{"env": "prod"}),
+		newMachine("foo-cp", map[string]string{clusterv1.ClusterLabelName: "foo", clusterv1.MachineControlPlaneLabelName: ""}, true),
+		newMachine("foo-worker-1", clusterLabels, true),
+		newMachine("foo-worker-2", clusterLabels, false),
+		&expv1.MachinePool{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "foo-pool", Labels: clusterLabels},
+			Spec:       expv1.MachinePoolSpec{ClusterName: "foo"},
+			Status:     expv1.MachinePoolStatus{Replicas: 3, ReadyReplicas: 2},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		options GetClustersOptions
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "lists the Clusters in the current namespace",
+			options: GetClustersOptions{},
+			want:    []string{"ns1/bar", "ns1/foo"},
+		},
+		{
+			name:    "lists the Clusters in a namespace",
+			options: GetClustersOptions{Namespace: "ns2"},
+			want:    []string{"ns2/baz"},
+		},
+		{
+			name:    "lists the Clusters in all the namespaces",
+			options: GetClustersOptions{Namespace: "ns2", AllNamespaces: true},
+			want:    []string{"ns1/bar", "ns1/foo", "ns2/baz"},
+		},
+		{
+			name:    "filters the Clusters by label",
+			options: GetClustersOptions{AllNamespaces: true, LabelSelector: "env=prod"},
+			want:    []string{"ns1/foo", "ns2/baz"},
+		},
+		{
+			name:    "returns error for invalid label selectors",
+			options: GetClustersOptions{LabelSelector: "env in prod"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			config1 := newFakeConfig()
+			cluster1 := newFakeCluster(cluster.Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"}, config1).WithObjs(objs...)
+			cluster1.fakeProxy.WithNamespace("ns1").WithFakeCAPISetup()
+			c := newFakeClient(config1).WithCluster(cluster1)
+
+			tt.options.Kubeconfig = Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"}
+			got, err := c.GetClusters(tt.options)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+
+			names := []string{}
+			for _, s := range got {
+				names = append(names, s.Namespace+"/"+s.Name)
+			}
+			g.Expect(names).To(Equal(tt.want))
+		})
+	}
+
+	t.Run("summarizes the status of the Cluster", func(t *testing.T) {
+		g := NewWithT(t)
+
+		config1 := newFakeConfig()
+		cluster1 := newFakeCluster(cluster.Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"}, config1).WithObjs(objs...)
+		cluster1.fakeProxy.WithNamespace("ns1").WithFakeCAPISetup()
+		c := newFakeClient(config1).WithCluster(cluster1)
+
+		got, err := c.GetClusters(GetClustersOptions{
+			Kubeconfig:    Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+			LabelSelector: "env=prod",
+		})
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(got).To(HaveLen(1))
+		g.Expect(got[0].Phase).To(Equal(string(clusterv1.ClusterPhaseProvisioned)))
+		g.Expect(got[0].ControlPlaneReady).To(BeTrue())
+		g.Expect(got[0].Version).To(Equal("v1.21.2"))
+		// The control plane Machine is not a worker; MachinePool replicas are.
+		g.Expect(got[0].Workers).To(Equal(int32(5)))
+		g.Expect(got[0].ReadyWorkers).To(Equal(int32(3)))
+	})
+}
@@ -55,6 +55,11 @@ type InitOptions struct {
 	// will be installed in a provider's default namespace.
 	TargetNamespace string
 
+	// Phases defines the installation phases to execute, e.g. crds for installing only the CustomResourceDefinitions
+	// of the providers, or components for installing all the other provider components. If unspecified, all the phases
+	// are executed.
+	Phases []string
+
 	// LogUsageInstructions instructs the init command to print the usage instructions in case of first run.
 	LogUsageInstructions bool
 
@@ -67,6 +72,11 @@ type InitOptions struct {
 func (c *clusterctlClient) Init(options InitOptions) ([]Components, error) {
 	log := logf.Log
 
+	installOptions, err := parseInstallPhases(options.Phases)
+	if err != nil {
+		return nil, err
+	}
+
 	// gets access to the management cluster
 	clusterClient, err := c.clusterClientFactory(ClusterClientFactoryInput{Kubeconfig: options.Kubeconfig})
 	if err != nil {
@@ -103,19 +113,26 @@ func (c *clusterctlClient) Init(options InitOptions) ([]Components, error) {
 		return nil, err
 	}
 
+	// Before installing the providers, ensure the current identity is allowed to create all the provider components,
+	// so init fails before changing anything instead of leaving the management cluster partially initialized.
+	if err := installer.CheckPermissions(installOptions); err != nil {
+		return nil, err
+	}
+
 	// Before installing the providers, ensure the cert-manager Webhook is in place.
 	certManager := clusterClient.CertManager()
 	if err := certManager.EnsureInstalled(); err != nil {
 		return nil, err
 	}
 
-	components, err := installer.Install()
+	components, err := installer.Install(installOptions)
 	if err != nil {
 		return nil, err
 	}
 
 	// If this is the firstRun, then log the usage instructions.
-	if firstRun && options.LogUsageInstructions {
+	// NOTE: The management cluster is initialized only after the provider components have been installed.
+	if firstRun && options.LogUsageInstructions && installOptions.HasPhase(cluster.InstallComponentsPhase) {
 		log.Info("")
 		log.Info("Your management cluster has been initialized successfully!")
 		log.Info("")
@@ -19,6 +19,9 @@ package client
 import (
 	"os"
 
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
 )
 
@@ -36,8 +39,24 @@ type MoveOptions struct {
 	// namespace will be used.
 	Namespace string
 
+	// ToNamespace is the namespace where the objects are moved to in the target management cluster.
+	// If unspecified, the objects are moved to the same namespace they exist in the source management cluster.
+	ToNamespace string
+
 	// DryRun means the move action is a dry run, no real action will be performed
 	DryRun bool
+
+	// CreateNamespace allows to create the namespaces missing in the target management cluster; if not set,
+	// the move fails before moving any object if any of the target namespaces is missing.
+	CreateNamespace bool
+
+	// IncludeKinds is a list of additional kinds to be moved, in the Kind.group format (e.g. Certificate.cert-manager.io);
+	// all the objects of those kinds existing in the namespace are moved, even if their CRDs are not installed by clusterctl.
+	IncludeKinds []string
+
+	// ExcludeKinds is a list of kinds not to be moved, in the Kind.group format (e.g. ConfigMap for the core group).
+	// The move fails if any of the objects to be moved is owned by an object of an excluded kind.
+	ExcludeKinds []string
 }
 
 // BackupOptions holds options supported by backup.
@@ -65,6 +84,11 @@ type RestoreOptions struct {
 }
 
 func (c *clusterctlClient) Move(options MoveOptions) error {
+	kindFilter, err := parseKindFilter(options.IncludeKinds, options.ExcludeKinds)
+	if err != nil {
+		return err
+	}
+
 	// Get the client for interacting with the source management cluster.
 	fromCluster, err := c.clusterClientFactory(ClusterClientFactoryInput{Kubeconfig: options.FromKubeconfig})
 	if err != nil {
@@ -109,7 +133,43 @@ func (c *clusterctlClient) Move(options MoveOptions) error {
 		options.Namespace = currentNamespace
 	}
 
-	return fromCluster.ObjectMover().Move(options.Namespace, toCluster, options.DryRun)
+	return fromCluster.ObjectMover().Move(options.Namespace, options.ToNamespace, toCluster, options.DryRun, options.CreateNamespace, kindFilter)
+}
+
+// parseKindFilter parses the kinds to be included in, or excluded from, the move.
+func parseKindFilter(includeKinds, excludeKinds []string) (cluster.KindFilter, error) {
+	filter := cluster.KindFilter{}
+	for _, kind := range includeKinds {
+		gk, err := parseGroupKind(kind)
+		if err != nil {
+			return filter, err
+		}
+		filter.Include = append(filter.Include, gk)
+	}
+	for _, kind := range excludeKinds {
+		gk, err := parseGroupKind(kind)
+		if err != nil {
+			return filter, err
+		}
+		if gk == clusterv1.GroupVersion.WithKind("Cluster").GroupKind() {
+			return filter, errors.Errorf("invalid kind %q: Cluster objects can't be excluded from move", kind)
+		}
+		for _, included := range filter.Include {
+			if gk == included {
+				return filter, errors.Errorf("invalid kind %q: the kind can't be both included in and excluded from move", kind)
+			}
+		}
+		filter.Exclude = append(filter.Exclude, gk)
+	}
+	return filter, nil
+}
+
+func parseGroupKind(kind string) (schema.GroupKind, error) {
+	gk := schema.ParseGroupKind(kind)
+	if gk.Kind == "" {
+		return gk, errors.Errorf("invalid kind %q: the kind must be in the Kind.group format", kind)
+	}
+	return gk, nil
 }
 
 func (c *clusterctlClient) Backup(options BackupOptions) error {
@@ -22,6 +22,7 @@ import (
 	"testing"
 
 	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
@@ -255,7 +256,7 @@ type fakeObjectMover struct {
 	restoerErr error
 }
 
-func (f *fakeObjectMover) Move(namespace string, toCluster cluster.Client, dryRun bool) error {
+func (f *fakeObjectMover) Move(namespace, toNamespace string, toCluster cluster.Client, dryRun, createNamespace bool, kindFilter cluster.KindFilter) error {
 	return f.moveErr
 }
 
@@ -266,3 +267,56 @@ func (f *fakeObjectMover) Backup(namespace string, directory string) error {
 func (f *fakeObjectMover) Restore(toCluster cluster.Client, directory string) error {
 	return f.restoerErr
 }
+
+func Test_parseKindFilter(t *testing.T) {
+	tests := []struct {
+		name         string
+		includeKinds []string
+		excludeKinds []string
+		want         cluster.KindFilter
+		wantErr      bool
+	}{
+		{
+			name: "empty filter",
+			want: cluster.KindFilter{},
+		},
+		{
+			name:         "parses included and excluded kinds",
+			includeKinds: []string{"Certificate.cert-manager.io"},
+			excludeKinds: []string{"ConfigMap", "Bar.foo"},
+			want: cluster.KindFilter{
+				Include: []schema.GroupKind{{Group: "cert-manager.io", Kind: "Certificate"}},
+				Exclude: []schema.GroupKind{{Kind: "ConfigMap"}, {Group: "foo", Kind: "Bar"}},
+			},
+		},
+		{
+			name:         "fails for empty kinds",
+			includeKinds: []string{""},
+			wantErr:      true,
+		},
+		{
+			name:         "fails for kinds both included and excluded",
+			includeKinds: []string{"Bar.foo"},
+			excludeKinds: []string{"Bar.foo"},
+			wantErr:      true,
+		},
+		{
+			name:         "fails when excluding Clusters",
+			excludeKinds: []string{"Cluster.cluster.x-k8s.io"},
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got, err := parseKindFilter(tt.includeKinds, tt.excludeKinds)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
@@ -120,6 +120,15 @@ func newRepositoryClient(provider config.Provider, configClient config.Client, o
 		client.repository = r
 	}
 
+	// if signature verification is configured or required, verify the provider components and metadata.
+	verification, err := configClient.SignatureVerification().Get()
+	if err != nil {
+		return nil, err
+	}
+	if verification.Required() || len(verification.PublicKeys()) > 0 {
+		client.repository = newSignatureVerifyingRepository(client.repository, verification)
+	}
+
 	return client, nil
 }
 
@@ -146,6 +155,9 @@ type Repository interface {
 
 	// GetVersion return the list of versions that are available in a provider repository
 	GetVersions() ([]string, error)
+
+	// ListFiles returns the names of the files available for a given provider version, relative to RootPath.
+	ListFiles(version string) ([]string, error)
 }
 
 var _ Repository = &test.FakeRepository{}
@@ -148,6 +148,9 @@ type ComponentsOptions struct {
 	// SkipTemplateProcess allows for skipping the call to the template processor, including also variable replacement in the component YAML.
 	// NOTE this works only if the rawYaml is a valid yaml by itself, like e.g when using envsubst/the simple processor.
 	SkipTemplateProcess bool
+	// YamlProcessor defines the yaml processor to use for the components processing. If not defined, the
+	// processor of the components client will be used, that defaults to the SimpleProcessor.
+	YamlProcessor yaml.Processor
 }
 
 // ComponentsInput represents all the inputs required by NewComponents.
@@ -165,9 +168,10 @@ type ComponentsInput struct {
 // from the provider repositories:
 // 1. Checks for all the variables in the component YAML file and replace with corresponding config values
 // 2. The variables replacement can be skipped using the SkipTemplateProcess flag in the input options
-// 3. Ensure all the provider components are deployed in the target namespace (apply only to namespaced objects)
-// 4. Ensure all the ClusterRoleBinding which are referencing namespaced objects have the name prefixed with the namespace name
-// 5. Adds labels to all the components in order to allow easy identification of the provider objects.
+// 3. Applies the local kustomize overlay defined for the provider in the clusterctl configuration, if any
+// 4. Ensure all the provider components are deployed in the target namespace (apply only to namespaced objects)
+// 5. Ensure all the ClusterRoleBinding which are referencing namespaced objects have the name prefixed with the namespace name
+// 6. Adds labels to all the components in order to allow easy identification of the provider objects.
 func NewComponents(input ComponentsInput) (Components, error) {
 	variables, err := input.Processor.GetVariables(input.RawYaml)
 	if err != nil {
@@ -184,6 +188,19 @@ func NewComponents(input ComponentsInput) (Components, error) {
 		}
 	}
 
+	// Apply the kustomize overlay, if defined; this happens after variable substitution, so the overlay
+	// can patch the actual values, and before all the other transformations, so they apply to the patched objects.
+	overlay, err := input.ConfigClient.Overlays().Get(input.Provider)
+	if err != nil {
+		return nil, err
+	}
+	if overlay != "" {
+		processedYaml, err = applyOverlay(overlay, processedYaml)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Transform the yaml in a list of objects, so following transformation can work on typed objects (instead of working on a string/slice of bytes)
 	objs, err := utilyaml.ToUnstructured(processedYaml)
 	if err != nil {
@@ -62,7 +62,11 @@ func (f *componentsClient) Get(options ComponentsOptions) (Components, error) {
 	if err != nil {
 		return nil, err
 	}
-	return NewComponents(ComponentsInput{f.provider, f.configClient, f.processor, file, options})
+	processor := f.processor
+	if options.YamlProcessor != nil {
+		processor = options.YamlProcessor
+	}
+	return NewComponents(ComponentsInput{f.provider, f.configClient, processor, file, options})
 }
 
 func (f *componentsClient) getRawBytes(options *ComponentsOptions) ([]byte, error) {
@@ -82,6 +86,7 @@ func (f *componentsClient) getRawBytes(options *ComponentsOptions) ([]byte, erro
 		provider:              f.provider,
 		version:               options.Version,
 		filePath:              path,
+		repository:            f.repository,
 	})
 	if err != nil {
 		return nil, err
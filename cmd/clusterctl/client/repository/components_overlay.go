@@ -0,0 +1,70 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// OverlayComponentsFile is the name of the file the provider components are written to in the kustomize
+	// overlay directory; the kustomization file of the overlay must list it in its resources.
+	OverlayComponentsFile = "components.yaml"
+)
+
+// kustomizeBuild runs kustomize build on a directory and returns the resulting yaml.
+// It is a variable so it can be replaced in tests.
+var kustomizeBuild = func(dir string) ([]byte, error) {
+	var cmd *exec.Cmd
+	if path, err := exec.LookPath("kustomize"); err == nil {
+		cmd = exec.Command(path, "build", dir) //nolint:gosec
+	} else if path, err := exec.LookPath("kubectl"); err == nil {
+		cmd = exec.Command(path, "kustomize", dir) //nolint:gosec
+	} else {
+		return nil, errors.New("applying a kustomize overlay requires either kustomize or kubectl to be in the PATH")
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, errors.Wrapf(err, "failed to run %s: %s", cmd.String(), stderr.String())
+	}
+	return stdout.Bytes(), nil
+}
+
+// applyOverlay applies a local kustomize overlay to the provider components yaml. The overlay is built in place,
+// so relative references to other directories, e.g. a shared base, keep working; the components yaml is written
+// to the overlay directory for the duration of the build and removed afterwards.
+func applyOverlay(overlay string, yaml []byte) ([]byte, error) {
+	componentsFile := filepath.Join(overlay, OverlayComponentsFile)
+	if err := os.WriteFile(componentsFile, yaml, 0600); err != nil {
+		return nil, errors.Wrapf(err, "failed to write the components yaml to the overlay %q", overlay)
+	}
+	defer os.Remove(componentsFile)
+
+	out, err := kustomizeBuild(overlay)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to apply the overlay %q", overlay)
+	}
+	return out, nil
+}
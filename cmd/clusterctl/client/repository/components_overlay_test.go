@@ -0,0 +1,54 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func Test_applyOverlay(t *testing.T) {
+	g := NewWithT(t)
+
+	root, err := os.MkdirTemp("", "overlay")
+	g.Expect(err).NotTo(HaveOccurred())
+	defer os.RemoveAll(root)
+
+	g.Expect(os.MkdirAll(filepath.Join(root, "base"), 0700)).To(Succeed())
+	g.Expect(os.WriteFile(filepath.Join(root, "base", "kustomization.yaml"), []byte("resources:\n- args.yaml\n"), 0600)).To(Succeed())
+	overlayDir := filepath.Join(root, "aws")
+	g.Expect(os.MkdirAll(overlayDir, 0700)).To(Succeed())
+	g.Expect(os.WriteFile(filepath.Join(overlayDir, "kustomization.yaml"), []byte("resources:\n- components.yaml\n- ../base\n"), 0600)).To(Succeed())
+
+	defer func(f func(string) ([]byte, error)) { kustomizeBuild = f }(kustomizeBuild)
+	kustomizeBuild = func(dir string) ([]byte, error) {
+		// The overlay is built in place, so relative references to other directories can be resolved.
+		g.Expect(dir).To(Equal(overlayDir))
+		g.Expect(filepath.Join(dir, "..", "base", "kustomization.yaml")).To(BeAnExistingFile())
+		return os.ReadFile(filepath.Join(dir, OverlayComponentsFile))
+	}
+
+	out, err := applyOverlay(overlayDir, []byte("kind: Namespace"))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(out)).To(Equal("kind: Namespace"))
+
+	// The components yaml is removed from the overlay directory after the build.
+	g.Expect(filepath.Join(overlayDir, OverlayComponentsFile)).NotTo(BeAnExistingFile())
+}
@@ -67,6 +67,7 @@ func (f *metadataClient) Get() (*clusterctlv1.Metadata, error) {
 		provider:              f.provider,
 		version:               version,
 		filePath:              metadataFile,
+		repository:            f.repository,
 	})
 	if err != nil {
 		return nil, err
@@ -49,6 +49,10 @@ type newOverrideInput struct {
 	provider              config.Provider
 	version               string
 	filePath              string
+
+	// repository is the repository the override replaces files of; if it verifies signatures,
+	// the override signature is verified too.
+	repository Repository
 }
 
 // newOverride returns an Overrider.
@@ -89,6 +93,11 @@ func getLocalOverride(info *newOverrideInput) ([]byte, error) {
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to read local override for %s", overridePath)
 		}
+		if r, ok := info.repository.(*signatureVerifyingRepository); ok {
+			if err := r.verifyLocalOverride(overridePath, content); err != nil {
+				return nil, err
+			}
+		}
 		return content, nil
 	}
 
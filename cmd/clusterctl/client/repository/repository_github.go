@@ -121,6 +121,27 @@ func (g *gitHubRepository) GetFile(version, path string) ([]byte, error) {
 	return files, nil
 }
 
+// ListFiles returns the names of the files available for a given provider version, i.e. the assets of the GitHub release.
+func (g *gitHubRepository) ListFiles(version string) ([]string, error) {
+	release, err := g.getReleaseByTag(version)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get GitHub release %s", version)
+	}
+
+	files := []string{}
+	for _, a := range release.Assets {
+		if a.Name == nil {
+			continue
+		}
+		name, err := filepath.Rel(g.rootPath, *a.Name)
+		if err != nil || strings.HasPrefix(name, "..") {
+			continue
+		}
+		files = append(files, name)
+	}
+	return files, nil
+}
+
 // newGitHubRepository returns a gitHubRepository implementation.
 func newGitHubRepository(providerConfig config.Provider, configVariablesClient config.VariablesClient, opts ...githubRepositoryOption) (*gitHubRepository, error) {
 	if configVariablesClient == nil {
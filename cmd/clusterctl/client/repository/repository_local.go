@@ -118,6 +118,34 @@ func (r *localRepository) GetFile(version, fileName string) ([]byte, error) {
 	return content, nil
 }
 
+// ListFiles returns the names of the files available for a given provider version.
+func (r *localRepository) ListFiles(version string) ([]string, error) {
+	var err error
+
+	if version == latestVersionTag {
+		version, err = r.getLatestRelease()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get the latest release")
+		}
+	} else if version == "" {
+		version = r.defaultVersion
+	}
+
+	releasePath := filepath.Join(r.basepath, r.providerLabel, version, r.RootPath())
+	entries, err := os.ReadDir(releasePath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list files from local release %s", version)
+	}
+	files := []string{}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		files = append(files, e.Name())
+	}
+	return files, nil
+}
+
 // GetVersions returns the list of versions that are available for a local repository.
 func (r *localRepository) GetVersions() ([]string, error) {
 	// get all the sub-directories under {basepath}/{provider-id}/
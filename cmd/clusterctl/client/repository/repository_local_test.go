@@ -306,6 +306,29 @@ func Test_localRepository_GetFile(t *testing.T) {
 	}
 }
 
+func Test_localRepository_ListFiles(t *testing.T) {
+	g := NewWithT(t)
+
+	tmpDir := createTempDir(t)
+	defer os.RemoveAll(tmpDir)
+
+	dst := createLocalTestProviderFile(t, tmpDir, "infrastructure-foo/v1.0.0/infrastructure-components.yaml", "foo: bar")
+	createLocalTestProviderFile(t, tmpDir, "infrastructure-foo/v1.0.0/cluster-template.yaml", "foo: bar")
+	createLocalTestProviderFile(t, tmpDir, "infrastructure-foo/v1.0.0/cluster-template-ipv6.yaml", "foo: bar")
+	createLocalTestProviderFile(t, tmpDir, "infrastructure-foo/v1.0.0/subdir/cluster-template-other.yaml", "foo: bar")
+	p := config.NewProvider("foo", dst, clusterctlv1.InfrastructureProviderType)
+
+	r, err := newLocalRepository(p, test.NewFakeVariableClient())
+	g.Expect(err).NotTo(HaveOccurred())
+
+	files, err := r.ListFiles("v1.0.0")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(files).To(ConsistOf("infrastructure-components.yaml", "cluster-template.yaml", "cluster-template-ipv6.yaml"))
+
+	_, err = r.ListFiles("v2.0.0")
+	g.Expect(err).To(HaveOccurred())
+}
+
 func Test_localRepository_GetVersions(t *testing.T) {
 	tmpDir := createTempDir(t)
 	defer os.RemoveAll(tmpDir)
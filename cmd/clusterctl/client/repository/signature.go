@@ -0,0 +1,153 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	logf "sigs.k8s.io/cluster-api/cmd/clusterctl/log"
+)
+
+// signatureSuffix is the suffix of the file containing the signature of a file, as generated by `cosign sign-blob`.
+const signatureSuffix = ".sig"
+
+// signatureVerifyingRepository is a Repository verifying the signatures of provider components and metadata
+// before returning them.
+type signatureVerifyingRepository struct {
+	Repository
+	verification config.SignatureVerification
+}
+
+// ensure signatureVerifyingRepository implements Repository.
+var _ Repository = &signatureVerifyingRepository{}
+
+// newSignatureVerifyingRepository returns a Repository verifying signatures according to the given configuration.
+func newSignatureVerifyingRepository(repository Repository, verification config.SignatureVerification) *signatureVerifyingRepository {
+	return &signatureVerifyingRepository{
+		Repository:   repository,
+		verification: verification,
+	}
+}
+
+// GetFile returns a file for a given provider version, verifying its signature in case of provider components or metadata.
+func (r *signatureVerifyingRepository) GetFile(version, path string) ([]byte, error) {
+	file, err := r.Repository.GetFile(version, path)
+	if err != nil {
+		return nil, err
+	}
+
+	if path != r.ComponentsPath() && path != metadataFile {
+		return file, nil
+	}
+	getSignature := func() ([]byte, error) {
+		return r.Repository.GetFile(version, path+signatureSuffix)
+	}
+	if err := r.verify(path, file, getSignature); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// verifyLocalOverride verifies the signature of a provider components or metadata file read from the overrides layer;
+// the signature is expected next to the override file, so the overrides layer can't be used to bypass the verification.
+func (r *signatureVerifyingRepository) verifyLocalOverride(overridePath string, file []byte) error {
+	getSignature := func() ([]byte, error) {
+		return os.ReadFile(overridePath + signatureSuffix) //nolint:gosec
+	}
+	return r.verify(overridePath, file, getSignature)
+}
+
+func (r *signatureVerifyingRepository) verify(path string, file []byte, getSignature func() ([]byte, error)) error {
+	log := logf.Log
+
+	if len(r.verification.PublicKeys()) == 0 {
+		if r.verification.Required() {
+			return errors.Errorf("failed to verify the signature of %q: signed files are required, but no public keys are configured", path)
+		}
+		return nil
+	}
+
+	rawSignature, err := getSignature()
+	if err != nil {
+		if r.verification.Required() {
+			return errors.Wrapf(err, "failed to get the signature of %q, signed files are required", path)
+		}
+		log.V(1).Info("Skipping signature verification, signature not found", "File", path)
+		return nil
+	}
+	signature := decodeBase64(rawSignature)
+	digest := sha256.Sum256(file)
+
+	for _, k := range r.verification.PublicKeys() {
+		publicKey, err := parsePublicKey(k)
+		if err != nil {
+			return err
+		}
+		if verifySignature(publicKey, file, digest[:], signature) {
+			log.V(1).Info("Signature verified", "File", path)
+			return nil
+		}
+	}
+
+	return errors.Errorf("failed to verify the signature of %q: the signature does not match any of the configured public keys", path)
+}
+
+// decodeBase64 returns the base64 decoded content of a file, or the file as is if it is not base64 encoded.
+func decodeBase64(raw []byte) []byte {
+	decoded, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(raw)))
+	if err != nil {
+		return raw
+	}
+	return decoded
+}
+
+func parsePublicKey(rawPEM []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(rawPEM)
+	if block == nil {
+		return nil, errors.New("failed to decode public key: invalid PEM")
+	}
+	publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse public key")
+	}
+	return publicKey, nil
+}
+
+// verifySignature verifies a signature generated by cosign; ed25519 signs the file, while the other algorithms sign its SHA-256 digest.
+func verifySignature(publicKey crypto.PublicKey, file, digest, signature []byte) bool {
+	switch k := publicKey.(type) {
+	case *ecdsa.PublicKey:
+		return ecdsa.VerifyASN1(k, digest, signature)
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(k, crypto.SHA256, digest, signature) == nil
+	case ed25519.PublicKey:
+		return ed25519.Verify(k, file, signature)
+	default:
+		return false
+	}
+}
@@ -0,0 +1,205 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+)
+
+func Test_signatureVerifyingRepository_GetFile(t *testing.T) {
+	g := NewWithT(t)
+
+	components := []byte("components")
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	g.Expect(err).NotTo(HaveOccurred())
+	publicKey := encodePublicKey(g, &key.PublicKey)
+	signature := sign(g, key, components)
+
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	g.Expect(err).NotTo(HaveOccurred())
+	otherPublicKey := encodePublicKey(g, &otherKey.PublicKey)
+
+	repository := func(files map[string][]byte) Repository {
+		r := test.NewFakeRepository().
+			WithPaths("root", "components.yaml").
+			WithDefaultVersion("v1.0.0").
+			WithFile("v1.0.0", "components.yaml", components).
+			WithFile("v1.0.0", "cluster-template.yaml", []byte("template"))
+		for path, content := range files {
+			r.WithFile("v1.0.0", path, content)
+		}
+		return r
+	}
+
+	tests := []struct {
+		name         string
+		path         string
+		repository   Repository
+		verification config.SignatureVerification
+		wantErr      bool
+	}{
+		{
+			name:         "pass with a valid signature",
+			path:         "components.yaml",
+			repository:   repository(map[string][]byte{"components.yaml.sig": signature}),
+			verification: config.NewSignatureVerification(false, [][]byte{otherPublicKey, publicKey}),
+			wantErr:      false,
+		},
+		{
+			name:         "fails with a signature not matching the public keys",
+			path:         "components.yaml",
+			repository:   repository(map[string][]byte{"components.yaml.sig": signature}),
+			verification: config.NewSignatureVerification(false, [][]byte{otherPublicKey}),
+			wantErr:      true,
+		},
+		{
+			name:         "fails with a signature for a different content",
+			path:         "components.yaml",
+			repository:   repository(map[string][]byte{"components.yaml.sig": sign(g, key, []byte("tampered"))}),
+			verification: config.NewSignatureVerification(false, [][]byte{publicKey}),
+			wantErr:      true,
+		},
+		{
+			name:         "pass without signature if signatures are not required",
+			path:         "components.yaml",
+			repository:   repository(nil),
+			verification: config.NewSignatureVerification(false, [][]byte{publicKey}),
+			wantErr:      false,
+		},
+		{
+			name:         "fails without signature if signatures are required",
+			path:         "components.yaml",
+			repository:   repository(nil),
+			verification: config.NewSignatureVerification(true, [][]byte{publicKey}),
+			wantErr:      true,
+		},
+		{
+			name:         "fails if signatures are required but no keys are configured",
+			path:         "components.yaml",
+			repository:   repository(map[string][]byte{"components.yaml.sig": signature}),
+			verification: config.NewSignatureVerification(true, nil),
+			wantErr:      true,
+		},
+		{
+			name:         "pass for files other than components and metadata",
+			path:         "cluster-template.yaml",
+			repository:   repository(nil),
+			verification: config.NewSignatureVerification(true, [][]byte{publicKey}),
+			wantErr:      false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			r := newSignatureVerifyingRepository(tt.repository, tt.verification)
+			_, err := r.GetFile("v1.0.0", tt.path)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+		})
+	}
+}
+
+func Test_signatureVerifyingRepository_verifyLocalOverride(t *testing.T) {
+	g := NewWithT(t)
+
+	components := []byte("components")
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	g.Expect(err).NotTo(HaveOccurred())
+	publicKey := encodePublicKey(g, &key.PublicKey)
+
+	tests := []struct {
+		name      string
+		signature []byte
+		wantErr   bool
+	}{
+		{
+			name:      "pass with a valid signature",
+			signature: sign(g, key, components),
+			wantErr:   false,
+		},
+		{
+			name:      "fails with a signature for a different content",
+			signature: sign(g, key, []byte("tampered")),
+			wantErr:   true,
+		},
+		{
+			name:    "fails without signature if signatures are required",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			tmpDir := createTempDir(t)
+			defer os.RemoveAll(tmpDir)
+
+			createLocalTestProviderFile(t, tmpDir, "infrastructure-myinfra/v1.0.0/components.yaml", string(components))
+			if tt.signature != nil {
+				createLocalTestProviderFile(t, tmpDir, "infrastructure-myinfra/v1.0.0/components.yaml.sig", string(tt.signature))
+			}
+
+			r := newSignatureVerifyingRepository(test.NewFakeRepository(), config.NewSignatureVerification(true, [][]byte{publicKey}))
+			_, err := getLocalOverride(&newOverrideInput{
+				configVariablesClient: test.NewFakeVariableClient().WithVar(overrideFolderKey, tmpDir),
+				provider:              config.NewProvider("myinfra", "", clusterctlv1.InfrastructureProviderType),
+				version:               "v1.0.0",
+				filePath:              "components.yaml",
+				repository:            r,
+			})
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+		})
+	}
+}
+
+func encodePublicKey(g *WithT, publicKey *ecdsa.PublicKey) []byte {
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	g.Expect(err).NotTo(HaveOccurred())
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+// sign generates a signature like `cosign sign-blob` does.
+func sign(g *WithT, key *ecdsa.PrivateKey, file []byte) []byte {
+	digest := sha256.Sum256(file)
+	signature, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+	g.Expect(err).NotTo(HaveOccurred())
+	return []byte(base64.StdEncoding.EncodeToString(signature))
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
 	yaml "sigs.k8s.io/cluster-api/cmd/clusterctl/client/yamlprocessor"
 	utilyaml "sigs.k8s.io/cluster-api/util/yaml"
@@ -40,6 +41,10 @@ type Template interface {
 	// This value is derived from the template YAML.
 	VariableMap() map[string]*string
 
+	// VariableDefinitions describes the variables used by the template, if the provider defines them
+	// in its metadata file. This value is set only for templates read from a provider repository.
+	VariableDefinitions() []clusterctlv1.VariableDefinition
+
 	// TargetNamespace where the template objects will be installed.
 	TargetNamespace() string
 
@@ -52,10 +57,11 @@ type Template interface {
 
 // template implements Template.
 type template struct {
-	variables       []string
-	variableMap     map[string]*string
-	targetNamespace string
-	objs            []unstructured.Unstructured
+	variables           []string
+	variableMap         map[string]*string
+	variableDefinitions []clusterctlv1.VariableDefinition
+	targetNamespace     string
+	objs                []unstructured.Unstructured
 }
 
 // Ensures template implements the Template interface.
@@ -69,6 +75,10 @@ func (t *template) VariableMap() map[string]*string {
 	return t.variableMap
 }
 
+func (t *template) VariableDefinitions() []clusterctlv1.VariableDefinition {
+	return t.variableDefinitions
+}
+
 func (t *template) TargetNamespace() string {
 	return t.targetNamespace
 }
@@ -88,6 +98,7 @@ type TemplateInput struct {
 	Processor             yaml.Processor
 	TargetNamespace       string
 	SkipTemplateProcess   bool
+	VariableDefinitions   []clusterctlv1.VariableDefinition
 }
 
 // NewTemplate returns a new objects embedding a cluster template YAML file.
@@ -104,13 +115,27 @@ func NewTemplate(input TemplateInput) (Template, error) {
 
 	if input.SkipTemplateProcess {
 		return &template{
-			variables:       variables,
-			variableMap:     variableMap,
-			targetNamespace: input.TargetNamespace,
+			variables:           variables,
+			variableMap:         variableMap,
+			variableDefinitions: input.VariableDefinitions,
+			targetNamespace:     input.TargetNamespace,
 		}, nil
 	}
 
-	processedYaml, err := input.Processor.Process(input.RawArtifact, input.ConfigVariablesClient.Get)
+	// Validate the variables defined in the provider metadata, if any, and use their values in the canonical
+	// format of their type when processing the template.
+	values, err := validateVariables(input.VariableDefinitions, variables, input.ConfigVariablesClient.Get)
+	if err != nil {
+		return nil, err
+	}
+	getVariable := func(name string) (string, error) {
+		if value, ok := values[name]; ok {
+			return value, nil
+		}
+		return input.ConfigVariablesClient.Get(name)
+	}
+
+	processedYaml, err := input.Processor.Process(input.RawArtifact, getVariable)
 	if err != nil {
 		return nil, err
 	}
@@ -127,9 +152,10 @@ func NewTemplate(input TemplateInput) (Template, error) {
 	objs = fixTargetNamespace(objs, input.TargetNamespace)
 
 	return &template{
-		variables:       variables,
-		variableMap:     variableMap,
-		targetNamespace: input.TargetNamespace,
-		objs:            objs,
+		variables:           variables,
+		variableMap:         variableMap,
+		variableDefinitions: input.VariableDefinitions,
+		targetNamespace:     input.TargetNamespace,
+		objs:                objs,
 	}, nil
 }
@@ -17,7 +17,11 @@ limitations under the License.
 package repository
 
 import (
+	"sort"
+	"strings"
+
 	"github.com/pkg/errors"
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
 	yaml "sigs.k8s.io/cluster-api/cmd/clusterctl/client/yamlprocessor"
 	logf "sigs.k8s.io/cluster-api/cmd/clusterctl/log"
@@ -27,6 +31,18 @@ import (
 // Templates are yaml files to be used for creating a guest cluster.
 type TemplateClient interface {
 	Get(flavor, targetNamespace string, listVariablesOnly bool) (Template, error)
+
+	// ListFlavors returns the flavors of the templates available in the provider repository.
+	ListFlavors() ([]Flavor, error)
+}
+
+// Flavor describes a flavor of the cluster templates hosted on a provider repository.
+type Flavor struct {
+	// Name of the flavor; the default template has an empty name.
+	Name string
+
+	// Description of the flavor, as defined in the provider metadata, if any.
+	Description string
 }
 
 // templateClient implements TemplateClient.
@@ -96,11 +112,71 @@ func (c *templateClient) Get(flavor, targetNamespace string, skipTemplateProcess
 		log.V(1).Info("Using", "Override", name, "Provider", c.provider.ManifestLabel(), "Version", version)
 	}
 
+	// Read the variable definitions from the provider metadata, used to list and to validate the variables;
+	// definitions are optional, so failing to read them is not an error.
+	var variableDefinitions []clusterctlv1.VariableDefinition
+	metadata, err := newMetadataClient(c.provider, version, c.repository, c.configVariablesClient).Get()
+	if err != nil {
+		log.V(5).Info("Failed to read variable definitions from the provider metadata", "Provider", c.provider.ManifestLabel(), "Version", version, "Error", err.Error())
+	} else {
+		variableDefinitions = metadata.Variables
+	}
+
 	return NewTemplate(TemplateInput{
-		rawArtifact,
-		c.configVariablesClient,
-		c.processor,
-		targetNamespace,
-		skipTemplateProcess,
+		RawArtifact:           rawArtifact,
+		ConfigVariablesClient: c.configVariablesClient,
+		Processor:             c.processor,
+		TargetNamespace:       targetNamespace,
+		SkipTemplateProcess:   skipTemplateProcess,
+		VariableDefinitions:   variableDefinitions,
+	})
+}
+
+// ListFlavors returns the flavors of the templates available in the provider repository; if the version of the
+// template client is empty, the default version of the repository is used.
+// ListFlavors assumes the following naming convention for templates: cluster-template[-<flavor_name>].yaml;
+// files matching the naming convention which are not used by the yaml processor are ignored.
+func (c *templateClient) ListFlavors() ([]Flavor, error) {
+	log := logf.Log
+
+	version := c.version
+	if version == "" {
+		version = c.repository.DefaultVersion()
+	}
+
+	files, err := c.repository.ListFiles(version)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list files from provider's repository %q", c.provider.ManifestLabel())
+	}
+
+	// Descriptions are optional, so failing to read them from the provider metadata is not an error.
+	descriptions := map[string]string{}
+	metadata, err := newMetadataClient(c.provider, version, c.repository, c.configVariablesClient).Get()
+	if err != nil {
+		log.V(5).Info("Failed to read flavor definitions from the provider metadata", "Provider", c.provider.ManifestLabel(), "Version", version, "Error", err.Error())
+	} else {
+		for _, f := range metadata.Flavors {
+			descriptions[f.Name] = f.Description
+		}
+	}
+
+	flavors := []Flavor{}
+	for _, file := range files {
+		if !strings.HasPrefix(file, "cluster-template") || !strings.HasSuffix(file, ".yaml") {
+			continue
+		}
+		name := strings.TrimPrefix(strings.TrimSuffix(strings.TrimPrefix(file, "cluster-template"), ".yaml"), "-")
+		if c.processor.GetTemplateName(version, name) != file {
+			continue
+		}
+		flavors = append(flavors, Flavor{
+			Name:        name,
+			Description: descriptions[name],
+		})
+	}
+
+	sort.Slice(flavors, func(i, j int) bool {
+		return flavors[i].Name < flavors[j].Name
 	})
+	return flavors, nil
 }
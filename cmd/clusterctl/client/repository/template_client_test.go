@@ -45,8 +45,9 @@ func Test_templates_Get(t *testing.T) {
 		listVariablesOnly bool
 	}
 	type want struct {
-		variables       []string
-		targetNamespace string
+		variables           []string
+		variableDefinitions []clusterctlv1.VariableDefinition
+		targetNamespace     string
 	}
 	tests := []struct {
 		name    string
@@ -101,6 +102,37 @@ func Test_templates_Get(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "pass and read variable definitions from the metadata if listing variables",
+			fields: fields{
+				version:  "v1.0",
+				provider: p1,
+				repository: test.NewFakeRepository().
+					WithPaths("root", "").
+					WithDefaultVersion("v1.0").
+					WithFile("v1.0", "cluster-template.yaml", templateMapYaml).
+					WithMetadata("v1.0", &clusterctlv1.Metadata{
+						Variables: []clusterctlv1.VariableDefinition{
+							{Name: variableName, Type: "string", Description: "a variable"},
+						},
+					}),
+				configVariablesClient: test.NewFakeVariableClient(),
+				processor:             yaml.NewSimpleProcessor(),
+			},
+			args: args{
+				flavor:            "",
+				targetNamespace:   "ns1",
+				listVariablesOnly: true,
+			},
+			want: want{
+				variables: []string{variableName},
+				variableDefinitions: []clusterctlv1.VariableDefinition{
+					{Name: variableName, Type: "string", Description: "a variable"},
+				},
+				targetNamespace: "ns1",
+			},
+			wantErr: false,
+		},
 		{
 			name: "fails if template does not exists",
 			fields: fields{
@@ -202,6 +234,7 @@ func Test_templates_Get(t *testing.T) {
 
 			g.Expect(got.Variables()).To(Equal(tt.want.variables))
 			g.Expect(got.TargetNamespace()).To(Equal(tt.want.targetNamespace))
+			g.Expect(got.VariableDefinitions()).To(Equal(tt.want.variableDefinitions))
 
 			// check variable replaced in yaml
 			yaml, err := got.Yaml()
@@ -218,3 +251,38 @@ func Test_templates_Get(t *testing.T) {
 		})
 	}
 }
+
+func Test_templates_ListFlavors(t *testing.T) {
+	g := NewWithT(t)
+
+	p1 := config.NewProvider("p1", "", clusterctlv1.InfrastructureProviderType)
+	repository := test.NewFakeRepository().
+		WithPaths("root", "").
+		WithDefaultVersion("v1.0").
+		WithFile("v1.0", "cluster-template.yaml", templateMapYaml).
+		WithFile("v1.0", "cluster-template-machinepool.yaml", templateMapYaml).
+		WithFile("v1.0", "cluster-template-ipv6.yaml", templateMapYaml).
+		WithFile("v1.0", "infrastructure-components.yaml", []byte("components")).
+		WithMetadata("v1.0", &clusterctlv1.Metadata{
+			ReleaseSeries: []clusterctlv1.ReleaseSeries{{Major: 1, Minor: 0, Contract: test.CurrentCAPIContract}},
+			Flavors: []clusterctlv1.FlavorDefinition{
+				{Name: "machinepool", Description: "Workers are managed by a MachinePool."},
+			},
+		})
+
+	f := newTemplateClient(
+		TemplateClientInput{
+			provider:              p1,
+			repository:            repository,
+			configVariablesClient: test.NewFakeVariableClient(),
+			processor:             yaml.NewSimpleProcessor(),
+		},
+	)
+	flavors, err := f.ListFlavors()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(flavors).To(Equal([]Flavor{
+		{Name: ""},
+		{Name: "ipv6"},
+		{Name: "machinepool", Description: "Workers are managed by a MachinePool."},
+	}))
+}
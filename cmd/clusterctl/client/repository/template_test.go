@@ -22,6 +22,7 @@ import (
 
 	. "github.com/onsi/gomega"
 
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
 	yaml "sigs.k8s.io/cluster-api/cmd/clusterctl/client/yamlprocessor"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
@@ -116,3 +117,55 @@ func Test_newTemplate(t *testing.T) {
 		})
 	}
 }
+
+func Test_newTemplate_VariableDefinitions(t *testing.T) {
+	rawYaml := []byte("apiVersion: v1\n" +
+		"data:\n" +
+		"  debug: ${DEBUG}\n" +
+		"  replicas: ${REPLICAS}\n" +
+		"  tier: ${TIER}\n" +
+		"kind: ConfigMap\n" +
+		"metadata:\n" +
+		"  name: manager")
+	definitions := []clusterctlv1.VariableDefinition{
+		{Name: "DEBUG", Type: "boolean"},
+		{Name: "REPLICAS", Type: "integer"},
+		{Name: "TIER", Enum: []string{"standard", "premium"}},
+		{Name: "UNUSED", Type: "integer"},
+	}
+
+	t.Run("converts the variables to the canonical format of their type", func(t *testing.T) {
+		g := NewWithT(t)
+
+		got, err := NewTemplate(TemplateInput{
+			RawArtifact:           rawYaml,
+			ConfigVariablesClient: test.NewFakeVariableClient().WithVar("DEBUG", "True").WithVar("REPLICAS", "03").WithVar("TIER", "premium").WithVar("UNUSED", "foo"),
+			Processor:             yaml.NewSimpleProcessor(),
+			TargetNamespace:       "ns1",
+			VariableDefinitions:   definitions,
+		})
+		g.Expect(err).NotTo(HaveOccurred())
+
+		yml, err := got.Yaml()
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(yml).To(ContainSubstring("debug: true"))
+		g.Expect(yml).To(ContainSubstring("replicas: 3"))
+		g.Expect(yml).To(ContainSubstring("tier: premium"))
+	})
+
+	t.Run("reports all the invalid variables at once", func(t *testing.T) {
+		g := NewWithT(t)
+
+		_, err := NewTemplate(TemplateInput{
+			RawArtifact:           rawYaml,
+			ConfigVariablesClient: test.NewFakeVariableClient().WithVar("DEBUG", "maybe").WithVar("REPLICAS", "three").WithVar("TIER", "gold"),
+			Processor:             yaml.NewSimpleProcessor(),
+			TargetNamespace:       "ns1",
+			VariableDefinitions:   definitions,
+		})
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(ContainSubstring(`invalid value for variable "DEBUG": "maybe" is not a boolean`))
+		g.Expect(err.Error()).To(ContainSubstring(`invalid value for variable "REPLICAS": "three" is not an integer`))
+		g.Expect(err.Error()).To(ContainSubstring(`invalid value for variable "TIER": "gold" is not one of standard, premium`))
+	})
+}
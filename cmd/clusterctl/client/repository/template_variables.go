@@ -0,0 +1,109 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+)
+
+// validateVariables validates the values of the template variables against the variable definitions from the
+// provider metadata, and returns the values converted to the canonical format of their type, e.g. `true` for a
+// boolean set to `True` or `1`. Variables without a definition or without a value are not validated.
+// All the invalid variables are reported at once.
+func validateVariables(definitions []clusterctlv1.VariableDefinition, variables []string, get func(string) (string, error)) (map[string]string, error) {
+	used := map[string]bool{}
+	for _, name := range variables {
+		used[name] = true
+	}
+
+	values := map[string]string{}
+	errList := []error{}
+	for _, definition := range definitions {
+		if !used[definition.Name] {
+			continue
+		}
+		value, err := get(definition.Name)
+		if err != nil {
+			// Missing variables are reported by the yaml processor.
+			continue
+		}
+
+		converted, err := convertVariableValue(value, definition.Type)
+		if err != nil {
+			errList = append(errList, errors.Wrapf(err, "invalid value for variable %q", definition.Name))
+			continue
+		}
+		if len(definition.Enum) > 0 && !isAllowedVariableValue(converted, definition) {
+			errList = append(errList, errors.Errorf("invalid value for variable %q: %q is not one of %s", definition.Name, value, strings.Join(definition.Enum, ", ")))
+			continue
+		}
+		values[definition.Name] = converted
+	}
+
+	sort.Slice(errList, func(i, j int) bool {
+		return errList[i].Error() < errList[j].Error()
+	})
+	if err := kerrors.NewAggregate(errList); err != nil {
+		return nil, errors.Wrap(err, "invalid variables")
+	}
+	return values, nil
+}
+
+// convertVariableValue returns the value of a variable in the canonical format of the given type.
+func convertVariableValue(value, valueType string) (string, error) {
+	switch valueType {
+	case "", "string":
+		return value, nil
+	case "integer":
+		i, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return "", errors.Errorf("%q is not an integer", value)
+		}
+		return strconv.FormatInt(i, 10), nil
+	case "number":
+		f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return "", errors.Errorf("%q is not a number", value)
+		}
+		return strconv.FormatFloat(f, 'f', -1, 64), nil
+	case "boolean":
+		b, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return "", errors.Errorf("%q is not a boolean", value)
+		}
+		return strconv.FormatBool(b), nil
+	default:
+		return "", errors.Errorf("unsupported type %q", valueType)
+	}
+}
+
+// isAllowedVariableValue returns true if the converted value of a variable is one of the allowed values.
+func isAllowedVariableValue(value string, definition clusterctlv1.VariableDefinition) bool {
+	for _, allowed := range definition.Enum {
+		if converted, err := convertVariableValue(allowed, definition.Type); err == nil && converted == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"os"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
+	logf "sigs.k8s.io/cluster-api/cmd/clusterctl/log"
+)
+
+// SupportBundleOptions carries the options supported by SupportBundle.
+type SupportBundleOptions struct {
+	// Kubeconfig defines the kubeconfig to use for accessing the management cluster. If empty,
+	// default rules for kubeconfig discovery will be used.
+	Kubeconfig Kubeconfig
+
+	// Namespace where the objects describing the workload clusters exists. If empty, objects
+	// from all the namespaces are collected.
+	Namespace string
+
+	// OutputFile is the path of the tarball the support bundle is written to.
+	OutputFile string
+
+	// LogTailLines defines the number of log lines to collect for each controller container.
+	// If zero, a default value is used; if negative, controller logs are not collected.
+	LogTailLines int64
+}
+
+func (c *clusterctlClient) SupportBundle(options SupportBundleOptions) error {
+	if options.OutputFile == "" {
+		return errors.New("output file for the support bundle must be set")
+	}
+
+	// Get the client for interacting with the management cluster.
+	clusterClient, err := c.clusterClientFactory(ClusterClientFactoryInput{Kubeconfig: options.Kubeconfig})
+	if err != nil {
+		return err
+	}
+
+	// NB. The CAPI contract is not checked here, because the support bundle should be collected
+	// also from management clusters that are in a broken or inconsistent state.
+
+	f, err := os.OpenFile(options.OutputFile, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create support bundle file %q", options.OutputFile)
+	}
+	defer f.Close()
+
+	if err := clusterClient.SupportBundle().Collect(cluster.SupportBundleOptions{
+		Namespace:    options.Namespace,
+		LogTailLines: options.LogTailLines,
+	}, f); err != nil {
+		return err
+	}
+
+	log := logf.Log
+	log.Info("Support bundle saved", "File", options.OutputFile)
+	return nil
+}
@@ -0,0 +1,233 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/repository"
+	yaml "sigs.k8s.io/cluster-api/cmd/clusterctl/client/yamlprocessor"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// TopologyFlavor is the flavor generating a Cluster with a managed topology; if the provider repository does not
+// publish a template for this flavor, the built-in topology template is used.
+const TopologyFlavor = "topology"
+
+// topologyTemplate is the built-in template for the topology flavor; it generates a Cluster with a managed topology
+// referencing an existing ClusterClass, with a single MachineDeployment topology.
+var topologyTemplate = []byte(`apiVersion: cluster.x-k8s.io/v1alpha4
+kind: Cluster
+metadata:
+  name: ${CLUSTER_NAME}
+  namespace: ${NAMESPACE}
+spec:
+  topology:
+    class: ${CLUSTER_CLASS}
+    version: ${KUBERNETES_VERSION}
+    controlPlane:
+      replicas: ${CONTROL_PLANE_MACHINE_COUNT}
+    workers:
+      machineDeployments:
+      - class: ${WORKER_MACHINE_DEPLOYMENT_CLASS}
+        name: md-0
+        replicas: ${WORKER_MACHINE_COUNT}
+`)
+
+// topologyVariableDefinitions are the definitions of the variables used by the built-in topology template; they are
+// used to describe the variables when listing them and to validate the values provided before generating the template.
+var topologyVariableDefinitions = []clusterctlv1.VariableDefinition{
+	{Name: "CLUSTER_CLASS", Description: "Name of the ClusterClass the Cluster topology is based on."},
+	{Name: "WORKER_MACHINE_DEPLOYMENT_CLASS", Description: "Name of the MachineDeployment class, as defined in the ClusterClass, used for the workers."},
+	{Name: "CONTROL_PLANE_MACHINE_COUNT", Type: "integer", Description: "Number of control plane machines."},
+	{Name: "WORKER_MACHINE_COUNT", Type: "integer", Description: "Number of worker machines."},
+}
+
+// hasFlavor returns true if the provider repository publishes a template for the given flavor.
+func hasFlavor(templates repository.TemplateClient, flavor string) (bool, error) {
+	flavors, err := templates.ListFlavors()
+	if err != nil {
+		return false, err
+	}
+	for _, f := range flavors {
+		if f.Name == flavor {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// getTopologyTemplate returns the built-in template for the topology flavor.
+func getTopologyTemplate(configVariablesClient config.VariablesClient, processor yaml.Processor, targetNamespace string, listVariablesOnly bool) (Template, error) {
+	if processor == nil {
+		processor = yaml.NewSimpleProcessor()
+	}
+	return repository.NewTemplate(repository.TemplateInput{
+		RawArtifact:           topologyTemplate,
+		ConfigVariablesClient: configVariablesClient,
+		Processor:             processor,
+		TargetNamespace:       targetNamespace,
+		SkipTemplateProcess:   listVariablesOnly,
+		VariableDefinitions:   topologyVariableDefinitions,
+	})
+}
+
+// clusterClassGetter returns the ClusterClass with the given namespace and name, or nil if it does not exist.
+type clusterClassGetter func(namespace, name string) (*clusterv1.ClusterClass, error)
+
+// errManagementClusterNotReachable is returned by a clusterClassGetter when the management cluster can't be reached,
+// e.g. when generating a template without a management cluster; the checks depending on the ClusterClass are then skipped.
+var errManagementClusterNotReachable = errors.New("management cluster not reachable")
+
+// clusterClassFromManagementCluster returns a clusterClassGetter reading ClusterClasses from the management cluster.
+// The management cluster is contacted only when a ClusterClass is required, so templates without a managed topology
+// do not wait for an unreachable cluster.
+func clusterClassFromManagementCluster(proxy cluster.Proxy) clusterClassGetter {
+	var c client.Client
+	var clientErr error
+	initialized := false
+	return func(namespace, name string) (*clusterv1.ClusterClass, error) {
+		if !initialized {
+			initialized = true
+			if clientErr = proxy.ValidateKubernetesVersion(); clientErr == nil {
+				c, clientErr = proxy.NewClient()
+			}
+		}
+		if clientErr != nil {
+			return nil, errManagementClusterNotReachable
+		}
+
+		class := &clusterv1.ClusterClass{}
+		if err := c.Get(context.TODO(), client.ObjectKey{Namespace: namespace, Name: name}, class); err != nil {
+			if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+				return nil, nil
+			}
+			return nil, errors.Wrapf(err, "failed to read ClusterClass %s/%s from the management cluster", namespace, name)
+		}
+		return class, nil
+	}
+}
+
+// validateClusterTopology validates the Clusters with a managed topology defined in a workload cluster template
+// against the ClusterClass they reference. The ClusterClass is read from the template itself, if included, otherwise
+// using getClusterClass; if getClusterClass is nil, or the management cluster is not reachable, only the checks not
+// depending on the ClusterClass are run.
+func validateClusterTopology(objs []unstructured.Unstructured, getClusterClass clusterClassGetter) error {
+	classes := map[string]*clusterv1.ClusterClass{}
+	clusters := []*clusterv1.Cluster{}
+	for i := range objs {
+		obj := objs[i]
+		if obj.GroupVersionKind().GroupVersion() != clusterv1.GroupVersion {
+			continue
+		}
+		switch obj.GetKind() {
+		case "ClusterClass":
+			class := &clusterv1.ClusterClass{}
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, class); err != nil {
+				return errors.Wrapf(err, "failed to convert ClusterClass %s/%s", obj.GetNamespace(), obj.GetName())
+			}
+			classes[class.Namespace+"/"+class.Name] = class
+		case "Cluster":
+			cluster := &clusterv1.Cluster{}
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, cluster); err != nil {
+				return errors.Wrapf(err, "failed to convert Cluster %s/%s", obj.GetNamespace(), obj.GetName())
+			}
+			if cluster.Spec.Topology != nil {
+				clusters = append(clusters, cluster)
+			}
+		}
+	}
+
+	var errList []error
+	for _, cluster := range clusters {
+		topology := cluster.Spec.Topology
+		if topology.Class == "" {
+			errList = append(errList, errors.Errorf("Cluster %s/%s: spec.topology.class must be set", cluster.Namespace, cluster.Name))
+			continue
+		}
+		if topology.Version == "" {
+			errList = append(errList, errors.Errorf("Cluster %s/%s: spec.topology.version must be set", cluster.Namespace, cluster.Name))
+		}
+		if topology.ControlPlane.Replicas < 0 {
+			errList = append(errList, errors.Errorf("Cluster %s/%s: spec.topology.controlPlane.replicas must be greater than or equal to 0", cluster.Namespace, cluster.Name))
+		}
+
+		class, ok := classes[cluster.Namespace+"/"+topology.Class]
+		if !ok {
+			if getClusterClass == nil {
+				continue
+			}
+			var err error
+			class, err = getClusterClass(cluster.Namespace, topology.Class)
+			if errors.Is(err, errManagementClusterNotReachable) {
+				continue
+			}
+			if err != nil {
+				errList = append(errList, err)
+				continue
+			}
+			if class == nil {
+				errList = append(errList, errors.Errorf("Cluster %s/%s: ClusterClass %q does not exist in the template nor in the management cluster", cluster.Namespace, cluster.Name, topology.Class))
+				continue
+			}
+		}
+
+		if topology.Workers == nil {
+			continue
+		}
+		mdClasses := map[string]bool{}
+		for _, mdClass := range class.Spec.Workers.MachineDeployments {
+			mdClasses[mdClass.Class] = true
+		}
+		mdNames := map[string]bool{}
+		for _, md := range topology.Workers.MachineDeployments {
+			if !mdClasses[md.Class] {
+				errList = append(errList, errors.Errorf("Cluster %s/%s: MachineDeployment topology %q uses class %q, which is not defined in ClusterClass %q", cluster.Namespace, cluster.Name, md.Name, md.Class, class.Name))
+			}
+			if mdNames[md.Name] {
+				errList = append(errList, errors.Errorf("Cluster %s/%s: MachineDeployment topology name %q is used more than once", cluster.Namespace, cluster.Name, md.Name))
+			}
+			mdNames[md.Name] = true
+		}
+
+		mpClasses := map[string]bool{}
+		for _, mpClass := range class.Spec.Workers.MachinePools {
+			mpClasses[mpClass.Class] = true
+		}
+		mpNames := map[string]bool{}
+		for _, mp := range topology.Workers.MachinePools {
+			if !mpClasses[mp.Class] {
+				errList = append(errList, errors.Errorf("Cluster %s/%s: MachinePool topology %q uses class %q, which is not defined in ClusterClass %q", cluster.Namespace, cluster.Name, mp.Name, mp.Class, class.Name))
+			}
+			if mpNames[mp.Name] {
+				errList = append(errList, errors.Errorf("Cluster %s/%s: MachinePool topology name %q is used more than once", cluster.Namespace, cluster.Name, mp.Name))
+			}
+			mpNames[mp.Name] = true
+		}
+	}
+	return kerrors.NewAggregate(errList)
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+)
+
+func Test_validateClusterTopology(t *testing.T) {
+	class := &clusterv1.ClusterClass{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: clusterv1.GroupVersion.String(),
+			Kind:       "ClusterClass",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns1",
+			Name:      "class1",
+		},
+		Spec: clusterv1.ClusterClassSpec{
+			Workers: clusterv1.WorkersClass{
+				MachineDeployments: []clusterv1.MachineDeploymentClass{
+					{Class: "default-worker"},
+				},
+				MachinePools: []clusterv1.MachinePoolClass{
+					{Class: "default-pool"},
+				},
+			},
+		},
+	}
+
+	newCluster := func(class string, mdClasses ...string) *clusterv1.Cluster {
+		c := &clusterv1.Cluster{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: clusterv1.GroupVersion.String(),
+				Kind:       "Cluster",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "ns1",
+				Name:      "cluster1",
+			},
+			Spec: clusterv1.ClusterSpec{
+				Topology: &clusterv1.Topology{
+					Class:   class,
+					Version: "v1.21.2",
+					ControlPlane: clusterv1.ControlPlaneTopology{
+						Replicas: 1,
+					},
+				},
+			},
+		}
+		if len(mdClasses) > 0 {
+			c.Spec.Topology.Workers = &clusterv1.WorkersTopology{}
+			for i, mdClass := range mdClasses {
+				c.Spec.Topology.Workers.MachineDeployments = append(c.Spec.Topology.Workers.MachineDeployments, clusterv1.MachineDeploymentTopology{
+					Class: mdClass,
+					Name:  fmt.Sprintf("md%d", i),
+				})
+			}
+		}
+		return c
+	}
+
+	getClusterClass := func(namespace, name string) (*clusterv1.ClusterClass, error) {
+		if namespace == class.Namespace && name == class.Name {
+			return class, nil
+		}
+		return nil, nil
+	}
+
+	tests := []struct {
+		name            string
+		objs            []runtime.Object
+		getClusterClass clusterClassGetter
+		wantErr         bool
+	}{
+		{
+			name:    "pass if there are no Clusters with a managed topology",
+			objs:    []runtime.Object{class},
+			wantErr: false,
+		},
+		{
+			name:    "pass if the ClusterClass is included in the template",
+			objs:    []runtime.Object{class, newCluster("class1", "default-worker")},
+			wantErr: false,
+		},
+		{
+			name:            "pass if the ClusterClass exists in the management cluster",
+			objs:            []runtime.Object{newCluster("class1", "default-worker")},
+			getClusterClass: getClusterClass,
+			wantErr:         false,
+		},
+		{
+			name:    "pass if the ClusterClass can't be read because there is no management cluster",
+			objs:    []runtime.Object{newCluster("class1", "default-worker")},
+			wantErr: false,
+		},
+		{
+			name: "pass if the ClusterClass can't be read because the management cluster is not reachable",
+			objs: []runtime.Object{newCluster("class1", "default-worker")},
+			getClusterClass: func(namespace, name string) (*clusterv1.ClusterClass, error) {
+				return nil, errManagementClusterNotReachable
+			},
+			wantErr: false,
+		},
+		{
+			name:            "fail if the ClusterClass does not exist",
+			objs:            []runtime.Object{newCluster("class2")},
+			getClusterClass: getClusterClass,
+			wantErr:         true,
+		},
+		{
+			name:    "fail if a MachineDeployment topology uses a class not defined in the ClusterClass",
+			objs:    []runtime.Object{class, newCluster("class1", "default-worker", "gpu-worker")},
+			wantErr: true,
+		},
+		{
+			name: "pass if the MachinePool topologies use classes defined in the ClusterClass",
+			objs: func() []runtime.Object {
+				c := newCluster("class1", "default-worker")
+				c.Spec.Topology.Workers.MachinePools = []clusterv1.MachinePoolTopology{{Class: "default-pool", Name: "mp0"}}
+				return []runtime.Object{class, c}
+			}(),
+			wantErr: false,
+		},
+		{
+			name: "fail if a MachinePool topology uses a class not defined in the ClusterClass",
+			objs: func() []runtime.Object {
+				c := newCluster("class1", "default-worker")
+				c.Spec.Topology.Workers.MachinePools = []clusterv1.MachinePoolTopology{{Class: "gpu-pool", Name: "mp0"}}
+				return []runtime.Object{class, c}
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "fail if the topology version is not set",
+			objs: func() []runtime.Object {
+				c := newCluster("class1")
+				c.Spec.Topology.Version = ""
+				return []runtime.Object{class, c}
+			}(),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			objs := []unstructured.Unstructured{}
+			for _, o := range tt.objs {
+				u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(o)
+				g.Expect(err).NotTo(HaveOccurred())
+				objs = append(objs, unstructured.Unstructured{Object: u})
+			}
+
+			err := validateClusterTopology(objs, tt.getClusterClass)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+		})
+	}
+}
@@ -17,7 +17,12 @@ limitations under the License.
 package client
 
 import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -25,8 +30,14 @@ import (
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
+	logf "sigs.k8s.io/cluster-api/cmd/clusterctl/log"
+	"sigs.k8s.io/yaml"
 )
 
+// upgradeBackupDirectoryVariable is the clusterctl configuration variable defining the directory used for
+// backing up the management cluster before applying an upgrade, if not specified in ApplyUpgradeOptions.
+const upgradeBackupDirectoryVariable = "CLUSTERCTL_UPGRADE_BACKUP_DIRECTORY"
+
 // PlanUpgradeOptions carries the options supported by upgrade plan.
 type PlanUpgradeOptions struct {
 	// Kubeconfig defines the kubeconfig to use for accessing the management cluster. If empty, default discovery rules apply.
@@ -100,6 +111,26 @@ type ApplyUpgradeOptions struct {
 
 	// InfrastructureProviders instance and versions (e.g. capa-system/aws:v0.5.0) to upgrade to. This field can be used as alternative to Contract.
 	InfrastructureProviders []string
+
+	// BackupDirectory defines the local directory where the provider inventory and all the Cluster API objects existing
+	// in the management cluster are saved before applying the upgrade. If empty, the CLUSTERCTL_UPGRADE_BACKUP_DIRECTORY
+	// variable is used, if defined; otherwise no backup is taken.
+	// NOTE: The backup includes the Secrets existing in the management cluster, e.g. the workload cluster kubeconfigs
+	// and certificate authorities, stored in plaintext.
+	BackupDirectory string
+
+	// Phases defines the installation phases to execute, e.g. crds for upgrading only the CustomResourceDefinitions
+	// of the providers, or components for upgrading all the other provider components. If unspecified, all the phases
+	// are executed.
+	Phases []string
+
+	// SkipProviders defines the providers (e.g. capa-system/aws) to be held back at their current version while
+	// upgrading by Contract; the current version of each skipped provider must support the target contract.
+	SkipProviders []string
+
+	// DryRun reports the provider components that would be added, changed or removed by the upgrade, comparing
+	// the components of the target versions with the ones installed in the management cluster, without changing anything.
+	DryRun bool
 }
 
 func (c *clusterctlClient) ApplyUpgrade(options ApplyUpgradeOptions) error {
@@ -107,6 +138,12 @@ func (c *clusterctlClient) ApplyUpgrade(options ApplyUpgradeOptions) error {
 		return errors.Errorf("current version of clusterctl could only upgrade to %s contract, requested %s", clusterv1.GroupVersion.Version, options.Contract)
 	}
 
+	installOptions, err := parseInstallPhases(options.Phases)
+	if err != nil {
+		return err
+	}
+	installOptions.DryRun = options.DryRun
+
 	// Get the client for interacting with the management cluster.
 	clusterClient, err := c.clusterClientFactory(ClusterClientFactoryInput{Kubeconfig: options.Kubeconfig})
 	if err != nil {
@@ -119,17 +156,28 @@ func (c *clusterctlClient) ApplyUpgrade(options ApplyUpgradeOptions) error {
 	}
 
 	// Ensures the custom resource definitions required by clusterctl are in place.
-	if err := clusterClient.ProviderInventory().EnsureCustomResourceDefinitions(); err != nil {
-		return err
+	if !options.DryRun {
+		if err := clusterClient.ProviderInventory().EnsureCustomResourceDefinitions(); err != nil {
+			return err
+		}
+	}
+
+	// Backs up the management cluster before mutating providers, if requested, so it is possible to recover from a failed upgrade.
+	if backupDirectory := c.upgradeBackupDirectory(options); backupDirectory != "" && !options.DryRun {
+		if err := backupBeforeUpgrade(clusterClient, backupDirectory); err != nil {
+			return errors.Wrap(err, "failed to back up the management cluster before the upgrade")
+		}
 	}
 
 	// Ensures the latest version of cert-manager.
 	// NOTE: it is safe to upgrade to latest version of cert-manager given that it provides
 	// conversion web-hooks around Issuer/Certificate kinds, so installing an older versions of providers
 	// should continue to work with the latest cert-manager.
-	certManager := clusterClient.CertManager()
-	if err := certManager.EnsureLatestVersion(); err != nil {
-		return err
+	if !options.DryRun {
+		certManager := clusterClient.CertManager()
+		if err := certManager.EnsureLatestVersion(); err != nil {
+			return err
+		}
 	}
 
 	// Check if the user want a custom upgrade
@@ -140,6 +188,10 @@ func (c *clusterctlClient) ApplyUpgrade(options ApplyUpgradeOptions) error {
 
 	// If we are upgrading a specific set of providers only, process the providers and call ApplyCustomPlan.
 	if isCustomUpgrade {
+		if len(options.SkipProviders) > 0 {
+			return errors.New("skipping providers is supported only when upgrading by contract; providers not listed are not upgraded")
+		}
+
 		// Converts upgrade references back into an UpgradeItem.
 		upgradeItems := []cluster.UpgradeItem{}
 
@@ -163,11 +215,60 @@ func (c *clusterctlClient) ApplyUpgrade(options ApplyUpgradeOptions) error {
 		}
 
 		// Execute the upgrade using the custom upgrade items
-		return clusterClient.ProviderUpgrader().ApplyCustomPlan(upgradeItems...)
+		return clusterClient.ProviderUpgrader().ApplyCustomPlan(installOptions, upgradeItems...)
 	}
 
 	// Otherwise we are upgrading a whole management cluster according to a clusterctl generated upgrade plan.
-	return clusterClient.ProviderUpgrader().ApplyPlan(options.Contract)
+	return clusterClient.ProviderUpgrader().ApplyPlan(installOptions, options.Contract, options.SkipProviders...)
+}
+
+// upgradeBackupDirectory returns the directory where the management cluster is backed up before applying an upgrade,
+// or an empty string if no backup is requested.
+func (c *clusterctlClient) upgradeBackupDirectory(options ApplyUpgradeOptions) string {
+	if options.BackupDirectory != "" {
+		return options.BackupDirectory
+	}
+	if v, err := c.configClient.Variables().Get(upgradeBackupDirectoryVariable); err == nil {
+		return v
+	}
+	return ""
+}
+
+// backupBeforeUpgrade saves the provider inventory and all the Cluster API objects existing in the management cluster
+// into a new timestamped sub directory of directory.
+// NOTE: the objects are saved in a separated "objects" directory, so it can be used with clusterctl restore.
+// NOTE: the backup includes Secrets in plaintext, so the directories are readable only by the current user.
+func backupBeforeUpgrade(clusterClient cluster.Client, directory string) error {
+	log := logf.Log
+
+	backupDirectory := filepath.Join(directory, fmt.Sprintf("upgrade-%s", time.Now().UTC().Format("20060102-150405")))
+	objectsDirectory := filepath.Join(backupDirectory, "objects")
+	if err := os.MkdirAll(objectsDirectory, 0700); err != nil {
+		return errors.Wrapf(err, "failed to create backup directory %s", objectsDirectory)
+	}
+	log.Info("Backing up the management cluster", "Directory", backupDirectory)
+	log.Info("Warning: the backup contains the management cluster Secrets in plaintext; store it securely and delete it when no longer required")
+
+	providers, err := clusterClient.ProviderInventory().List()
+	if err != nil {
+		return err
+	}
+	providers.SetGroupVersionKind(clusterctlv1.GroupVersion.WithKind("ProviderList"))
+	inventory, err := yaml.Marshal(providers)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the provider inventory")
+	}
+	if err := ioutil.WriteFile(filepath.Join(backupDirectory, "inventory.yaml"), inventory, 0600); err != nil {
+		return errors.Wrap(err, "failed to write the provider inventory")
+	}
+
+	// Saves the objects from all the namespaces.
+	if err := clusterClient.ObjectMover().Backup("", objectsDirectory); err != nil {
+		return err
+	}
+
+	log.Info(fmt.Sprintf("Management cluster backed up; to restore the Cluster API objects run: clusterctl restore --directory %s", objectsDirectory))
+	return nil
 }
 
 func addUpgradeItems(upgradeItems []cluster.UpgradeItem, providerType clusterctlv1.ProviderType, providers ...string) ([]cluster.UpgradeItem, error) {
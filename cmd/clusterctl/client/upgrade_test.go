@@ -17,11 +17,15 @@ limitations under the License.
 package client
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"sort"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
@@ -29,6 +33,7 @@ import (
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+	"sigs.k8s.io/yaml"
 )
 
 func Test_clusterctlClient_PlanCertUpgrade(t *testing.T) {
@@ -449,3 +454,88 @@ func Test_parseUpgradeItem(t *testing.T) {
 		})
 	}
 }
+
+func Test_clusterctlClient_upgradeBackupDirectory(t *testing.T) {
+	tests := []struct {
+		name    string
+		client  *fakeClient
+		options ApplyUpgradeOptions
+		want    string
+	}{
+		{
+			name:    "backup directory from the options",
+			client:  newFakeClient(newFakeConfig().WithVar(upgradeBackupDirectoryVariable, "/tmp/from-variable")),
+			options: ApplyUpgradeOptions{BackupDirectory: "/tmp/from-options"},
+			want:    "/tmp/from-options",
+		},
+		{
+			name:    "backup directory from the clusterctl configuration",
+			client:  newFakeClient(newFakeConfig().WithVar(upgradeBackupDirectoryVariable, "/tmp/from-variable")),
+			options: ApplyUpgradeOptions{},
+			want:    "/tmp/from-variable",
+		},
+		{
+			name:    "no backup by default",
+			client:  newFakeClient(newFakeConfig()),
+			options: ApplyUpgradeOptions{},
+			want:    "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			g.Expect(tt.client.internalClient.upgradeBackupDirectory(tt.options)).To(Equal(tt.want))
+		})
+	}
+}
+
+func Test_backupBeforeUpgrade(t *testing.T) {
+	g := NewWithT(t)
+
+	dir, err := ioutil.TempDir("", "clusterctl")
+	g.Expect(err).NotTo(HaveOccurred())
+	defer os.RemoveAll(dir)
+
+	cluster1 := newFakeCluster(cluster.Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"}, newFakeConfig()).
+		WithProviderInventory("cluster-api", clusterctlv1.CoreProviderType, "v1.0.0", "cluster-api-system").
+		WithObjectMover(&fakeObjectMover{})
+
+	g.Expect(backupBeforeUpgrade(cluster1, dir)).To(Succeed())
+
+	backups, err := ioutil.ReadDir(dir)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(backups).To(HaveLen(1))
+	g.Expect(backups[0].Name()).To(HavePrefix("upgrade-"))
+
+	backupDir := filepath.Join(dir, backups[0].Name())
+	g.Expect(filepath.Join(backupDir, "objects")).To(BeADirectory())
+
+	// The backup contains Secrets, so it must be readable only by the current user.
+	g.Expect(backups[0].Mode().Perm() & 0077).To(BeZero())
+	inventoryInfo, err := os.Stat(filepath.Join(backupDir, "inventory.yaml"))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(inventoryInfo.Mode().Perm() & 0077).To(BeZero())
+
+	inventory, err := ioutil.ReadFile(filepath.Join(backupDir, "inventory.yaml"))
+	g.Expect(err).NotTo(HaveOccurred())
+	providers := &clusterctlv1.ProviderList{}
+	g.Expect(yaml.Unmarshal(inventory, providers)).To(Succeed())
+	g.Expect(providers.Kind).To(Equal("ProviderList"))
+	g.Expect(providers.Items).To(HaveLen(1))
+	g.Expect(providers.Items[0].ProviderName).To(Equal("cluster-api"))
+}
+
+func Test_backupBeforeUpgradeFails(t *testing.T) {
+	g := NewWithT(t)
+
+	dir, err := ioutil.TempDir("", "clusterctl")
+	g.Expect(err).NotTo(HaveOccurred())
+	defer os.RemoveAll(dir)
+
+	cluster1 := newFakeCluster(cluster.Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"}, newFakeConfig()).
+		WithProviderInventory("cluster-api", clusterctlv1.CoreProviderType, "v1.0.0", "cluster-api-system").
+		WithObjectMover(&fakeObjectMover{backupErr: errors.New("backup failed")})
+
+	g.Expect(backupBeforeUpgrade(cluster1, dir)).NotTo(Succeed())
+}
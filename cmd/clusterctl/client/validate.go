@@ -0,0 +1,317 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"sort"
+
+	"github.com/pkg/errors"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	logf "sigs.k8s.io/cluster-api/cmd/clusterctl/log"
+	utilyaml "sigs.k8s.io/cluster-api/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ValidateOptions carries the options supported by Validate.
+type ValidateOptions struct {
+	// Kubeconfig defines the kubeconfig to use for accessing the management cluster. If empty,
+	// default rules for kubeconfig discovery will be used.
+	Kubeconfig Kubeconfig
+
+	// File is the path of the file with the rendered cluster template to validate.
+	File string
+
+	// SkipServerDryRun skips submitting the objects to the management cluster in dry-run mode, so only
+	// the structural validation against the CRD schemas is performed and admission webhooks are not invoked.
+	SkipServerDryRun bool
+}
+
+// ValidationIssue describes a problem found while validating an object of a cluster template.
+type ValidationIssue struct {
+	// Object identifies the object with the problem, e.g. Cluster/default/my-cluster.
+	Object string
+
+	// Field is the path of the field with the problem, if any, e.g. spec.clusterNetwork.pods.
+	Field string
+
+	// Message describes the problem.
+	Message string
+}
+
+// String returns a human readable representation of the ValidationIssue.
+func (i ValidationIssue) String() string {
+	if i.Field == "" {
+		return fmt.Sprintf("%s: %s", i.Object, i.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", i.Object, i.Field, i.Message)
+}
+
+func (c *clusterctlClient) Validate(options ValidateOptions) ([]ValidationIssue, error) {
+	log := logf.Log
+
+	rawYAML, err := ioutil.ReadFile(options.File)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %q", options.File)
+	}
+	objs, err := utilyaml.ToUnstructured(rawYAML)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %q", options.File)
+	}
+
+	// Get the client for interacting with the management cluster.
+	clusterClient, err := c.clusterClientFactory(ClusterClientFactoryInput{Kubeconfig: options.Kubeconfig})
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure this command only runs against management clusters with the current Cluster API contract.
+	if err := clusterClient.ProviderInventory().CheckCAPIContract(); err != nil {
+		return nil, err
+	}
+
+	c2, err := clusterClient.Proxy().NewClient()
+	if err != nil {
+		return nil, err
+	}
+
+	crds := &apiextensionsv1.CustomResourceDefinitionList{}
+	if err := c2.List(context.TODO(), crds); err != nil {
+		return nil, errors.Wrap(err, "failed to list the CustomResourceDefinitions installed in the management cluster")
+	}
+
+	issues := []ValidationIssue{}
+	for i := range objs {
+		obj := &objs[i]
+		issues = append(issues, validateObjectSchema(obj, crds.Items)...)
+
+		if options.SkipServerDryRun {
+			continue
+		}
+		dryRunIssues, err := validateObjectDryRun(c2, obj)
+		if err != nil {
+			log.V(1).Info("Skipping server dry-run", "Object", objectID(obj), "Reason", err.Error())
+			continue
+		}
+		issues = append(issues, dryRunIssues...)
+	}
+	return issues, nil
+}
+
+// objectID returns a string identifying an object in the ValidationIssues.
+func objectID(obj *unstructured.Unstructured) string {
+	if obj.GetNamespace() == "" {
+		return fmt.Sprintf("%s/%s", obj.GetKind(), obj.GetName())
+	}
+	return fmt.Sprintf("%s/%s/%s", obj.GetKind(), obj.GetNamespace(), obj.GetName())
+}
+
+// validateObjectSchema validates an object against the OpenAPI schema of the corresponding CRD, if any.
+// Objects not defined by a CRD, e.g. Secrets or ConfigMaps, are not validated.
+func validateObjectSchema(obj *unstructured.Unstructured, crds []apiextensionsv1.CustomResourceDefinition) []ValidationIssue {
+	gvk := obj.GroupVersionKind()
+	for i := range crds {
+		crd := &crds[i]
+		if crd.Spec.Group != gvk.Group || crd.Spec.Names.Kind != gvk.Kind {
+			continue
+		}
+		for _, version := range crd.Spec.Versions {
+			if version.Name != gvk.Version {
+				continue
+			}
+			if !version.Served {
+				return []ValidationIssue{{Object: objectID(obj), Message: fmt.Sprintf("version %s of %s is not served by the management cluster", gvk.Version, crd.Name)}}
+			}
+			if version.Schema == nil || version.Schema.OpenAPIV3Schema == nil {
+				return nil
+			}
+			var issues []ValidationIssue
+			for _, err := range validateSchema("", obj.Object, version.Schema.OpenAPIV3Schema, true) {
+				issues = append(issues, ValidationIssue{Object: objectID(obj), Field: err.field, Message: err.message})
+			}
+			return issues
+		}
+		return []ValidationIssue{{Object: objectID(obj), Message: fmt.Sprintf("version %s is not defined in %s", gvk.Version, crd.Name)}}
+	}
+	return nil
+}
+
+// validateObjectDryRun submits an object to the management cluster in dry-run mode, so the API server validates
+// it and invokes the admission webhooks without persisting it. An error is returned if the dry-run cannot
+// be performed, e.g. because the object already exists or its namespace does not exist.
+func validateObjectDryRun(c client.Client, obj *unstructured.Unstructured) ([]ValidationIssue, error) {
+	err := c.Create(context.TODO(), obj.DeepCopy(), client.DryRunAll)
+	if err == nil {
+		return nil, nil
+	}
+	if meta.IsNoMatchError(err) {
+		return []ValidationIssue{{Object: objectID(obj), Message: fmt.Sprintf("%s is not installed in the management cluster", obj.GroupVersionKind())}}, nil
+	}
+	if apierrors.IsAlreadyExists(err) || apierrors.IsNotFound(err) {
+		return nil, err
+	}
+
+	statusErr := &apierrors.StatusError{}
+	if errors.As(err, &statusErr) && statusErr.ErrStatus.Details != nil && len(statusErr.ErrStatus.Details.Causes) > 0 {
+		issues := []ValidationIssue{}
+		for _, cause := range statusErr.ErrStatus.Details.Causes {
+			issues = append(issues, ValidationIssue{Object: objectID(obj), Field: cause.Field, Message: cause.Message})
+		}
+		return issues, nil
+	}
+	return []ValidationIssue{{Object: objectID(obj), Message: err.Error()}}, nil
+}
+
+// schemaError is an error found by validateSchema.
+type schemaError struct {
+	field   string
+	message string
+}
+
+// validateSchema validates a value against a structural OpenAPI schema, reporting unknown fields, which are
+// otherwise silently pruned by the API server, missing required fields, values of the wrong type and values
+// not included in an enum. Null values are ignored, because they are dropped by the API server as well.
+func validateSchema(path string, value interface{}, s *apiextensionsv1.JSONSchemaProps, isRoot bool) []schemaError {
+	if s == nil || value == nil || s.XIntOrString {
+		return nil
+	}
+
+	var errs []schemaError
+	if len(s.Enum) > 0 && !enumContains(s.Enum, value) {
+		errs = append(errs, schemaError{field: path, message: fmt.Sprintf("unsupported value %v", value)})
+	}
+
+	switch s.Type {
+	case "object":
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return append(errs, schemaError{field: path, message: "must be an object"})
+		}
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			field := joinFieldPath(path, k)
+			if prop, ok := s.Properties[k]; ok {
+				prop := prop
+				// NB. metadata is validated by the API server itself.
+				if isRoot && k == "metadata" {
+					continue
+				}
+				errs = append(errs, validateSchema(field, m[k], &prop, false)...)
+				continue
+			}
+			if isRoot && (k == "apiVersion" || k == "kind" || k == "metadata") {
+				continue
+			}
+			if s.XEmbeddedResource && (k == "apiVersion" || k == "kind" || k == "metadata") {
+				continue
+			}
+			if s.AdditionalProperties != nil {
+				if s.AdditionalProperties.Schema != nil {
+					errs = append(errs, validateSchema(field, m[k], s.AdditionalProperties.Schema, false)...)
+					continue
+				}
+				if s.AdditionalProperties.Allows {
+					continue
+				}
+			}
+			if s.XPreserveUnknownFields != nil && *s.XPreserveUnknownFields {
+				continue
+			}
+			errs = append(errs, schemaError{field: field, message: "unknown field"})
+		}
+		for _, required := range s.Required {
+			if _, ok := m[required]; !ok {
+				errs = append(errs, schemaError{field: joinFieldPath(path, required), message: "required value"})
+			}
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return append(errs, schemaError{field: path, message: "must be an array"})
+		}
+		if s.Items == nil || s.Items.Schema == nil {
+			return errs
+		}
+		for i, item := range items {
+			errs = append(errs, validateSchema(fmt.Sprintf("%s[%d]", path, i), item, s.Items.Schema, false)...)
+		}
+	case "string":
+		if _, ok := value.(string); !ok {
+			errs = append(errs, schemaError{field: path, message: "must be a string"})
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			errs = append(errs, schemaError{field: path, message: "must be a boolean"})
+		}
+	case "integer":
+		if !isInteger(value) {
+			errs = append(errs, schemaError{field: path, message: "must be an integer"})
+		}
+	case "number":
+		switch value.(type) {
+		case int, int32, int64, float32, float64:
+		default:
+			errs = append(errs, schemaError{field: path, message: "must be a number"})
+		}
+	}
+	return errs
+}
+
+// joinFieldPath appends a field to a field path.
+func joinFieldPath(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
+
+// isInteger returns true if value is an integer; integral float64 values are considered integers,
+// because numbers are decoded as float64 when parsing YAML into unstructured objects.
+func isInteger(value interface{}) bool {
+	switch v := value.(type) {
+	case int, int32, int64:
+		return true
+	case float64:
+		return v == math.Trunc(v)
+	}
+	return false
+}
+
+// enumContains returns true if the JSON representation of value is included in enum.
+func enumContains(enum []apiextensionsv1.JSON, value interface{}) bool {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return false
+	}
+	for _, e := range enum {
+		if string(e.Raw) == string(raw) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,189 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
+)
+
+func fakeInfrastructureClusterSchema() *apiextensionsv1.JSONSchemaProps {
+	return &apiextensionsv1.JSONSchemaProps{
+		Type: "object",
+		Properties: map[string]apiextensionsv1.JSONSchemaProps{
+			"apiVersion": {Type: "string"},
+			"kind":       {Type: "string"},
+			"metadata":   {Type: "object"},
+			"spec": {
+				Type:     "object",
+				Required: []string{"region"},
+				Properties: map[string]apiextensionsv1.JSONSchemaProps{
+					"region": {Type: "string"},
+					"size": {
+						Type: "string",
+						Enum: []apiextensionsv1.JSON{{Raw: []byte(`"small"`)}, {Raw: []byte(`"large"`)}},
+					},
+					"replicas": {Type: "integer"},
+					"tags": {
+						Type:                 "object",
+						AdditionalProperties: &apiextensionsv1.JSONSchemaPropsOrBool{Schema: &apiextensionsv1.JSONSchemaProps{Type: "string"}},
+					},
+					"extra": {
+						Type:                   "object",
+						XPreserveUnknownFields: pointer.BoolPtr(true),
+					},
+					"subnets": {
+						Type: "array",
+						Items: &apiextensionsv1.JSONSchemaPropsOrArray{Schema: &apiextensionsv1.JSONSchemaProps{
+							Type:       "object",
+							Properties: map[string]apiextensionsv1.JSONSchemaProps{"cidr": {Type: "string"}},
+						}},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestValidateSchema(t *testing.T) {
+	tests := []struct {
+		name string
+		spec map[string]interface{}
+		want []schemaError
+	}{
+		{
+			name: "valid object",
+			spec: map[string]interface{}{
+				"region":   "eu",
+				"size":     "small",
+				"replicas": float64(3),
+				"tags":     map[string]interface{}{"owner": "foo"},
+				"extra":    map[string]interface{}{"anything": map[string]interface{}{"goes": true}},
+				"subnets":  []interface{}{map[string]interface{}{"cidr": "10.0.0.0/24"}},
+			},
+			want: nil,
+		},
+		{
+			name: "unknown fields",
+			spec: map[string]interface{}{
+				"region":  "eu",
+				"regoin":  "eu",
+				"subnets": []interface{}{map[string]interface{}{"cdir": "10.0.0.0/24"}},
+			},
+			want: []schemaError{
+				{field: "spec.regoin", message: "unknown field"},
+				{field: "spec.subnets[0].cdir", message: "unknown field"},
+			},
+		},
+		{
+			name: "missing required field, wrong types and unsupported enum value",
+			spec: map[string]interface{}{
+				"size":     "medium",
+				"replicas": 1.5,
+				"tags":     map[string]interface{}{"owner": float64(1)},
+			},
+			want: []schemaError{
+				{field: "spec.replicas", message: "must be an integer"},
+				{field: "spec.size", message: "unsupported value medium"},
+				{field: "spec.tags.owner", message: "must be a string"},
+				{field: "spec.region", message: "required value"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			obj := map[string]interface{}{
+				"apiVersion": "infrastructure.cluster.x-k8s.io/v1alpha4",
+				"kind":       "GenericInfrastructureCluster",
+				"metadata":   map[string]interface{}{"name": "foo", "unknown": "ignored"},
+				"spec":       tt.spec,
+			}
+			g.Expect(validateSchema("", obj, fakeInfrastructureClusterSchema(), true)).To(Equal(tt.want))
+		})
+	}
+}
+
+func Test_clusterctlClient_Validate(t *testing.T) {
+	g := NewWithT(t)
+
+	template := `apiVersion: infrastructure.cluster.x-k8s.io/v1alpha4
+kind: GenericInfrastructureCluster
+metadata:
+  name: foo
+  namespace: default
+spec:
+  region: eu
+  regoin: us
+---
+apiVersion: infrastructure.cluster.x-k8s.io/v1alpha3
+kind: GenericInfrastructureCluster
+metadata:
+  name: bar
+  namespace: default
+spec:
+  region: eu
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: foo
+  namespace: default
+`
+	file := filepath.Join(t.TempDir(), "cluster.yaml")
+	g.Expect(ioutil.WriteFile(file, []byte(template), 0600)).To(Succeed())
+
+	crd := &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "genericinfrastructureclusters.infrastructure.cluster.x-k8s.io"},
+		Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+			Group: "infrastructure.cluster.x-k8s.io",
+			Names: apiextensionsv1.CustomResourceDefinitionNames{Kind: "GenericInfrastructureCluster"},
+			Versions: []apiextensionsv1.CustomResourceDefinitionVersion{
+				{
+					Name:    "v1alpha4",
+					Served:  true,
+					Storage: true,
+					Schema:  &apiextensionsv1.CustomResourceValidation{OpenAPIV3Schema: fakeInfrastructureClusterSchema()},
+				},
+			},
+		},
+	}
+
+	config1 := newFakeConfig()
+	cluster1 := newFakeCluster(cluster.Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"}, config1).WithObjs(crd)
+	cluster1.fakeProxy.WithFakeCAPISetup()
+	c := newFakeClient(config1).WithCluster(cluster1)
+
+	issues, err := c.Validate(ValidateOptions{
+		Kubeconfig:       Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+		File:             file,
+		SkipServerDryRun: true,
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(issues).To(ConsistOf(
+		ValidationIssue{Object: "GenericInfrastructureCluster/default/foo", Field: "spec.regoin", Message: "unknown field"},
+		ValidationIssue{Object: "GenericInfrastructureCluster/default/bar", Message: "version v1alpha3 is not defined in genericinfrastructureclusters.infrastructure.cluster.x-k8s.io"},
+	))
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yamlprocessor
+
+import (
+	"github.com/pkg/errors"
+
+	utilyaml "sigs.k8s.io/cluster-api/util/yaml"
+)
+
+// CommonMetadataProcessor is a yaml processor that wraps another processor and injects a set of
+// common labels and annotations in all the objects of the processed yaml, e.g. the labels used by
+// GitOps tools for tracking the ownership of the objects.
+type CommonMetadataProcessor struct {
+	processor   Processor
+	labels      map[string]string
+	annotations map[string]string
+}
+
+var _ Processor = &CommonMetadataProcessor{}
+
+// NewCommonMetadataProcessor returns a new processor injecting the given labels and annotations
+// in all the objects processed by the wrapped processor. If processor is nil, the SimpleProcessor is used.
+func NewCommonMetadataProcessor(processor Processor, labels, annotations map[string]string) *CommonMetadataProcessor {
+	if processor == nil {
+		processor = NewSimpleProcessor()
+	}
+	return &CommonMetadataProcessor{
+		processor:   processor,
+		labels:      labels,
+		annotations: annotations,
+	}
+}
+
+// GetTemplateName returns the name of the template used by the wrapped processor.
+func (p *CommonMetadataProcessor) GetTemplateName(version, flavor string) string {
+	return p.processor.GetTemplateName(version, flavor)
+}
+
+// GetVariables returns the list of variables returned by the wrapped processor.
+func (p *CommonMetadataProcessor) GetVariables(rawArtifact []byte) ([]string, error) {
+	return p.processor.GetVariables(rawArtifact)
+}
+
+// GetVariableMap returns the map of variables returned by the wrapped processor.
+func (p *CommonMetadataProcessor) GetVariableMap(rawArtifact []byte) (map[string]*string, error) {
+	return p.processor.GetVariableMap(rawArtifact)
+}
+
+// Process processes the yaml using the wrapped processor, and then adds the common
+// labels and annotations to all the objects; existing values for the same keys are overwritten.
+func (p *CommonMetadataProcessor) Process(rawArtifact []byte, variablesClient func(string) (string, error)) ([]byte, error) {
+	processedYaml, err := p.processor.Process(rawArtifact, variablesClient)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(p.labels) == 0 && len(p.annotations) == 0 {
+		return processedYaml, nil
+	}
+
+	objs, err := utilyaml.ToUnstructured(processedYaml)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse yaml")
+	}
+
+	for i := range objs {
+		o := &objs[i]
+		if len(p.labels) > 0 {
+			labels := o.GetLabels()
+			if labels == nil {
+				labels = map[string]string{}
+			}
+			for k, v := range p.labels {
+				labels[k] = v
+			}
+			o.SetLabels(labels)
+		}
+		if len(p.annotations) > 0 {
+			annotations := o.GetAnnotations()
+			if annotations == nil {
+				annotations = map[string]string{}
+			}
+			for k, v := range p.annotations {
+				annotations[k] = v
+			}
+			o.SetAnnotations(annotations)
+		}
+	}
+
+	return utilyaml.FromUnstructured(objs)
+}
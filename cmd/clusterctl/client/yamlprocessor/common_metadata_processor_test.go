@@ -0,0 +1,76 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yamlprocessor
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+	utilyaml "sigs.k8s.io/cluster-api/util/yaml"
+)
+
+func TestCommonMetadataProcessor_Process(t *testing.T) {
+	rawYaml := []byte(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: ${NAME}
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: bar
+  labels:
+    foo: bar
+  annotations:
+    foo: bar
+`)
+	variablesClient := test.NewFakeVariableClient().WithVar("NAME", "foo")
+
+	t.Run("injects common labels and annotations in all the objects", func(t *testing.T) {
+		g := NewWithT(t)
+
+		p := NewCommonMetadataProcessor(nil,
+			map[string]string{"argocd.argoproj.io/instance": "my-cluster"},
+			map[string]string{"argocd.argoproj.io/sync-options": "Prune=false"},
+		)
+		processedYaml, err := p.Process(rawYaml, variablesClient.Get)
+		g.Expect(err).NotTo(HaveOccurred())
+
+		objs, err := utilyaml.ToUnstructured(processedYaml)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(objs).To(HaveLen(2))
+
+		g.Expect(objs[0].GetName()).To(Equal("foo"))
+		g.Expect(objs[0].GetLabels()).To(Equal(map[string]string{"argocd.argoproj.io/instance": "my-cluster"}))
+		g.Expect(objs[0].GetAnnotations()).To(Equal(map[string]string{"argocd.argoproj.io/sync-options": "Prune=false"}))
+
+		g.Expect(objs[1].GetLabels()).To(Equal(map[string]string{"foo": "bar", "argocd.argoproj.io/instance": "my-cluster"}))
+		g.Expect(objs[1].GetAnnotations()).To(Equal(map[string]string{"foo": "bar", "argocd.argoproj.io/sync-options": "Prune=false"}))
+	})
+
+	t.Run("returns the yaml processed by the wrapped processor if there are no common labels and annotations", func(t *testing.T) {
+		g := NewWithT(t)
+
+		want, err := NewSimpleProcessor().Process(rawYaml, variablesClient.Get)
+		g.Expect(err).NotTo(HaveOccurred())
+
+		got, err := NewCommonMetadataProcessor(nil, nil, nil).Process(rawYaml, variablesClient.Get)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(got).To(Equal(want))
+	})
+}
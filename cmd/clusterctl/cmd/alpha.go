@@ -29,6 +29,8 @@ var alphaCmd = &cobra.Command{
 func init() {
 	// Alpha commands should be added here.
 	alphaCmd.AddCommand(rolloutCmd)
+	alphaCmd.AddCommand(supportBundleCmd)
+	alphaCmd.AddCommand(validateCmd)
 
 	RootCmd.AddCommand(alphaCmd)
 }
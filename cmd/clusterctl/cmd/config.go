@@ -22,8 +22,8 @@ import (
 
 var configCmd = &cobra.Command{
 	Use:   "config",
-	Short: "Display clusterctl configuration.",
-	Long:  `Display clusterctl configuration.`,
+	Short: "Display and manage clusterctl configuration.",
+	Long:  `Display and manage clusterctl configuration.`,
 }
 
 func init() {
@@ -0,0 +1,73 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+)
+
+var configCertManagerCmd = &cobra.Command{
+	Use:   "cert-manager",
+	Args:  cobra.NoArgs,
+	Short: "Display the cert-manager release installed by clusterctl.",
+	Long: LongDesc(`
+		Display the cert-manager release installed by clusterctl init and clusterctl upgrade,
+		i.e. the source of the cert-manager manifest, the version and the required images.
+
+		The cert-manager release can be customized, e.g. to use a mirror in air-gapped environments, by setting
+		the cert-manager url and version in the $HOME/.cluster-api/clusterctl.yaml file.`),
+
+	Example: Examples(`
+		# Displays the cert-manager release installed by clusterctl.
+		clusterctl config cert-manager`),
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runConfigCertManager(cfgFile, os.Stdout)
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configCertManagerCmd)
+}
+
+func runConfigCertManager(cfgFile string, out io.Writer) error {
+	c, err := client.New(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	info, err := c.GetCertManagerInfo()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 10, 4, 3, ' ', 0)
+	fmt.Fprintf(w, "URL:\t%s\n", info.URL)
+	fmt.Fprintf(w, "Version:\t%s\n", info.Version)
+	fmt.Fprintf(w, "Timeout:\t%s\n", info.Timeout)
+	fmt.Fprintln(w, "Images:")
+	for _, image := range info.Images {
+		fmt.Fprintf(w, "  %s\n", image)
+	}
+	return w.Flush()
+}
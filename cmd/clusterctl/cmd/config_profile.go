@@ -0,0 +1,163 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	"sigs.k8s.io/yaml"
+)
+
+type configExportOptions struct {
+	outputFile string
+	variables  []string
+}
+
+var ceo = &configExportOptions{}
+
+var configExportCmd = &cobra.Command{
+	Use:   "export NAME",
+	Args:  cobra.ExactArgs(1),
+	Short: "Export the clusterctl configuration as a named profile.",
+	Long: LongDesc(`
+		Export the provider repositories and the image overrides defined in the clusterctl configuration
+		file as a named profile, so it can be shared with other users.
+
+		Variables could host secrets, e.g. the GitHub token or provider credentials, so they are exported
+		only if explicitly requested with the --variable flag.
+
+		Values are exported as they are defined in the clusterctl configuration file: references
+		to environment variables, e.g. ${AWS_REGION}, are preserved, and values defined only in
+		environment variables are not exported.`),
+
+	Example: Examples(`
+		# Export the clusterctl configuration as the profile my-team.
+		clusterctl config export my-team
+
+		# Export the clusterctl configuration as the profile my-team to a file.
+		clusterctl config export my-team --output-file my-team.yaml
+
+		# Export the clusterctl configuration as the profile my-team, including the AWS_REGION variable.
+		clusterctl config export my-team --variable AWS_REGION`),
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runConfigExport(args[0], os.Stdout)
+	},
+}
+
+type configImportOptions struct {
+	overwrite bool
+}
+
+var cio = &configImportOptions{}
+
+var configImportCmd = &cobra.Command{
+	Use:   "import FILE",
+	Args:  cobra.ExactArgs(1),
+	Short: "Import a profile into the clusterctl configuration.",
+	Long: LongDesc(`
+		Import a profile exported with clusterctl config export into the clusterctl configuration file;
+		the configuration file is created if it does not exist.
+
+		Provider repositories, image overrides and variables already defined in the clusterctl configuration
+		file are preserved, unless the --overwrite flag is set. The configuration file is edited in place,
+		so comments are preserved.`),
+
+	Example: Examples(`
+		# Import the profile in my-team.yaml into the clusterctl configuration.
+		clusterctl config import my-team.yaml
+
+		# Import the profile in my-team.yaml, overwriting the existing configurations.
+		clusterctl config import my-team.yaml --overwrite`),
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runConfigImport(args[0], os.Stdout)
+	},
+}
+
+func init() {
+	configExportCmd.Flags().StringVar(&ceo.outputFile, "output-file", "",
+		"The file to write the profile to. If unspecified, the profile is printed to stdout.")
+	configExportCmd.Flags().StringSliceVar(&ceo.variables, "variable", nil,
+		"The variables to export. Variables are not exported unless listed, given that they could host secrets.")
+	configImportCmd.Flags().BoolVar(&cio.overwrite, "overwrite", false,
+		"Overwrite the provider repositories, the image overrides and the variables already defined in the clusterctl configuration file.")
+
+	configCmd.AddCommand(configExportCmd)
+	configCmd.AddCommand(configImportCmd)
+}
+
+func runConfigExport(name string, out io.Writer) error {
+	configFile, err := localConfigFile()
+	if err != nil {
+		return err
+	}
+
+	profile, err := config.ExportProfile(configFile, name, ceo.variables)
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(profile)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the profile")
+	}
+
+	if ceo.outputFile != "" {
+		if err := os.WriteFile(ceo.outputFile, data, 0600); err != nil {
+			return errors.Wrapf(err, "failed to write the profile to %q", ceo.outputFile)
+		}
+		return nil
+	}
+	_, err = out.Write(data)
+	return err
+}
+
+func runConfigImport(file string, out io.Writer) error {
+	configFile, err := localConfigFile()
+	if err != nil {
+		return err
+	}
+
+	profile, err := config.ReadProfile(file)
+	if err != nil {
+		return err
+	}
+	if err := config.ImportProfile(configFile, profile, cio.overwrite); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Profile %q imported into %s\n", profile.Name, configFile)
+	return nil
+}
+
+// localConfigFile returns the path of the clusterctl configuration file to export from or to import to.
+func localConfigFile() (string, error) {
+	if cfgFile == "" {
+		return config.DefaultConfigFile(), nil
+	}
+	if strings.HasPrefix(cfgFile, "http://") || strings.HasPrefix(cfgFile, "https://") {
+		return "", errors.Errorf("profiles can not be exported from or imported to a remote clusterctl configuration file %q", cfgFile)
+	}
+	return cfgFile, nil
+}
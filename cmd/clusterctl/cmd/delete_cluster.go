@@ -0,0 +1,104 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+)
+
+type deleteClusterOptions struct {
+	kubeconfig            string
+	kubeconfigContext     string
+	namespace             string
+	timeout               time.Duration
+	forceRemoveFinalizers bool
+	confirm               bool
+}
+
+var dc = &deleteClusterOptions{}
+
+var deleteClusterCmd = &cobra.Command{
+	Use:   "cluster",
+	Short: "Delete a workload cluster.",
+	Long: LongDesc(`
+		Delete a workload cluster and wait for the deletion of all the objects belonging to it.
+
+		The Cluster object is deleted, and Cluster API providers take care of draining and deleting
+		the Machines and of removing the corresponding infrastructure; the progress is reported
+		until the deletion completes or the timeout expires.`),
+
+	Example: Examples(`
+		# Deletes the workload cluster and waits for the deletion to complete.
+		clusterctl delete cluster <name of workload cluster>
+
+		# Deletes the workload cluster in a particular namespace, waiting up to one hour.
+		clusterctl delete cluster <name of workload cluster> --namespace foo --timeout 1h
+
+		# Deletes the workload cluster, removing the finalizers from the objects still existing after the timeout.
+		# Important! As a consequence of this operation, all the infrastructure the providers were not able to
+		# clean up is orphaned and there might be ongoing costs incurred as a result of this.
+		clusterctl delete cluster <name of workload cluster> --force-remove-finalizers --yes`),
+
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runDeleteCluster(args[0])
+	},
+}
+
+func init() {
+	deleteClusterCmd.Flags().StringVar(&dc.kubeconfig, "kubeconfig", "",
+		"Path to the kubeconfig file to use for accessing the management cluster. If unspecified, default discovery rules apply.")
+	deleteClusterCmd.Flags().StringVar(&dc.kubeconfigContext, "kubeconfig-context", "",
+		"Context to be used within the kubeconfig file. If empty, current context will be used.")
+	deleteClusterCmd.Flags().StringVarP(&dc.namespace, "namespace", "n", "",
+		"Namespace where the workload cluster exist.")
+
+	deleteClusterCmd.Flags().DurationVar(&dc.timeout, "timeout", 30*time.Minute,
+		"Time to wait for the deletion of the workload cluster to complete. If zero, the command returns as soon as the deletion is started.")
+	deleteClusterCmd.Flags().BoolVar(&dc.forceRemoveFinalizers, "force-remove-finalizers", false,
+		"Remove the finalizers from the objects of the workload cluster still existing after the timeout. Requires --yes.")
+	deleteClusterCmd.Flags().BoolVar(&dc.confirm, "yes", false,
+		"Confirm that the infrastructure not yet cleaned up by the providers can be orphaned when using --force-remove-finalizers.")
+
+	deleteCmd.AddCommand(deleteClusterCmd)
+}
+
+func runDeleteCluster(name string) error {
+	if dc.forceRemoveFinalizers && !dc.confirm {
+		return errors.New("--force-remove-finalizers orphans the infrastructure not yet cleaned up by the providers; use --yes to confirm")
+	}
+	if dc.forceRemoveFinalizers && dc.timeout <= 0 {
+		return errors.New("--force-remove-finalizers requires a --timeout greater than zero")
+	}
+
+	c, err := client.New(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	return c.DeleteCluster(client.DeleteClusterOptions{
+		Kubeconfig:            client.Kubeconfig{Path: dc.kubeconfig, Context: dc.kubeconfigContext},
+		Namespace:             dc.namespace,
+		ClusterName:           name,
+		Timeout:               dc.timeout,
+		ForceRemoveFinalizers: dc.forceRemoveFinalizers,
+	})
+}
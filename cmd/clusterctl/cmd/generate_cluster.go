@@ -18,9 +18,25 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"text/tabwriter"
 
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/yamlprocessor"
+)
+
+const (
+	// VariablesOutputText is an option used to print the list of variables in text format.
+	VariablesOutputText = "text"
+	// VariablesOutputJSONSchema is an option used to print the list of variables as a JSON schema.
+	VariablesOutputJSONSchema = "json-schema"
+)
+
+var (
+	// VariablesOutputs is a list of valid list of variables outputs.
+	VariablesOutputs = []string{VariablesOutputText, VariablesOutputJSONSchema}
 )
 
 type generateClusterOptions struct {
@@ -39,7 +55,12 @@ type generateClusterOptions struct {
 	configMapName      string
 	configMapDataKey   string
 
+	commonLabels      map[string]string
+	commonAnnotations map[string]string
+
 	listVariables bool
+	listFlavors   bool
+	output        string
 }
 
 var gc = &generateClusterOptions{}
@@ -84,11 +105,29 @@ var generateClusterClusterCmd = &cobra.Command{
 		# Generates a yaml file for creating workload clusters using a template stored locally.
 		clusterctl generate cluster my-cluster --from ~/workspace/cluster-template.yaml
 
+		# Generates a yaml file for creating workload clusters with a label added to all the objects,
+		# e.g. for tracking the objects with a GitOps tool.
+		clusterctl generate cluster my-cluster --common-labels argocd.argoproj.io/instance=my-cluster
+
 		# Prints the list of variables required by the yaml file for creating workload cluster.
-		clusterctl generate cluster my-cluster --list-variables`),
+		clusterctl generate cluster my-cluster --list-variables
+
+		# Prints a JSON schema describing the variables required by the yaml file for creating workload cluster.
+		clusterctl generate cluster my-cluster --list-variables --output json-schema
 
-	Args: cobra.ExactArgs(1),
+		# Prints the list of template flavors available in the AWS infrastructure provider repository.
+		clusterctl generate cluster --list-flavors --infrastructure=aws`),
+
+	Args: func(cmd *cobra.Command, args []string) error {
+		if gc.listFlavors {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if gc.listFlavors {
+			return runGenerateClusterListFlavors()
+		}
 		return runGenerateClusterTemplate(cmd, args[0])
 	},
 }
@@ -127,14 +166,31 @@ func init() {
 	generateClusterClusterCmd.Flags().StringVar(&gc.configMapDataKey, "from-config-map-key", "",
 		fmt.Sprintf("The ConfigMap.Data key where the workload cluster template is hosted. If unspecified, %q will be used", client.DefaultCustomTemplateConfigMapKey))
 
+	// flags for the common metadata
+	generateClusterClusterCmd.Flags().StringToStringVar(&gc.commonLabels, "common-labels", nil,
+		"Labels to add to all the objects in the workload cluster template, in the form key=value (e.g. argocd.argoproj.io/instance=my-cluster).")
+	generateClusterClusterCmd.Flags().StringToStringVar(&gc.commonAnnotations, "common-annotations", nil,
+		"Annotations to add to all the objects in the workload cluster template, in the form key=value.")
+
 	// other flags
 	generateClusterClusterCmd.Flags().BoolVar(&gc.listVariables, "list-variables", false,
 		"Returns the list of variables expected by the template instead of the template yaml")
+	generateClusterClusterCmd.Flags().BoolVar(&gc.listFlavors, "list-flavors", false,
+		"Returns the list of template flavors available in the infrastructure provider repository instead of the template yaml")
+	generateClusterClusterCmd.Flags().StringVarP(&gc.output, "output", "o", VariablesOutputText,
+		fmt.Sprintf("Output format for the list of variables. Valid values: %v.", VariablesOutputs))
 
 	generateCmd.AddCommand(generateClusterClusterCmd)
 }
 
 func runGenerateClusterTemplate(cmd *cobra.Command, name string) error {
+	if gc.output != VariablesOutputText && gc.output != VariablesOutputJSONSchema {
+		return errors.Errorf("invalid output format %q. Valid values: %v", gc.output, VariablesOutputs)
+	}
+	if cmd.Flags().Changed("output") && !gc.listVariables {
+		return errors.New("--output can be used only together with --list-variables")
+	}
+
 	c, err := client.New(cfgFile)
 	if err != nil {
 		return err
@@ -155,6 +211,10 @@ func runGenerateClusterTemplate(cmd *cobra.Command, name string) error {
 		templateOptions.WorkerMachineCount = &gc.workerMachineCount
 	}
 
+	if len(gc.commonLabels) > 0 || len(gc.commonAnnotations) > 0 {
+		templateOptions.YamlProcessor = yamlprocessor.NewCommonMetadataProcessor(nil, gc.commonLabels, gc.commonAnnotations)
+	}
+
 	if gc.url != "" {
 		templateOptions.URLSource = &client.URLSourceOptions{
 			URL: gc.url,
@@ -182,8 +242,40 @@ func runGenerateClusterTemplate(cmd *cobra.Command, name string) error {
 	}
 
 	if gc.listVariables {
+		if gc.output == VariablesOutputJSONSchema {
+			return printVariablesJSONSchemaOutput(template, templateOptions)
+		}
 		return printVariablesOutput(template, templateOptions)
 	}
 
 	return printYamlOutput(template)
 }
+
+func runGenerateClusterListFlavors() error {
+	if gc.infrastructureProvider == "" {
+		return errors.New("--list-flavors requires the --infrastructure flag to be set")
+	}
+
+	c, err := client.New(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	flavors, err := c.ListFlavors(client.ListFlavorsOptions{
+		InfrastructureProvider: gc.infrastructureProvider,
+	})
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 10, 4, 3, ' ', 0)
+	fmt.Fprintln(w, "NAME\tDESCRIPTION")
+	for _, f := range flavors {
+		name := f.Name
+		if name == "" {
+			name = "(default)"
+		}
+		fmt.Fprintf(w, "%s\t%s\n", name, f.Description)
+	}
+	return w.Flush()
+}
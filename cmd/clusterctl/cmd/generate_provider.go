@@ -22,6 +22,7 @@ import (
 
 	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/yamlprocessor"
 )
 
 type generateProvidersOptions struct {
@@ -32,6 +33,8 @@ type generateProvidersOptions struct {
 	targetNamespace        string
 	textOutput             bool
 	raw                    bool
+	commonLabels           map[string]string
+	commonAnnotations      map[string]string
 }
 
 var gpo = &generateProvidersOptions{}
@@ -66,7 +69,11 @@ var generateProviderCmd = &cobra.Command{
 
 		# Generates a yaml file for creating provider for a specific version.
 		# No variables will be processed and substituted using this flag
-		clusterctl generate provider --infrastructure aws:v0.4.1 --raw`),
+		clusterctl generate provider --infrastructure aws:v0.4.1 --raw
+
+		# Generates a yaml file for creating provider with a label added to all the objects,
+		# e.g. for tracking the objects with a GitOps tool.
+		clusterctl generate provider --infrastructure aws --common-labels argocd.argoproj.io/instance=capa`),
 
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return runGenerateProviderComponents()
@@ -88,6 +95,10 @@ func init() {
 		"Generate configuration without variable substitution.")
 	generateProviderCmd.Flags().BoolVar(&gpo.raw, "raw", false,
 		"Generate configuration without variable substitution in a yaml format.")
+	generateProviderCmd.Flags().StringToStringVar(&gpo.commonLabels, "common-labels", nil,
+		"Labels to add to all the provider components, in the form key=value (e.g. argocd.argoproj.io/instance=capa). Not supported together with --raw.")
+	generateProviderCmd.Flags().StringToStringVar(&gpo.commonAnnotations, "common-annotations", nil,
+		"Annotations to add to all the provider components, in the form key=value. Not supported together with --raw.")
 
 	generateCmd.AddCommand(generateProviderCmd)
 }
@@ -97,6 +108,9 @@ func runGenerateProviderComponents() error {
 	if err != nil {
 		return err
 	}
+	if gpo.raw && (len(gpo.commonLabels) > 0 || len(gpo.commonAnnotations) > 0) {
+		return errors.New("--common-labels and --common-annotations can't be used together with --raw")
+	}
 	c, err := client.New(cfgFile)
 	if err != nil {
 		return err
@@ -107,6 +121,10 @@ func runGenerateProviderComponents() error {
 		SkipTemplateProcess: gpo.raw,
 	}
 
+	if len(gpo.commonLabels) > 0 || len(gpo.commonAnnotations) > 0 {
+		options.YamlProcessor = yamlprocessor.NewCommonMetadataProcessor(nil, gpo.commonLabels, gpo.commonAnnotations)
+	}
+
 	components, err := c.GetProviderComponents(providerName, providerType, options)
 	if err != nil {
 		return err
@@ -0,0 +1,114 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+)
+
+type getClustersOptions struct {
+	kubeconfig        string
+	kubeconfigContext string
+	namespace         string
+	allNamespaces     bool
+	selector          string
+}
+
+var gcs = &getClustersOptions{}
+
+var getClustersCmd = &cobra.Command{
+	Use:   "clusters",
+	Short: "Gets a summary of the workload clusters in a management cluster",
+	Long: LongDesc(`
+		Gets a summary of the workload clusters in a management cluster, with the phase, the control plane
+		readiness, the number of ready and total worker machines, the Kubernetes version and the age of each cluster.
+
+		The command is read-only; use clusterctl describe cluster for the detailed status of a cluster.`),
+
+	Example: Examples(`
+		# Gets the workload clusters in the current namespace.
+		clusterctl get clusters
+
+		# Gets the workload clusters in all the namespaces.
+		clusterctl get clusters -A
+
+		# Gets the workload clusters with a given label.
+		clusterctl get clusters -A --selector env=prod`),
+
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runGetClusters()
+	},
+}
+
+func init() {
+	getClustersCmd.Flags().StringVarP(&gcs.namespace, "namespace", "n", "",
+		"The namespace where the workload clusters are located. If unspecified, the current namespace will be used.")
+	getClustersCmd.Flags().BoolVarP(&gcs.allNamespaces, "all-namespaces", "A", false,
+		"Gets the workload clusters in all the namespaces.")
+	getClustersCmd.Flags().StringVarP(&gcs.selector, "selector", "l", "",
+		"Label selector to filter the workload clusters on, e.g. env=prod.")
+	getClustersCmd.Flags().StringVar(&gcs.kubeconfig, "kubeconfig", "",
+		"Path to the kubeconfig file to use for accessing the management cluster. If unspecified, default discovery rules apply.")
+	getClustersCmd.Flags().StringVar(&gcs.kubeconfigContext, "kubeconfig-context", "",
+		"Context to be used within the kubeconfig file. If empty, current context will be used.")
+	getCmd.AddCommand(getClustersCmd)
+}
+
+func runGetClusters() error {
+	c, err := client.New(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	summaries, err := c.GetClusters(client.GetClustersOptions{
+		Kubeconfig:    client.Kubeconfig{Path: gcs.kubeconfig, Context: gcs.kubeconfigContext},
+		Namespace:     gcs.namespace,
+		AllNamespaces: gcs.allNamespaces,
+		LabelSelector: gcs.selector,
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(summaries) == 0 {
+		fmt.Println("No workload clusters found.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 10, 4, 3, ' ', 0)
+	header := "NAME\tPHASE\tCONTROL PLANE READY\tWORKERS\tVERSION\tAGE"
+	if gcs.allNamespaces {
+		header = "NAMESPACE\t" + header
+	}
+	fmt.Fprintln(w, header)
+	for _, s := range summaries {
+		row := fmt.Sprintf("%s\t%s\t%t\t%d/%d\t%s\t%s", s.Name, s.Phase, s.ControlPlaneReady, s.ReadyWorkers, s.Workers, s.Version, duration.HumanDuration(time.Since(s.CreationTimestamp.Time)))
+		if gcs.allNamespaces {
+			row = s.Namespace + "\t" + row
+		}
+		fmt.Fprintln(w, row)
+	}
+	return w.Flush()
+}
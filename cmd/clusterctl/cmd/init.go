@@ -21,6 +21,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
 )
 
 type initOptions struct {
@@ -31,7 +32,10 @@ type initOptions struct {
 	controlPlaneProviders   []string
 	infrastructureProviders []string
 	targetNamespace         string
+	imageOverrides          []string
+	phases                  []string
 	listImages              bool
+	requireSigned           bool
 }
 
 var initOpts = &initOptions{}
@@ -75,6 +79,14 @@ var initCmd = &cobra.Command{
 		# Initialize a management cluster with a custom target namespace for the provider resources.
 		clusterctl init --infrastructure aws --target-namespace foo
 
+		# Initialize a management cluster pulling all the images from a mirrored registry.
+		clusterctl init --infrastructure aws --image-override all.repository=myorg.io/local-repo
+
+		# Initialize a management cluster in two steps: first install only the CustomResourceDefinitions of the providers,
+		# e.g. using an identity with elevated privileges, and then install all the other provider components.
+		clusterctl init --infrastructure aws --phases crds
+		clusterctl init --infrastructure aws --phases components
+
 		# Lists the container images required for initializing the management cluster.
 		#
 		# Note: This command is a dry-run; it won't perform any action other than printing to screen.
@@ -101,6 +113,17 @@ func init() {
 	initCmd.Flags().StringVar(&initOpts.targetNamespace, "target-namespace", "",
 		"The target namespace where the providers should be deployed. If unspecified, the provider components' default namespace is used.")
 
+	initCmd.Flags().StringSliceVar(&initOpts.imageOverrides, "image-override", nil,
+		"Image overrides in the form <component>[/<image>].<repository|tag>=<value> (e.g. all.repository=myorg.io/local-repo). "+
+			"Image overrides defined using this flag take precedence over the ones defined in the clusterctl configuration file.")
+
+	initCmd.Flags().StringSliceVar(&initOpts.phases, "phases", nil,
+		"The installation phases to execute, crds for installing only the CustomResourceDefinitions, components for installing all the other provider components (e.g. --phases crds). "+
+			"If unspecified, all the phases are executed.")
+
+	initCmd.Flags().BoolVar(&initOpts.requireSigned, "require-signed", false,
+		"Requires provider components and metadata to be signed and verified using the signature verification configuration defined in the clusterctl configuration file.")
+
 	// TODO: Move this to a sub-command or similar, it shouldn't really be a flag.
 	initCmd.Flags().BoolVar(&initOpts.listImages, "list-images", false,
 		"Lists the container images required for initializing the management cluster (without actually installing the providers)")
@@ -109,7 +132,7 @@ func init() {
 }
 
 func runInit() error {
-	c, err := client.New(cfgFile)
+	c, err := newClientWithImageOverrides(initOpts.imageOverrides, config.InjectRequireSigned(initOpts.requireSigned))
 	if err != nil {
 		return err
 	}
@@ -121,6 +144,7 @@ func runInit() error {
 		ControlPlaneProviders:   initOpts.controlPlaneProviders,
 		InfrastructureProviders: initOpts.infrastructureProviders,
 		TargetNamespace:         initOpts.targetNamespace,
+		Phases:                  initOpts.phases,
 		LogUsageInstructions:    true,
 	}
 
@@ -141,3 +165,13 @@ func runInit() error {
 	}
 	return nil
 }
+
+// newClientWithImageOverrides returns a clusterctl client applying the given image overrides on top of the ones
+// defined in the clusterctl configuration file, and the given additional config options.
+func newClientWithImageOverrides(imageOverrides []string, options ...config.Option) (client.Client, error) {
+	configClient, err := config.New(cfgFile, append([]config.Option{config.InjectImageOverrides(imageOverrides)}, options...)...)
+	if err != nil {
+		return nil, err
+	}
+	return client.New(cfgFile, client.InjectConfig(configClient))
+}
@@ -28,7 +28,11 @@ type moveOptions struct {
 	toKubeconfig          string
 	toKubeconfigContext   string
 	namespace             string
+	toNamespace           string
 	dryRun                bool
+	createNamespace       bool
+	includeKinds          []string
+	excludeKinds          []string
 }
 
 var mo = &moveOptions{}
@@ -43,7 +47,19 @@ var moveCmd = &cobra.Command{
 
 	Example: Examples(`
 		Move Cluster API objects and all dependencies between management clusters.
-		clusterctl move --to-kubeconfig=target-kubeconfig.yaml`),
+		clusterctl move --to-kubeconfig=target-kubeconfig.yaml
+
+		Move Cluster API objects and all dependencies creating the namespaces missing in the target management cluster.
+		clusterctl move --to-kubeconfig=target-kubeconfig.yaml --create-namespace
+
+		Move Cluster API objects and all dependencies to a namespace with a different name in the target management cluster.
+		clusterctl move --to-kubeconfig=target-kubeconfig.yaml --namespace=foo --to-namespace=bar
+
+		Move Cluster API objects and all dependencies together with the cert-manager Certificates existing in the namespace.
+		clusterctl move --to-kubeconfig=target-kubeconfig.yaml --include-kind=Certificate.cert-manager.io
+
+		Move Cluster API objects and all dependencies except ConfigMaps.
+		clusterctl move --to-kubeconfig=target-kubeconfig.yaml --exclude-kind=ConfigMap`),
 	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return runMove()
@@ -61,8 +77,16 @@ func init() {
 		"Context to be used within the kubeconfig file for the destination management cluster. If empty, current context will be used.")
 	moveCmd.Flags().StringVarP(&mo.namespace, "namespace", "n", "",
 		"The namespace where the workload cluster is hosted. If unspecified, the current context's namespace is used.")
+	moveCmd.Flags().StringVar(&mo.toNamespace, "to-namespace", "",
+		"The namespace where the workload cluster is moved to in the destination management cluster. If unspecified, the same namespace of the source management cluster is used.")
 	moveCmd.Flags().BoolVar(&mo.dryRun, "dry-run", false,
 		"Enable dry run, don't really perform the move actions")
+	moveCmd.Flags().BoolVar(&mo.createNamespace, "create-namespace", false,
+		"Create the namespaces missing in the destination management cluster. If unset, the move fails if any of the namespaces is missing.")
+	moveCmd.Flags().StringSliceVar(&mo.includeKinds, "include-kind", nil,
+		"Additional kinds to be moved, in the Kind.group format (e.g. Certificate.cert-manager.io). All the objects of those kinds existing in the namespace are moved.")
+	moveCmd.Flags().StringSliceVar(&mo.excludeKinds, "exclude-kind", nil,
+		"Kinds not to be moved, in the Kind.group format (e.g. ConfigMap). The move fails if any of the objects to be moved is owned by an object of an excluded kind.")
 
 	RootCmd.AddCommand(moveCmd)
 }
@@ -79,9 +103,13 @@ func runMove() error {
 	}
 
 	return c.Move(client.MoveOptions{
-		FromKubeconfig: client.Kubeconfig{Path: mo.fromKubeconfig, Context: mo.fromKubeconfigContext},
-		ToKubeconfig:   client.Kubeconfig{Path: mo.toKubeconfig, Context: mo.toKubeconfigContext},
-		Namespace:      mo.namespace,
-		DryRun:         mo.dryRun,
+		FromKubeconfig:  client.Kubeconfig{Path: mo.fromKubeconfig, Context: mo.fromKubeconfigContext},
+		ToKubeconfig:    client.Kubeconfig{Path: mo.toKubeconfig, Context: mo.toKubeconfigContext},
+		Namespace:       mo.namespace,
+		ToNamespace:     mo.toNamespace,
+		DryRun:          mo.dryRun,
+		CreateNamespace: mo.createNamespace,
+		IncludeKinds:    mo.includeKinds,
+		ExcludeKinds:    mo.excludeKinds,
 	})
 }
@@ -0,0 +1,84 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+)
+
+type supportBundleOptions struct {
+	kubeconfig        string
+	kubeconfigContext string
+	namespace         string
+	output            string
+	logTailLines      int64
+}
+
+var sbo = &supportBundleOptions{}
+
+var supportBundleCmd = &cobra.Command{
+	Use:   "support-bundle",
+	Short: "Collect troubleshooting information from a management cluster into a local tarball.",
+	Long: LongDesc(`
+		Collect troubleshooting information from a management cluster into a local tarball.
+
+		The support bundle includes the Cluster API objects and their object graph, the provider inventory,
+		version information and the logs of the provider controllers. The bundle is only written locally,
+		nothing is uploaded, and the values of all the Secrets are redacted.
+
+		The resulting tarball can be attached to bug reports in order to speed up triage.`),
+
+	Example: Examples(`
+		# Collect a support bundle from the current management cluster.
+		clusterctl alpha support-bundle --output=support-bundle.tar.gz
+
+		# Collect a support bundle including only objects from the foo namespace and without controller logs.
+		clusterctl alpha support-bundle --output=support-bundle.tar.gz --namespace=foo --log-tail-lines=-1`),
+
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runSupportBundle()
+	},
+}
+
+func init() {
+	supportBundleCmd.Flags().StringVar(&sbo.kubeconfig, "kubeconfig", "",
+		"Path to the kubeconfig file for the management cluster. If unspecified, default discovery rules apply.")
+	supportBundleCmd.Flags().StringVar(&sbo.kubeconfigContext, "kubeconfig-context", "",
+		"Context to be used within the kubeconfig file. If empty, current context will be used.")
+	supportBundleCmd.Flags().StringVarP(&sbo.namespace, "namespace", "n", "",
+		"The namespace where the workload clusters are hosted. If unspecified, objects from all the namespaces are collected.")
+	supportBundleCmd.Flags().StringVarP(&sbo.output, "output", "o", "support-bundle.tar.gz",
+		"The path of the tarball the support bundle is written to.")
+	supportBundleCmd.Flags().Int64Var(&sbo.logTailLines, "log-tail-lines", 0,
+		"The number of log lines to collect for each controller container. If 0, a default value is used; if negative, controller logs are not collected.")
+}
+
+func runSupportBundle() error {
+	c, err := client.New(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	return c.SupportBundle(client.SupportBundleOptions{
+		Kubeconfig:   client.Kubeconfig{Path: sbo.kubeconfig, Context: sbo.kubeconfigContext},
+		Namespace:    sbo.namespace,
+		OutputFile:   sbo.output,
+		LogTailLines: sbo.logTailLines,
+	})
+}
@@ -21,6 +21,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
 )
 
 type upgradeApplyOptions struct {
@@ -31,6 +32,12 @@ type upgradeApplyOptions struct {
 	bootstrapProviders      []string
 	controlPlaneProviders   []string
 	infrastructureProviders []string
+	imageOverrides          []string
+	backupDirectory         string
+	phases                  []string
+	skipProviders           []string
+	requireSigned           bool
+	dryRun                  bool
 }
 
 var ua = &upgradeApplyOptions{}
@@ -49,8 +56,24 @@ var upgradeApplyCmd = &cobra.Command{
 		# to the v1alpha4 API Version of Cluster API (contract).
 		clusterctl upgrade apply --contract v1alpha4
 
+		# Upgrades all the providers in the management cluster to the latest version available which is compliant
+		# to the v1alpha4 API Version of Cluster API (contract), except the capa-system/aws provider.
+		clusterctl upgrade apply --contract v1alpha4 --skip-providers capa-system/aws
+
 		# Upgrades only the capa-system/aws provider to the v0.5.0 version.
-		clusterctl upgrade apply --infrastructure capa-system/aws:v0.5.0`),
+		clusterctl upgrade apply --infrastructure capa-system/aws:v0.5.0
+
+		# Backs up the management cluster to a sub directory of /tmp/backups before upgrading it.
+		# NOTE: The backup includes the management cluster Secrets in plaintext.
+		clusterctl upgrade apply --contract v1alpha4 --backup-directory /tmp/backups
+
+		# Upgrades only the CustomResourceDefinitions of all the providers, e.g. using an identity with elevated privileges,
+		# and then upgrades all the other provider components.
+		clusterctl upgrade apply --contract v1alpha4 --phases crds
+		clusterctl upgrade apply --contract v1alpha4 --phases components
+
+		# Reports the provider components that would be added, changed or removed by the upgrade, without applying it.
+		clusterctl upgrade apply --contract v1alpha4 --dry-run`),
 	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return runUpgradeApply()
@@ -73,10 +96,26 @@ func init() {
 		"Bootstrap providers instance and versions (e.g. capi-kubeadm-bootstrap-system/kubeadm:v0.3.0) to upgrade to. This flag can be used as alternative to --contract.")
 	upgradeApplyCmd.Flags().StringSliceVarP(&ua.controlPlaneProviders, "control-plane", "c", nil,
 		"ControlPlane providers instance and versions (e.g. capi-kubeadm-control-plane-system/kubeadm:v0.3.0) to upgrade to. This flag can be used as alternative to --contract.")
+	upgradeApplyCmd.Flags().StringSliceVar(&ua.imageOverrides, "image-override", nil,
+		"Image overrides in the form <component>[/<image>].<repository|tag>=<value> (e.g. all.repository=myorg.io/local-repo). "+
+			"Image overrides defined using this flag take precedence over the ones defined in the clusterctl configuration file.")
+	upgradeApplyCmd.Flags().StringVar(&ua.backupDirectory, "backup-directory", "",
+		"The directory where the provider inventory and all the Cluster API objects, including Secrets in plaintext, are saved before upgrading. "+
+			"If unspecified, the CLUSTERCTL_UPGRADE_BACKUP_DIRECTORY variable is used, if defined; otherwise no backup is taken.")
+	upgradeApplyCmd.Flags().StringSliceVar(&ua.phases, "phases", nil,
+		"The installation phases to execute, crds for upgrading only the CustomResourceDefinitions, components for upgrading all the other provider components (e.g. --phases crds). "+
+			"If unspecified, all the phases are executed.")
+	upgradeApplyCmd.Flags().StringSliceVar(&ua.skipProviders, "skip-providers", nil,
+		"Providers instance (e.g. capa-system/aws) to be held back at their current version while upgrading all the other providers with --contract. "+
+			"The current version of the skipped providers must support the target contract.")
+	upgradeApplyCmd.Flags().BoolVar(&ua.requireSigned, "require-signed", false,
+		"Requires provider components and metadata to be signed and verified using the signature verification configuration defined in the clusterctl configuration file.")
+	upgradeApplyCmd.Flags().BoolVar(&ua.dryRun, "dry-run", false,
+		"Report the provider components that would be added, changed or removed by the upgrade, without changing the management cluster.")
 }
 
 func runUpgradeApply() error {
-	c, err := client.New(cfgFile)
+	c, err := newClientWithImageOverrides(ua.imageOverrides, config.InjectRequireSigned(ua.requireSigned))
 	if err != nil {
 		return err
 	}
@@ -90,6 +129,10 @@ func runUpgradeApply() error {
 		return errors.New("The --contract flag can't be used in combination with --core, --bootstrap, --control-plane, --infrastructure")
 	}
 
+	if len(ua.skipProviders) > 0 && ua.contract == "" {
+		return errors.New("The --skip-providers flag can be used only in combination with --contract")
+	}
+
 	return c.ApplyUpgrade(client.ApplyUpgradeOptions{
 		Kubeconfig:              client.Kubeconfig{Path: ua.kubeconfig, Context: ua.kubeconfigContext},
 		Contract:                ua.contract,
@@ -97,5 +140,9 @@ func runUpgradeApply() error {
 		BootstrapProviders:      ua.bootstrapProviders,
 		ControlPlaneProviders:   ua.controlPlaneProviders,
 		InfrastructureProviders: ua.infrastructureProviders,
+		BackupDirectory:         ua.backupDirectory,
+		Phases:                  ua.phases,
+		SkipProviders:           ua.skipProviders,
+		DryRun:                  ua.dryRun,
 	})
 }
@@ -121,6 +121,8 @@ func runUpgradePlan() error {
 		}
 		fmt.Println("")
 
+		printUpgradeNotes(plan)
+
 		if upgradeAvailable {
 			if plan.Contract == clusterv1.GroupVersion.Version {
 				fmt.Println("You can now apply the upgrade by executing the following command:")
@@ -137,3 +139,25 @@ func runUpgradePlan() error {
 
 	return nil
 }
+
+// printUpgradeNotes prints the upgrade notes defined in the provider metadata for the release series included in the upgrade plan.
+func printUpgradeNotes(plan client.UpgradePlan) {
+	notesAvailable := false
+	for _, upgradeItem := range plan.Providers {
+		if len(upgradeItem.UpgradeNotes) == 0 {
+			continue
+		}
+		if !notesAvailable {
+			fmt.Println("Please read the following notes before upgrading:")
+			fmt.Println("")
+			notesAvailable = true
+		}
+		fmt.Printf("%s (%s -> %s):\n", upgradeItem.Provider.Name, upgradeItem.Provider.Version, upgradeItem.NextVersion)
+		for _, note := range upgradeItem.UpgradeNotes {
+			fmt.Printf("  - %s\n", note)
+		}
+	}
+	if notesAvailable {
+		fmt.Println("")
+	}
+}
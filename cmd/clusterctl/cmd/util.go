@@ -17,6 +17,7 @@ limitations under the License.
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -26,6 +27,7 @@ import (
 	"text/tabwriter"
 
 	"github.com/pkg/errors"
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
 )
 
@@ -63,48 +65,8 @@ func printVariablesOutput(template client.Template, options client.GetClusterTem
 			}
 		}
 
-		// Fix up default for well-know variables that have a special logic implemented in clusterctl.
-		// NOTE: this logic mimics the defaulting rules implemented in client.GetClusterTemplate;
-		switch name {
-		case "CLUSTER_NAME":
-			// Cluster name from the cmd arguments is used instead of template default.
-			variableMap[name] = stringPtr(options.ClusterName)
-		case "NAMESPACE":
-			// Namespace name from the cmd flags or from the kubeconfig is used instead of template default.
-			if options.TargetNamespace != "" {
-				variableMap[name] = stringPtr(options.TargetNamespace)
-			} else {
-				variableMap[name] = stringPtr("current Namespace in the KubeConfig file")
-			}
-		case "CONTROL_PLANE_MACHINE_COUNT":
-			// Control plane machine count uses the cmd flag, env variable or a constant is used instead of template default.
-			if options.ControlPlaneMachineCount == nil {
-				if val, ok := os.LookupEnv("CONTROL_PLANE_MACHINE_COUNT"); ok {
-					variableMap[name] = stringPtr(val)
-				} else {
-					variableMap[name] = stringPtr("1")
-				}
-			} else {
-				variableMap[name] = stringPtr(strconv.FormatInt(*options.ControlPlaneMachineCount, 10))
-			}
-		case "WORKER_MACHINE_COUNT":
-			// Worker machine count uses the cmd flag, env variable or a constant is used instead of template default.
-			if options.WorkerMachineCount == nil {
-				if val, ok := os.LookupEnv("WORKER_MACHINE_COUNT"); ok {
-					variableMap[name] = stringPtr(val)
-				} else {
-					variableMap[name] = stringPtr("0")
-				}
-			} else {
-				variableMap[name] = stringPtr(strconv.FormatInt(*options.WorkerMachineCount, 10))
-			}
-		case "KUBERNETES_VERSION":
-			// Kubernetes version uses the cmd flag, env variable, or the template default.
-			if options.KubernetesVersion != "" {
-				variableMap[name] = stringPtr(options.KubernetesVersion)
-			} else if val, ok := os.LookupEnv("KUBERNETES_VERSION"); ok {
-				variableMap[name] = stringPtr(val)
-			}
+		if v, ok := wellKnownVariableDefault(name, options); ok {
+			variableMap[name] = v
 		}
 
 		if variableMap[name] != nil {
@@ -138,6 +100,165 @@ func printVariablesOutput(template client.Template, options client.GetClusterTem
 	return nil
 }
 
+// wellKnownVariableDefault returns the default for well-know variables that have a special logic implemented in clusterctl.
+// NOTE: this logic mimics the defaulting rules implemented in client.GetClusterTemplate.
+func wellKnownVariableDefault(name string, options client.GetClusterTemplateOptions) (*string, bool) {
+	switch name {
+	case "CLUSTER_NAME":
+		// Cluster name from the cmd arguments is used instead of template default.
+		return stringPtr(options.ClusterName), true
+	case "NAMESPACE":
+		// Namespace name from the cmd flags or from the kubeconfig is used instead of template default.
+		if options.TargetNamespace != "" {
+			return stringPtr(options.TargetNamespace), true
+		}
+		return stringPtr("current Namespace in the KubeConfig file"), true
+	case "CONTROL_PLANE_MACHINE_COUNT":
+		// Control plane machine count uses the cmd flag, env variable or a constant is used instead of template default.
+		if options.ControlPlaneMachineCount == nil {
+			if val, ok := os.LookupEnv("CONTROL_PLANE_MACHINE_COUNT"); ok {
+				return stringPtr(val), true
+			}
+			return stringPtr("1"), true
+		}
+		return stringPtr(strconv.FormatInt(*options.ControlPlaneMachineCount, 10)), true
+	case "WORKER_MACHINE_COUNT":
+		// Worker machine count uses the cmd flag, env variable or a constant is used instead of template default.
+		if options.WorkerMachineCount == nil {
+			if val, ok := os.LookupEnv("WORKER_MACHINE_COUNT"); ok {
+				return stringPtr(val), true
+			}
+			return stringPtr("0"), true
+		}
+		return stringPtr(strconv.FormatInt(*options.WorkerMachineCount, 10)), true
+	case "KUBERNETES_VERSION":
+		// Kubernetes version uses the cmd flag, env variable, or the template default.
+		if options.KubernetesVersion != "" {
+			return stringPtr(options.KubernetesVersion), true
+		} else if val, ok := os.LookupEnv("KUBERNETES_VERSION"); ok {
+			return stringPtr(val), true
+		}
+	}
+	return nil, false
+}
+
+// wellKnownVariableDefinitions describes the well-know variables that have a special logic implemented in clusterctl;
+// providers can override these definitions in their metadata file.
+var wellKnownVariableDefinitions = map[string]clusterctlv1.VariableDefinition{
+	"CLUSTER_NAME":                {Type: "string", Description: "The name of the workload cluster."},
+	"NAMESPACE":                   {Type: "string", Description: "The namespace of the workload cluster; if not set, the current Namespace in the KubeConfig file is used."},
+	"KUBERNETES_VERSION":          {Type: "string", Description: "The Kubernetes version of the workload cluster."},
+	"CONTROL_PLANE_MACHINE_COUNT": {Type: "integer", Description: "The number of control plane machines of the workload cluster."},
+	"WORKER_MACHINE_COUNT":        {Type: "integer", Description: "The number of worker machines of the workload cluster."},
+}
+
+// variablesSchema is a JSON schema describing the variables expected by a template.
+type variablesSchema struct {
+	Schema     string                    `json:"$schema"`
+	Type       string                    `json:"type"`
+	Properties map[string]variableSchema `json:"properties"`
+	Required   []string                  `json:"required,omitempty"`
+}
+
+// variableSchema is a JSON schema describing a variable expected by a template.
+type variableSchema struct {
+	Type        string        `json:"type"`
+	Description string        `json:"description,omitempty"`
+	Default     interface{}   `json:"default,omitempty"`
+	Enum        []interface{} `json:"enum,omitempty"`
+}
+
+// printVariablesJSONSchemaOutput prints a JSON schema describing the expected variables in the template to stdout.
+func printVariablesJSONSchemaOutput(template client.Template, options client.GetClusterTemplateOptions) error {
+	schema, err := variablesJSONSchema(template, options)
+	if err != nil {
+		return err
+	}
+	schema = append(schema, '\n')
+
+	if _, err := os.Stdout.Write(schema); err != nil {
+		return errors.Wrap(err, "failed to write JSON schema to Stdout")
+	}
+	return nil
+}
+
+// variablesJSONSchema returns a JSON schema describing the expected variables in the template, using the
+// variable definitions from the provider metadata, if any, to set the type and the description of each variable.
+func variablesJSONSchema(template client.Template, options client.GetClusterTemplateOptions) ([]byte, error) {
+	definitions := map[string]clusterctlv1.VariableDefinition{}
+	for name, definition := range wellKnownVariableDefinitions {
+		definitions[name] = definition
+	}
+	for _, definition := range template.VariableDefinitions() {
+		definitions[definition.Name] = definition
+	}
+
+	schema := variablesSchema{
+		Schema:     "http://json-schema.org/draft-07/schema#",
+		Type:       "object",
+		Properties: map[string]variableSchema{},
+	}
+	for name, value := range template.VariableMap() {
+		property := variableSchema{
+			Type:        definitions[name].Type,
+			Description: definitions[name].Description,
+		}
+		if property.Type == "" {
+			property.Type = "string"
+		}
+		for _, allowed := range definitions[name].Enum {
+			allowedValue, err := typedVariableValue(allowed, property.Type)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid allowed value for variable %q", name)
+			}
+			property.Enum = append(property.Enum, allowedValue)
+		}
+
+		// If the namespace is not set, the current Namespace in the KubeConfig file is used; this
+		// cannot be expressed as a default value, but the variable is not required either.
+		if name == "NAMESPACE" && options.TargetNamespace == "" {
+			schema.Properties[name] = property
+			continue
+		}
+		if v, ok := wellKnownVariableDefault(name, options); ok {
+			value = v
+		}
+
+		if value == nil {
+			schema.Required = append(schema.Required, name)
+		} else {
+			defaultValue, err := typedVariableValue(*value, property.Type)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid default value for variable %q", name)
+			}
+			property.Default = defaultValue
+		}
+		schema.Properties[name] = property
+	}
+	sort.Strings(schema.Required)
+
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// typedVariableValue converts the value of a variable to the given JSON schema type.
+func typedVariableValue(value, valueType string) (interface{}, error) {
+	switch valueType {
+	case "string":
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			return unquoted, nil
+		}
+		return value, nil
+	case "integer":
+		return strconv.ParseInt(value, 10, 64)
+	case "number":
+		return strconv.ParseFloat(value, 64)
+	case "boolean":
+		return strconv.ParseBool(value)
+	default:
+		return nil, errors.Errorf("unsupported type %q", valueType)
+	}
+}
+
 // printComponentsAsText prints information about the components to stdout.
 func printComponentsAsText(c client.Components) error {
 	dir, file := filepath.Split(c.URL())
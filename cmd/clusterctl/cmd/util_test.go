@@ -0,0 +1,88 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/repository"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/yamlprocessor"
+)
+
+func Test_variablesJSONSchema(t *testing.T) {
+	g := NewWithT(t)
+
+	template, err := repository.NewTemplate(repository.TemplateInput{
+		RawArtifact: []byte(`name: ${CLUSTER_NAME}
+namespace: ${NAMESPACE}
+region: ${REGION}
+tier: ${TIER:=standard}
+replicas: ${WORKER_MACHINE_COUNT}
+size: ${DISK_SIZE:=20}
+debug: ${DEBUG:=false}`),
+		Processor:           yamlprocessor.NewSimpleProcessor(),
+		SkipTemplateProcess: true,
+		VariableDefinitions: []clusterctlv1.VariableDefinition{
+			{Name: "REGION", Description: "The region of the workload cluster."},
+			{Name: "DISK_SIZE", Type: "integer"},
+			{Name: "DEBUG", Type: "boolean"},
+			{Name: "TIER", Enum: []string{"standard", "premium"}},
+		},
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	workerMachineCount := int64(3)
+	schema, err := variablesJSONSchema(template, client.GetClusterTemplateOptions{
+		ClusterName:        "my-cluster",
+		WorkerMachineCount: &workerMachineCount,
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(schema).To(MatchJSON(`{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "type": "object",
+  "properties": {
+    "CLUSTER_NAME": {"type": "string", "description": "The name of the workload cluster.", "default": "my-cluster"},
+    "NAMESPACE": {"type": "string", "description": "The namespace of the workload cluster; if not set, the current Namespace in the KubeConfig file is used."},
+    "REGION": {"type": "string", "description": "The region of the workload cluster."},
+    "WORKER_MACHINE_COUNT": {"type": "integer", "description": "The number of worker machines of the workload cluster.", "default": 3},
+    "DISK_SIZE": {"type": "integer", "default": 20},
+    "DEBUG": {"type": "boolean", "default": false},
+    "TIER": {"type": "string", "default": "standard", "enum": ["standard", "premium"]}
+  },
+  "required": ["REGION"]
+}`))
+}
+
+func Test_variablesJSONSchema_InvalidDefault(t *testing.T) {
+	g := NewWithT(t)
+
+	template, err := repository.NewTemplate(repository.TemplateInput{
+		RawArtifact:         []byte(`size: ${DISK_SIZE:=large}`),
+		Processor:           yamlprocessor.NewSimpleProcessor(),
+		SkipTemplateProcess: true,
+		VariableDefinitions: []clusterctlv1.VariableDefinition{
+			{Name: "DISK_SIZE", Type: "integer"},
+		},
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	_, err = variablesJSONSchema(template, client.GetClusterTemplateOptions{})
+	g.Expect(err).To(HaveOccurred())
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+)
+
+type validateOptions struct {
+	kubeconfig        string
+	kubeconfigContext string
+	file              string
+	skipServerDryRun  bool
+}
+
+var vo = &validateOptions{}
+
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate a cluster template against the CRDs and webhooks installed in a management cluster.",
+	Long: LongDesc(`
+		Validate a rendered cluster template against the CRDs and webhooks installed in a management cluster,
+		without creating any object.
+
+		Each object is validated against the OpenAPI schema of the corresponding CRD, reporting unknown fields,
+		which are otherwise silently dropped by the API server, missing required fields and values of the wrong type.
+		Then, unless --skip-server-dry-run is set, each object is submitted to the management cluster in dry-run mode,
+		so the API server and the admission webhooks validate it; objects already existing, or whose namespace does
+		not exist, are not submitted.`),
+
+	Example: Examples(`
+		# Validate a cluster template before applying it.
+		clusterctl generate cluster my-cluster > my-cluster.yaml
+		clusterctl alpha validate -f my-cluster.yaml
+
+		# Validate a cluster template against the CRD schemas only.
+		clusterctl alpha validate -f my-cluster.yaml --skip-server-dry-run`),
+
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runValidate()
+	},
+}
+
+func init() {
+	validateCmd.Flags().StringVar(&vo.kubeconfig, "kubeconfig", "",
+		"Path to the kubeconfig file for the management cluster. If unspecified, default discovery rules apply.")
+	validateCmd.Flags().StringVar(&vo.kubeconfigContext, "kubeconfig-context", "",
+		"Context to be used within the kubeconfig file. If empty, current context will be used.")
+	validateCmd.Flags().StringVarP(&vo.file, "file", "f", "",
+		"The path of the file with the rendered cluster template to validate.")
+	validateCmd.Flags().BoolVar(&vo.skipServerDryRun, "skip-server-dry-run", false,
+		"Validate the objects against the CRD schemas only, without submitting them to the management cluster in dry-run mode.")
+}
+
+func runValidate() error {
+	if vo.file == "" {
+		return errors.New("please specify the cluster template to validate using the --file flag")
+	}
+
+	c, err := client.New(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	issues, err := c.Validate(client.ValidateOptions{
+		Kubeconfig:       client.Kubeconfig{Path: vo.kubeconfig, Context: vo.kubeconfigContext},
+		File:             vo.file,
+		SkipServerDryRun: vo.skipServerDryRun,
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(issues) == 0 {
+		fmt.Printf("No issues found in %s\n", vo.file)
+		return nil
+	}
+	for _, issue := range issues {
+		fmt.Println(issue.String())
+	}
+	return errors.Errorf("found %d issues in %s", len(issues), vo.file)
+}
@@ -17,6 +17,9 @@ limitations under the License.
 package test
 
 import (
+	"context"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -31,14 +34,16 @@ import (
 	fakeinfrastructure "sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test/providers/infrastructure"
 	addonsv1 "sigs.k8s.io/cluster-api/exp/addons/api/v1alpha4"
 	expv1 "sigs.k8s.io/cluster-api/exp/api/v1alpha4"
+	ipamv1 "sigs.k8s.io/cluster-api/exp/ipam/api/v1alpha4"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 type FakeProxy struct {
-	cs        client.Client
-	namespace string
-	objs      []client.Object
+	cs           client.Client
+	namespace    string
+	objs         []client.Object
+	deniedAccess []authorizationv1.ResourceAttributes
 }
 
 var (
@@ -51,6 +56,7 @@ func init() {
 	_ = clusterv1.AddToScheme(FakeScheme)
 	_ = expv1.AddToScheme(FakeScheme)
 	_ = addonsv1.AddToScheme(FakeScheme)
+	_ = ipamv1.AddToScheme(FakeScheme)
 	_ = apiextensionsv1.AddToScheme(FakeScheme)
 
 	_ = fakebootstrap.AddToScheme(FakeScheme)
@@ -75,10 +81,39 @@ func (f *FakeProxy) NewClient() (client.Client, error) {
 	if f.cs != nil {
 		return f.cs, nil
 	}
-	f.cs = fake.NewClientBuilder().WithScheme(FakeScheme).WithObjects(f.objs...).Build()
+	f.cs = &fakeAccessReviewClient{
+		Client:       fake.NewClientBuilder().WithScheme(FakeScheme).WithObjects(f.objs...).Build(),
+		deniedAccess: f.deniedAccess,
+	}
 	return f.cs, nil
 }
 
+// fakeAccessReviewClient is a fake client answering SelfSubjectAccessReviews, given that the fake client
+// does not implement authorization; all the requests are allowed, except the ones explicitly denied.
+type fakeAccessReviewClient struct {
+	client.Client
+	deniedAccess []authorizationv1.ResourceAttributes
+}
+
+func (c *fakeAccessReviewClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	review, ok := obj.(*authorizationv1.SelfSubjectAccessReview)
+	if !ok {
+		return c.Client.Create(ctx, obj, opts...)
+	}
+
+	review.Status.Allowed = true
+	if review.Spec.ResourceAttributes != nil {
+		for _, denied := range c.deniedAccess {
+			if *review.Spec.ResourceAttributes == denied {
+				review.Status.Allowed = false
+				review.Status.Reason = "denied by the fake proxy"
+				break
+			}
+		}
+	}
+	return nil
+}
+
 // ListResources returns all the resources known by the FakeProxy.
 func (f *FakeProxy) ListResources(labels map[string]string, namespaces ...string) ([]unstructured.Unstructured, error) {
 	var ret []unstructured.Unstructured //nolint
@@ -138,6 +173,12 @@ func (f *FakeProxy) WithNamespace(n string) *FakeProxy {
 	return f
 }
 
+// WithDeniedAccess denies the given requests when checking the permissions of the current identity.
+func (f *FakeProxy) WithDeniedAccess(attributes ...authorizationv1.ResourceAttributes) *FakeProxy {
+	f.deniedAccess = append(f.deniedAccess, attributes...)
+	return f
+}
+
 // WithProviderInventory can be used as a fast track for setting up test scenarios requiring an already initialized management cluster.
 // NB. this method adds an items to the Provider inventory, but it doesn't install the corresponding provider; if the
 // test case requires the actual provider to be installed, use the the fake client to install both the provider
@@ -18,6 +18,8 @@ package test
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -67,6 +69,21 @@ func (f *FakeRepository) GetVersions() ([]string, error) {
 	return v, nil
 }
 
+func (f *FakeRepository) ListFiles(version string) ([]string, error) {
+	if _, ok := f.versions[version]; !ok {
+		return nil, errors.Errorf("unable to list files for version %s", version)
+	}
+
+	files := []string{}
+	for p := range f.files {
+		if strings.HasPrefix(p, vpath(version, "")) {
+			files = append(files, strings.TrimPrefix(p, vpath(version, "")))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
 func NewFakeRepository() *FakeRepository {
 	return &FakeRepository{
 		versions: map[string]bool{},
@@ -29,6 +29,7 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/record"
@@ -68,18 +69,36 @@ type ClusterReconciler struct {
 	Client           client.Client
 	WatchFilterValue string
 
+	// ControlPlaneEndpointProbeInterval is the interval between two probes of the control plane endpoint of
+	// each cluster; probing is disabled if zero.
+	ControlPlaneEndpointProbeInterval time.Duration
+
 	restConfig      *rest.Config
 	recorder        record.EventRecorder
 	externalTracker external.ObjectTracker
+	endpointProber  *controlPlaneEndpointProber
 }
 
 func (r *ClusterReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, options controller.Options) error {
-	controller, err := ctrl.NewControllerManagedBy(mgr).
+	builder := ctrl.NewControllerManagedBy(mgr).
 		For(&clusterv1.Cluster{}).
 		Watches(
 			&source.Kind{Type: &clusterv1.Machine{}},
 			handler.EnqueueRequestsFromMapFunc(r.controlPlaneMachineToCluster),
-		).
+		)
+
+	if r.ControlPlaneEndpointProbeInterval > 0 {
+		r.endpointProber = newControlPlaneEndpointProber(r.ControlPlaneEndpointProbeInterval, probeControlPlaneEndpoint)
+		if err := mgr.Add(r.endpointProber); err != nil {
+			return errors.Wrap(err, "failed to add the control plane endpoint prober to the controller manager")
+		}
+		builder = builder.Watches(
+			&source.Channel{Source: r.endpointProber.events},
+			&handler.EnqueueRequestForObject{},
+		)
+	}
+
+	controller, err := builder.
 		WithOptions(options).
 		WithEventFilter(predicates.ResourceNotPausedAndHasFilterLabel(ctrl.LoggerFrom(ctx), r.WatchFilterValue)).
 		Build(r)
@@ -103,6 +122,9 @@ func (r *ClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_
 	cluster := &clusterv1.Cluster{}
 	if err := r.Client.Get(ctx, req.NamespacedName, cluster); err != nil {
 		if apierrors.IsNotFound(err) {
+			if r.endpointProber != nil {
+				r.endpointProber.untrack(req.NamespacedName)
+			}
 			// Object not found, return.  Created objects are automatically garbage collected.
 			// For additional cleanup logic use finalizers.
 			return ctrl.Result{}, nil
@@ -171,6 +193,8 @@ func patchCluster(ctx context.Context, patchHelper *patch.Helper, cluster *clust
 			clusterv1.ReadyCondition,
 			clusterv1.ControlPlaneReadyCondition,
 			clusterv1.InfrastructureReadyCondition,
+			clusterv1.APIVersionsUpToDateCondition,
+			clusterv1.ControlPlaneEndpointReachableCondition,
 		}},
 	)
 	return patchHelper.Patch(ctx, cluster, options...)
@@ -193,6 +217,9 @@ func (r *ClusterReconciler) reconcile(ctx context.Context, cluster *clusterv1.Cl
 		r.reconcileControlPlane,
 		r.reconcileKubeconfig,
 		r.reconcileControlPlaneInitialized,
+		r.reconcileControlPlaneEndpoint,
+		r.reconcileDeprecatedAPIVersions,
+		r.reconcilePropagatedMetadata,
 	}
 
 	res := ctrl.Result{}
@@ -215,6 +242,10 @@ func (r *ClusterReconciler) reconcile(ctx context.Context, cluster *clusterv1.Cl
 func (r *ClusterReconciler) reconcileDelete(ctx context.Context, cluster *clusterv1.Cluster) (reconcile.Result, error) {
 	log := ctrl.LoggerFrom(ctx)
 
+	if r.endpointProber != nil {
+		r.endpointProber.untrack(types.NamespacedName{Namespace: cluster.Namespace, Name: cluster.Name})
+	}
+
 	descendants, err := r.listDescendants(ctx, cluster)
 	if err != nil {
 		log.Error(err, "Failed to list descendants")
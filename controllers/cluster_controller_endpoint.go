@@ -0,0 +1,242 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+const (
+	// controlPlaneEndpointProbeTimeout is the timeout of a single probe of a control plane endpoint.
+	controlPlaneEndpointProbeTimeout = 10 * time.Second
+)
+
+// controlPlaneEndpointProbeFunc checks whether the API server behind a control plane endpoint is reachable.
+type controlPlaneEndpointProbeFunc func(ctx context.Context, endpoint clusterv1.APIEndpoint) error
+
+// controlPlaneEndpointProbeResult is the result of the last probe of the control plane endpoint of a cluster.
+type controlPlaneEndpointProbeResult struct {
+	endpoint clusterv1.APIEndpoint
+	probed   bool
+	err      error
+}
+
+// controlPlaneEndpointProber periodically probes the control plane endpoints of the tracked clusters in the
+// background, so the Cluster reconciler can report whether the endpoints are reachable without blocking on the
+// network; when the outcome of a probe changes, the cluster is sent to the events channel to be reconciled again.
+type controlPlaneEndpointProber struct {
+	interval time.Duration
+	probe    controlPlaneEndpointProbeFunc
+
+	lock    sync.Mutex
+	results map[types.NamespacedName]*controlPlaneEndpointProbeResult
+
+	// trigger is used to probe the endpoints of newly tracked clusters without waiting for the next interval.
+	trigger chan struct{}
+	events  chan event.GenericEvent
+}
+
+func newControlPlaneEndpointProber(interval time.Duration, probe controlPlaneEndpointProbeFunc) *controlPlaneEndpointProber {
+	return &controlPlaneEndpointProber{
+		interval: interval,
+		probe:    probe,
+		results:  map[types.NamespacedName]*controlPlaneEndpointProbeResult{},
+		trigger:  make(chan struct{}, 1),
+		events:   make(chan event.GenericEvent),
+	}
+}
+
+// Start starts probing the control plane endpoints of the tracked clusters, until the context is done.
+func (p *controlPlaneEndpointProber) Start(ctx context.Context) error {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			p.probeAll(ctx, false)
+		case <-p.trigger:
+			p.probeAll(ctx, true)
+		}
+	}
+}
+
+// track starts probing the control plane endpoint of the cluster, and returns the result of the last probe;
+// if the endpoint changed, the previous result is discarded.
+func (p *controlPlaneEndpointProber) track(cluster *clusterv1.Cluster) controlPlaneEndpointProbeResult {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	key := types.NamespacedName{Namespace: cluster.Namespace, Name: cluster.Name}
+	result, ok := p.results[key]
+	if !ok || result.endpoint != cluster.Spec.ControlPlaneEndpoint {
+		result = &controlPlaneEndpointProbeResult{endpoint: cluster.Spec.ControlPlaneEndpoint}
+		p.results[key] = result
+
+		select {
+		case p.trigger <- struct{}{}:
+		default:
+		}
+	}
+	return *result
+}
+
+// untrack stops probing the control plane endpoint of the cluster.
+func (p *controlPlaneEndpointProber) untrack(key types.NamespacedName) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	delete(p.results, key)
+}
+
+// probeAll probes the control plane endpoints of the tracked clusters concurrently; if onlyNew is true, only the
+// endpoints never probed are.
+func (p *controlPlaneEndpointProber) probeAll(ctx context.Context, onlyNew bool) {
+	p.lock.Lock()
+	endpoints := map[types.NamespacedName]clusterv1.APIEndpoint{}
+	for key, result := range p.results {
+		if onlyNew && result.probed {
+			continue
+		}
+		endpoints[key] = result.endpoint
+	}
+	p.lock.Unlock()
+
+	wg := sync.WaitGroup{}
+	for key, endpoint := range endpoints {
+		wg.Add(1)
+		go func(key types.NamespacedName, endpoint clusterv1.APIEndpoint) {
+			defer wg.Done()
+
+			probeCtx, cancel := context.WithTimeout(ctx, controlPlaneEndpointProbeTimeout)
+			defer cancel()
+			err := p.probe(probeCtx, endpoint)
+
+			if p.setResult(key, endpoint, err) {
+				p.notify(ctx, key)
+			}
+		}(key, endpoint)
+	}
+	wg.Wait()
+}
+
+// setResult records the result of a probe, and returns true if the outcome changed; results for endpoints
+// no longer tracked are discarded.
+func (p *controlPlaneEndpointProber) setResult(key types.NamespacedName, endpoint clusterv1.APIEndpoint, err error) bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	result, ok := p.results[key]
+	if !ok || result.endpoint != endpoint {
+		return false
+	}
+
+	changed := !result.probed || errorMessage(result.err) != errorMessage(err)
+	result.probed = true
+	result.err = err
+	return changed
+}
+
+// notify sends the cluster to the events channel, so it is reconciled again.
+func (p *controlPlaneEndpointProber) notify(ctx context.Context, key types.NamespacedName) {
+	select {
+	case p.events <- event.GenericEvent{Object: &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name}}}:
+	case <-ctx.Done():
+	}
+}
+
+func errorMessage(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// probeControlPlaneEndpoint checks that the API server behind the control plane endpoint answers on /healthz.
+// The request is anonymous, so responses denying access are considered healthy as well: the purpose is detecting
+// an endpoint which can't be reached, not checking the API server permissions.
+func probeControlPlaneEndpoint(ctx context.Context, endpoint clusterv1.APIEndpoint) error {
+	client := &http.Client{
+		Transport: &http.Transport{
+			// The endpoint is probed without the cluster CA; no credentials are sent.
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, //nolint:gosec
+		},
+	}
+	defer client.CloseIdleConnections()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("https://%s/healthz", endpoint.String()), nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to create the /healthz request")
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "failed to reach the control plane endpoint %s", endpoint.String())
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusUnauthorized, http.StatusForbidden:
+		return nil
+	default:
+		return errors.Errorf("the control plane endpoint %s answered /healthz with status %d", endpoint.String(), resp.StatusCode)
+	}
+}
+
+// reconcileControlPlaneEndpoint reports in the ControlPlaneEndpointReachable condition the result of the last probe
+// of the control plane endpoint of the cluster.
+func (r *ClusterReconciler) reconcileControlPlaneEndpoint(ctx context.Context, cluster *clusterv1.Cluster) (ctrl.Result, error) {
+	if r.endpointProber == nil {
+		return ctrl.Result{}, nil
+	}
+
+	if !cluster.Spec.ControlPlaneEndpoint.IsValid() {
+		r.endpointProber.untrack(types.NamespacedName{Namespace: cluster.Namespace, Name: cluster.Name})
+		conditions.MarkFalse(cluster, clusterv1.ControlPlaneEndpointReachableCondition, clusterv1.WaitingForControlPlaneEndpointReason, clusterv1.ConditionSeverityInfo, "")
+		return ctrl.Result{}, nil
+	}
+
+	result := r.endpointProber.track(cluster)
+	switch {
+	case !result.probed:
+		conditions.MarkUnknown(cluster, clusterv1.ControlPlaneEndpointReachableCondition, clusterv1.WaitingForControlPlaneEndpointReason, "Waiting for the control plane endpoint to be probed")
+	case result.err != nil:
+		// Failing probes are expected while the control plane is being initialized.
+		severity := clusterv1.ConditionSeverityInfo
+		if conditions.IsTrue(cluster, clusterv1.ControlPlaneInitializedCondition) {
+			severity = clusterv1.ConditionSeverityWarning
+		}
+		conditions.MarkFalse(cluster, clusterv1.ControlPlaneEndpointReachableCondition, clusterv1.ControlPlaneEndpointUnreachableReason, severity, "%s", result.err.Error())
+	default:
+		conditions.MarkTrue(cluster, clusterv1.ControlPlaneEndpointReachableCondition)
+	}
+	return ctrl.Result{}, nil
+}
@@ -0,0 +1,205 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+func TestReconcileControlPlaneEndpoint(t *testing.T) {
+	newCluster := func(endpoint clusterv1.APIEndpoint) *clusterv1.Cluster {
+		return &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: metav1.NamespaceDefault},
+			Spec:       clusterv1.ClusterSpec{ControlPlaneEndpoint: endpoint},
+		}
+	}
+	endpoint := clusterv1.APIEndpoint{Host: "example.com", Port: 6443}
+
+	t.Run("waits for the control plane endpoint to be set", func(t *testing.T) {
+		g := NewWithT(t)
+
+		r := &ClusterReconciler{endpointProber: newControlPlaneEndpointProber(time.Minute, nil)}
+		cluster := newCluster(clusterv1.APIEndpoint{})
+
+		_, err := r.reconcileControlPlaneEndpoint(ctx, cluster)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(conditions.IsFalse(cluster, clusterv1.ControlPlaneEndpointReachableCondition)).To(BeTrue())
+		g.Expect(conditions.GetReason(cluster, clusterv1.ControlPlaneEndpointReachableCondition)).To(Equal(clusterv1.WaitingForControlPlaneEndpointReason))
+		g.Expect(r.endpointProber.results).To(BeEmpty())
+	})
+
+	t.Run("waits for the control plane endpoint to be probed", func(t *testing.T) {
+		g := NewWithT(t)
+
+		r := &ClusterReconciler{endpointProber: newControlPlaneEndpointProber(time.Minute, nil)}
+		cluster := newCluster(endpoint)
+
+		_, err := r.reconcileControlPlaneEndpoint(ctx, cluster)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(conditions.IsUnknown(cluster, clusterv1.ControlPlaneEndpointReachableCondition)).To(BeTrue())
+		g.Expect(r.endpointProber.results).To(HaveKey(types.NamespacedName{Namespace: cluster.Namespace, Name: cluster.Name}))
+	})
+
+	t.Run("reports the result of the last probe", func(t *testing.T) {
+		g := NewWithT(t)
+
+		p := newControlPlaneEndpointProber(time.Minute, nil)
+		r := &ClusterReconciler{endpointProber: p}
+		cluster := newCluster(endpoint)
+		key := types.NamespacedName{Namespace: cluster.Namespace, Name: cluster.Name}
+
+		_, err := r.reconcileControlPlaneEndpoint(ctx, cluster)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(p.setResult(key, endpoint, nil)).To(BeTrue())
+
+		_, err = r.reconcileControlPlaneEndpoint(ctx, cluster)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(conditions.IsTrue(cluster, clusterv1.ControlPlaneEndpointReachableCondition)).To(BeTrue())
+
+		// Failures are reported with Info severity until the control plane is initialized.
+		g.Expect(p.setResult(key, endpoint, errors.New("connection refused"))).To(BeTrue())
+		_, err = r.reconcileControlPlaneEndpoint(ctx, cluster)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(conditions.IsFalse(cluster, clusterv1.ControlPlaneEndpointReachableCondition)).To(BeTrue())
+		g.Expect(conditions.GetReason(cluster, clusterv1.ControlPlaneEndpointReachableCondition)).To(Equal(clusterv1.ControlPlaneEndpointUnreachableReason))
+		g.Expect(*conditions.GetSeverity(cluster, clusterv1.ControlPlaneEndpointReachableCondition)).To(Equal(clusterv1.ConditionSeverityInfo))
+		g.Expect(conditions.GetMessage(cluster, clusterv1.ControlPlaneEndpointReachableCondition)).To(Equal("connection refused"))
+
+		conditions.MarkTrue(cluster, clusterv1.ControlPlaneInitializedCondition)
+		_, err = r.reconcileControlPlaneEndpoint(ctx, cluster)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(*conditions.GetSeverity(cluster, clusterv1.ControlPlaneEndpointReachableCondition)).To(Equal(clusterv1.ConditionSeverityWarning))
+	})
+
+	t.Run("discards the result when the endpoint changes", func(t *testing.T) {
+		g := NewWithT(t)
+
+		p := newControlPlaneEndpointProber(time.Minute, nil)
+		r := &ClusterReconciler{endpointProber: p}
+		cluster := newCluster(endpoint)
+		key := types.NamespacedName{Namespace: cluster.Namespace, Name: cluster.Name}
+
+		_, err := r.reconcileControlPlaneEndpoint(ctx, cluster)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(p.setResult(key, endpoint, nil)).To(BeTrue())
+
+		cluster.Spec.ControlPlaneEndpoint.Port = 443
+		_, err = r.reconcileControlPlaneEndpoint(ctx, cluster)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(conditions.IsUnknown(cluster, clusterv1.ControlPlaneEndpointReachableCondition)).To(BeTrue())
+
+		// Results of probes of the previous endpoint are ignored.
+		g.Expect(p.setResult(key, endpoint, nil)).To(BeFalse())
+	})
+}
+
+func TestControlPlaneEndpointProber(t *testing.T) {
+	g := NewWithT(t)
+
+	probes := make(chan clusterv1.APIEndpoint, 10)
+	p := newControlPlaneEndpointProber(time.Hour, func(_ context.Context, endpoint clusterv1.APIEndpoint) error {
+		probes <- endpoint
+		return nil
+	})
+
+	proberCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		_ = p.Start(proberCtx)
+	}()
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: metav1.NamespaceDefault},
+		Spec:       clusterv1.ClusterSpec{ControlPlaneEndpoint: clusterv1.APIEndpoint{Host: "example.com", Port: 6443}},
+	}
+
+	// Newly tracked endpoints are probed without waiting for the next interval, and the cluster is notified.
+	g.Expect(p.track(cluster).probed).To(BeFalse())
+	g.Eventually(probes).Should(Receive(Equal(cluster.Spec.ControlPlaneEndpoint)))
+	g.Eventually(p.events).Should(Receive(WithTransform(func(e event.GenericEvent) string {
+		return e.Object.GetName()
+	}, Equal(cluster.Name))))
+	g.Expect(p.track(cluster).probed).To(BeTrue())
+
+	p.untrack(types.NamespacedName{Namespace: cluster.Namespace, Name: cluster.Name})
+	g.Expect(p.results).To(BeEmpty())
+}
+
+func TestProbeControlPlaneEndpoint(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr bool
+	}{
+		{name: "healthy", status: http.StatusOK},
+		{name: "anonymous requests denied", status: http.StatusForbidden},
+		{name: "unhealthy", status: http.StatusInternalServerError, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/healthz" {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			err := probeControlPlaneEndpoint(ctx, testServerEndpoint(g, server))
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+		})
+	}
+
+	t.Run("unreachable", func(t *testing.T) {
+		g := NewWithT(t)
+
+		server := httptest.NewTLSServer(http.NotFoundHandler())
+		endpoint := testServerEndpoint(g, server)
+		server.Close()
+
+		g.Expect(probeControlPlaneEndpoint(ctx, endpoint)).NotTo(Succeed())
+	})
+}
+
+func testServerEndpoint(g *WithT, server *httptest.Server) clusterv1.APIEndpoint {
+	host, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	g.Expect(err).NotTo(HaveOccurred())
+	p, err := strconv.Atoi(port)
+	g.Expect(err).NotTo(HaveOccurred())
+	return clusterv1.APIEndpoint{Host: host, Port: int32(p)}
+}
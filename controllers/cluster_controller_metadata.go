@@ -0,0 +1,184 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machinedeployments;machines,verbs=get;list;watch;patch
+
+// reconcilePropagatedMetadata propagates the Cluster labels and annotations listed in the PropagateLabelsAnnotation and
+// PropagateAnnotationsAnnotation to the MachineDeployments and the Machines of the Cluster; only the objects out of sync are patched.
+// The propagated keys are recorded on each object, so the labels and annotations no longer listed are removed.
+// NOTE: The Machine controller propagates the same labels and annotations to the Nodes.
+func (r *ClusterReconciler) reconcilePropagatedMetadata(ctx context.Context, cluster *clusterv1.Cluster) (ctrl.Result, error) {
+	labels, annotations := propagatedMetadata(cluster)
+
+	log := ctrl.LoggerFrom(ctx)
+
+	machineDeployments := &clusterv1.MachineDeploymentList{}
+	if err := r.Client.List(ctx, machineDeployments, client.InNamespace(cluster.Namespace), client.MatchingLabels{clusterv1.ClusterLabelName: cluster.Name}); err != nil {
+		return ctrl.Result{}, errors.Wrapf(err, "failed to list MachineDeployments for cluster %s/%s", cluster.Namespace, cluster.Name)
+	}
+	machines := &clusterv1.MachineList{}
+	if err := r.Client.List(ctx, machines, client.InNamespace(cluster.Namespace), client.MatchingLabels{clusterv1.ClusterLabelName: cluster.Name}); err != nil {
+		return ctrl.Result{}, errors.Wrapf(err, "failed to list Machines for cluster %s/%s", cluster.Namespace, cluster.Name)
+	}
+
+	// NOTE: Kind is tracked explicitly because TypeMeta is not set on the items of typed lists.
+	type kindObject struct {
+		kind string
+		obj  client.Object
+	}
+	objs := []kindObject{}
+	for i := range machineDeployments.Items {
+		objs = append(objs, kindObject{kind: "MachineDeployment", obj: &machineDeployments.Items[i]})
+	}
+	for i := range machines.Items {
+		objs = append(objs, kindObject{kind: "Machine", obj: &machines.Items[i]})
+	}
+
+	var errs []error
+	for _, o := range objs {
+		obj := o.obj
+		if !obj.GetDeletionTimestamp().IsZero() {
+			continue
+		}
+		patchBase := client.MergeFrom(obj.DeepCopyObject().(client.Object))
+		objLabels, objAnnotations := propagatedMetadataForObject(obj, labels, annotations)
+		newLabels, labelsChanged := applyPropagatedMetadata(obj.GetLabels(), objLabels)
+		newAnnotations, annotationsChanged := applyPropagatedMetadata(obj.GetAnnotations(), objAnnotations)
+		if !labelsChanged && !annotationsChanged {
+			continue
+		}
+		obj.SetLabels(newLabels)
+		obj.SetAnnotations(newAnnotations)
+		if err := r.Client.Patch(ctx, obj, patchBase); err != nil {
+			errs = append(errs, errors.Wrapf(err, "failed to propagate Cluster metadata to %s %s", o.kind, obj.GetName()))
+			continue
+		}
+		log.V(3).Info("Propagated Cluster metadata", "kind", o.kind, "name", obj.GetName())
+	}
+
+	return ctrl.Result{}, kerrors.NewAggregate(errs)
+}
+
+// propagatedMetadata returns the Cluster labels and annotations to be propagated to the objects of the Cluster,
+// as listed in the PropagateLabelsAnnotation and in the PropagateAnnotationsAnnotation; listed keys not set on the
+// Cluster have a nil value, meaning they must be removed from the objects of the Cluster.
+func propagatedMetadata(cluster *clusterv1.Cluster) (map[string]*string, map[string]*string) {
+	fromKeys := func(keys string, values map[string]string) map[string]*string {
+		ret := map[string]*string{}
+		for _, key := range strings.Split(keys, ",") {
+			key = strings.TrimSpace(key)
+			// The propagation annotations are not propagated, so the other objects are never configured for propagation.
+			if key == "" || isPropagationAnnotation(key) {
+				continue
+			}
+			ret[key] = nil
+			if value, ok := values[key]; ok {
+				v := value
+				ret[key] = &v
+			}
+		}
+		return ret
+	}
+	annotations := cluster.GetAnnotations()
+	return fromKeys(annotations[clusterv1.PropagateLabelsAnnotation], cluster.GetLabels()),
+		fromKeys(annotations[clusterv1.PropagateAnnotationsAnnotation], annotations)
+}
+
+// propagatedMetadataForObject returns the Cluster labels and annotations to be propagated to an object, including
+// nil values for the keys recorded as propagated to the object and no longer listed on the Cluster, and the
+// PropagatedLabelsAnnotation and PropagatedAnnotationsAnnotation recording the keys propagated from now on.
+func propagatedMetadataForObject(obj client.Object, labels, annotations map[string]*string) (map[string]*string, map[string]*string) {
+	withRecorded := func(propagated map[string]*string, recorded string) map[string]*string {
+		ret := map[string]*string{}
+		for _, key := range strings.Split(recorded, ",") {
+			if key != "" && !isPropagationAnnotation(key) {
+				ret[key] = nil
+			}
+		}
+		for key, value := range propagated {
+			ret[key] = value
+		}
+		return ret
+	}
+	record := func(propagated map[string]*string) *string {
+		keys := []string{}
+		for key, value := range propagated {
+			if value != nil {
+				keys = append(keys, key)
+			}
+		}
+		if len(keys) == 0 {
+			return nil
+		}
+		sort.Strings(keys)
+		value := strings.Join(keys, ",")
+		return &value
+	}
+
+	current := obj.GetAnnotations()
+	objLabels := withRecorded(labels, current[clusterv1.PropagatedLabelsAnnotation])
+	objAnnotations := withRecorded(annotations, current[clusterv1.PropagatedAnnotationsAnnotation])
+	objAnnotations[clusterv1.PropagatedLabelsAnnotation] = record(labels)
+	objAnnotations[clusterv1.PropagatedAnnotationsAnnotation] = record(annotations)
+	return objLabels, objAnnotations
+}
+
+// isPropagationAnnotation returns true if key is one of the annotations configuring or recording the propagation
+// of the Cluster metadata.
+func isPropagationAnnotation(key string) bool {
+	switch key {
+	case clusterv1.PropagateLabelsAnnotation, clusterv1.PropagateAnnotationsAnnotation,
+		clusterv1.PropagatedLabelsAnnotation, clusterv1.PropagatedAnnotationsAnnotation:
+		return true
+	}
+	return false
+}
+
+// applyPropagatedMetadata returns a copy of current with the propagated metadata applied, and true if it is
+// different from current.
+func applyPropagatedMetadata(current map[string]string, propagated map[string]*string) (map[string]string, bool) {
+	ret := map[string]string{}
+	for k, v := range current {
+		ret[k] = v
+	}
+	changed := false
+	for key, value := range propagated {
+		currentValue, ok := ret[key]
+		switch {
+		case value == nil && ok:
+			delete(ret, key)
+			changed = true
+		case value != nil && (!ok || currentValue != *value):
+			ret[key] = *value
+			changed = true
+		}
+	}
+	return ret, changed
+}
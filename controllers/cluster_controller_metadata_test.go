@@ -0,0 +1,198 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestClusterReconcilePropagatedMetadata(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster",
+			Namespace: metav1.NamespaceDefault,
+			Labels: map[string]string{
+				"team":  "foo",
+				"other": "bar",
+			},
+			Annotations: map[string]string{
+				clusterv1.PropagateLabelsAnnotation:      "team, cost-center",
+				clusterv1.PropagateAnnotationsAnnotation: "owner",
+				"owner":                                  "foo@example.com",
+			},
+		},
+	}
+	machineDeployment := &clusterv1.MachineDeployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-md",
+			Namespace: metav1.NamespaceDefault,
+			Labels: map[string]string{
+				clusterv1.ClusterLabelName: cluster.Name,
+				"cost-center":              "stale",
+			},
+		},
+	}
+	machine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-machine",
+			Namespace: metav1.NamespaceDefault,
+			Labels: map[string]string{
+				clusterv1.ClusterLabelName: cluster.Name,
+				"team":                     "old-team",
+				"no-longer-listed":         "foo",
+			},
+			Annotations: map[string]string{
+				clusterv1.PropagatedLabelsAnnotation: "no-longer-listed,team",
+			},
+		},
+	}
+	otherClusterMachine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "other-machine",
+			Namespace: metav1.NamespaceDefault,
+			Labels: map[string]string{
+				clusterv1.ClusterLabelName: "other-cluster",
+			},
+		},
+	}
+
+	c := fake.NewClientBuilder().WithObjects(cluster, machineDeployment, machine, otherClusterMachine).Build()
+	r := &ClusterReconciler{Client: c}
+
+	_, err := r.reconcilePropagatedMetadata(ctx, cluster)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	gotMachineDeployment := &clusterv1.MachineDeployment{}
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(machineDeployment), gotMachineDeployment)).To(Succeed())
+	g.Expect(gotMachineDeployment.Labels).To(HaveKeyWithValue("team", "foo"))
+	g.Expect(gotMachineDeployment.Labels).NotTo(HaveKey("cost-center"))
+	g.Expect(gotMachineDeployment.Labels).NotTo(HaveKey("other"))
+	g.Expect(gotMachineDeployment.Annotations).To(HaveKeyWithValue("owner", "foo@example.com"))
+	g.Expect(gotMachineDeployment.Annotations).NotTo(HaveKey(clusterv1.PropagateLabelsAnnotation))
+	g.Expect(gotMachineDeployment.Annotations).To(HaveKeyWithValue(clusterv1.PropagatedLabelsAnnotation, "team"))
+	g.Expect(gotMachineDeployment.Annotations).To(HaveKeyWithValue(clusterv1.PropagatedAnnotationsAnnotation, "owner"))
+
+	gotMachine := &clusterv1.Machine{}
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(machine), gotMachine)).To(Succeed())
+	g.Expect(gotMachine.Labels).To(HaveKeyWithValue("team", "foo"))
+	g.Expect(gotMachine.Labels).To(HaveKeyWithValue(clusterv1.ClusterLabelName, cluster.Name))
+	g.Expect(gotMachine.Labels).NotTo(HaveKey("no-longer-listed"))
+	g.Expect(gotMachine.Annotations).To(HaveKeyWithValue(clusterv1.PropagatedLabelsAnnotation, "team"))
+	g.Expect(gotMachine.Annotations).To(HaveKeyWithValue("owner", "foo@example.com"))
+
+	gotOtherClusterMachine := &clusterv1.Machine{}
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(otherClusterMachine), gotOtherClusterMachine)).To(Succeed())
+	g.Expect(gotOtherClusterMachine.Labels).NotTo(HaveKey("team"))
+	g.Expect(gotOtherClusterMachine.Annotations).NotTo(HaveKey("owner"))
+}
+
+func TestClusterReconcilePropagatedMetadataNoLongerListed(t *testing.T) {
+	g := NewWithT(t)
+
+	// The Cluster does not list any key to be propagated anymore.
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster",
+			Namespace: metav1.NamespaceDefault,
+			Labels:    map[string]string{"team": "foo"},
+		},
+	}
+	machine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-machine",
+			Namespace: metav1.NamespaceDefault,
+			Labels: map[string]string{
+				clusterv1.ClusterLabelName: cluster.Name,
+				"team":                     "foo",
+			},
+			Annotations: map[string]string{
+				clusterv1.PropagatedLabelsAnnotation:      "team",
+				clusterv1.PropagatedAnnotationsAnnotation: "owner",
+				"owner": "foo@example.com",
+			},
+		},
+	}
+
+	c := fake.NewClientBuilder().WithObjects(cluster, machine).Build()
+	r := &ClusterReconciler{Client: c}
+
+	_, err := r.reconcilePropagatedMetadata(ctx, cluster)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	gotMachine := &clusterv1.Machine{}
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(machine), gotMachine)).To(Succeed())
+	g.Expect(gotMachine.Labels).NotTo(HaveKey("team"))
+	g.Expect(gotMachine.Labels).To(HaveKeyWithValue(clusterv1.ClusterLabelName, cluster.Name))
+	g.Expect(gotMachine.Annotations).To(BeEmpty())
+}
+
+func TestApplyPropagatedMetadata(t *testing.T) {
+	tests := []struct {
+		name        string
+		current     map[string]string
+		propagated  map[string]*string
+		want        map[string]string
+		wantChanged bool
+	}{
+		{
+			name:        "adds missing keys",
+			current:     map[string]string{"a": "1"},
+			propagated:  map[string]*string{"b": pointer.StringPtr("2")},
+			want:        map[string]string{"a": "1", "b": "2"},
+			wantChanged: true,
+		},
+		{
+			name:        "updates out of sync values",
+			current:     map[string]string{"a": "1"},
+			propagated:  map[string]*string{"a": pointer.StringPtr("2")},
+			want:        map[string]string{"a": "2"},
+			wantChanged: true,
+		},
+		{
+			name:        "removes keys not set on the Cluster",
+			current:     map[string]string{"a": "1", "b": "2"},
+			propagated:  map[string]*string{"b": nil},
+			want:        map[string]string{"a": "1"},
+			wantChanged: true,
+		},
+		{
+			name:        "no changes if in sync",
+			current:     map[string]string{"a": "1"},
+			propagated:  map[string]*string{"a": pointer.StringPtr("1"), "b": nil},
+			want:        map[string]string{"a": "1"},
+			wantChanged: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got, changed := applyPropagatedMetadata(tt.current, tt.propagated)
+			g.Expect(got).To(Equal(tt.want))
+			g.Expect(changed).To(Equal(tt.wantChanged))
+		})
+	}
+}
@@ -33,10 +33,8 @@ import (
 	"sigs.k8s.io/cluster-api/util/conditions"
 	utilconversion "sigs.k8s.io/cluster-api/util/conversion"
 	"sigs.k8s.io/cluster-api/util/kubeconfig"
-	"sigs.k8s.io/cluster-api/util/patch"
 	"sigs.k8s.io/cluster-api/util/secret"
 	ctrl "sigs.k8s.io/controller-runtime"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 )
 
@@ -85,27 +83,13 @@ func (r *ClusterReconciler) reconcileExternal(ctx context.Context, cluster *clus
 		return external.ReconcileOutput{Paused: true}, nil
 	}
 
-	// Initialize the patch helper.
-	patchHelper, err := patch.NewHelper(obj, r.Client)
-	if err != nil {
-		return external.ReconcileOutput{}, err
-	}
-
-	// Set external object ControllerReference to the Cluster.
-	if err := controllerutil.SetControllerReference(cluster, obj, r.Client.Scheme()); err != nil {
-		return external.ReconcileOutput{}, err
-	}
-
-	// Set the Cluster label.
-	labels := obj.GetLabels()
-	if labels == nil {
-		labels = make(map[string]string)
-	}
-	labels[clusterv1.ClusterLabelName] = cluster.Name
-	obj.SetLabels(labels)
-
-	// Always attempt to Patch the external object.
-	if err := patchHelper.Patch(ctx, obj); err != nil {
+	// Set the external object ControllerReference and Cluster label, and always attempt to Patch it.
+	if err := external.PatchExternal(ctx, &external.PatchExternalInput{
+		Client:      r.Client,
+		Object:      obj,
+		Owner:       cluster,
+		ClusterName: cluster.Name,
+	}); err != nil {
 		return external.ReconcileOutput{}, err
 	}
 
@@ -179,8 +163,9 @@ func (r *ClusterReconciler) reconcileInfrastructure(ctx context.Context, cluster
 		return ctrl.Result{}, nil
 	}
 
-	// Get and parse Spec.ControlPlaneEndpoint field from the infrastructure provider.
-	if !cluster.Spec.ControlPlaneEndpoint.IsValid() {
+	// Get and parse Spec.ControlPlaneEndpoint field from the infrastructure provider, unless the
+	// control plane endpoint is externally managed and thus provided by the user.
+	if !cluster.Spec.ControlPlaneEndpoint.IsValid() && !annotations.HasExternallyManagedEndpoint(cluster) {
 		if err := util.UnstructuredUnmarshalField(infraConfig, &cluster.Spec.ControlPlaneEndpoint, "spec", "controlPlaneEndpoint"); err != nil {
 			return ctrl.Result{}, errors.Wrapf(err, "failed to retrieve Spec.ControlPlaneEndpoint from infrastructure provider for Cluster %q in namespace %q",
 				cluster.Name, cluster.Namespace)
@@ -266,9 +251,12 @@ func (r *ClusterReconciler) reconcileKubeconfig(ctx context.Context, cluster *cl
 		return ctrl.Result{}, nil
 	}
 
-	_, err := secret.Get(ctx, r.Client, util.ObjectKey(cluster), secret.Kubeconfig)
-	switch {
-	case apierrors.IsNotFound(err):
+	// NOTE: only the existence of the Kubeconfig Secret is checked, so its metadata is enough.
+	exists, err := secret.Exists(ctx, r.Client, util.ObjectKey(cluster), secret.Kubeconfig)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrapf(err, "failed to retrieve Kubeconfig Secret for Cluster %q in namespace %q", cluster.Name, cluster.Namespace)
+	}
+	if !exists {
 		if err := kubeconfig.CreateSecret(ctx, r.Client, cluster); err != nil {
 			if err == kubeconfig.ErrDependentCertificateNotFound {
 				log.Info("could not find secret for cluster, requeuing", "secret", secret.ClusterCA)
@@ -276,8 +264,6 @@ func (r *ClusterReconciler) reconcileKubeconfig(ctx context.Context, cluster *cl
 			}
 			return ctrl.Result{}, err
 		}
-	case err != nil:
-		return ctrl.Result{}, errors.Wrapf(err, "failed to retrieve Kubeconfig Secret for Cluster %q in namespace %q", cluster.Name, cluster.Namespace)
 	}
 
 	return ctrl.Result{}, nil
@@ -17,6 +17,7 @@ limitations under the License.
 package controllers
 
 import (
+	"bytes"
 	"testing"
 	"time"
 
@@ -28,6 +29,7 @@ import (
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 	"sigs.k8s.io/cluster-api/controllers/external"
 	capierrors "sigs.k8s.io/cluster-api/errors"
+	"sigs.k8s.io/cluster-api/util/secret"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -371,3 +373,45 @@ func TestClusterReconciler_reconcilePhase(t *testing.T) {
 		})
 	}
 }
+
+// BenchmarkKubeconfigSecretExistence compares the memory allocated for checking the existence of a Kubeconfig Secret
+// by reading the whole Secret or only its metadata.
+func BenchmarkKubeconfigSecretExistence(b *testing.B) {
+	g := NewWithT(b)
+
+	ns, err := env.CreateNamespace(ctx, "test-kubeconfig-existence")
+	g.Expect(err).NotTo(HaveOccurred())
+	defer func() {
+		g.Expect(env.Cleanup(ctx, ns)).To(Succeed())
+	}()
+
+	cluster := client.ObjectKey{Namespace: ns.Name, Name: "test-cluster"}
+	kubeconfigSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: ns.Name,
+			Name:      secret.Name(cluster.Name, secret.Kubeconfig),
+		},
+		Data: map[string][]byte{
+			secret.KubeconfigDataName: bytes.Repeat([]byte("x"), 256*1024),
+		},
+	}
+	g.Expect(env.Create(ctx, kubeconfigSecret)).To(Succeed())
+
+	b.Run("full object", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := secret.Get(ctx, env.GetAPIReader(), cluster, secret.Kubeconfig); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("metadata only", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := secret.Exists(ctx, env.GetAPIReader(), cluster, secret.Kubeconfig); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,258 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/feature"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// deprecatedAPIVersions are the Cluster API versions which are deprecated and are going to be removed in a future release.
+var deprecatedAPIVersions = sets.NewString("v1alpha2", "v1alpha3")
+
+var (
+	// deprecatedAPIVersionStored reports the Cluster API custom resource definitions with objects still stored
+	// using a deprecated API version.
+	deprecatedAPIVersionStored = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "capi_deprecated_api_version_stored",
+			Help: "Whether objects of a Cluster API custom resource definition are stored using a deprecated API version, by custom resource definition and version.",
+		},
+		[]string{"crd", "version"},
+	)
+
+	// deprecatedAPIVersionAppliedObjects reports the number of Cluster API objects applied using a deprecated API version.
+	deprecatedAPIVersionAppliedObjects = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "capi_deprecated_api_version_applied_objects",
+			Help: "Number of Cluster API objects applied using a deprecated API version, by kind and API version.",
+		},
+		[]string{"kind", "version"},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(deprecatedAPIVersionStored, deprecatedAPIVersionAppliedObjects)
+}
+
+// DeprecatedAPIVersionsReporter periodically reports, by logging and using metrics, the Cluster API custom resource
+// definitions with objects still stored using deprecated API versions, and the Cluster API objects applied using
+// deprecated API versions, so operators can migrate them before the deprecated versions are removed.
+type DeprecatedAPIVersionsReporter struct {
+	Client client.Client
+
+	// Interval is the interval between two reports.
+	Interval time.Duration
+}
+
+func (r *DeprecatedAPIVersionsReporter) SetupWithManager(mgr ctrl.Manager) error {
+	if r.Interval <= 0 {
+		return errors.New("the interval between two reports of the deprecated API versions must be greater than zero")
+	}
+	return mgr.Add(r)
+}
+
+// Start starts reporting the deprecated API versions in use, until the context is done.
+func (r *DeprecatedAPIVersionsReporter) Start(ctx context.Context) error {
+	wait.UntilWithContext(ctx, r.report, r.Interval)
+	return nil
+}
+
+func (r *DeprecatedAPIVersionsReporter) report(ctx context.Context) {
+	log := ctrl.Log.WithName("DeprecatedAPIVersionsReporter")
+
+	if err := r.reportStoredVersions(ctx); err != nil {
+		log.Error(err, "Failed to report the deprecated API versions used for storing objects")
+	}
+	if err := r.reportAppliedObjects(ctx); err != nil {
+		log.Error(err, "Failed to report the objects applied using deprecated API versions")
+	}
+}
+
+func (r *DeprecatedAPIVersionsReporter) reportStoredVersions(ctx context.Context) error {
+	log := ctrl.Log.WithName("DeprecatedAPIVersionsReporter")
+
+	crds := &apiextensionsv1.CustomResourceDefinitionList{}
+	if err := r.Client.List(ctx, crds); err != nil {
+		return errors.Wrap(err, "failed to list CustomResourceDefinitions")
+	}
+
+	deprecatedAPIVersionStored.Reset()
+	for i := range crds.Items {
+		crd := &crds.Items[i]
+		if !isClusterAPIGroup(crd.Spec.Group) {
+			continue
+		}
+		for _, version := range deprecatedAPIVersionsStored(crd) {
+			deprecatedAPIVersionStored.WithLabelValues(crd.Name, version).Set(1)
+			log.Info("Objects are stored using a deprecated API version, they must be migrated before the version is removed", "crd", crd.Name, "version", version)
+		}
+	}
+	return nil
+}
+
+func (r *DeprecatedAPIVersionsReporter) reportAppliedObjects(ctx context.Context) error {
+	log := ctrl.Log.WithName("DeprecatedAPIVersionsReporter")
+
+	lists := append([]deprecatedAPIVersionsObjectList{{kind: "Cluster", list: &clusterv1.ClusterList{}}}, descendantObjectLists()...)
+	counts := map[string]map[string]int{}
+	for _, l := range lists {
+		if err := r.Client.List(ctx, l.list); err != nil {
+			return errors.Wrapf(err, "failed to list %s objects", l.kind)
+		}
+		if err := meta.EachListItem(l.list, func(o runtime.Object) error {
+			for _, version := range deprecatedAPIVersionsAppliedBy(o.(client.Object)) {
+				if counts[l.kind] == nil {
+					counts[l.kind] = map[string]int{}
+				}
+				counts[l.kind][version]++
+			}
+			return nil
+		}); err != nil {
+			return errors.Wrapf(err, "failed to inspect %s objects", l.kind)
+		}
+	}
+
+	deprecatedAPIVersionAppliedObjects.Reset()
+	for kind, versions := range counts {
+		for version, count := range versions {
+			deprecatedAPIVersionAppliedObjects.WithLabelValues(kind, version).Set(float64(count))
+			log.Info("Objects are applied using a deprecated API version, their manifests must be updated before the version is removed", "kind", kind, "version", version, "count", count)
+		}
+	}
+	return nil
+}
+
+// reconcileDeprecatedAPIVersions reports in the APIVersionsUpToDate condition whether the cluster or its descendants
+// have been applied using deprecated API versions.
+func (r *ClusterReconciler) reconcileDeprecatedAPIVersions(ctx context.Context, cluster *clusterv1.Cluster) (ctrl.Result, error) {
+	inUse := map[string]sets.String{}
+	addInUse := func(kind string, obj client.Object) {
+		for _, version := range deprecatedAPIVersionsAppliedBy(obj) {
+			if inUse[version] == nil {
+				inUse[version] = sets.NewString()
+			}
+			inUse[version].Insert(kind)
+		}
+	}
+
+	addInUse("Cluster", cluster)
+	for _, l := range descendantObjectLists() {
+		if err := r.Client.List(ctx, l.list, client.InNamespace(cluster.Namespace), client.MatchingLabels{clusterv1.ClusterLabelName: cluster.Name}); err != nil {
+			return ctrl.Result{}, errors.Wrapf(err, "failed to list %s objects for cluster %s/%s", l.kind, cluster.Namespace, cluster.Name)
+		}
+		if err := meta.EachListItem(l.list, func(o runtime.Object) error {
+			addInUse(l.kind, o.(client.Object))
+			return nil
+		}); err != nil {
+			return ctrl.Result{}, errors.Wrapf(err, "failed to inspect %s objects for cluster %s/%s", l.kind, cluster.Namespace, cluster.Name)
+		}
+	}
+
+	if len(inUse) == 0 {
+		conditions.MarkTrue(cluster, clusterv1.APIVersionsUpToDateCondition)
+		return ctrl.Result{}, nil
+	}
+
+	versions := make([]string, 0, len(inUse))
+	for version, kinds := range inUse {
+		versions = append(versions, fmt.Sprintf("%s (%s)", version, strings.Join(kinds.List(), ", ")))
+	}
+	sort.Strings(versions)
+	conditions.MarkFalse(cluster, clusterv1.APIVersionsUpToDateCondition, clusterv1.DeprecatedAPIVersionsInUseReason, clusterv1.ConditionSeverityWarning,
+		"Deprecated API versions in use: %s", strings.Join(versions, "; "))
+	return ctrl.Result{}, nil
+}
+
+// deprecatedAPIVersionsObjectList is a list of Cluster API objects to be inspected for deprecated API versions.
+type deprecatedAPIVersionsObjectList struct {
+	kind string
+	list client.ObjectList
+}
+
+// descendantObjectLists returns the lists of the Cluster API objects descending from a cluster.
+func descendantObjectLists() []deprecatedAPIVersionsObjectList {
+	lists := []deprecatedAPIVersionsObjectList{
+		{kind: "MachineDeployment", list: &clusterv1.MachineDeploymentList{}},
+		{kind: "MachineSet", list: &clusterv1.MachineSetList{}},
+		{kind: "Machine", list: &clusterv1.MachineList{}},
+	}
+	if feature.Gates.Enabled(feature.MachinePool) {
+		lists = append(lists, deprecatedAPIVersionsObjectList{kind: "MachinePool", list: &expv1.MachinePoolList{}})
+	}
+	return lists
+}
+
+// deprecatedAPIVersionsAppliedBy returns the deprecated Cluster API versions used for applying an object,
+// as recorded in the object's managed fields.
+func deprecatedAPIVersionsAppliedBy(obj client.Object) []string {
+	versions := sets.NewString()
+	for _, f := range obj.GetManagedFields() {
+		gv, err := schema.ParseGroupVersion(f.APIVersion)
+		if err != nil {
+			continue
+		}
+		if isClusterAPIGroup(gv.Group) && deprecatedAPIVersions.Has(gv.Version) {
+			versions.Insert(f.APIVersion)
+		}
+	}
+	return versions.List()
+}
+
+// deprecatedAPIVersionsStored returns the deprecated versions in the stored versions of a custom resource definition;
+// besides the deprecated Cluster API versions, versions explicitly marked as deprecated in the definition are considered.
+func deprecatedAPIVersionsStored(crd *apiextensionsv1.CustomResourceDefinition) []string {
+	deprecated := sets.NewString()
+	for _, v := range crd.Spec.Versions {
+		if v.Deprecated {
+			deprecated.Insert(v.Name)
+		}
+	}
+
+	var versions []string
+	for _, v := range crd.Status.StoredVersions {
+		if deprecatedAPIVersions.Has(v) || deprecated.Has(v) {
+			versions = append(versions, v)
+		}
+	}
+	return versions
+}
+
+// isClusterAPIGroup returns true if the group is the Cluster API group, or one of its sub groups,
+// e.g. infrastructure.cluster.x-k8s.io.
+func isClusterAPIGroup(group string) bool {
+	return group == clusterv1.GroupVersion.Group || strings.HasSuffix(group, "."+clusterv1.GroupVersion.Group)
+}
@@ -0,0 +1,164 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestDeprecatedAPIVersionsAppliedBy(t *testing.T) {
+	g := NewWithT(t)
+
+	obj := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			ManagedFields: []metav1.ManagedFieldsEntry{
+				{Manager: "kubectl", APIVersion: "cluster.x-k8s.io/v1alpha3"},
+				{Manager: "kubectl-edit", APIVersion: "cluster.x-k8s.io/v1alpha3"},
+				{Manager: "manager", APIVersion: "cluster.x-k8s.io/v1alpha4"},
+				{Manager: "old-controller", APIVersion: "infrastructure.cluster.x-k8s.io/v1alpha2"},
+				{Manager: "other", APIVersion: "example.com/v1alpha3"},
+			},
+		},
+	}
+	g.Expect(deprecatedAPIVersionsAppliedBy(obj)).To(Equal([]string{
+		"cluster.x-k8s.io/v1alpha3",
+		"infrastructure.cluster.x-k8s.io/v1alpha2",
+	}))
+	g.Expect(deprecatedAPIVersionsAppliedBy(&clusterv1.Cluster{})).To(BeEmpty())
+}
+
+func TestDeprecatedAPIVersionsStored(t *testing.T) {
+	g := NewWithT(t)
+
+	crd := &apiextensionsv1.CustomResourceDefinition{
+		Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+			Versions: []apiextensionsv1.CustomResourceDefinitionVersion{
+				{Name: "v1beta1", Deprecated: true},
+				{Name: "v1"},
+			},
+		},
+		Status: apiextensionsv1.CustomResourceDefinitionStatus{
+			StoredVersions: []string{"v1alpha3", "v1beta1", "v1"},
+		},
+	}
+	g.Expect(deprecatedAPIVersionsStored(crd)).To(Equal([]string{"v1alpha3", "v1beta1"}))
+}
+
+func TestDeprecatedAPIVersionsReporter_reportStoredVersions(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(apiextensionsv1.AddToScheme(scheme)).To(Succeed())
+
+	capiCRD := &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "machines.cluster.x-k8s.io"},
+		Spec:       apiextensionsv1.CustomResourceDefinitionSpec{Group: "cluster.x-k8s.io"},
+		Status:     apiextensionsv1.CustomResourceDefinitionStatus{StoredVersions: []string{"v1alpha3", "v1alpha4"}},
+	}
+	otherCRD := &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "foos.example.com"},
+		Spec:       apiextensionsv1.CustomResourceDefinitionSpec{Group: "example.com"},
+		Status:     apiextensionsv1.CustomResourceDefinitionStatus{StoredVersions: []string{"v1alpha3"}},
+	}
+
+	r := &DeprecatedAPIVersionsReporter{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(capiCRD, otherCRD).Build(),
+	}
+	g.Expect(r.reportStoredVersions(ctx)).To(Succeed())
+	g.Expect(testutil.CollectAndCount(deprecatedAPIVersionStored)).To(Equal(1))
+	g.Expect(testutil.ToFloat64(deprecatedAPIVersionStored.WithLabelValues("machines.cluster.x-k8s.io", "v1alpha3"))).To(Equal(float64(1)))
+}
+
+func TestClusterReconciler_reconcileDeprecatedAPIVersions(t *testing.T) {
+	t.Run("Should mark the condition true when no deprecated API versions are in use", func(t *testing.T) {
+		g := NewWithT(t)
+
+		cluster := &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-cluster",
+				Namespace: "default",
+				ManagedFields: []metav1.ManagedFieldsEntry{
+					{Manager: "kubectl", APIVersion: "cluster.x-k8s.io/v1alpha4"},
+				},
+			},
+		}
+
+		r := &ClusterReconciler{
+			Client: fake.NewClientBuilder().WithObjects(cluster).Build(),
+		}
+		res, err := r.reconcileDeprecatedAPIVersions(ctx, cluster)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(res.IsZero()).To(BeTrue())
+		g.Expect(conditions.IsTrue(cluster, clusterv1.APIVersionsUpToDateCondition)).To(BeTrue())
+	})
+
+	t.Run("Should report the deprecated API versions used by the cluster and its descendants", func(t *testing.T) {
+		g := NewWithT(t)
+
+		cluster := &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-cluster",
+				Namespace: "default",
+				ManagedFields: []metav1.ManagedFieldsEntry{
+					{Manager: "kubectl", APIVersion: "cluster.x-k8s.io/v1alpha3"},
+				},
+			},
+		}
+		machineDeployment := &clusterv1.MachineDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "md",
+				Namespace: "default",
+				Labels:    map[string]string{clusterv1.ClusterLabelName: cluster.Name},
+				ManagedFields: []metav1.ManagedFieldsEntry{
+					{Manager: "kubectl", APIVersion: "cluster.x-k8s.io/v1alpha3"},
+				},
+			},
+		}
+		otherClusterMachine := &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "machine",
+				Namespace: "default",
+				Labels:    map[string]string{clusterv1.ClusterLabelName: "other-cluster"},
+				ManagedFields: []metav1.ManagedFieldsEntry{
+					{Manager: "kubectl", APIVersion: "cluster.x-k8s.io/v1alpha2"},
+				},
+			},
+		}
+
+		r := &ClusterReconciler{
+			Client: fake.NewClientBuilder().WithObjects(cluster, machineDeployment, otherClusterMachine).Build(),
+		}
+		_, err := r.reconcileDeprecatedAPIVersions(ctx, cluster)
+		g.Expect(err).NotTo(HaveOccurred())
+
+		c := conditions.Get(cluster, clusterv1.APIVersionsUpToDateCondition)
+		g.Expect(c).NotTo(BeNil())
+		g.Expect(c.Status).To(BeEquivalentTo("False"))
+		g.Expect(c.Reason).To(Equal(clusterv1.DeprecatedAPIVersionsInUseReason))
+		g.Expect(c.Severity).To(Equal(clusterv1.ConditionSeverityWarning))
+		g.Expect(c.Message).To(Equal("Deprecated API versions in use: cluster.x-k8s.io/v1alpha3 (Cluster, MachineDeployment)"))
+	})
+}
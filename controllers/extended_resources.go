@@ -0,0 +1,103 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/controllers/external"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// reconcileExtendedResourcesAnnotation sets the ExtendedResourcesAnnotation on obj according to the extended resources
+// reported in the status.capacity of the infrastructure machine template referenced by ref, or removes it if there are none.
+func reconcileExtendedResourcesAnnotation(ctx context.Context, c client.Client, obj metav1.Object, ref *corev1.ObjectReference) error {
+	if !strings.HasSuffix(ref.Kind, external.TemplateSuffix) {
+		return nil
+	}
+
+	template, err := external.Get(ctx, c, ref, obj.GetNamespace())
+	if err != nil {
+		return err
+	}
+
+	value, err := extendedResourcesFromTemplate(template)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read the capacity of %s %q", ref.Kind, ref.Name)
+	}
+
+	annotations := obj.GetAnnotations()
+	if value == "" {
+		if _, ok := annotations[clusterv1.ExtendedResourcesAnnotation]; ok {
+			delete(annotations, clusterv1.ExtendedResourcesAnnotation)
+			obj.SetAnnotations(annotations)
+		}
+		return nil
+	}
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[clusterv1.ExtendedResourcesAnnotation] = value
+	obj.SetAnnotations(annotations)
+	return nil
+}
+
+// extendedResourcesFromTemplate returns the extended resources in the status.capacity of an infrastructure machine template,
+// formatted as the value of the ExtendedResourcesAnnotation; an empty string is returned if there are none.
+func extendedResourcesFromTemplate(template *unstructured.Unstructured) (string, error) {
+	capacity, found, err := unstructured.NestedStringMap(template.Object, "status", "capacity")
+	if err != nil || !found {
+		return "", err
+	}
+
+	resources := []string{}
+	for name, value := range capacity {
+		if !isExtendedResourceName(name) {
+			continue
+		}
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			return "", errors.Wrapf(err, "invalid quantity %q for %s", value, name)
+		}
+		if quantity.IsZero() {
+			continue
+		}
+		resources = append(resources, fmt.Sprintf("%s=%s", name, quantity.String()))
+	}
+	sort.Strings(resources)
+	return strings.Join(resources, ","), nil
+}
+
+// isExtendedResourceName returns true if name is a fully qualified resource name outside of the kubernetes.io domain,
+// e.g. nvidia.com/gpu.
+func isExtendedResourceName(name string) bool {
+	parts := strings.Split(name, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return false
+	}
+	domain := parts[0]
+	return domain != "kubernetes.io" && !strings.HasSuffix(domain, ".kubernetes.io")
+}
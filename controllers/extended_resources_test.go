@@ -0,0 +1,125 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestExtendedResourcesFromTemplate(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  map[string]interface{}
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "no capacity",
+			status: map[string]interface{}{},
+			want:   "",
+		},
+		{
+			name: "only standard resources",
+			status: map[string]interface{}{
+				"capacity": map[string]interface{}{"cpu": "4", "memory": "16Gi", "kubernetes.io/foo": "1"},
+			},
+			want: "",
+		},
+		{
+			name: "extended resources are sorted by name and zero quantities are skipped",
+			status: map[string]interface{}{
+				"capacity": map[string]interface{}{"cpu": "4", "nvidia.com/gpu": "2", "example.com/fpga": "1", "example.com/none": "0"},
+			},
+			want: "example.com/fpga=1,nvidia.com/gpu=2",
+		},
+		{
+			name: "invalid quantity",
+			status: map[string]interface{}{
+				"capacity": map[string]interface{}{"nvidia.com/gpu": "two"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			template := &unstructured.Unstructured{Object: map[string]interface{}{"status": tt.status}}
+			got, err := extendedResourcesFromTemplate(template)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestReconcileExtendedResourcesAnnotation(t *testing.T) {
+	newTemplate := func(capacity map[string]interface{}) *unstructured.Unstructured {
+		return &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"kind":       "GenericInfrastructureMachineTemplate",
+				"apiVersion": "infrastructure.cluster.x-k8s.io/v1alpha4",
+				"metadata": map[string]interface{}{
+					"name":      "infra-template",
+					"namespace": metav1.NamespaceDefault,
+				},
+				"status": map[string]interface{}{
+					"capacity": capacity,
+				},
+			},
+		}
+	}
+	ref := &corev1.ObjectReference{
+		Kind:       "GenericInfrastructureMachineTemplate",
+		APIVersion: "infrastructure.cluster.x-k8s.io/v1alpha4",
+		Name:       "infra-template",
+	}
+
+	t.Run("sets the annotation", func(t *testing.T) {
+		g := NewWithT(t)
+
+		ms := &clusterv1.MachineSet{ObjectMeta: metav1.ObjectMeta{Name: "ms", Namespace: metav1.NamespaceDefault}}
+		c := fake.NewClientBuilder().WithObjects(newTemplate(map[string]interface{}{"nvidia.com/gpu": "1"})).Build()
+
+		g.Expect(reconcileExtendedResourcesAnnotation(ctx, c, ms, ref)).To(Succeed())
+		g.Expect(ms.Annotations).To(HaveKeyWithValue(clusterv1.ExtendedResourcesAnnotation, "nvidia.com/gpu=1"))
+	})
+
+	t.Run("removes the annotation if there are no extended resources", func(t *testing.T) {
+		g := NewWithT(t)
+
+		ms := &clusterv1.MachineSet{ObjectMeta: metav1.ObjectMeta{
+			Name:        "ms",
+			Namespace:   metav1.NamespaceDefault,
+			Annotations: map[string]string{clusterv1.ExtendedResourcesAnnotation: "nvidia.com/gpu=1", "foo": "bar"},
+		}}
+		c := fake.NewClientBuilder().WithObjects(newTemplate(map[string]interface{}{"cpu": "2"})).Build()
+
+		g.Expect(reconcileExtendedResourcesAnnotation(ctx, c, ms, ref)).To(Succeed())
+		g.Expect(ms.Annotations).To(Equal(map[string]string{"foo": "bar"}))
+	})
+}
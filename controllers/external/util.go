@@ -26,8 +26,10 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apiserver/pkg/storage/names"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/util/patch"
 )
 
 const (
@@ -197,6 +199,74 @@ func GenerateTemplate(in *GenerateTemplateInput) (*unstructured.Unstructured, er
 	return to, nil
 }
 
+// PatchExternalInput is the input to PatchExternal.
+type PatchExternalInput struct {
+	// Client is the controller runtime client.
+	// +required
+	Client client.Client
+
+	// Object is the external object to be patched.
+	// +required
+	Object *unstructured.Unstructured
+
+	// Owner is the object that should be set as the controller of the external object.
+	// +required
+	Owner client.Object
+
+	// NonController sets a plain owner reference to the owner instead of a controller reference, e.g. for
+	// templates or other objects which can be shared by multiple owners.
+	// +optional
+	NonController bool
+
+	// ClusterName is the cluster the external object is linked to; if empty, the cluster name label is not set,
+	// e.g. for templates which can be shared by multiple clusters.
+	// +optional
+	ClusterName string
+
+	// Mutate is an optional func to apply additional changes to the external object before patching it.
+	// +optional
+	Mutate func(obj *unstructured.Unstructured) error
+}
+
+// PatchExternal sets the controller reference, or a plain owner reference, to the owner and the cluster name label
+// on an external object, applies the optional mutation, and then patches the changes.
+func PatchExternal(ctx context.Context, in *PatchExternalInput) error {
+	patchHelper, err := patch.NewHelper(in.Object, in.Client)
+	if err != nil {
+		return err
+	}
+
+	if in.Mutate != nil {
+		if err := in.Mutate(in.Object); err != nil {
+			return err
+		}
+	}
+
+	if in.NonController {
+		// Set external object OwnerReference to the owner.
+		if err := controllerutil.SetOwnerReference(in.Owner, in.Object, in.Client.Scheme()); err != nil {
+			return err
+		}
+	} else {
+		// Set external object ControllerReference to the owner.
+		if err := controllerutil.SetControllerReference(in.Owner, in.Object, in.Client.Scheme()); err != nil {
+			return err
+		}
+	}
+
+	// Set the Cluster label.
+	if in.ClusterName != "" {
+		labels := in.Object.GetLabels()
+		if labels == nil {
+			labels = make(map[string]string)
+		}
+		labels[clusterv1.ClusterLabelName] = in.ClusterName
+		in.Object.SetLabels(labels)
+	}
+
+	return patchHelper.Patch(ctx, in.Object)
+}
+
 // GetObjectReference converts an unstructured into object reference.
 func GetObjectReference(obj *unstructured.Unstructured) *corev1.ObjectReference {
 	return &corev1.ObjectReference{
@@ -223,6 +293,17 @@ func FailuresFrom(obj *unstructured.Unstructured) (string, string, error) {
 	return failureReason, failureMessage, nil
 }
 
+// IsFailureRetryable returns true if the Status.FailureRetryable field on an external object is true, meaning that
+// the failure reported in the FailureReason and FailureMessage fields is transient and the provider keeps retrying.
+func IsFailureRetryable(obj *unstructured.Unstructured) (bool, error) {
+	retryable, _, err := unstructured.NestedBool(obj.Object, "status", "failureRetryable")
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to determine failureRetryable on %v %q",
+			obj.GroupVersionKind(), obj.GetName())
+	}
+	return retryable, nil
+}
+
 // IsReady returns true if the Status.Ready field on an external object is true.
 func IsReady(obj *unstructured.Unstructured) (bool, error) {
 	ready, found, err := unstructured.NestedBool(obj.Object, "status", "ready")
@@ -242,3 +323,14 @@ func IsInitialized(obj *unstructured.Unstructured) (bool, error) {
 	}
 	return initialized && found, nil
 }
+
+// DataSecretNameFrom returns the Status.DataSecretName field from an external bootstrap object,
+// or an empty string if the field is not set.
+func DataSecretNameFrom(obj *unstructured.Unstructured) (string, error) {
+	secretName, _, err := unstructured.NestedString(obj.Object, "status", "dataSecretName")
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to determine dataSecretName on %v %q",
+			obj.GroupVersionKind(), obj.GetName())
+	}
+	return secretName, nil
+}
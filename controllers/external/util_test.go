@@ -26,6 +26,7 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -326,3 +327,132 @@ func TestCloneTemplateMissingSpecTemplate(t *testing.T) {
 	})
 	g.Expect(err).To(HaveOccurred())
 }
+
+func TestPatchExternal(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+
+	obj := &unstructured.Unstructured{}
+	obj.SetKind("GreenMachine")
+	obj.SetAPIVersion("green.io/v1")
+	obj.SetName("green-machine")
+	obj.SetNamespace(testNamespace)
+
+	owner := &clusterv1.Machine{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Machine",
+			APIVersion: clusterv1.GroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "machine",
+			Namespace: testNamespace,
+			UID:       "machine-uid",
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(obj.DeepCopy()).Build()
+
+	g.Expect(PatchExternal(ctx, &PatchExternalInput{
+		Client:      fakeClient,
+		Object:      obj,
+		Owner:       owner,
+		ClusterName: testClusterName,
+		Mutate: func(obj *unstructured.Unstructured) error {
+			return unstructured.SetNestedField(obj.Object, "foo", "spec", "providerID")
+		},
+	})).To(Succeed())
+
+	got := &unstructured.Unstructured{}
+	got.SetKind("GreenMachine")
+	got.SetAPIVersion("green.io/v1")
+	g.Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: testNamespace, Name: "green-machine"}, got)).To(Succeed())
+
+	g.Expect(got.GetLabels()).To(HaveKeyWithValue(clusterv1.ClusterLabelName, testClusterName))
+	g.Expect(metav1.GetControllerOf(got)).ToNot(BeNil())
+	g.Expect(metav1.GetControllerOf(got).UID).To(Equal(owner.UID))
+	providerID, _, err := unstructured.NestedString(got.Object, "spec", "providerID")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(providerID).To(Equal("foo"))
+}
+
+func TestPatchExternalNonController(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+
+	obj := &unstructured.Unstructured{}
+	obj.SetKind("GreenMachineTemplate")
+	obj.SetAPIVersion("green.io/v1")
+	obj.SetName("green-template")
+	obj.SetNamespace(testNamespace)
+
+	owner := &clusterv1.Cluster{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Cluster",
+			APIVersion: clusterv1.GroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testClusterName,
+			Namespace: testNamespace,
+			UID:       "cluster-uid",
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(obj.DeepCopy()).Build()
+
+	g.Expect(PatchExternal(ctx, &PatchExternalInput{
+		Client:        fakeClient,
+		Object:        obj,
+		Owner:         owner,
+		NonController: true,
+	})).To(Succeed())
+
+	got := &unstructured.Unstructured{}
+	got.SetKind("GreenMachineTemplate")
+	got.SetAPIVersion("green.io/v1")
+	g.Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: testNamespace, Name: "green-template"}, got)).To(Succeed())
+
+	g.Expect(got.GetLabels()).NotTo(HaveKey(clusterv1.ClusterLabelName))
+	g.Expect(metav1.GetControllerOf(got)).To(BeNil())
+	g.Expect(got.GetOwnerReferences()).To(HaveLen(1))
+	g.Expect(got.GetOwnerReferences()[0].UID).To(Equal(owner.UID))
+}
+
+func TestDataSecretNameFrom(t *testing.T) {
+	g := NewWithT(t)
+
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	secretName, err := DataSecretNameFrom(obj)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(secretName).To(BeEmpty())
+
+	g.Expect(unstructured.SetNestedField(obj.Object, "secret", "status", "dataSecretName")).To(Succeed())
+	secretName, err = DataSecretNameFrom(obj)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(secretName).To(Equal("secret"))
+
+	g.Expect(unstructured.SetNestedField(obj.Object, int64(1), "status", "dataSecretName")).To(Succeed())
+	_, err = DataSecretNameFrom(obj)
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestIsFailureRetryable(t *testing.T) {
+	g := NewWithT(t)
+
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	retryable, err := IsFailureRetryable(obj)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(retryable).To(BeFalse())
+
+	g.Expect(unstructured.SetNestedField(obj.Object, true, "status", "failureRetryable")).To(Succeed())
+	retryable, err = IsFailureRetryable(obj)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(retryable).To(BeTrue())
+
+	g.Expect(unstructured.SetNestedField(obj.Object, "true", "status", "failureRetryable")).To(Succeed())
+	_, err = IsFailureRetryable(obj)
+	g.Expect(err).To(HaveOccurred())
+}
@@ -19,6 +19,7 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -26,11 +27,13 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/flowcontrol"
 	"k8s.io/klog/v2"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 	"sigs.k8s.io/cluster-api/controllers/external"
@@ -55,6 +58,17 @@ import (
 const (
 	// MachineControllerName defines the controller used when creating clients.
 	MachineControllerName = "machine-controller"
+
+	// orphanMachineCleanupGracePeriod is how long a machine must be orphaned before it is deleted by the orphan machine cleanup.
+	orphanMachineCleanupGracePeriod = 5 * time.Minute
+
+	// orphanMachineCacheRequeueAfter is how long to wait before checking again the Cluster of a machine, if the Cluster
+	// exists but it is not yet in the cache.
+	orphanMachineCacheRequeueAfter = 5 * time.Second
+
+	// defaultNodeVolumeDetachTimeout is how long to wait for the volumes to be detached from the node of a deleted
+	// machine, if the machine's NodeVolumeDetachTimeout is not set.
+	defaultNodeVolumeDetachTimeout = 10 * time.Minute
 )
 
 var (
@@ -78,10 +92,24 @@ type MachineReconciler struct {
 	Tracker          *remote.ClusterCacheTracker
 	WatchFilterValue string
 
+	// APIReader is used to confirm that the Cluster of a machine does not exist before deleting an orphan machine.
+	APIReader client.Reader
+
+	// OrphanMachineCleanup enables the deletion of the machines whose Cluster does not exist anymore; the
+	// infrastructure and bootstrap objects of those machines are deleted, and their finalizer is removed.
+	OrphanMachineCleanup bool
+
 	controller      controller.Controller
 	restConfig      *rest.Config
 	recorder        record.EventRecorder
 	externalTracker external.ObjectTracker
+
+	// externalRefBackoff tracks the requeue backoff for external objects referenced by Machines that can't be found.
+	externalRefBackoff *flowcontrol.Backoff
+
+	// retryableFailures tracks the last retryable failure reported by the external objects referenced by Machines,
+	// so the RetryableFailure event is emitted only when the failure changes.
+	retryableFailures sync.Map
 }
 
 func (r *MachineReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, options controller.Options) error {
@@ -116,6 +144,7 @@ func (r *MachineReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manag
 	r.externalTracker = external.ObjectTracker{
 		Controller: controller,
 	}
+	r.externalRefBackoff = flowcontrol.NewBackOff(externalReadyWait, externalRefMaxBackoff)
 	return nil
 }
 
@@ -137,6 +166,9 @@ func (r *MachineReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_
 
 	cluster, err := util.GetClusterByName(ctx, r.Client, m.ObjectMeta.Namespace, m.Spec.ClusterName)
 	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return r.reconcileOrphan(ctx, m)
+		}
 		return ctrl.Result{}, errors.Wrapf(err, "failed to get cluster %q for machine %q in namespace %q",
 			m.Spec.ClusterName, m.Name, m.Namespace)
 	}
@@ -167,6 +199,11 @@ func (r *MachineReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_
 		}
 	}()
 
+	// The Cluster exists, e.g. because it has been recreated after the machine was orphaned.
+	if conditions.Has(m, clusterv1.ClusterFoundCondition) {
+		conditions.MarkTrue(m, clusterv1.ClusterFoundCondition)
+	}
+
 	// Reconcile labels.
 	if m.Labels == nil {
 		m.Labels = make(map[string]string)
@@ -198,6 +235,8 @@ func patchMachine(ctx context.Context, patchHelper *patch.Helper, machine *clust
 			clusterv1.InfrastructureReadyCondition,
 			// Boostrap comes after, but it is relevant only during initial machine provisioning.
 			clusterv1.BootstrapReadyCondition,
+			// Auxiliary infrastructure is relevant only during initial machine provisioning too.
+			clusterv1.AuxiliaryInfrastructureReadyCondition,
 			// MHC reported condition should take precedence over the remediation progress
 			clusterv1.MachineHealthCheckSuccededCondition,
 			clusterv1.MachineOwnerRemediatedCondition,
@@ -217,9 +256,11 @@ func patchMachine(ctx context.Context, patchHelper *patch.Helper, machine *clust
 			clusterv1.ReadyCondition,
 			clusterv1.BootstrapReadyCondition,
 			clusterv1.InfrastructureReadyCondition,
+			clusterv1.AuxiliaryInfrastructureReadyCondition,
 			clusterv1.DrainingSucceededCondition,
 			clusterv1.MachineHealthCheckSuccededCondition,
 			clusterv1.MachineOwnerRemediatedCondition,
+			clusterv1.ClusterFoundCondition,
 		}},
 	)
 
@@ -247,10 +288,13 @@ func (r *MachineReconciler) reconcile(ctx context.Context, cluster *clusterv1.Cl
 	}
 
 	phases := []func(context.Context, *clusterv1.Cluster, *clusterv1.Machine) (ctrl.Result, error){
+		r.reconcileAuxiliaryInfrastructure,
 		r.reconcileBootstrap,
 		r.reconcileInfrastructure,
+		r.reconcileProvisioningTimeout,
 		r.reconcileNode,
 		r.reconcileInterruptibleNodeLabel,
+		r.reconcileNodeMetadata,
 	}
 
 	res := ctrl.Result{}
@@ -294,33 +338,53 @@ func (r *MachineReconciler) reconcileDelete(ctx context.Context, cluster *cluste
 
 		// Drain node before deletion and issue a patch in order to make this operation visible to the users.
 		if r.isNodeDrainAllowed(m) {
-			patchHelper, err := patch.NewHelper(m, r.Client)
-			if err != nil {
-				return ctrl.Result{}, err
-			}
+			// Once the node is drained, it is not drained again while waiting for the volumes to be detached.
+			if !conditions.IsTrue(m, clusterv1.DrainingSucceededCondition) {
+				patchHelper, err := patch.NewHelper(m, r.Client)
+				if err != nil {
+					return ctrl.Result{}, err
+				}
 
-			log.Info("Draining node", "node", m.Status.NodeRef.Name)
-			// The DrainingSucceededCondition never exists before the node is drained for the first time,
-			// so its transition time can be used to record the first time draining.
-			// This `if` condition prevents the transition time to be changed more than once.
-			if conditions.Get(m, clusterv1.DrainingSucceededCondition) == nil {
-				conditions.MarkFalse(m, clusterv1.DrainingSucceededCondition, clusterv1.DrainingReason, clusterv1.ConditionSeverityInfo, "Draining the node before deletion")
-			}
+				log.Info("Draining node", "node", m.Status.NodeRef.Name)
+				// The DrainingSucceededCondition never exists before the node is drained for the first time,
+				// so its transition time can be used to record the first time draining.
+				// This `if` condition prevents the transition time to be changed more than once.
+				if conditions.Get(m, clusterv1.DrainingSucceededCondition) == nil {
+					conditions.MarkFalse(m, clusterv1.DrainingSucceededCondition, clusterv1.DrainingReason, clusterv1.ConditionSeverityInfo, "Draining the node before deletion")
+				}
 
-			if err := patchMachine(ctx, patchHelper, m); err != nil {
-				return ctrl.Result{}, errors.Wrap(err, "failed to patch Machine")
-			}
+				setMachineDeletionStep(m, clusterv1.MachineDeletionStepDraining)
 
-			if result, err := r.drainNode(ctx, cluster, m.Status.NodeRef.Name); !result.IsZero() || err != nil {
-				if err != nil {
-					conditions.MarkFalse(m, clusterv1.DrainingSucceededCondition, clusterv1.DrainingFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
-					r.recorder.Eventf(m, corev1.EventTypeWarning, "FailedDrainNode", "error draining Machine's node %q: %v", m.Status.NodeRef.Name, err)
+				if err := patchMachine(ctx, patchHelper, m); err != nil {
+					return ctrl.Result{}, errors.Wrap(err, "failed to patch Machine")
+				}
+
+				if result, err := r.drainNode(ctx, cluster, m.Status.NodeRef.Name); !result.IsZero() || err != nil {
+					if err != nil {
+						conditions.MarkFalse(m, clusterv1.DrainingSucceededCondition, clusterv1.DrainingFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+						r.recorder.Eventf(m, corev1.EventTypeWarning, "FailedDrainNode", "error draining Machine's node %q: %v", m.Status.NodeRef.Name, err)
+					}
+					return result, err
 				}
-				return result, err
+
+				conditions.MarkTrue(m, clusterv1.DrainingSucceededCondition)
+				r.recorder.Eventf(m, corev1.EventTypeNormal, "SuccessfulDrainNode", "success draining Machine's node %q", m.Status.NodeRef.Name)
 			}
 
-			conditions.MarkTrue(m, clusterv1.DrainingSucceededCondition)
-			r.recorder.Eventf(m, corev1.EventTypeNormal, "SuccessfulDrainNode", "success draining Machine's node %q", m.Status.NodeRef.Name)
+			// Wait for the volumes to be detached from the drained node before deleting the infrastructure,
+			// so they can be safely attached to other nodes; the wait is bound by the node volume detach timeout.
+			setMachineDeletionStep(m, clusterv1.MachineDeletionStepWaitingForVolumeDetach)
+			attached, err := r.nodeVolumesAttached(ctx, cluster, m.Status.NodeRef.Name)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			if attached {
+				if !r.nodeVolumeDetachTimeoutExceeded(m) {
+					log.Info("Waiting for volumes to be detached from node", "node", m.Status.NodeRef.Name)
+					return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+				}
+				log.Info("Timed out waiting for volumes to be detached from node, moving on", "node", m.Status.NodeRef.Name)
+			}
 		}
 	}
 
@@ -345,6 +409,13 @@ func (r *MachineReconciler) reconcileDelete(ctx context.Context, cluster *cluste
 		return ctrl.Result{}, errors.Wrap(err, "failed to patch Machine")
 	}
 
+	// The delete policy is set into the infrastructure machine once, when the infrastructure deletion starts.
+	if m.Spec.DeletePolicy == clusterv1.MachineDeletePolicyRetain && m.Status.DeletionStep != clusterv1.MachineDeletionStepDeletingInfrastructure {
+		if err := r.reconcileInfrastructureDeletePolicy(ctx, m); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	setMachineDeletionStep(m, clusterv1.MachineDeletionStepDeletingInfrastructure)
 	if ok, err := r.reconcileDeleteInfrastructure(ctx, m); !ok || err != nil {
 		return ctrl.Result{}, err
 	}
@@ -357,14 +428,28 @@ func (r *MachineReconciler) reconcileDelete(ctx context.Context, cluster *cluste
 	// https://github.com/kubernetes-sigs/cluster-api/issues/2565
 	if isDeleteNodeAllowed {
 		log.Info("Deleting node", "node", m.Status.NodeRef.Name)
+		setMachineDeletionStep(m, clusterv1.MachineDeletionStepDeletingNode)
 
 		var deleteNodeErr error
-		waitErr := wait.PollImmediate(2*time.Second, 10*time.Second, func() (bool, error) {
+		var waitErr error
+		if m.Spec.NodeDeletionTimeout != nil {
+			// When a node deletion timeout is set, the node deletion is retried across reconciliations
+			// until the timeout is exceeded.
 			if deleteNodeErr = r.deleteNode(ctx, cluster, m.Status.NodeRef.Name); deleteNodeErr != nil && !apierrors.IsNotFound(errors.Cause(deleteNodeErr)) {
-				return false, nil
+				if !r.nodeDeletionTimeoutExceeded(m) {
+					r.recorder.Eventf(m, corev1.EventTypeWarning, "FailedDeleteNode", "error deleting Machine's node: %v", deleteNodeErr)
+					return ctrl.Result{}, deleteNodeErr
+				}
+				waitErr = wait.ErrWaitTimeout
 			}
-			return true, nil
-		})
+		} else {
+			waitErr = wait.PollImmediate(2*time.Second, 10*time.Second, func() (bool, error) {
+				if deleteNodeErr = r.deleteNode(ctx, cluster, m.Status.NodeRef.Name); deleteNodeErr != nil && !apierrors.IsNotFound(errors.Cause(deleteNodeErr)) {
+					return false, nil
+				}
+				return true, nil
+			})
+		}
 		if waitErr != nil {
 			log.Error(deleteNodeErr, "Timed out deleting node, moving on", "node", m.Status.NodeRef.Name)
 			conditions.MarkFalse(m, clusterv1.MachineNodeHealthyCondition, clusterv1.DeletionFailedReason, clusterv1.ConditionSeverityWarning, "")
@@ -372,6 +457,105 @@ func (r *MachineReconciler) reconcileDelete(ctx context.Context, cluster *cluste
 		}
 	}
 
+	recordMachineDeleted(m)
+	controllerutil.RemoveFinalizer(m, clusterv1.MachineFinalizer)
+	return ctrl.Result{}, nil
+}
+
+// reconcileOrphan handles a machine whose Cluster does not exist, e.g. because the Cluster finalizer has been removed
+// manually. The machine is reported as orphaned and, if the orphan machine cleanup is enabled, it is deleted together
+// with its infrastructure and bootstrap objects; the node drain and the node deletion are skipped, given that
+// the workload cluster can't be accessed anymore.
+// NOTE: The machine is deleted only if the Cluster is not found by a live read as well, and only after the machine has
+// been orphaned for orphanMachineCleanupGracePeriod, so machines created together with their Cluster, e.g. by
+// clusterctl move, are not deleted if they are reconciled before the Cluster reaches the cache.
+func (r *MachineReconciler) reconcileOrphan(ctx context.Context, m *clusterv1.Machine) (_ ctrl.Result, reterr error) {
+	log := ctrl.LoggerFrom(ctx, "cluster", m.Spec.ClusterName)
+
+	if annotations.HasPausedAnnotation(m) {
+		log.Info("Reconciliation is paused for this object")
+		return ctrl.Result{}, nil
+	}
+
+	// Machines without the finalizer being deleted have nothing to cleanup.
+	if !controllerutil.ContainsFinalizer(m, clusterv1.MachineFinalizer) && !m.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	// Confirm that the Cluster does not exist with a live read, the cache could be not yet up to date.
+	reader := r.APIReader
+	if reader == nil {
+		reader = r.Client
+	}
+	if err := reader.Get(ctx, client.ObjectKey{Namespace: m.Namespace, Name: m.Spec.ClusterName}, &clusterv1.Cluster{}); err == nil {
+		log.Info("Cluster for Machine not found in the cache, waiting for the cache to be updated")
+		return ctrl.Result{RequeueAfter: orphanMachineCacheRequeueAfter}, nil
+	} else if !apierrors.IsNotFound(err) {
+		return ctrl.Result{}, errors.Wrapf(err, "failed to get cluster %q for machine %q in namespace %q", m.Spec.ClusterName, m.Name, m.Namespace)
+	}
+
+	patchHelper, err := patch.NewHelper(m, r.Client)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	removed := false
+	defer func() {
+		// Machines without the finalizer do not exist anymore once deleted.
+		if removed {
+			return
+		}
+
+		r.reconcilePhase(ctx, m)
+
+		if err := patchMachine(ctx, patchHelper, m); err != nil {
+			reterr = kerrors.NewAggregate([]error{reterr, err})
+		}
+	}()
+
+	conditions.MarkFalse(m, clusterv1.ClusterFoundCondition, clusterv1.ClusterNotFoundReason, clusterv1.ConditionSeverityError, "Cluster %s does not exist", m.Spec.ClusterName)
+
+	// Without the orphan machine cleanup the machine is left untouched, so it can be adopted again by recreating the Cluster.
+	if !r.OrphanMachineCleanup {
+		log.Info("Cluster for Machine does not exist, waiting for the Cluster to be recreated or for the orphan machine cleanup to be enabled")
+		return ctrl.Result{}, nil
+	}
+
+	if m.DeletionTimestamp.IsZero() {
+		if orphanedFor := time.Since(conditions.GetLastTransitionTime(m, clusterv1.ClusterFoundCondition).Time); orphanedFor < orphanMachineCleanupGracePeriod {
+			log.Info("Cluster for Machine does not exist, waiting for the grace period to expire before deleting the Machine")
+			return ctrl.Result{RequeueAfter: orphanMachineCleanupGracePeriod - orphanedFor}, nil
+		}
+
+		log.Info("Deleting Machine, its Cluster does not exist")
+		if err := r.Client.Delete(ctx, m); err != nil && !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, errors.Wrapf(err, "failed to delete orphan Machine %q in namespace %q", m.Name, m.Namespace)
+		}
+		removed = !controllerutil.ContainsFinalizer(m, clusterv1.MachineFinalizer)
+		return ctrl.Result{}, nil
+	}
+
+	// Machines without the finalizer are removed as soon as they are deleted.
+	if !controllerutil.ContainsFinalizer(m, clusterv1.MachineFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	// The delete policy is set into the infrastructure machine once, when the infrastructure deletion starts.
+	if m.Spec.DeletePolicy == clusterv1.MachineDeletePolicyRetain && m.Status.DeletionStep != clusterv1.MachineDeletionStepDeletingInfrastructure {
+		if err := r.reconcileInfrastructureDeletePolicy(ctx, m); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	setMachineDeletionStep(m, clusterv1.MachineDeletionStepDeletingInfrastructure)
+	if ok, err := r.reconcileDeleteInfrastructure(ctx, m); !ok || err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if ok, err := r.reconcileDeleteBootstrap(ctx, m); !ok || err != nil {
+		return ctrl.Result{}, err
+	}
+
+	recordMachineDeleted(m)
 	controllerutil.RemoveFinalizer(m, clusterv1.MachineFinalizer)
 	return ctrl.Result{}, nil
 }
@@ -405,6 +589,39 @@ func (r *MachineReconciler) nodeDrainTimeoutExceeded(machine *clusterv1.Machine)
 	return diff.Seconds() >= machine.Spec.NodeDrainTimeout.Seconds()
 }
 
+// nodeVolumeDetachTimeoutExceeded returns true if the Machine has been waiting for the volumes to be detached
+// from its node for longer than the NodeVolumeDetachTimeout, or than defaultNodeVolumeDetachTimeout if not set.
+func (r *MachineReconciler) nodeVolumeDetachTimeoutExceeded(machine *clusterv1.Machine) bool {
+	timeout := defaultNodeVolumeDetachTimeout
+	if machine.Spec.NodeVolumeDetachTimeout != nil {
+		// if the NodeVolumeDetachTimeout is set to wait without time limitations
+		if machine.Spec.NodeVolumeDetachTimeout.Seconds() <= 0 {
+			return false
+		}
+		timeout = machine.Spec.NodeVolumeDetachTimeout.Duration
+	}
+	return deletionStepTimeoutExceeded(machine, clusterv1.MachineDeletionStepWaitingForVolumeDetach, timeout)
+}
+
+// nodeDeletionTimeoutExceeded returns true if the Machine has been trying to delete its node
+// for longer than the NodeDeletionTimeout.
+func (r *MachineReconciler) nodeDeletionTimeoutExceeded(machine *clusterv1.Machine) bool {
+	// if the NodeDeletionTimeout is not set by user, or set to retry without time limitations
+	if machine.Spec.NodeDeletionTimeout == nil || machine.Spec.NodeDeletionTimeout.Seconds() <= 0 {
+		return false
+	}
+	return deletionStepTimeoutExceeded(machine, clusterv1.MachineDeletionStepDeletingNode, machine.Spec.NodeDeletionTimeout.Duration)
+}
+
+// deletionStepTimeoutExceeded returns true if the Machine has been going through the given deletion step
+// for longer than the timeout.
+func deletionStepTimeoutExceeded(machine *clusterv1.Machine, step clusterv1.MachineDeletionStep, timeout time.Duration) bool {
+	if machine.Status.DeletionStep != step || machine.Status.DeletionStepStartTime == nil {
+		return false
+	}
+	return time.Since(machine.Status.DeletionStepStartTime.Time) >= timeout
+}
+
 // isDeleteNodeAllowed returns nil only if the Machine's NodeRef is not nil
 // and if the Machine is not the last control plane node in the cluster.
 func (r *MachineReconciler) isDeleteNodeAllowed(ctx context.Context, cluster *clusterv1.Cluster, machine *clusterv1.Machine) error {
@@ -555,6 +772,114 @@ func (r *MachineReconciler) deleteNode(ctx context.Context, cluster *clusterv1.C
 	return nil
 }
 
+// nodeVolumesAttached returns true if any volume which is expected to be detached by the drain is still attached
+// to the node; a node which does not exist anymore, or which is unreachable, is not waited for.
+func (r *MachineReconciler) nodeVolumesAttached(ctx context.Context, cluster *clusterv1.Cluster, name string) (bool, error) {
+	log := ctrl.LoggerFrom(ctx, "cluster", cluster.Name, "node", name)
+
+	// Use an uncached client, so listing the pods of the node does not start a Pod informer for the workload cluster.
+	restConfig, err := remote.RESTConfig(ctx, MachineControllerName, r.Client, util.ObjectKey(cluster))
+	if err != nil {
+		log.Error(err, "Error creating a remote client while deleting Machine, won't wait for volumes to be detached")
+		return false, nil
+	}
+	kubeClient, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		log.Error(err, "Error creating a remote client while deleting Machine, won't wait for volumes to be detached")
+		return false, nil
+	}
+
+	return volumesAttached(ctx, kubeClient, name)
+}
+
+// volumesAttached returns true if the node has attached volumes other than the ones used by DaemonSet and mirror
+// pods; those pods are not evicted by the drain, so their volumes are never detached before the node goes away.
+func volumesAttached(ctx context.Context, kubeClient kubernetes.Interface, name string) (bool, error) {
+	node, err := kubeClient.CoreV1().Nodes().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, errors.Wrapf(err, "error getting node %s", name)
+	}
+	if noderefutil.IsNodeUnreachable(node) || len(node.Status.VolumesAttached) == 0 {
+		return false, nil
+	}
+
+	pods, err := kubeClient.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("spec.nodeName", name).String(),
+	})
+	if err != nil {
+		return false, errors.Wrapf(err, "error listing pods on node %s", name)
+	}
+
+	ignored := map[corev1.UniqueVolumeName]bool{}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if !isDaemonSetOrMirrorPod(pod) {
+			continue
+		}
+		for _, volume := range pod.Spec.Volumes {
+			if volume.PersistentVolumeClaim == nil {
+				continue
+			}
+			volumeName, err := persistentVolumeUniqueName(ctx, kubeClient, pod.Namespace, volume.PersistentVolumeClaim.ClaimName)
+			if err != nil {
+				return false, err
+			}
+			if volumeName != "" {
+				ignored[volumeName] = true
+			}
+		}
+	}
+
+	for _, volume := range node.Status.VolumesAttached {
+		if !ignored[volume.Name] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// isDaemonSetOrMirrorPod returns true if the pod is managed by a DaemonSet or is a mirror pod of a static pod.
+func isDaemonSetOrMirrorPod(pod *corev1.Pod) bool {
+	if _, ok := pod.Annotations[corev1.MirrorPodAnnotationKey]; ok {
+		return true
+	}
+	if controllerRef := metav1.GetControllerOf(pod); controllerRef != nil && controllerRef.Kind == "DaemonSet" {
+		return true
+	}
+	return false
+}
+
+// persistentVolumeUniqueName returns the name under which the volume bound to a PersistentVolumeClaim is reported
+// in the node's attached volumes. Only CSI volumes can be resolved; an empty name is returned for the others,
+// so they are waited for until the node volume detach timeout.
+func persistentVolumeUniqueName(ctx context.Context, kubeClient kubernetes.Interface, namespace, claimName string) (corev1.UniqueVolumeName, error) {
+	pvc, err := kubeClient.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, claimName, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", errors.Wrapf(err, "error getting PersistentVolumeClaim %s/%s", namespace, claimName)
+	}
+	if pvc.Spec.VolumeName == "" {
+		return "", nil
+	}
+
+	pv, err := kubeClient.CoreV1().PersistentVolumes().Get(ctx, pvc.Spec.VolumeName, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", errors.Wrapf(err, "error getting PersistentVolume %s", pvc.Spec.VolumeName)
+	}
+	if pv.Spec.CSI == nil {
+		return "", nil
+	}
+	return corev1.UniqueVolumeName(fmt.Sprintf("kubernetes.io/csi/%s^%s", pv.Spec.CSI.Driver, pv.Spec.CSI.VolumeHandle)), nil
+}
+
 func (r *MachineReconciler) reconcileDeleteBootstrap(ctx context.Context, m *clusterv1.Machine) (bool, error) {
 	obj, err := r.reconcileDeleteExternal(ctx, m, m.Spec.Bootstrap.ConfigRef)
 	if err != nil {
@@ -595,6 +920,48 @@ func (r *MachineReconciler) reconcileDeleteInfrastructure(ctx context.Context, m
 	return false, nil
 }
 
+// reconcileInfrastructureDeletePolicy sets the delete policy of the Machine into the spec.deletePolicy field of the
+// infrastructure machine before deleting it, so the infrastructure provider can retain the infrastructure
+// instead of destroying it.
+// NOTE: If the infrastructure machine schema does not define spec.deletePolicy, the API server prunes the field and
+// the infrastructure is destroyed; this is surfaced with a Warning event.
+func (r *MachineReconciler) reconcileInfrastructureDeletePolicy(ctx context.Context, m *clusterv1.Machine) error {
+	ref := &m.Spec.InfrastructureRef
+	obj, err := external.Get(ctx, r.Client, ref, m.Namespace)
+	if err != nil {
+		if apierrors.IsNotFound(errors.Cause(err)) {
+			return nil
+		}
+		return errors.Wrapf(err, "failed to get %s %q for Machine %q in namespace %q",
+			ref.GroupVersionKind(), ref.Name, m.Name, m.Namespace)
+	}
+
+	deletePolicy, _, err := unstructured.NestedString(obj.Object, "spec", "deletePolicy")
+	if err != nil || deletePolicy == string(m.Spec.DeletePolicy) {
+		return err
+	}
+
+	patchHelper, err := patch.NewHelper(obj, r.Client)
+	if err != nil {
+		return err
+	}
+	if err := unstructured.SetNestedField(obj.Object, string(m.Spec.DeletePolicy), "spec", "deletePolicy"); err != nil {
+		return err
+	}
+	if err := patchHelper.Patch(ctx, obj); err != nil {
+		return errors.Wrapf(err, "failed to set the delete policy of %v %q for Machine %q in namespace %q",
+			obj.GroupVersionKind(), obj.GetName(), m.Name, m.Namespace)
+	}
+
+	// The patched object is read back from the API server, which prunes the fields not defined in the schema.
+	if _, found, _ := unstructured.NestedString(obj.Object, "spec", "deletePolicy"); !found {
+		r.recorder.Eventf(m, corev1.EventTypeWarning, "DeletePolicyNotSupported", "The delete policy is not supported by %v %q, the infrastructure of the Machine is deleted", obj.GroupVersionKind().Kind, obj.GetName())
+		return nil
+	}
+	r.recorder.Eventf(m, corev1.EventTypeNormal, "RetainingInfrastructure", "Retaining the infrastructure of the Machine, %v %q", obj.GroupVersionKind().Kind, obj.GetName())
+	return nil
+}
+
 // reconcileDeleteExternal tries to delete external references.
 func (r *MachineReconciler) reconcileDeleteExternal(ctx context.Context, m *clusterv1.Machine, ref *corev1.ObjectReference) (*unstructured.Unstructured, error) {
 	if ref == nil {
@@ -0,0 +1,167 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/controllers/external"
+	"sigs.k8s.io/cluster-api/util/annotations"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// reconcileAuxiliaryInfrastructure reconciles the Spec.AuxiliaryInfrastructure objects on a Machine.
+// Objects defined by a template are cloned and controlled by the Machine, while objects referenced by the user
+// are only read, given that they could be shared with other Machines; the readiness of all the objects
+// is then reported as an aggregate in the AuxiliaryInfrastructureReady condition, which gates the provisioning
+// of the bootstrap and infrastructure objects of the Machine.
+func (r *MachineReconciler) reconcileAuxiliaryInfrastructure(ctx context.Context, cluster *clusterv1.Cluster, m *clusterv1.Machine) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx, "cluster", cluster.Name)
+
+	if len(m.Spec.AuxiliaryInfrastructure) == 0 {
+		return ctrl.Result{}, nil
+	}
+
+	// Auxiliary infrastructure objects are deleted together with the Machine, by the garbage collector.
+	if !m.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	notReady := []string{}
+	for i := range m.Spec.AuxiliaryInfrastructure {
+		aux := &m.Spec.AuxiliaryInfrastructure[i]
+
+		// Create the object from the template, if not yet created.
+		if aux.Ref == nil {
+			if aux.TemplateRef == nil {
+				return ctrl.Result{}, errors.Errorf("auxiliary infrastructure %q for Machine %q in namespace %q must have either a templateRef or a ref", aux.Name, m.Name, m.Namespace)
+			}
+			ref, err := r.createAuxiliaryInfrastructure(ctx, cluster, m, aux)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			aux.Ref = ref
+		}
+
+		// Objects created from a template are adopted by calling the generic external reconciler, which sets
+		// the controller reference and adds a watch on the object.
+		var auxResult external.ReconcileOutput
+		var err error
+		if aux.TemplateRef != nil {
+			auxResult, err = r.reconcileExternal(ctx, cluster, m, aux.Ref)
+		} else {
+			auxResult, err = r.getAuxiliaryInfrastructure(ctx, cluster, m, aux.Ref)
+		}
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if auxResult.RequeueAfter > 0 || auxResult.Paused {
+			notReady = append(notReady, aux.Name)
+			continue
+		}
+
+		ready, err := external.IsReady(auxResult.Result)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if !ready {
+			notReady = append(notReady, aux.Name)
+		}
+	}
+
+	if len(notReady) > 0 {
+		conditions.MarkFalse(m, clusterv1.AuxiliaryInfrastructureReadyCondition, clusterv1.WaitingForAuxiliaryInfrastructureReason, clusterv1.ConditionSeverityInfo,
+			"Waiting for auxiliary infrastructure %s", strings.Join(notReady, ", "))
+		log.Info("Auxiliary infrastructure is not ready, requeuing", "notReady", notReady)
+		return ctrl.Result{RequeueAfter: externalReadyWait}, nil
+	}
+
+	conditions.MarkTrue(m, clusterv1.AuxiliaryInfrastructureReadyCondition)
+	return ctrl.Result{}, nil
+}
+
+// getAuxiliaryInfrastructure gets an auxiliary infrastructure object referenced by the user.
+// NOTE: The object is not modified, e.g. no owner reference is set, so it is not garbage collected when the Machine
+// is deleted; given that the object is not watched, readiness is checked by requeuing until it is ready.
+func (r *MachineReconciler) getAuxiliaryInfrastructure(ctx context.Context, cluster *clusterv1.Cluster, m *clusterv1.Machine, ref *corev1.ObjectReference) (external.ReconcileOutput, error) {
+	log := ctrl.LoggerFrom(ctx, "cluster", cluster.Name)
+
+	obj, err := external.Get(ctx, r.Client, ref, m.Namespace)
+	if err != nil {
+		if apierrors.IsNotFound(errors.Cause(err)) {
+			log.Info("could not find auxiliary infrastructure, requeueing", "RefGVK", ref.GroupVersionKind(), "RefName", ref.Name, "Machine", m.Name, "Namespace", m.Namespace)
+			return external.ReconcileOutput{RequeueAfter: externalReadyWait}, nil
+		}
+		return external.ReconcileOutput{}, err
+	}
+
+	if annotations.IsPaused(cluster, obj) {
+		log.V(3).Info("Auxiliary infrastructure referenced is paused")
+		return external.ReconcileOutput{Paused: true}, nil
+	}
+	return external.ReconcileOutput{Result: obj}, nil
+}
+
+// isAuxiliaryInfrastructureReady returns true if the Machine does not define auxiliary infrastructure objects,
+// or if all of them are ready.
+func isAuxiliaryInfrastructureReady(m *clusterv1.Machine) bool {
+	return len(m.Spec.AuxiliaryInfrastructure) == 0 || conditions.IsTrue(m, clusterv1.AuxiliaryInfrastructureReadyCondition)
+}
+
+// createAuxiliaryInfrastructure creates an auxiliary infrastructure object from its template.
+// The object name is derived from the Machine and the auxiliary infrastructure names, so an object created
+// during a previous reconcile whose reference was not yet persisted on the Machine is adopted instead of duplicated.
+func (r *MachineReconciler) createAuxiliaryInfrastructure(ctx context.Context, cluster *clusterv1.Cluster, m *clusterv1.Machine, aux *clusterv1.AuxiliaryInfrastructure) (*corev1.ObjectReference, error) {
+	template, err := external.Get(ctx, r.Client, aux.TemplateRef, m.Namespace)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get template for auxiliary infrastructure %q for Machine %q in namespace %q", aux.Name, m.Name, m.Namespace)
+	}
+
+	to, err := external.GenerateTemplate(&external.GenerateTemplateInput{
+		Template:    template,
+		TemplateRef: aux.TemplateRef,
+		Namespace:   m.Namespace,
+		ClusterName: cluster.Name,
+		OwnerRef:    metav1.NewControllerRef(m, clusterv1.GroupVersion.WithKind("Machine")),
+		Labels: map[string]string{
+			clusterv1.ClusterLabelName: cluster.Name,
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to generate auxiliary infrastructure %q for Machine %q in namespace %q", aux.Name, m.Name, m.Namespace)
+	}
+	to.SetName(fmt.Sprintf("%s-%s", m.Name, aux.Name))
+
+	if err := r.Client.Create(ctx, to); err != nil {
+		if !apierrors.IsAlreadyExists(err) {
+			return nil, errors.Wrapf(err, "failed to create auxiliary infrastructure %q for Machine %q in namespace %q", aux.Name, m.Name, m.Namespace)
+		}
+		if err := r.Client.Get(ctx, client.ObjectKeyFromObject(to), to); err != nil {
+			return nil, errors.Wrapf(err, "failed to get auxiliary infrastructure %q for Machine %q in namespace %q", aux.Name, m.Name, m.Namespace)
+		}
+	}
+	return external.GetObjectReference(to), nil
+}
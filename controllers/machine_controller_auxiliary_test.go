@@ -0,0 +1,311 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/record"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/controllers/external"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReconcileAuxiliaryInfrastructure(t *testing.T) {
+	defaultCluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster",
+			Namespace: "default",
+		},
+	}
+
+	template := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"kind":       "InfrastructureMachineTemplate",
+			"apiVersion": "infrastructure.cluster.x-k8s.io/v1alpha4",
+			"metadata": map[string]interface{}{
+				"name":      "nic-template",
+				"namespace": "default",
+			},
+			"spec": map[string]interface{}{
+				"template": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"network": "secondary",
+					},
+				},
+			},
+		},
+	}
+
+	auxObject := func(name string, ready bool) *unstructured.Unstructured {
+		return &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"kind":       "InfrastructureMachine",
+				"apiVersion": "infrastructure.cluster.x-k8s.io/v1alpha4",
+				"metadata": map[string]interface{}{
+					"name":      name,
+					"namespace": "default",
+				},
+				"status": map[string]interface{}{
+					"ready": ready,
+				},
+			},
+		}
+	}
+
+	auxRef := func(name string) *corev1.ObjectReference {
+		return &corev1.ObjectReference{
+			APIVersion: "infrastructure.cluster.x-k8s.io/v1alpha4",
+			Kind:       "InfrastructureMachine",
+			Name:       name,
+			Namespace:  "default",
+		}
+	}
+
+	testCases := []struct {
+		name         string
+		aux          []clusterv1.AuxiliaryInfrastructure
+		objs         []client.Object
+		expectResult ctrl.Result
+		expected     func(g *WithT, c client.Client, m *clusterv1.Machine)
+	}{
+		{
+			name:         "no auxiliary infrastructure",
+			expectResult: ctrl.Result{},
+			expected: func(g *WithT, c client.Client, m *clusterv1.Machine) {
+				g.Expect(conditions.Has(m, clusterv1.AuxiliaryInfrastructureReadyCondition)).To(BeFalse())
+			},
+		},
+		{
+			name: "creates auxiliary infrastructure from template",
+			aux: []clusterv1.AuxiliaryInfrastructure{
+				{
+					Name: "nic1",
+					TemplateRef: &corev1.ObjectReference{
+						APIVersion: "infrastructure.cluster.x-k8s.io/v1alpha4",
+						Kind:       "InfrastructureMachineTemplate",
+						Name:       "nic-template",
+						Namespace:  "default",
+					},
+				},
+			},
+			objs:         []client.Object{template.DeepCopy()},
+			expectResult: ctrl.Result{RequeueAfter: externalReadyWait},
+			expected: func(g *WithT, c client.Client, m *clusterv1.Machine) {
+				g.Expect(m.Spec.AuxiliaryInfrastructure[0].Ref).NotTo(BeNil())
+				g.Expect(m.Spec.AuxiliaryInfrastructure[0].Ref.Name).To(Equal("machine-test-nic1"))
+
+				obj := &unstructured.Unstructured{}
+				obj.SetAPIVersion("infrastructure.cluster.x-k8s.io/v1alpha4")
+				obj.SetKind("InfrastructureMachine")
+				g.Expect(c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "machine-test-nic1"}, obj)).To(Succeed())
+				g.Expect(obj.GetOwnerReferences()).To(HaveLen(1))
+				g.Expect(obj.GetOwnerReferences()[0].Name).To(Equal("machine-test"))
+				g.Expect(obj.GetLabels()).To(HaveKeyWithValue(clusterv1.ClusterLabelName, "test-cluster"))
+
+				g.Expect(conditions.IsFalse(m, clusterv1.AuxiliaryInfrastructureReadyCondition)).To(BeTrue())
+				g.Expect(conditions.GetReason(m, clusterv1.AuxiliaryInfrastructureReadyCondition)).To(Equal(clusterv1.WaitingForAuxiliaryInfrastructureReason))
+			},
+		},
+		{
+			name: "all auxiliary infrastructure ready",
+			aux: []clusterv1.AuxiliaryInfrastructure{
+				{Name: "nic1", Ref: auxRef("nic1")},
+				{Name: "nic2", Ref: auxRef("nic2")},
+			},
+			objs:         []client.Object{auxObject("nic1", true), auxObject("nic2", true)},
+			expectResult: ctrl.Result{},
+			expected: func(g *WithT, c client.Client, m *clusterv1.Machine) {
+				g.Expect(conditions.IsTrue(m, clusterv1.AuxiliaryInfrastructureReadyCondition)).To(BeTrue())
+
+				// Objects referenced by the user are not modified, given that they could be shared with other Machines.
+				obj := &unstructured.Unstructured{}
+				obj.SetAPIVersion("infrastructure.cluster.x-k8s.io/v1alpha4")
+				obj.SetKind("InfrastructureMachine")
+				g.Expect(c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "nic1"}, obj)).To(Succeed())
+				g.Expect(obj.GetOwnerReferences()).To(BeEmpty())
+				g.Expect(obj.GetLabels()).NotTo(HaveKey(clusterv1.ClusterLabelName))
+			},
+		},
+		{
+			name: "some auxiliary infrastructure not ready or missing",
+			aux: []clusterv1.AuxiliaryInfrastructure{
+				{Name: "nic1", Ref: auxRef("nic1")},
+				{Name: "nic2", Ref: auxRef("nic2")},
+				{Name: "nic3", Ref: auxRef("nic3")},
+			},
+			objs:         []client.Object{auxObject("nic1", true), auxObject("nic2", false)},
+			expectResult: ctrl.Result{RequeueAfter: externalReadyWait},
+			expected: func(g *WithT, c client.Client, m *clusterv1.Machine) {
+				g.Expect(conditions.IsFalse(m, clusterv1.AuxiliaryInfrastructureReadyCondition)).To(BeTrue())
+				g.Expect(conditions.GetMessage(m, clusterv1.AuxiliaryInfrastructureReadyCondition)).To(Equal("Waiting for auxiliary infrastructure nic2, nic3"))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			machine := &clusterv1.Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "machine-test",
+					Namespace: "default",
+					Labels: map[string]string{
+						clusterv1.ClusterLabelName: "test-cluster",
+					},
+				},
+				Spec: clusterv1.MachineSpec{
+					ClusterName:             "test-cluster",
+					AuxiliaryInfrastructure: tc.aux,
+				},
+			}
+
+			objs := append([]client.Object{
+				machine.DeepCopy(),
+				external.TestGenericInfrastructureCRD.DeepCopy(),
+				external.TestGenericInfrastructureTemplateCRD.DeepCopy(),
+			}, tc.objs...)
+			c := fake.NewClientBuilder().WithObjects(objs...).Build()
+			r := &MachineReconciler{
+				Client:   c,
+				recorder: record.NewFakeRecorder(32),
+			}
+
+			result, err := r.reconcileAuxiliaryInfrastructure(ctx, defaultCluster, machine)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(result).To(Equal(tc.expectResult))
+			tc.expected(g, c, machine)
+		})
+	}
+}
+
+func TestAuxiliaryInfrastructureGatesProvisioning(t *testing.T) {
+	g := NewWithT(t)
+
+	defaultCluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster",
+			Namespace: "default",
+		},
+	}
+
+	bootstrapConfig := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"kind":       "BootstrapMachine",
+			"apiVersion": "bootstrap.cluster.x-k8s.io/v1alpha4",
+			"metadata": map[string]interface{}{
+				"name":      "bootstrap-config1",
+				"namespace": "default",
+			},
+		},
+	}
+	infraMachine := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"kind":       "InfrastructureMachine",
+			"apiVersion": "infrastructure.cluster.x-k8s.io/v1alpha4",
+			"metadata": map[string]interface{}{
+				"name":      "infra-config1",
+				"namespace": "default",
+			},
+		},
+	}
+
+	machine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "machine-test",
+			Namespace: "default",
+			Labels: map[string]string{
+				clusterv1.ClusterLabelName: "test-cluster",
+			},
+		},
+		Spec: clusterv1.MachineSpec{
+			ClusterName: "test-cluster",
+			Bootstrap: clusterv1.Bootstrap{
+				ConfigRef: &corev1.ObjectReference{
+					APIVersion: "bootstrap.cluster.x-k8s.io/v1alpha4",
+					Kind:       "BootstrapMachine",
+					Name:       "bootstrap-config1",
+				},
+			},
+			InfrastructureRef: corev1.ObjectReference{
+				APIVersion: "infrastructure.cluster.x-k8s.io/v1alpha4",
+				Kind:       "InfrastructureMachine",
+				Name:       "infra-config1",
+			},
+			AuxiliaryInfrastructure: []clusterv1.AuxiliaryInfrastructure{
+				{
+					Name: "nic1",
+					Ref: &corev1.ObjectReference{
+						APIVersion: "infrastructure.cluster.x-k8s.io/v1alpha4",
+						Kind:       "InfrastructureMachine",
+						Name:       "nic1",
+					},
+				},
+			},
+		},
+	}
+	conditions.MarkFalse(machine, clusterv1.AuxiliaryInfrastructureReadyCondition, clusterv1.WaitingForAuxiliaryInfrastructureReason, clusterv1.ConditionSeverityInfo, "")
+
+	c := fake.NewClientBuilder().WithObjects(
+		machine.DeepCopy(),
+		external.TestGenericBootstrapCRD.DeepCopy(),
+		external.TestGenericInfrastructureCRD.DeepCopy(),
+		bootstrapConfig,
+		infraMachine,
+	).Build()
+	r := &MachineReconciler{
+		Client:   c,
+		recorder: record.NewFakeRecorder(32),
+	}
+
+	getOwnerReferences := func(obj *unstructured.Unstructured) []metav1.OwnerReference {
+		current := &unstructured.Unstructured{}
+		current.SetGroupVersionKind(obj.GroupVersionKind())
+		g.Expect(c.Get(ctx, client.ObjectKeyFromObject(obj), current)).To(Succeed())
+		return current.GetOwnerReferences()
+	}
+
+	// The bootstrap config and the infrastructure machine are not adopted while the auxiliary infrastructure is not ready.
+	_, err := r.reconcileBootstrap(ctx, defaultCluster, machine)
+	g.Expect(err).NotTo(HaveOccurred())
+	_, err = r.reconcileInfrastructure(ctx, defaultCluster, machine)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	g.Expect(getOwnerReferences(bootstrapConfig)).To(BeEmpty())
+	g.Expect(getOwnerReferences(infraMachine)).To(BeEmpty())
+	g.Expect(conditions.GetReason(machine, clusterv1.BootstrapReadyCondition)).To(Equal(clusterv1.WaitingForAuxiliaryInfrastructureReason))
+	g.Expect(conditions.GetReason(machine, clusterv1.InfrastructureReadyCondition)).To(Equal(clusterv1.WaitingForAuxiliaryInfrastructureReason))
+
+	// The bootstrap config and the infrastructure machine are adopted once the auxiliary infrastructure is ready.
+	conditions.MarkTrue(machine, clusterv1.AuxiliaryInfrastructureReadyCondition)
+	_, err = r.reconcileBootstrap(ctx, defaultCluster, machine)
+	g.Expect(err).NotTo(HaveOccurred())
+	_, err = r.reconcileInfrastructure(ctx, defaultCluster, machine)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	g.Expect(getOwnerReferences(bootstrapConfig)).To(HaveLen(1))
+	g.Expect(getOwnerReferences(infraMachine)).To(HaveLen(1))
+}
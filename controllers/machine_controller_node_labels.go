@@ -24,6 +24,7 @@ import (
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 	"sigs.k8s.io/cluster-api/controllers/external"
 	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/labels"
 	"sigs.k8s.io/cluster-api/util/patch"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -92,3 +93,40 @@ func (r *MachineReconciler) setInterruptibleNodeLabel(ctx context.Context, remot
 
 	return patchHelper.Patch(ctx, node)
 }
+
+// syncNodeLabels sets on the Node the well-known labels identifying the Cluster, Machine, MachineSet and MachineDeployment
+// the Node belongs to, removing the MachineSet and MachineDeployment labels if the Machine is no longer controlled by them.
+// Machine names longer than 63 characters are not valid label values, so they are hashed.
+// It returns true if the labels of the Node have been changed.
+func syncNodeLabels(node *corev1.Node, machine *clusterv1.Machine) bool {
+	desired := map[string]string{
+		clusterv1.ClusterLabelName:     machine.Spec.ClusterName,
+		clusterv1.MachineNameLabelName: labels.MustFormatValue(machine.Name),
+	}
+	var stale []string
+	for _, name := range []string{clusterv1.MachineSetLabelName, clusterv1.MachineDeploymentLabelName} {
+		if value, ok := machine.Labels[name]; ok {
+			desired[name] = value
+			continue
+		}
+		stale = append(stale, name)
+	}
+
+	if node.Labels == nil {
+		node.Labels = map[string]string{}
+	}
+	changed := false
+	for name, value := range desired {
+		if current, ok := node.Labels[name]; !ok || current != value {
+			node.Labels[name] = value
+			changed = true
+		}
+	}
+	for _, name := range stale {
+		if _, ok := node.Labels[name]; ok {
+			delete(node.Labels, name)
+			changed = true
+		}
+	}
+	return changed
+}
@@ -18,6 +18,7 @@ package controllers
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 
@@ -31,6 +32,7 @@ import (
 
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 	"sigs.k8s.io/cluster-api/controllers/remote"
+	"sigs.k8s.io/cluster-api/util/labels"
 	"sigs.k8s.io/cluster-api/util/patch"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -138,3 +140,101 @@ func TestReconcileInterruptibleNodeLabel(t *testing.T) {
 		return ok
 	}, 10*time.Second).Should(BeTrue())
 }
+
+func TestSyncNodeLabels(t *testing.T) {
+	machine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "machine-1",
+			Labels: map[string]string{
+				clusterv1.MachineSetLabelName:        "ms-1",
+				clusterv1.MachineDeploymentLabelName: "md-1",
+			},
+		},
+		Spec: clusterv1.MachineSpec{
+			ClusterName: "cluster-1",
+		},
+	}
+	machineWithoutOwners := machine.DeepCopy()
+	machineWithoutOwners.Labels = nil
+	machineWithLongName := machineWithoutOwners.DeepCopy()
+	machineWithLongName.Name = strings.Repeat("m", 100)
+
+	wellKnownLabels := map[string]string{
+		clusterv1.ClusterLabelName:           "cluster-1",
+		clusterv1.MachineNameLabelName:       "machine-1",
+		clusterv1.MachineSetLabelName:        "ms-1",
+		clusterv1.MachineDeploymentLabelName: "md-1",
+	}
+
+	tests := []struct {
+		name        string
+		nodeLabels  map[string]string
+		machine     *clusterv1.Machine
+		wantLabels  map[string]string
+		wantChanged bool
+	}{
+		{
+			name:        "sets the well-known labels on a node without labels",
+			nodeLabels:  nil,
+			machine:     machine,
+			wantLabels:  wellKnownLabels,
+			wantChanged: true,
+		},
+		{
+			name:       "preserves the other labels of the node",
+			nodeLabels: map[string]string{"foo": "bar", clusterv1.MachineSetLabelName: "ms-0"},
+			machine:    machine,
+			wantLabels: map[string]string{
+				"foo":                                "bar",
+				clusterv1.ClusterLabelName:           "cluster-1",
+				clusterv1.MachineNameLabelName:       "machine-1",
+				clusterv1.MachineSetLabelName:        "ms-1",
+				clusterv1.MachineDeploymentLabelName: "md-1",
+			},
+			wantChanged: true,
+		},
+		{
+			name:        "does not change a node with the well-known labels already set",
+			nodeLabels:  wellKnownLabels,
+			machine:     machine,
+			wantLabels:  wellKnownLabels,
+			wantChanged: false,
+		},
+		{
+			name:       "removes the labels of the MachineSet and MachineDeployment no longer controlling the machine",
+			nodeLabels: wellKnownLabels,
+			machine:    machineWithoutOwners,
+			wantLabels: map[string]string{
+				clusterv1.ClusterLabelName:     "cluster-1",
+				clusterv1.MachineNameLabelName: "machine-1",
+			},
+			wantChanged: true,
+		},
+		{
+			name:       "hashes machine names which are not valid label values",
+			nodeLabels: nil,
+			machine:    machineWithLongName,
+			wantLabels: map[string]string{
+				clusterv1.ClusterLabelName:     "cluster-1",
+				clusterv1.MachineNameLabelName: labels.MustFormatValue(strings.Repeat("m", 100)),
+			},
+			wantChanged: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			node := &corev1.Node{}
+			if tt.nodeLabels != nil {
+				node.Labels = map[string]string{}
+				for k, v := range tt.nodeLabels {
+					node.Labels[k] = v
+				}
+			}
+
+			g.Expect(syncNodeLabels(node, tt.machine)).To(Equal(tt.wantChanged))
+			g.Expect(node.Labels).To(Equal(tt.wantLabels))
+		})
+	}
+}
@@ -0,0 +1,193 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/controllers/external"
+	"sigs.k8s.io/cluster-api/util"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// nodeMetadataFieldManager is the field manager used for applying the InfraMachine metadata to the Node; given that
+// only the labels and annotations applied by this field manager are removed when no longer reported by the
+// InfraMachine, labels and annotations set by other actors are never changed.
+const nodeMetadataFieldManager = "capi-machine-node-metadata"
+
+var (
+	// infraMachineNodeLabels maps the InfraMachine status fields reported on the Node as labels.
+	infraMachineNodeLabels = map[string]string{
+		"instanceType": clusterv1.NodeInstanceTypeLabel,
+		"zone":         clusterv1.NodeZoneLabel,
+	}
+
+	// infraMachineNodeAnnotations maps the InfraMachine status fields reported on the Node as annotations;
+	// annotations are used for values which are not guaranteed to be valid label values, e.g. image names.
+	infraMachineNodeAnnotations = map[string]string{
+		"image": clusterv1.NodeImageAnnotation,
+	}
+)
+
+// reconcileNodeMetadata applies to the Machine's Node the labels and annotations reporting the characteristics of the
+// machine as defined by the infrastructure provider, e.g. the instance type, so they can be used for scheduling decisions,
+// and the labels and annotations propagated from the Cluster.
+func (r *MachineReconciler) reconcileNodeMetadata(ctx context.Context, cluster *clusterv1.Cluster, machine *clusterv1.Machine) (ctrl.Result, error) {
+	// Check that the Machine hasn't been deleted or in the process
+	// and that the Machine has a NodeRef.
+	if !machine.DeletionTimestamp.IsZero() || machine.Status.NodeRef == nil {
+		return ctrl.Result{}, nil
+	}
+
+	// Get the infrastructure object
+	infra, err := external.Get(ctx, r.Client, &machine.Spec.InfrastructureRef, machine.Namespace)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	log := ctrl.LoggerFrom(ctx)
+
+	labels, annotations := nodeMetadataFromInfraMachine(infra)
+	for name, value := range labels {
+		if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+			log.V(1).Info("Skipping invalid Node label reported by the infrastructure provider", "label", name, "value", value, "errors", errs)
+			delete(labels, name)
+		}
+	}
+
+	// Add the Cluster labels and annotations to be propagated to the Nodes; the metadata reported by the
+	// infrastructure provider takes precedence.
+	propagatedLabels, propagatedAnnotations := propagatedMetadata(cluster)
+	addPropagated := func(metadata map[string]string, propagated map[string]*string) {
+		for name, value := range propagated {
+			if _, ok := metadata[name]; !ok && value != nil {
+				metadata[name] = *value
+			}
+		}
+	}
+	addPropagated(labels, propagatedLabels)
+	addPropagated(annotations, propagatedAnnotations)
+
+	remoteClient, err := r.Tracker.GetClient(ctx, util.ObjectKey(cluster))
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	node := &corev1.Node{}
+	if err := remoteClient.Get(ctx, client.ObjectKey{Name: machine.Status.NodeRef.Name}, node); err != nil {
+		// The Node is going to be reported as missing by reconcileNode.
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	if !nodeMetadataNeedsSync(node, labels, annotations) {
+		return ctrl.Result{}, nil
+	}
+
+	// Use server side apply, so labels and annotations previously applied by this field manager and no longer
+	// reported by the InfraMachine or propagated from the Cluster are removed, without changing the fields owned by other actors.
+	nodeMetadata := &unstructured.Unstructured{}
+	nodeMetadata.SetAPIVersion("v1")
+	nodeMetadata.SetKind("Node")
+	nodeMetadata.SetName(node.Name)
+	nodeMetadata.SetLabels(labels)
+	nodeMetadata.SetAnnotations(annotations)
+	if err := remoteClient.Patch(ctx, nodeMetadata, client.Apply, client.FieldOwner(nodeMetadataFieldManager), client.ForceOwnership); err != nil {
+		return ctrl.Result{}, errors.Wrapf(err, "failed to apply infrastructure metadata to Node %s", node.Name)
+	}
+
+	log.V(3).Info("Applied infrastructure metadata to Machine's Node", "nodename", node.Name, "labels", labels, "annotations", annotations)
+	return ctrl.Result{}, nil
+}
+
+// nodeMetadataFromInfraMachine returns the Node labels and annotations for the fields reported in the InfraMachine status.
+func nodeMetadataFromInfraMachine(infra *unstructured.Unstructured) (map[string]string, map[string]string) {
+	fromStatus := func(fields map[string]string) map[string]string {
+		ret := map[string]string{}
+		for field, name := range fields {
+			if value, ok, err := unstructured.NestedString(infra.Object, "status", field); err == nil && ok && value != "" {
+				ret[name] = value
+			}
+		}
+		return ret
+	}
+	return fromStatus(infraMachineNodeLabels), fromStatus(infraMachineNodeAnnotations)
+}
+
+// nodeMetadataNeedsSync returns true if the Node does not have the desired labels and annotations, or if the labels and
+// annotations applied by nodeMetadataFieldManager are not the desired ones, e.g. because a field is no longer reported
+// by the InfraMachine or a key is no longer propagated from the Cluster.
+// NOTE: Labels and annotations owned by other field managers are never removed by applying the metadata, so they are ignored.
+func nodeMetadataNeedsSync(node *corev1.Node, labels, annotations map[string]string) bool {
+	appliedLabels, appliedAnnotations := nodeMetadataApplied(node)
+	needsSync := func(current, desired map[string]string, applied sets.String) bool {
+		if !applied.Equal(sets.StringKeySet(desired)) {
+			return true
+		}
+		for name, desiredValue := range desired {
+			if currentValue, ok := current[name]; !ok || currentValue != desiredValue {
+				return true
+			}
+		}
+		return false
+	}
+	return needsSync(node.Labels, labels, appliedLabels) || needsSync(node.Annotations, annotations, appliedAnnotations)
+}
+
+// nodeMetadataApplied returns the keys of the Node labels and annotations applied by nodeMetadataFieldManager,
+// as recorded in the Node managed fields.
+func nodeMetadataApplied(node *corev1.Node) (sets.String, sets.String) {
+	labels, annotations := sets.NewString(), sets.NewString()
+	for _, entry := range node.ManagedFields {
+		if entry.Manager != nodeMetadataFieldManager || entry.Operation != metav1.ManagedFieldsOperationApply || entry.FieldsV1 == nil {
+			continue
+		}
+		fields := struct {
+			Metadata struct {
+				Labels      map[string]interface{} `json:"f:labels"`
+				Annotations map[string]interface{} `json:"f:annotations"`
+			} `json:"f:metadata"`
+		}{}
+		if err := json.Unmarshal(entry.FieldsV1.Raw, &fields); err != nil {
+			continue
+		}
+		for key := range fields.Metadata.Labels {
+			if strings.HasPrefix(key, "f:") {
+				labels.Insert(strings.TrimPrefix(key, "f:"))
+			}
+		}
+		for key := range fields.Metadata.Annotations {
+			if strings.HasPrefix(key, "f:") {
+				annotations.Insert(strings.TrimPrefix(key, "f:"))
+			}
+		}
+	}
+	return labels, annotations
+}
@@ -0,0 +1,259 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/controllers/remote"
+	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestReconcileNodeMetadata(t *testing.T) {
+	g := NewWithT(t)
+
+	ns, err := env.CreateNamespace(ctx, "test-node-metadata")
+	g.Expect(err).ToNot(HaveOccurred())
+
+	infraMachine := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"kind":       "InfrastructureMachine",
+			"apiVersion": "infrastructure.cluster.x-k8s.io/v1alpha4",
+			"metadata": map[string]interface{}{
+				"name":      "infra-config1",
+				"namespace": ns.Name,
+			},
+		},
+	}
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "cluster-1",
+			Namespace: ns.Name,
+		},
+	}
+
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "node-metadata-1",
+			Labels: map[string]string{"foo": "bar"},
+		},
+	}
+
+	machine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "machine-test",
+			Namespace: ns.Name,
+		},
+		Spec: clusterv1.MachineSpec{
+			ClusterName: cluster.Name,
+			InfrastructureRef: corev1.ObjectReference{
+				APIVersion: "infrastructure.cluster.x-k8s.io/v1alpha4",
+				Kind:       "InfrastructureMachine",
+				Name:       "infra-config1",
+				Namespace:  ns.Name,
+			},
+			Bootstrap: clusterv1.Bootstrap{
+				ConfigRef: &corev1.ObjectReference{
+					APIVersion: "bootstrap.cluster.x-k8s.io/v1alpha4",
+					Kind:       "BootstrapMachine",
+					Name:       "bootstrap-config1",
+				},
+			},
+		},
+		Status: clusterv1.MachineStatus{
+			NodeRef: &corev1.ObjectReference{
+				Name: "node-metadata-1",
+			},
+		},
+	}
+
+	g.Expect(env.Create(ctx, cluster)).To(Succeed())
+	g.Expect(env.Create(ctx, node)).To(Succeed())
+	g.Expect(env.Create(ctx, infraMachine)).To(Succeed())
+	g.Expect(env.Create(ctx, machine)).To(Succeed())
+
+	defer func(do ...client.Object) {
+		g.Expect(env.Cleanup(ctx, do...)).To(Succeed())
+	}(cluster, node, infraMachine, machine)
+
+	r := &MachineReconciler{
+		Client:   env.Client,
+		Tracker:  remote.NewTestClusterCacheTracker(log.NullLogger{}, env.Client, scheme.Scheme, client.ObjectKey{Name: cluster.Name, Namespace: cluster.Namespace}),
+		recorder: record.NewFakeRecorder(32),
+	}
+
+	setInfraMachineStatus := func(status map[string]interface{}) {
+		g.Eventually(func() error {
+			return env.Get(ctx, client.ObjectKey{Namespace: ns.Name, Name: infraMachine.GetName()}, infraMachine)
+		}, 10*time.Second).Should(Succeed())
+		patchHelper, err := patch.NewHelper(infraMachine, env)
+		g.Expect(err).ShouldNot(HaveOccurred())
+		g.Expect(unstructured.SetNestedField(infraMachine.Object, status, "status")).To(Succeed())
+		g.Expect(patchHelper.Patch(ctx, infraMachine)).To(Succeed())
+
+		// Wait for the cache to be updated before reconciling.
+		g.Eventually(func() (map[string]interface{}, error) {
+			got := infraMachine.DeepCopy()
+			if err := env.Get(ctx, client.ObjectKey{Namespace: ns.Name, Name: infraMachine.GetName()}, got); err != nil {
+				return nil, err
+			}
+			s, _, err := unstructured.NestedMap(got.Object, "status")
+			return s, err
+		}, 10*time.Second).Should(Equal(status))
+	}
+
+	getNode := func() *corev1.Node {
+		updatedNode := &corev1.Node{}
+		g.Expect(env.Get(ctx, client.ObjectKey{Name: node.Name}, updatedNode)).To(Succeed())
+		return updatedNode
+	}
+
+	// Sets the labels and annotations reported by the InfraMachine, preserving the other labels.
+	setInfraMachineStatus(map[string]interface{}{
+		"instanceType": "m5.large",
+		"zone":         "us-east-1a",
+		"image":        "registry.example.com/images/ubuntu:20.04",
+	})
+
+	_, err = r.reconcileNodeMetadata(ctx, cluster, machine)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	g.Eventually(func() map[string]string {
+		return getNode().Labels
+	}, 10*time.Second).Should(And(
+		HaveKeyWithValue(clusterv1.NodeInstanceTypeLabel, "m5.large"),
+		HaveKeyWithValue(clusterv1.NodeZoneLabel, "us-east-1a"),
+		HaveKeyWithValue("foo", "bar"),
+	))
+	g.Expect(getNode().Annotations).To(HaveKeyWithValue(clusterv1.NodeImageAnnotation, "registry.example.com/images/ubuntu:20.04"))
+
+	// Removes the labels and annotations for the fields no longer reported by the InfraMachine.
+	setInfraMachineStatus(map[string]interface{}{
+		"instanceType": "m5.xlarge",
+	})
+
+	_, err = r.reconcileNodeMetadata(ctx, cluster, machine)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	g.Eventually(func() map[string]string {
+		return getNode().Labels
+	}, 10*time.Second).Should(And(
+		HaveKeyWithValue(clusterv1.NodeInstanceTypeLabel, "m5.xlarge"),
+		Not(HaveKey(clusterv1.NodeZoneLabel)),
+		HaveKeyWithValue("foo", "bar"),
+	))
+	g.Expect(getNode().Annotations).ToNot(HaveKey(clusterv1.NodeImageAnnotation))
+}
+
+func TestNodeMetadataNeedsSync(t *testing.T) {
+	// applied returns the managed fields of the labels and annotations applied by the node metadata field manager.
+	applied := func(fieldsV1 string) []metav1.ManagedFieldsEntry {
+		return []metav1.ManagedFieldsEntry{{
+			Manager:    nodeMetadataFieldManager,
+			Operation:  metav1.ManagedFieldsOperationApply,
+			FieldsType: "FieldsV1",
+			FieldsV1:   &metav1.FieldsV1{Raw: []byte(fieldsV1)},
+		}}
+	}
+
+	tests := []struct {
+		name            string
+		nodeLabels      map[string]string
+		nodeAnnotations map[string]string
+		managedFields   []metav1.ManagedFieldsEntry
+		labels          map[string]string
+		annotations     map[string]string
+		want            bool
+	}{
+		{
+			name:       "does not sync if the infrastructure provider does not report any metadata",
+			nodeLabels: map[string]string{"foo": "bar"},
+			want:       false,
+		},
+		{
+			name:            "does not sync if the node is up to date",
+			nodeLabels:      map[string]string{"foo": "bar", clusterv1.NodeInstanceTypeLabel: "m5.large"},
+			nodeAnnotations: map[string]string{clusterv1.NodeImageAnnotation: "image"},
+			managedFields:   applied(`{"f:metadata":{"f:labels":{"f:node.cluster.x-k8s.io/instance-type":{}},"f:annotations":{"f:node.cluster.x-k8s.io/image":{}}}}`),
+			labels:          map[string]string{clusterv1.NodeInstanceTypeLabel: "m5.large"},
+			annotations:     map[string]string{clusterv1.NodeImageAnnotation: "image"},
+			want:            false,
+		},
+		{
+			name:   "syncs a missing label",
+			labels: map[string]string{clusterv1.NodeInstanceTypeLabel: "m5.large"},
+			want:   true,
+		},
+		{
+			name:          "syncs a changed label",
+			nodeLabels:    map[string]string{clusterv1.NodeInstanceTypeLabel: "m5.large"},
+			managedFields: applied(`{"f:metadata":{"f:labels":{"f:node.cluster.x-k8s.io/instance-type":{}}}}`),
+			labels:        map[string]string{clusterv1.NodeInstanceTypeLabel: "m5.xlarge"},
+			want:          true,
+		},
+		{
+			name:       "syncs a label set by another field manager",
+			nodeLabels: map[string]string{"team": "foo"},
+			labels:     map[string]string{"team": "foo"},
+			want:       true,
+		},
+		{
+			name:            "syncs an annotation no longer reported",
+			nodeAnnotations: map[string]string{clusterv1.NodeImageAnnotation: "image"},
+			managedFields:   applied(`{"f:metadata":{"f:annotations":{"f:node.cluster.x-k8s.io/image":{}}}}`),
+			want:            true,
+		},
+		{
+			name:          "syncs a label no longer propagated",
+			nodeLabels:    map[string]string{"team": "foo"},
+			managedFields: applied(`{"f:metadata":{"f:labels":{"f:team":{}}}}`),
+			want:          true,
+		},
+		{
+			name:       "does not sync a label set by another field manager and no longer desired",
+			nodeLabels: map[string]string{"team": "foo"},
+			want:       false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			node := &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:        tt.nodeLabels,
+					Annotations:   tt.nodeAnnotations,
+					ManagedFields: tt.managedFields,
+				},
+			}
+			g.Expect(nodeMetadataNeedsSync(node, tt.labels, tt.annotations)).To(Equal(tt.want))
+		})
+	}
+}
@@ -89,7 +89,7 @@ func (r *MachineReconciler) reconcileNode(ctx context.Context, cluster *clusterv
 		r.recorder.Event(machine, corev1.EventTypeNormal, "SuccessfulSetNodeRef", machine.Status.NodeRef.Name)
 	}
 
-	// Reconcile node annotations.
+	// Reconcile node annotations and well-known labels.
 	patchHelper, err := patch.NewHelper(node, remoteClient)
 	if err != nil {
 		return ctrl.Result{}, err
@@ -103,9 +103,11 @@ func (r *MachineReconciler) reconcileNode(ctx context.Context, cluster *clusterv
 		desired[clusterv1.OwnerKindAnnotation] = owner.Kind
 		desired[clusterv1.OwnerNameAnnotation] = owner.Name
 	}
-	if annotations.AddAnnotations(node, desired) {
+	annotationsChanged := annotations.AddAnnotations(node, desired)
+	labelsChanged := syncNodeLabels(node, machine)
+	if annotationsChanged || labelsChanged {
 		if err := patchHelper.Patch(ctx, node); err != nil {
-			log.V(2).Info("Failed patch node to set annotations", "err", err, "node name", node.Name)
+			log.V(2).Info("Failed patch node to set annotations and labels", "err", err, "node name", node.Name)
 			return ctrl.Result{}, err
 		}
 	}
@@ -35,14 +35,17 @@ import (
 	"sigs.k8s.io/cluster-api/util/annotations"
 	"sigs.k8s.io/cluster-api/util/conditions"
 	utilconversion "sigs.k8s.io/cluster-api/util/conversion"
-	"sigs.k8s.io/cluster-api/util/patch"
 	ctrl "sigs.k8s.io/controller-runtime"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 )
 
 var (
 	externalReadyWait = 30 * time.Second
+
+	// externalRefMaxBackoff is the maximum time to wait before retrying to reconcile an external object
+	// referenced by a Machine that can't be found. It is kept short because the object is not watched until it
+	// is found, e.g. while its provider is being installed, so the Machine picks it up only on the next retry.
+	externalRefMaxBackoff = 2 * time.Minute
 )
 
 func (r *MachineReconciler) reconcilePhase(_ context.Context, m *clusterv1.Machine) {
@@ -53,18 +56,21 @@ func (r *MachineReconciler) reconcilePhase(_ context.Context, m *clusterv1.Machi
 		m.Status.SetTypedPhase(clusterv1.MachinePhasePending)
 	}
 
+	// Auxiliary infrastructure, if any, must be ready before the machine is considered provisioned.
+	auxiliaryInfrastructureReady := !conditions.IsFalse(m, clusterv1.AuxiliaryInfrastructureReadyCondition)
+
 	// Set the phase to "provisioning" if bootstrap is ready and the infrastructure isn't.
-	if m.Status.BootstrapReady && !m.Status.InfrastructureReady {
+	if m.Status.BootstrapReady && (!m.Status.InfrastructureReady || (!auxiliaryInfrastructureReady && m.Status.NodeRef == nil)) {
 		m.Status.SetTypedPhase(clusterv1.MachinePhaseProvisioning)
 	}
 
 	// Set the phase to "provisioned" if there is a provider ID.
-	if m.Spec.ProviderID != nil {
+	if m.Spec.ProviderID != nil && auxiliaryInfrastructureReady {
 		m.Status.SetTypedPhase(clusterv1.MachinePhaseProvisioned)
 	}
 
 	// Set the phase to "running" if there is a NodeRef field and infrastructure is ready.
-	if m.Status.NodeRef != nil && m.Status.InfrastructureReady {
+	if m.Status.NodeRef != nil && m.Status.InfrastructureReady && auxiliaryInfrastructureReady {
 		m.Status.SetTypedPhase(clusterv1.MachinePhaseRunning)
 	}
 
@@ -78,10 +84,26 @@ func (r *MachineReconciler) reconcilePhase(_ context.Context, m *clusterv1.Machi
 		m.Status.SetTypedPhase(clusterv1.MachinePhaseDeleting)
 	}
 
-	// If the phase has changed, update the LastUpdated timestamp
+	// If the phase has changed, update the LastUpdated timestamp and record the phase transition as the last operation.
 	if m.Status.Phase != originalPhase {
 		now := metav1.Now()
 		m.Status.LastUpdated = &now
+
+		lastOperation := &clusterv1.MachineOperation{
+			Type:      m.Status.Phase,
+			Timestamp: now,
+			Result:    clusterv1.MachineOperationSucceeded,
+		}
+		if m.Status.GetTypedPhase() == clusterv1.MachinePhaseFailed {
+			lastOperation.Result = clusterv1.MachineOperationFailed
+			// The failure is copied, so later changes to the Machine status do not alter the recorded operation.
+			if m.Status.FailureMessage != nil {
+				lastOperation.Error = pointer.StringPtr(*m.Status.FailureMessage)
+			} else if m.Status.FailureReason != nil {
+				lastOperation.Error = pointer.StringPtr(string(*m.Status.FailureReason))
+			}
+		}
+		m.Status.LastOperation = lastOperation
 	}
 }
 
@@ -96,11 +118,13 @@ func (r *MachineReconciler) reconcileExternal(ctx context.Context, cluster *clus
 	obj, err := external.Get(ctx, r.Client, ref, m.Namespace)
 	if err != nil {
 		if apierrors.IsNotFound(errors.Cause(err)) {
-			log.Info("could not find external ref, requeueing", "RefGVK", ref.GroupVersionKind(), "RefName", ref.Name, "Machine", m.Name, "Namespace", m.Namespace)
-			return external.ReconcileOutput{RequeueAfter: externalReadyWait}, nil
+			requeueAfter := r.nextExternalRefBackoff(m, ref)
+			log.Info("could not find external ref, requeueing", "RefGVK", ref.GroupVersionKind(), "RefName", ref.Name, "Machine", m.Name, "Namespace", m.Namespace, "RequeueAfter", requeueAfter)
+			return external.ReconcileOutput{RequeueAfter: requeueAfter}, nil
 		}
 		return external.ReconcileOutput{}, err
 	}
+	r.resetExternalRefBackoff(m, ref)
 
 	// if external ref is paused, return error.
 	if annotations.IsPaused(cluster, obj) {
@@ -108,40 +132,28 @@ func (r *MachineReconciler) reconcileExternal(ctx context.Context, cluster *clus
 		return external.ReconcileOutput{Paused: true}, nil
 	}
 
-	// Initialize the patch helper.
-	patchHelper, err := patch.NewHelper(obj, r.Client)
-	if err != nil {
-		return external.ReconcileOutput{}, err
-	}
-
-	// With the migration from v1alpha2 to v1alpha3, Machine controllers should be the owner for the
-	// infra Machines, hence remove any existing machineset controller owner reference
-	if controller := metav1.GetControllerOf(obj); controller != nil && controller.Kind == "MachineSet" {
-		gv, err := schema.ParseGroupVersion(controller.APIVersion)
-		if err != nil {
-			return external.ReconcileOutput{}, err
-		}
-		if gv.Group == clusterv1.GroupVersion.Group {
-			ownerRefs := util.RemoveOwnerRef(obj.GetOwnerReferences(), *controller)
-			obj.SetOwnerReferences(ownerRefs)
-		}
-	}
-
-	// Set external object ControllerReference to the Machine.
-	if err := controllerutil.SetControllerReference(m, obj, r.Client.Scheme()); err != nil {
-		return external.ReconcileOutput{}, err
-	}
-
-	// Set the Cluster label.
-	labels := obj.GetLabels()
-	if labels == nil {
-		labels = make(map[string]string)
-	}
-	labels[clusterv1.ClusterLabelName] = m.Spec.ClusterName
-	obj.SetLabels(labels)
-
-	// Always attempt to Patch the external object.
-	if err := patchHelper.Patch(ctx, obj); err != nil {
+	// Set the external object ControllerReference and Cluster label, and always attempt to Patch it.
+	if err := external.PatchExternal(ctx, &external.PatchExternalInput{
+		Client:      r.Client,
+		Object:      obj,
+		Owner:       m,
+		ClusterName: m.Spec.ClusterName,
+		Mutate: func(obj *unstructured.Unstructured) error {
+			// With the migration from v1alpha2 to v1alpha3, Machine controllers should be the owner for the
+			// infra Machines, hence remove any existing machineset controller owner reference
+			if controller := metav1.GetControllerOf(obj); controller != nil && controller.Kind == "MachineSet" {
+				gv, err := schema.ParseGroupVersion(controller.APIVersion)
+				if err != nil {
+					return err
+				}
+				if gv.Group == clusterv1.GroupVersion.Group {
+					ownerRefs := util.RemoveOwnerRef(obj.GetOwnerReferences(), *controller)
+					obj.SetOwnerReferences(ownerRefs)
+				}
+			}
+			return nil
+		},
+	}); err != nil {
 		return external.ReconcileOutput{}, err
 	}
 
@@ -155,6 +167,25 @@ func (r *MachineReconciler) reconcileExternal(ctx context.Context, cluster *clus
 	if err != nil {
 		return external.ReconcileOutput{}, err
 	}
+	if failureReason != "" || failureMessage != "" {
+		retryable, err := external.IsFailureRetryable(obj)
+		if err != nil {
+			return external.ReconcileOutput{}, err
+		}
+		// Retryable failures are not propagated to the Machine status, because Machines with a failure are
+		// considered permanently failed and replaced, while the provider is still retrying.
+		if retryable {
+			id, failure := externalRefBackoffID(m, ref), fmt.Sprintf("%s %s", failureReason, failureMessage)
+			if previous, ok := r.retryableFailures.Load(id); !ok || previous != failure {
+				r.retryableFailures.Store(id, failure)
+				log.Info("Retryable failure detected from referenced resource", "RefGVK", ref.GroupVersionKind(), "RefName", ref.Name, "Reason", failureReason, "Message", failureMessage)
+				r.recorder.Eventf(m, corev1.EventTypeWarning, "RetryableFailure", "Retryable failure detected from referenced resource %v with name %q: %s",
+					obj.GroupVersionKind(), obj.GetName(), failure)
+			}
+			return external.ReconcileOutput{Result: obj}, nil
+		}
+	}
+	r.retryableFailures.Delete(externalRefBackoffID(m, ref))
 	if failureReason != "" {
 		machineStatusError := capierrors.MachineStatusError(failureReason)
 		m.Status.FailureReason = &machineStatusError
@@ -169,6 +200,36 @@ func (r *MachineReconciler) reconcileExternal(ctx context.Context, cluster *clus
 	return external.ReconcileOutput{Result: obj}, nil
 }
 
+// externalRefBackoffID returns the ID used for tracking the backoff of an external object referenced by a Machine.
+func externalRefBackoffID(m *clusterv1.Machine, ref *corev1.ObjectReference) string {
+	return fmt.Sprintf("%s/%s/%s/%s", m.Namespace, m.Name, ref.GroupVersionKind().GroupKind(), ref.Name)
+}
+
+// nextExternalRefBackoff returns the time to wait before retrying to reconcile an external object referenced by a Machine
+// that can't be found; the wait time starts from externalReadyWait and doubles at every consecutive failure for the same
+// reference, up to externalRefMaxBackoff, so permanently broken references do not cause unnecessary API churn.
+func (r *MachineReconciler) nextExternalRefBackoff(m *clusterv1.Machine, ref *corev1.ObjectReference) time.Duration {
+	if r.externalRefBackoff == nil {
+		return externalReadyWait
+	}
+
+	id := externalRefBackoffID(m, ref)
+	r.externalRefBackoff.Next(id, time.Now())
+
+	// Drop the backoff entries not updated recently, e.g. for deleted Machines.
+	r.externalRefBackoff.GC()
+
+	return r.externalRefBackoff.Get(id)
+}
+
+// resetExternalRefBackoff resets the backoff of an external object referenced by a Machine after it has been found.
+func (r *MachineReconciler) resetExternalRefBackoff(m *clusterv1.Machine, ref *corev1.ObjectReference) {
+	if r.externalRefBackoff == nil {
+		return
+	}
+	r.externalRefBackoff.Reset(externalRefBackoffID(m, ref))
+}
+
 // reconcileBootstrap reconciles the Spec.Bootstrap.ConfigRef object on a Machine.
 func (r *MachineReconciler) reconcileBootstrap(ctx context.Context, cluster *clusterv1.Cluster, m *clusterv1.Machine) (ctrl.Result, error) {
 	log := ctrl.LoggerFrom(ctx, "cluster", cluster.Name)
@@ -185,6 +246,14 @@ func (r *MachineReconciler) reconcileBootstrap(ctx context.Context, cluster *clu
 		return ctrl.Result{}, nil
 	}
 
+	// The bootstrap config is not adopted, and so the bootstrap provider does not generate the bootstrap data,
+	// until the auxiliary infrastructure is ready; the auxiliary infrastructure reconciler takes care of requeuing.
+	if !m.Status.BootstrapReady && !isAuxiliaryInfrastructureReady(m) {
+		log.Info("Waiting for auxiliary infrastructure before reconciling the bootstrap config")
+		conditions.MarkFalse(m, clusterv1.BootstrapReadyCondition, clusterv1.WaitingForAuxiliaryInfrastructureReason, clusterv1.ConditionSeverityInfo, "")
+		return ctrl.Result{}, nil
+	}
+
 	// Call generic external reconciler if we have an external reference.
 	externalResult, err := r.reconcileExternal(ctx, cluster, m, m.Spec.Bootstrap.ConfigRef)
 	if err != nil {
@@ -222,7 +291,7 @@ func (r *MachineReconciler) reconcileBootstrap(ctx context.Context, cluster *clu
 	}
 
 	// Get and set the name of the secret containing the bootstrap data.
-	secretName, _, err := unstructured.NestedString(bootstrapConfig.Object, "status", "dataSecretName")
+	secretName, err := external.DataSecretNameFrom(bootstrapConfig)
 	if err != nil {
 		return ctrl.Result{}, errors.Wrapf(err, "failed to retrieve dataSecretName from bootstrap provider for Machine %q in namespace %q", m.Name, m.Namespace)
 	} else if secretName == "" {
@@ -238,6 +307,14 @@ func (r *MachineReconciler) reconcileBootstrap(ctx context.Context, cluster *clu
 func (r *MachineReconciler) reconcileInfrastructure(ctx context.Context, cluster *clusterv1.Cluster, m *clusterv1.Machine) (ctrl.Result, error) {
 	log := ctrl.LoggerFrom(ctx, "cluster", cluster.Name)
 
+	// The infrastructure machine is not adopted, and so the infrastructure provider does not provision it,
+	// until the auxiliary infrastructure is ready; the auxiliary infrastructure reconciler takes care of requeuing.
+	if !m.Status.InfrastructureReady && !isAuxiliaryInfrastructureReady(m) {
+		log.Info("Waiting for auxiliary infrastructure before reconciling the infrastructure machine")
+		conditions.MarkFalse(m, clusterv1.InfrastructureReadyCondition, clusterv1.WaitingForAuxiliaryInfrastructureReason, clusterv1.ConditionSeverityInfo, "")
+		return ctrl.Result{}, nil
+	}
+
 	// Call generic external reconciler.
 	infraReconcileResult, err := r.reconcileExternal(ctx, cluster, m, &m.Spec.InfrastructureRef)
 	if err != nil {
@@ -311,3 +388,31 @@ func (r *MachineReconciler) reconcileInfrastructure(ctx context.Context, cluster
 	m.Spec.ProviderID = pointer.StringPtr(providerID)
 	return ctrl.Result{}, nil
 }
+
+// reconcileProvisioningTimeout sets a terminal failure on a Machine if bootstrap and infrastructure
+// did not become ready within Spec.ProvisioningTimeout, so the Machine transitions to the Failed phase.
+func (r *MachineReconciler) reconcileProvisioningTimeout(ctx context.Context, cluster *clusterv1.Cluster, m *clusterv1.Machine) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx, "cluster", cluster.Name)
+
+	if m.Spec.ProvisioningTimeout == nil || m.Spec.ProvisioningTimeout.Duration <= 0 {
+		return ctrl.Result{}, nil
+	}
+
+	// If provisioning is completed or the machine already failed, there is nothing to do.
+	if (m.Status.BootstrapReady && m.Status.InfrastructureReady && !conditions.IsFalse(m, clusterv1.AuxiliaryInfrastructureReadyCondition)) || m.Status.FailureReason != nil || m.Status.FailureMessage != nil {
+		return ctrl.Result{}, nil
+	}
+
+	// If the timeout is not yet expired, requeue when it is going to expire.
+	remaining := m.Spec.ProvisioningTimeout.Duration - time.Since(m.CreationTimestamp.Time)
+	if remaining > 0 {
+		return ctrl.Result{RequeueAfter: remaining}, nil
+	}
+
+	log.Info("Machine did not complete provisioning within the provisioning timeout, setting failure state",
+		"timeout", m.Spec.ProvisioningTimeout.Duration.String(), "bootstrapReady", m.Status.BootstrapReady, "infrastructureReady", m.Status.InfrastructureReady)
+	m.Status.FailureReason = capierrors.MachineStatusErrorPtr(capierrors.ProvisioningTimeoutMachineError)
+	m.Status.FailureMessage = pointer.StringPtr(fmt.Sprintf("Machine did not complete provisioning within %s (bootstrap ready: %t, infrastructure ready: %t)",
+		m.Spec.ProvisioningTimeout.Duration, m.Status.BootstrapReady, m.Status.InfrastructureReady))
+	return ctrl.Result{}, nil
+}
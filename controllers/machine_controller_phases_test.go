@@ -29,10 +29,12 @@ import (
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/flowcontrol"
 	"k8s.io/utils/pointer"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 	"sigs.k8s.io/cluster-api/controllers/external"
 	"sigs.k8s.io/cluster-api/controllers/remote"
+	capierrors "sigs.k8s.io/cluster-api/errors"
 	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/kubeconfig"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -175,6 +177,12 @@ func TestReconcileMachinePhases(t *testing.T) {
 
 		// LastUpdated should be set as the phase changes
 		g.Expect(machine.Status.LastUpdated).NotTo(BeNil())
+
+		// LastOperation should record the phase transition
+		g.Expect(machine.Status.LastOperation).NotTo(BeNil())
+		g.Expect(machine.Status.LastOperation.Type).To(Equal(string(clusterv1.MachinePhasePending)))
+		g.Expect(machine.Status.LastOperation.Result).To(Equal(clusterv1.MachineOperationSucceeded))
+		g.Expect(machine.Status.LastOperation.Error).To(BeNil())
 	})
 
 	t.Run("Should set `Provisioning` when bootstrap is ready", func(t *testing.T) {
@@ -502,6 +510,29 @@ func TestReconcileMachinePhases(t *testing.T) {
 		g.Expect(machine.Status.LastUpdated.After(lastUpdated.Time)).To(BeTrue())
 	})
 
+	t.Run("Should set `Failed` and record the failure as last operation when the Machine fails", func(t *testing.T) {
+		g := NewWithT(t)
+
+		machine := defaultMachine.DeepCopy()
+		machine.Status.SetTypedPhase(clusterv1.MachinePhaseProvisioning)
+		machine.Status.FailureReason = capierrors.MachineStatusErrorPtr(capierrors.CreateMachineError)
+		machine.Status.FailureMessage = pointer.StringPtr("failed to create the machine")
+
+		r := &MachineReconciler{}
+		r.reconcilePhase(ctx, machine)
+		g.Expect(machine.Status.GetTypedPhase()).To(Equal(clusterv1.MachinePhaseFailed))
+
+		// LastOperation should record the failure
+		g.Expect(machine.Status.LastOperation).NotTo(BeNil())
+		g.Expect(machine.Status.LastOperation.Type).To(Equal(string(clusterv1.MachinePhaseFailed)))
+		g.Expect(machine.Status.LastOperation.Result).To(Equal(clusterv1.MachineOperationFailed))
+		g.Expect(machine.Status.LastOperation.Error).To(Equal(pointer.StringPtr("failed to create the machine")))
+
+		// Changes to the failure message do not alter the recorded operation.
+		*machine.Status.FailureMessage = "another failure"
+		g.Expect(machine.Status.LastOperation.Error).To(Equal(pointer.StringPtr("failed to create the machine")))
+	})
+
 	t.Run("Should set `Deleting` when Machine is being deleted", func(t *testing.T) {
 		g := NewWithT(t)
 
@@ -889,6 +920,158 @@ func TestReconcileBootstrap(t *testing.T) {
 	}
 }
 
+func TestReconcileExternalBackoff(t *testing.T) {
+	g := NewWithT(t)
+
+	machine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "machine-test",
+			Namespace: "default",
+			Labels: map[string]string{
+				clusterv1.ClusterLabelName: "test-cluster",
+			},
+		},
+		Spec: clusterv1.MachineSpec{
+			Bootstrap: clusterv1.Bootstrap{
+				ConfigRef: &corev1.ObjectReference{
+					APIVersion: "bootstrap.cluster.x-k8s.io/v1alpha4",
+					Kind:       "BootstrapMachine",
+					Name:       "bootstrap-config1",
+				},
+			},
+		},
+	}
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster",
+			Namespace: "default",
+		},
+	}
+
+	c := fake.NewClientBuilder().
+		WithObjects(machine,
+			external.TestGenericBootstrapCRD.DeepCopy(),
+			external.TestGenericInfrastructureCRD.DeepCopy(),
+		).Build()
+	r := &MachineReconciler{
+		Client:             c,
+		externalRefBackoff: flowcontrol.NewBackOff(externalReadyWait, 4*externalReadyWait),
+	}
+
+	// The wait time doubles at every consecutive failure, up to the cap.
+	for _, want := range []time.Duration{externalReadyWait, 2 * externalReadyWait, 4 * externalReadyWait, 4 * externalReadyWait} {
+		res, err := r.reconcileExternal(ctx, cluster, machine, machine.Spec.Bootstrap.ConfigRef)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(res.RequeueAfter).To(Equal(want))
+	}
+
+	// The backoff is reset once the external object is found.
+	bootstrapConfig := &unstructured.Unstructured{Object: map[string]interface{}{
+		"kind":       "BootstrapMachine",
+		"apiVersion": "bootstrap.cluster.x-k8s.io/v1alpha4",
+		"metadata": map[string]interface{}{
+			"name":      "bootstrap-config1",
+			"namespace": "default",
+		},
+	}}
+	g.Expect(c.Create(ctx, bootstrapConfig)).To(Succeed())
+
+	res, err := r.reconcileExternal(ctx, cluster, machine, machine.Spec.Bootstrap.ConfigRef)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(res.RequeueAfter).To(Equal(time.Duration(0)))
+	g.Expect(r.externalRefBackoff.Get(externalRefBackoffID(machine, machine.Spec.Bootstrap.ConfigRef))).To(Equal(time.Duration(0)))
+}
+
+func TestReconcileExternalRetryableFailureEvents(t *testing.T) {
+	g := NewWithT(t)
+
+	machine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "machine-test",
+			Namespace: "default",
+			Labels: map[string]string{
+				clusterv1.ClusterLabelName: "test-cluster",
+			},
+		},
+		Spec: clusterv1.MachineSpec{
+			InfrastructureRef: corev1.ObjectReference{
+				APIVersion: "infrastructure.cluster.x-k8s.io/v1alpha4",
+				Kind:       "InfrastructureMachine",
+				Name:       "infra-config1",
+			},
+		},
+	}
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster",
+			Namespace: "default",
+		},
+	}
+	infraConfig := &unstructured.Unstructured{Object: map[string]interface{}{
+		"kind":       "InfrastructureMachine",
+		"apiVersion": "infrastructure.cluster.x-k8s.io/v1alpha4",
+		"metadata": map[string]interface{}{
+			"name":      "infra-config1",
+			"namespace": "default",
+		},
+		"status": map[string]interface{}{
+			"failureReason":    "CreateError",
+			"failureMessage":   "API rate limit exceeded",
+			"failureRetryable": true,
+		},
+	}}
+
+	c := fake.NewClientBuilder().
+		WithObjects(machine,
+			external.TestGenericInfrastructureCRD.DeepCopy(),
+			infraConfig,
+		).Build()
+	recorder := record.NewFakeRecorder(32)
+	r := &MachineReconciler{
+		Client:   c,
+		recorder: recorder,
+	}
+
+	// The event is emitted only once while the failure does not change.
+	for i := 0; i < 3; i++ {
+		_, err := r.reconcileExternal(ctx, cluster, machine, &machine.Spec.InfrastructureRef)
+		g.Expect(err).NotTo(HaveOccurred())
+	}
+	g.Expect(recorder.Events).To(HaveLen(1))
+	<-recorder.Events
+
+	// A different failure is reported again.
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(infraConfig), infraConfig)).To(Succeed())
+	g.Expect(unstructured.SetNestedField(infraConfig.Object, "quota exceeded", "status", "failureMessage")).To(Succeed())
+	g.Expect(c.Update(ctx, infraConfig)).To(Succeed())
+
+	_, err := r.reconcileExternal(ctx, cluster, machine, &machine.Spec.InfrastructureRef)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(recorder.Events).To(HaveLen(1))
+	<-recorder.Events
+
+	// The same failure is reported again after it has been resolved.
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(infraConfig), infraConfig)).To(Succeed())
+	unstructured.RemoveNestedField(infraConfig.Object, "status")
+	g.Expect(c.Update(ctx, infraConfig)).To(Succeed())
+
+	_, err = r.reconcileExternal(ctx, cluster, machine, &machine.Spec.InfrastructureRef)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(recorder.Events).To(BeEmpty())
+
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(infraConfig), infraConfig)).To(Succeed())
+	g.Expect(unstructured.SetNestedField(infraConfig.Object, map[string]interface{}{
+		"failureReason":    "CreateError",
+		"failureMessage":   "quota exceeded",
+		"failureRetryable": true,
+	}, "status")).To(Succeed())
+	g.Expect(c.Update(ctx, infraConfig)).To(Succeed())
+
+	_, err = r.reconcileExternal(ctx, cluster, machine, &machine.Spec.InfrastructureRef)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(recorder.Events).To(HaveLen(1))
+}
+
 func TestReconcileInfrastructure(t *testing.T) {
 	defaultMachine := clusterv1.Machine{
 		ObjectMeta: metav1.ObjectMeta{
@@ -1028,6 +1211,55 @@ func TestReconcileInfrastructure(t *testing.T) {
 				g.Expect(m.Status.GetTypedPhase()).To(Equal(clusterv1.MachinePhaseFailed))
 			},
 		},
+		{
+			name: "infrastructure config with a terminal failure, expect failed",
+			infraConfig: map[string]interface{}{
+				"kind":       "InfrastructureMachine",
+				"apiVersion": "infrastructure.cluster.x-k8s.io/v1alpha4",
+				"metadata": map[string]interface{}{
+					"name":      "infra-config1",
+					"namespace": "default",
+				},
+				"spec": map[string]interface{}{},
+				"status": map[string]interface{}{
+					"failureReason":  "InvalidConfiguration",
+					"failureMessage": "instance type does not exist",
+				},
+			},
+			expectResult: ctrl.Result{RequeueAfter: externalReadyWait},
+			expectError:  false,
+			expected: func(g *WithT, m *clusterv1.Machine) {
+				g.Expect(m.Status.InfrastructureReady).To(BeFalse())
+				g.Expect(m.Status.FailureReason).NotTo(BeNil())
+				g.Expect(m.Status.FailureMessage).NotTo(BeNil())
+				g.Expect(m.Status.GetTypedPhase()).To(Equal(clusterv1.MachinePhaseFailed))
+			},
+		},
+		{
+			name: "infrastructure config with a retryable failure, expect not failed",
+			infraConfig: map[string]interface{}{
+				"kind":       "InfrastructureMachine",
+				"apiVersion": "infrastructure.cluster.x-k8s.io/v1alpha4",
+				"metadata": map[string]interface{}{
+					"name":      "infra-config1",
+					"namespace": "default",
+				},
+				"spec": map[string]interface{}{},
+				"status": map[string]interface{}{
+					"failureReason":    "CreateError",
+					"failureMessage":   "API rate limit exceeded",
+					"failureRetryable": true,
+				},
+			},
+			expectResult: ctrl.Result{RequeueAfter: externalReadyWait},
+			expectError:  false,
+			expected: func(g *WithT, m *clusterv1.Machine) {
+				g.Expect(m.Status.InfrastructureReady).To(BeFalse())
+				g.Expect(m.Status.FailureReason).To(BeNil())
+				g.Expect(m.Status.FailureMessage).To(BeNil())
+				g.Expect(m.Status.GetTypedPhase()).NotTo(Equal(clusterv1.MachinePhaseFailed))
+			},
+		},
 		{
 			name: "infrastructure ref is paused",
 			infraConfig: map[string]interface{}{
@@ -1082,6 +1314,7 @@ func TestReconcileInfrastructure(t *testing.T) {
 						external.TestGenericInfrastructureCRD.DeepCopy(),
 						infraConfig,
 					).Build(),
+				recorder: record.NewFakeRecorder(32),
 			}
 
 			result, err := r.reconcileInfrastructure(ctx, defaultCluster, tc.machine)
@@ -1099,3 +1332,99 @@ func TestReconcileInfrastructure(t *testing.T) {
 		})
 	}
 }
+
+func TestReconcileProvisioningTimeout(t *testing.T) {
+	defaultCluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster",
+			Namespace: "default",
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		machine       *clusterv1.Machine
+		expectFailure bool
+		expectRequeue bool
+	}{
+		{
+			name: "no timeout set",
+			machine: &clusterv1.Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					CreationTimestamp: metav1.NewTime(time.Now().Add(-time.Hour)),
+				},
+			},
+			expectFailure: false,
+			expectRequeue: false,
+		},
+		{
+			name: "timeout not yet expired",
+			machine: &clusterv1.Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					CreationTimestamp: metav1.Now(),
+				},
+				Spec: clusterv1.MachineSpec{
+					ProvisioningTimeout: &metav1.Duration{Duration: 10 * time.Minute},
+				},
+			},
+			expectFailure: false,
+			expectRequeue: true,
+		},
+		{
+			name: "timeout expired while waiting for infrastructure",
+			machine: &clusterv1.Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					CreationTimestamp: metav1.NewTime(time.Now().Add(-time.Hour)),
+				},
+				Spec: clusterv1.MachineSpec{
+					ProvisioningTimeout: &metav1.Duration{Duration: 10 * time.Minute},
+				},
+				Status: clusterv1.MachineStatus{
+					BootstrapReady: true,
+				},
+			},
+			expectFailure: true,
+			expectRequeue: false,
+		},
+		{
+			name: "timeout expired but provisioning completed",
+			machine: &clusterv1.Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					CreationTimestamp: metav1.NewTime(time.Now().Add(-time.Hour)),
+				},
+				Spec: clusterv1.MachineSpec{
+					ProvisioningTimeout: &metav1.Duration{Duration: 10 * time.Minute},
+				},
+				Status: clusterv1.MachineStatus{
+					BootstrapReady:      true,
+					InfrastructureReady: true,
+				},
+			},
+			expectFailure: false,
+			expectRequeue: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			r := &MachineReconciler{}
+			res, err := r.reconcileProvisioningTimeout(ctx, defaultCluster, tc.machine)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(res.RequeueAfter > 0).To(Equal(tc.expectRequeue))
+
+			if tc.expectFailure {
+				g.Expect(tc.machine.Status.FailureReason).NotTo(BeNil())
+				g.Expect(*tc.machine.Status.FailureReason).To(Equal(capierrors.ProvisioningTimeoutMachineError))
+				g.Expect(tc.machine.Status.FailureMessage).NotTo(BeNil())
+
+				r.reconcilePhase(ctx, tc.machine)
+				g.Expect(tc.machine.Status.GetTypedPhase()).To(Equal(clusterv1.MachinePhaseFailed))
+				return
+			}
+			g.Expect(tc.machine.Status.FailureReason).To(BeNil())
+			g.Expect(tc.machine.Status.FailureMessage).To(BeNil())
+		})
+	}
+}
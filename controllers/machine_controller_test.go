@@ -17,6 +17,7 @@ limitations under the License.
 package controllers
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -26,7 +27,10 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/pointer"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 	"sigs.k8s.io/cluster-api/controllers/external"
@@ -1063,6 +1067,92 @@ func TestReconcileDeleteExternal(t *testing.T) {
 	}
 }
 
+func TestReconcileInfrastructureDeletePolicy(t *testing.T) {
+	infraMachine := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"kind":       "InfrastructureMachine",
+			"apiVersion": "infrastructure.cluster.x-k8s.io/v1alpha4",
+			"metadata": map[string]interface{}{
+				"name":      "retain-infra",
+				"namespace": "default",
+			},
+			"spec": map[string]interface{}{},
+		},
+	}
+
+	machine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "retain",
+			Namespace: "default",
+		},
+		Spec: clusterv1.MachineSpec{
+			ClusterName: "test-cluster",
+			InfrastructureRef: corev1.ObjectReference{
+				APIVersion: "infrastructure.cluster.x-k8s.io/v1alpha4",
+				Kind:       "InfrastructureMachine",
+				Name:       "retain-infra",
+			},
+			DeletePolicy: clusterv1.MachineDeletePolicyRetain,
+		},
+	}
+
+	t.Run("should set the delete policy into the infrastructure machine", func(t *testing.T) {
+		g := NewWithT(t)
+
+		c := fake.NewClientBuilder().WithObjects(machine.DeepCopy(), infraMachine.DeepCopy()).Build()
+		r := &MachineReconciler{
+			Client:   c,
+			recorder: record.NewFakeRecorder(32),
+		}
+
+		g.Expect(r.reconcileInfrastructureDeletePolicy(ctx, machine)).To(Succeed())
+
+		obj, err := external.Get(ctx, c, &machine.Spec.InfrastructureRef, machine.Namespace)
+		g.Expect(err).NotTo(HaveOccurred())
+		deletePolicy, _, err := unstructured.NestedString(obj.Object, "spec", "deletePolicy")
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(deletePolicy).To(Equal(string(clusterv1.MachineDeletePolicyRetain)))
+	})
+
+	t.Run("should emit a warning if the infrastructure machine does not support the delete policy", func(t *testing.T) {
+		g := NewWithT(t)
+
+		recorder := record.NewFakeRecorder(32)
+		r := &MachineReconciler{
+			Client:   deletePolicyPruningClient{fake.NewClientBuilder().WithObjects(machine.DeepCopy(), infraMachine.DeepCopy()).Build()},
+			recorder: recorder,
+		}
+
+		g.Expect(r.reconcileInfrastructureDeletePolicy(ctx, machine)).To(Succeed())
+		g.Expect(recorder.Events).To(Receive(ContainSubstring("DeletePolicyNotSupported")))
+		g.Expect(recorder.Events).NotTo(Receive())
+	})
+
+	t.Run("should succeed if the infrastructure machine does not exist", func(t *testing.T) {
+		g := NewWithT(t)
+
+		r := &MachineReconciler{
+			Client:   fake.NewClientBuilder().WithObjects(machine.DeepCopy()).Build(),
+			recorder: record.NewFakeRecorder(32),
+		}
+
+		g.Expect(r.reconcileInfrastructureDeletePolicy(ctx, machine)).To(Succeed())
+	})
+}
+
+// deletePolicyPruningClient simulates an API server pruning spec.deletePolicy from infrastructure machines
+// whose schema does not define it.
+type deletePolicyPruningClient struct {
+	client.Client
+}
+
+func (c deletePolicyPruningClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		unstructured.RemoveNestedField(u.Object, "spec", "deletePolicy")
+	}
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
 func TestRemoveMachineFinalizerAfterDeleteReconcile(t *testing.T) {
 	g := NewWithT(t)
 
@@ -1101,6 +1191,140 @@ func TestRemoveMachineFinalizerAfterDeleteReconcile(t *testing.T) {
 	g.Expect(actual.ObjectMeta.Finalizers).To(Equal([]string{"test"}))
 }
 
+func TestReconcileOrphanMachine(t *testing.T) {
+	newMachine := func() *clusterv1.Machine {
+		return &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "orphan",
+				Namespace:  "default",
+				Finalizers: []string{clusterv1.MachineFinalizer, "test"},
+			},
+			Spec: clusterv1.MachineSpec{
+				ClusterName: "deleted-cluster",
+				InfrastructureRef: corev1.ObjectReference{
+					APIVersion: "infrastructure.cluster.x-k8s.io/v1alpha4",
+					Kind:       "InfrastructureMachine",
+					Name:       "infra-config1",
+				},
+				Bootstrap: clusterv1.Bootstrap{DataSecretName: pointer.StringPtr("data")},
+			},
+		}
+	}
+	key := client.ObjectKey{Namespace: "default", Name: "orphan"}
+
+	t.Run("reports the machine as orphaned if the orphan machine cleanup is disabled", func(t *testing.T) {
+		g := NewWithT(t)
+
+		mr := &MachineReconciler{
+			Client: fake.NewClientBuilder().WithObjects(newMachine()).Build(),
+		}
+		_, err := mr.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		g.Expect(err).ToNot(HaveOccurred())
+
+		var actual clusterv1.Machine
+		g.Expect(mr.Client.Get(ctx, key, &actual)).To(Succeed())
+		g.Expect(actual.DeletionTimestamp.IsZero()).To(BeTrue())
+		g.Expect(actual.Finalizers).To(ContainElement(clusterv1.MachineFinalizer))
+		g.Expect(conditions.IsFalse(&actual, clusterv1.ClusterFoundCondition)).To(BeTrue())
+		g.Expect(conditions.GetReason(&actual, clusterv1.ClusterFoundCondition)).To(Equal(clusterv1.ClusterNotFoundReason))
+	})
+
+	orphanedMachine := func(orphanedFor time.Duration) *clusterv1.Machine {
+		m := newMachine()
+		conditions.Set(m, &clusterv1.Condition{
+			Type:               clusterv1.ClusterFoundCondition,
+			Status:             corev1.ConditionFalse,
+			Severity:           clusterv1.ConditionSeverityError,
+			Reason:             clusterv1.ClusterNotFoundReason,
+			Message:            "Cluster deleted-cluster does not exist",
+			LastTransitionTime: metav1.NewTime(time.Now().Add(-orphanedFor).UTC().Truncate(time.Second)),
+		})
+		return m
+	}
+
+	t.Run("waits for the grace period before deleting the machine", func(t *testing.T) {
+		g := NewWithT(t)
+
+		mr := &MachineReconciler{
+			Client:               fake.NewClientBuilder().WithObjects(newMachine()).Build(),
+			OrphanMachineCleanup: true,
+		}
+		result, err := mr.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+		g.Expect(result.RequeueAfter).To(BeNumerically("<=", orphanMachineCleanupGracePeriod))
+
+		var actual clusterv1.Machine
+		g.Expect(mr.Client.Get(ctx, key, &actual)).To(Succeed())
+		g.Expect(actual.DeletionTimestamp.IsZero()).To(BeTrue())
+		g.Expect(conditions.IsFalse(&actual, clusterv1.ClusterFoundCondition)).To(BeTrue())
+	})
+
+	t.Run("does not delete the machine if the cluster is not yet in the cache", func(t *testing.T) {
+		g := NewWithT(t)
+
+		testCluster := &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "deleted-cluster"},
+		}
+		mr := &MachineReconciler{
+			Client:               fake.NewClientBuilder().WithObjects(orphanedMachine(time.Hour)).Build(),
+			APIReader:            fake.NewClientBuilder().WithObjects(testCluster).Build(),
+			OrphanMachineCleanup: true,
+		}
+		result, err := mr.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(result.RequeueAfter).To(Equal(orphanMachineCacheRequeueAfter))
+
+		var actual clusterv1.Machine
+		g.Expect(mr.Client.Get(ctx, key, &actual)).To(Succeed())
+		g.Expect(actual.DeletionTimestamp.IsZero()).To(BeTrue())
+	})
+
+	t.Run("deletes and finalizes the machine if the orphan machine cleanup is enabled", func(t *testing.T) {
+		g := NewWithT(t)
+
+		mr := &MachineReconciler{
+			Client:               fake.NewClientBuilder().WithObjects(orphanedMachine(time.Hour)).Build(),
+			OrphanMachineCleanup: true,
+		}
+		_, err := mr.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		g.Expect(err).ToNot(HaveOccurred())
+
+		var actual clusterv1.Machine
+		g.Expect(mr.Client.Get(ctx, key, &actual)).To(Succeed())
+		g.Expect(actual.DeletionTimestamp.IsZero()).To(BeFalse())
+		g.Expect(actual.Finalizers).To(ContainElement(clusterv1.MachineFinalizer))
+
+		_, err = mr.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		g.Expect(err).ToNot(HaveOccurred())
+
+		g.Expect(mr.Client.Get(ctx, key, &actual)).To(Succeed())
+		g.Expect(actual.Finalizers).To(Equal([]string{"test"}))
+	})
+
+	t.Run("resolves the condition when the cluster is recreated", func(t *testing.T) {
+		g := NewWithT(t)
+
+		dt := metav1.Now()
+		m := newMachine()
+		m.DeletionTimestamp = &dt
+		conditions.MarkFalse(m, clusterv1.ClusterFoundCondition, clusterv1.ClusterNotFoundReason, clusterv1.ConditionSeverityError, "")
+		testCluster := &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "deleted-cluster"},
+		}
+
+		mr := &MachineReconciler{
+			Client: fake.NewClientBuilder().WithObjects(testCluster, m).Build(),
+		}
+		_, err := mr.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		g.Expect(err).ToNot(HaveOccurred())
+
+		var actual clusterv1.Machine
+		g.Expect(mr.Client.Get(ctx, key, &actual)).To(Succeed())
+		g.Expect(conditions.IsTrue(&actual, clusterv1.ClusterFoundCondition)).To(BeTrue())
+	})
+}
+
 func TestIsNodeDrainedAllowed(t *testing.T) {
 	testCluster := &clusterv1.Cluster{
 		TypeMeta:   metav1.TypeMeta{Kind: "Cluster", APIVersion: clusterv1.GroupVersion.String()},
@@ -1226,6 +1450,204 @@ func TestIsNodeDrainedAllowed(t *testing.T) {
 	}
 }
 
+func TestNodeDeletionStepTimeoutsExceeded(t *testing.T) {
+	stepStartTime := func(d time.Duration) *metav1.Time {
+		ts := metav1.NewTime(time.Now().Add(-d))
+		return &ts
+	}
+
+	tests := []struct {
+		name                 string
+		spec                 clusterv1.MachineSpec
+		status               clusterv1.MachineStatus
+		volumeDetachExceeded bool
+		nodeDeletionExceeded bool
+	}{
+		{
+			name: "Default node volume detach timeout is over",
+			status: clusterv1.MachineStatus{
+				DeletionStep:          clusterv1.MachineDeletionStepWaitingForVolumeDetach,
+				DeletionStepStartTime: stepStartTime(time.Hour),
+			},
+			volumeDetachExceeded: true,
+		},
+		{
+			name: "Default node volume detach timeout is not yet over",
+			status: clusterv1.MachineStatus{
+				DeletionStep:          clusterv1.MachineDeletionStepWaitingForVolumeDetach,
+				DeletionStepStartTime: stepStartTime(time.Minute),
+			},
+		},
+		{
+			name: "NodeVolumeDetachTimeout option is set to 0",
+			spec: clusterv1.MachineSpec{
+				NodeVolumeDetachTimeout: &metav1.Duration{},
+			},
+			status: clusterv1.MachineStatus{
+				DeletionStep:          clusterv1.MachineDeletionStepWaitingForVolumeDetach,
+				DeletionStepStartTime: stepStartTime(time.Hour),
+			},
+		},
+		{
+			name: "Node volume detach timeout is over",
+			spec: clusterv1.MachineSpec{
+				NodeVolumeDetachTimeout: &metav1.Duration{Duration: time.Second * 60},
+				NodeDeletionTimeout:     &metav1.Duration{Duration: time.Second * 60},
+			},
+			status: clusterv1.MachineStatus{
+				DeletionStep:          clusterv1.MachineDeletionStepWaitingForVolumeDetach,
+				DeletionStepStartTime: stepStartTime(time.Second * 70),
+			},
+			volumeDetachExceeded: true,
+		},
+		{
+			name: "Node volume detach timeout is not yet over",
+			spec: clusterv1.MachineSpec{
+				NodeVolumeDetachTimeout: &metav1.Duration{Duration: time.Second * 60},
+			},
+			status: clusterv1.MachineStatus{
+				DeletionStep:          clusterv1.MachineDeletionStepWaitingForVolumeDetach,
+				DeletionStepStartTime: stepStartTime(time.Second * 30),
+			},
+		},
+		{
+			name: "Node deletion timeout is over",
+			spec: clusterv1.MachineSpec{
+				NodeVolumeDetachTimeout: &metav1.Duration{Duration: time.Second * 60},
+				NodeDeletionTimeout:     &metav1.Duration{Duration: time.Second * 60},
+			},
+			status: clusterv1.MachineStatus{
+				DeletionStep:          clusterv1.MachineDeletionStepDeletingNode,
+				DeletionStepStartTime: stepStartTime(time.Second * 70),
+			},
+			nodeDeletionExceeded: true,
+		},
+		{
+			name: "NodeDeletionTimeout option is set to 0",
+			spec: clusterv1.MachineSpec{
+				NodeDeletionTimeout: &metav1.Duration{},
+			},
+			status: clusterv1.MachineStatus{
+				DeletionStep:          clusterv1.MachineDeletionStepDeletingNode,
+				DeletionStepStartTime: stepStartTime(time.Hour),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			m := &clusterv1.Machine{Spec: tt.spec, Status: tt.status}
+			r := &MachineReconciler{}
+
+			g.Expect(r.nodeVolumeDetachTimeoutExceeded(m)).To(Equal(tt.volumeDetachExceeded))
+			g.Expect(r.nodeDeletionTimeoutExceeded(m)).To(Equal(tt.nodeDeletionExceeded))
+		})
+	}
+}
+
+func TestVolumesAttached(t *testing.T) {
+	node := func(unreachable bool, volumes ...corev1.UniqueVolumeName) *corev1.Node {
+		n := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}}
+		for _, v := range volumes {
+			n.Status.VolumesAttached = append(n.Status.VolumesAttached, corev1.AttachedVolume{Name: v})
+		}
+		if unreachable {
+			n.Status.Conditions = []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionUnknown}}
+		}
+		return n
+	}
+	pod := func(name string, annotations map[string]string, ownerKind string) *corev1.Pod {
+		p := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: metav1.NamespaceDefault, Annotations: annotations},
+			Spec: corev1.PodSpec{
+				NodeName: "node-1",
+				Volumes: []corev1.Volume{{
+					Name: "data",
+					VolumeSource: corev1.VolumeSource{
+						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: name},
+					},
+				}},
+			},
+		}
+		if ownerKind != "" {
+			p.OwnerReferences = []metav1.OwnerReference{{Kind: ownerKind, Name: "owner", Controller: pointer.BoolPtr(true)}}
+		}
+		return p
+	}
+	volume := func(name string) []runtime.Object {
+		return []runtime.Object{
+			&corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: metav1.NamespaceDefault},
+				Spec:       corev1.PersistentVolumeClaimSpec{VolumeName: "pv-" + name},
+			},
+			&corev1.PersistentVolume{
+				ObjectMeta: metav1.ObjectMeta{Name: "pv-" + name},
+				Spec: corev1.PersistentVolumeSpec{
+					PersistentVolumeSource: corev1.PersistentVolumeSource{
+						CSI: &corev1.CSIPersistentVolumeSource{Driver: "csi.example.com", VolumeHandle: name},
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		objs     []runtime.Object
+		expected bool
+	}{
+		{
+			name:     "Node does not exist",
+			expected: false,
+		},
+		{
+			name:     "Node without attached volumes",
+			objs:     []runtime.Object{node(false)},
+			expected: false,
+		},
+		{
+			name:     "Node with attached volumes",
+			objs:     []runtime.Object{node(false, "kubernetes.io/csi/csi.example.com^app")},
+			expected: true,
+		},
+		{
+			name:     "Unreachable node with attached volumes",
+			objs:     []runtime.Object{node(true, "kubernetes.io/csi/csi.example.com^app")},
+			expected: false,
+		},
+		{
+			name: "Node with volumes attached only for DaemonSet and mirror pods",
+			objs: append(append([]runtime.Object{
+				node(false, "kubernetes.io/csi/csi.example.com^ds", "kubernetes.io/csi/csi.example.com^static"),
+				pod("ds", nil, "DaemonSet"),
+				pod("static", map[string]string{corev1.MirrorPodAnnotationKey: "hash"}, ""),
+			}, volume("ds")...), volume("static")...),
+			expected: false,
+		},
+		{
+			name: "Node with volumes attached for DaemonSet and other pods",
+			objs: append(append([]runtime.Object{
+				node(false, "kubernetes.io/csi/csi.example.com^ds", "kubernetes.io/csi/csi.example.com^app"),
+				pod("ds", nil, "DaemonSet"),
+				pod("app", nil, "ReplicaSet"),
+			}, volume("ds")...), volume("app")...),
+			expected: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			kubeClient := kubefake.NewSimpleClientset(tt.objs...)
+
+			attached, err := volumesAttached(ctx, kubeClient, "node-1")
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(attached).To(Equal(tt.expected))
+		})
+	}
+}
+
 func TestIsDeleteNodeAllowed(t *testing.T) {
 	deletionts := metav1.Now()
 
@@ -0,0 +1,83 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// machineDeletionBuckets are the buckets of the Machine deletion histograms, ranging from seconds to an hour.
+var machineDeletionBuckets = []float64{1, 5, 15, 30, 60, 120, 300, 600, 1200, 1800, 3600}
+
+var (
+	// machineDeletionStepDuration observes the duration of the steps of the Machine deletion workflow.
+	machineDeletionStepDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "capi_machine_deletion_step_duration_seconds",
+			Help:    "Duration of the steps of the Machine deletion workflow, by step.",
+			Buckets: machineDeletionBuckets,
+		},
+		[]string{"step"},
+	)
+
+	// machineDeletionDuration observes the duration of the whole Machine deletion workflow.
+	machineDeletionDuration = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "capi_machine_deletion_duration_seconds",
+			Help:    "Duration of the Machine deletion, from the deletion request to the removal of the Machine finalizer.",
+			Buckets: machineDeletionBuckets,
+		},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(machineDeletionStepDuration, machineDeletionDuration)
+}
+
+// setMachineDeletionStep records the deletion step the Machine is going through in its status;
+// when the step changes, the duration of the previous step is observed.
+func setMachineDeletionStep(m *clusterv1.Machine, step clusterv1.MachineDeletionStep) {
+	if m.Status.DeletionStep == step {
+		return
+	}
+	observeMachineDeletionStep(m)
+
+	now := metav1.Now()
+	m.Status.DeletionStep = step
+	m.Status.DeletionStepStartTime = &now
+}
+
+// recordMachineDeleted observes the duration of the last deletion step and of the whole deletion of a Machine
+// whose finalizer is being removed.
+func recordMachineDeleted(m *clusterv1.Machine) {
+	observeMachineDeletionStep(m)
+	if !m.DeletionTimestamp.IsZero() {
+		machineDeletionDuration.Observe(time.Since(m.DeletionTimestamp.Time).Seconds())
+	}
+}
+
+func observeMachineDeletionStep(m *clusterv1.Machine) {
+	if m.Status.DeletionStep == "" || m.Status.DeletionStepStartTime == nil {
+		return
+	}
+	machineDeletionStepDuration.WithLabelValues(string(m.Status.DeletionStep)).Observe(time.Since(m.Status.DeletionStepStartTime.Time).Seconds())
+}
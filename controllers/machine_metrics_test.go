@@ -0,0 +1,57 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+)
+
+func TestMachineDeletionSteps(t *testing.T) {
+	g := NewWithT(t)
+
+	machineDeletionStepDuration.Reset()
+	deletionTimestamp := metav1.NewTime(time.Now().Add(-time.Minute))
+	m := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "foo",
+			DeletionTimestamp: &deletionTimestamp,
+		},
+	}
+
+	setMachineDeletionStep(m, clusterv1.MachineDeletionStepDraining)
+	g.Expect(m.Status.DeletionStep).To(Equal(clusterv1.MachineDeletionStepDraining))
+	g.Expect(m.Status.DeletionStepStartTime).NotTo(BeNil())
+	g.Expect(testutil.CollectAndCount(machineDeletionStepDuration)).To(Equal(0))
+
+	// Setting the same step again does not reset the step start time.
+	startTime := m.Status.DeletionStepStartTime
+	setMachineDeletionStep(m, clusterv1.MachineDeletionStepDraining)
+	g.Expect(m.Status.DeletionStepStartTime).To(BeIdenticalTo(startTime))
+
+	setMachineDeletionStep(m, clusterv1.MachineDeletionStepDeletingInfrastructure)
+	g.Expect(m.Status.DeletionStep).To(Equal(clusterv1.MachineDeletionStepDeletingInfrastructure))
+	g.Expect(testutil.CollectAndCount(machineDeletionStepDuration)).To(Equal(1))
+
+	recordMachineDeleted(m)
+	g.Expect(testutil.CollectAndCount(machineDeletionStepDuration)).To(Equal(2))
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	// machineDeploymentReplicasByRevision reports the number of machines of a MachineDeployment for each revision
+	// of its machine template, so the progress of a rollout can be observed.
+	machineDeploymentReplicasByRevision = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "capi_machinedeployment_replicas_by_revision",
+			Help: "Number of machines of a MachineDeployment, by revision of the machine template they have been created from.",
+		},
+		[]string{"namespace", "machinedeployment", "revision"},
+	)
+
+	// machineDeploymentStaleReplicas reports the number of machines of a MachineDeployment not created from
+	// the latest revision of its machine template.
+	machineDeploymentStaleReplicas = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "capi_machinedeployment_stale_replicas",
+			Help: "Number of machines of a MachineDeployment not created from the latest revision of its machine template.",
+		},
+		[]string{"namespace", "machinedeployment"},
+	)
+
+	// machineDeploymentRevisions tracks the revisions reported for each MachineDeployment, so the gauges of
+	// the revisions without machines can be deleted.
+	machineDeploymentRevisions     = map[types.NamespacedName]sets.String{}
+	machineDeploymentRevisionsLock sync.Mutex
+)
+
+func init() {
+	metrics.Registry.MustRegister(machineDeploymentReplicasByRevision, machineDeploymentStaleReplicas)
+}
+
+// recordMachineDeploymentRevisions reports the number of machines of a MachineDeployment by revision, and the number
+// of stale machines, according to the MachineSets of the MachineDeployment and to its status.
+func recordMachineDeploymentRevisions(d *clusterv1.MachineDeployment, allMSs []*clusterv1.MachineSet) {
+	replicasByRevision := map[string]int32{}
+	for _, ms := range allMSs {
+		revision, ok := ms.Annotations[clusterv1.RevisionAnnotation]
+		if !ok || ms.Status.Replicas == 0 {
+			continue
+		}
+		replicasByRevision[revision] += ms.Status.Replicas
+	}
+
+	machineDeploymentRevisionsLock.Lock()
+	defer machineDeploymentRevisionsLock.Unlock()
+
+	key := types.NamespacedName{Namespace: d.Namespace, Name: d.Name}
+	for revision := range machineDeploymentRevisions[key] {
+		if _, ok := replicasByRevision[revision]; !ok {
+			machineDeploymentReplicasByRevision.DeleteLabelValues(d.Namespace, d.Name, revision)
+		}
+	}
+	revisions := sets.NewString()
+	for revision, replicas := range replicasByRevision {
+		machineDeploymentReplicasByRevision.WithLabelValues(d.Namespace, d.Name, revision).Set(float64(replicas))
+		revisions.Insert(revision)
+	}
+	machineDeploymentRevisions[key] = revisions
+
+	machineDeploymentStaleReplicas.WithLabelValues(d.Namespace, d.Name).Set(float64(d.Status.Replicas - d.Status.UpdatedReplicas))
+}
+
+// deleteMachineDeploymentMetrics deletes the gauges of a MachineDeployment which does not exist anymore.
+func deleteMachineDeploymentMetrics(namespace, name string) {
+	machineDeploymentRevisionsLock.Lock()
+	defer machineDeploymentRevisionsLock.Unlock()
+
+	key := types.NamespacedName{Namespace: namespace, Name: name}
+	for revision := range machineDeploymentRevisions[key] {
+		machineDeploymentReplicasByRevision.DeleteLabelValues(namespace, name, revision)
+	}
+	delete(machineDeploymentRevisions, key)
+	machineDeploymentStaleReplicas.DeleteLabelValues(namespace, name)
+}
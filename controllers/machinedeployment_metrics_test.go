@@ -0,0 +1,71 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+)
+
+func TestRecordMachineDeploymentRevisions(t *testing.T) {
+	g := NewWithT(t)
+
+	machineDeploymentReplicasByRevision.Reset()
+	machineDeploymentStaleReplicas.Reset()
+
+	d := &clusterv1.MachineDeployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "md",
+			Namespace: "default",
+		},
+	}
+	newMachineSet := func(revision string, replicas int32) *clusterv1.MachineSet {
+		return &clusterv1.MachineSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{clusterv1.RevisionAnnotation: revision},
+			},
+			Status: clusterv1.MachineSetStatus{Replicas: replicas},
+		}
+	}
+
+	// During a rollout, machines are reported for both the old and the new revision.
+	d.Status.Replicas = 3
+	d.Status.UpdatedReplicas = 1
+	recordMachineDeploymentRevisions(d, []*clusterv1.MachineSet{newMachineSet("1", 2), newMachineSet("2", 1)})
+
+	g.Expect(testutil.ToFloat64(machineDeploymentReplicasByRevision.WithLabelValues("default", "md", "1"))).To(Equal(float64(2)))
+	g.Expect(testutil.ToFloat64(machineDeploymentReplicasByRevision.WithLabelValues("default", "md", "2"))).To(Equal(float64(1)))
+	g.Expect(testutil.ToFloat64(machineDeploymentStaleReplicas.WithLabelValues("default", "md"))).To(Equal(float64(2)))
+
+	// Once the rollout completes, the old revision is not reported anymore.
+	d.Status.UpdatedReplicas = 3
+	recordMachineDeploymentRevisions(d, []*clusterv1.MachineSet{newMachineSet("1", 0), newMachineSet("2", 3)})
+
+	g.Expect(testutil.CollectAndCount(machineDeploymentReplicasByRevision)).To(Equal(1))
+	g.Expect(testutil.ToFloat64(machineDeploymentReplicasByRevision.WithLabelValues("default", "md", "2"))).To(Equal(float64(3)))
+	g.Expect(testutil.ToFloat64(machineDeploymentStaleReplicas.WithLabelValues("default", "md"))).To(Equal(float64(0)))
+
+	// The metrics are deleted together with the MachineDeployment.
+	deleteMachineDeploymentMetrics("default", "md")
+
+	g.Expect(testutil.CollectAndCount(machineDeploymentReplicasByRevision)).To(Equal(0))
+	g.Expect(testutil.CollectAndCount(machineDeploymentStaleReplicas)).To(Equal(0))
+}
@@ -39,6 +39,8 @@ import (
 	"sigs.k8s.io/cluster-api/controllers/external"
 	"sigs.k8s.io/cluster-api/controllers/noderefutil"
 	"sigs.k8s.io/cluster-api/controllers/remote"
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/feature"
 	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/cluster-api/util/annotations"
 	"sigs.k8s.io/cluster-api/util/conditions"
@@ -63,6 +65,8 @@ const (
 // +kubebuilder:rbac:groups=core,resources=events,verbs=get;list;watch;create;patch
 // +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
 // +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machines;machines/status,verbs=get;list;watch;delete
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machinedeployments,verbs=get;list;watch
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machinepools;machinepools/status,verbs=get;list;watch;patch
 // +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machinehealthchecks;machinehealthchecks/status;machinehealthchecks/finalizers,verbs=get;list;watch;update;patch
 
 // MachineHealthCheckReconciler reconciles a MachineHealthCheck object.
@@ -97,6 +101,15 @@ func (r *MachineHealthCheckReconciler) SetupWithManager(ctx context.Context, mgr
 	if err != nil {
 		return errors.Wrap(err, "failed to add Watch for Clusters to controller manager")
 	}
+	if feature.Gates.Enabled(feature.MachinePool) {
+		err = controller.Watch(
+			&source.Kind{Type: &expv1.MachinePool{}},
+			handler.EnqueueRequestsFromMapFunc(r.machinePoolToMachineHealthCheck),
+		)
+		if err != nil {
+			return errors.Wrap(err, "failed to add Watch for MachinePools to controller manager")
+		}
+	}
 
 	r.controller = controller
 	r.recorder = mgr.GetEventRecorderFor("machinehealthcheck-controller")
@@ -202,6 +215,19 @@ func (r *MachineHealthCheckReconciler) reconcile(ctx context.Context, logger log
 		return ctrl.Result{}, err
 	}
 	totalTargets := len(targets)
+
+	// fetch all machine pool targets, if enabled; machine pool instances have no Machine objects, so their nodes are health checked directly.
+	var machinePoolTargets []machinePoolTarget
+	if feature.Gates.Enabled(feature.MachinePool) && m.Spec.TargetMachinePools {
+		machinePoolTargets, err = r.getMachinePoolTargetsFromMHC(ctx, logger, remoteClient, m)
+		if err != nil {
+			logger.Error(err, "Failed to fetch machine pool targets from MachineHealthCheck")
+			return ctrl.Result{}, err
+		}
+		for _, t := range machinePoolTargets {
+			totalTargets += len(t.Nodes)
+		}
+	}
 	m.Status.ExpectedMachines = int32(totalTargets)
 	m.Status.Targets = make([]string, totalTargets)
 	for i, t := range targets {
@@ -218,6 +244,20 @@ func (r *MachineHealthCheckReconciler) reconcile(ctx context.Context, logger log
 	// health check all targets and reconcile mhc status
 	healthy, unhealthy, nextCheckTimes := r.healthCheckTargets(targets, logger, *nodeStartupTimeout)
 	m.Status.CurrentHealthy = int32(len(healthy))
+	unhealthyCount := len(unhealthy)
+
+	// health check all machine pool nodes
+	machinePoolProviderIDs := map[string][]string{}
+	for i := range machinePoolTargets {
+		t := &machinePoolTargets[i]
+		healthyNodes, unhealthyProviderIDs, nextCheck := t.healthCheck(logger, cluster, m, time.Now())
+		m.Status.CurrentHealthy += int32(healthyNodes)
+		unhealthyCount += len(unhealthyProviderIDs)
+		machinePoolProviderIDs[t.MachinePool.Name] = unhealthyProviderIDs
+		if nextCheck > 0 {
+			nextCheckTimes = append(nextCheckTimes, nextCheck)
+		}
+	}
 
 	var unhealthyLimitKey, unhealthyLimitValue interface{}
 
@@ -235,14 +275,14 @@ func (r *MachineHealthCheckReconciler) reconcile(ctx context.Context, logger log
 			unhealthyLimitValue = m.Spec.MaxUnhealthy
 			message = fmt.Sprintf("Remediation is not allowed, the number of not started or unhealthy machines exceeds maxUnhealthy (total: %v, unhealthy: %v, maxUnhealthy: %v)",
 				totalTargets,
-				len(unhealthy),
+				unhealthyCount,
 				m.Spec.MaxUnhealthy)
 		} else {
 			unhealthyLimitKey = "unhealthy range"
 			unhealthyLimitValue = *m.Spec.UnhealthyRange
 			message = fmt.Sprintf("Remediation is not allowed, the number of not started or unhealthy machines does not fall within the range (total: %v, unhealthy: %v, unhealthyRange: %v)",
 				totalTargets,
-				len(unhealthy),
+				unhealthyCount,
 				*m.Spec.UnhealthyRange)
 		}
 
@@ -250,7 +290,7 @@ func (r *MachineHealthCheckReconciler) reconcile(ctx context.Context, logger log
 			"Short-circuiting remediation",
 			"total target", totalTargets,
 			unhealthyLimitKey, unhealthyLimitValue,
-			"unhealthy targets", len(unhealthy),
+			"unhealthy targets", unhealthyCount,
 		)
 
 		// Remediation not allowed, the number of not started or unhealthy machines either exceeds maxUnhealthy (or) not within unhealthyRange
@@ -276,6 +316,7 @@ func (r *MachineHealthCheckReconciler) reconcile(ctx context.Context, logger log
 				continue
 			}
 		}
+		errList = append(errList, r.patchMachinePoolTargets(ctx, machinePoolTargets, nil)...)
 		if len(errList) > 0 {
 			return ctrl.Result{}, kerrors.NewAggregate(errList)
 		}
@@ -286,15 +327,91 @@ func (r *MachineHealthCheckReconciler) reconcile(ctx context.Context, logger log
 		"Remediations are allowed",
 		"total target", totalTargets,
 		unhealthyLimitKey, unhealthyLimitValue,
-		"unhealthy targets", len(unhealthy),
+		"unhealthy targets", unhealthyCount,
 	)
 
 	// Remediation is allowed so unhealthyMachineCount is within unhealthyRange (or) maxUnhealthy - unhealthyMachineCount >= 0
 	m.Status.RemediationsAllowed = remediationCount
 	conditions.MarkTrue(m, clusterv1.RemediationAllowedCondition)
 
+	// Pause the remediations of machines owned by a MachineDeployment or a control plane in the middle of a rollout.
+	unhealthy, paused, err := r.splitByOwnerRollout(ctx, cluster, unhealthy, time.Now())
+	if err != nil {
+		return ctrl.Result{}, errors.Wrapf(err, "error checking if remediation is paused during rollouts")
+	}
+	if len(paused) > 0 {
+		message := fmt.Sprintf("Remediation is paused, the owners of some unhealthy machines are in the middle of a rollout (paused: %v)", len(paused))
+
+		logger.V(3).Info(
+			"Pausing remediation during rollout",
+			"paused targets", len(paused),
+			"requeueIn", rolloutRemediationCheckInterval.String(),
+		)
+
+		conditions.MarkFalse(m, clusterv1.RemediationAllowedCondition, clusterv1.RemediationPausedDuringRolloutReason, clusterv1.ConditionSeverityInfo, message)
+		r.recorder.Eventf(
+			m,
+			corev1.EventTypeNormal,
+			EventRemediationRestricted,
+			message,
+		)
+		nextCheckTimes = append(nextCheckTimes, rolloutRemediationCheckInterval)
+	}
+
+	// Defer the remediations exceeding the remediation rate limit, if any.
+	now := time.Now()
+	var remediations, deferred []healthCheckTarget
+	if rateLimit := m.Spec.RemediationRateLimit; rateLimit != nil {
+		var requeueAfter time.Duration
+		remediations, unhealthy, deferred, requeueAfter = r.splitByRemediationRateLimit(ctx, cluster, m, unhealthy, now)
+
+		if len(deferred) > 0 {
+			message := fmt.Sprintf("Remediation is deferred, the number of remediations exceeds the remediation rate limit (deferred: %v, maxRemediations: %v)",
+				len(deferred),
+				rateLimit.MaxRemediations)
+			if conditions.IsFalse(m, clusterv1.RemediationCircuitBreakerClosedCondition) {
+				message = fmt.Sprintf("Remediation is paused, the remediation circuit breaker is open (deferred: %v)", len(deferred))
+			}
+
+			logger.V(3).Info(
+				"Rate limiting remediation",
+				"max remediations", rateLimit.MaxRemediations,
+				"deferred targets", len(deferred),
+				"requeueIn", requeueAfter.Truncate(time.Second).String(),
+			)
+
+			conditions.MarkFalse(m, clusterv1.RemediationAllowedCondition, clusterv1.RemediationRateLimitedReason, clusterv1.ConditionSeverityWarning, message)
+			r.recorder.Eventf(
+				m,
+				corev1.EventTypeWarning,
+				EventRemediationRestricted,
+				message,
+			)
+			nextCheckTimes = append(nextCheckTimes, requeueAfter)
+		}
+	} else {
+		m.Status.RemediationRateLimit = nil
+		conditions.Delete(m, clusterv1.RemediationCircuitBreakerClosedCondition)
+	}
+
 	errList := r.patchUnhealthyTargets(ctx, logger, unhealthy, cluster, m)
+	errList = append(errList, r.patchRemediationTargets(ctx, logger, remediations, cluster, m, now)...)
+	if rateLimit := m.Spec.RemediationRateLimit; rateLimit != nil {
+		remaining := rateLimit.MaxRemediations - int32(len(m.Status.RemediationRateLimit.RecentRemediations))
+		if remaining < 0 || conditions.IsFalse(m, clusterv1.RemediationCircuitBreakerClosedCondition) {
+			remaining = 0
+		}
+		if remaining < m.Status.RemediationsAllowed {
+			m.Status.RemediationsAllowed = remaining
+		}
+	}
 	errList = append(errList, r.patchHealthyTargets(ctx, logger, healthy, m)...)
+	errList = append(errList, r.patchMachinePoolTargets(ctx, machinePoolTargets, machinePoolProviderIDs)...)
+	for _, t := range append(deferred, paused...) {
+		if err := t.patchHelper.Patch(ctx, t.Machine); err != nil {
+			errList = append(errList, errors.Wrapf(err, "failed to patch unhealthy machine status for machine: %s/%s", t.Machine.Namespace, t.Machine.Name))
+		}
+	}
 
 	// handle update errors
 	if len(errList) > 0 {
@@ -353,7 +470,10 @@ func (r *MachineHealthCheckReconciler) patchUnhealthyTargets(ctx context.Context
 		if annotations.IsPaused(cluster, t.Machine) {
 			logger.Info("Machine has failed health check, but machine is paused so skipping remediation", "target", t.string(), "reason", condition.Reason, "message", condition.Message)
 		} else {
-			if m.Spec.RemediationTemplate != nil {
+			// NOTE: Control plane machines owned by a control plane provider are always remediated by their owner, which
+			// is responsible for remediating one machine at a time without losing etcd quorum; external remediation
+			// applies only to the other machines.
+			if m.Spec.RemediationTemplate != nil && !isControlPlaneOwnedMachine(t.Machine) {
 				// If external remediation request already exists,
 				// return early
 				if r.externalRemediationRequestExists(ctx, m, t.Machine.Name) {
@@ -503,12 +623,17 @@ func (r *MachineHealthCheckReconciler) watchClusterNodes(ctx context.Context, cl
 		return nil
 	}
 
+	mapFunc := r.nodeToMachineHealthCheck
+	if feature.Gates.Enabled(feature.MachinePool) {
+		mapFunc = r.clusterNodeToMachineHealthCheck(cluster)
+	}
+
 	return r.Tracker.Watch(ctx, remote.WatchInput{
 		Name:         "machinehealthcheck-watchClusterNodes",
 		Cluster:      util.ObjectKey(cluster),
 		Watcher:      r.controller,
 		Kind:         &corev1.Node{},
-		EventHandler: handler.EnqueueRequestsFromMapFunc(r.nodeToMachineHealthCheck),
+		EventHandler: handler.EnqueueRequestsFromMapFunc(mapFunc),
 	})
 }
 
@@ -582,6 +707,12 @@ func unhealthyMachineCount(mhc *clusterv1.MachineHealthCheck) int {
 	return int(mhc.Status.ExpectedMachines - mhc.Status.CurrentHealthy)
 }
 
+// isControlPlaneOwnedMachine returns true if the machine is a control plane machine controlled by a control plane provider,
+// e.g. the KubeadmControlPlane.
+func isControlPlaneOwnedMachine(machine *clusterv1.Machine) bool {
+	return util.IsControlPlaneMachine(machine) && metav1.GetControllerOf(machine) != nil
+}
+
 // getExternalRemediationRequest gets reference to External Remediation Request, unstructured object.
 func (r *MachineHealthCheckReconciler) getExternalRemediationRequest(ctx context.Context, m *clusterv1.MachineHealthCheck, machineName string) (*unstructured.Unstructured, error) {
 	remediationRef := &corev1.ObjectReference{
@@ -2623,3 +2623,57 @@ func TestPatchTargets(t *testing.T) {
 	// Target with wrong patch helper will fail but the other one will be patched.
 	g.Expect(len(r.patchHealthyTargets(context.TODO(), log.NullLogger{}, []healthCheckTarget{target1, target3}, mhc))).To(BeNumerically(">", 0))
 }
+
+func TestPatchUnhealthyTargetsDelegatesControlPlaneMachines(t *testing.T) {
+	g := NewWithT(t)
+
+	namespace := defaultNamespaceName
+	clusterName := testClusterName
+	defaultCluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      clusterName,
+			Namespace: namespace,
+		},
+	}
+	labels := map[string]string{clusterv1.MachineControlPlaneLabelName: ""}
+
+	// NB. The remediation template does not exist, so any attempt to create an external remediation request fails.
+	mhc := newMachineHealthCheckWithLabels("mhc", namespace, clusterName, labels)
+	mhc.Spec.RemediationTemplate = &corev1.ObjectReference{
+		APIVersion: "infrastructure.cluster.x-k8s.io/v1alpha4",
+		Kind:       "InfrastructureRemediationTemplate",
+		Name:       "remediation-template",
+		Namespace:  namespace,
+	}
+	machine := newTestMachine("machine1", namespace, clusterName, "nodeName", labels)
+	machine.OwnerReferences = []metav1.OwnerReference{
+		{
+			APIVersion: "controlplane.cluster.x-k8s.io/v1alpha4",
+			Kind:       "KubeadmControlPlane",
+			Name:       "kcp",
+			Controller: pointer.BoolPtr(true),
+		},
+	}
+	conditions.MarkFalse(machine, clusterv1.MachineHealthCheckSuccededCondition, clusterv1.NodeNotFoundReason, clusterv1.ConditionSeverityWarning, "")
+
+	cl := fake.NewClientBuilder().WithObjects(machine, mhc).Build()
+	r := &MachineHealthCheckReconciler{
+		Client:   cl,
+		recorder: record.NewFakeRecorder(32),
+	}
+
+	patchHelper, err := patch.NewHelper(machine, cl)
+	g.Expect(err).ToNot(HaveOccurred())
+	target := healthCheckTarget{
+		MHC:         mhc,
+		Machine:     machine,
+		patchHelper: patchHelper,
+		Node:        &corev1.Node{},
+	}
+
+	// Control plane machines are marked for remediation by the control plane instead of using external remediation.
+	g.Expect(r.patchUnhealthyTargets(context.TODO(), log.NullLogger{}, []healthCheckTarget{target}, defaultCluster, mhc)).To(BeEmpty())
+	g.Expect(cl.Get(ctx, client.ObjectKey{Name: machine.Name, Namespace: machine.Namespace}, machine)).To(Succeed())
+	g.Expect(conditions.IsFalse(machine, clusterv1.MachineOwnerRemediatedCondition)).To(BeTrue())
+	g.Expect(conditions.GetReason(machine, clusterv1.MachineOwnerRemediatedCondition)).To(Equal(clusterv1.WaitingForRemediationReason))
+}
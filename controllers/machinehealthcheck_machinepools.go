@@ -0,0 +1,216 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/annotations"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// machinePoolTarget contains the information required to health check the nodes of a MachinePool.
+// Machine instances of a MachinePool do not have Machine objects, so unhealthy nodes are remediated
+// by asking the infrastructure provider to delete or replace the corresponding instances.
+type machinePoolTarget struct {
+	MachinePool *expv1.MachinePool
+	Nodes       []*corev1.Node
+	patchHelper *patch.Helper
+}
+
+// healthCheck health checks the nodes of the MachinePool, returning the number of healthy nodes,
+// the provider IDs of the unhealthy nodes and the duration after which the nodes should be checked again.
+// NOTE: nodes without a provider ID are ignored, because the infrastructure provider can't identify the
+// corresponding instances.
+func (t *machinePoolTarget) healthCheck(logger logr.Logger, cluster *clusterv1.Cluster, mhc *clusterv1.MachineHealthCheck, now time.Time) (int, []string, time.Duration) {
+	// Don't penalize any node if the control plane has not been initialized or the cluster infrastructure is not ready.
+	// We'll get requeued when the Cluster is updated.
+	if !conditions.IsTrue(cluster, clusterv1.ControlPlaneInitializedCondition) || !conditions.IsTrue(cluster, clusterv1.InfrastructureReadyCondition) {
+		logger.V(3).Info("Not evaluating MachinePool health because the cluster is not yet initialized", "machinePool", t.MachinePool.Name)
+		return 0, nil, 0
+	}
+
+	var nextCheckTimes []time.Duration
+	healthy := 0
+	unhealthy := []string{}
+	for _, node := range t.Nodes {
+		if node.Spec.ProviderID == "" {
+			continue
+		}
+
+		c, nextCheck := unhealthyNodeCondition(node, mhc.Spec.UnhealthyConditions, now)
+		if c != nil {
+			logger.V(3).Info("MachinePool node is unhealthy: condition is in state longer than allowed timeout", "machinePool", t.MachinePool.Name, "node", node.Name, "condition", c.Type, "state", c.Status, "timeout", c.Timeout.Duration.String())
+			unhealthy = append(unhealthy, node.Spec.ProviderID)
+			continue
+		}
+		if nextCheck > 0 {
+			nextCheckTimes = append(nextCheckTimes, nextCheck)
+			continue
+		}
+		healthy++
+	}
+	sort.Strings(unhealthy)
+	return healthy, unhealthy, minDuration(nextCheckTimes)
+}
+
+// getMachinePoolTargetsFromMHC uses the MachineHealthCheck's selector to fetch the MachinePools
+// and their nodes targeted by the health check; it must be called only if TargetMachinePools is enabled.
+func (r *MachineHealthCheckReconciler) getMachinePoolTargetsFromMHC(ctx context.Context, logger logr.Logger, clusterClient client.Reader, mhc *clusterv1.MachineHealthCheck) ([]machinePoolTarget, error) {
+	selector, err := metav1.LabelSelectorAsSelector(metav1.CloneSelectorAndAddLabel(
+		&mhc.Spec.Selector, clusterv1.ClusterLabelName, mhc.Spec.ClusterName,
+	))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build selector")
+	}
+
+	machinePoolList := &expv1.MachinePoolList{}
+	if err := r.Client.List(
+		ctx,
+		machinePoolList,
+		client.MatchingLabelsSelector{Selector: selector},
+		client.InNamespace(mhc.GetNamespace()),
+	); err != nil {
+		return nil, errors.Wrap(err, "failed to list machine pools")
+	}
+
+	targets := []machinePoolTarget{}
+	for i := range machinePoolList.Items {
+		mp := &machinePoolList.Items[i]
+		if annotations.HasPausedAnnotation(mp) || annotations.HasSkipRemediationAnnotation(mp) {
+			logger.Info("skipping remediation", "machinePool", mp.Name, "reason", "machine pool has paused or skip remediation annotation")
+			continue
+		}
+
+		patchHelper, err := patch.NewHelper(mp, r.Client)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to initialize patch helper")
+		}
+		target := machinePoolTarget{
+			MachinePool: mp,
+			patchHelper: patchHelper,
+		}
+		for _, nodeRef := range mp.Status.NodeRefs {
+			node := &corev1.Node{}
+			if err := clusterClient.Get(ctx, client.ObjectKey{Name: nodeRef.Name}, node); err != nil {
+				// Nodes going away are not reported; the NodeRefs are updated by the MachinePool controller.
+				if apierrors.IsNotFound(err) {
+					continue
+				}
+				return nil, errors.Wrap(err, "error getting node")
+			}
+			target.Nodes = append(target.Nodes, node)
+		}
+		targets = append(targets, target)
+	}
+	return targets, nil
+}
+
+// patchMachinePoolTargets reports the provider IDs of the instances to be remediated in the MachinePools status;
+// a nil providerIDs map clears the instances to be remediated, e.g. when remediation is not allowed.
+func (r *MachineHealthCheckReconciler) patchMachinePoolTargets(ctx context.Context, targets []machinePoolTarget, providerIDs map[string][]string) []error {
+	errList := []error{}
+	for _, t := range targets {
+		t.MachinePool.Status.ProviderIDsToRemediate = nil
+		if ids := providerIDs[t.MachinePool.Name]; len(ids) > 0 {
+			t.MachinePool.Status.ProviderIDsToRemediate = ids
+		}
+		if err := t.patchHelper.Patch(ctx, t.MachinePool); err != nil {
+			errList = append(errList, errors.Wrapf(err, "failed to patch machine pool status for machine pool: %s/%s", t.MachinePool.Namespace, t.MachinePool.Name))
+		}
+	}
+	return errList
+}
+
+// machinePoolToMachineHealthCheck maps events from MachinePool objects to
+// MachineHealthCheck objects that monitor the given machine pool, i.e. with TargetMachinePools enabled.
+func (r *MachineHealthCheckReconciler) machinePoolToMachineHealthCheck(o client.Object) []reconcile.Request {
+	mp, ok := o.(*expv1.MachinePool)
+	if !ok {
+		panic(fmt.Sprintf("Expected a MachinePool, got %T", o))
+	}
+
+	mhcList := &clusterv1.MachineHealthCheckList{}
+	if err := r.Client.List(
+		context.TODO(),
+		mhcList,
+		client.InNamespace(mp.Namespace),
+		client.MatchingLabels{clusterv1.ClusterLabelName: mp.Spec.ClusterName},
+	); err != nil {
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for k := range mhcList.Items {
+		mhc := &mhcList.Items[k]
+		if mhc.Spec.TargetMachinePools && hasMatchingLabels(mhc.Spec.Selector, mp.Labels) {
+			requests = append(requests, reconcile.Request{NamespacedName: util.ObjectKey(mhc)})
+		}
+	}
+	return requests
+}
+
+// clusterNodeToMachineHealthCheck returns a handler.MapFunc mapping events from the Nodes of the given cluster
+// to the MachineHealthCheck objects that monitor the Machine, or the machine pool, the node belongs to.
+func (r *MachineHealthCheckReconciler) clusterNodeToMachineHealthCheck(cluster *clusterv1.Cluster) handler.MapFunc {
+	return func(o client.Object) []reconcile.Request {
+		if requests := r.nodeToMachineHealthCheck(o); len(requests) > 0 {
+			return requests
+		}
+		// Nodes of machine pool instances do not have a Machine.
+		return r.nodeToMachinePoolMachineHealthCheck(cluster, o.(*corev1.Node))
+	}
+}
+
+// nodeToMachinePoolMachineHealthCheck maps events from the Nodes of machine pool instances to
+// MachineHealthCheck objects that monitor the machine pool the node belongs to.
+func (r *MachineHealthCheckReconciler) nodeToMachinePoolMachineHealthCheck(cluster *clusterv1.Cluster, node *corev1.Node) []reconcile.Request {
+	machinePoolList := &expv1.MachinePoolList{}
+	if err := r.Client.List(
+		context.TODO(),
+		machinePoolList,
+		client.InNamespace(cluster.Namespace),
+		client.MatchingLabels{clusterv1.ClusterLabelName: cluster.Name},
+	); err != nil {
+		return nil
+	}
+
+	for i := range machinePoolList.Items {
+		mp := &machinePoolList.Items[i]
+		for _, nodeRef := range mp.Status.NodeRefs {
+			if nodeRef.Name == node.Name {
+				return r.machinePoolToMachineHealthCheck(mp)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestMachinePoolTargetHealthCheck(t *testing.T) {
+	now := time.Now()
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test-mhc", Name: "test-cluster"},
+	}
+	conditions.MarkTrue(cluster, clusterv1.InfrastructureReadyCondition)
+	conditions.MarkTrue(cluster, clusterv1.ControlPlaneInitializedCondition)
+
+	mhc := &clusterv1.MachineHealthCheck{
+		Spec: clusterv1.MachineHealthCheckSpec{
+			UnhealthyConditions: []clusterv1.UnhealthyCondition{
+				{Type: corev1.NodeReady, Status: corev1.ConditionUnknown, Timeout: metav1.Duration{Duration: 5 * time.Minute}},
+			},
+		},
+	}
+
+	newNode := func(name, providerID string, status corev1.ConditionStatus, since time.Duration) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       corev1.NodeSpec{ProviderID: providerID},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{
+					{Type: corev1.NodeReady, Status: status, LastTransitionTime: metav1.NewTime(now.Add(-since))},
+				},
+			},
+		}
+	}
+
+	target := &machinePoolTarget{
+		MachinePool: &expv1.MachinePool{ObjectMeta: metav1.ObjectMeta{Name: "test-mp"}},
+		Nodes: []*corev1.Node{
+			newNode("healthy", "cloud:///healthy", corev1.ConditionTrue, time.Hour),
+			newNode("unhealthy-b", "cloud:///unhealthy-b", corev1.ConditionUnknown, 10*time.Minute),
+			newNode("unhealthy-a", "cloud:///unhealthy-a", corev1.ConditionUnknown, 10*time.Minute),
+			newNode("likely-unhealthy", "cloud:///likely-unhealthy", corev1.ConditionUnknown, time.Minute),
+			newNode("without-provider-id", "", corev1.ConditionUnknown, 10*time.Minute),
+		},
+	}
+
+	t.Run("reports the provider IDs of the unhealthy nodes", func(t *testing.T) {
+		g := NewWithT(t)
+
+		healthy, unhealthy, nextCheck := target.healthCheck(ctrl.LoggerFrom(ctx), cluster, mhc, now)
+		g.Expect(healthy).To(Equal(1))
+		g.Expect(unhealthy).To(Equal([]string{"cloud:///unhealthy-a", "cloud:///unhealthy-b"}))
+		g.Expect(nextCheck).To(Equal(4*time.Minute + time.Second))
+	})
+
+	t.Run("does not evaluate the nodes until the control plane is initialized", func(t *testing.T) {
+		g := NewWithT(t)
+
+		notInitialized := cluster.DeepCopy()
+		conditions.MarkFalse(notInitialized, clusterv1.ControlPlaneInitializedCondition, "", clusterv1.ConditionSeverityInfo, "")
+
+		healthy, unhealthy, nextCheck := target.healthCheck(ctrl.LoggerFrom(ctx), notInitialized, mhc, now)
+		g.Expect(healthy).To(Equal(0))
+		g.Expect(unhealthy).To(BeEmpty())
+		g.Expect(nextCheck).To(BeZero())
+	})
+}
+
+func TestGetMachinePoolTargetsFromMHC(t *testing.T) {
+	g := NewWithT(t)
+
+	namespace := "test-mhc"
+	mhc := &clusterv1.MachineHealthCheck{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "test-mhc"},
+		Spec: clusterv1.MachineHealthCheckSpec{
+			ClusterName: "test-cluster",
+			Selector:    metav1.LabelSelector{MatchLabels: map[string]string{"pool": "foo"}},
+		},
+	}
+	newMachinePool := func(name string, labels map[string]string, nodeNames ...string) *expv1.MachinePool {
+		mp := &expv1.MachinePool{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: labels},
+			Spec:       expv1.MachinePoolSpec{ClusterName: "test-cluster"},
+		}
+		for _, n := range nodeNames {
+			mp.Status.NodeRefs = append(mp.Status.NodeRefs, corev1.ObjectReference{Kind: "Node", Name: n})
+		}
+		return mp
+	}
+
+	matching := newMachinePool("matching", map[string]string{"pool": "foo", clusterv1.ClusterLabelName: "test-cluster"}, "node-1", "node-2")
+	otherCluster := newMachinePool("other-cluster", map[string]string{"pool": "foo", clusterv1.ClusterLabelName: "other-cluster"}, "node-3")
+	skipped := newMachinePool("skipped", map[string]string{"pool": "foo", clusterv1.ClusterLabelName: "test-cluster"}, "node-3")
+	skipped.Annotations = map[string]string{clusterv1.MachineSkipRemediationAnnotation: ""}
+
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(mhc, matching, otherCluster, skipped).Build()
+	// node-2 is not found, e.g. because the instance has been deleted.
+	remoteClient := fake.NewClientBuilder().WithObjects(
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-3"}},
+	).Build()
+
+	r := &MachineHealthCheckReconciler{Client: c}
+	targets, err := r.getMachinePoolTargetsFromMHC(ctx, ctrl.LoggerFrom(ctx), remoteClient, mhc)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(targets).To(HaveLen(1))
+	g.Expect(targets[0].MachinePool.Name).To(Equal("matching"))
+	g.Expect(targets[0].Nodes).To(HaveLen(1))
+	g.Expect(targets[0].Nodes[0].Name).To(Equal("node-1"))
+
+	// The provider IDs to remediate are reported in the MachinePool status, and cleared when nil.
+	g.Expect(r.patchMachinePoolTargets(ctx, targets, map[string][]string{"matching": {"cloud:///node-1"}})).To(BeEmpty())
+	mp := &expv1.MachinePool{}
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(matching), mp)).To(Succeed())
+	g.Expect(mp.Status.ProviderIDsToRemediate).To(Equal([]string{"cloud:///node-1"}))
+
+	targets, err = r.getMachinePoolTargetsFromMHC(ctx, ctrl.LoggerFrom(ctx), remoteClient, mhc)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(r.patchMachinePoolTargets(ctx, targets, nil)).To(BeEmpty())
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(matching), mp)).To(Succeed())
+	g.Expect(mp.Status.ProviderIDsToRemediate).To(BeEmpty())
+
+	// MachinePools are mapped to the MachineHealthChecks selecting them, only if TargetMachinePools is enabled.
+	g.Expect(r.machinePoolToMachineHealthCheck(matching)).To(BeEmpty())
+	mhc.Labels = map[string]string{clusterv1.ClusterLabelName: "test-cluster"}
+	g.Expect(c.Update(ctx, mhc)).To(Succeed())
+	g.Expect(r.machinePoolToMachineHealthCheck(matching)).To(BeEmpty())
+	mhc.Spec.TargetMachinePools = true
+	g.Expect(c.Update(ctx, mhc)).To(Succeed())
+	g.Expect(r.machinePoolToMachineHealthCheck(matching)).To(HaveLen(1))
+	g.Expect(r.machinePoolToMachineHealthCheck(otherCluster)).To(BeEmpty())
+
+	// Nodes are mapped through the MachinePools of their cluster only.
+	cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "test-cluster"}}
+	g.Expect(r.nodeToMachinePoolMachineHealthCheck(cluster, &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}})).To(HaveLen(1))
+	otherClusterObj := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "other-cluster"}}
+	g.Expect(r.nodeToMachinePoolMachineHealthCheck(otherClusterObj, &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}})).To(BeEmpty())
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/util/annotations"
+	"sigs.k8s.io/cluster-api/util/conditions"
+)
+
+// splitByRemediationRateLimit splits the unhealthy targets into the targets whose remediation is allowed by the
+// remediation rate limit of the MachineHealthCheck, the targets for which remediation has already been triggered, and
+// the targets for which remediation must be deferred because of the rate limit.
+// Targets for which remediation has already been triggered are never deferred, because they do not count against the limit.
+func (r *MachineHealthCheckReconciler) splitByRemediationRateLimit(ctx context.Context, cluster *clusterv1.Cluster, m *clusterv1.MachineHealthCheck, unhealthy []healthCheckTarget, now time.Time) ([]healthCheckTarget, []healthCheckTarget, []healthCheckTarget, time.Duration) {
+	pending := []healthCheckTarget{}
+	inProgress := []healthCheckTarget{}
+	for _, t := range unhealthy {
+		if r.requiresNewRemediation(ctx, cluster, m, t) {
+			pending = append(pending, t)
+			continue
+		}
+		inProgress = append(inProgress, t)
+	}
+
+	allowedCount, requeueAfter := reconcileRemediationRateLimit(m, len(pending), now)
+	return pending[:allowedCount], inProgress, pending[allowedCount:], requeueAfter
+}
+
+// patchRemediationTargets triggers the remediation of the targets allowed by the remediation rate limit, recording each
+// remediation in the rate limit status only once the target has been patched, so failed attempts do not consume the
+// remediation budget.
+func (r *MachineHealthCheckReconciler) patchRemediationTargets(ctx context.Context, logger logr.Logger, targets []healthCheckTarget, cluster *clusterv1.Cluster, m *clusterv1.MachineHealthCheck, now time.Time) []error {
+	errList := []error{}
+	for _, t := range targets {
+		errs := r.patchUnhealthyTargets(ctx, logger, []healthCheckTarget{t}, cluster, m)
+		if len(errs) > 0 {
+			errList = append(errList, errs...)
+			continue
+		}
+		m.Status.RemediationRateLimit.RecentRemediations = append(m.Status.RemediationRateLimit.RecentRemediations, metav1.NewTime(now))
+	}
+	return errList
+}
+
+// requiresNewRemediation returns true if remediation has not yet been triggered for an unhealthy target.
+func (r *MachineHealthCheckReconciler) requiresNewRemediation(ctx context.Context, cluster *clusterv1.Cluster, m *clusterv1.MachineHealthCheck, t healthCheckTarget) bool {
+	if annotations.IsPaused(cluster, t.Machine) {
+		return false
+	}
+	if m.Spec.RemediationTemplate != nil {
+		return !r.externalRemediationRequestExists(ctx, m, t.Machine.Name)
+	}
+	return !conditions.Has(t.Machine, clusterv1.MachineOwnerRemediatedCondition) || conditions.IsTrue(t.Machine, clusterv1.MachineOwnerRemediatedCondition)
+}
+
+// reconcileRemediationRateLimit updates the remediation rate limit status and the circuit breaker condition of the
+// MachineHealthCheck, and returns how many of the pending remediations are allowed; the allowed remediations are recorded
+// in the status by patchRemediationTargets once triggered.
+// If some remediations are deferred, it also returns the time after which the rate limit should be evaluated again.
+func reconcileRemediationRateLimit(m *clusterv1.MachineHealthCheck, pending int, now time.Time) (int, time.Duration) {
+	rateLimit := m.Spec.RemediationRateLimit
+	window := clusterv1.DefaultRemediationRateLimitWindow.Duration
+	if rateLimit.Window != nil {
+		window = rateLimit.Window.Duration
+	}
+
+	if m.Status.RemediationRateLimit == nil {
+		m.Status.RemediationRateLimit = &clusterv1.RemediationRateLimitStatus{}
+	}
+	status := m.Status.RemediationRateLimit
+
+	// Forget about remediations triggered before the current window.
+	recentRemediations := []metav1.Time{}
+	for _, t := range status.RecentRemediations {
+		if now.Sub(t.Time) < window {
+			recentRemediations = append(recentRemediations, t)
+		}
+	}
+	status.RecentRemediations = recentRemediations
+
+	// Breaches are consecutive only if the limit has been reached in the previous window too.
+	if status.LastBreachTime != nil && now.Sub(status.LastBreachTime.Time) >= 2*window {
+		status.ConsecutiveBreaches = 0
+	}
+
+	if rateLimit.CircuitBreakerThreshold == nil {
+		conditions.Delete(m, clusterv1.RemediationCircuitBreakerClosedCondition)
+	} else {
+		// If the circuit breaker is open, all the remediations are paused until the cooldown expires.
+		if conditions.IsFalse(m, clusterv1.RemediationCircuitBreakerClosedCondition) {
+			cooldown := window
+			if rateLimit.CircuitBreakerCooldown != nil {
+				cooldown = rateLimit.CircuitBreakerCooldown.Duration
+			}
+			if openedAt := conditions.GetLastTransitionTime(m, clusterv1.RemediationCircuitBreakerClosedCondition); openedAt != nil && now.Sub(openedAt.Time) < cooldown {
+				return 0, cooldown - now.Sub(openedAt.Time)
+			}
+			status.ConsecutiveBreaches = 0
+		}
+		conditions.MarkTrue(m, clusterv1.RemediationCircuitBreakerClosedCondition)
+	}
+
+	allowed := int(rateLimit.MaxRemediations) - len(status.RecentRemediations)
+	if allowed < 0 {
+		allowed = 0
+	}
+	if pending < allowed {
+		allowed = pending
+	}
+
+	if pending == allowed {
+		return allowed, 0
+	}
+
+	// The limit has been reached, count a new breach once per window.
+	if status.LastBreachTime == nil || now.Sub(status.LastBreachTime.Time) >= window {
+		status.ConsecutiveBreaches++
+	}
+	status.LastBreachTime = &metav1.Time{Time: now}
+
+	if rateLimit.CircuitBreakerThreshold != nil && status.ConsecutiveBreaches >= *rateLimit.CircuitBreakerThreshold {
+		conditions.MarkFalse(m, clusterv1.RemediationCircuitBreakerClosedCondition, clusterv1.CircuitBreakerOpenReason, clusterv1.ConditionSeverityWarning,
+			"Remediation is paused, the remediation rate limit has been reached in %d consecutive windows", status.ConsecutiveBreaches)
+	}
+
+	// The next remediation is allowed as soon as the oldest remediation in the window expires; if there are none
+	// yet, all the allowed remediations are triggered now.
+	requeueAfter := window
+	if len(status.RecentRemediations) > 0 {
+		requeueAfter = status.RecentRemediations[0].Add(window).Sub(now)
+	}
+	return allowed, requeueAfter
+}
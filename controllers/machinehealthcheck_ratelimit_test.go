@@ -0,0 +1,205 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestReconcileRemediationRateLimit(t *testing.T) {
+	now := time.Now()
+
+	newMHC := func(threshold *int32, recentRemediations ...time.Time) *clusterv1.MachineHealthCheck {
+		m := &clusterv1.MachineHealthCheck{
+			Spec: clusterv1.MachineHealthCheckSpec{
+				RemediationRateLimit: &clusterv1.RemediationRateLimit{
+					MaxRemediations:         2,
+					Window:                  &metav1.Duration{Duration: time.Hour},
+					CircuitBreakerThreshold: threshold,
+				},
+			},
+		}
+		if len(recentRemediations) > 0 {
+			m.Status.RemediationRateLimit = &clusterv1.RemediationRateLimitStatus{}
+			for _, r := range recentRemediations {
+				m.Status.RemediationRateLimit.RecentRemediations = append(m.Status.RemediationRateLimit.RecentRemediations, metav1.NewTime(r))
+			}
+		}
+		return m
+	}
+
+	t.Run("allows all the pending remediations within the limit", func(t *testing.T) {
+		g := NewWithT(t)
+
+		m := newMHC(nil)
+		allowed, requeueAfter := reconcileRemediationRateLimit(m, 2, now)
+		g.Expect(allowed).To(Equal(2))
+		g.Expect(requeueAfter).To(BeZero())
+		// The allowed remediations are recorded only once triggered.
+		g.Expect(m.Status.RemediationRateLimit.RecentRemediations).To(BeEmpty())
+		g.Expect(m.Status.RemediationRateLimit.ConsecutiveBreaches).To(BeZero())
+		g.Expect(conditions.Has(m, clusterv1.RemediationCircuitBreakerClosedCondition)).To(BeFalse())
+	})
+
+	t.Run("forgets remediations outside of the window", func(t *testing.T) {
+		g := NewWithT(t)
+
+		m := newMHC(nil, now.Add(-2*time.Hour), now.Add(-90*time.Minute))
+		allowed, requeueAfter := reconcileRemediationRateLimit(m, 1, now)
+		g.Expect(allowed).To(Equal(1))
+		g.Expect(requeueAfter).To(BeZero())
+		g.Expect(m.Status.RemediationRateLimit.RecentRemediations).To(BeEmpty())
+	})
+
+	t.Run("defers remediations exceeding the limit and records a breach", func(t *testing.T) {
+		g := NewWithT(t)
+
+		m := newMHC(nil, now.Add(-30*time.Minute))
+		allowed, requeueAfter := reconcileRemediationRateLimit(m, 3, now)
+		g.Expect(allowed).To(Equal(1))
+		g.Expect(requeueAfter).To(Equal(30 * time.Minute))
+		g.Expect(m.Status.RemediationRateLimit.RecentRemediations).To(HaveLen(1))
+		g.Expect(m.Status.RemediationRateLimit.ConsecutiveBreaches).To(Equal(int32(1)))
+		g.Expect(m.Status.RemediationRateLimit.LastBreachTime).ToNot(BeNil())
+	})
+
+	t.Run("counts a single breach per window", func(t *testing.T) {
+		g := NewWithT(t)
+
+		m := newMHC(nil, now.Add(-30*time.Minute), now.Add(-20*time.Minute))
+		m.Status.RemediationRateLimit.ConsecutiveBreaches = 1
+		m.Status.RemediationRateLimit.LastBreachTime = &metav1.Time{Time: now.Add(-10 * time.Minute)}
+		allowed, _ := reconcileRemediationRateLimit(m, 1, now)
+		g.Expect(allowed).To(BeZero())
+		g.Expect(m.Status.RemediationRateLimit.ConsecutiveBreaches).To(Equal(int32(1)))
+	})
+
+	t.Run("resets breaches if the limit was not reached in the previous window", func(t *testing.T) {
+		g := NewWithT(t)
+
+		m := newMHC(nil)
+		m.Status.RemediationRateLimit = &clusterv1.RemediationRateLimitStatus{
+			ConsecutiveBreaches: 3,
+			LastBreachTime:      &metav1.Time{Time: now.Add(-3 * time.Hour)},
+		}
+		allowed, _ := reconcileRemediationRateLimit(m, 1, now)
+		g.Expect(allowed).To(Equal(1))
+		g.Expect(m.Status.RemediationRateLimit.ConsecutiveBreaches).To(BeZero())
+	})
+
+	t.Run("opens the circuit breaker when breaches reach the threshold", func(t *testing.T) {
+		g := NewWithT(t)
+
+		m := newMHC(pointer.Int32Ptr(2), now.Add(-30*time.Minute), now.Add(-20*time.Minute))
+		m.Status.RemediationRateLimit.ConsecutiveBreaches = 1
+		m.Status.RemediationRateLimit.LastBreachTime = &metav1.Time{Time: now.Add(-70 * time.Minute)}
+		allowed, _ := reconcileRemediationRateLimit(m, 1, now)
+		g.Expect(allowed).To(BeZero())
+		g.Expect(m.Status.RemediationRateLimit.ConsecutiveBreaches).To(Equal(int32(2)))
+		g.Expect(conditions.IsFalse(m, clusterv1.RemediationCircuitBreakerClosedCondition)).To(BeTrue())
+		g.Expect(conditions.GetReason(m, clusterv1.RemediationCircuitBreakerClosedCondition)).To(Equal(clusterv1.CircuitBreakerOpenReason))
+	})
+
+	t.Run("pauses all the remediations while the circuit breaker is open", func(t *testing.T) {
+		g := NewWithT(t)
+
+		m := newMHC(pointer.Int32Ptr(2))
+		conditions.Set(m, &clusterv1.Condition{
+			Type:               clusterv1.RemediationCircuitBreakerClosedCondition,
+			Status:             "False",
+			Reason:             clusterv1.CircuitBreakerOpenReason,
+			LastTransitionTime: metav1.NewTime(now.Add(-10 * time.Minute)),
+		})
+		allowed, requeueAfter := reconcileRemediationRateLimit(m, 1, now)
+		g.Expect(allowed).To(BeZero())
+		g.Expect(requeueAfter).To(Equal(50 * time.Minute))
+		g.Expect(conditions.IsFalse(m, clusterv1.RemediationCircuitBreakerClosedCondition)).To(BeTrue())
+	})
+
+	t.Run("closes the circuit breaker when the cooldown expires", func(t *testing.T) {
+		g := NewWithT(t)
+
+		m := newMHC(pointer.Int32Ptr(2))
+		m.Status.RemediationRateLimit = &clusterv1.RemediationRateLimitStatus{
+			ConsecutiveBreaches: 2,
+			LastBreachTime:      &metav1.Time{Time: now.Add(-90 * time.Minute)},
+		}
+		conditions.Set(m, &clusterv1.Condition{
+			Type:               clusterv1.RemediationCircuitBreakerClosedCondition,
+			Status:             "False",
+			Reason:             clusterv1.CircuitBreakerOpenReason,
+			LastTransitionTime: metav1.NewTime(now.Add(-90 * time.Minute)),
+		})
+		allowed, requeueAfter := reconcileRemediationRateLimit(m, 1, now)
+		g.Expect(allowed).To(Equal(1))
+		g.Expect(requeueAfter).To(BeZero())
+		g.Expect(m.Status.RemediationRateLimit.ConsecutiveBreaches).To(BeZero())
+		g.Expect(conditions.IsTrue(m, clusterv1.RemediationCircuitBreakerClosedCondition)).To(BeTrue())
+	})
+}
+
+func TestPatchRemediationTargets(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testClusterName,
+			Namespace: defaultNamespaceName,
+		},
+	}
+	labels := map[string]string{"cluster": "foo", "nodepool": "bar"}
+
+	mhc := newMachineHealthCheckWithLabels("mhc", defaultNamespaceName, testClusterName, labels)
+	mhc.Status.RemediationRateLimit = &clusterv1.RemediationRateLimitStatus{}
+	machine1 := newTestMachine("machine1", defaultNamespaceName, testClusterName, "nodeName", labels)
+	conditions.MarkFalse(machine1, clusterv1.MachineHealthCheckSuccededCondition, clusterv1.NodeNotFoundReason, clusterv1.ConditionSeverityWarning, "")
+	machine2 := machine1.DeepCopy()
+	machine2.Name = "machine2"
+
+	cl := fake.NewClientBuilder().WithObjects(machine1, machine2, mhc).Build()
+	r := &MachineHealthCheckReconciler{
+		Client:   cl,
+		recorder: record.NewFakeRecorder(32),
+	}
+
+	// To make the patch fail, create the patch helper of the first target with a different client.
+	failingPatchHelper, err := patch.NewHelper(machine1, fake.NewClientBuilder().Build())
+	g.Expect(err).ToNot(HaveOccurred())
+	patchHelper, err := patch.NewHelper(machine2, cl)
+	g.Expect(err).ToNot(HaveOccurred())
+	targets := []healthCheckTarget{
+		{MHC: mhc, Machine: machine1, patchHelper: failingPatchHelper, Node: &corev1.Node{}},
+		{MHC: mhc, Machine: machine2, patchHelper: patchHelper, Node: &corev1.Node{}},
+	}
+
+	// Only the remediation triggered successfully is counted against the rate limit.
+	g.Expect(r.patchRemediationTargets(ctx, log.NullLogger{}, targets, cluster, mhc, time.Now())).To(HaveLen(1))
+	g.Expect(mhc.Status.RemediationRateLimit.RecentRemediations).To(HaveLen(1))
+}
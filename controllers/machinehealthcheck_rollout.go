@@ -0,0 +1,149 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/controllers/external"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// rolloutRemediationCheckInterval is the interval after which remediations paused because of a rollout are evaluated again.
+	rolloutRemediationCheckInterval = 30 * time.Second
+
+	// maxRolloutRemediationPause is the maximum amount of time the remediation of an unhealthy machine is paused because
+	// of a rollout; after that the machine is remediated anyway, given that an unhealthy machine could be what is blocking
+	// the rollout, e.g. the KubeadmControlPlane preflight checks do not let a rollout proceed with an unhealthy control plane.
+	maxRolloutRemediationPause = 10 * time.Minute
+
+	// controlPlaneMachinesSpecUpToDateCondition is the condition used by control plane providers, e.g. the KubeadmControlPlane,
+	// to report if the spec of the control plane machines is up to date; when False, a rollout is in progress.
+	controlPlaneMachinesSpecUpToDateCondition clusterv1.ConditionType = "MachinesSpecUpToDate"
+)
+
+// rolloutChecker checks if the owner of a machine is in the middle of a rollout, caching the result for each owner.
+type rolloutChecker struct {
+	client  client.Client
+	cluster *clusterv1.Cluster
+
+	machineDeployments map[string]bool
+	controlPlane       *bool
+}
+
+func newRolloutChecker(c client.Client, cluster *clusterv1.Cluster) *rolloutChecker {
+	return &rolloutChecker{
+		client:             c,
+		cluster:            cluster,
+		machineDeployments: map[string]bool{},
+	}
+}
+
+// splitByOwnerRollout splits the unhealthy targets into the targets that can be remediated and the targets for which
+// remediation is paused because the MachineDeployment or the control plane owning the machine is in the middle of a rollout,
+// so nodes briefly NotReady during upgrades do not lead to duplicate deletions.
+// Nb. Machines without a node are never paused, so machines failing to start during a rollout are still remediated;
+// also machines unhealthy for longer than maxRolloutRemediationPause are not paused anymore, so a machine blocking
+// the rollout is eventually remediated.
+func (r *MachineHealthCheckReconciler) splitByOwnerRollout(ctx context.Context, cluster *clusterv1.Cluster, unhealthy []healthCheckTarget, now time.Time) ([]healthCheckTarget, []healthCheckTarget, error) {
+	checker := newRolloutChecker(r.Client, cluster)
+
+	allowed := []healthCheckTarget{}
+	paused := []healthCheckTarget{}
+	for _, t := range unhealthy {
+		if t.Machine.Status.NodeRef == nil || t.nodeMissing {
+			allowed = append(allowed, t)
+			continue
+		}
+
+		if unhealthySince := conditions.GetLastTransitionTime(t.Machine, clusterv1.MachineHealthCheckSuccededCondition); unhealthySince != nil &&
+			conditions.IsFalse(t.Machine, clusterv1.MachineHealthCheckSuccededCondition) &&
+			now.Sub(unhealthySince.Time) >= maxRolloutRemediationPause {
+			allowed = append(allowed, t)
+			continue
+		}
+
+		inProgress, err := checker.isRolloutInProgress(ctx, t.Machine)
+		if err != nil {
+			return nil, nil, err
+		}
+		if inProgress {
+			paused = append(paused, t)
+			continue
+		}
+		allowed = append(allowed, t)
+	}
+	return allowed, paused, nil
+}
+
+// isRolloutInProgress returns true if the MachineDeployment or the control plane owning the machine is in the middle of a rollout.
+func (c *rolloutChecker) isRolloutInProgress(ctx context.Context, machine *clusterv1.Machine) (bool, error) {
+	if util.IsControlPlaneMachine(machine) {
+		return c.isControlPlaneRolloutInProgress(ctx)
+	}
+	if name, ok := machine.Labels[clusterv1.MachineDeploymentLabelName]; ok {
+		return c.isMachineDeploymentRolloutInProgress(ctx, machine.Namespace, name)
+	}
+	return false, nil
+}
+
+func (c *rolloutChecker) isMachineDeploymentRolloutInProgress(ctx context.Context, namespace, name string) (bool, error) {
+	if inProgress, ok := c.machineDeployments[name]; ok {
+		return inProgress, nil
+	}
+
+	md := &clusterv1.MachineDeployment{}
+	if err := c.client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, md); err != nil {
+		if apierrors.IsNotFound(err) {
+			c.machineDeployments[name] = false
+			return false, nil
+		}
+		return false, errors.Wrapf(err, "failed to get MachineDeployment %s/%s", namespace, name)
+	}
+
+	// A rollout is in progress while there are machines not yet updated to the latest MachineSet.
+	inProgress := md.Status.UpdatedReplicas < md.Status.Replicas
+	c.machineDeployments[name] = inProgress
+	return inProgress, nil
+}
+
+func (c *rolloutChecker) isControlPlaneRolloutInProgress(ctx context.Context) (bool, error) {
+	if c.controlPlane != nil {
+		return *c.controlPlane, nil
+	}
+
+	inProgress := false
+	if c.cluster.Spec.ControlPlaneRef != nil {
+		controlPlane, err := external.Get(ctx, c.client, c.cluster.Spec.ControlPlaneRef, c.cluster.Namespace)
+		switch {
+		case apierrors.IsNotFound(errors.Cause(err)):
+		case err != nil:
+			return false, errors.Wrapf(err, "failed to get control plane for Cluster %s/%s", c.cluster.Namespace, c.cluster.Name)
+		default:
+			inProgress = conditions.IsFalse(conditions.UnstructuredGetter(controlPlane), controlPlaneMachinesSpecUpToDateCondition)
+		}
+	}
+	c.controlPlane = &inProgress
+	return inProgress, nil
+}
@@ -0,0 +1,179 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/scheme"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestSplitByOwnerRollout(t *testing.T) {
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"},
+		Spec: clusterv1.ClusterSpec{
+			ControlPlaneRef: &corev1.ObjectReference{
+				APIVersion: "controlplane.cluster.x-k8s.io/v1alpha4",
+				Kind:       "GenericControlPlane",
+				Name:       "test-control-plane",
+			},
+		},
+	}
+
+	controlPlane := func(upToDate corev1.ConditionStatus) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{}
+		u.SetAPIVersion("controlplane.cluster.x-k8s.io/v1alpha4")
+		u.SetKind("GenericControlPlane")
+		u.SetNamespace("default")
+		u.SetName("test-control-plane")
+		_ = unstructured.SetNestedSlice(u.Object, []interface{}{
+			map[string]interface{}{"type": string(controlPlaneMachinesSpecUpToDateCondition), "status": string(upToDate)},
+		}, "status", "conditions")
+		return u
+	}
+
+	machineDeployment := func(name string, replicas, updatedReplicas int32) *clusterv1.MachineDeployment {
+		return &clusterv1.MachineDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Status: clusterv1.MachineDeploymentStatus{
+				Replicas:        replicas,
+				UpdatedReplicas: updatedReplicas,
+			},
+		}
+	}
+
+	target := func(name string, labels map[string]string, hasNode bool) healthCheckTarget {
+		m := &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: labels},
+		}
+		if hasNode {
+			m.Status.NodeRef = &corev1.ObjectReference{Name: name}
+		}
+		return healthCheckTarget{Machine: m}
+	}
+
+	unhealthyTarget := func(name string, labels map[string]string, unhealthyFor time.Duration) healthCheckTarget {
+		t := target(name, labels, true)
+		conditions.Set(t.Machine, &clusterv1.Condition{
+			Type:               clusterv1.MachineHealthCheckSuccededCondition,
+			Status:             corev1.ConditionFalse,
+			Severity:           clusterv1.ConditionSeverityWarning,
+			Reason:             clusterv1.UnhealthyNodeConditionReason,
+			LastTransitionTime: metav1.NewTime(time.Now().Add(-unhealthyFor)),
+		})
+		return t
+	}
+
+	targetNames := func(targets []healthCheckTarget) []string {
+		names := []string{}
+		for _, t := range targets {
+			names = append(names, t.Machine.Name)
+		}
+		return names
+	}
+
+	tests := []struct {
+		name        string
+		objs        []client.Object
+		unhealthy   []healthCheckTarget
+		wantAllowed []string
+		wantPaused  []string
+	}{
+		{
+			name: "pauses machines owned by a MachineDeployment in the middle of a rollout",
+			objs: []client.Object{machineDeployment("md-rolling", 4, 2), machineDeployment("md-stable", 3, 3)},
+			unhealthy: []healthCheckTarget{
+				target("m1", map[string]string{clusterv1.MachineDeploymentLabelName: "md-rolling"}, true),
+				target("m2", map[string]string{clusterv1.MachineDeploymentLabelName: "md-stable"}, true),
+				target("m3", nil, true),
+			},
+			wantAllowed: []string{"m2", "m3"},
+			wantPaused:  []string{"m1"},
+		},
+		{
+			name: "pauses control plane machines if the control plane is in the middle of a rollout",
+			objs: []client.Object{controlPlane(corev1.ConditionFalse)},
+			unhealthy: []healthCheckTarget{
+				target("m1", map[string]string{clusterv1.MachineControlPlaneLabelName: ""}, true),
+			},
+			wantAllowed: []string{},
+			wantPaused:  []string{"m1"},
+		},
+		{
+			name: "does not pause control plane machines if the control plane machines are up to date",
+			objs: []client.Object{controlPlane(corev1.ConditionTrue)},
+			unhealthy: []healthCheckTarget{
+				target("m1", map[string]string{clusterv1.MachineControlPlaneLabelName: ""}, true),
+			},
+			wantAllowed: []string{"m1"},
+			wantPaused:  []string{},
+		},
+		{
+			name: "does not pause machines unhealthy for longer than the maximum pause",
+			objs: []client.Object{controlPlane(corev1.ConditionFalse), machineDeployment("md-rolling", 4, 2)},
+			unhealthy: []healthCheckTarget{
+				unhealthyTarget("m1", map[string]string{clusterv1.MachineControlPlaneLabelName: ""}, maxRolloutRemediationPause+time.Minute),
+				unhealthyTarget("m2", map[string]string{clusterv1.MachineDeploymentLabelName: "md-rolling"}, maxRolloutRemediationPause+time.Minute),
+				unhealthyTarget("m3", map[string]string{clusterv1.MachineDeploymentLabelName: "md-rolling"}, time.Minute),
+			},
+			wantAllowed: []string{"m1", "m2"},
+			wantPaused:  []string{"m3"},
+		},
+		{
+			name: "does not pause machines without a node",
+			objs: []client.Object{machineDeployment("md-rolling", 4, 2)},
+			unhealthy: []healthCheckTarget{
+				target("m1", map[string]string{clusterv1.MachineDeploymentLabelName: "md-rolling"}, false),
+			},
+			wantAllowed: []string{"m1"},
+			wantPaused:  []string{},
+		},
+		{
+			name: "does not pause machines if the owner does not exist",
+			unhealthy: []healthCheckTarget{
+				target("m1", map[string]string{clusterv1.MachineDeploymentLabelName: "md-missing"}, true),
+				target("m2", map[string]string{clusterv1.MachineControlPlaneLabelName: ""}, true),
+			},
+			wantAllowed: []string{"m1", "m2"},
+			wantPaused:  []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			r := &MachineHealthCheckReconciler{
+				Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(tt.objs...).Build(),
+			}
+
+			allowed, paused, err := r.splitByOwnerRollout(ctx, cluster, tt.unhealthy, time.Now())
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(targetNames(allowed)).To(Equal(tt.wantAllowed))
+			g.Expect(targetNames(paused)).To(Equal(tt.wantPaused))
+		})
+	}
+}
@@ -87,7 +87,6 @@ func (t *healthCheckTarget) nodeName() string {
 // which the target should next be checked.
 // The target should be requeued after this duration.
 func (t *healthCheckTarget) needsRemediation(logger logr.Logger, timeoutForMachineToHaveNode metav1.Duration) (bool, time.Duration) {
-	var nextCheckTimes []time.Duration
 	now := time.Now()
 
 	if t.Machine.Status.FailureReason != nil {
@@ -159,8 +158,22 @@ func (t *healthCheckTarget) needsRemediation(logger logr.Logger, timeoutForMachi
 	}
 
 	// check conditions
-	for _, c := range t.MHC.Spec.UnhealthyConditions {
-		nodeCondition := getNodeCondition(t.Node, c.Type)
+	c, nextCheck := unhealthyNodeCondition(t.Node, t.MHC.Spec.UnhealthyConditions, now)
+	if c != nil {
+		conditions.MarkFalse(t.Machine, clusterv1.MachineHealthCheckSuccededCondition, clusterv1.UnhealthyNodeConditionReason, clusterv1.ConditionSeverityWarning, "Condition %s on node is reporting status %s for more than %s", c.Type, c.Status, c.Timeout.Duration.String())
+		logger.V(3).Info("Target is unhealthy: condition is in state longer than allowed timeout", "condition", c.Type, "state", c.Status, "timeout", c.Timeout.Duration.String())
+		return true, time.Duration(0)
+	}
+	return false, nextCheck
+}
+
+// unhealthyNodeCondition returns the first unhealthy condition the node is matching for longer than the condition timeout, if any;
+// otherwise it returns the duration after which the node should be checked again, or zero if no condition is matched.
+func unhealthyNodeCondition(node *corev1.Node, unhealthyConditions []clusterv1.UnhealthyCondition, now time.Time) (*clusterv1.UnhealthyCondition, time.Duration) {
+	var nextCheckTimes []time.Duration
+	for i := range unhealthyConditions {
+		c := &unhealthyConditions[i]
+		nodeCondition := getNodeCondition(node, c.Type)
 
 		// Skip when current node condition is different from the one reported
 		// in the MachineHealthCheck.
@@ -169,11 +182,9 @@ func (t *healthCheckTarget) needsRemediation(logger logr.Logger, timeoutForMachi
 		}
 
 		// If the condition has been in the unhealthy state for longer than the
-		// timeout, return true with no requeue time.
+		// timeout, return the condition with no requeue time.
 		if nodeCondition.LastTransitionTime.Add(c.Timeout.Duration).Before(now) {
-			conditions.MarkFalse(t.Machine, clusterv1.MachineHealthCheckSuccededCondition, clusterv1.UnhealthyNodeConditionReason, clusterv1.ConditionSeverityWarning, "Condition %s on node is reporting status %s for more than %s", c.Type, c.Status, c.Timeout.Duration.String())
-			logger.V(3).Info("Target is unhealthy: condition is in state longer than allowed timeout", "condition", c.Type, "state", c.Status, "timeout", c.Timeout.Duration.String())
-			return true, time.Duration(0)
+			return c, time.Duration(0)
 		}
 
 		durationUnhealthy := now.Sub(nodeCondition.LastTransitionTime.Time)
@@ -182,7 +193,7 @@ func (t *healthCheckTarget) needsRemediation(logger logr.Logger, timeoutForMachi
 			nextCheckTimes = append(nextCheckTimes, nextCheck)
 		}
 	}
-	return false, minDuration(nextCheckTimes)
+	return nil, minDuration(nextCheckTimes)
 }
 
 // getTargetsFromMHC uses the MachineHealthCheck's selector to fetch machines
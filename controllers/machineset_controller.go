@@ -32,6 +32,7 @@ import (
 	"k8s.io/client-go/tools/record"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 	"sigs.k8s.io/cluster-api/controllers/external"
+	"sigs.k8s.io/cluster-api/controllers/mdutil"
 	"sigs.k8s.io/cluster-api/controllers/noderefutil"
 	"sigs.k8s.io/cluster-api/controllers/remote"
 	"sigs.k8s.io/cluster-api/util"
@@ -71,6 +72,14 @@ type MachineSetReconciler struct {
 	Tracker          *remote.ClusterCacheTracker
 	WatchFilterValue string
 
+	// CreationBatchSize is the default maximum number of Machines created in a single batch when scaling up;
+	// 0 means no limit. It can be overridden on each MachineSet with the MachineCreationBatchSizeAnnotation.
+	CreationBatchSize int
+
+	// CreationBatchInterval is the default minimum interval between two batches of Machine creations.
+	// It can be overridden on each MachineSet with the MachineCreationBatchIntervalAnnotation.
+	CreationBatchInterval time.Duration
+
 	recorder   record.EventRecorder
 	restConfig *rest.Config
 }
@@ -118,6 +127,7 @@ func (r *MachineSetReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		if apierrors.IsNotFound(err) {
 			// Object not found, return. Created objects are automatically garbage collected.
 			// For additional cleanup logic use finalizers.
+			deleteMachineSetMetrics(req.Namespace, req.Name)
 			return ctrl.Result{}, nil
 		}
 		// Error reading the object - requeue the request.
@@ -143,7 +153,12 @@ func (r *MachineSetReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 
 	defer func() {
 		// Always attempt to patch the object and status after each reconciliation.
-		if err := patchHelper.Patch(ctx, machineSet); err != nil {
+		// Patch ObservedGeneration only if the reconciliation completed successfully
+		patchOpts := []patch.Option{}
+		if reterr == nil {
+			patchOpts = append(patchOpts, patch.WithStatusObservedGeneration{})
+		}
+		if err := patchHelper.Patch(ctx, machineSet, patchOpts...); err != nil {
 			reterr = kerrors.NewAggregate([]error{reterr, err})
 		}
 	}()
@@ -185,6 +200,10 @@ func (r *MachineSetReconciler) reconcile(ctx context.Context, cluster *clusterv1
 	if err := reconcileExternalTemplateReference(ctx, r.Client, r.restConfig, cluster, &machineSet.Spec.Template.Spec.InfrastructureRef); err != nil {
 		return ctrl.Result{}, err
 	}
+	// Expose the extended resources provided by the Machines, if any.
+	if err := reconcileExtendedResourcesAnnotation(ctx, r.Client, machineSet, &machineSet.Spec.Template.Spec.InfrastructureRef); err != nil {
+		return ctrl.Result{}, err
+	}
 	// Make sure to reconcile the external bootstrap reference, if any.
 	if machineSet.Spec.Template.Spec.Bootstrap.ConfigRef != nil {
 		if err := reconcileExternalTemplateReference(ctx, r.Client, r.restConfig, cluster, machineSet.Spec.Template.Spec.Bootstrap.ConfigRef); err != nil {
@@ -269,10 +288,11 @@ func (r *MachineSetReconciler) reconcile(ctx context.Context, cluster *clusterv1
 		return ctrl.Result{}, errors.Wrap(err, "failed to remediate machines")
 	}
 
-	syncErr := r.syncReplicas(ctx, machineSet, filteredMachines)
+	syncResult, syncErr := r.syncReplicas(ctx, machineSet, filteredMachines)
 
 	// Always updates status as machines come up or die.
-	if err := r.updateStatus(ctx, cluster, machineSet, filteredMachines); err != nil {
+	statusResult, err := r.updateStatus(ctx, cluster, machineSet, filteredMachines)
+	if err != nil {
 		return ctrl.Result{}, errors.Wrapf(kerrors.NewAggregate([]error{err, syncErr}), "failed to update MachineSet's Status")
 	}
 
@@ -280,6 +300,11 @@ func (r *MachineSetReconciler) reconcile(ctx context.Context, cluster *clusterv1
 		return ctrl.Result{}, errors.Wrapf(syncErr, "failed to sync MachineSet replicas")
 	}
 
+	// Requeue for creating the next batch of machines, if any.
+	if !syncResult.IsZero() {
+		return syncResult, nil
+	}
+
 	var replicas int32
 	if machineSet.Spec.Replicas != nil {
 		replicas = *machineSet.Spec.Replicas
@@ -292,26 +317,28 @@ func (r *MachineSetReconciler) reconcile(ctx context.Context, cluster *clusterv1
 	// exceeds MinReadySeconds could be incorrect.
 	// To avoid an available replica stuck in the ready state, we force a reconcile after MinReadySeconds,
 	// at which point it should confirm any available replica to be available.
+	// NOTE: statusResult requeues when the next ready replica is expected to become available, so the
+	// available replicas, and thus the rollouts, progress as soon as MinReadySeconds have elapsed.
 	if machineSet.Spec.MinReadySeconds > 0 &&
 		machineSet.Status.ReadyReplicas == replicas &&
 		machineSet.Status.AvailableReplicas != replicas {
-		return ctrl.Result{RequeueAfter: time.Duration(machineSet.Spec.MinReadySeconds) * time.Second}, nil
+		return util.LowestNonZeroResult(ctrl.Result{RequeueAfter: time.Duration(machineSet.Spec.MinReadySeconds) * time.Second}, statusResult), nil
 	}
 
 	// Quickly reconcile until the nodes become Ready.
 	if machineSet.Status.ReadyReplicas != replicas {
 		log.V(4).Info("Some nodes are not ready yet, requeuing until they are ready")
-		return ctrl.Result{RequeueAfter: 15 * time.Second}, nil
+		return util.LowestNonZeroResult(ctrl.Result{RequeueAfter: 15 * time.Second}, statusResult), nil
 	}
 
 	return ctrl.Result{}, nil
 }
 
 // syncReplicas scales Machine resources up or down.
-func (r *MachineSetReconciler) syncReplicas(ctx context.Context, ms *clusterv1.MachineSet, machines []*clusterv1.Machine) error {
+func (r *MachineSetReconciler) syncReplicas(ctx context.Context, ms *clusterv1.MachineSet, machines []*clusterv1.Machine) (ctrl.Result, error) {
 	log := ctrl.LoggerFrom(ctx)
 	if ms.Spec.Replicas == nil {
-		return errors.Errorf("the Replicas field in Spec for machineset %v is nil, this should not be allowed", ms.Name)
+		return ctrl.Result{}, errors.Errorf("the Replicas field in Spec for machineset %v is nil, this should not be allowed", ms.Name)
 	}
 	diff := len(machines) - int(*(ms.Spec.Replicas))
 	switch {
@@ -321,9 +348,30 @@ func (r *MachineSetReconciler) syncReplicas(ctx context.Context, ms *clusterv1.M
 		if ms.Annotations != nil {
 			if _, ok := ms.Annotations[clusterv1.DisableMachineCreate]; ok {
 				log.V(2).Info("Automatic creation of new machines disabled for machine set")
-				return nil
+				return ctrl.Result{}, nil
 			}
 		}
+
+		// When creating machines in batches, wait for the batch interval to elapse since the newest machine
+		// has been created, and create at most a batch of machines; the remaining ones are created in the next batches.
+		var result ctrl.Result
+		if batchSize, batchInterval := r.machineCreationBatch(ctx, ms); batchSize > 0 {
+			if remaining := batchInterval - time.Since(newestMachineCreationTime(machines)); remaining > 0 {
+				log.Info("Waiting before creating the next batch of machines", "after", remaining)
+				return ctrl.Result{RequeueAfter: jitterMachineCreationBatchInterval(remaining)}, nil
+			}
+			if diff > batchSize {
+				log.Info("Creating machines in batches", "batchSize", batchSize, "remaining", diff-batchSize)
+				diff = batchSize
+				result = nextMachineCreationBatch(batchInterval)
+			}
+		}
+
+		templateHash, err := machineTemplateHash(ms)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+
 		var (
 			machineList []*clusterv1.Machine
 			errs        []error
@@ -350,7 +398,10 @@ func (r *MachineSetReconciler) syncReplicas(ctx context.Context, ms *clusterv1.M
 					Labels:      machine.Labels,
 				})
 				if err != nil {
-					return errors.Wrapf(err, "failed to clone bootstrap configuration for MachineSet %q in namespace %q", ms.Name, ms.Namespace)
+					log.Error(err, "Unable to clone bootstrap configuration", "template", machine.Spec.Bootstrap.ConfigRef.Name)
+					r.recorder.Eventf(ms, corev1.EventTypeWarning, "FailedCreate", "Failed to clone bootstrap configuration from %s %q: %v", machine.Spec.Bootstrap.ConfigRef.Kind, machine.Spec.Bootstrap.ConfigRef.Name, err)
+					recordMachineCreationFailure(ms, clusterv1.BootstrapTemplateCloningFailedReason, err)
+					return ctrl.Result{}, errors.Wrapf(err, "failed to clone bootstrap configuration for MachineSet %q in namespace %q", ms.Name, ms.Namespace)
 				}
 				machine.Spec.Bootstrap.ConfigRef = bootstrapRef
 			}
@@ -364,13 +415,18 @@ func (r *MachineSetReconciler) syncReplicas(ctx context.Context, ms *clusterv1.M
 				Annotations: machine.Annotations,
 			})
 			if err != nil {
-				return errors.Wrapf(err, "failed to clone infrastructure configuration for MachineSet %q in namespace %q", ms.Name, ms.Namespace)
+				log.Error(err, "Unable to clone infrastructure configuration", "template", machine.Spec.InfrastructureRef.Name)
+				r.recorder.Eventf(ms, corev1.EventTypeWarning, "FailedCreate", "Failed to clone infrastructure configuration from %s %q: %v", machine.Spec.InfrastructureRef.Kind, machine.Spec.InfrastructureRef.Name, err)
+				recordMachineCreationFailure(ms, clusterv1.InfrastructureTemplateCloningFailedReason, err)
+				return ctrl.Result{}, errors.Wrapf(err, "failed to clone infrastructure configuration for MachineSet %q in namespace %q", ms.Name, ms.Namespace)
 			}
 			machine.Spec.InfrastructureRef = *infraRef
+			machine.Annotations[clusterv1.MachineSetTemplateHashAnnotation] = templateHash
 
 			if err := r.Client.Create(ctx, machine); err != nil {
 				log.Error(err, "Unable to create Machine", "machine", machine.Name)
 				r.recorder.Eventf(ms, corev1.EventTypeWarning, "FailedCreate", "Failed to create machine %q: %v", machine.Name, err)
+				recordMachineCreationFailure(ms, clusterv1.MachineCreationFailedReason, err)
 				errs = append(errs, err)
 
 				// Try to cleanup the external objects if the Machine creation failed.
@@ -390,16 +446,18 @@ func (r *MachineSetReconciler) syncReplicas(ctx context.Context, ms *clusterv1.M
 			machineList = append(machineList, machine)
 		}
 
+		// The next batch, if any, is created only if all the machines in this batch have been created successfully.
 		if len(errs) > 0 {
-			return kerrors.NewAggregate(errs)
+			return ctrl.Result{}, kerrors.NewAggregate(errs)
 		}
-		return r.waitForMachineCreation(ctx, machineList)
+		conditions.MarkTrue(ms, clusterv1.MachinesCreatedCondition)
+		return result, r.waitForMachineCreation(ctx, machineList)
 	case diff > 0:
 		log.Info("Too many replicas", "need", *(ms.Spec.Replicas), "deleting", diff)
 
 		deletePriorityFunc, err := getDeletePriorityFunc(ms)
 		if err != nil {
-			return err
+			return ctrl.Result{}, err
 		}
 		log.Info("Found delete policy", "delete-policy", ms.Spec.DeletePolicy)
 
@@ -417,25 +475,30 @@ func (r *MachineSetReconciler) syncReplicas(ctx context.Context, ms *clusterv1.M
 		}
 
 		if len(errs) > 0 {
-			return kerrors.NewAggregate(errs)
+			return ctrl.Result{}, kerrors.NewAggregate(errs)
 		}
-		return r.waitForMachineDeletion(ctx, machinesToDelete)
+		return ctrl.Result{}, r.waitForMachineDeletion(ctx, machinesToDelete)
 	}
 
-	return nil
+	return ctrl.Result{}, nil
 }
 
 // getNewMachine creates a new Machine object. The name of the newly created resource is going
 // to be created by the API server, we set the generateName field.
 func (r *MachineSetReconciler) getNewMachine(machineSet *clusterv1.MachineSet) *clusterv1.Machine {
 	gv := clusterv1.GroupVersion
+	// Copy the annotations, so the annotations added to the Machine are not added to the MachineSet template.
+	machineAnnotations := make(map[string]string, len(machineSet.Spec.Template.Annotations))
+	for k, v := range machineSet.Spec.Template.Annotations {
+		machineAnnotations[k] = v
+	}
 	machine := &clusterv1.Machine{
 		ObjectMeta: metav1.ObjectMeta{
 			GenerateName:    fmt.Sprintf("%s-", machineSet.Name),
 			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(machineSet, machineSetKind)},
 			Namespace:       machineSet.Namespace,
 			Labels:          machineSet.Spec.Template.Labels,
-			Annotations:     machineSet.Spec.Template.Annotations,
+			Annotations:     machineAnnotations,
 		},
 		TypeMeta: metav1.TypeMeta{
 			Kind:       gv.WithKind("Machine").Kind,
@@ -450,6 +513,16 @@ func (r *MachineSetReconciler) getNewMachine(machineSet *clusterv1.MachineSet) *
 	return machine
 }
 
+// machineTemplateHash returns the hash of the machine template of the MachineSet, which is stored on the machines
+// created from it in the MachineSetTemplateHashAnnotation.
+func machineTemplateHash(ms *clusterv1.MachineSet) (string, error) {
+	hash, err := mdutil.ComputeSpewHash(&ms.Spec.Template)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to compute the machine template hash for MachineSet %s/%s", ms.Namespace, ms.Name)
+	}
+	return fmt.Sprintf("%d", hash), nil
+}
+
 // shouldExcludeMachine returns true if the machine should be filtered out, false otherwise.
 func shouldExcludeMachine(machineSet *clusterv1.MachineSet, machine *clusterv1.Machine) bool {
 	if metav1.GetControllerOf(machine) != nil && !metav1.IsControlledBy(machine, machineSet) {
@@ -581,7 +654,8 @@ func (r *MachineSetReconciler) shouldAdopt(ms *clusterv1.MachineSet) bool {
 
 // updateStatus updates the Status field for the MachineSet
 // It checks for the current state of the replicas and updates the Status of the MachineSet.
-func (r *MachineSetReconciler) updateStatus(ctx context.Context, cluster *clusterv1.Cluster, ms *clusterv1.MachineSet, filteredMachines []*clusterv1.Machine) error {
+// The returned result requeues when the next ready replica is expected to become available, if any.
+func (r *MachineSetReconciler) updateStatus(ctx context.Context, cluster *clusterv1.Cluster, ms *clusterv1.MachineSet, filteredMachines []*clusterv1.Machine) (ctrl.Result, error) {
 	log := ctrl.LoggerFrom(ctx)
 	newStatus := ms.Status.DeepCopy()
 
@@ -589,7 +663,7 @@ func (r *MachineSetReconciler) updateStatus(ctx context.Context, cluster *cluste
 	// This is necessary for CRDs including scale subresources.
 	selector, err := metav1.LabelSelectorAsSelector(&ms.Spec.Selector)
 	if err != nil {
-		return errors.Wrapf(err, "failed to update status for MachineSet %s/%s", ms.Namespace, ms.Name)
+		return ctrl.Result{}, errors.Wrapf(err, "failed to update status for MachineSet %s/%s", ms.Namespace, ms.Name)
 	}
 	newStatus.Selector = selector.String()
 
@@ -601,13 +675,25 @@ func (r *MachineSetReconciler) updateStatus(ctx context.Context, cluster *cluste
 	fullyLabeledReplicasCount := 0
 	readyReplicasCount := 0
 	availableReplicasCount := 0
+	staleReplicasCount := 0
+	result := ctrl.Result{}
+	now := metav1.Now()
 	templateLabel := labels.Set(ms.Spec.Template.Labels).AsSelectorPreValidated()
+	templateHash, err := machineTemplateHash(ms)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
 
 	for _, machine := range filteredMachines {
 		if templateLabel.Matches(labels.Set(machine.Labels)) {
 			fullyLabeledReplicasCount++
 		}
 
+		// Machines without the template hash, e.g. adopted ones, can't be identified as stale.
+		if hash, ok := machine.Annotations[clusterv1.MachineSetTemplateHashAnnotation]; ok && hash != templateHash {
+			staleReplicasCount++
+		}
+
 		if machine.Status.NodeRef == nil {
 			log.V(2).Info("Unable to retrieve Node status, missing NodeRef", "machine", machine.Name)
 			continue
@@ -621,9 +707,11 @@ func (r *MachineSetReconciler) updateStatus(ctx context.Context, cluster *cluste
 
 		if noderefutil.IsNodeReady(node) {
 			readyReplicasCount++
-			if noderefutil.IsNodeAvailable(node, ms.Spec.MinReadySeconds, metav1.Now()) {
+			if noderefutil.IsNodeAvailable(node, ms.Spec.MinReadySeconds, now) && readinessGatesPassed(machine) {
 				availableReplicasCount++
 			}
+			// A ready replica becomes available only after its node has been ready for MinReadySeconds.
+			result = util.LowestNonZeroResult(result, ctrl.Result{RequeueAfter: noderefutil.NodeAvailableAfter(node, ms.Spec.MinReadySeconds, now)})
 		}
 	}
 
@@ -631,16 +719,16 @@ func (r *MachineSetReconciler) updateStatus(ctx context.Context, cluster *cluste
 	newStatus.FullyLabeledReplicas = int32(fullyLabeledReplicasCount)
 	newStatus.ReadyReplicas = int32(readyReplicasCount)
 	newStatus.AvailableReplicas = int32(availableReplicasCount)
+	newStatus.StaleReplicas = int32(staleReplicasCount)
+	recordMachineSetStaleReplicas(ms, newStatus.StaleReplicas)
 
 	// Copy the newly calculated status into the machineset
 	if ms.Status.Replicas != newStatus.Replicas ||
 		ms.Status.FullyLabeledReplicas != newStatus.FullyLabeledReplicas ||
 		ms.Status.ReadyReplicas != newStatus.ReadyReplicas ||
 		ms.Status.AvailableReplicas != newStatus.AvailableReplicas ||
-		ms.Generation != ms.Status.ObservedGeneration {
-		// Save the generation number we acted on, otherwise we might wrongfully indicate
-		// that we've seen a spec update when we retry.
-		newStatus.ObservedGeneration = ms.Generation
+		ms.Status.StaleReplicas != newStatus.StaleReplicas {
+		// NB. ObservedGeneration is set when patching the MachineSet, only if the reconciliation completed successfully.
 		newStatus.DeepCopyInto(&ms.Status)
 
 		log.V(4).Info(fmt.Sprintf("Updating status for %v: %s/%s, ", ms.Kind, ms.Namespace, ms.Name) +
@@ -648,10 +736,10 @@ func (r *MachineSetReconciler) updateStatus(ctx context.Context, cluster *cluste
 			fmt.Sprintf("fullyLabeledReplicas %d->%d, ", ms.Status.FullyLabeledReplicas, newStatus.FullyLabeledReplicas) +
 			fmt.Sprintf("readyReplicas %d->%d, ", ms.Status.ReadyReplicas, newStatus.ReadyReplicas) +
 			fmt.Sprintf("availableReplicas %d->%d, ", ms.Status.AvailableReplicas, newStatus.AvailableReplicas) +
-			fmt.Sprintf("sequence No: %v->%v", ms.Status.ObservedGeneration, newStatus.ObservedGeneration))
+			fmt.Sprintf("staleReplicas %d->%d", ms.Status.StaleReplicas, newStatus.StaleReplicas))
 	}
 
-	return nil
+	return result, nil
 }
 
 func (r *MachineSetReconciler) getMachineNode(ctx context.Context, cluster *clusterv1.Cluster, machine *clusterv1.Machine) (*corev1.Node, error) {
@@ -680,17 +768,21 @@ func reconcileExternalTemplateReference(ctx context.Context, c client.Client, re
 		return err
 	}
 
-	patchHelper, err := patch.NewHelper(obj, c)
-	if err != nil {
-		return err
-	}
-
-	obj.SetOwnerReferences(util.EnsureOwnerRef(obj.GetOwnerReferences(), metav1.OwnerReference{
-		APIVersion: clusterv1.GroupVersion.String(),
-		Kind:       "Cluster",
-		Name:       cluster.Name,
-		UID:        cluster.UID,
-	}))
+	// Templates can be shared by multiple objects, so the Cluster is set as a plain owner.
+	return external.PatchExternal(ctx, &external.PatchExternalInput{
+		Client:        c,
+		Object:        obj,
+		Owner:         cluster,
+		NonController: true,
+	})
+}
 
-	return patchHelper.Patch(ctx, obj)
+// readinessGatesPassed returns true if all the conditions listed in the Machine's readiness gates are true.
+func readinessGatesPassed(machine *clusterv1.Machine) bool {
+	for _, gate := range machine.Spec.ReadinessGates {
+		if !conditions.IsTrue(machine, gate.ConditionType) {
+			return false
+		}
+	}
+	return true
 }
@@ -21,15 +21,18 @@ import (
 	"time"
 
 	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 	"sigs.k8s.io/cluster-api/controllers/external"
 	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -441,6 +444,40 @@ func TestMachineSetReconcile(t *testing.T) {
 	})
 }
 
+func TestMachineSetSyncReplicasCreationFailure(t *testing.T) {
+	g := NewWithT(t)
+
+	replicas := int32(1)
+	ms := newMachineSet("machineset1", "test-cluster")
+	ms.Spec.Replicas = &replicas
+	ms.Spec.Template.Spec = clusterv1.MachineSpec{
+		ClusterName: "test-cluster",
+		InfrastructureRef: corev1.ObjectReference{
+			APIVersion: "infrastructure.cluster.x-k8s.io/v1alpha4",
+			Kind:       "GenericInfrastructureMachineTemplate",
+			Name:       "does-not-exist",
+			Namespace:  "default",
+		},
+	}
+
+	rec := record.NewFakeRecorder(32)
+	msr := &MachineSetReconciler{
+		Client:   fake.NewClientBuilder().WithObjects(ms).Build(),
+		recorder: rec,
+	}
+	_, err := msr.syncReplicas(ctx, ms, nil)
+	g.Expect(err).To(HaveOccurred())
+
+	g.Expect(conditions.IsFalse(ms, clusterv1.MachinesCreatedCondition)).To(BeTrue())
+	g.Expect(conditions.GetReason(ms, clusterv1.MachinesCreatedCondition)).To(Equal(clusterv1.InfrastructureTemplateCloningFailedReason))
+	g.Expect(*conditions.GetSeverity(ms, clusterv1.MachinesCreatedCondition)).To(Equal(clusterv1.ConditionSeverityError))
+
+	var event string
+	g.Eventually(rec.Events).Should(Receive(&event))
+	g.Expect(event).To(ContainSubstring("FailedCreate"))
+	g.Expect(event).To(ContainSubstring("does-not-exist"))
+}
+
 func TestMachineSetToMachines(t *testing.T) {
 	machineSetList := []client.Object{
 		&clusterv1.MachineSet{
@@ -703,3 +740,100 @@ func newMachineSet(name, cluster string) *clusterv1.MachineSet {
 		},
 	}
 }
+
+func TestReadinessGatesPassed(t *testing.T) {
+	machine := &clusterv1.Machine{
+		Spec: clusterv1.MachineSpec{
+			ReadinessGates: []clusterv1.MachineReadinessGate{
+				{ConditionType: "LoadBalancerRegistered"},
+				{ConditionType: "Scanned"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		conditions clusterv1.Conditions
+		want       bool
+	}{
+		{
+			name:       "not passed when the conditions are missing",
+			conditions: nil,
+			want:       false,
+		},
+		{
+			name: "not passed when a condition is false",
+			conditions: clusterv1.Conditions{
+				*conditions.TrueCondition("LoadBalancerRegistered"),
+				*conditions.FalseCondition("Scanned", "ScanInProgress", clusterv1.ConditionSeverityInfo, ""),
+			},
+			want: false,
+		},
+		{
+			name: "passed when all the conditions are true",
+			conditions: clusterv1.Conditions{
+				*conditions.TrueCondition("LoadBalancerRegistered"),
+				*conditions.TrueCondition("Scanned"),
+			},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			m := machine.DeepCopy()
+			m.Status.Conditions = tt.conditions
+			g.Expect(readinessGatesPassed(m)).To(Equal(tt.want))
+		})
+	}
+
+	g := NewWithT(t)
+	g.Expect(readinessGatesPassed(&clusterv1.Machine{})).To(BeTrue())
+}
+
+func TestMachineSetUpdateStatusStaleReplicas(t *testing.T) {
+	g := NewWithT(t)
+
+	ms := newMachineSet("machineset1", "test-cluster")
+	templateHash, err := machineTemplateHash(ms)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	msr := &MachineSetReconciler{}
+
+	// Annotating new machines with the template hash does not change the machine template.
+	machine := msr.getNewMachine(ms)
+	machine.Annotations[clusterv1.MachineSetTemplateHashAnnotation] = templateHash
+	g.Expect(ms.Spec.Template.Annotations).NotTo(HaveKey(clusterv1.MachineSetTemplateHashAnnotation))
+
+	newMachine := func(name string, annotations map[string]string) *clusterv1.Machine {
+		return &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   ms.Namespace,
+				Labels:      ms.Spec.Template.Labels,
+				Annotations: annotations,
+			},
+		}
+	}
+	machines := []*clusterv1.Machine{
+		newMachine("up-to-date", map[string]string{clusterv1.MachineSetTemplateHashAnnotation: templateHash}),
+		newMachine("stale", map[string]string{clusterv1.MachineSetTemplateHashAnnotation: "previous-hash"}),
+		newMachine("adopted", nil),
+	}
+
+	_, err = msr.updateStatus(ctx, &clusterv1.Cluster{}, ms, machines)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(ms.Status.Replicas).To(Equal(int32(3)))
+	g.Expect(ms.Status.StaleReplicas).To(Equal(int32(1)))
+	g.Expect(testutil.ToFloat64(machineSetStaleReplicas.WithLabelValues(ms.Namespace, ms.Name))).To(Equal(float64(1)))
+
+	// Updating the machine template makes all the machines with a template hash stale.
+	ms.Spec.Template.Spec.Version = pointer.StringPtr("v1.21.2")
+	_, err = msr.updateStatus(ctx, &clusterv1.Cluster{}, ms, machines)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(ms.Status.StaleReplicas).To(Equal(int32(2)))
+
+	deleteMachineSetMetrics(ms.Namespace, ms.Name)
+	g.Expect(testutil.CollectAndCount(machineSetStaleReplicas)).To(Equal(0))
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// machineCreationBatchJitterFactor is the maximum jitter applied to the interval between two batches of Machine
+// creations, so the batches of many MachineSets scaling up at the same time are spread over time.
+const machineCreationBatchJitterFactor = 0.5
+
+// machineCreationBatch returns the maximum number of Machines to be created in a single batch by a MachineSet
+// and the minimum interval between two batches, as defined by the MachineSet annotations or by the reconciler defaults.
+// Invalid annotation values are ignored.
+func (r *MachineSetReconciler) machineCreationBatch(ctx context.Context, ms *clusterv1.MachineSet) (int, time.Duration) {
+	log := ctrl.LoggerFrom(ctx)
+
+	batchSize := r.CreationBatchSize
+	if value, ok := ms.Annotations[clusterv1.MachineCreationBatchSizeAnnotation]; ok {
+		if size, err := strconv.Atoi(value); err == nil && size >= 0 {
+			batchSize = size
+		} else {
+			log.Info("Ignoring invalid annotation value", "annotation", clusterv1.MachineCreationBatchSizeAnnotation, "value", value)
+		}
+	}
+
+	batchInterval := r.CreationBatchInterval
+	if value, ok := ms.Annotations[clusterv1.MachineCreationBatchIntervalAnnotation]; ok {
+		if interval, err := time.ParseDuration(value); err == nil && interval >= 0 {
+			batchInterval = interval
+		} else {
+			log.Info("Ignoring invalid annotation value", "annotation", clusterv1.MachineCreationBatchIntervalAnnotation, "value", value)
+		}
+	}
+
+	return batchSize, batchInterval
+}
+
+// newestMachineCreationTime returns the creation time of the newest Machine, or the zero time if there are no Machines.
+func newestMachineCreationTime(machines []*clusterv1.Machine) time.Time {
+	newest := time.Time{}
+	for _, m := range machines {
+		if m.CreationTimestamp.After(newest) {
+			newest = m.CreationTimestamp.Time
+		}
+	}
+	return newest
+}
+
+// nextMachineCreationBatch returns the result for requeueing a MachineSet when the next batch of Machines can be created.
+func nextMachineCreationBatch(interval time.Duration) ctrl.Result {
+	if interval <= 0 {
+		return ctrl.Result{Requeue: true}
+	}
+	return ctrl.Result{RequeueAfter: jitterMachineCreationBatchInterval(interval)}
+}
+
+// jitterMachineCreationBatchInterval adds a random jitter of up to machineCreationBatchJitterFactor to the interval.
+func jitterMachineCreationBatchInterval(interval time.Duration) time.Duration {
+	return wait.Jitter(interval, machineCreationBatchJitterFactor)
+}
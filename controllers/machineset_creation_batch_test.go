@@ -0,0 +1,118 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestMachineCreationBatch(t *testing.T) {
+	r := &MachineSetReconciler{
+		CreationBatchSize:     10,
+		CreationBatchInterval: 30 * time.Second,
+	}
+
+	tests := []struct {
+		name         string
+		annotations  map[string]string
+		wantSize     int
+		wantInterval time.Duration
+	}{
+		{
+			name:         "defaults",
+			wantSize:     10,
+			wantInterval: 30 * time.Second,
+		},
+		{
+			name: "annotations override the defaults",
+			annotations: map[string]string{
+				clusterv1.MachineCreationBatchSizeAnnotation:     "0",
+				clusterv1.MachineCreationBatchIntervalAnnotation: "1m",
+			},
+			wantSize:     0,
+			wantInterval: time.Minute,
+		},
+		{
+			name: "invalid annotations are ignored",
+			annotations: map[string]string{
+				clusterv1.MachineCreationBatchSizeAnnotation:     "-1",
+				clusterv1.MachineCreationBatchIntervalAnnotation: "soon",
+			},
+			wantSize:     10,
+			wantInterval: 30 * time.Second,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			ms := newMachineSet("ms", "test-cluster")
+			ms.Annotations = tt.annotations
+			size, interval := r.machineCreationBatch(ctx, ms)
+			g.Expect(size).To(Equal(tt.wantSize))
+			g.Expect(interval).To(Equal(tt.wantInterval))
+		})
+	}
+}
+
+func TestMachineSetSyncReplicasWaitsForCreationBatchInterval(t *testing.T) {
+	g := NewWithT(t)
+
+	replicas := int32(3)
+	ms := newMachineSet("ms", "test-cluster")
+	ms.Spec.Replicas = &replicas
+	ms.Annotations = map[string]string{
+		clusterv1.MachineCreationBatchSizeAnnotation:     "1",
+		clusterv1.MachineCreationBatchIntervalAnnotation: "1h",
+	}
+	machines := []*clusterv1.Machine{
+		{ObjectMeta: metav1.ObjectMeta{Name: "old", CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour))}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "new", CreationTimestamp: metav1.NewTime(time.Now().Add(-time.Minute))}},
+	}
+
+	r := &MachineSetReconciler{
+		Client:   fake.NewClientBuilder().WithObjects(ms).Build(),
+		recorder: record.NewFakeRecorder(32),
+	}
+	result, err := r.syncReplicas(ctx, ms, machines)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// The next batch is created one hour after the newest machine, with up to 50% jitter.
+	g.Expect(result.RequeueAfter).To(BeNumerically(">", 58*time.Minute))
+	g.Expect(result.RequeueAfter).To(BeNumerically("<", 90*time.Minute))
+
+	machineList := &clusterv1.MachineList{}
+	g.Expect(r.Client.List(ctx, machineList)).To(Succeed())
+	g.Expect(machineList.Items).To(BeEmpty())
+}
+
+func TestNextMachineCreationBatch(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(nextMachineCreationBatch(0).Requeue).To(BeTrue())
+	g.Expect(nextMachineCreationBatch(10 * time.Second).RequeueAfter).To(And(
+		BeNumerically(">=", 10*time.Second),
+		BeNumerically("<=", 15*time.Second),
+	))
+}
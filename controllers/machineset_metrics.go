@@ -0,0 +1,63 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// machineSetMachineCreationFailures counts the failures to create the machines of a MachineSet, by reason.
+var machineSetMachineCreationFailures = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "capi_machineset_machine_creation_failures_total",
+		Help: "Total number of failures when creating the machines of a MachineSet, including the cloning of the bootstrap and infrastructure templates.",
+	},
+	[]string{"namespace", "machineset", "reason"},
+)
+
+// machineSetStaleReplicas reports the number of machines of a MachineSet created from a previous revision of its machine template.
+var machineSetStaleReplicas = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "capi_machineset_stale_replicas",
+		Help: "Number of machines of a MachineSet created from a previous revision of its machine template.",
+	},
+	[]string{"namespace", "machineset"},
+)
+
+func init() {
+	metrics.Registry.MustRegister(machineSetMachineCreationFailures, machineSetStaleReplicas)
+}
+
+// recordMachineCreationFailure surfaces a failure to create a machine in the MachinesCreated condition
+// of the MachineSet, and counts it in the machine creation failures metric.
+func recordMachineCreationFailure(ms *clusterv1.MachineSet, reason string, err error) {
+	conditions.MarkFalse(ms, clusterv1.MachinesCreatedCondition, reason, clusterv1.ConditionSeverityError, "%v", err)
+	machineSetMachineCreationFailures.WithLabelValues(ms.Namespace, ms.Name, reason).Inc()
+}
+
+// recordMachineSetStaleReplicas reports the number of stale machines of a MachineSet.
+func recordMachineSetStaleReplicas(ms *clusterv1.MachineSet, staleReplicas int32) {
+	machineSetStaleReplicas.WithLabelValues(ms.Namespace, ms.Name).Set(float64(staleReplicas))
+}
+
+// deleteMachineSetMetrics deletes the gauges of a MachineSet which does not exist anymore.
+func deleteMachineSetMetrics(namespace, name string) {
+	machineSetStaleReplicas.DeleteLabelValues(namespace, name)
+}
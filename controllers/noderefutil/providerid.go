@@ -49,7 +49,9 @@ type ProviderID struct {
 var providerIDRegex = regexp.MustCompile("^[^:]+://.*[^/]$")
 
 // NewProviderID parses the input string and returns a new ProviderID.
+// Leading and trailing white spaces are ignored.
 func NewProviderID(id string) (*ProviderID, error) {
+	id = strings.TrimSpace(id)
 	if id == "" {
 		return nil, ErrEmptyProviderID
 	}
@@ -88,8 +90,10 @@ func (p *ProviderID) ID() string {
 }
 
 // Equals returns true if both the CloudProvider and ID match.
+// The comparison is case insensitive, because some infrastructure providers report the same
+// ProviderID with a different case in the Machine and in the Node, e.g. for UUIDs.
 func (p *ProviderID) Equals(o *ProviderID) bool {
-	return p.CloudProvider() == o.CloudProvider() && p.ID() == o.ID()
+	return strings.EqualFold(p.CloudProvider(), o.CloudProvider()) && strings.EqualFold(p.ID(), o.ID())
 }
 
 // String returns the string representation of this object.
@@ -103,9 +107,10 @@ func (p *ProviderID) Validate() bool {
 }
 
 // IndexKey returns a string concatenating the cloudProvider and the ID parts of the providerID.
-// E.g Format: cloudProvider://optional/segments/etc/id. IndexKey: cloudProvider/id
+// E.g Format: cloudProvider://optional/segments/etc/id. IndexKey: cloudprovider/id
 // This is useful to use the providerID as a reliable index between nodes and machines
 // as it guarantees the infra Providers contract.
+// The IndexKey is lower case, consistently with Equals.
 func (p *ProviderID) IndexKey() string {
-	return fmt.Sprintf("%s/%s", p.CloudProvider(), p.ID())
+	return strings.ToLower(fmt.Sprintf("%s/%s", p.CloudProvider(), p.ID()))
 }
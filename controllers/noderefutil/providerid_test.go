@@ -131,3 +131,18 @@ func TestProviderIDEquals(t *testing.T) {
 
 	g.Expect(parsed1.Equals(parsed2)).To(BeTrue())
 }
+
+func TestProviderIDEqualsIgnoresCase(t *testing.T) {
+	g := NewWithT(t)
+
+	parsed1, err := NewProviderID("vsphere://4231A3B6-2C61-ABCD-1234-567890ABCDEF")
+	g.Expect(err).NotTo(HaveOccurred())
+
+	parsed2, err := NewProviderID(" vsphere://4231a3b6-2c61-abcd-1234-567890abcdef\n")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(parsed2.String()).To(Equal("vsphere://4231a3b6-2c61-abcd-1234-567890abcdef"))
+
+	g.Expect(parsed1.Equals(parsed2)).To(BeTrue())
+	g.Expect(parsed1.IndexKey()).To(Equal(parsed2.IndexKey()))
+	g.Expect(parsed1.IndexKey()).To(Equal("vsphere/4231a3b6-2c61-abcd-1234-567890abcdef"))
+}
@@ -44,6 +44,20 @@ func IsNodeAvailable(node *corev1.Node, minReadySeconds int32, now metav1.Time)
 	return false
 }
 
+// NodeAvailableAfter returns the time left before a ready node becomes available, i.e. before minReadySeconds have
+// elapsed since the node became ready. It returns 0 if the node is not ready or if it is already available.
+func NodeAvailableAfter(node *corev1.Node, minReadySeconds int32, now metav1.Time) time.Duration {
+	if !IsNodeReady(node) || IsNodeAvailable(node, minReadySeconds, now) {
+		return 0
+	}
+
+	readyCondition := GetReadyCondition(&node.Status)
+	if readyCondition.LastTransitionTime.IsZero() {
+		return 0
+	}
+	return readyCondition.LastTransitionTime.Add(time.Duration(minReadySeconds) * time.Second).Sub(now.Time)
+}
+
 // GetReadyCondition extracts the ready condition from the given status and returns that.
 // Returns nil and -1 if the condition is not present, and the index of the located condition.
 func GetReadyCondition(status *corev1.NodeStatus) *corev1.NodeCondition {
@@ -148,6 +148,61 @@ func TestIsNodeAvaialble(t *testing.T) {
 	}
 }
 
+func TestNodeAvailableAfter(t *testing.T) {
+	now := metav1.Now()
+	readyNode := func(lastTransitionTime time.Time) *corev1.Node {
+		return &corev1.Node{Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{
+				{
+					Type:               corev1.NodeReady,
+					Status:             corev1.ConditionTrue,
+					LastTransitionTime: metav1.NewTime(lastTransitionTime),
+				},
+			}},
+		}
+	}
+
+	tests := []struct {
+		name            string
+		node            *corev1.Node
+		minReadySeconds int32
+		expected        time.Duration
+	}{
+		{
+			name:            "node not ready",
+			node:            &corev1.Node{},
+			minReadySeconds: 60,
+			expected:        0,
+		},
+		{
+			name:            "node available without minReadySeconds",
+			node:            readyNode(now.Time),
+			minReadySeconds: 0,
+			expected:        0,
+		},
+		{
+			name:            "node available after minReadySeconds",
+			node:            readyNode(now.Add(-2 * time.Minute)),
+			minReadySeconds: 60,
+			expected:        0,
+		},
+		{
+			name:            "node ready for less than minReadySeconds",
+			node:            readyNode(now.Add(-20 * time.Second)),
+			minReadySeconds: 60,
+			expected:        40 * time.Second,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			g.Expect(NodeAvailableAfter(test.node, test.minReadySeconds, now)).To(Equal(test.expected))
+		})
+	}
+}
+
 func TestGetReadyCondition(t *testing.T) {
 	tests := []struct {
 		name              string
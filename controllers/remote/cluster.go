@@ -53,7 +53,11 @@ func RESTConfig(ctx context.Context, sourceName string, c client.Reader, cluster
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to retrieve kubeconfig secret for Cluster %s/%s", cluster.Namespace, cluster.Name)
 	}
+	return restConfigFromKubeconfig(sourceName, cluster, kubeConfig)
+}
 
+// restConfigFromKubeconfig returns a configuration instance built from the kubeconfig of the given Cluster.
+func restConfigFromKubeconfig(sourceName string, cluster client.ObjectKey, kubeConfig []byte) (*restclient.Config, error) {
 	restConfig, err := clientcmd.RESTConfigFromKubeConfig(kubeConfig)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to create REST configuration for Cluster %s/%s", cluster.Namespace, cluster.Name)
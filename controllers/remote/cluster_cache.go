@@ -17,6 +17,7 @@ limitations under the License.
 package remote
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"sync"
@@ -34,6 +35,7 @@ import (
 	"k8s.io/client-go/rest"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 	"sigs.k8s.io/cluster-api/util/conditions"
+	kcfg "sigs.k8s.io/cluster-api/util/kubeconfig"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -51,12 +53,20 @@ const (
 	clusterCacheControllerName    = "cluster-cache-tracker"
 )
 
+// errKubeconfigChanged is returned by the health check when the kubeconfig of a cluster has been rotated
+// after its clusterAccessor was created.
+var errKubeconfigChanged = errors.New("kubeconfig has changed")
+
 // ClusterCacheTracker manages client caches for workload clusters.
 type ClusterCacheTracker struct {
 	log                   logr.Logger
 	clientUncachedObjects []client.Object
 	client                client.Client
 	scheme                *runtime.Scheme
+	clientQPS             float32
+	clientBurst           int
+	clientTimeout         time.Duration
+	userAgent             string
 
 	lock             sync.RWMutex
 	clusterAccessors map[client.ObjectKey]*clusterAccessor
@@ -75,6 +85,27 @@ type ClusterCacheTrackerOptions struct {
 	// Defaults to never caching ConfigMap and Secret if not set.
 	ClientUncachedObjects []client.Object
 	Indexes               []Index
+
+	// ClientQPS is the maximum number of queries per second from the clients to the API server of each workload cluster.
+	// Defaults to the client-go default if not set.
+	ClientQPS float32
+
+	// ClientBurst is the maximum burst of queries from the clients to the API server of each workload cluster.
+	// Defaults to the client-go default if not set.
+	ClientBurst int
+
+	// ClientTimeout is the timeout of the requests to the API server of each workload cluster.
+	// Defaults to 10s if not set.
+	ClientTimeout time.Duration
+
+	// ControllerName is the name of the controller manager using the ClusterCacheTracker, used in the User-Agent
+	// of the requests to the workload clusters. Defaults to cluster-cache-tracker if not set.
+	ControllerName string
+
+	// ManagementClusterName identifies the management cluster in the User-Agent of the requests to the workload clusters,
+	// so the administrators of a workload cluster can identify the management cluster managing it.
+	// +optional
+	ManagementClusterName string
 }
 
 func setDefaultOptions(opts *ClusterCacheTrackerOptions) {
@@ -82,6 +113,14 @@ func setDefaultOptions(opts *ClusterCacheTrackerOptions) {
 		opts.Log = log.NullLogger{}
 	}
 
+	if opts.ClientTimeout == 0 {
+		opts.ClientTimeout = defaultClientTimeout
+	}
+
+	if opts.ControllerName == "" {
+		opts.ControllerName = clusterCacheControllerName
+	}
+
 	if len(opts.ClientUncachedObjects) == 0 {
 		opts.ClientUncachedObjects = []client.Object{
 			&corev1.ConfigMap{},
@@ -101,6 +140,10 @@ func NewClusterCacheTracker(manager ctrl.Manager, options ClusterCacheTrackerOpt
 		scheme:                manager.GetScheme(),
 		clusterAccessors:      make(map[client.ObjectKey]*clusterAccessor),
 		indexes:               options.Indexes,
+		clientQPS:             options.ClientQPS,
+		clientBurst:           options.ClientBurst,
+		clientTimeout:         options.ClientTimeout,
+		userAgent:             userAgent(options.ControllerName, options.ManagementClusterName),
 	}, nil
 }
 
@@ -153,11 +196,16 @@ func (t *ClusterCacheTracker) getClusterAccessorLH(ctx context.Context, cluster
 
 // newClusterAccessor creates a new clusterAccessor.
 func (t *ClusterCacheTracker) newClusterAccessor(ctx context.Context, cluster client.ObjectKey, indexes ...Index) (*clusterAccessor, error) {
-	// Get a rest config for the remote cluster
-	config, err := RESTConfig(ctx, clusterCacheControllerName, t.client, cluster)
+	// Get a rest config for the remote cluster; the kubeconfig is kept for detecting when it gets rotated.
+	kubeconfig, err := kcfg.FromSecret(ctx, t.client, cluster)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error fetching kubeconfig for remote cluster %q", cluster.String())
+	}
+	config, err := restConfigFromKubeconfig(clusterCacheControllerName, cluster, kubeconfig)
 	if err != nil {
 		return nil, errors.Wrapf(err, "error fetching REST client config for remote cluster %q", cluster.String())
 	}
+	t.configureRESTConfig(config, cluster)
 
 	// Create a mapper for it
 	mapper, err := apiutil.NewDynamicRESTMapper(config)
@@ -203,8 +251,9 @@ func (t *ClusterCacheTracker) newClusterAccessor(ctx context.Context, cluster cl
 
 	// Start cluster healthcheck!!!
 	go t.healthCheckCluster(cacheCtx, &healthCheckInput{
-		cluster: cluster,
-		cfg:     config,
+		cluster:    cluster,
+		cfg:        config,
+		kubeconfig: kubeconfig,
 	})
 
 	delegatingClient, err := client.NewDelegatingClient(client.NewDelegatingClientInput{
@@ -223,6 +272,24 @@ func (t *ClusterCacheTracker) newClusterAccessor(ctx context.Context, cluster cl
 	}, nil
 }
 
+// configureRESTConfig applies the rate limits, timeout and User-Agent of the ClusterCacheTracker to the REST config
+// of a workload cluster, and instruments it for observing the latency of the requests.
+func (t *ClusterCacheTracker) configureRESTConfig(config *rest.Config, cluster client.ObjectKey) {
+	if t.clientQPS > 0 {
+		config.QPS = t.clientQPS
+	}
+	if t.clientBurst > 0 {
+		config.Burst = t.clientBurst
+	}
+	if t.clientTimeout > 0 {
+		config.Timeout = t.clientTimeout
+	}
+	if t.userAgent != "" {
+		config.UserAgent = t.userAgent
+	}
+	config.Wrap(newInstrumentedRoundTripper(cluster))
+}
+
 // deleteAccessor stops a clusterAccessor's cache and removes the clusterAccessor from the tracker.
 func (t *ClusterCacheTracker) deleteAccessor(cluster client.ObjectKey) {
 	t.lock.Lock()
@@ -313,6 +380,10 @@ type healthCheckInput struct {
 	requestTimeout     time.Duration
 	unhealthyThreshold int
 	path               string
+
+	// kubeconfig is the kubeconfig the clusterAccessor has been created with; if set, the clusterAccessor
+	// is removed as soon as the kubeconfig Secret of the cluster contains a different kubeconfig.
+	kubeconfig []byte
 }
 
 // setDefaults sets default values if optional parameters are not set.
@@ -334,6 +405,8 @@ func (h *healthCheckInput) setDefaults() {
 // healthCheckCluster will poll the cluster's API at the path given and, if there are
 // `unhealthyThreshold` consecutive failures, will deem the cluster unhealthy.
 // Once the cluster is deemed unhealthy, the cluster's cache is stopped and removed.
+// The cache is also stopped and removed when the kubeconfig of the cluster is rotated, so the next
+// request creates a new cache with the new kubeconfig.
 func (t *ClusterCacheTracker) healthCheckCluster(ctx context.Context, in *healthCheckInput) {
 	// populate optional params for healthCheckInput
 	in.setDefaults()
@@ -373,6 +446,14 @@ func (t *ClusterCacheTracker) healthCheckCluster(ctx context.Context, in *health
 			return true, nil
 		}
 
+		if in.kubeconfig != nil {
+			// Errors reading the kubeconfig are ignored, the check is retried at the next poll.
+			kubeconfig, err := kcfg.FromSecret(ctx, t.client, in.cluster)
+			if err == nil && !bytes.Equal(kubeconfig, in.kubeconfig) {
+				return false, errKubeconfigChanged
+			}
+		}
+
 		// An error here means there was either an issue connecting or the API returned an error.
 		// If no error occurs, reset the unhealthy counter.
 		_, err := restClient.Get().AbsPath(in.path).Timeout(in.requestTimeout).DoRaw(ctx)
@@ -395,6 +476,11 @@ func (t *ClusterCacheTracker) healthCheckCluster(ctx context.Context, in *health
 	// times for the cluster to be considered unhealthy
 	// NB. we are ignoring ErrWaitTimeout because this error happens when the channel is close, that in this case
 	// happens when the cache is explicitly stopped.
+	if err == errKubeconfigChanged {
+		t.log.V(2).Info("Kubeconfig has changed, removing the cache", "cluster", in.cluster.String())
+		t.deleteAccessor(in.cluster)
+		return
+	}
 	if err != nil && err != wait.ErrWaitTimeout {
 		t.log.Error(err, "Error health checking cluster", "cluster", in.cluster.String())
 		t.deleteAccessor(in.cluster)
@@ -128,20 +128,41 @@ func TestClusterCacheHealthCheck(t *testing.T) {
 			ctx, cancel := context.WithCancel(ctx)
 			defer cancel()
 
-			// TODO(community): Fill in these field names.
 			go cct.healthCheckCluster(ctx, &healthCheckInput{
-				testClusterKey,
-				env.Config,
-				testPollInterval,
-				testPollTimeout,
-				testUnhealthyThreshold,
-				"/",
+				cluster:            testClusterKey,
+				cfg:                env.Config,
+				interval:           testPollInterval,
+				requestTimeout:     testPollTimeout,
+				unhealthyThreshold: testUnhealthyThreshold,
+				path:               "/",
 			})
 
 			// Make sure this passes for at least two seconds, to give the health check goroutine time to run.
 			g.Consistently(func() bool { return cct.clusterAccessorExists(testClusterKey) }, 2*time.Second, 100*time.Millisecond).Should(BeTrue())
 		})
 
+		t.Run("with a rotated kubeconfig", func(t *testing.T) {
+			g := NewWithT(t)
+			setup(t, g)
+			defer teardown(t, g)
+
+			ctx, cancel := context.WithCancel(ctx)
+			defer cancel()
+
+			go cct.healthCheckCluster(ctx, &healthCheckInput{
+				cluster:            testClusterKey,
+				cfg:                env.Config,
+				interval:           testPollInterval,
+				requestTimeout:     testPollTimeout,
+				unhealthyThreshold: testUnhealthyThreshold,
+				path:               "/",
+				kubeconfig:         []byte("a kubeconfig before rotation"),
+			})
+
+			// The kubeconfig in the Secret differs from the one the accessor has been created with.
+			g.Eventually(func() bool { return cct.clusterAccessorExists(testClusterKey) }, 2*time.Second, 100*time.Millisecond).Should(BeFalse())
+		})
+
 		t.Run("with an invalid path", func(t *testing.T) {
 			g := NewWithT(t)
 			setup(t, g)
@@ -150,15 +171,14 @@ func TestClusterCacheHealthCheck(t *testing.T) {
 			ctx, cancel := context.WithCancel(ctx)
 			defer cancel()
 
-			// TODO(community): Fill in these field names.
 			go cct.healthCheckCluster(ctx,
 				&healthCheckInput{
-					testClusterKey,
-					env.Config,
-					testPollInterval,
-					testPollTimeout,
-					testUnhealthyThreshold,
-					"/clusterAccessor",
+					cluster:            testClusterKey,
+					cfg:                env.Config,
+					interval:           testPollInterval,
+					requestTimeout:     testPollTimeout,
+					unhealthyThreshold: testUnhealthyThreshold,
+					path:               "/clusterAccessor",
 				})
 
 			// This should succeed after N consecutive failed requests.
@@ -183,14 +203,13 @@ func TestClusterCacheHealthCheck(t *testing.T) {
 			config := rest.CopyConfig(env.Config)
 			config.Host = fmt.Sprintf("http://127.0.0.1:%d", l.Addr().(*net.TCPAddr).Port)
 
-			// TODO(community): Fill in these field names.
 			go cct.healthCheckCluster(ctx, &healthCheckInput{
-				testClusterKey,
-				config,
-				testPollInterval,
-				testPollTimeout,
-				testUnhealthyThreshold,
-				"/",
+				cluster:            testClusterKey,
+				cfg:                config,
+				interval:           testPollInterval,
+				requestTimeout:     testPollTimeout,
+				unhealthyThreshold: testUnhealthyThreshold,
+				path:               "/",
 			})
 
 			// This should succeed after N consecutive failed requests.
@@ -25,6 +25,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 	"sigs.k8s.io/cluster-api/controllers/noderefutil"
 	"sigs.k8s.io/cluster-api/util"
@@ -226,3 +227,31 @@ func cleanupTestClusters(ctx context.Context, c client.Client) error {
 	}
 	return nil
 }
+
+func TestConfigureRESTConfig(t *testing.T) {
+	g := NewWithT(t)
+
+	options := ClusterCacheTrackerOptions{
+		ClientQPS:             5,
+		ClientBurst:           10,
+		ControllerName:        "test-controller",
+		ManagementClusterName: "mgmt",
+	}
+	setDefaultOptions(&options)
+	tracker := &ClusterCacheTracker{
+		clientQPS:     options.ClientQPS,
+		clientBurst:   options.ClientBurst,
+		clientTimeout: options.ClientTimeout,
+		userAgent:     userAgent(options.ControllerName, options.ManagementClusterName),
+	}
+
+	config := &rest.Config{}
+	tracker.configureRESTConfig(config, client.ObjectKey{Namespace: "default", Name: "foo"})
+
+	g.Expect(config.QPS).To(Equal(float32(5)))
+	g.Expect(config.Burst).To(Equal(10))
+	g.Expect(config.Timeout).To(Equal(defaultClientTimeout))
+	g.Expect(config.UserAgent).To(ContainSubstring(" test-controller "))
+	g.Expect(config.UserAgent).To(HaveSuffix(" management-cluster/mgmt"))
+	g.Expect(config.WrapTransport).NotTo(BeNil())
+}
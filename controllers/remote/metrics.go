@@ -0,0 +1,67 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// remoteRequestDuration observes the latency of the requests to the API servers of the workload clusters.
+var remoteRequestDuration = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "capi_remote_request_duration_seconds",
+		Help:    "Latency of the requests to the API server of a workload cluster, by workload cluster, verb and response code.",
+		Buckets: []float64{0.005, 0.025, 0.1, 0.25, 0.5, 1, 2, 4, 8, 15, 30, 60},
+	},
+	[]string{"cluster", "verb", "code"},
+)
+
+func init() {
+	metrics.Registry.MustRegister(remoteRequestDuration)
+}
+
+// instrumentedRoundTripper is an http.RoundTripper observing the latency of the requests to a workload cluster.
+type instrumentedRoundTripper struct {
+	cluster  string
+	delegate http.RoundTripper
+}
+
+// newInstrumentedRoundTripper returns a function wrapping an http.RoundTripper so the latency of the requests
+// to the given cluster is observed; it is meant to be used with rest.Config.Wrap.
+func newInstrumentedRoundTripper(cluster client.ObjectKey) func(http.RoundTripper) http.RoundTripper {
+	return func(rt http.RoundTripper) http.RoundTripper {
+		return &instrumentedRoundTripper{cluster: cluster.String(), delegate: rt}
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (i *instrumentedRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := i.delegate.RoundTrip(req)
+	code := "<error>"
+	if err == nil {
+		code = strconv.Itoa(resp.StatusCode)
+	}
+	remoteRequestDuration.WithLabelValues(i.cluster, req.Method, code).Observe(time.Since(start).Seconds())
+	return resp, err
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"net/http"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestInstrumentedRoundTripper(t *testing.T) {
+	g := NewWithT(t)
+
+	remoteRequestDuration.Reset()
+	cluster := client.ObjectKey{Namespace: "default", Name: "foo"}
+
+	ok := newInstrumentedRoundTripper(cluster)(roundTripperFunc(func(*http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK}, nil
+	}))
+	req, err := http.NewRequest(http.MethodGet, "https://foo.example.com/api", nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	_, err = ok.RoundTrip(req)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	failing := newInstrumentedRoundTripper(cluster)(roundTripperFunc(func(*http.Request) (*http.Response, error) {
+		return nil, errors.New("connection refused")
+	}))
+	_, err = failing.RoundTrip(req)
+	g.Expect(err).To(HaveOccurred())
+
+	// One series for the successful request, and one for the failed one.
+	g.Expect(testutil.CollectAndCount(remoteRequestDuration)).To(Equal(2))
+}
@@ -46,6 +46,15 @@ func DefaultClusterAPIUserAgent(sourceName string) string {
 		adjustCommit(version.Get().GitCommit))
 }
 
+// userAgent returns the User-Agent for the given source, identifying the management cluster if its name is not empty.
+func userAgent(sourceName, managementClusterName string) string {
+	ua := DefaultClusterAPIUserAgent(sourceName)
+	if managementClusterName != "" {
+		ua = fmt.Sprintf("%s management-cluster/%s", ua, managementClusterName)
+	}
+	return ua
+}
+
 // adjustSourceName returns the name of the source calling the client.
 func adjustSourceName(c string) string {
 	if len(c) == 0 {
@@ -18,6 +18,7 @@ package v1alpha3
 
 import (
 	apiconversion "k8s.io/apimachinery/pkg/conversion"
+	cabpkv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1alpha4"
 	utilconversion "sigs.k8s.io/cluster-api/util/conversion"
 	"sigs.k8s.io/controller-runtime/pkg/conversion"
@@ -37,7 +38,20 @@ func (src *KubeadmControlPlane) ConvertTo(destRaw conversion.Hub) error {
 	}
 
 	dest.Spec.RolloutStrategy = restored.Spec.RolloutStrategy
+	dest.Spec.CloudProviderMigration = restored.Spec.CloudProviderMigration
+	dest.Spec.DeletionProtection = restored.Spec.DeletionProtection
+	dest.Spec.LoadBalancerHooks = restored.Spec.LoadBalancerHooks
 	dest.Spec.MachineTemplate.ObjectMeta = restored.Spec.MachineTemplate.ObjectMeta
+	dest.Spec.MachineTemplate.NodeVolumeDetachTimeout = restored.Spec.MachineTemplate.NodeVolumeDetachTimeout
+	dest.Spec.MachineTemplate.NodeDeletionTimeout = restored.Spec.MachineTemplate.NodeDeletionTimeout
+	dest.Spec.KubeadmConfigSpec.RotateKubeletServerCertificates = restored.Spec.KubeadmConfigSpec.RotateKubeletServerCertificates
+	dest.Spec.KubeadmConfigSpec.DataSecretMaxSize = restored.Spec.KubeadmConfigSpec.DataSecretMaxSize
+	dest.Spec.KubeadmConfigSpec.GPU = restored.Spec.KubeadmConfigSpec.GPU
+	dest.Spec.KubeadmConfigSpec.UploadCertificates = restored.Spec.KubeadmConfigSpec.UploadCertificates
+	dest.Spec.KubeadmConfigSpec.ImagePullSecrets = restored.Spec.KubeadmConfigSpec.ImagePullSecrets
+	dest.Spec.KubeadmConfigSpec.RenderFileTemplates = restored.Spec.KubeadmConfigSpec.RenderFileTemplates
+	dest.Spec.KubeadmConfigSpec.SecretDirectories = restored.Spec.KubeadmConfigSpec.SecretDirectories
+	cabpkv1.RestoreFilesContentFrom(dest.Spec.KubeadmConfigSpec.Files, restored.Spec.KubeadmConfigSpec.Files)
 
 	return nil
 }
@@ -177,6 +177,9 @@ func autoConvert_v1alpha4_KubeadmControlPlaneSpec_To_v1alpha3_KubeadmControlPlan
 	}
 	// WARNING: in.RolloutAfter requires manual conversion: does not exist in peer-type
 	// WARNING: in.RolloutStrategy requires manual conversion: does not exist in peer-type
+	// WARNING: in.CloudProviderMigration requires manual conversion: does not exist in peer-type
+	// WARNING: in.DeletionProtection requires manual conversion: does not exist in peer-type
+	// WARNING: in.LoadBalancerHooks requires manual conversion: does not exist in peer-type
 	return nil
 }
 
@@ -54,6 +54,11 @@ const (
 	// RollingUpdateInProgressReason (Severity=Warning) documents a KubeadmControlPlane object executing a
 	// rolling upgrade for aligning the machines spec to the desired state.
 	RollingUpdateInProgressReason = "RollingUpdateInProgress"
+
+	// WaitingForExternalCloudControllerManagerReason (Severity=Warning) documents a KubeadmControlPlane migrating to an
+	// external cloud provider waiting for the external cloud controller manager to be running in the workload cluster
+	// before rolling out the control plane machines.
+	WaitingForExternalCloudControllerManagerReason = "WaitingForExternalCloudControllerManager"
 )
 
 const (
@@ -65,6 +70,10 @@ const (
 
 	// ScalingDownReason (Severity=Info) documents a KubeadmControlPlane that is decreasing the number of replicas.
 	ScalingDownReason = "ScalingDown"
+
+	// WaitingForControlPlaneComponentsReason (Severity=Info) documents a KubeadmControlPlane waiting for the control plane
+	// components hosted on the joined machines to be reported as healthy before considering the resize operation completed.
+	WaitingForControlPlaneComponentsReason = "WaitingForControlPlaneComponents"
 )
 
 const (
@@ -132,6 +141,37 @@ const (
 	// EtcdMemberUnhealthyReason (Severity=Error) documents a Machine's etcd member is unhealthy.
 	EtcdMemberUnhealthyReason = "EtcdMemberUnhealthy"
 
+	// EtcdQuorumRecoveredCondition documents the progress of an etcd quorum recovery triggered using the
+	// EtcdQuorumRecoveryAnnotation.
+	// NOTE: This condition exists only after a recovery has been requested.
+	EtcdQuorumRecoveredCondition clusterv1.ConditionType = "EtcdQuorumRecovered"
+
+	// EtcdQuorumRecoveryRejectedReason (Severity=Warning) documents a KubeadmControlPlane refusing to perform a requested
+	// etcd quorum recovery because one of the safety checks failed, e.g. because etcd quorum is still available.
+	EtcdQuorumRecoveryRejectedReason = "EtcdQuorumRecoveryRejected"
+
+	// WaitingForForceNewClusterReason (Severity=Warning) documents a KubeadmControlPlane waiting for the surviving etcd
+	// member to be restarted as a new single-member etcd cluster.
+	WaitingForForceNewClusterReason = "WaitingForForceNewCluster"
+
+	// EtcdQuorumRecoveryInProgressReason (Severity=Info) documents a KubeadmControlPlane deleting the machines
+	// which are not part of the recovered etcd cluster anymore.
+	EtcdQuorumRecoveryInProgressReason = "EtcdQuorumRecoveryInProgress"
+
+	// EtcdMembersPromotedCondition documents that all the etcd members which joined the etcd cluster as learners
+	// have been promoted to voting members.
+	// NOTE: This condition exists only when both the Kubernetes and the etcd version support joining etcd members as learners;
+	// in this case control plane machines are replaced one at a time, regardless of maxSurge.
+	EtcdMembersPromotedCondition clusterv1.ConditionType = "EtcdMembersPromoted"
+
+	// WaitingForEtcdLearnersReason (Severity=Info) documents a KubeadmControlPlane waiting for etcd learners to be
+	// in sync with the leader before promoting them to voting members.
+	WaitingForEtcdLearnersReason = "WaitingForEtcdLearners"
+
+	// EtcdLearnerPromotionFailedReason (Severity=Warning) documents a KubeadmControlPlane failing to promote
+	// an etcd learner to a voting member.
+	EtcdLearnerPromotionFailedReason = "EtcdLearnerPromotionFailed"
+
 	// MachinesCreatedCondition documents that the machines controlled by the KubeadmControlPlane are created.
 	// When this condition is false, it indicates that there was an error when cloning the infrastructure/bootstrap template or
 	// when generating the machine object.
@@ -149,3 +189,17 @@ const (
 	// generate a machine object.
 	MachineGenerationFailedReason = "MachineGenerationFailed"
 )
+
+const (
+	// CoreDNSUpToDateCondition documents that the CoreDNS deployment in the workload cluster matches the
+	// DNS configuration defined in the KubeadmControlPlane.
+	CoreDNSUpToDateCondition clusterv1.ConditionType = "CoreDNSUpToDate"
+
+	// CoreDNSUpgradeFailedReason (Severity=Warning) documents a KubeadmControlPlane failing to upgrade
+	// CoreDNS, e.g. because the Corefile cannot be migrated or the target version is not supported.
+	CoreDNSUpgradeFailedReason = "CoreDNSUpgradeFailed"
+
+	// CoreDNSUpgradeSkippedReason (Severity=Info) documents a KubeadmControlPlane not managing CoreDNS upgrades,
+	// e.g. because of the skip-coredns annotation or because the ClusterConfiguration is not set.
+	CoreDNSUpgradeSkippedReason = "CoreDNSUpgradeSkipped"
+)
@@ -40,14 +40,64 @@ const (
 	KubeadmControlPlaneFinalizer = "kubeadm.controlplane.cluster.x-k8s.io"
 
 	// SkipCoreDNSAnnotation annotation explicitly skips reconciling CoreDNS if set.
-	SkipCoreDNSAnnotation = "controlplane.cluster.x-k8s.io/skip-coredns"
+	// The annotation is propagated to the KubeadmConfigs, so CoreDNS is not installed by kubeadm init either.
+	SkipCoreDNSAnnotation = cabpkv1.SkipCoreDNSAnnotation
 
 	// SkipKubeProxyAnnotation annotation explicitly skips reconciling kube-proxy if set.
-	SkipKubeProxyAnnotation = "controlplane.cluster.x-k8s.io/skip-kube-proxy"
+	// The annotation is propagated to the KubeadmConfigs, so kube-proxy is not installed by kubeadm init either.
+	SkipKubeProxyAnnotation = cabpkv1.SkipKubeProxyAnnotation
 
 	// KubeadmClusterConfigurationAnnotation is a machine annotation that stores the json-marshalled string of KCP ClusterConfiguration.
 	// This annotation is used to detect any changes in ClusterConfiguration and trigger machine rollout in KCP.
 	KubeadmClusterConfigurationAnnotation = "controlplane.cluster.x-k8s.io/kubeadm-cluster-configuration"
+
+	// KubeadmFilesContentHashAnnotation is a machine annotation that stores a hash of the content of the Secrets
+	// referenced by the KCP KubeadmConfigSpec.Files, e.g. an audit policy or an encryption configuration.
+	// This annotation is used to detect any changes in the referenced content and trigger machine rollout in KCP.
+	KubeadmFilesContentHashAnnotation = "controlplane.cluster.x-k8s.io/kubeadm-files-content-hash"
+
+	// EtcdVersionAnnotation is a machine annotation that reports the version of the etcd member running on the machine,
+	// as reported by the etcd member itself; it is updated by KCP while checking the etcd members health.
+	EtcdVersionAnnotation = "controlplane.cluster.x-k8s.io/etcd-version"
+
+	// EtcdQuorumRecoveryAnnotation is a KubeadmControlPlane annotation that triggers the recovery of an etcd cluster
+	// which lost quorum; the value is the name of the control plane machine hosting the surviving etcd member.
+	// While the annotation is set KCP does not perform any other operation; after the surviving member has been
+	// restarted as a new single-member etcd cluster, KCP deletes all the other machines, removes the annotation and
+	// scales the control plane back up.
+	EtcdQuorumRecoveryAnnotation = "controlplane.cluster.x-k8s.io/etcd-quorum-recovery"
+
+	// RemediationInProgressAnnotation is a KubeadmControlPlane annotation that tracks the name of the control plane
+	// machine being remediated; it is set by KCP when deleting an unhealthy machine and removed once the control plane
+	// has been scaled back to the desired number of healthy machines, so only one machine is remediated at a time.
+	RemediationInProgressAnnotation = "controlplane.cluster.x-k8s.io/remediation-in-progress"
+
+	// DeletionConfirmedAnnotation is a KubeadmControlPlane annotation that confirms the deletion of a control plane
+	// with deletion protection enabled; it is an alias of the Cluster API core annotation, so clusterctl move can
+	// delete the source objects.
+	DeletionConfirmedAnnotation = clusterv1.DeletionConfirmedAnnotation
+
+	// PatchVersionRollbackAnnotation is a KubeadmControlPlane annotation that allows changing spec.version to a previous
+	// patch version of the same minor version, e.g. to recover from a regression in a patch release; it should be removed
+	// once the rollback is completed. Minor version downgrades are never allowed.
+	PatchVersionRollbackAnnotation = "controlplane.cluster.x-k8s.io/allow-patch-version-rollback"
+
+	// LoadBalancerAttachAnnotation is a machine annotation set by KCP to LoadBalancerHookRequested on new control plane
+	// machines when spec.loadBalancerHooks is enabled; the infrastructure provider sets it to LoadBalancerHookCompleted
+	// once the machine has been added to the API server load balancer.
+	LoadBalancerAttachAnnotation = "controlplane.cluster.x-k8s.io/lb-attach"
+
+	// LoadBalancerDetachAnnotation is a machine annotation set by KCP to LoadBalancerHookRequested on the control plane
+	// machines about to be deleted, e.g. by a scale down or a remediation, when spec.loadBalancerHooks is enabled; the
+	// infrastructure provider sets it to LoadBalancerHookCompleted once the machine has been removed from the API server
+	// load balancer. KCP removes the annotation if the scale down is abandoned.
+	LoadBalancerDetachAnnotation = "controlplane.cluster.x-k8s.io/lb-detach"
+
+	// LoadBalancerHookRequested is the value of the load balancer annotations while KCP waits for the infrastructure provider.
+	LoadBalancerHookRequested = "requested"
+
+	// LoadBalancerHookCompleted is the value of the load balancer annotations acknowledging the load balancer membership change.
+	LoadBalancerHookCompleted = "completed"
 )
 
 // KubeadmControlPlaneSpec defines the desired state of KubeadmControlPlane.
@@ -80,6 +130,36 @@ type KubeadmControlPlaneSpec struct {
 	// new ones.
 	// +optional
 	RolloutStrategy *RolloutStrategy `json:"rolloutStrategy,omitempty"`
+
+	// CloudProviderMigration, if set, migrates the control plane from an in-tree cloud provider to an external one:
+	// the --cloud-provider=external flag is set on the API server, the controller manager and the kubelet of the
+	// control plane machines, and all the machines are replaced with a single rollout.
+	// +optional
+	CloudProviderMigration *CloudProviderMigration `json:"cloudProviderMigration,omitempty"`
+
+	// DeletionProtection, if true, prevents the KubeadmControlPlane from being deleted unless the
+	// cluster.x-k8s.io/deletion-confirmed annotation is set, or the control plane has no machines left.
+	// NOTE: While deletion protection is enabled the deletion of the owning Cluster does not complete
+	// until the deletion of the KubeadmControlPlane is confirmed.
+	// +optional
+	DeletionProtection bool `json:"deletionProtection,omitempty"`
+
+	// LoadBalancerHooks, if true, makes KCP wait for the infrastructure provider to add new control plane machines to
+	// the API server load balancer before continuing a rollout, and to remove the control plane machines from the
+	// load balancer before deleting them, so no traffic is sent to machines being removed.
+	// The infrastructure provider acknowledges the membership changes using the controlplane.cluster.x-k8s.io/lb-attach
+	// and controlplane.cluster.x-k8s.io/lb-detach annotations on the control plane machines; enabling the hooks with an
+	// infrastructure provider that does not support them blocks the rollouts.
+	// +optional
+	LoadBalancerHooks bool `json:"loadBalancerHooks,omitempty"`
+}
+
+// CloudProviderMigration defines the migration of the control plane from an in-tree cloud provider to an external one.
+type CloudProviderMigration struct {
+	// KubeletExtraArgs are additional kubelet args to be set on the control plane machines
+	// once migrated to the external cloud provider, e.g. the provider-id.
+	// +optional
+	KubeletExtraArgs map[string]string `json:"kubeletExtraArgs,omitempty"`
 }
 
 // KubeadmControlPlaneMachineTemplate defines the template for Machines
@@ -99,6 +179,21 @@ type KubeadmControlPlaneMachineTemplate struct {
 	// NOTE: NodeDrainTimeout is different from `kubectl drain --timeout`
 	// +optional
 	NodeDrainTimeout *metav1.Duration `json:"nodeDrainTimeout,omitempty"`
+
+	// NodeVolumeDetachTimeout is the total amount of time that the controller will spend on waiting for all volumes
+	// to be detached from a controlplane node, once the node has been drained.
+	// If not set, the controller waits for the volumes to be detached for 10 minutes; 0 means that the controller
+	// waits for the volumes to be detached without any time limitations. Volumes used by DaemonSet and mirror pods,
+	// and volumes of unreachable nodes, are not waited for.
+	// +optional
+	NodeVolumeDetachTimeout *metav1.Duration `json:"nodeVolumeDetachTimeout,omitempty"`
+
+	// NodeDeletionTimeout is the total amount of time that the controller will spend on trying to delete
+	// a controlplane node, once the infrastructure of the Machine has been deleted.
+	// If not set, the controller tries to delete the node for 10 seconds; 0 means that the controller
+	// retries deleting the node without any time limitations.
+	// +optional
+	NodeDeletionTimeout *metav1.Duration `json:"nodeDeletionTimeout,omitempty"`
 }
 
 // RolloutStrategy describes how to replace existing machines
@@ -120,10 +215,12 @@ type RolloutStrategy struct {
 type RollingUpdate struct {
 	// The maximum number of control planes that can be scheduled above or under the
 	// desired number of control planes.
-	// Value can be an absolute number 1 or 0.
+	// Value can be an absolute number 0, 1, or lower than the number of replicas.
 	// Defaults to 1.
 	// Example: when this is set to 1, the control plane can be scaled
 	// up immediately when the rolling update starts.
+	// When this is greater than 1, machines are replaced in batches of up to maxSurge
+	// machines, created in parallel, so large control planes are upgraded faster.
 	// +optional
 	MaxSurge *intstr.IntOrString `json:"maxSurge,omitempty"`
 }
@@ -42,7 +42,7 @@ func (in *KubeadmControlPlane) SetupWebhookWithManager(mgr ctrl.Manager) error {
 }
 
 // +kubebuilder:webhook:verbs=create;update,path=/mutate-controlplane-cluster-x-k8s-io-v1alpha4-kubeadmcontrolplane,mutating=true,failurePolicy=fail,matchPolicy=Equivalent,groups=controlplane.cluster.x-k8s.io,resources=kubeadmcontrolplanes,versions=v1alpha4,name=default.kubeadmcontrolplane.controlplane.cluster.x-k8s.io,sideEffects=None,admissionReviewVersions=v1;v1beta1
-// +kubebuilder:webhook:verbs=create;update,path=/validate-controlplane-cluster-x-k8s-io-v1alpha4-kubeadmcontrolplane,mutating=false,failurePolicy=fail,matchPolicy=Equivalent,groups=controlplane.cluster.x-k8s.io,resources=kubeadmcontrolplanes;kubeadmcontrolplanes/scale,versions=v1alpha4,name=validation.kubeadmcontrolplane.controlplane.cluster.x-k8s.io,sideEffects=None,admissionReviewVersions=v1;v1beta1
+// +kubebuilder:webhook:verbs=create;update;delete,path=/validate-controlplane-cluster-x-k8s-io-v1alpha4-kubeadmcontrolplane,mutating=false,failurePolicy=fail,matchPolicy=Equivalent,groups=controlplane.cluster.x-k8s.io,resources=kubeadmcontrolplanes;kubeadmcontrolplanes/scale,versions=v1alpha4,name=validation.kubeadmcontrolplane.controlplane.cluster.x-k8s.io,sideEffects=None,admissionReviewVersions=v1;v1beta1
 
 var _ webhook.Defaulter = &KubeadmControlPlane{}
 var _ webhook.Validator = &KubeadmControlPlane{}
@@ -130,6 +130,13 @@ func (in *KubeadmControlPlane) ValidateUpdate(old runtime.Object) error {
 		{spec, kubeadmConfigSpec, postKubeadmCommands},
 		{spec, kubeadmConfigSpec, files},
 		{spec, kubeadmConfigSpec, "verbosity"},
+		{spec, kubeadmConfigSpec, "rotateKubeletServerCertificates"},
+		{spec, kubeadmConfigSpec, "dataSecretMaxSize"},
+		{spec, kubeadmConfigSpec, "gpu", "*"},
+		{spec, kubeadmConfigSpec, "uploadCertificates"},
+		{spec, kubeadmConfigSpec, "imagePullSecrets"},
+		{spec, kubeadmConfigSpec, "renderFileTemplates"},
+		{spec, kubeadmConfigSpec, "secretDirectories"},
 		{spec, kubeadmConfigSpec, users},
 		{spec, kubeadmConfigSpec, ntp, "*"},
 		{spec, "machineTemplate", "metadata"},
@@ -137,8 +144,12 @@ func (in *KubeadmControlPlane) ValidateUpdate(old runtime.Object) error {
 		{spec, "replicas"},
 		{spec, "version"},
 		{spec, "rolloutAfter"},
-		{spec, "nodeDrainTimeout"},
+		{spec, "machineTemplate", "nodeDrainTimeout"},
+		{spec, "machineTemplate", "nodeVolumeDetachTimeout"},
+		{spec, "machineTemplate", "nodeDeletionTimeout"},
 		{spec, "rolloutStrategy", "*"},
+		{spec, "cloudProviderMigration", "*"},
+		{spec, "deletionProtection"},
 	}
 
 	allErrs := in.validateCommon()
@@ -182,6 +193,7 @@ func (in *KubeadmControlPlane) ValidateUpdate(old runtime.Object) error {
 	allErrs = append(allErrs, in.validateVersion(prev.Spec.Version)...)
 	allErrs = append(allErrs, in.validateEtcd(prev)...)
 	allErrs = append(allErrs, in.validateCoreDNSVersion(prev)...)
+	allErrs = append(allErrs, in.validateCloudProviderMigrationUpdate(prev)...)
 
 	if len(allErrs) > 0 {
 		return apierrors.NewInvalid(GroupVersion.WithKind("KubeadmControlPlane").GroupKind(), in.Name, allErrs)
@@ -336,22 +348,65 @@ func (in *KubeadmControlPlane) validateCommon() (allErrs field.ErrorList) {
 			)
 		}
 
-		if *in.Spec.RolloutStrategy.RollingUpdate.MaxSurge != ios1 && *in.Spec.RolloutStrategy.RollingUpdate.MaxSurge != ios0 {
+		// A maxSurge greater than 1 adds more than one etcd member before removing the outdated ones; keeping it lower
+		// than the number of replicas bounds the number of additional members, which join one at a time.
+		maxSurge := *in.Spec.RolloutStrategy.RollingUpdate.MaxSurge
+		if maxSurge.Type != intstr.Int || (maxSurge != ios1 && maxSurge != ios0 && (maxSurge.IntVal < 0 || maxSurge.IntVal >= *in.Spec.Replicas)) {
 			allErrs = append(
 				allErrs,
 				field.Required(
 					field.NewPath("spec", "rolloutStrategy", "rollingUpdate", "maxSurge"),
-					"value must be 1 or 0",
+					"value must be 1, 0, or an integer lower than the number of replicas",
 				),
 			)
 		}
 	}
 
 	allErrs = append(allErrs, in.validateCoreDNSImage()...)
+	allErrs = append(allErrs, in.validateCloudProviderMigration()...)
 
 	return allErrs
 }
 
+func (in *KubeadmControlPlane) validateCloudProviderMigration() (allErrs field.ErrorList) {
+	if in.Spec.CloudProviderMigration == nil {
+		return allErrs
+	}
+
+	// The cloud provider flags are managed by the migration and they can't be overridden.
+	for _, arg := range []string{"cloud-provider", "cloud-config"} {
+		if _, ok := in.Spec.CloudProviderMigration.KubeletExtraArgs[arg]; ok {
+			allErrs = append(
+				allErrs,
+				field.Forbidden(
+					field.NewPath("spec", "cloudProviderMigration", "kubeletExtraArgs", arg),
+					"is managed by the cloud provider migration and cannot be set",
+				),
+			)
+		}
+	}
+	return allErrs
+}
+
+func (in *KubeadmControlPlane) validateCloudProviderMigrationUpdate(prev *KubeadmControlPlane) (allErrs field.ErrorList) {
+	if in.Spec.CloudProviderMigration == nil || prev.Spec.CloudProviderMigration != nil {
+		return allErrs
+	}
+
+	// The migration to the external cloud provider must be performed with a dedicated rollout,
+	// so it is not possible to start it while upgrading the control plane.
+	if in.Spec.Version != prev.Spec.Version {
+		allErrs = append(
+			allErrs,
+			field.Forbidden(
+				field.NewPath("spec", "cloudProviderMigration"),
+				"cannot be set while changing spec.version; the migration to the external cloud provider must be performed separately from upgrades",
+			),
+		)
+	}
+	return allErrs
+}
+
 func (in *KubeadmControlPlane) validateCoreDNSImage() (allErrs field.ErrorList) {
 	if in.Spec.KubeadmConfigSpec.ClusterConfiguration == nil {
 		return allErrs
@@ -464,6 +519,69 @@ func (in *KubeadmControlPlane) validateEtcd(prev *KubeadmControlPlane) (allErrs
 		}
 	}
 
+	allErrs = append(allErrs, in.validateEtcdVersion(prev)...)
+
+	return allErrs
+}
+
+// minimumEtcdVersions defines the minimum etcd version compatible with a Kubernetes version;
+// entries must be sorted by Kubernetes version.
+var minimumEtcdVersions = []struct {
+	kubernetes semver.Version
+	etcd       semver.Version
+}{
+	{kubernetes: semver.MustParse("1.16.0"), etcd: semver.MustParse("3.2.18")},
+	{kubernetes: semver.MustParse("1.22.0"), etcd: semver.MustParse("3.4.13")},
+}
+
+// validateEtcdVersion validates the local etcd image tag, if set, ensuring it is compatible with the
+// Kubernetes version and, in case of updates, that it is not a downgrade of the current etcd version.
+func (in *KubeadmControlPlane) validateEtcdVersion(prev *KubeadmControlPlane) (allErrs field.ErrorList) {
+	if in.Spec.KubeadmConfigSpec.ClusterConfiguration.Etcd.Local == nil || in.Spec.KubeadmConfigSpec.ClusterConfiguration.Etcd.Local.ImageTag == "" {
+		return allErrs
+	}
+	imageTag := in.Spec.KubeadmConfigSpec.ClusterConfiguration.Etcd.Local.ImageTag
+	imageTagPath := field.NewPath("spec", "kubeadmConfigSpec", "clusterConfiguration", "etcd", "local", "imageTag")
+
+	// NOTE: custom tags not following the etcd versioning scheme can't be validated.
+	toVersion, err := version.ParseMajorMinorPatchTolerant(imageTag)
+	if err != nil {
+		return allErrs
+	}
+
+	if kubernetesVersion, err := version.ParseMajorMinorPatchTolerant(in.Spec.Version); err == nil {
+		var minimumEtcdVersion *semver.Version
+		for i := range minimumEtcdVersions {
+			if kubernetesVersion.GTE(minimumEtcdVersions[i].kubernetes) {
+				minimumEtcdVersion = &minimumEtcdVersions[i].etcd
+			}
+		}
+		if minimumEtcdVersion != nil && toVersion.LT(*minimumEtcdVersion) {
+			allErrs = append(allErrs,
+				field.Forbidden(
+					imageTagPath,
+					fmt.Sprintf("etcd version %s is not compatible with Kubernetes version %s, etcd %s or higher is required", toVersion, in.Spec.Version, minimumEtcdVersion),
+				),
+			)
+		}
+	}
+
+	if prev == nil || prev.Spec.KubeadmConfigSpec.ClusterConfiguration == nil || prev.Spec.KubeadmConfigSpec.ClusterConfiguration.Etcd.Local == nil {
+		return allErrs
+	}
+	fromVersion, err := version.ParseMajorMinorPatchTolerant(prev.Spec.KubeadmConfigSpec.ClusterConfiguration.Etcd.Local.ImageTag)
+	if err != nil {
+		return allErrs
+	}
+	if toVersion.LT(fromVersion) {
+		allErrs = append(allErrs,
+			field.Forbidden(
+				imageTagPath,
+				fmt.Sprintf("cannot downgrade etcd from %s to %s", fromVersion, toVersion),
+			),
+		)
+	}
+
 	return allErrs
 }
 
@@ -503,6 +621,30 @@ func (in *KubeadmControlPlane) validateVersion(previousVersion string) (allErrs
 		return allErrs
 	}
 
+	// Minor version downgrades are not supported by kubeadm.
+	if toVersion.Major < fromVersion.Major || (toVersion.Major == fromVersion.Major && toVersion.Minor < fromVersion.Minor) {
+		allErrs = append(allErrs,
+			field.Forbidden(
+				field.NewPath("spec", "version"),
+				fmt.Sprintf("cannot downgrade Kubernetes version from %s to %s", previousVersion, in.Spec.Version),
+			),
+		)
+		return allErrs
+	}
+
+	// Rollbacks to a previous patch version are allowed only if explicitly requested.
+	if toVersion.Major == fromVersion.Major && toVersion.Minor == fromVersion.Minor && toVersion.Patch < fromVersion.Patch {
+		if _, ok := in.Annotations[PatchVersionRollbackAnnotation]; !ok {
+			allErrs = append(allErrs,
+				field.Forbidden(
+					field.NewPath("spec", "version"),
+					fmt.Sprintf("cannot roll back Kubernetes version from %s to %s unless the %q annotation is set", previousVersion, in.Spec.Version, PatchVersionRollbackAnnotation),
+				),
+			)
+		}
+		return allErrs
+	}
+
 	// Since upgrades to the next minor version are allowed, irrespective of the patch version.
 	ceilVersion := semver.Version{
 		Major: fromVersion.Major,
@@ -523,5 +665,21 @@ func (in *KubeadmControlPlane) validateVersion(previousVersion string) (allErrs
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type.
 func (in *KubeadmControlPlane) ValidateDelete() error {
-	return nil
+	if !in.Spec.DeletionProtection {
+		return nil
+	}
+
+	// Allow the deletion if it has been explicitly confirmed, or if there are no machines left to be deleted.
+	if _, ok := in.Annotations[DeletionConfirmedAnnotation]; ok {
+		return nil
+	}
+	if in.Status.Replicas == 0 {
+		return nil
+	}
+
+	return apierrors.NewForbidden(
+		GroupVersion.WithResource("kubeadmcontrolplanes").GroupResource(),
+		in.Name,
+		errors.Errorf("deletion protection is enabled: set the %q annotation or disable spec.deletionProtection to delete the KubeadmControlPlane", DeletionConfirmedAnnotation),
+	)
 }
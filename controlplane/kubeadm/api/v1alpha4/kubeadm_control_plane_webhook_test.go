@@ -96,6 +96,14 @@ func TestKubeadmControlPlaneValidateCreate(t *testing.T) {
 	invalidMaxSurge := valid.DeepCopy()
 	invalidMaxSurge.Spec.RolloutStrategy.RollingUpdate.MaxSurge.IntVal = int32(3)
 
+	validMaxSurgeLowerThanReplicas := valid.DeepCopy()
+	validMaxSurgeLowerThanReplicas.Spec.Replicas = pointer.Int32Ptr(5)
+	validMaxSurgeLowerThanReplicas.Spec.RolloutStrategy.RollingUpdate.MaxSurge.IntVal = int32(2)
+
+	invalidMaxSurgeEqualToReplicas := valid.DeepCopy()
+	invalidMaxSurgeEqualToReplicas.Spec.Replicas = pointer.Int32Ptr(3)
+	invalidMaxSurgeEqualToReplicas.Spec.RolloutStrategy.RollingUpdate.MaxSurge.IntVal = int32(3)
+
 	invalidNamespace := valid.DeepCopy()
 	invalidNamespace.Spec.MachineTemplate.InfrastructureRef.Namespace = "bar"
 
@@ -181,6 +189,16 @@ func TestKubeadmControlPlaneValidateCreate(t *testing.T) {
 			expectErr: true,
 			kcp:       invalidMaxSurge,
 		},
+		{
+			name:      "should succeed when maxSurge is lower than the number of replicas",
+			expectErr: false,
+			kcp:       validMaxSurgeLowerThanReplicas,
+		},
+		{
+			name:      "should return error when maxSurge is not lower than the number of replicas",
+			expectErr: true,
+			kcp:       invalidMaxSurgeEqualToReplicas,
+		},
 	}
 
 	for _, tt := range tests {
@@ -325,6 +343,9 @@ func TestKubeadmControlPlaneValidateUpdate(t *testing.T) {
 	validUpdate.Spec.Replicas = pointer.Int32Ptr(5)
 	now := metav1.NewTime(time.Now())
 	validUpdate.Spec.RolloutAfter = &now
+	validUpdate.Spec.MachineTemplate.NodeDrainTimeout = &metav1.Duration{Duration: time.Second}
+	validUpdate.Spec.MachineTemplate.NodeVolumeDetachTimeout = &metav1.Duration{Duration: time.Second}
+	validUpdate.Spec.MachineTemplate.NodeDeletionTimeout = &metav1.Duration{Duration: time.Second}
 
 	scaleToZero := before.DeepCopy()
 	scaleToZero.Spec.Replicas = pointer.Int32Ptr(0)
@@ -359,6 +380,16 @@ func TestKubeadmControlPlaneValidateUpdate(t *testing.T) {
 		},
 	}
 
+	etcdLocalImageTagDowngrade := etcdLocalImageTag.DeepCopy()
+	etcdLocalImageTagDowngrade.Spec.KubeadmConfigSpec.ClusterConfiguration.Etcd.Local.ImageTag = "v9.0.0"
+
+	etcdLocalImageTagIncompatible := before.DeepCopy()
+	etcdLocalImageTagIncompatible.Spec.KubeadmConfigSpec.ClusterConfiguration.Etcd.Local = &bootstrapv1.LocalEtcd{
+		ImageMeta: bootstrapv1.ImageMeta{
+			ImageTag: "3.2.13-0",
+		},
+	}
+
 	unsetEtcd := etcdLocalImageTag.DeepCopy()
 	unsetEtcd.Spec.KubeadmConfigSpec.ClusterConfiguration.Etcd.Local = nil
 
@@ -531,6 +562,22 @@ func TestKubeadmControlPlaneValidateUpdate(t *testing.T) {
 	disableNTPServers := before.DeepCopy()
 	disableNTPServers.Spec.KubeadmConfigSpec.NTP.Enabled = pointer.BoolPtr(false)
 
+	startCloudProviderMigration := before.DeepCopy()
+	startCloudProviderMigration.Spec.CloudProviderMigration = &CloudProviderMigration{
+		KubeletExtraArgs: map[string]string{"provider-id": "aws:///us-east-1a/i-1234"},
+	}
+
+	startCloudProviderMigrationWithUpgrade := startCloudProviderMigration.DeepCopy()
+	startCloudProviderMigrationWithUpgrade.Spec.Version = "v1.17.0"
+
+	cloudProviderMigrationWithCloudProviderArg := startCloudProviderMigration.DeepCopy()
+	cloudProviderMigrationWithCloudProviderArg.Spec.CloudProviderMigration.KubeletExtraArgs["cloud-provider"] = "aws"
+
+	upgradeDuringCloudProviderMigration := startCloudProviderMigrationWithUpgrade.DeepCopy()
+
+	enableDeletionProtection := before.DeepCopy()
+	enableDeletionProtection.Spec.DeletionProtection = true
+
 	tests := []struct {
 		name      string
 		expectErr bool
@@ -621,6 +668,18 @@ func TestKubeadmControlPlaneValidateUpdate(t *testing.T) {
 			before:    before,
 			kcp:       etcdLocalImageInvalidTag,
 		},
+		{
+			name:      "should fail when downgrading the local etcd image tag",
+			expectErr: true,
+			before:    etcdLocalImageTag,
+			kcp:       etcdLocalImageTagDowngrade,
+		},
+		{
+			name:      "should fail when using an etcd image tag not compatible with the Kubernetes version",
+			expectErr: true,
+			before:    before,
+			kcp:       etcdLocalImageTagIncompatible,
+		},
 		{
 			name:      "should fail when making a change to the cluster config's networking struct",
 			expectErr: true,
@@ -819,6 +878,36 @@ func TestKubeadmControlPlaneValidateUpdate(t *testing.T) {
 			before:    before,
 			kcp:       disableNTPServers,
 		},
+		{
+			name:      "should allow starting the cloud provider migration",
+			expectErr: false,
+			before:    before,
+			kcp:       startCloudProviderMigration,
+		},
+		{
+			name:      "should return an error when starting the cloud provider migration while changing the version",
+			expectErr: true,
+			before:    before,
+			kcp:       startCloudProviderMigrationWithUpgrade,
+		},
+		{
+			name:      "should return an error when the cloud provider migration sets the cloud-provider kubelet arg",
+			expectErr: true,
+			before:    before,
+			kcp:       cloudProviderMigrationWithCloudProviderArg,
+		},
+		{
+			name:      "should allow changing the version once the cloud provider migration is started",
+			expectErr: false,
+			before:    startCloudProviderMigration,
+			kcp:       upgradeDuringCloudProviderMigration,
+		},
+		{
+			name:      "should allow enabling deletion protection",
+			expectErr: false,
+			before:    before,
+			kcp:       enableDeletionProtection,
+		},
 	}
 
 	for _, tt := range tests {
@@ -889,6 +978,111 @@ func TestKubeadmControlPlaneValidateUpdateAfterDefaulting(t *testing.T) {
 	}
 }
 
+func TestKubeadmControlPlaneValidateDelete(t *testing.T) {
+	kcp := &KubeadmControlPlane{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "foo",
+		},
+		Status: KubeadmControlPlaneStatus{
+			Replicas: 3,
+		},
+	}
+
+	protected := kcp.DeepCopy()
+	protected.Spec.DeletionProtection = true
+
+	protectedAndConfirmed := protected.DeepCopy()
+	protectedAndConfirmed.Annotations = map[string]string{DeletionConfirmedAnnotation: ""}
+
+	protectedWithoutMachines := protected.DeepCopy()
+	protectedWithoutMachines.Status.Replicas = 0
+
+	tests := []struct {
+		name      string
+		expectErr bool
+		kcp       *KubeadmControlPlane
+	}{
+		{
+			name:      "should allow deletion when deletion protection is disabled",
+			expectErr: false,
+			kcp:       kcp,
+		},
+		{
+			name:      "should return an error when deletion protection is enabled",
+			expectErr: true,
+			kcp:       protected,
+		},
+		{
+			name:      "should allow deletion when deletion protection is enabled and the deletion is confirmed",
+			expectErr: false,
+			kcp:       protectedAndConfirmed,
+		},
+		{
+			name:      "should allow deletion when deletion protection is enabled and there are no machines",
+			expectErr: false,
+			kcp:       protectedWithoutMachines,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			err := tt.kcp.ValidateDelete()
+			if tt.expectErr {
+				g.Expect(err).To(HaveOccurred())
+			} else {
+				g.Expect(err).To(Succeed())
+			}
+		})
+	}
+}
+
+func TestKubeadmControlPlaneValidateVersion(t *testing.T) {
+	tests := []struct {
+		name               string
+		fromVersion        string
+		toVersion          string
+		allowPatchRollback bool
+		expectErr          bool
+	}{
+		{name: "same version", fromVersion: "v1.20.4", toVersion: "v1.20.4"},
+		{name: "patch upgrade", fromVersion: "v1.20.4", toVersion: "v1.20.6"},
+		{name: "minor upgrade", fromVersion: "v1.20.4", toVersion: "v1.21.0"},
+		{name: "minor upgrade to an older patch version", fromVersion: "v1.20.4", toVersion: "v1.21.1"},
+		{name: "upgrade skipping a minor version", fromVersion: "v1.20.4", toVersion: "v1.22.0", expectErr: true},
+		{name: "major upgrade", fromVersion: "v1.20.4", toVersion: "v2.0.0", expectErr: true},
+		{name: "patch rollback", fromVersion: "v1.20.4", toVersion: "v1.20.2", expectErr: true},
+		{name: "patch rollback with annotation", fromVersion: "v1.20.4", toVersion: "v1.20.2", allowPatchRollback: true},
+		{name: "minor downgrade", fromVersion: "v1.21.0", toVersion: "v1.20.4", expectErr: true},
+		{name: "minor downgrade with annotation", fromVersion: "v1.21.0", toVersion: "v1.20.4", allowPatchRollback: true, expectErr: true},
+		{name: "minor downgrade to a newer patch version", fromVersion: "v1.21.0", toVersion: "v1.20.9", expectErr: true},
+		{name: "major downgrade", fromVersion: "v2.0.0", toVersion: "v1.20.4", expectErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			kcp := &KubeadmControlPlane{
+				Spec: KubeadmControlPlaneSpec{
+					Version: tt.toVersion,
+				},
+			}
+			if tt.allowPatchRollback {
+				kcp.Annotations = map[string]string{PatchVersionRollbackAnnotation: ""}
+			}
+
+			allErrs := kcp.validateVersion(tt.fromVersion)
+			if tt.expectErr {
+				g.Expect(allErrs).NotTo(BeEmpty())
+			} else {
+				g.Expect(allErrs).To(BeEmpty())
+			}
+		})
+	}
+}
+
 func TestPathsMatch(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -19,6 +19,7 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/blang/semver"
@@ -54,6 +55,7 @@ import (
 
 // +kubebuilder:rbac:groups=core,resources=events,verbs=get;list;watch;create;patch
 // +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io;bootstrap.cluster.x-k8s.io;controlplane.cluster.x-k8s.io,resources=*,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters;clusters/status,verbs=get;list;watch
 // +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machines;machines/status,verbs=get;list;watch;create;update;patch;delete
@@ -66,11 +68,37 @@ type KubeadmControlPlaneReconciler struct {
 	Tracker          *remote.ClusterCacheTracker
 	WatchFilterValue string
 
+	// KubeletServingCertApproverImage is the image of the approver of kubelet serving certificate signing requests
+	// deployed in the workload clusters of the KubeadmControlPlanes setting RotateKubeletServerCertificates.
+	// If empty, the approver is not deployed.
+	KubeletServingCertApproverImage string
+
 	managementCluster         internal.ManagementCluster
 	managementClusterUncached internal.ManagementCluster
 }
 
+const (
+	// kcpFileSecretIndex is used to index the KubeadmControlPlanes by the names of the Secrets referenced by their files.
+	kcpFileSecretIndex = "spec.kubeadmConfigSpec.files.contentFrom.secret.name"
+
+	// kcpFileConfigMapIndex is used to index the KubeadmControlPlanes by the names of the ConfigMaps referenced by their files.
+	kcpFileConfigMapIndex = "spec.kubeadmConfigSpec.files.contentFrom.configMap.name"
+)
+
 func (r *KubeadmControlPlaneReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, options controller.Options) error {
+	if err := mgr.GetFieldIndexer().IndexField(ctx, &controlplanev1.KubeadmControlPlane{},
+		kcpFileSecretIndex,
+		indexKubeadmControlPlaneByFileSecret,
+	); err != nil {
+		return errors.Wrap(err, "error setting index fields for KubeadmControlPlanes")
+	}
+	if err := mgr.GetFieldIndexer().IndexField(ctx, &controlplanev1.KubeadmControlPlane{},
+		kcpFileConfigMapIndex,
+		indexKubeadmControlPlaneByFileConfigMap,
+	); err != nil {
+		return errors.Wrap(err, "error setting index fields for KubeadmControlPlanes")
+	}
+
 	c, err := ctrl.NewControllerManagedBy(mgr).
 		For(&controlplanev1.KubeadmControlPlane{}).
 		Owns(&clusterv1.Machine{}).
@@ -93,6 +121,28 @@ func (r *KubeadmControlPlaneReconciler) SetupWithManager(ctx context.Context, mg
 		return errors.Wrap(err, "failed adding Watch for Clusters to controller manager")
 	}
 
+	// Watch the metadata of the Secrets and ConfigMaps referenced by the KCP files, so changes to their content trigger
+	// a rollout; only metadata is cached given that Secrets and ConfigMaps are not cached by the KCP manager.
+	secretMetadata := &metav1.PartialObjectMetadata{}
+	secretMetadata.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("Secret"))
+	err = c.Watch(
+		&source.Kind{Type: secretMetadata},
+		handler.EnqueueRequestsFromMapFunc(r.secretToKubeadmControlPlane),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed adding Watch for Secrets to controller manager")
+	}
+
+	configMapMetadata := &metav1.PartialObjectMetadata{}
+	configMapMetadata.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("ConfigMap"))
+	err = c.Watch(
+		&source.Kind{Type: configMapMetadata},
+		handler.EnqueueRequestsFromMapFunc(r.configMapToKubeadmControlPlane),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed adding Watch for ConfigMaps to controller manager")
+	}
+
 	r.controller = c
 	r.recorder = mgr.GetEventRecorderFor("kubeadm-control-plane-controller")
 
@@ -180,7 +230,12 @@ func (r *KubeadmControlPlaneReconciler) Reconcile(ctx context.Context, req ctrl.
 		}
 
 		// Always attempt to Patch the KubeadmControlPlane object and status after each reconciliation.
-		if err := patchKubeadmControlPlane(ctx, patchHelper, kcp); err != nil {
+		// Patch ObservedGeneration only if the reconciliation completed successfully
+		patchOpts := []patch.Option{}
+		if reterr == nil {
+			patchOpts = append(patchOpts, patch.WithStatusObservedGeneration{})
+		}
+		if err := patchKubeadmControlPlane(ctx, patchHelper, kcp, patchOpts...); err != nil {
 			log.Error(err, "Failed to patch KubeadmControlPlane")
 			reterr = kerrors.NewAggregate([]error{reterr, err})
 		}
@@ -204,7 +259,7 @@ func (r *KubeadmControlPlaneReconciler) Reconcile(ctx context.Context, req ctrl.
 	return r.reconcile(ctx, cluster, kcp)
 }
 
-func patchKubeadmControlPlane(ctx context.Context, patchHelper *patch.Helper, kcp *controlplanev1.KubeadmControlPlane) error {
+func patchKubeadmControlPlane(ctx context.Context, patchHelper *patch.Helper, kcp *controlplanev1.KubeadmControlPlane, options ...patch.Option) error {
 	// Always update the readyCondition by summarizing the state of other conditions.
 	conditions.SetSummary(kcp,
 		conditions.WithConditions(
@@ -218,9 +273,7 @@ func patchKubeadmControlPlane(ctx context.Context, patchHelper *patch.Helper, kc
 	)
 
 	// Patch the object, ignoring conflicts on the conditions owned by this controller.
-	return patchHelper.Patch(
-		ctx,
-		kcp,
+	options = append(options,
 		patch.WithOwnedConditions{Conditions: []clusterv1.ConditionType{
 			controlplanev1.MachinesCreatedCondition,
 			clusterv1.ReadyCondition,
@@ -229,9 +282,12 @@ func patchKubeadmControlPlane(ctx context.Context, patchHelper *patch.Helper, kc
 			controlplanev1.MachinesReadyCondition,
 			controlplanev1.AvailableCondition,
 			controlplanev1.CertificatesAvailableCondition,
+			controlplanev1.CoreDNSUpToDateCondition,
+			controlplanev1.EtcdQuorumRecoveredCondition,
+			controlplanev1.EtcdMembersPromotedCondition,
 		}},
-		patch.WithStatusObservedGeneration{},
 	)
+	return patchHelper.Patch(ctx, kcp, options...)
 }
 
 // reconcile handles KubeadmControlPlane reconciliation.
@@ -302,6 +358,19 @@ func (r *KubeadmControlPlaneReconciler) reconcile(ctx context.Context, cluster *
 	// source ref (reason@machine/name) so the problem can be easily tracked down to its source machine.
 	conditions.SetAggregate(controlPlane.KCP, controlplanev1.MachinesReadyCondition, ownedMachines.ConditionGetters(), conditions.AddSourceRef(), conditions.WithStepCounterIf(false))
 
+	// Propagate the node drain and deletion timeouts to the existing machines, so they apply to the machines
+	// being deleted during a rollout as well.
+	if err := r.syncMachineDeletionTimeouts(ctx, controlPlane); err != nil {
+		log.Error(err, "failed to sync the deletion timeouts of the control plane machines")
+		return ctrl.Result{}, err
+	}
+
+	// Recovering etcd quorum, if requested by the user, takes precedence over all the other operations, which
+	// could not complete anyway while etcd quorum is lost.
+	if result, err := r.reconcileEtcdQuorumRecovery(ctx, controlPlane); err != nil || !result.IsZero() {
+		return result, err
+	}
+
 	// Updates conditions reporting the status of static pods and the status of the etcd cluster.
 	// NOTE: Conditions reporting KCP operation progress like e.g. Resized or SpecUpToDate are inlined with the rest of the execution.
 	if result, err := r.reconcileControlPlaneConditions(ctx, controlPlane); err != nil || !result.IsZero() {
@@ -314,12 +383,27 @@ func (r *KubeadmControlPlaneReconciler) reconcile(ctx context.Context, cluster *
 		return result, err
 	}
 
+	// Promotes etcd members joined as learners, if any.
+	// NOTE: Scale operations are blocked by preflight checks until all the learners are promoted; failures are only
+	// surfaced in the EtcdMembersPromoted condition, so they do not block the remediation of unhealthy machines,
+	// which could be required exactly to recover etcd.
+	if err := r.reconcileEtcdLearners(ctx, controlPlane); err != nil {
+		log.Error(err, "Failed to promote etcd learners")
+	}
+
 	// Reconcile unhealthy machines by triggering deletion and requeue if it is considered safe to remediate,
 	// otherwise continue with the other KCP operations.
 	if result, err := r.reconcileUnhealthyMachines(ctx, controlPlane); err != nil || !result.IsZero() {
 		return result, err
 	}
 
+	// Changes to the content of the Secrets and ConfigMaps referenced by the KCP files, e.g. the audit policy, trigger
+	// a rollout as well; if a referenced Secret or ConfigMap does not exist, the rollout is delayed until it is created.
+	if err := controlPlane.FilesContentHashError(); err != nil && !errors.Is(err, internal.ErrFilesContentNotFound) {
+		log.Error(err, "failed to compute the hash of the files content")
+		return ctrl.Result{}, err
+	}
+
 	// Control plane machines rollout due to configuration changes (e.g. upgrades) takes precedence over other operations.
 	needRollout := controlPlane.MachinesNeedingRollout()
 	switch {
@@ -340,6 +424,14 @@ func (r *KubeadmControlPlaneReconciler) reconcile(ctx context.Context, cluster *
 	numMachines := len(ownedMachines)
 	desiredReplicas := int(*kcp.Spec.Replicas)
 
+	// If a scale down has been abandoned, e.g. because the replicas have been raised, add the machine selected for
+	// scale down back to the API server load balancer.
+	if numMachines <= desiredReplicas {
+		if err := r.cancelLoadBalancerDetach(ctx, controlPlane); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
 	switch {
 	// We are creating the first replica
 	case numMachines < desiredReplicas && numMachines == 0:
@@ -371,6 +463,18 @@ func (r *KubeadmControlPlaneReconciler) reconcile(ctx context.Context, cluster *
 		return ctrl.Result{}, errors.Wrap(err, "failed to set role and role binding for kubeadm")
 	}
 
+	// Ensure the RBAC rules required for approving kubelet serving certificates, and the approver if configured, if requested.
+	if kcp.Spec.KubeadmConfigSpec.RotateKubeletServerCertificates {
+		if err := workloadCluster.AllowKubeletServingCertificateApproval(ctx); err != nil {
+			return ctrl.Result{}, errors.Wrap(err, "failed to set service account, role and role binding for the kubelet serving certificate approver")
+		}
+		if r.KubeletServingCertApproverImage != "" {
+			if err := workloadCluster.EnsureKubeletServingCertApprover(ctx, r.KubeletServingCertApproverImage); err != nil {
+				return ctrl.Result{}, errors.Wrap(err, "failed to deploy the kubelet serving certificate approver")
+			}
+		}
+	}
+
 	// Update kube-proxy daemonset.
 	if err := workloadCluster.UpdateKubeProxyImageInfo(ctx, kcp); err != nil {
 		log.Error(err, "failed to update kube-proxy daemonset")
@@ -378,18 +482,41 @@ func (r *KubeadmControlPlaneReconciler) reconcile(ctx context.Context, cluster *
 	}
 
 	// Update CoreDNS deployment.
+	if err := r.reconcileCoreDNS(ctx, kcp, workloadCluster); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// reconcileCoreDNS upgrades the CoreDNS deployment in the workload cluster according to the DNS configuration
+// defined in the KubeadmControlPlane, and surfaces the result in the CoreDNSUpToDate condition.
+func (r *KubeadmControlPlaneReconciler) reconcileCoreDNS(ctx context.Context, kcp *controlplanev1.KubeadmControlPlane, workloadCluster internal.WorkloadCluster) error {
+	// If CoreDNS is not managed by KCP, report why the condition does not apply.
+	if _, ok := kcp.Annotations[controlplanev1.SkipCoreDNSAnnotation]; ok {
+		conditions.MarkFalse(kcp, controlplanev1.CoreDNSUpToDateCondition, controlplanev1.CoreDNSUpgradeSkippedReason, clusterv1.ConditionSeverityInfo,
+			"CoreDNS upgrades are skipped because of the %s annotation", controlplanev1.SkipCoreDNSAnnotation)
+		return nil
+	}
+	if kcp.Spec.KubeadmConfigSpec.ClusterConfiguration == nil {
+		conditions.MarkFalse(kcp, controlplanev1.CoreDNSUpToDateCondition, controlplanev1.CoreDNSUpgradeSkippedReason, clusterv1.ConditionSeverityInfo,
+			"CoreDNS upgrades are skipped because the ClusterConfiguration is not set")
+		return nil
+	}
+
 	// We intentionally only parse major/minor/patch so that the subsequent code
 	// also already applies to beta versions of new releases.
 	parsedVersion, err := version.ParseMajorMinorPatchTolerant(kcp.Spec.Version)
 	if err != nil {
-		return ctrl.Result{}, errors.Wrapf(err, "failed to parse kubernetes version %q", kcp.Spec.Version)
+		return errors.Wrapf(err, "failed to parse kubernetes version %q", kcp.Spec.Version)
 	}
 
 	if err := workloadCluster.UpdateCoreDNS(ctx, kcp, parsedVersion); err != nil {
-		return ctrl.Result{}, errors.Wrap(err, "failed to update CoreDNS deployment")
+		conditions.MarkFalse(kcp, controlplanev1.CoreDNSUpToDateCondition, controlplanev1.CoreDNSUpgradeFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+		return errors.Wrap(err, "failed to update CoreDNS deployment")
 	}
-
-	return ctrl.Result{}, nil
+	conditions.MarkTrue(kcp, controlplanev1.CoreDNSUpToDateCondition)
+	return nil
 }
 
 // reconcileDelete handles KubeadmControlPlane deletion.
@@ -451,6 +578,18 @@ func (r *KubeadmControlPlaneReconciler) reconcileDelete(ctx context.Context, clu
 	for i := range machinesToDelete {
 		m := machinesToDelete[i]
 		logger := log.WithValues("machine", m)
+		// If the load balancer hooks are enabled, remove the machine from the API server load balancer before deleting it.
+		if kcp.Spec.LoadBalancerHooks {
+			result, err := r.detachFromLoadBalancer(ctx, controlPlane, m)
+			if err != nil {
+				logger.Error(err, "Failed to remove owned machine from the API server load balancer")
+				errs = append(errs, err)
+				continue
+			}
+			if !result.IsZero() {
+				continue
+			}
+		}
 		if err := r.Client.Delete(ctx, machinesToDelete[i]); err != nil && !apierrors.IsNotFound(err) {
 			logger.Error(err, "Failed to cleanup owned machine")
 			errs = append(errs, err)
@@ -482,6 +621,78 @@ func (r *KubeadmControlPlaneReconciler) ClusterToKubeadmControlPlane(o client.Ob
 	return nil
 }
 
+// secretToKubeadmControlPlane is a handler.ToRequestsFunc to be used to enqueue requests for reconciliation
+// for the KubeadmControlPlanes with files referencing the Secret.
+func (r *KubeadmControlPlaneReconciler) secretToKubeadmControlPlane(o client.Object) []ctrl.Request {
+	return r.kubeadmControlPlanesReferencingFileSource(o, kcpFileSecretIndex, fileSecretNames)
+}
+
+// configMapToKubeadmControlPlane is a handler.ToRequestsFunc to be used to enqueue requests for reconciliation
+// for the KubeadmControlPlanes with files referencing the ConfigMap.
+func (r *KubeadmControlPlaneReconciler) configMapToKubeadmControlPlane(o client.Object) []ctrl.Request {
+	return r.kubeadmControlPlanesReferencingFileSource(o, kcpFileConfigMapIndex, fileConfigMapNames)
+}
+
+// kubeadmControlPlanesReferencingFileSource returns the requests for the KubeadmControlPlanes with files referencing
+// the object; only the KubeadmControlPlanes indexed by the object name are listed, so events for Secrets and ConfigMaps
+// not referenced by any KubeadmControlPlane are cheap.
+func (r *KubeadmControlPlaneReconciler) kubeadmControlPlanesReferencingFileSource(o client.Object, index string, names func(*controlplanev1.KubeadmControlPlane) []string) []ctrl.Request {
+	kcpList := &controlplanev1.KubeadmControlPlaneList{}
+	if err := r.Client.List(context.TODO(), kcpList, client.InNamespace(o.GetNamespace()), client.MatchingFields{index: o.GetName()}); err != nil {
+		return nil
+	}
+
+	result := []ctrl.Request{}
+	for i := range kcpList.Items {
+		kcp := &kcpList.Items[i]
+		for _, name := range names(kcp) {
+			if name == o.GetName() {
+				result = append(result, ctrl.Request{NamespacedName: client.ObjectKey{Namespace: kcp.Namespace, Name: kcp.Name}})
+				break
+			}
+		}
+	}
+	return result
+}
+
+func indexKubeadmControlPlaneByFileSecret(o client.Object) []string {
+	kcp, ok := o.(*controlplanev1.KubeadmControlPlane)
+	if !ok {
+		panic(fmt.Sprintf("Expected a KubeadmControlPlane but got a %T", o))
+	}
+	return fileSecretNames(kcp)
+}
+
+func indexKubeadmControlPlaneByFileConfigMap(o client.Object) []string {
+	kcp, ok := o.(*controlplanev1.KubeadmControlPlane)
+	if !ok {
+		panic(fmt.Sprintf("Expected a KubeadmControlPlane but got a %T", o))
+	}
+	return fileConfigMapNames(kcp)
+}
+
+// fileSecretNames returns the names of the Secrets referenced by the KCP files.
+func fileSecretNames(kcp *controlplanev1.KubeadmControlPlane) []string {
+	names := []string{}
+	for _, file := range kcp.Spec.KubeadmConfigSpec.Files {
+		if file.ContentFrom != nil && file.ContentFrom.ConfigMap == nil && file.ContentFrom.Secret.Name != "" {
+			names = append(names, file.ContentFrom.Secret.Name)
+		}
+	}
+	return names
+}
+
+// fileConfigMapNames returns the names of the ConfigMaps referenced by the KCP files.
+func fileConfigMapNames(kcp *controlplanev1.KubeadmControlPlane) []string {
+	names := []string{}
+	for _, file := range kcp.Spec.KubeadmConfigSpec.Files {
+		if file.ContentFrom != nil && file.ContentFrom.ConfigMap != nil {
+			names = append(names, file.ContentFrom.ConfigMap.Name)
+		}
+	}
+	return names
+}
+
 // reconcileControlPlaneConditions is responsible of reconciling conditions reporting the status of static pods and
 // the status of the etcd cluster.
 func (r *KubeadmControlPlaneReconciler) reconcileControlPlaneConditions(ctx context.Context, controlPlane *internal.ControlPlane) (ctrl.Result, error) {
@@ -565,6 +776,86 @@ func (r *KubeadmControlPlaneReconciler) reconcileEtcdMembers(ctx context.Context
 	return ctrl.Result{}, nil
 }
 
+// reconcileEtcdLearnerMode enables joining new etcd members as learners, which do not count toward quorum while
+// catching up with the leader, when supported by both the Kubernetes and the etcd version.
+// NOTE: This requires contacting the etcd leader and reading the kubeadm-config ConfigMap, so it is called only
+// before joining new control plane machines.
+func (r *KubeadmControlPlaneReconciler) reconcileEtcdLearnerMode(ctx context.Context, controlPlane *internal.ControlPlane) error {
+	if !controlPlane.IsEtcdManaged() {
+		return nil
+	}
+
+	workloadCluster, err := r.managementCluster.GetWorkloadCluster(ctx, util.ObjectKey(controlPlane.Cluster))
+	if err != nil {
+		return errors.Wrap(err, "cannot get remote client to workload cluster")
+	}
+
+	parsedVersion, err := semver.ParseTolerant(controlPlane.KCP.Spec.Version)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse kubernetes version %q", controlPlane.KCP.Spec.Version)
+	}
+
+	enabled, err := workloadCluster.ReconcileEtcdLearnerMode(ctx, parsedVersion)
+	if err != nil {
+		return errors.Wrap(err, "failed to reconcile etcd learner mode")
+	}
+	if !enabled {
+		conditions.Delete(controlPlane.KCP, controlplanev1.EtcdMembersPromotedCondition)
+		return nil
+	}
+	// The condition is initialized before the first learner joins, so rollouts are serialized from now on.
+	if !conditions.Has(controlPlane.KCP, controlplanev1.EtcdMembersPromotedCondition) {
+		conditions.MarkTrue(controlPlane.KCP, controlplanev1.EtcdMembersPromotedCondition)
+	}
+	return nil
+}
+
+// reconcileEtcdLearners promotes to voting members the etcd learners in sync with the leader, and surfaces progress
+// using the EtcdMembersPromoted condition.
+// NOTE: The learners are detected while checking the etcd members health, see reconcileControlPlaneConditions, so
+// the etcd leader is contacted only if there are learners to promote.
+func (r *KubeadmControlPlaneReconciler) reconcileEtcdLearners(ctx context.Context, controlPlane *internal.ControlPlane) error {
+	log := ctrl.LoggerFrom(ctx, "cluster", controlPlane.Cluster.Name)
+
+	// If etcd is not managed by KCP or the control plane is not yet initialized this is a no-op.
+	if !controlPlane.IsEtcdManaged() || !controlPlane.KCP.Status.Initialized {
+		return nil
+	}
+
+	// If the etcd members could not be inspected, wait for the next reconcile.
+	learners, ok := controlPlane.EtcdLearners()
+	if !ok {
+		return nil
+	}
+	if len(learners) == 0 {
+		// The condition exists only if members joined as learners.
+		if conditions.Has(controlPlane.KCP, controlplanev1.EtcdMembersPromotedCondition) {
+			conditions.MarkTrue(controlPlane.KCP, controlplanev1.EtcdMembersPromotedCondition)
+		}
+		return nil
+	}
+
+	workloadCluster, err := r.managementCluster.GetWorkloadCluster(ctx, util.ObjectKey(controlPlane.Cluster))
+	if err != nil {
+		conditions.MarkFalse(controlPlane.KCP, controlplanev1.EtcdMembersPromotedCondition, controlplanev1.EtcdLearnerPromotionFailedReason, clusterv1.ConditionSeverityWarning, "%v", err)
+		return errors.Wrap(err, "cannot get remote client to workload cluster")
+	}
+
+	learners, err = workloadCluster.PromoteEtcdLearners(ctx)
+	if err != nil {
+		conditions.MarkFalse(controlPlane.KCP, controlplanev1.EtcdMembersPromotedCondition, controlplanev1.EtcdLearnerPromotionFailedReason, clusterv1.ConditionSeverityWarning, "%v", err)
+		return errors.Wrap(err, "failed to promote etcd learners")
+	}
+	if len(learners) > 0 {
+		log.Info("Waiting for etcd learners to be in sync with the leader", "learners", learners)
+		conditions.MarkFalse(controlPlane.KCP, controlplanev1.EtcdMembersPromotedCondition, controlplanev1.WaitingForEtcdLearnersReason, clusterv1.ConditionSeverityInfo, "Waiting for etcd learners %s to be in sync with the leader", strings.Join(learners, ", "))
+		return nil
+	}
+
+	conditions.MarkTrue(controlPlane.KCP, controlplanev1.EtcdMembersPromotedCondition)
+	return nil
+}
+
 func (r *KubeadmControlPlaneReconciler) adoptMachines(ctx context.Context, kcp *controlplanev1.KubeadmControlPlane, machines collections.Machines, cluster *clusterv1.Cluster) error {
 	// We do an uncached full quorum read against the KCP to avoid re-adopting Machines the garbage collector just intentionally orphaned
 	// See https://github.com/kubernetes/kubernetes/issues/42639
@@ -28,6 +28,7 @@ import (
 
 	"github.com/blang/semver"
 	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -86,6 +87,90 @@ func TestClusterToKubeadmControlPlane(t *testing.T) {
 	g.Expect(got).To(Equal(expectedResult))
 }
 
+func TestSecretToKubeadmControlPlane(t *testing.T) {
+	g := NewWithT(t)
+
+	kcpWithFile := func(name, secretName string) *controlplanev1.KubeadmControlPlane {
+		return &controlplanev1.KubeadmControlPlane{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: name},
+			Spec: controlplanev1.KubeadmControlPlaneSpec{
+				KubeadmConfigSpec: bootstrapv1.KubeadmConfigSpec{
+					Files: []bootstrapv1.File{
+						{
+							Path:        "/etc/kubernetes/audit-policy.yaml",
+							ContentFrom: &bootstrapv1.FileSource{Secret: bootstrapv1.SecretFileSource{Name: secretName, Key: "policy"}},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	r := &KubeadmControlPlaneReconciler{
+		Client:   newFakeClient(kcpWithFile("kcp-foo", "audit"), kcpWithFile("kcp-bar", "other")),
+		recorder: record.NewFakeRecorder(32),
+	}
+
+	secret := &metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "audit"}}
+	got := r.secretToKubeadmControlPlane(secret)
+	g.Expect(got).To(Equal([]ctrl.Request{{NamespacedName: client.ObjectKey{Namespace: "test", Name: "kcp-foo"}}}))
+
+	// A ConfigMap with the same name of a referenced Secret does not trigger a reconcile.
+	configMap := &metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "audit"}}
+	g.Expect(r.configMapToKubeadmControlPlane(configMap)).To(BeEmpty())
+}
+
+func TestConfigMapToKubeadmControlPlane(t *testing.T) {
+	g := NewWithT(t)
+
+	kcpWithFile := func(name, configMapName string) *controlplanev1.KubeadmControlPlane {
+		return &controlplanev1.KubeadmControlPlane{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: name},
+			Spec: controlplanev1.KubeadmControlPlaneSpec{
+				KubeadmConfigSpec: bootstrapv1.KubeadmConfigSpec{
+					Files: []bootstrapv1.File{
+						{
+							Path:        "/etc/kubernetes/audit-policy.yaml",
+							ContentFrom: &bootstrapv1.FileSource{ConfigMap: &bootstrapv1.ConfigMapFileSource{Name: configMapName, Key: "policy"}},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	r := &KubeadmControlPlaneReconciler{
+		Client:   newFakeClient(kcpWithFile("kcp-foo", "audit"), kcpWithFile("kcp-bar", "other")),
+		recorder: record.NewFakeRecorder(32),
+	}
+
+	configMap := &metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "audit"}}
+	got := r.configMapToKubeadmControlPlane(configMap)
+	g.Expect(got).To(Equal([]ctrl.Request{{NamespacedName: client.ObjectKey{Namespace: "test", Name: "kcp-foo"}}}))
+
+	// A Secret with the same name of a referenced ConfigMap does not trigger a reconcile.
+	secret := &metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "audit"}}
+	g.Expect(r.secretToKubeadmControlPlane(secret)).To(BeEmpty())
+}
+
+func TestIndexKubeadmControlPlaneByFileSource(t *testing.T) {
+	g := NewWithT(t)
+
+	kcp := &controlplanev1.KubeadmControlPlane{
+		Spec: controlplanev1.KubeadmControlPlaneSpec{
+			KubeadmConfigSpec: bootstrapv1.KubeadmConfigSpec{
+				Files: []bootstrapv1.File{
+					{Path: "/etc/foo", Content: "foo"},
+					{Path: "/etc/bar", ContentFrom: &bootstrapv1.FileSource{Secret: bootstrapv1.SecretFileSource{Name: "bar", Key: "bar"}}},
+					{Path: "/etc/baz", ContentFrom: &bootstrapv1.FileSource{ConfigMap: &bootstrapv1.ConfigMapFileSource{Name: "baz", Key: "baz"}}},
+				},
+			},
+		},
+	}
+	g.Expect(indexKubeadmControlPlaneByFileSecret(kcp)).To(ConsistOf("bar"))
+	g.Expect(indexKubeadmControlPlaneByFileConfigMap(kcp)).To(ConsistOf("baz"))
+}
+
 func TestClusterToKubeadmControlPlaneNoControlPlane(t *testing.T) {
 	g := NewWithT(t)
 	fakeClient := newFakeClient()
@@ -186,15 +271,11 @@ func TestReconcileUpdateObservedGeneration(t *testing.T) {
 	kcp.Spec.Replicas = pointer.Int32Ptr(*kcp.Spec.Replicas + 2)
 	g.Expect(env.Update(ctx, kcp)).To(Succeed())
 
-	// read kcp.Generation after the update
-	errGettingObject = env.Get(ctx, util.ObjectKey(kcp), kcp)
-	g.Expect(errGettingObject).NotTo(HaveOccurred())
-	generation = kcp.Generation
-
-	// call reconcile the second time, so we can check if observedGeneration is set when calling defer patch
-	// NB. The call to reconcile fails because KCP is not properly setup (e.g. missing InfrastructureTemplate)
-	// but this is not important because what we want is KCP to be patched
-	_, _ = r.Reconcile(ctx, ctrl.Request{NamespacedName: util.ObjectKey(kcp)})
+	// call reconcile the second time, so we can check if observedGeneration is not set when calling defer patch
+	// NB. The call to reconcile fails because KCP is not properly setup (e.g. missing InfrastructureTemplate),
+	// so the new generation should not be reported as observed.
+	_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: util.ObjectKey(kcp)})
+	g.Expect(err).To(HaveOccurred())
 
 	g.Eventually(func() int64 {
 		errGettingObject = env.Get(ctx, util.ObjectKey(kcp), kcp)
@@ -1185,6 +1266,213 @@ kubernetesVersion: metav1.16.1`,
 
 		g.Expect(workloadCluster.UpdateCoreDNS(ctx, kcp, semver.MustParse("1.19.1"))).ToNot(Succeed())
 	})
+
+	t.Run("sets the CoreDNSUpToDate condition to false when unable to UpdateCoreDNS", func(t *testing.T) {
+		g := NewWithT(t)
+		objs := []client.Object{
+			cluster.DeepCopy(),
+			kcp.DeepCopy(),
+			depl.DeepCopy(),
+			corednsCM.DeepCopy(),
+		}
+
+		fakeClient := newFakeClient(objs...)
+		log.SetLogger(klogr.New())
+
+		workloadCluster := fakeWorkloadCluster{
+			Workload: &internal.Workload{
+				Client: fakeClient,
+				CoreDNSMigrator: &fakeMigrator{
+					migratedCorefile: "new core file",
+				},
+			},
+		}
+
+		r := &KubeadmControlPlaneReconciler{}
+		kcp := kcp.DeepCopy()
+
+		g.Expect(r.reconcileCoreDNS(ctx, kcp, workloadCluster)).ToNot(Succeed())
+		g.Expect(conditions.IsFalse(kcp, controlplanev1.CoreDNSUpToDateCondition)).To(BeTrue())
+		g.Expect(conditions.GetReason(kcp, controlplanev1.CoreDNSUpToDateCondition)).To(Equal(controlplanev1.CoreDNSUpgradeFailedReason))
+	})
+
+	t.Run("sets the CoreDNSUpToDate condition to true when there is nothing to update", func(t *testing.T) {
+		g := NewWithT(t)
+		objs := []client.Object{
+			cluster.DeepCopy(),
+			kcp.DeepCopy(),
+			kubeadmCM.DeepCopy(),
+		}
+
+		fakeClient := newFakeClient(objs...)
+		log.SetLogger(klogr.New())
+
+		workloadCluster := fakeWorkloadCluster{
+			Workload: &internal.Workload{
+				Client: fakeClient,
+				CoreDNSMigrator: &fakeMigrator{
+					migratedCorefile: "new core file",
+				},
+			},
+		}
+
+		r := &KubeadmControlPlaneReconciler{}
+		kcp := kcp.DeepCopy()
+
+		g.Expect(r.reconcileCoreDNS(ctx, kcp, workloadCluster)).To(Succeed())
+		g.Expect(conditions.IsTrue(kcp, controlplanev1.CoreDNSUpToDateCondition)).To(BeTrue())
+	})
+
+	t.Run("sets the CoreDNSUpToDate condition to false with a skipped reason when CoreDNS upgrades are skipped", func(t *testing.T) {
+		g := NewWithT(t)
+		objs := []client.Object{
+			cluster.DeepCopy(),
+			kcp.DeepCopy(),
+			depl.DeepCopy(),
+			corednsCM.DeepCopy(),
+		}
+
+		fakeClient := newFakeClient(objs...)
+		log.SetLogger(klogr.New())
+
+		workloadCluster := fakeWorkloadCluster{
+			Workload: &internal.Workload{
+				Client: fakeClient,
+				CoreDNSMigrator: &fakeMigrator{
+					migratedCorefile: "new core file",
+				},
+			},
+		}
+
+		r := &KubeadmControlPlaneReconciler{}
+		kcp := kcp.DeepCopy()
+		kcp.Annotations = map[string]string{controlplanev1.SkipCoreDNSAnnotation: ""}
+		conditions.MarkTrue(kcp, controlplanev1.CoreDNSUpToDateCondition)
+
+		g.Expect(r.reconcileCoreDNS(ctx, kcp, workloadCluster)).To(Succeed())
+		g.Expect(conditions.IsFalse(kcp, controlplanev1.CoreDNSUpToDateCondition)).To(BeTrue())
+		g.Expect(conditions.GetReason(kcp, controlplanev1.CoreDNSUpToDateCondition)).To(Equal(controlplanev1.CoreDNSUpgradeSkippedReason))
+		g.Expect(*conditions.GetSeverity(kcp, controlplanev1.CoreDNSUpToDateCondition)).To(Equal(clusterv1.ConditionSeverityInfo))
+
+		kcp.Annotations = nil
+		kcp.Spec.KubeadmConfigSpec.ClusterConfiguration = nil
+		g.Expect(r.reconcileCoreDNS(ctx, kcp, workloadCluster)).To(Succeed())
+		g.Expect(conditions.GetReason(kcp, controlplanev1.CoreDNSUpToDateCondition)).To(Equal(controlplanev1.CoreDNSUpgradeSkippedReason))
+	})
+}
+
+func TestKubeadmControlPlaneReconciler_reconcileEtcdLearnerMode(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster, kcp, _ := createClusterWithControlPlane()
+	kcp.Spec.Version = "v1.27.0"
+	controlPlane := &internal.ControlPlane{Cluster: cluster, KCP: kcp}
+	conditions.MarkTrue(controlPlane.KCP, controlplanev1.EtcdMembersPromotedCondition)
+	r := &KubeadmControlPlaneReconciler{
+		managementCluster: &fakeManagementCluster{Workload: fakeWorkloadCluster{}},
+	}
+
+	// The condition is removed if learner mode is not supported.
+	g.Expect(r.reconcileEtcdLearnerMode(ctx, controlPlane)).To(Succeed())
+	g.Expect(conditions.Has(controlPlane.KCP, controlplanev1.EtcdMembersPromotedCondition)).To(BeFalse())
+
+	// The condition is initialized if learner mode is enabled.
+	r.managementCluster = &fakeManagementCluster{Workload: fakeWorkloadCluster{EtcdLearnerMode: true}}
+	g.Expect(r.reconcileEtcdLearnerMode(ctx, controlPlane)).To(Succeed())
+	g.Expect(conditions.IsTrue(controlPlane.KCP, controlplanev1.EtcdMembersPromotedCondition)).To(BeTrue())
+
+	// The condition is preserved while learners are waiting to be promoted.
+	conditions.MarkFalse(controlPlane.KCP, controlplanev1.EtcdMembersPromotedCondition, controlplanev1.WaitingForEtcdLearnersReason, clusterv1.ConditionSeverityInfo, "")
+	g.Expect(r.reconcileEtcdLearnerMode(ctx, controlPlane)).To(Succeed())
+	g.Expect(conditions.IsFalse(controlPlane.KCP, controlplanev1.EtcdMembersPromotedCondition)).To(BeTrue())
+}
+
+func TestKubeadmControlPlaneReconciler_reconcileEtcdLearners(t *testing.T) {
+	newControlPlane := func(learners ...string) *internal.ControlPlane {
+		cluster, kcp, _ := createClusterWithControlPlane()
+		kcp.Spec.Version = "v1.27.0"
+		kcp.Status.Initialized = true
+		controlPlane := &internal.ControlPlane{Cluster: cluster, KCP: kcp}
+		controlPlane.SetEtcdLearners(learners)
+		return controlPlane
+	}
+
+	t.Run("does not contact etcd if there are no learners", func(t *testing.T) {
+		g := NewWithT(t)
+
+		controlPlane := newControlPlane()
+		r := &KubeadmControlPlaneReconciler{
+			managementCluster: &fakeManagementCluster{Workload: fakeWorkloadCluster{
+				EtcdLearnersErr: errors.New("etcd should not be contacted"),
+			}},
+		}
+
+		g.Expect(r.reconcileEtcdLearners(ctx, controlPlane)).To(Succeed())
+		g.Expect(conditions.Has(controlPlane.KCP, controlplanev1.EtcdMembersPromotedCondition)).To(BeFalse())
+	})
+
+	t.Run("does nothing if the etcd members could not be inspected", func(t *testing.T) {
+		g := NewWithT(t)
+
+		cluster, kcp, _ := createClusterWithControlPlane()
+		kcp.Status.Initialized = true
+		controlPlane := &internal.ControlPlane{Cluster: cluster, KCP: kcp}
+		conditions.MarkFalse(controlPlane.KCP, controlplanev1.EtcdMembersPromotedCondition, controlplanev1.WaitingForEtcdLearnersReason, clusterv1.ConditionSeverityInfo, "")
+		r := &KubeadmControlPlaneReconciler{
+			managementCluster: &fakeManagementCluster{Workload: fakeWorkloadCluster{}},
+		}
+
+		g.Expect(r.reconcileEtcdLearners(ctx, controlPlane)).To(Succeed())
+		g.Expect(conditions.IsFalse(controlPlane.KCP, controlplanev1.EtcdMembersPromotedCondition)).To(BeTrue())
+	})
+
+	t.Run("reports learners not yet in sync with the leader", func(t *testing.T) {
+		g := NewWithT(t)
+
+		controlPlane := newControlPlane("machine-3")
+		r := &KubeadmControlPlaneReconciler{
+			managementCluster: &fakeManagementCluster{Workload: fakeWorkloadCluster{
+				EtcdLearnersResult: []string{"machine-3"},
+			}},
+		}
+
+		g.Expect(r.reconcileEtcdLearners(ctx, controlPlane)).To(Succeed())
+		g.Expect(conditions.IsFalse(controlPlane.KCP, controlplanev1.EtcdMembersPromotedCondition)).To(BeTrue())
+		g.Expect(conditions.GetReason(controlPlane.KCP, controlplanev1.EtcdMembersPromotedCondition)).To(Equal(controlplanev1.WaitingForEtcdLearnersReason))
+		g.Expect(conditions.GetMessage(controlPlane.KCP, controlplanev1.EtcdMembersPromotedCondition)).To(ContainSubstring("machine-3"))
+	})
+
+	t.Run("reports failures promoting learners", func(t *testing.T) {
+		g := NewWithT(t)
+
+		controlPlane := newControlPlane("machine-3")
+		r := &KubeadmControlPlaneReconciler{
+			managementCluster: &fakeManagementCluster{Workload: fakeWorkloadCluster{
+				EtcdLearnersErr: errors.New("failed to promote"),
+			}},
+		}
+
+		g.Expect(r.reconcileEtcdLearners(ctx, controlPlane)).NotTo(Succeed())
+		g.Expect(conditions.IsFalse(controlPlane.KCP, controlplanev1.EtcdMembersPromotedCondition)).To(BeTrue())
+		g.Expect(conditions.GetReason(controlPlane.KCP, controlplanev1.EtcdMembersPromotedCondition)).To(Equal(controlplanev1.EtcdLearnerPromotionFailedReason))
+	})
+
+	t.Run("marks the condition true when all the learners are promoted", func(t *testing.T) {
+		g := NewWithT(t)
+
+		controlPlane := newControlPlane("machine-3")
+		r := &KubeadmControlPlaneReconciler{
+			managementCluster: &fakeManagementCluster{Workload: fakeWorkloadCluster{}},
+		}
+
+		g.Expect(r.reconcileEtcdLearners(ctx, controlPlane)).To(Succeed())
+		g.Expect(conditions.IsTrue(controlPlane.KCP, controlplanev1.EtcdMembersPromotedCondition)).To(BeTrue())
+
+		// Once promoted, the condition stays true.
+		controlPlane.SetEtcdLearners(nil)
+		g.Expect(r.reconcileEtcdLearners(ctx, controlPlane)).To(Succeed())
+		g.Expect(conditions.IsTrue(controlPlane.KCP, controlplanev1.EtcdMembersPromotedCondition)).To(BeTrue())
+	})
 }
 
 func TestKubeadmControlPlaneReconciler_reconcileDelete(t *testing.T) {
@@ -1227,6 +1515,43 @@ func TestKubeadmControlPlaneReconciler_reconcileDelete(t *testing.T) {
 		g.Expect(kcp.Finalizers).To(BeEmpty())
 	})
 
+	t.Run("removes control plane Machines from the load balancer before deleting them if the load balancer hooks are enabled", func(t *testing.T) {
+		g := NewWithT(t)
+
+		cluster, kcp, _ := createClusterWithControlPlane()
+		controllerutil.AddFinalizer(kcp, controlplanev1.KubeadmControlPlaneFinalizer)
+		kcp.Spec.LoadBalancerHooks = true
+		initObjs := []client.Object{cluster.DeepCopy(), kcp.DeepCopy()}
+
+		for i := 0; i < 2; i++ {
+			m, _ := createMachineNodePair(fmt.Sprintf("test-%d", i), cluster, kcp, true)
+			initObjs = append(initObjs, m)
+		}
+		detached := initObjs[2].(*clusterv1.Machine)
+		detached.Annotations = map[string]string{controlplanev1.LoadBalancerDetachAnnotation: controlplanev1.LoadBalancerHookCompleted}
+
+		fakeClient := newFakeClient(initObjs...)
+
+		r := &KubeadmControlPlaneReconciler{
+			Client: fakeClient,
+			managementCluster: &fakeManagementCluster{
+				Management: &internal.Management{Client: fakeClient},
+				Workload:   fakeWorkloadCluster{},
+			},
+			recorder: record.NewFakeRecorder(32),
+		}
+
+		result, err := r.reconcileDelete(ctx, cluster, kcp)
+		g.Expect(result).To(Equal(ctrl.Result{RequeueAfter: deleteRequeueAfter}))
+		g.Expect(err).To(BeNil())
+
+		controlPlaneMachines := clusterv1.MachineList{}
+		g.Expect(fakeClient.List(ctx, &controlPlaneMachines)).To(Succeed())
+		g.Expect(controlPlaneMachines.Items).To(HaveLen(1))
+		g.Expect(controlPlaneMachines.Items[0].Name).To(Equal("test-1"))
+		g.Expect(controlPlaneMachines.Items[0].Annotations).To(HaveKeyWithValue(controlplanev1.LoadBalancerDetachAnnotation, controlplanev1.LoadBalancerHookRequested))
+	})
+
 	t.Run("does not remove any control plane Machines if other Machines exist", func(t *testing.T) {
 		g := NewWithT(t)
 
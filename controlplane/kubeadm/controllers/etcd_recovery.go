@@ -0,0 +1,148 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/blang/semver"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// etcdQuorumRecoveryRequeueAfter is how long to wait before checking again the progress of an etcd quorum recovery.
+const etcdQuorumRecoveryRequeueAfter = 20 * time.Second
+
+// reconcileEtcdQuorumRecovery drives the recovery of an etcd cluster which lost quorum, when requested using the
+// EtcdQuorumRecoveryAnnotation. The recovery codifies the disaster recovery runbook for a stacked etcd cluster.
+// First, the etcd member on the surviving machine is restarted as a new single-member etcd cluster using the etcd
+// --force-new-cluster flag; this step requires access to the node, and it is surfaced to the user by the
+// EtcdQuorumRecovered condition. Once etcd reports the surviving member as the only member, all the other machines
+// are deleted; when only the surviving machine is left, the annotation is removed and the control plane is scaled
+// up as usual.
+// While the recovery is in progress, all the other KCP operations are blocked.
+//
+// NOTE: Machines are deleted only after etcd itself confirms the surviving member is the only member of the cluster,
+// so a recovery requested by mistake on a cluster with quorum is rejected without any destructive action.
+func (r *KubeadmControlPlaneReconciler) reconcileEtcdQuorumRecovery(ctx context.Context, controlPlane *internal.ControlPlane) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx, "cluster", controlPlane.Cluster.Name)
+	kcp := controlPlane.KCP
+
+	survivorName, ok := kcp.Annotations[controlplanev1.EtcdQuorumRecoveryAnnotation]
+	if !ok {
+		// Cleanup leftovers of a recovery rejected or aborted by removing the annotation.
+		if conditions.IsFalse(kcp, controlplanev1.EtcdQuorumRecoveredCondition) {
+			conditions.Delete(kcp, controlplanev1.EtcdQuorumRecoveredCondition)
+		}
+		return ctrl.Result{}, nil
+	}
+
+	// Run safety checks before starting the recovery.
+	if !controlPlane.IsEtcdManaged() {
+		return r.rejectEtcdQuorumRecovery(ctx, controlPlane, "etcd is not managed by the KubeadmControlPlane")
+	}
+	if !kcp.Status.Initialized {
+		return r.rejectEtcdQuorumRecovery(ctx, controlPlane, "the control plane is not initialized")
+	}
+	survivor, ok := controlPlane.Machines[survivorName]
+	if !ok {
+		return r.rejectEtcdQuorumRecovery(ctx, controlPlane, "machine %s is not a control plane machine owned by the KubeadmControlPlane", survivorName)
+	}
+	if !survivor.DeletionTimestamp.IsZero() {
+		return r.rejectEtcdQuorumRecovery(ctx, controlPlane, "machine %s is being deleted", survivorName)
+	}
+	if survivor.Status.NodeRef == nil {
+		return r.rejectEtcdQuorumRecovery(ctx, controlPlane, "machine %s does not have a node", survivorName)
+	}
+	survivorNodeName := survivor.Status.NodeRef.Name
+
+	// Gets the list of etcd members; while etcd quorum is lost, it is not possible to get it, and this is also
+	// the case for the workload cluster API server.
+	var members []string
+	workloadCluster, err := r.managementCluster.GetWorkloadCluster(ctx, util.ObjectKey(controlPlane.Cluster))
+	if err == nil {
+		members, err = workloadCluster.EtcdMembers(ctx)
+	}
+	if err != nil {
+		log.Info("Waiting for the surviving etcd member to be restarted as a new single-member etcd cluster", "machine", survivorName, "node", survivorNodeName, "cause", err.Error())
+		conditions.MarkFalse(kcp, controlplanev1.EtcdQuorumRecoveredCondition, controlplanev1.WaitingForForceNewClusterReason, clusterv1.ConditionSeverityWarning,
+			"Waiting for the etcd member on node %s to be restarted with the --force-new-cluster flag", survivorNodeName)
+		return ctrl.Result{RequeueAfter: etcdQuorumRecoveryRequeueAfter}, nil
+	}
+
+	// If etcd answers, either the quorum was never lost or the surviving member is already the only member of a new cluster.
+	if len(members) != 1 || members[0] != survivorNodeName {
+		return r.rejectEtcdQuorumRecovery(ctx, controlPlane, "etcd quorum is available, the etcd cluster is composed by members %s", strings.Join(members, ", "))
+	}
+
+	// Deletes all the machines which are not part of the new etcd cluster.
+	machinesToDelete := controlPlane.Machines.Filter(func(m *clusterv1.Machine) bool {
+		return m.Name != survivorName
+	})
+	if machinesToDelete.Len() > 0 {
+		conditions.MarkFalse(kcp, controlplanev1.EtcdQuorumRecoveredCondition, controlplanev1.EtcdQuorumRecoveryInProgressReason, clusterv1.ConditionSeverityInfo,
+			"Deleting %d machines not part of the recovered etcd cluster", machinesToDelete.Len())
+
+		parsedVersion, err := semver.ParseTolerant(kcp.Spec.Version)
+		if err != nil {
+			return ctrl.Result{}, errors.Wrapf(err, "failed to parse kubernetes version %q", kcp.Spec.Version)
+		}
+
+		for _, machine := range machinesToDelete {
+			if !machine.DeletionTimestamp.IsZero() {
+				continue
+			}
+			if err := workloadCluster.RemoveMachineFromKubeadmConfigMap(ctx, machine, parsedVersion); err != nil {
+				return ctrl.Result{}, errors.Wrapf(err, "failed to remove machine %s from kubeadm ConfigMap", machine.Name)
+			}
+			log.Info("Deleting control plane machine not part of the recovered etcd cluster", "machine", machine.Name)
+			if err := r.Client.Delete(ctx, machine); err != nil && !apierrors.IsNotFound(err) {
+				return ctrl.Result{}, errors.Wrapf(err, "failed to delete control plane machine %s", machine.Name)
+			}
+			r.recorder.Eventf(kcp, corev1.EventTypeNormal, "EtcdQuorumRecovery",
+				"Deleted control plane Machine %s, not part of the etcd cluster recovered from Machine %s", machine.Name, survivorName)
+		}
+		return ctrl.Result{RequeueAfter: deleteRequeueAfter}, nil
+	}
+
+	// The recovery is completed; remove the annotation so the control plane can be scaled up as usual.
+	log.Info("Etcd quorum recovery completed", "machine", survivorName)
+	delete(kcp.Annotations, controlplanev1.EtcdQuorumRecoveryAnnotation)
+	conditions.MarkTrue(kcp, controlplanev1.EtcdQuorumRecoveredCondition)
+	r.recorder.Eventf(kcp, corev1.EventTypeNormal, "EtcdQuorumRecovered", "Etcd quorum recovered from Machine %s", survivorName)
+	return ctrl.Result{}, nil
+}
+
+// rejectEtcdQuorumRecovery surfaces the reason why an etcd quorum recovery was rejected; the other KCP operations
+// are not blocked, given that no action was taken as part of the recovery.
+func (r *KubeadmControlPlaneReconciler) rejectEtcdQuorumRecovery(ctx context.Context, controlPlane *internal.ControlPlane, messageFormat string, messageArgs ...interface{}) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx, "cluster", controlPlane.Cluster.Name)
+
+	conditions.MarkFalse(controlPlane.KCP, controlplanev1.EtcdQuorumRecoveredCondition, controlplanev1.EtcdQuorumRecoveryRejectedReason, clusterv1.ConditionSeverityWarning,
+		"Etcd quorum recovery rejected: "+messageFormat, messageArgs...)
+	log.Info("Etcd quorum recovery rejected, remove the annotation to clear this condition", "reason", conditions.GetMessage(controlPlane.KCP, controlplanev1.EtcdQuorumRecoveredCondition))
+	return ctrl.Result{}, nil
+}
@@ -0,0 +1,164 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal"
+	"sigs.k8s.io/cluster-api/util/collections"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestReconcileEtcdQuorumRecovery(t *testing.T) {
+	machine := func(name string) *clusterv1.Machine {
+		return &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Status: clusterv1.MachineStatus{
+				NodeRef: &corev1.ObjectReference{Kind: "Node", Name: "node-" + name},
+			},
+		}
+	}
+
+	kcp := func(annotations map[string]string) *controlplanev1.KubeadmControlPlane {
+		return &controlplanev1.KubeadmControlPlane{
+			ObjectMeta: metav1.ObjectMeta{Name: "kcp", Namespace: "default", Annotations: annotations},
+			Spec:       controlplanev1.KubeadmControlPlaneSpec{Version: "v1.21.1"},
+			Status:     controlplanev1.KubeadmControlPlaneStatus{Initialized: true},
+		}
+	}
+	recoverFrom := func(name string) map[string]string {
+		return map[string]string{controlplanev1.EtcdQuorumRecoveryAnnotation: name}
+	}
+
+	tests := []struct {
+		name              string
+		kcp               *controlplanev1.KubeadmControlPlane
+		workload          fakeWorkloadCluster
+		expectRequeue     bool
+		expectReason      string
+		expectDeleted     []string
+		expectAnnotation  bool
+		expectNoCondition bool
+	}{
+		{
+			name:              "no-op if recovery is not requested",
+			kcp:               kcp(nil),
+			expectNoCondition: true,
+		},
+		{
+			name:             "rejects recovery from a machine not owned by the KubeadmControlPlane",
+			kcp:              kcp(recoverFrom("m4")),
+			expectReason:     controlplanev1.EtcdQuorumRecoveryRejectedReason,
+			expectAnnotation: true,
+		},
+		{
+			name:             "rejects recovery if etcd quorum is available",
+			kcp:              kcp(recoverFrom("m1")),
+			workload:         fakeWorkloadCluster{EtcdMembersResult: []string{"node-m1", "node-m2", "node-m3"}},
+			expectReason:     controlplanev1.EtcdQuorumRecoveryRejectedReason,
+			expectAnnotation: true,
+		},
+		{
+			name:             "waits for the surviving member to be restarted as a new cluster while etcd quorum is lost",
+			kcp:              kcp(recoverFrom("m1")),
+			workload:         fakeWorkloadCluster{EtcdMembersErr: errors.New("context deadline exceeded")},
+			expectRequeue:    true,
+			expectReason:     controlplanev1.WaitingForForceNewClusterReason,
+			expectAnnotation: true,
+		},
+		{
+			name:             "deletes the other machines when the surviving member is the only member",
+			kcp:              kcp(recoverFrom("m1")),
+			workload:         fakeWorkloadCluster{EtcdMembersResult: []string{"node-m1"}},
+			expectRequeue:    true,
+			expectReason:     controlplanev1.EtcdQuorumRecoveryInProgressReason,
+			expectDeleted:    []string{"m2", "m3"},
+			expectAnnotation: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			m1, m2, m3 := machine("m1"), machine("m2"), machine("m3")
+			fakeClient := newFakeClient(m1.DeepCopy(), m2.DeepCopy(), m3.DeepCopy())
+			r := &KubeadmControlPlaneReconciler{
+				Client:            fakeClient,
+				managementCluster: &fakeManagementCluster{Workload: tt.workload},
+				recorder:          record.NewFakeRecorder(32),
+			}
+			controlPlane := &internal.ControlPlane{
+				KCP:      tt.kcp,
+				Cluster:  &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "default"}},
+				Machines: collections.FromMachines(m1, m2, m3),
+			}
+
+			result, err := r.reconcileEtcdQuorumRecovery(ctx, controlPlane)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(result.IsZero()).To(Equal(!tt.expectRequeue))
+			_, hasAnnotation := tt.kcp.Annotations[controlplanev1.EtcdQuorumRecoveryAnnotation]
+			g.Expect(hasAnnotation).To(Equal(tt.expectAnnotation))
+
+			if tt.expectNoCondition {
+				g.Expect(conditions.Has(tt.kcp, controlplanev1.EtcdQuorumRecoveredCondition)).To(BeFalse())
+			} else {
+				g.Expect(conditions.GetReason(tt.kcp, controlplanev1.EtcdQuorumRecoveredCondition)).To(Equal(tt.expectReason))
+			}
+
+			for _, m := range []*clusterv1.Machine{m1, m2, m3} {
+				err := fakeClient.Get(ctx, client.ObjectKeyFromObject(m), &clusterv1.Machine{})
+				deleted := false
+				for _, name := range tt.expectDeleted {
+					deleted = deleted || name == m.Name
+				}
+				g.Expect(apierrors.IsNotFound(err)).To(Equal(deleted), "machine %s", m.Name)
+			}
+		})
+	}
+
+	t.Run("completes the recovery when only the surviving machine is left", func(t *testing.T) {
+		g := NewWithT(t)
+
+		m1 := machine("m1")
+		r := &KubeadmControlPlaneReconciler{
+			Client:            newFakeClient(m1.DeepCopy()),
+			managementCluster: &fakeManagementCluster{Workload: fakeWorkloadCluster{EtcdMembersResult: []string{"node-m1"}}},
+			recorder:          record.NewFakeRecorder(32),
+		}
+		controlPlane := &internal.ControlPlane{
+			KCP:      kcp(recoverFrom("m1")),
+			Cluster:  &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "default"}},
+			Machines: collections.FromMachines(m1),
+		}
+
+		result, err := r.reconcileEtcdQuorumRecovery(ctx, controlPlane)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(result.IsZero()).To(BeTrue())
+		g.Expect(controlPlane.KCP.Annotations).ToNot(HaveKey(controlplanev1.EtcdQuorumRecoveryAnnotation))
+		g.Expect(conditions.IsTrue(controlPlane.KCP, controlplanev1.EtcdQuorumRecoveredCondition)).To(BeTrue())
+	})
+}
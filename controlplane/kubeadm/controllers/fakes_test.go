@@ -66,6 +66,13 @@ type fakeWorkloadCluster struct {
 	*internal.Workload
 	Status            internal.ClusterStatus
 	EtcdMembersResult []string
+	EtcdMembersErr    error
+
+	EtcdLearnerMode    bool
+	EtcdLearnersResult []string
+	EtcdLearnersErr    error
+
+	ExternalCloudControllerManagerRunning bool
 }
 
 func (f fakeWorkloadCluster) ForwardEtcdLeadership(_ context.Context, _ *clusterv1.Machine, _ *clusterv1.Machine) error {
@@ -76,6 +83,18 @@ func (f fakeWorkloadCluster) ReconcileEtcdMembers(ctx context.Context, nodeNames
 	return nil, nil
 }
 
+func (f fakeWorkloadCluster) ReconcileEtcdLearnerMode(_ context.Context, _ semver.Version) (bool, error) {
+	return f.EtcdLearnerMode, nil
+}
+
+func (f fakeWorkloadCluster) PromoteEtcdLearners(_ context.Context) ([]string, error) {
+	return f.EtcdLearnersResult, f.EtcdLearnersErr
+}
+
+func (f fakeWorkloadCluster) IsExternalCloudControllerManagerRunning(_ context.Context) (bool, error) {
+	return f.ExternalCloudControllerManagerRunning, nil
+}
+
 func (f fakeWorkloadCluster) ClusterStatus(_ context.Context) (internal.ClusterStatus, error) {
 	return f.Status, nil
 }
@@ -84,6 +103,14 @@ func (f fakeWorkloadCluster) AllowBootstrapTokensToGetNodes(ctx context.Context)
 	return nil
 }
 
+func (f fakeWorkloadCluster) AllowKubeletServingCertificateApproval(ctx context.Context) error {
+	return nil
+}
+
+func (f fakeWorkloadCluster) EnsureKubeletServingCertApprover(ctx context.Context, image string) error {
+	return nil
+}
+
 func (f fakeWorkloadCluster) ReconcileKubeletRBACRole(ctx context.Context, version semver.Version) error {
 	return nil
 }
@@ -113,7 +140,7 @@ func (f fakeWorkloadCluster) RemoveMachineFromKubeadmConfigMap(ctx context.Conte
 }
 
 func (f fakeWorkloadCluster) EtcdMembers(_ context.Context) ([]string, error) {
-	return f.EtcdMembersResult, nil
+	return f.EtcdMembersResult, f.EtcdMembersErr
 }
 
 type fakeMigrator struct {
@@ -19,6 +19,7 @@ package controllers
 import (
 	"context"
 	"encoding/json"
+	"reflect"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -234,12 +235,23 @@ func (r *KubeadmControlPlaneReconciler) generateKubeadmConfig(ctx context.Contex
 		UID:        kcp.UID,
 	}
 
+	// Propagate the annotations skipping the kube-proxy and CoreDNS addons, so they are not installed by kubeadm init.
+	annotations := map[string]string{}
+	for k, v := range kcp.Spec.MachineTemplate.ObjectMeta.Annotations {
+		annotations[k] = v
+	}
+	for _, k := range []string{controlplanev1.SkipKubeProxyAnnotation, controlplanev1.SkipCoreDNSAnnotation} {
+		if v, ok := kcp.Annotations[k]; ok {
+			annotations[k] = v
+		}
+	}
+
 	bootstrapConfig := &bootstrapv1.KubeadmConfig{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:            names.SimpleNameGenerator.GenerateName(kcp.Name + "-"),
 			Namespace:       kcp.Namespace,
 			Labels:          internal.ControlPlaneMachineLabelsForCluster(kcp, cluster.Name),
-			Annotations:     kcp.Spec.MachineTemplate.ObjectMeta.Annotations,
+			Annotations:     annotations,
 			OwnerReferences: []metav1.OwnerReference{owner},
 		},
 		Spec: *spec,
@@ -278,21 +290,68 @@ func (r *KubeadmControlPlaneReconciler) generateMachine(ctx context.Context, kcp
 			Bootstrap: clusterv1.Bootstrap{
 				ConfigRef: bootstrapRef,
 			},
-			FailureDomain:    failureDomain,
-			NodeDrainTimeout: kcp.Spec.MachineTemplate.NodeDrainTimeout,
+			FailureDomain:           failureDomain,
+			NodeDrainTimeout:        kcp.Spec.MachineTemplate.NodeDrainTimeout,
+			NodeVolumeDetachTimeout: kcp.Spec.MachineTemplate.NodeVolumeDetachTimeout,
+			NodeDeletionTimeout:     kcp.Spec.MachineTemplate.NodeDeletionTimeout,
 		},
 	}
 
 	// Machine's bootstrap config may be missing ClusterConfiguration if it is not the first machine in the control plane.
 	// We store ClusterConfiguration as annotation here to detect any changes in KCP ClusterConfiguration and rollout the machine if any.
-	clusterConfig, err := json.Marshal(kcp.Spec.KubeadmConfigSpec.ClusterConfiguration)
+	clusterConfig, err := json.Marshal(internal.DesiredKubeadmConfigSpec(kcp).ClusterConfiguration)
 	if err != nil {
 		return errors.Wrap(err, "failed to marshal cluster configuration")
 	}
-	machine.SetAnnotations(map[string]string{controlplanev1.KubeadmClusterConfigurationAnnotation: string(clusterConfig)})
+	annotations := map[string]string{controlplanev1.KubeadmClusterConfigurationAnnotation: string(clusterConfig)}
+
+	// We store the hash of the content of the files referenced by KCP, so changes to the referenced Secrets or ConfigMaps,
+	// e.g. to the audit policy or to the encryption configuration, trigger the rollout of the machine.
+	filesContentHash, err := internal.FilesContentHash(ctx, r.Client, kcp)
+	if err != nil {
+		return errors.Wrap(err, "failed to compute the hash of the files content")
+	}
+	if filesContentHash != "" {
+		annotations[controlplanev1.KubeadmFilesContentHashAnnotation] = filesContentHash
+	}
+
+	// KCP waits for the infrastructure provider to add the machine to the API server load balancer before continuing.
+	if kcp.Spec.LoadBalancerHooks {
+		annotations[controlplanev1.LoadBalancerAttachAnnotation] = controlplanev1.LoadBalancerHookRequested
+	}
+	machine.SetAnnotations(annotations)
 
 	if err := r.Client.Create(ctx, machine); err != nil {
 		return errors.Wrap(err, "failed to create machine")
 	}
 	return nil
 }
+
+// syncMachineDeletionTimeouts propagates in place the node drain, node volume detach and node deletion timeouts
+// from the KCP machine template to the control plane Machines. Changing the timeouts does not require a rollout,
+// so e.g. setting a node drain timeout on KCP unblocks a stuck drain of an outdated Machine during an upgrade.
+func (r *KubeadmControlPlaneReconciler) syncMachineDeletionTimeouts(ctx context.Context, controlPlane *internal.ControlPlane) error {
+	template := controlPlane.KCP.Spec.MachineTemplate
+
+	var errs []error
+	for _, m := range controlPlane.Machines {
+		if reflect.DeepEqual(m.Spec.NodeDrainTimeout, template.NodeDrainTimeout) &&
+			reflect.DeepEqual(m.Spec.NodeVolumeDetachTimeout, template.NodeVolumeDetachTimeout) &&
+			reflect.DeepEqual(m.Spec.NodeDeletionTimeout, template.NodeDeletionTimeout) {
+			continue
+		}
+
+		patchHelper, err := patch.NewHelper(m, r.Client)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		m.Spec.NodeDrainTimeout = template.NodeDrainTimeout
+		m.Spec.NodeVolumeDetachTimeout = template.NodeVolumeDetachTimeout
+		m.Spec.NodeDeletionTimeout = template.NodeDeletionTimeout
+		if err := patchHelper.Patch(ctx, m); err != nil {
+			errs = append(errs, errors.Wrapf(err, "failed to patch machine %s", m.Name))
+		}
+	}
+	return kerrors.NewAggregate(errs)
+}
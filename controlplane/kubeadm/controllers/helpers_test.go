@@ -18,6 +18,7 @@ package controllers
 
 import (
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 
@@ -31,6 +32,7 @@ import (
 	"sigs.k8s.io/cluster-api/controllers/external"
 	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1alpha4"
 	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal"
+	"sigs.k8s.io/cluster-api/util/collections"
 	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/kubeconfig"
 	"sigs.k8s.io/cluster-api/util/secret"
@@ -494,6 +496,11 @@ func TestKubeadmControlPlaneReconciler_generateMachine(t *testing.T) {
 		},
 		Spec: controlplanev1.KubeadmControlPlaneSpec{
 			Version: "v1.16.6",
+			MachineTemplate: controlplanev1.KubeadmControlPlaneMachineTemplate{
+				NodeDrainTimeout:        &metav1.Duration{Duration: time.Minute},
+				NodeVolumeDetachTimeout: &metav1.Duration{Duration: 2 * time.Minute},
+				NodeDeletionTimeout:     &metav1.Duration{Duration: 3 * time.Minute},
+			},
 		},
 	}
 
@@ -515,7 +522,10 @@ func TestKubeadmControlPlaneReconciler_generateMachine(t *testing.T) {
 		Bootstrap: clusterv1.Bootstrap{
 			ConfigRef: bootstrapRef.DeepCopy(),
 		},
-		InfrastructureRef: *infraRef.DeepCopy(),
+		InfrastructureRef:       *infraRef.DeepCopy(),
+		NodeDrainTimeout:        kcp.Spec.MachineTemplate.NodeDrainTimeout,
+		NodeVolumeDetachTimeout: kcp.Spec.MachineTemplate.NodeVolumeDetachTimeout,
+		NodeDeletionTimeout:     kcp.Spec.MachineTemplate.NodeDeletionTimeout,
 	}
 	r := &KubeadmControlPlaneReconciler{
 		Client:            fakeClient,
@@ -535,6 +545,128 @@ func TestKubeadmControlPlaneReconciler_generateMachine(t *testing.T) {
 	g.Expect(machine.Spec).To(Equal(expectedMachineSpec))
 }
 
+func TestKubeadmControlPlaneReconciler_syncMachineDeletionTimeouts(t *testing.T) {
+	g := NewWithT(t)
+
+	kcp := &controlplanev1.KubeadmControlPlane{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "testControlPlane",
+			Namespace: "test",
+		},
+		Spec: controlplanev1.KubeadmControlPlaneSpec{
+			MachineTemplate: controlplanev1.KubeadmControlPlaneMachineTemplate{
+				NodeDrainTimeout:    &metav1.Duration{Duration: time.Minute},
+				NodeDeletionTimeout: &metav1.Duration{Duration: time.Minute},
+			},
+		},
+	}
+	outdated := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "outdated",
+			Namespace: kcp.Namespace,
+		},
+		Spec: clusterv1.MachineSpec{
+			NodeVolumeDetachTimeout: &metav1.Duration{Duration: time.Minute},
+		},
+	}
+	upToDate := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "up-to-date",
+			Namespace: kcp.Namespace,
+		},
+		Spec: clusterv1.MachineSpec{
+			NodeDrainTimeout:    &metav1.Duration{Duration: time.Minute},
+			NodeDeletionTimeout: &metav1.Duration{Duration: time.Minute},
+		},
+	}
+	fakeClient := newFakeClient(outdated.DeepCopy(), upToDate.DeepCopy())
+
+	r := &KubeadmControlPlaneReconciler{
+		Client:   fakeClient,
+		recorder: record.NewFakeRecorder(32),
+	}
+	controlPlane := &internal.ControlPlane{
+		KCP:      kcp,
+		Machines: collections.FromMachines(outdated, upToDate),
+	}
+	g.Expect(r.syncMachineDeletionTimeouts(ctx, controlPlane)).To(Succeed())
+
+	for _, name := range []string{outdated.Name, upToDate.Name} {
+		machine := &clusterv1.Machine{}
+		g.Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: kcp.Namespace, Name: name}, machine)).To(Succeed())
+		g.Expect(machine.Spec.NodeDrainTimeout).To(Equal(kcp.Spec.MachineTemplate.NodeDrainTimeout))
+		g.Expect(machine.Spec.NodeVolumeDetachTimeout).To(BeNil())
+		g.Expect(machine.Spec.NodeDeletionTimeout).To(Equal(kcp.Spec.MachineTemplate.NodeDeletionTimeout))
+	}
+}
+
+func TestKubeadmControlPlaneReconciler_generateMachineWithFilesContentHash(t *testing.T) {
+	g := NewWithT(t)
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "audit-policy",
+			Namespace: "test",
+		},
+		Data: map[string][]byte{"policy": []byte("apiVersion: audit.k8s.io/v1")},
+	}
+	fakeClient := newFakeClient(secret.DeepCopy())
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "testCluster",
+			Namespace: "test",
+		},
+	}
+
+	kcp := &controlplanev1.KubeadmControlPlane{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "testControlPlane",
+			Namespace: cluster.Namespace,
+		},
+		Spec: controlplanev1.KubeadmControlPlaneSpec{
+			Version: "v1.16.6",
+			KubeadmConfigSpec: bootstrapv1.KubeadmConfigSpec{
+				Files: []bootstrapv1.File{
+					{
+						Path: "/etc/kubernetes/audit-policy.yaml",
+						ContentFrom: &bootstrapv1.FileSource{
+							Secret: bootstrapv1.SecretFileSource{Name: secret.Name, Key: "policy"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	infraRef := &corev1.ObjectReference{
+		Kind:       "InfraKind",
+		APIVersion: "infrastructure.cluster.x-k8s.io/v1alpha4",
+		Name:       "infra",
+		Namespace:  cluster.Namespace,
+	}
+	bootstrapRef := &corev1.ObjectReference{
+		Kind:       "BootstrapKind",
+		APIVersion: "bootstrap.cluster.x-k8s.io/v1alpha4",
+		Name:       "bootstrap",
+		Namespace:  cluster.Namespace,
+	}
+	r := &KubeadmControlPlaneReconciler{
+		Client:            fakeClient,
+		managementCluster: &internal.Management{Client: fakeClient},
+		recorder:          record.NewFakeRecorder(32),
+	}
+	g.Expect(r.generateMachine(ctx, kcp, cluster, infraRef, bootstrapRef, nil)).To(Succeed())
+
+	expectedHash, err := internal.FilesContentHash(ctx, fakeClient, kcp)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	machineList := &clusterv1.MachineList{}
+	g.Expect(fakeClient.List(ctx, machineList, client.InNamespace(cluster.Namespace))).To(Succeed())
+	g.Expect(machineList.Items).To(HaveLen(1))
+	g.Expect(machineList.Items[0].Annotations).To(HaveKeyWithValue(controlplanev1.KubeadmFilesContentHashAnnotation, expectedHash))
+}
+
 func TestKubeadmControlPlaneReconciler_generateKubeadmConfig(t *testing.T) {
 	g := NewWithT(t)
 	fakeClient := newFakeClient()
@@ -550,6 +682,16 @@ func TestKubeadmControlPlaneReconciler_generateKubeadmConfig(t *testing.T) {
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "testControlPlane",
 			Namespace: cluster.Namespace,
+			Annotations: map[string]string{
+				controlplanev1.SkipKubeProxyAnnotation: "",
+			},
+		},
+		Spec: controlplanev1.KubeadmControlPlaneSpec{
+			MachineTemplate: controlplanev1.KubeadmControlPlaneMachineTemplate{
+				ObjectMeta: clusterv1.ObjectMeta{
+					Annotations: map[string]string{"foo": "bar"},
+				},
+			},
 		},
 	}
 
@@ -581,4 +723,9 @@ func TestKubeadmControlPlaneReconciler_generateKubeadmConfig(t *testing.T) {
 	g.Expect(bootstrapConfig.OwnerReferences).To(HaveLen(1))
 	g.Expect(bootstrapConfig.OwnerReferences).To(ContainElement(expectedOwner))
 	g.Expect(bootstrapConfig.Spec).To(Equal(spec))
+	g.Expect(bootstrapConfig.Annotations).To(Equal(map[string]string{
+		"foo":                                  "bar",
+		controlplanev1.SkipKubeProxyAnnotation: "",
+	}))
+	g.Expect(kcp.Spec.MachineTemplate.ObjectMeta.Annotations).ToNot(HaveKey(controlplanev1.SkipKubeProxyAnnotation))
 }
@@ -37,6 +37,16 @@ import (
 func (r *KubeadmControlPlaneReconciler) reconcileUnhealthyMachines(ctx context.Context, controlPlane *internal.ControlPlane) (ret ctrl.Result, retErr error) {
 	log := ctrl.LoggerFrom(ctx)
 
+	// If a remediation triggered by KCP is in progress, check if it is completed, i.e. if the remediated machine has been
+	// replaced by a new healthy machine; until then, KCP does not remediate other machines, so control plane machines
+	// are remediated one at a time.
+	remediatedMachine, remediationInProgress := controlPlane.KCP.Annotations[controlplanev1.RemediationInProgressAnnotation]
+	if remediationInProgress && isRemediationCompleted(controlPlane, remediatedMachine) {
+		log.Info("Remediation completed", "RemediatedMachine", remediatedMachine)
+		delete(controlPlane.KCP.Annotations, controlplanev1.RemediationInProgressAnnotation)
+		remediationInProgress = false
+	}
+
 	// Gets all machines that have `MachineHealthCheckSucceeded=False` (indicating a problem was detected on the machine)
 	// and `MachineOwnerRemediated` present, indicating that this controller is responsible for performing remediation.
 	unhealthyMachines := controlPlane.UnhealthyMachines()
@@ -80,6 +90,14 @@ func (r *KubeadmControlPlaneReconciler) reconcileUnhealthyMachines(ctx context.C
 
 	desiredReplicas := int(*controlPlane.KCP.Spec.Replicas)
 
+	// Only one machine MUST be remediated at a time. This rule ensures that the replacement of the previously remediated
+	// machine has joined the cluster and it is healthy before removing another etcd member.
+	if remediationInProgress {
+		log.Info("A control plane machine needs remediation, but the remediation of another machine is still in progress. Skipping remediation", "UnhealthyMachine", machineToBeRemediated.Name, "RemediatedMachine", remediatedMachine)
+		conditions.MarkFalse(machineToBeRemediated, clusterv1.MachineOwnerRemediatedCondition, clusterv1.WaitingForRemediationReason, clusterv1.ConditionSeverityWarning, "KCP waiting for the remediation of machine %s to complete before triggering another remediation", remediatedMachine)
+		return ctrl.Result{}, nil
+	}
+
 	// The cluster MUST have more than one replica, because this is the smallest cluster size that allows any etcd failure tolerance.
 	if controlPlane.Machines.Len() <= 1 {
 		log.Info("A control plane machine needs remediation, but the number of current replicas is less or equal to 1. Skipping remediation", "UnhealthyMachine", machineToBeRemediated.Name, "Replicas", controlPlane.Machines.Len())
@@ -117,6 +135,17 @@ func (r *KubeadmControlPlaneReconciler) reconcileUnhealthyMachines(ctx context.C
 		}
 	}
 
+	// If the load balancer hooks are enabled, remove the machine from the API server load balancer before
+	// removing its etcd member and deleting it.
+	if controlPlane.KCP.Spec.LoadBalancerHooks {
+		if result, err := r.detachFromLoadBalancer(ctx, controlPlane, machineToBeRemediated); err != nil || !result.IsZero() {
+			if err == nil {
+				conditions.MarkFalse(machineToBeRemediated, clusterv1.MachineOwnerRemediatedCondition, clusterv1.WaitingForRemediationReason, clusterv1.ConditionSeverityWarning, "KCP waiting for the machine to be removed from the API server load balancer")
+			}
+			return result, err
+		}
+	}
+
 	workloadCluster, err := r.managementCluster.GetWorkloadCluster(ctx, util.ObjectKey(controlPlane.Cluster))
 	if err != nil {
 		log.Error(err, "Failed to create client to workload cluster")
@@ -151,11 +180,37 @@ func (r *KubeadmControlPlaneReconciler) reconcileUnhealthyMachines(ctx context.C
 		return ctrl.Result{}, errors.Wrapf(err, "failed to delete unhealthy machine %s", machineToBeRemediated.Name)
 	}
 
+	// Track the remediation on the KCP object, so no other machine is remediated until the replacement machine is healthy.
+	if controlPlane.KCP.Annotations == nil {
+		controlPlane.KCP.Annotations = map[string]string{}
+	}
+	controlPlane.KCP.Annotations[controlplanev1.RemediationInProgressAnnotation] = machineToBeRemediated.Name
+
 	log.Info("Remediating unhealthy machine", "UnhealthyMachine", machineToBeRemediated.Name)
 	conditions.MarkFalse(machineToBeRemediated, clusterv1.MachineOwnerRemediatedCondition, clusterv1.RemediationInProgressReason, clusterv1.ConditionSeverityWarning, "")
 	return ctrl.Result{Requeue: true}, nil
 }
 
+// isRemediationCompleted returns true if the remediated machine has been deleted and the control plane has been scaled
+// back to the desired number of replicas, with the newest machine, the replacement of the remediated one, reporting
+// healthy control plane components.
+func isRemediationCompleted(controlPlane *internal.ControlPlane, remediatedMachine string) bool {
+	if controlPlane.HasDeletingMachine() || controlPlane.Machines.Len() < int(*controlPlane.KCP.Spec.Replicas) {
+		return false
+	}
+	if _, ok := controlPlane.Machines[remediatedMachine]; ok {
+		return false
+	}
+
+	replacement := controlPlane.Machines.Newest()
+	for _, condition := range machineHealthConditions(controlPlane) {
+		if err := preflightCheckCondition("machine", replacement, condition); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
 // canSafelyRemoveEtcdMember assess if it is possible to remove the member hosted on the machine to be remediated
 // without loosing etcd quorum.
 //
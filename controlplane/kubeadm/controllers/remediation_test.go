@@ -146,6 +146,59 @@ func TestReconcileUnhealthyMachines(t *testing.T) {
 
 		g.Expect(env.Cleanup(ctx, m1, m2)).To(Succeed())
 	})
+	t.Run("Remediation does not happen if the remediation of another machine is in progress", func(t *testing.T) {
+		g := NewWithT(t)
+
+		m1 := createMachine(ctx, g, ns.Name, "m1-unhealthy-", withMachineHealthCheckFailed())
+		m2 := createMachine(ctx, g, ns.Name, "m2-healthy-", withHealthyEtcdMember())
+		m3 := createMachine(ctx, g, ns.Name, "m3-replacement-", withHealthyEtcdMember()) // NB. Control plane components are not healthy yet
+		controlPlane := &internal.ControlPlane{
+			KCP: &controlplanev1.KubeadmControlPlane{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{controlplanev1.RemediationInProgressAnnotation: "m0-remediated"},
+				},
+				Spec: controlplanev1.KubeadmControlPlaneSpec{
+					Replicas: utilpointer.Int32Ptr(3),
+				},
+			},
+			Cluster:  &clusterv1.Cluster{},
+			Machines: collections.FromMachines(m1, m2, m3),
+		}
+		ret, err := r.reconcileUnhealthyMachines(context.TODO(), controlPlane)
+
+		g.Expect(ret.IsZero()).To(BeTrue()) // Remediation skipped
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(controlPlane.KCP.Annotations).To(HaveKeyWithValue(controlplanev1.RemediationInProgressAnnotation, "m0-remediated"))
+		assertMachineCondition(ctx, g, m1, clusterv1.MachineOwnerRemediatedCondition, corev1.ConditionFalse, clusterv1.WaitingForRemediationReason, clusterv1.ConditionSeverityWarning, "KCP waiting for the remediation of machine m0-remediated to complete before triggering another remediation")
+
+		g.Expect(env.Cleanup(ctx, m1, m2, m3)).To(Succeed())
+	})
+	t.Run("Remediation in progress completes when the replacement machine is healthy", func(t *testing.T) {
+		g := NewWithT(t)
+
+		m1 := createMachine(ctx, g, ns.Name, "m1-healthy-", withHealthyControlPlaneComponents())
+		m2 := createMachine(ctx, g, ns.Name, "m2-healthy-", withHealthyControlPlaneComponents())
+		m3 := createMachine(ctx, g, ns.Name, "m3-replacement-", withHealthyControlPlaneComponents())
+		controlPlane := &internal.ControlPlane{
+			KCP: &controlplanev1.KubeadmControlPlane{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{controlplanev1.RemediationInProgressAnnotation: "m0-remediated"},
+				},
+				Spec: controlplanev1.KubeadmControlPlaneSpec{
+					Replicas: utilpointer.Int32Ptr(3),
+				},
+			},
+			Cluster:  &clusterv1.Cluster{},
+			Machines: collections.FromMachines(m1, m2, m3),
+		}
+		ret, err := r.reconcileUnhealthyMachines(context.TODO(), controlPlane)
+
+		g.Expect(ret.IsZero()).To(BeTrue())
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(controlPlane.KCP.Annotations).ToNot(HaveKey(controlplanev1.RemediationInProgressAnnotation))
+
+		g.Expect(env.Cleanup(ctx, m1, m2, m3)).To(Succeed())
+	})
 	t.Run("Remediation does not happen if there is at least one additional unhealthy etcd member on a 3 machine CP", func(t *testing.T) {
 		g := NewWithT(t)
 
@@ -299,6 +352,7 @@ func TestReconcileUnhealthyMachines(t *testing.T) {
 		g.Expect(err).ToNot(HaveOccurred())
 
 		assertMachineCondition(ctx, g, m1, clusterv1.MachineOwnerRemediatedCondition, corev1.ConditionFalse, clusterv1.RemediationInProgressReason, clusterv1.ConditionSeverityWarning, "")
+		g.Expect(controlPlane.KCP.Annotations).To(HaveKeyWithValue(controlplanev1.RemediationInProgressAnnotation, m1.Name))
 
 		err = env.Get(ctx, client.ObjectKey{Namespace: m1.Namespace, Name: m1.Name}, m1)
 		g.Expect(err).ToNot(HaveOccurred())
@@ -762,6 +816,16 @@ func withHealthyEtcdMember() machineOption {
 	}
 }
 
+func withHealthyControlPlaneComponents() machineOption {
+	return func(machine *clusterv1.Machine) {
+		conditions.MarkTrue(machine, controlplanev1.MachineAPIServerPodHealthyCondition)
+		conditions.MarkTrue(machine, controlplanev1.MachineControllerManagerPodHealthyCondition)
+		conditions.MarkTrue(machine, controlplanev1.MachineSchedulerPodHealthyCondition)
+		conditions.MarkTrue(machine, controlplanev1.MachineEtcdPodHealthyCondition)
+		conditions.MarkTrue(machine, controlplanev1.MachineEtcdMemberHealthyCondition)
+	}
+}
+
 func withUnhealthyEtcdMember() machineOption {
 	return func(machine *clusterv1.Machine) {
 		conditions.MarkFalse(machine, controlplanev1.MachineEtcdMemberHealthyCondition, controlplanev1.EtcdMemberUnhealthyReason, clusterv1.ConditionSeverityError, "")
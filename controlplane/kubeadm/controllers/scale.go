@@ -31,7 +31,9 @@ import (
 	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1alpha4"
 	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal"
 	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/annotations"
 	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/patch"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
 
@@ -64,14 +66,22 @@ func (r *KubeadmControlPlaneReconciler) initializeControlPlane(ctx context.Conte
 	return ctrl.Result{Requeue: true}, nil
 }
 
-func (r *KubeadmControlPlaneReconciler) scaleUpControlPlane(ctx context.Context, cluster *clusterv1.Cluster, kcp *controlplanev1.KubeadmControlPlane, controlPlane *internal.ControlPlane) (ctrl.Result, error) {
+// scaleUpControlPlane creates a new control plane machine; machines in excludeFor, e.g. machines still being
+// provisioned as part of the same rollout batch, are excluded from the preflight checks.
+func (r *KubeadmControlPlaneReconciler) scaleUpControlPlane(ctx context.Context, cluster *clusterv1.Cluster, kcp *controlplanev1.KubeadmControlPlane, controlPlane *internal.ControlPlane, excludeFor ...*clusterv1.Machine) (ctrl.Result, error) {
 	logger := controlPlane.Logger()
 
 	// Run preflight checks to ensure that the control plane is stable before proceeding with a scale up/scale down operation; if not, wait.
-	if result, err := r.preflightChecks(ctx, controlPlane); err != nil || !result.IsZero() {
+	if result, err := r.preflightChecks(ctx, controlPlane, excludeFor...); err != nil || !result.IsZero() {
 		return result, err
 	}
 
+	// Join the etcd member of the new machine as a learner, if supported.
+	if err := r.reconcileEtcdLearnerMode(ctx, controlPlane); err != nil {
+		logger.Error(err, "Failed to reconcile etcd learner mode")
+		return ctrl.Result{}, err
+	}
+
 	// Create the bootstrap configuration
 	bootstrapSpec := controlPlane.JoinControlPlaneConfig()
 	fd := controlPlane.NextFailureDomainForScaleUp()
@@ -117,6 +127,14 @@ func (r *KubeadmControlPlaneReconciler) scaleDownControlPlane(
 		return ctrl.Result{}, errors.New("failed to pick control plane Machine to delete")
 	}
 
+	// If the load balancer hooks are enabled, remove the machine from the API server load balancer before
+	// removing its etcd member and deleting it.
+	if kcp.Spec.LoadBalancerHooks {
+		if result, err := r.detachFromLoadBalancer(ctx, controlPlane, machineToDelete); err != nil || !result.IsZero() {
+			return result, err
+		}
+	}
+
 	// If KCP should manage etcd, If etcd leadership is on machine that is about to be deleted, move it to the newest member available.
 	if controlPlane.IsEtcdManaged() {
 		etcdLeaderCandidate := controlPlane.Machines.Newest()
@@ -152,11 +170,60 @@ func (r *KubeadmControlPlaneReconciler) scaleDownControlPlane(
 	return ctrl.Result{Requeue: true}, nil
 }
 
+// detachFromLoadBalancer requests the infrastructure provider to remove the machine from the API server load balancer,
+// and requeues until the infrastructure provider acknowledges the request.
+func (r *KubeadmControlPlaneReconciler) detachFromLoadBalancer(ctx context.Context, controlPlane *internal.ControlPlane, machine *clusterv1.Machine) (ctrl.Result, error) {
+	logger := controlPlane.Logger().WithValues("machine", machine.Name)
+
+	switch machine.Annotations[controlplanev1.LoadBalancerDetachAnnotation] {
+	case controlplanev1.LoadBalancerHookCompleted:
+		return ctrl.Result{}, nil
+	case controlplanev1.LoadBalancerHookRequested:
+		logger.Info("Waiting for the machine to be removed from the API server load balancer")
+		return ctrl.Result{RequeueAfter: preflightFailedRequeueAfter}, nil
+	}
+
+	patchHelper, err := patch.NewHelper(machine, r.Client)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	annotations.AddAnnotations(machine, map[string]string{controlplanev1.LoadBalancerDetachAnnotation: controlplanev1.LoadBalancerHookRequested})
+	if err := patchHelper.Patch(ctx, machine); err != nil {
+		return ctrl.Result{}, errors.Wrapf(err, "failed to request the removal of machine %s from the API server load balancer", machine.Name)
+	}
+	logger.Info("Requested the removal of the machine from the API server load balancer")
+	return ctrl.Result{RequeueAfter: preflightFailedRequeueAfter}, nil
+}
+
+// cancelLoadBalancerDetach removes the lb-detach annotation from the machines selected for a scale down which has been
+// abandoned; those machines would otherwise stay out of the API server load balancer, and be selected again by the next
+// scale down. If the load balancer hooks are enabled, the infrastructure provider is requested to add the machines back
+// to the load balancer.
+func (r *KubeadmControlPlaneReconciler) cancelLoadBalancerDetach(ctx context.Context, controlPlane *internal.ControlPlane) error {
+	machines := controlPlane.Machines.Filter(hasLoadBalancerDetachAnnotation, collections.Not(collections.HasDeletionTimestamp))
+	for _, machine := range machines {
+		patchHelper, err := patch.NewHelper(machine, r.Client)
+		if err != nil {
+			return err
+		}
+		delete(machine.Annotations, controlplanev1.LoadBalancerDetachAnnotation)
+		if controlPlane.KCP.Spec.LoadBalancerHooks {
+			annotations.AddAnnotations(machine, map[string]string{controlplanev1.LoadBalancerAttachAnnotation: controlplanev1.LoadBalancerHookRequested})
+		}
+		if err := patchHelper.Patch(ctx, machine); err != nil {
+			return errors.Wrapf(err, "failed to cancel the removal of machine %s from the API server load balancer", machine.Name)
+		}
+		controlPlane.Logger().Info("Requested to add back the machine to the API server load balancer, scale down is no longer required", "machine", machine.Name)
+	}
+	return nil
+}
+
 // preflightChecks checks if the control plane is stable before proceeding with a scale up/scale down operation,
 // where stable means that:
 // - There are no machine deletion in progress
 // - All the health conditions on KCP are true.
 // - All the health conditions on the control plane machines are true.
+// - All the control plane machines have been added to the API server load balancer, if the load balancer hooks are enabled.
 // If the control plane is not passing preflight checks, it requeue.
 //
 // NOTE: this func uses KCP conditions, it is required to call reconcileControlPlaneConditions before this.
@@ -175,18 +242,27 @@ func (r *KubeadmControlPlaneReconciler) preflightChecks(_ context.Context, contr
 		return ctrl.Result{RequeueAfter: deleteRequeueAfter}, nil
 	}
 
-	// Check machine health conditions; if there are conditions with False or Unknown, then wait.
-	allMachineHealthConditions := []clusterv1.ConditionType{
-		controlplanev1.MachineAPIServerPodHealthyCondition,
-		controlplanev1.MachineControllerManagerPodHealthyCondition,
-		controlplanev1.MachineSchedulerPodHealthyCondition,
+	// If there are etcd learners not yet promoted, wait before adding or removing other etcd members.
+	if conditions.IsFalse(controlPlane.KCP, controlplanev1.EtcdMembersPromotedCondition) {
+		logger.Info("Waiting for etcd learners to be promoted", "reason", conditions.GetMessage(controlPlane.KCP, controlplanev1.EtcdMembersPromotedCondition))
+		return ctrl.Result{RequeueAfter: preflightFailedRequeueAfter}, nil
 	}
-	if controlPlane.IsEtcdManaged() {
-		allMachineHealthConditions = append(allMachineHealthConditions,
-			controlplanev1.MachineEtcdPodHealthyCondition,
-			controlplanev1.MachineEtcdMemberHealthyCondition,
-		)
+
+	// Check KCP conditions; if there are health problems, then wait.
+	// NOTE: KCP conditions report problems that can't be assigned to a specific machine, e.g. an etcd member
+	// without a corresponding machine.
+	if conditions.IsFalse(controlPlane.KCP, controlplanev1.ControlPlaneComponentsHealthyCondition) ||
+		conditions.IsFalse(controlPlane.KCP, controlplanev1.EtcdClusterHealthyCondition) {
+		r.recorder.Eventf(controlPlane.KCP, corev1.EventTypeWarning, "ControlPlaneUnhealthy",
+			"Waiting for control plane to pass preflight checks to continue reconciliation: the control plane or the etcd cluster are not healthy")
+		logger.Info("Waiting for control plane to pass preflight checks",
+			"ControlPlaneComponentsHealthy", conditions.GetMessage(controlPlane.KCP, controlplanev1.ControlPlaneComponentsHealthyCondition),
+			"EtcdClusterHealthy", conditions.GetMessage(controlPlane.KCP, controlplanev1.EtcdClusterHealthyCondition))
+		return ctrl.Result{RequeueAfter: preflightFailedRequeueAfter}, nil
 	}
+
+	// Check machine health conditions; if there are conditions with False or Unknown, then wait.
+	allMachineHealthConditions := machineHealthConditions(controlPlane)
 	machineErrors := []error{}
 
 loopmachines:
@@ -204,6 +280,10 @@ loopmachines:
 				machineErrors = append(machineErrors, err)
 			}
 		}
+
+		if controlPlane.KCP.Spec.LoadBalancerHooks && machine.Annotations[controlplanev1.LoadBalancerAttachAnnotation] == controlplanev1.LoadBalancerHookRequested {
+			machineErrors = append(machineErrors, errors.Errorf("machine %s is waiting to be added to the API server load balancer", machine.Name))
+		}
 	}
 	if len(machineErrors) > 0 {
 		aggregatedError := kerrors.NewAggregate(machineErrors)
@@ -217,6 +297,23 @@ loopmachines:
 	return ctrl.Result{}, nil
 }
 
+// machineHealthConditions returns the conditions reporting the health of the control plane components
+// hosted on each control plane machine.
+func machineHealthConditions(controlPlane *internal.ControlPlane) []clusterv1.ConditionType {
+	allMachineHealthConditions := []clusterv1.ConditionType{
+		controlplanev1.MachineAPIServerPodHealthyCondition,
+		controlplanev1.MachineControllerManagerPodHealthyCondition,
+		controlplanev1.MachineSchedulerPodHealthyCondition,
+	}
+	if controlPlane.IsEtcdManaged() {
+		allMachineHealthConditions = append(allMachineHealthConditions,
+			controlplanev1.MachineEtcdPodHealthyCondition,
+			controlplanev1.MachineEtcdMemberHealthyCondition,
+		)
+	}
+	return allMachineHealthConditions
+}
+
 func preflightCheckCondition(kind string, obj conditions.Getter, condition clusterv1.ConditionType) error {
 	c := conditions.Get(obj, condition)
 	if c == nil {
@@ -234,6 +331,9 @@ func preflightCheckCondition(kind string, obj conditions.Getter, condition clust
 func selectMachineForScaleDown(controlPlane *internal.ControlPlane, outdatedMachines collections.Machines) (*clusterv1.Machine, error) {
 	machines := controlPlane.Machines
 	switch {
+	// A machine being removed from the API server load balancer has already been selected for scale down.
+	case machines.Filter(hasLoadBalancerDetachAnnotation).Len() > 0:
+		machines = machines.Filter(hasLoadBalancerDetachAnnotation)
 	case controlPlane.MachineWithDeleteAnnotation(outdatedMachines).Len() > 0:
 		machines = controlPlane.MachineWithDeleteAnnotation(outdatedMachines)
 	case controlPlane.MachineWithDeleteAnnotation(machines).Len() > 0:
@@ -243,3 +343,10 @@ func selectMachineForScaleDown(controlPlane *internal.ControlPlane, outdatedMach
 	}
 	return controlPlane.MachineInFailureDomainWithMostMachines(machines)
 }
+
+// hasLoadBalancerDetachAnnotation returns true if the removal of the machine from the API server load balancer
+// has been requested.
+func hasLoadBalancerDetachAnnotation(machine *clusterv1.Machine) bool {
+	_, ok := machine.Annotations[controlplanev1.LoadBalancerDetachAnnotation]
+	return ok
+}
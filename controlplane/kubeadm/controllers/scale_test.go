@@ -302,6 +302,7 @@ func TestSelectMachineForScaleDown(t *testing.T) {
 	m6 := machine("machine-6", withFailureDomain("two"), withTimestamp(startDate.Add(-7*time.Hour)))
 	m7 := machine("machine-7", withFailureDomain("two"), withTimestamp(startDate.Add(-5*time.Hour)), withAnnotation("cluster.x-k8s.io/delete-machine"))
 	m8 := machine("machine-8", withFailureDomain("two"), withTimestamp(startDate.Add(-6*time.Hour)), withAnnotation("cluster.x-k8s.io/delete-machine"))
+	m9 := machine("machine-9", withFailureDomain("one"), withTimestamp(startDate.Add(-time.Hour)), withAnnotation(controlplanev1.LoadBalancerDetachAnnotation))
 
 	mc3 := collections.FromMachines(m1, m2, m3, m4, m5)
 	mc6 := collections.FromMachines(m6, m7, m8)
@@ -327,6 +328,11 @@ func TestSelectMachineForScaleDown(t *testing.T) {
 		Cluster:  &clusterv1.Cluster{Status: clusterv1.ClusterStatus{FailureDomains: fd}},
 		Machines: mc6,
 	}
+	detachingControlPlane := &internal.ControlPlane{
+		KCP:      &kcp,
+		Cluster:  &clusterv1.Cluster{Status: clusterv1.ClusterStatus{FailureDomains: fd}},
+		Machines: collections.FromMachines(m6, m7, m8, m9),
+	}
 
 	testCases := []struct {
 		name             string
@@ -384,6 +390,13 @@ func TestSelectMachineForScaleDown(t *testing.T) {
 			expectErr:        false,
 			expectedMachine:  clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "machine-8"}},
 		},
+		{
+			name:             "when there is a machine being removed from the load balancer, it returns that machine first",
+			cp:               detachingControlPlane,
+			outDatedMachines: collections.FromMachines(m7, m8),
+			expectErr:        false,
+			expectedMachine:  clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "machine-9"}},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -445,6 +458,45 @@ func TestPreflightChecks(t *testing.T) {
 			},
 			expectResult: ctrl.Result{RequeueAfter: preflightFailedRequeueAfter},
 		},
+		{
+			name: "control plane with etcd learners not yet promoted should requeue",
+			kcp: &controlplanev1.KubeadmControlPlane{
+				Status: controlplanev1.KubeadmControlPlaneStatus{
+					Conditions: clusterv1.Conditions{
+						*conditions.FalseCondition(controlplanev1.EtcdMembersPromotedCondition, controlplanev1.WaitingForEtcdLearnersReason, clusterv1.ConditionSeverityInfo, ""),
+					},
+				},
+			},
+			machines: []*clusterv1.Machine{
+				{},
+			},
+			expectResult: ctrl.Result{RequeueAfter: preflightFailedRequeueAfter},
+		},
+		{
+			name: "control plane with an etcd member without a corresponding machine should requeue",
+			kcp: &controlplanev1.KubeadmControlPlane{
+				Status: controlplanev1.KubeadmControlPlaneStatus{
+					Conditions: clusterv1.Conditions{
+						*conditions.TrueCondition(controlplanev1.ControlPlaneComponentsHealthyCondition),
+						*conditions.FalseCondition(controlplanev1.EtcdClusterHealthyCondition, controlplanev1.EtcdClusterUnhealthyReason, clusterv1.ConditionSeverityError, "etcd member foo does not have a corresponding machine"),
+					},
+				},
+			},
+			machines: []*clusterv1.Machine{
+				{
+					Status: clusterv1.MachineStatus{
+						Conditions: clusterv1.Conditions{
+							*conditions.TrueCondition(controlplanev1.MachineAPIServerPodHealthyCondition),
+							*conditions.TrueCondition(controlplanev1.MachineControllerManagerPodHealthyCondition),
+							*conditions.TrueCondition(controlplanev1.MachineSchedulerPodHealthyCondition),
+							*conditions.TrueCondition(controlplanev1.MachineEtcdPodHealthyCondition),
+							*conditions.TrueCondition(controlplanev1.MachineEtcdMemberHealthyCondition),
+						},
+					},
+				},
+			},
+			expectResult: ctrl.Result{RequeueAfter: preflightFailedRequeueAfter},
+		},
 		{
 			name: "control plane with an healthy machine and an healthy kcp condition should pass",
 			kcp: &controlplanev1.KubeadmControlPlane{
@@ -470,6 +522,39 @@ func TestPreflightChecks(t *testing.T) {
 			},
 			expectResult: ctrl.Result{},
 		},
+		{
+			name: "control plane with a machine waiting to be added to the load balancer should requeue",
+			kcp: &controlplanev1.KubeadmControlPlane{
+				Spec: controlplanev1.KubeadmControlPlaneSpec{
+					LoadBalancerHooks: true,
+				},
+				Status: controlplanev1.KubeadmControlPlaneStatus{
+					Conditions: clusterv1.Conditions{
+						*conditions.TrueCondition(controlplanev1.ControlPlaneComponentsHealthyCondition),
+						*conditions.TrueCondition(controlplanev1.EtcdClusterHealthyCondition),
+					},
+				},
+			},
+			machines: []*clusterv1.Machine{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Annotations: map[string]string{
+							controlplanev1.LoadBalancerAttachAnnotation: controlplanev1.LoadBalancerHookRequested,
+						},
+					},
+					Status: clusterv1.MachineStatus{
+						Conditions: clusterv1.Conditions{
+							*conditions.TrueCondition(controlplanev1.MachineAPIServerPodHealthyCondition),
+							*conditions.TrueCondition(controlplanev1.MachineControllerManagerPodHealthyCondition),
+							*conditions.TrueCondition(controlplanev1.MachineSchedulerPodHealthyCondition),
+							*conditions.TrueCondition(controlplanev1.MachineEtcdPodHealthyCondition),
+							*conditions.TrueCondition(controlplanev1.MachineEtcdMemberHealthyCondition),
+						},
+					},
+				},
+			},
+			expectResult: ctrl.Result{RequeueAfter: preflightFailedRequeueAfter},
+		},
 	}
 
 	for _, tt := range testCases {
@@ -491,6 +576,75 @@ func TestPreflightChecks(t *testing.T) {
 	}
 }
 
+func TestDetachFromLoadBalancer(t *testing.T) {
+	controlPlane := &internal.ControlPlane{
+		KCP:     &controlplanev1.KubeadmControlPlane{},
+		Cluster: &clusterv1.Cluster{},
+	}
+
+	t.Run("requests the removal of the machine from the load balancer", func(t *testing.T) {
+		g := NewWithT(t)
+
+		m := machine("one")
+		fakeClient := newFakeClient(m.DeepCopy())
+		r := &KubeadmControlPlaneReconciler{Client: fakeClient}
+
+		result, err := r.detachFromLoadBalancer(context.Background(), controlPlane, m)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(result).To(Equal(ctrl.Result{RequeueAfter: preflightFailedRequeueAfter}))
+
+		updatedMachine := &clusterv1.Machine{}
+		g.Expect(fakeClient.Get(context.Background(), client.ObjectKeyFromObject(m), updatedMachine)).To(Succeed())
+		g.Expect(updatedMachine.Annotations).To(HaveKeyWithValue(controlplanev1.LoadBalancerDetachAnnotation, controlplanev1.LoadBalancerHookRequested))
+	})
+	t.Run("waits for the infrastructure provider to remove the machine from the load balancer", func(t *testing.T) {
+		g := NewWithT(t)
+
+		m := machine("one")
+		m.Annotations = map[string]string{controlplanev1.LoadBalancerDetachAnnotation: controlplanev1.LoadBalancerHookRequested}
+		r := &KubeadmControlPlaneReconciler{Client: newFakeClient(m.DeepCopy())}
+
+		result, err := r.detachFromLoadBalancer(context.Background(), controlPlane, m)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(result).To(Equal(ctrl.Result{RequeueAfter: preflightFailedRequeueAfter}))
+	})
+	t.Run("proceeds when the machine has been removed from the load balancer", func(t *testing.T) {
+		g := NewWithT(t)
+
+		m := machine("one")
+		m.Annotations = map[string]string{controlplanev1.LoadBalancerDetachAnnotation: controlplanev1.LoadBalancerHookCompleted}
+		r := &KubeadmControlPlaneReconciler{Client: newFakeClient(m.DeepCopy())}
+
+		result, err := r.detachFromLoadBalancer(context.Background(), controlPlane, m)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(result.IsZero()).To(BeTrue())
+	})
+}
+
+func TestCancelLoadBalancerDetach(t *testing.T) {
+	g := NewWithT(t)
+
+	detached := machine("one")
+	detached.Annotations = map[string]string{controlplanev1.LoadBalancerDetachAnnotation: controlplanev1.LoadBalancerHookCompleted}
+	other := machine("two")
+	fakeClient := newFakeClient(detached.DeepCopy(), other.DeepCopy())
+	r := &KubeadmControlPlaneReconciler{Client: fakeClient}
+	controlPlane := &internal.ControlPlane{
+		KCP:      &controlplanev1.KubeadmControlPlane{Spec: controlplanev1.KubeadmControlPlaneSpec{LoadBalancerHooks: true}},
+		Cluster:  &clusterv1.Cluster{},
+		Machines: collections.FromMachines(detached, other),
+	}
+
+	g.Expect(r.cancelLoadBalancerDetach(context.Background(), controlPlane)).To(Succeed())
+
+	updatedMachine := &clusterv1.Machine{}
+	g.Expect(fakeClient.Get(context.Background(), client.ObjectKeyFromObject(detached), updatedMachine)).To(Succeed())
+	g.Expect(updatedMachine.Annotations).NotTo(HaveKey(controlplanev1.LoadBalancerDetachAnnotation))
+	g.Expect(updatedMachine.Annotations).To(HaveKeyWithValue(controlplanev1.LoadBalancerAttachAnnotation, controlplanev1.LoadBalancerHookRequested))
+	g.Expect(fakeClient.Get(context.Background(), client.ObjectKeyFromObject(other), updatedMachine)).To(Succeed())
+	g.Expect(updatedMachine.Annotations).To(BeEmpty())
+}
+
 func TestPreflightCheckCondition(t *testing.T) {
 	condition := clusterv1.ConditionType("fooCondition")
 	testCases := []struct {
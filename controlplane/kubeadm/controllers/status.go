@@ -18,6 +18,8 @@ package controllers
 
 import (
 	"context"
+	"sort"
+	"strings"
 
 	"sigs.k8s.io/cluster-api/util/collections"
 
@@ -82,7 +84,16 @@ func (r *KubeadmControlPlaneReconciler) updateStatus(ctx context.Context, kcp *c
 		// are actually provisioned (vs reporting completed immediately after the last machine object is created).
 		readyMachines := ownedMachines.Filter(collections.IsReady())
 		if int32(len(readyMachines)) == replicas {
-			conditions.MarkTrue(kcp, controlplanev1.ResizedCondition)
+			// NOTE: Node readiness is not enough for a joined machine to be part of a working control plane, so
+			// we also wait for the control plane components hosted on each machine to be reported as healthy.
+			unhealthyMachines := readyMachines.Filter(collections.Not(internal.HasHealthyControlPlaneComponents(controlPlane.ControlPlaneComponentsConditions()...)))
+			if len(unhealthyMachines) > 0 {
+				names := unhealthyMachines.Names()
+				sort.Strings(names)
+				conditions.MarkFalse(kcp, controlplanev1.ResizedCondition, controlplanev1.WaitingForControlPlaneComponentsReason, clusterv1.ConditionSeverityInfo, "Waiting for control plane components to be healthy on machines %s", strings.Join(names, ", "))
+			} else {
+				conditions.MarkTrue(kcp, controlplanev1.ResizedCondition)
+			}
 		}
 
 		// This means that there was no error in generating the desired number of machine objects
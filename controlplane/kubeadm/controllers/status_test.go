@@ -229,6 +229,93 @@ func TestKubeadmControlPlaneReconciler_updateStatusAllMachinesReady(t *testing.T
 	g.Expect(kcp.Status.Ready).To(BeTrue())
 }
 
+func TestKubeadmControlPlaneReconciler_updateStatusResizedWaitsForControlPlaneComponents(t *testing.T) {
+	tests := []struct {
+		name              string
+		healthy           bool
+		wantResized       bool
+		wantResizedReason string
+	}{
+		{
+			name:              "resize is not completed if control plane components are not healthy",
+			healthy:           false,
+			wantResized:       false,
+			wantResizedReason: controlplanev1.WaitingForControlPlaneComponentsReason,
+		},
+		{
+			name:        "resize is completed if control plane components are healthy",
+			healthy:     true,
+			wantResized: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			cluster := &clusterv1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "test",
+					Name:      "foo",
+				},
+			}
+
+			kcp := &controlplanev1.KubeadmControlPlane{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "KubeadmControlPlane",
+					APIVersion: controlplanev1.GroupVersion.String(),
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: cluster.Namespace,
+					Name:      "foo",
+				},
+				Spec: controlplanev1.KubeadmControlPlaneSpec{
+					Version: "v1.16.6",
+					MachineTemplate: controlplanev1.KubeadmControlPlaneMachineTemplate{
+						InfrastructureRef: corev1.ObjectReference{
+							APIVersion: "test/v1alpha1",
+							Kind:       "UnknownInfraMachine",
+							Name:       "foo",
+						},
+					},
+				},
+			}
+			kcp.Default()
+			g.Expect(kcp.ValidateCreate()).To(Succeed())
+
+			m, n := createMachineNodePair("test-0", cluster, kcp, true)
+			conditions.MarkTrue(m, clusterv1.ReadyCondition)
+			if tt.healthy {
+				setMachineHealthy(m)
+			}
+			objs := []client.Object{cluster.DeepCopy(), kcp.DeepCopy(), kubeadmConfigMap(), n, m}
+
+			fakeClient := newFakeClient(objs...)
+			log.SetLogger(klogr.New())
+
+			r := &KubeadmControlPlaneReconciler{
+				Client: fakeClient,
+				managementCluster: &fakeManagementCluster{
+					Machines: map[string]*clusterv1.Machine{m.Name: m},
+					Workload: fakeWorkloadCluster{
+						Status: internal.ClusterStatus{
+							Nodes:            1,
+							ReadyNodes:       1,
+							HasKubeadmConfig: true,
+						},
+					},
+				},
+				recorder: record.NewFakeRecorder(32),
+			}
+
+			g.Expect(r.updateStatus(ctx, kcp, cluster)).To(Succeed())
+			g.Expect(conditions.IsTrue(kcp, controlplanev1.ResizedCondition)).To(Equal(tt.wantResized))
+			if !tt.wantResized {
+				g.Expect(conditions.GetReason(kcp, controlplanev1.ResizedCondition)).To(Equal(tt.wantResizedReason))
+			}
+		})
+	}
+}
+
 func TestKubeadmControlPlaneReconciler_updateStatusMachinesReadyMixed(t *testing.T) {
 	g := NewWithT(t)
 
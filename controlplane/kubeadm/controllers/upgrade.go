@@ -18,6 +18,7 @@ package controllers
 
 import (
 	"context"
+	"strings"
 
 	"github.com/blang/semver"
 	"github.com/pkg/errors"
@@ -26,6 +27,7 @@ import (
 	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal"
 	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/cluster-api/util/collections"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
 
@@ -55,6 +57,21 @@ func (r *KubeadmControlPlaneReconciler) upgradeControlPlane(
 		return ctrl.Result{}, errors.Wrapf(err, "failed to parse kubernetes version %q", kcp.Spec.Version)
 	}
 
+	// Once migrated to an external cloud provider, the control plane does not run the in-tree cloud provider anymore,
+	// so the rollout is started only if the external cloud controller manager is running.
+	if kcp.Spec.CloudProviderMigration != nil {
+		running, err := workloadCluster.IsExternalCloudControllerManagerRunning(ctx)
+		if err != nil {
+			return ctrl.Result{}, errors.Wrap(err, "failed to check the external cloud controller manager")
+		}
+		if !running {
+			logger.Info("Waiting for the external cloud controller manager to be running before migrating the control plane")
+			conditions.MarkFalse(kcp, controlplanev1.MachinesSpecUpToDateCondition, controlplanev1.WaitingForExternalCloudControllerManagerReason, clusterv1.ConditionSeverityWarning,
+				"Waiting for the external cloud controller manager to be running in the workload cluster")
+			return ctrl.Result{RequeueAfter: preflightFailedRequeueAfter}, nil
+		}
+	}
+
 	if err := workloadCluster.ReconcileKubeletRBACRole(ctx, parsedVersion); err != nil {
 		return ctrl.Result{}, errors.Wrap(err, "failed to reconcile the remote kubelet RBAC role")
 	}
@@ -87,16 +104,17 @@ func (r *KubeadmControlPlaneReconciler) upgradeControlPlane(
 		}
 	}
 
-	if kcp.Spec.KubeadmConfigSpec.ClusterConfiguration != nil {
-		if err := workloadCluster.UpdateAPIServerInKubeadmConfigMap(ctx, kcp.Spec.KubeadmConfigSpec.ClusterConfiguration.APIServer, parsedVersion); err != nil {
+	// NOTE: The desired ClusterConfiguration includes the flags required by the migration to the external cloud provider, if any.
+	if clusterConfiguration := internal.DesiredKubeadmConfigSpec(kcp).ClusterConfiguration; clusterConfiguration != nil {
+		if err := workloadCluster.UpdateAPIServerInKubeadmConfigMap(ctx, clusterConfiguration.APIServer, parsedVersion); err != nil {
 			return ctrl.Result{}, errors.Wrap(err, "failed to update api server in the kubeadm config map")
 		}
 
-		if err := workloadCluster.UpdateControllerManagerInKubeadmConfigMap(ctx, kcp.Spec.KubeadmConfigSpec.ClusterConfiguration.ControllerManager, parsedVersion); err != nil {
+		if err := workloadCluster.UpdateControllerManagerInKubeadmConfigMap(ctx, clusterConfiguration.ControllerManager, parsedVersion); err != nil {
 			return ctrl.Result{}, errors.Wrap(err, "failed to update controller manager in the kubeadm config map")
 		}
 
-		if err := workloadCluster.UpdateSchedulerInKubeadmConfigMap(ctx, kcp.Spec.KubeadmConfigSpec.ClusterConfiguration.Scheduler, parsedVersion); err != nil {
+		if err := workloadCluster.UpdateSchedulerInKubeadmConfigMap(ctx, clusterConfiguration.Scheduler, parsedVersion); err != nil {
 			return ctrl.Result{}, errors.Wrap(err, "failed to update scheduler in the kubeadm config map")
 		}
 	}
@@ -109,7 +127,16 @@ func (r *KubeadmControlPlaneReconciler) upgradeControlPlane(
 	case controlplanev1.RollingUpdateStrategyType:
 		// RolloutStrategy is currently defaulted and validated to be RollingUpdate
 		// We can ignore MaxUnavailable because we are enforcing health checks before we get here.
-		maxNodes := *kcp.Spec.Replicas + int32(kcp.Spec.RolloutStrategy.RollingUpdate.MaxSurge.IntValue())
+		maxSurge := int32(kcp.Spec.RolloutStrategy.RollingUpdate.MaxSurge.IntValue())
+		// Etcd allows only one learner at a time, so machines are replaced one at a time when etcd members join as learners.
+		if maxSurge > 1 && conditions.Has(kcp, controlplanev1.EtcdMembersPromotedCondition) {
+			logger.V(2).Info("Etcd members join as learners, replacing machines one at a time", "maxSurge", maxSurge)
+			maxSurge = 1
+		}
+		if maxSurge > 1 {
+			return r.rolloutWithSurge(ctx, cluster, kcp, controlPlane, machinesRequireUpgrade, maxSurge)
+		}
+		maxNodes := *kcp.Spec.Replicas + maxSurge
 		if int32(controlPlane.Machines.Len()) < maxNodes {
 			// scaleUp ensures that we don't continue scaling up while waiting for Machines to have NodeRefs
 			return r.scaleUpControlPlane(ctx, cluster, kcp, controlPlane)
@@ -120,3 +147,42 @@ func (r *KubeadmControlPlaneReconciler) upgradeControlPlane(
 		return ctrl.Result{}, nil
 	}
 }
+
+// rolloutWithSurge replaces machines in batches of up to maxSurge machines. The machines of a batch are created
+// without waiting for the previous ones to be fully provisioned; once all the machines are healthy, outdated machines are
+// deleted one at a time, and the next batch starts only after the current one is completed.
+// NOTE: Etcd members are still added one at a time, because adding a member changes the etcd quorum size and a new
+// member does not count towards quorum until it has caught up; so, when etcd is managed by KCP, the next machine of a
+// batch is created only after the etcd member of each machine already created in the batch is healthy.
+// Batches are not used when etcd members join as learners, because etcd allows only one learner at a time;
+// see reconcileEtcdLearnerMode.
+func (r *KubeadmControlPlaneReconciler) rolloutWithSurge(
+	ctx context.Context,
+	cluster *clusterv1.Cluster,
+	kcp *controlplanev1.KubeadmControlPlane,
+	controlPlane *internal.ControlPlane,
+	machinesRequireUpgrade collections.Machines,
+	maxSurge int32,
+) (ctrl.Result, error) {
+	logger := controlPlane.Logger()
+	surge := int32(controlPlane.Machines.Len()) - *kcp.Spec.Replicas
+
+	// Up-to-date machines not yet healthy belong to the batch being created, so they are excluded from the
+	// preflight checks when creating the other machines of the same batch.
+	provisioningMachines := controlPlane.Machines.Difference(machinesRequireUpgrade).Filter(
+		collections.Not(internal.HasHealthyControlPlaneComponents(machineHealthConditions(controlPlane)...)),
+	)
+	batchInProgress := surge <= 0 || provisioningMachines.Len() > 0
+
+	if batchInProgress && surge < maxSurge && int32(machinesRequireUpgrade.Len()) > surge {
+		if controlPlane.IsEtcdManaged() {
+			joiningMachines := provisioningMachines.Filter(collections.Not(internal.HasHealthyControlPlaneComponents(controlplanev1.MachineEtcdMemberHealthyCondition)))
+			if joiningMachines.Len() > 0 {
+				logger.Info("Waiting for the etcd members of the new machines to be healthy before creating the next machine", "Machines", strings.Join(joiningMachines.Names(), ", "))
+				return ctrl.Result{RequeueAfter: preflightFailedRequeueAfter}, nil
+			}
+		}
+		return r.scaleUpControlPlane(ctx, cluster, kcp, controlPlane, provisioningMachines.UnsortedList()...)
+	}
+	return r.scaleDownControlPlane(ctx, cluster, kcp, controlPlane, machinesRequireUpgrade)
+}
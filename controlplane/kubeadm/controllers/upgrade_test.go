@@ -31,7 +31,9 @@ import (
 	"k8s.io/utils/pointer"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1alpha4"
 	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -212,6 +214,206 @@ func TestKubeadmControlPlaneReconciler_RolloutStrategy_ScaleDown(t *testing.T) {
 	g.Expect(remainingMachines.Items).To(HaveLen(2))
 }
 
+func TestKubeadmControlPlaneReconciler_RolloutStrategy_MaxSurge(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster, kcp, genericMachineTemplate := createClusterWithControlPlane()
+	cluster.Spec.ControlPlaneEndpoint.Host = Host
+	cluster.Spec.ControlPlaneEndpoint.Port = 6443
+	kcp.Spec.KubeadmConfigSpec.ClusterConfiguration = nil
+	kcp.Spec.Replicas = pointer.Int32Ptr(3)
+	kcp.Spec.RolloutStrategy.RollingUpdate.MaxSurge.IntVal = 2
+	kcp.Spec.Version = UpdatedVersion
+	setKCPHealthy(kcp)
+
+	objs := []client.Object{cluster.DeepCopy(), kcp.DeepCopy(), genericMachineTemplate.DeepCopy()}
+	for i := 0; i < 3; i++ {
+		m, _ := createMachineNodePair(fmt.Sprintf("test-%d", i), cluster, kcp, true)
+		setMachineHealthy(m)
+		objs = append(objs, m)
+	}
+	fakeClient := newFakeClient(objs...)
+
+	r := &KubeadmControlPlaneReconciler{
+		Client:   fakeClient,
+		recorder: record.NewFakeRecorder(32),
+		managementCluster: &fakeManagementCluster{
+			Management: &internal.Management{Client: fakeClient},
+			Workload:   fakeWorkloadCluster{},
+		},
+	}
+
+	listMachines := func() collections.Machines {
+		machineList := &clusterv1.MachineList{}
+		g.Expect(fakeClient.List(ctx, machineList, client.InNamespace(cluster.Namespace))).To(Succeed())
+		return collections.FromMachineList(machineList)
+	}
+
+	// All the existing machines are outdated.
+	needingUpgrade := listMachines()
+	controlPlane := &internal.ControlPlane{
+		KCP:      kcp,
+		Cluster:  cluster,
+		Machines: needingUpgrade,
+	}
+
+	// The first machine of the batch is created.
+	result, err := r.upgradeControlPlane(ctx, cluster, kcp, controlPlane, needingUpgrade)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result).To(Equal(ctrl.Result{Requeue: true}))
+	controlPlane.Machines = listMachines()
+	g.Expect(controlPlane.Machines).To(HaveLen(4))
+
+	// The second machine of the batch is not created until the etcd member of the first one is healthy,
+	// so etcd members join one at a time.
+	result, err = r.upgradeControlPlane(ctx, cluster, kcp, controlPlane, needingUpgrade)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result).To(Equal(ctrl.Result{RequeueAfter: preflightFailedRequeueAfter}))
+	g.Expect(listMachines()).To(HaveLen(4))
+
+	// The second machine of the batch is created once the etcd member of the first one is healthy, without waiting
+	// for the first machine to be fully provisioned.
+	for _, m := range controlPlane.Machines.Difference(needingUpgrade) {
+		conditions.MarkTrue(m, controlplanev1.MachineEtcdMemberHealthyCondition)
+	}
+	result, err = r.upgradeControlPlane(ctx, cluster, kcp, controlPlane, needingUpgrade)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result).To(Equal(ctrl.Result{Requeue: true}))
+	controlPlane.Machines = listMachines()
+	g.Expect(controlPlane.Machines).To(HaveLen(5))
+
+	// Once maxSurge is reached, outdated machines are deleted only after the new machines are healthy.
+	result, err = r.upgradeControlPlane(ctx, cluster, kcp, controlPlane, needingUpgrade)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result).To(Equal(ctrl.Result{RequeueAfter: preflightFailedRequeueAfter}))
+	g.Expect(listMachines()).To(HaveLen(5))
+}
+
+func TestKubeadmControlPlaneReconciler_RolloutStrategy_MaxSurgeWithEtcdLearners(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster, kcp, genericMachineTemplate := createClusterWithControlPlane()
+	cluster.Spec.ControlPlaneEndpoint.Host = Host
+	cluster.Spec.ControlPlaneEndpoint.Port = 6443
+	kcp.Spec.KubeadmConfigSpec.ClusterConfiguration = nil
+	kcp.Spec.Replicas = pointer.Int32Ptr(3)
+	kcp.Spec.RolloutStrategy.RollingUpdate.MaxSurge.IntVal = 2
+	kcp.Spec.Version = UpdatedVersion
+	setKCPHealthy(kcp)
+
+	objs := []client.Object{cluster.DeepCopy(), kcp.DeepCopy(), genericMachineTemplate.DeepCopy()}
+	for i := 0; i < 3; i++ {
+		m, _ := createMachineNodePair(fmt.Sprintf("test-%d", i), cluster, kcp, true)
+		setMachineHealthy(m)
+		objs = append(objs, m)
+	}
+	fakeClient := newFakeClient(objs...)
+
+	r := &KubeadmControlPlaneReconciler{
+		Client:   fakeClient,
+		recorder: record.NewFakeRecorder(32),
+		managementCluster: &fakeManagementCluster{
+			Management: &internal.Management{Client: fakeClient},
+			Workload:   fakeWorkloadCluster{EtcdLearnerMode: true},
+		},
+	}
+
+	listMachines := func() collections.Machines {
+		machineList := &clusterv1.MachineList{}
+		g.Expect(fakeClient.List(ctx, machineList, client.InNamespace(cluster.Namespace))).To(Succeed())
+		return collections.FromMachineList(machineList)
+	}
+
+	// All the existing machines are outdated.
+	needingUpgrade := listMachines()
+	controlPlane := &internal.ControlPlane{
+		KCP:      kcp,
+		Cluster:  cluster,
+		Machines: needingUpgrade,
+	}
+
+	// The first machine is created, and its etcd member joins as a learner.
+	result, err := r.upgradeControlPlane(ctx, cluster, kcp, controlPlane, needingUpgrade)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result).To(Equal(ctrl.Result{Requeue: true}))
+	g.Expect(conditions.IsTrue(kcp, controlplanev1.EtcdMembersPromotedCondition)).To(BeTrue())
+	controlPlane.Machines = listMachines()
+	g.Expect(controlPlane.Machines).To(HaveLen(4))
+
+	// No other machine is created while the first one is being provisioned, because etcd allows only one learner at a time.
+	result, err = r.upgradeControlPlane(ctx, cluster, kcp, controlPlane, needingUpgrade)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result).To(Equal(ctrl.Result{RequeueAfter: preflightFailedRequeueAfter}))
+	g.Expect(listMachines()).To(HaveLen(4))
+
+	// No machine is created or deleted while the learner is waiting to be promoted.
+	for _, m := range controlPlane.Machines {
+		setMachineHealthy(m)
+	}
+	conditions.MarkFalse(kcp, controlplanev1.EtcdMembersPromotedCondition, controlplanev1.WaitingForEtcdLearnersReason, clusterv1.ConditionSeverityInfo, "")
+	result, err = r.upgradeControlPlane(ctx, cluster, kcp, controlPlane, needingUpgrade)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result).To(Equal(ctrl.Result{RequeueAfter: preflightFailedRequeueAfter}))
+	g.Expect(listMachines()).To(HaveLen(4))
+}
+
+func TestKubeadmControlPlaneReconciler_CloudProviderMigration_WaitsForExternalCloudControllerManager(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster, kcp, genericMachineTemplate := createClusterWithControlPlane()
+	cluster.Spec.ControlPlaneEndpoint.Host = Host
+	cluster.Spec.ControlPlaneEndpoint.Port = 6443
+	kcp.Spec.KubeadmConfigSpec.ClusterConfiguration = nil
+	kcp.Spec.Replicas = pointer.Int32Ptr(1)
+	kcp.Spec.CloudProviderMigration = &controlplanev1.CloudProviderMigration{}
+	setKCPHealthy(kcp)
+
+	m, _ := createMachineNodePair("test-0", cluster, kcp, true)
+	setMachineHealthy(m)
+	fakeClient := newFakeClient(cluster.DeepCopy(), kcp.DeepCopy(), genericMachineTemplate.DeepCopy(), m)
+
+	workloadCluster := fakeWorkloadCluster{}
+	r := &KubeadmControlPlaneReconciler{
+		Client:   fakeClient,
+		recorder: record.NewFakeRecorder(32),
+		managementCluster: &fakeManagementCluster{
+			Management: &internal.Management{Client: fakeClient},
+			Workload:   workloadCluster,
+		},
+	}
+
+	listMachines := func() collections.Machines {
+		machineList := &clusterv1.MachineList{}
+		g.Expect(fakeClient.List(ctx, machineList, client.InNamespace(cluster.Namespace))).To(Succeed())
+		return collections.FromMachineList(machineList)
+	}
+
+	needingUpgrade := listMachines()
+	controlPlane := &internal.ControlPlane{
+		KCP:      kcp,
+		Cluster:  cluster,
+		Machines: needingUpgrade,
+	}
+
+	// The rollout does not start until the external cloud controller manager is running.
+	result, err := r.upgradeControlPlane(ctx, cluster, kcp, controlPlane, needingUpgrade)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result).To(Equal(ctrl.Result{RequeueAfter: preflightFailedRequeueAfter}))
+	g.Expect(conditions.GetReason(kcp, controlplanev1.MachinesSpecUpToDateCondition)).To(Equal(controlplanev1.WaitingForExternalCloudControllerManagerReason))
+	g.Expect(listMachines()).To(HaveLen(1))
+
+	// The rollout starts once the external cloud controller manager is running.
+	workloadCluster.ExternalCloudControllerManagerRunning = true
+	r.managementCluster = &fakeManagementCluster{
+		Management: &internal.Management{Client: fakeClient},
+		Workload:   workloadCluster,
+	}
+	result, err = r.upgradeControlPlane(ctx, cluster, kcp, controlPlane, needingUpgrade)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result).To(Equal(ctrl.Result{Requeue: true}))
+	g.Expect(listMachines()).To(HaveLen(2))
+}
+
 type machineOpt func(*clusterv1.Machine)
 
 func machine(name string, opts ...machineOpt) *clusterv1.Machine {
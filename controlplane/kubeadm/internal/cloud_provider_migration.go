@@ -0,0 +1,112 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1alpha4"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	cloudProviderArg      = "cloud-provider"
+	cloudConfigArg        = "cloud-config"
+	externalCloudProvider = "external"
+
+	// cloudControllerManagerLeaseName is the name of the Lease used by the external cloud controller manager
+	// for leader election.
+	cloudControllerManagerLeaseName = "cloud-controller-manager"
+)
+
+// IsExternalCloudControllerManagerRunning returns true if an external cloud controller manager is running in the
+// workload cluster, i.e. if its leader election Lease has been renewed within the lease duration.
+func (w *Workload) IsExternalCloudControllerManagerRunning(ctx context.Context) (bool, error) {
+	lease := &coordinationv1.Lease{}
+	if err := w.Client.Get(ctx, ctrlclient.ObjectKey{Namespace: metav1.NamespaceSystem, Name: cloudControllerManagerLeaseName}, lease); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, errors.Wrap(err, "failed to get the cloud controller manager lease")
+	}
+	return isLeaseHeld(lease, time.Now()), nil
+}
+
+// isLeaseHeld returns true if the Lease has a holder which renewed it within the lease duration.
+func isLeaseHeld(lease *coordinationv1.Lease, now time.Time) bool {
+	if lease.Spec.HolderIdentity == nil || *lease.Spec.HolderIdentity == "" || lease.Spec.RenewTime == nil || lease.Spec.LeaseDurationSeconds == nil {
+		return false
+	}
+	expiration := lease.Spec.RenewTime.Add(time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second)
+	return now.Before(expiration)
+}
+
+// DesiredKubeadmConfigSpec returns the KubeadmConfigSpec the control plane machines must be created with, i.e. the
+// KCP KubeadmConfigSpec with the API server extra volumes required by the files with content from Secrets or ConfigMaps
+// and the changes required by the migration to the external cloud provider applied, if any.
+// NOTE: The desired KubeadmConfigSpec is used both for creating new machines and for detecting the machines to be
+// rolled out, so all the changes required by the migration are applied to the control plane with a single rollout.
+func DesiredKubeadmConfigSpec(kcp *controlplanev1.KubeadmControlPlane) *bootstrapv1.KubeadmConfigSpec {
+	spec := kcp.Spec.KubeadmConfigSpec.DeepCopy()
+	if spec.ClusterConfiguration != nil {
+		spec.ClusterConfiguration.APIServer.ExtraVolumes = append(spec.ClusterConfiguration.APIServer.ExtraVolumes, apiServerFileVolumes(spec)...)
+	}
+
+	migration := kcp.Spec.CloudProviderMigration
+	if migration == nil {
+		return spec
+	}
+
+	if spec.ClusterConfiguration == nil {
+		spec.ClusterConfiguration = &bootstrapv1.ClusterConfiguration{}
+	}
+	spec.ClusterConfiguration.APIServer.ExtraArgs = externalCloudProviderArgs(spec.ClusterConfiguration.APIServer.ExtraArgs, nil)
+	spec.ClusterConfiguration.ControllerManager.ExtraArgs = externalCloudProviderArgs(spec.ClusterConfiguration.ControllerManager.ExtraArgs, nil)
+
+	if spec.InitConfiguration == nil {
+		spec.InitConfiguration = &bootstrapv1.InitConfiguration{}
+	}
+	spec.InitConfiguration.NodeRegistration.KubeletExtraArgs = externalCloudProviderArgs(spec.InitConfiguration.NodeRegistration.KubeletExtraArgs, migration.KubeletExtraArgs)
+
+	if spec.JoinConfiguration == nil {
+		spec.JoinConfiguration = &bootstrapv1.JoinConfiguration{}
+	}
+	spec.JoinConfiguration.NodeRegistration.KubeletExtraArgs = externalCloudProviderArgs(spec.JoinConfiguration.NodeRegistration.KubeletExtraArgs, migration.KubeletExtraArgs)
+
+	return spec
+}
+
+// externalCloudProviderArgs returns the args of a component with the cloud provider set to external,
+// the in-tree cloud provider configuration removed and the additional args applied.
+func externalCloudProviderArgs(args map[string]string, additionalArgs map[string]string) map[string]string {
+	out := map[string]string{}
+	for k, v := range args {
+		out[k] = v
+	}
+	for k, v := range additionalArgs {
+		out[k] = v
+	}
+	delete(out, cloudConfigArg)
+	out[cloudProviderArg] = externalCloudProvider
+	return out
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1alpha4"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestDesiredKubeadmConfigSpec(t *testing.T) {
+	kubeadmConfigSpec := bootstrapv1.KubeadmConfigSpec{
+		ClusterConfiguration: &bootstrapv1.ClusterConfiguration{
+			APIServer: bootstrapv1.APIServer{
+				ControlPlaneComponent: bootstrapv1.ControlPlaneComponent{
+					ExtraArgs: map[string]string{"cloud-provider": "aws", "cloud-config": "/etc/kubernetes/cloud.conf", "foo": "bar"},
+				},
+			},
+		},
+		InitConfiguration: &bootstrapv1.InitConfiguration{
+			NodeRegistration: bootstrapv1.NodeRegistrationOptions{
+				KubeletExtraArgs: map[string]string{"cloud-provider": "aws", "node-labels": "foo=bar"},
+			},
+		},
+	}
+
+	t.Run("returns the KCP KubeadmConfigSpec if the cloud provider migration is not set", func(t *testing.T) {
+		g := NewWithT(t)
+		kcp := &controlplanev1.KubeadmControlPlane{
+			Spec: controlplanev1.KubeadmControlPlaneSpec{
+				KubeadmConfigSpec: *kubeadmConfigSpec.DeepCopy(),
+			},
+		}
+		g.Expect(DesiredKubeadmConfigSpec(kcp)).To(Equal(&kubeadmConfigSpec))
+	})
+	t.Run("sets the external cloud provider if the cloud provider migration is set", func(t *testing.T) {
+		g := NewWithT(t)
+		kcp := &controlplanev1.KubeadmControlPlane{
+			Spec: controlplanev1.KubeadmControlPlaneSpec{
+				KubeadmConfigSpec: *kubeadmConfigSpec.DeepCopy(),
+				CloudProviderMigration: &controlplanev1.CloudProviderMigration{
+					KubeletExtraArgs: map[string]string{"provider-id": "aws:///us-east-1a/i-1234"},
+				},
+			},
+		}
+
+		spec := DesiredKubeadmConfigSpec(kcp)
+		g.Expect(spec.ClusterConfiguration.APIServer.ExtraArgs).To(Equal(map[string]string{"cloud-provider": "external", "foo": "bar"}))
+		g.Expect(spec.ClusterConfiguration.ControllerManager.ExtraArgs).To(Equal(map[string]string{"cloud-provider": "external"}))
+		g.Expect(spec.InitConfiguration.NodeRegistration.KubeletExtraArgs).To(Equal(map[string]string{
+			"cloud-provider": "external",
+			"node-labels":    "foo=bar",
+			"provider-id":    "aws:///us-east-1a/i-1234",
+		}))
+		g.Expect(spec.JoinConfiguration.NodeRegistration.KubeletExtraArgs).To(Equal(map[string]string{
+			"cloud-provider": "external",
+			"provider-id":    "aws:///us-east-1a/i-1234",
+		}))
+
+		// The KCP KubeadmConfigSpec is not modified.
+		g.Expect(kcp.Spec.KubeadmConfigSpec).To(Equal(kubeadmConfigSpec))
+	})
+}
+
+func TestIsExternalCloudControllerManagerRunning(t *testing.T) {
+	lease := func(renewTime time.Time) *coordinationv1.Lease {
+		return &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: metav1.NamespaceSystem,
+				Name:      cloudControllerManagerLeaseName,
+			},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       pointer.StringPtr("ccm-1"),
+				LeaseDurationSeconds: pointer.Int32Ptr(15),
+				RenewTime:            &metav1.MicroTime{Time: renewTime},
+			},
+		}
+	}
+
+	t.Run("returns false if the lease does not exist", func(t *testing.T) {
+		g := NewWithT(t)
+
+		w := &Workload{Client: fake.NewClientBuilder().Build()}
+		running, err := w.IsExternalCloudControllerManagerRunning(ctx)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(running).To(BeFalse())
+	})
+
+	t.Run("returns true if the lease is held", func(t *testing.T) {
+		g := NewWithT(t)
+
+		w := &Workload{Client: fake.NewClientBuilder().WithObjects(lease(time.Now())).Build()}
+		running, err := w.IsExternalCloudControllerManagerRunning(ctx)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(running).To(BeTrue())
+	})
+
+	t.Run("returns false if the lease expired", func(t *testing.T) {
+		g := NewWithT(t)
+
+		w := &Workload{Client: fake.NewClientBuilder().WithObjects(lease(time.Now().Add(-time.Minute))).Build()}
+		running, err := w.IsExternalCloudControllerManagerRunning(ctx)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(running).To(BeFalse())
+	})
+
+	t.Run("returns false if the lease has no holder", func(t *testing.T) {
+		g := NewWithT(t)
+
+		l := lease(time.Now())
+		l.Spec.HolderIdentity = nil
+		w := &Workload{Client: fake.NewClientBuilder().WithObjects(l).Build()}
+		running, err := w.IsExternalCloudControllerManagerRunning(ctx)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(running).To(BeFalse())
+	})
+}
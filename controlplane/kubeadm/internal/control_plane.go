@@ -57,6 +57,15 @@ type ControlPlane struct {
 	// See discussion on https://github.com/kubernetes-sigs/cluster-api/pull/3405
 	kubeadmConfigs map[string]*bootstrapv1.KubeadmConfig
 	infraResources map[string]*unstructured.Unstructured
+
+	// filesContentHash is the hash of the content of the Secrets and ConfigMaps referenced by the KCP KubeadmConfigSpec.Files,
+	// and filesContentHashErr is the error preventing its computation, if any; see FilesContentHashError.
+	filesContentHash    string
+	filesContentHashErr error
+
+	// etcdLearners are the names of the etcd members which are learners; it is set only when checking the etcd
+	// members health, and it is nil if the etcd members could not be inspected, see SetEtcdLearners.
+	etcdLearners []string
 }
 
 // NewControlPlane returns an instantiated ControlPlane.
@@ -77,6 +86,9 @@ func NewControlPlane(ctx context.Context, client client.Client, cluster *cluster
 		}
 		patchHelpers[machine.Name] = patchHelper
 	}
+	// NOTE: A failure in computing the hash of the files content does not fail NewControlPlane, so it does not block
+	// operations not depending on it, like updating the status or deleting the control plane.
+	filesContentHash, filesContentHashErr := FilesContentHash(ctx, client, kcp)
 
 	return &ControlPlane{
 		KCP:                  kcp,
@@ -86,6 +98,8 @@ func NewControlPlane(ctx context.Context, client client.Client, cluster *cluster
 		kubeadmConfigs:       kubeadmConfigs,
 		infraResources:       infraObjects,
 		reconciliationTime:   metav1.Now(),
+		filesContentHash:     filesContentHash,
+		filesContentHashErr:  filesContentHashErr,
 	}, nil
 }
 
@@ -176,14 +190,14 @@ func (c *ControlPlane) NextFailureDomainForScaleUp() *string {
 
 // InitialControlPlaneConfig returns a new KubeadmConfigSpec that is to be used for an initializing control plane.
 func (c *ControlPlane) InitialControlPlaneConfig() *bootstrapv1.KubeadmConfigSpec {
-	bootstrapSpec := c.KCP.Spec.KubeadmConfigSpec.DeepCopy()
+	bootstrapSpec := DesiredKubeadmConfigSpec(c.KCP)
 	bootstrapSpec.JoinConfiguration = nil
 	return bootstrapSpec
 }
 
 // JoinControlPlaneConfig returns a new KubeadmConfigSpec that is to be used for joining control planes.
 func (c *ControlPlane) JoinControlPlaneConfig() *bootstrapv1.KubeadmConfigSpec {
-	bootstrapSpec := c.KCP.Spec.KubeadmConfigSpec.DeepCopy()
+	bootstrapSpec := DesiredKubeadmConfigSpec(c.KCP)
 	bootstrapSpec.InitConfiguration = nil
 	// NOTE: For the joining we are preserving the ClusterConfiguration in order to determine if the
 	// cluster is using an external etcd in the kubeadm bootstrap provider (even if this is not required by kubeadm Join).
@@ -253,6 +267,27 @@ func (c *ControlPlane) HasDeletingMachine() bool {
 	return len(c.Machines.Filter(collections.HasDeletionTimestamp)) > 0
 }
 
+// FilesContentHashError returns the error preventing the computation of the hash of the content of the files
+// referenced by the KCP KubeadmConfigSpec.Files, if any; it wraps ErrFilesContentNotFound if a referenced Secret,
+// ConfigMap or key does not exist.
+func (c *ControlPlane) FilesContentHashError() error {
+	return c.filesContentHashErr
+}
+
+// SetEtcdLearners sets the names of the etcd members which are learners, as detected while checking the etcd members health.
+func (c *ControlPlane) SetEtcdLearners(learners []string) {
+	if learners == nil {
+		learners = []string{}
+	}
+	c.etcdLearners = learners
+}
+
+// EtcdLearners returns the names of the etcd members which are learners, and false if the etcd members have not
+// been inspected, e.g. because etcd is not reachable.
+func (c *ControlPlane) EtcdLearners() ([]string, bool) {
+	return c.etcdLearners, c.etcdLearners != nil
+}
+
 // MachinesNeedingRollout return a list of machines that need to be rolled out.
 func (c *ControlPlane) MachinesNeedingRollout() collections.Machines {
 	// Ignore machines to be deleted.
@@ -264,13 +299,23 @@ func (c *ControlPlane) MachinesNeedingRollout() collections.Machines {
 		collections.ShouldRolloutAfter(&c.reconciliationTime, c.KCP.Spec.RolloutAfter),
 		// Machines that do not match with KCP config.
 		collections.Not(MatchesMachineSpec(c.infraResources, c.kubeadmConfigs, c.KCP)),
+		// Machines created with a different content of the files referenced by KCP.
+		// NOTE: If the content of the files is unknown, no machine is rolled out because of it, given that
+		// new machines could not be bootstrapped anyway.
+		collections.Not(MatchesFilesContentHash(c.filesContentHash)),
 	)
 }
 
 // UpToDateMachines returns the machines that are up to date with the control
 // plane's configuration and therefore do not require rollout.
+// NOTE: If the content of the files referenced by KCP is unknown, machines created with a known content of the files
+// are not considered up to date, given that it is not possible to tell if the content has changed.
 func (c *ControlPlane) UpToDateMachines() collections.Machines {
-	return c.Machines.Difference(c.MachinesNeedingRollout())
+	upToDate := c.Machines.Difference(c.MachinesNeedingRollout())
+	if c.filesContentHashErr != nil {
+		return upToDate.Filter(collections.Not(HasFilesContentHash))
+	}
+	return upToDate
 }
 
 // getInfraResources fetches the external infrastructure resource for each machine in the collection and returns a map of machine.Name -> infraResource.
@@ -314,6 +359,20 @@ func (c *ControlPlane) IsEtcdManaged() bool {
 	return c.KCP.Spec.KubeadmConfigSpec.ClusterConfiguration == nil || c.KCP.Spec.KubeadmConfigSpec.ClusterConfiguration.Etcd.External == nil
 }
 
+// ControlPlaneComponentsConditions returns the machine conditions reporting the health of the control plane components
+// hosted as static pods on each control plane machine; the etcd condition is included only if etcd is managed by KCP.
+func (c *ControlPlane) ControlPlaneComponentsConditions() []clusterv1.ConditionType {
+	conditionTypes := []clusterv1.ConditionType{
+		controlplanev1.MachineAPIServerPodHealthyCondition,
+		controlplanev1.MachineControllerManagerPodHealthyCondition,
+		controlplanev1.MachineSchedulerPodHealthyCondition,
+	}
+	if c.IsEtcdManaged() {
+		conditionTypes = append(conditionTypes, controlplanev1.MachineEtcdPodHealthyCondition)
+	}
+	return conditionTypes
+}
+
 // UnhealthyMachines returns the list of control plane machines marked as unhealthy by MHC.
 func (c *ControlPlane) UnhealthyMachines() collections.Machines {
 	return c.Machines.Filter(collections.HasUnhealthyCondition)
@@ -22,6 +22,7 @@ import (
 	"sigs.k8s.io/cluster-api/util/collections"
 
 	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -31,6 +32,7 @@ import (
 	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
 	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1alpha4"
 	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 func TestControlPlane(t *testing.T) {
@@ -95,6 +97,42 @@ func TestControlPlane(t *testing.T) {
 	})
 }
 
+func TestNewControlPlaneFilesContentHash(t *testing.T) {
+	kcp := &controlplanev1.KubeadmControlPlane{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "kcp"},
+		Spec: controlplanev1.KubeadmControlPlaneSpec{
+			KubeadmConfigSpec: bootstrapv1.KubeadmConfigSpec{
+				Files: []bootstrapv1.File{
+					{
+						Path:        "/etc/kubernetes/audit-policy.yaml",
+						ContentFrom: &bootstrapv1.FileSource{ConfigMap: &bootstrapv1.ConfigMapFileSource{Name: "audit", Key: "policy"}},
+					},
+				},
+			},
+		},
+	}
+
+	t.Run("computes the hash of the files content", func(t *testing.T) {
+		g := NewWithT(t)
+		c := fake.NewClientBuilder().WithObjects(&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "audit"},
+			Data:       map[string]string{"policy": "foo"},
+		}).Build()
+		controlPlane, err := NewControlPlane(ctx, c, &clusterv1.Cluster{}, kcp, collections.New())
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(controlPlane.FilesContentHashError()).NotTo(HaveOccurred())
+		g.Expect(controlPlane.filesContentHash).NotTo(BeEmpty())
+	})
+
+	t.Run("does not fail if the content of the files is not found", func(t *testing.T) {
+		g := NewWithT(t)
+		c := fake.NewClientBuilder().Build()
+		controlPlane, err := NewControlPlane(ctx, c, &clusterv1.Cluster{}, kcp, collections.New())
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(errors.Is(controlPlane.FilesContentHashError(), ErrFilesContentNotFound)).To(BeTrue())
+	})
+}
+
 func TestHasUnhealthyMachine(t *testing.T) {
 	// healthy machine (without MachineHealthCheckSucceded condition)
 	healthyMachine1 := &clusterv1.Machine{}
@@ -24,6 +24,7 @@ import (
 
 	"github.com/pkg/errors"
 	"go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
 	clientv3 "go.etcd.io/etcd/client/v3"
 	"google.golang.org/grpc"
 	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal/proxy"
@@ -32,6 +33,9 @@ import (
 // etcdTimeout is the maximum time any individual call to the etcd client through the backoff adapter will take.
 const etcdTimeout = 2 * time.Second
 
+// ErrLearnerNotReady is returned when promoting a learner member which is not yet in sync with the leader.
+var ErrLearnerNotReady = errors.New("etcd learner is not yet in sync with the leader")
+
 // GRPCDial is a function that creates a connection to a given endpoint.
 type GRPCDial func(ctx context.Context, addr string) (net.Conn, error)
 
@@ -42,6 +46,7 @@ type etcd interface {
 	Close() error
 	Endpoints() []string
 	MemberList(ctx context.Context) (*clientv3.MemberListResponse, error)
+	MemberPromote(ctx context.Context, id uint64) (*clientv3.MemberPromoteResponse, error)
 	MemberRemove(ctx context.Context, id uint64) (*clientv3.MemberRemoveResponse, error)
 	MemberUpdate(ctx context.Context, id uint64, peerURLs []string) (*clientv3.MemberUpdateResponse, error)
 	MoveLeader(ctx context.Context, id uint64) (*clientv3.MoveLeaderResponse, error)
@@ -53,6 +58,7 @@ type Client struct {
 	EtcdClient etcd
 	Endpoint   string
 	LeaderID   uint64
+	Version    string
 	Errors     []string
 }
 
@@ -163,6 +169,7 @@ func newEtcdClient(ctx context.Context, etcdClient etcd) (*Client, error) {
 		Endpoint:   endpoints[0],
 		EtcdClient: etcdClient,
 		LeaderID:   status.Leader,
+		Version:    status.Version,
 		Errors:     status.Errors,
 	}, nil
 }
@@ -212,6 +219,18 @@ func (c *Client) RemoveMember(ctx context.Context, id uint64) error {
 	return errors.Wrapf(err, "failed to remove member: %v", id)
 }
 
+// PromoteMember promotes a learner member to a voting member.
+// If the learner is not yet in sync with the leader, ErrLearnerNotReady is returned.
+func (c *Client) PromoteMember(ctx context.Context, id uint64) error {
+	if _, err := c.EtcdClient.MemberPromote(ctx, id); err != nil {
+		if errors.Is(err, rpctypes.ErrLearnerNotReady) {
+			return ErrLearnerNotReady
+		}
+		return errors.Wrapf(err, "failed to promote member: %v", id)
+	}
+	return nil
+}
+
 // UpdateMemberPeerURLs updates the list of peer URLs.
 func (c *Client) UpdateMemberPeerURLs(ctx context.Context, id uint64, peerURLs []string) ([]*Member, error) {
 	response, err := c.EtcdClient.MemberUpdate(ctx, id, peerURLs)
@@ -23,6 +23,7 @@ import (
 
 	"github.com/pkg/errors"
 	"go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
 	clientv3 "go.etcd.io/etcd/client/v3"
 	etcdfake "sigs.k8s.io/cluster-api/controlplane/kubeadm/internal/etcd/fake"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -61,6 +62,9 @@ func TestEtcdMembers_WithErrors(t *testing.T) {
 
 	err = client.RemoveMember(ctx, 1234)
 	g.Expect(err).To(HaveOccurred())
+
+	err = client.PromoteMember(ctx, 1234)
+	g.Expect(err).To(HaveOccurred())
 }
 
 func TestEtcdMembers_WithSuccess(t *testing.T) {
@@ -83,11 +87,12 @@ func TestEtcdMembers_WithSuccess(t *testing.T) {
 		},
 		MemberRemoveResponse: &clientv3.MemberRemoveResponse{},
 		AlarmResponse:        &clientv3.AlarmResponse{},
-		StatusResponse:       &clientv3.StatusResponse{},
+		StatusResponse:       &clientv3.StatusResponse{Version: "3.4.13"},
 	}
 
 	client, err := newEtcdClient(ctx, fakeEtcdClient)
 	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(client.Version).To(Equal("3.4.13"))
 
 	members, err := client.Members(ctx)
 	g.Expect(err).NotTo(HaveOccurred())
@@ -99,8 +104,29 @@ func TestEtcdMembers_WithSuccess(t *testing.T) {
 	err = client.RemoveMember(ctx, 1234)
 	g.Expect(err).NotTo(HaveOccurred())
 
+	err = client.PromoteMember(ctx, 1234)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(fakeEtcdClient.PromotedMembers).To(Equal([]uint64{1234}))
+
 	updatedMembers, err := client.UpdateMemberPeerURLs(ctx, 1234, []string{"https://4.5.6.7:2000"})
 	g.Expect(err).NotTo(HaveOccurred())
 	g.Expect(len(updatedMembers[0].PeerURLs)).To(Equal(2))
 	g.Expect(updatedMembers[0].PeerURLs).To(Equal([]string{"https://1.2.3.4:2000", "https://4.5.6.7:2000"}))
 }
+
+func TestEtcdMembers_PromoteLearnerNotReady(t *testing.T) {
+	g := NewWithT(t)
+
+	fakeEtcdClient := &etcdfake.FakeEtcdClient{
+		EtcdEndpoints:    []string{"https://etcd-instance:2379"},
+		StatusResponse:   &clientv3.StatusResponse{},
+		MemberPromoteErr: rpctypes.ErrLearnerNotReady,
+	}
+
+	client, err := newEtcdClient(ctx, fakeEtcdClient)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	err = client.PromoteMember(ctx, 1234)
+	g.Expect(err).To(Equal(ErrLearnerNotReady))
+	g.Expect(fakeEtcdClient.PromotedMembers).To(BeEmpty())
+}
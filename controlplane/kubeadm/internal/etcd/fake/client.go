@@ -24,16 +24,19 @@ import (
 )
 
 type FakeEtcdClient struct { //nolint:revive
-	AlarmResponse        *clientv3.AlarmResponse
-	EtcdEndpoints        []string
-	MemberListResponse   *clientv3.MemberListResponse
-	MemberRemoveResponse *clientv3.MemberRemoveResponse
-	MemberUpdateResponse *clientv3.MemberUpdateResponse
-	MoveLeaderResponse   *clientv3.MoveLeaderResponse
-	StatusResponse       *clientv3.StatusResponse
-	ErrorResponse        error
-	MovedLeader          uint64
-	RemovedMember        uint64
+	AlarmResponse         *clientv3.AlarmResponse
+	EtcdEndpoints         []string
+	MemberListResponse    *clientv3.MemberListResponse
+	MemberPromoteResponse *clientv3.MemberPromoteResponse
+	MemberPromoteErr      error
+	MemberRemoveResponse  *clientv3.MemberRemoveResponse
+	MemberUpdateResponse  *clientv3.MemberUpdateResponse
+	MoveLeaderResponse    *clientv3.MoveLeaderResponse
+	StatusResponse        *clientv3.StatusResponse
+	ErrorResponse         error
+	MovedLeader           uint64
+	RemovedMember         uint64
+	PromotedMembers       []uint64
 }
 
 func (c *FakeEtcdClient) Endpoints() []string {
@@ -56,6 +59,13 @@ func (c *FakeEtcdClient) AlarmList(_ context.Context) (*clientv3.AlarmResponse,
 func (c *FakeEtcdClient) MemberList(_ context.Context) (*clientv3.MemberListResponse, error) {
 	return c.MemberListResponse, c.ErrorResponse
 }
+func (c *FakeEtcdClient) MemberPromote(_ context.Context, i uint64) (*clientv3.MemberPromoteResponse, error) {
+	if c.MemberPromoteErr != nil {
+		return nil, c.MemberPromoteErr
+	}
+	c.PromotedMembers = append(c.PromotedMembers, i)
+	return c.MemberPromoteResponse, c.ErrorResponse
+}
 func (c *FakeEtcdClient) MemberRemove(_ context.Context, i uint64) (*clientv3.MemberRemoveResponse, error) {
 	c.RemovedMember = i
 	return c.MemberRemoveResponse, c.ErrorResponse
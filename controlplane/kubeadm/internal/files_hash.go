@@ -0,0 +1,167 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1alpha4"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ErrFilesContentNotFound is returned by FilesContentHash when a Secret or a ConfigMap referenced by the files
+// in the KCP KubeadmConfigSpec, or one of their keys, does not exist.
+var ErrFilesContentNotFound = errors.New("content of the files not found")
+
+// FilesContentHash returns a hash of the content of the Secrets and ConfigMaps referenced by the files in the KCP
+// KubeadmConfigSpec, or an empty string if no file gets its content from a Secret or a ConfigMap.
+// NOTE: An error wrapping ErrFilesContentNotFound is returned if a referenced Secret, ConfigMap or key does not exist,
+// given that the content of the files is unknown.
+func FilesContentHash(ctx context.Context, c client.Client, kcp *controlplanev1.KubeadmControlPlane) (string, error) {
+	files := kcp.Spec.KubeadmConfigSpec.Files
+	paths := []string{}
+	content := map[string][]byte{}
+	for i := range files {
+		file := files[i]
+		if file.ContentFrom == nil {
+			continue
+		}
+
+		data, err := fileSourceContent(ctx, c, kcp.Namespace, file.ContentFrom)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to get the content of file %q", file.Path)
+		}
+		paths = append(paths, file.Path)
+		content[file.Path] = data
+	}
+	if len(paths) == 0 {
+		return "", nil
+	}
+
+	// Sort by path, so the hash does not depend on the order of the files.
+	sort.Strings(paths)
+	hash := sha256.New()
+	for _, path := range paths {
+		for _, data := range [][]byte{[]byte(path), {0}, content[path], {0}} {
+			if _, err := hash.Write(data); err != nil {
+				return "", errors.Wrap(err, "failed to compute the hash of the files content")
+			}
+		}
+	}
+	return fmt.Sprintf("sha256:%x", hash.Sum(nil)), nil
+}
+
+// fileSourceContent returns the content of the key in the Secret or in the ConfigMap referenced by a file source.
+func fileSourceContent(ctx context.Context, c client.Client, namespace string, source *bootstrapv1.FileSource) ([]byte, error) {
+	if source.ConfigMap != nil {
+		configMap := &corev1.ConfigMap{}
+		key := client.ObjectKey{Namespace: namespace, Name: source.ConfigMap.Name}
+		if err := c.Get(ctx, key, configMap); err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil, errors.Wrapf(ErrFilesContentNotFound, "ConfigMap %s does not exist", key)
+			}
+			return nil, errors.Wrapf(err, "failed to get ConfigMap %s", key)
+		}
+		if data, ok := configMap.Data[source.ConfigMap.Key]; ok {
+			return []byte(data), nil
+		}
+		return nil, errors.Wrapf(ErrFilesContentNotFound, "key %s does not exist in ConfigMap %s", source.ConfigMap.Key, key)
+	}
+
+	secret := &corev1.Secret{}
+	key := client.ObjectKey{Namespace: namespace, Name: source.Secret.Name}
+	if err := c.Get(ctx, key, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, errors.Wrapf(ErrFilesContentNotFound, "Secret %s does not exist", key)
+		}
+		return nil, errors.Wrapf(err, "failed to get Secret %s", key)
+	}
+	data, ok := secret.Data[source.Secret.Key]
+	if !ok {
+		return nil, errors.Wrapf(ErrFilesContentNotFound, "key %s does not exist in Secret %s", source.Secret.Key, key)
+	}
+	return data, nil
+}
+
+// kubeadmAPIServerHostPaths are the host paths mounted by kubeadm in the API server static pod by default.
+var kubeadmAPIServerHostPaths = []string{
+	"/etc/kubernetes/pki",
+	"/etc/ssl/certs",
+	"/etc/pki",
+	"/etc/ca-certificates",
+	"/usr/share/ca-certificates",
+	"/usr/local/share/ca-certificates",
+}
+
+// apiServerFileVolumes returns the read-only API server extra volumes mounting the files with content from a Secret
+// or a ConfigMap which are passed to the API server via extra args, e.g. the audit policy or the encryption configuration,
+// so users are not required to configure the extra volumes on their own.
+// NOTE: No volume is returned for files which are already mounted in the API server static pod, either by the
+// extra volumes defined in the KubeadmConfigSpec or by kubeadm by default.
+func apiServerFileVolumes(spec *bootstrapv1.KubeadmConfigSpec) []bootstrapv1.HostPathMount {
+	if spec.ClusterConfiguration == nil {
+		return nil
+	}
+	apiServer := spec.ClusterConfiguration.APIServer
+
+	args := map[string]bool{}
+	for _, v := range apiServer.ExtraArgs {
+		args[v] = true
+	}
+	mounted := append([]string{}, kubeadmAPIServerHostPaths...)
+	for _, volume := range apiServer.ExtraVolumes {
+		mounted = append(mounted, volume.HostPath)
+	}
+
+	volumes := []bootstrapv1.HostPathMount{}
+	for _, file := range spec.Files {
+		if file.ContentFrom == nil || !args[file.Path] || isPathMounted(file.Path, mounted) {
+			continue
+		}
+		pathHash := sha256.Sum256([]byte(file.Path))
+		volumes = append(volumes, bootstrapv1.HostPathMount{
+			Name:      fmt.Sprintf("kcp-file-%x", pathHash[:5]),
+			HostPath:  file.Path,
+			MountPath: file.Path,
+			ReadOnly:  true,
+			PathType:  corev1.HostPathFile,
+		})
+		mounted = append(mounted, file.Path)
+	}
+	return volumes
+}
+
+// isPathMounted returns true if the path is equal to or is under one of the mounted paths.
+func isPathMounted(path string, mounted []string) bool {
+	path = filepath.Clean(path)
+	for _, m := range mounted {
+		m = filepath.Clean(m)
+		if path == m || strings.HasPrefix(path, strings.TrimSuffix(m, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,183 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1alpha4"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestFilesContentHash(t *testing.T) {
+	auditPolicy := bootstrapv1.File{
+		Path:        "/etc/kubernetes/audit-policy.yaml",
+		ContentFrom: &bootstrapv1.FileSource{Secret: bootstrapv1.SecretFileSource{Name: "audit", Key: "policy"}},
+	}
+	encryptionConfig := bootstrapv1.File{
+		Path:        "/etc/kubernetes/encryption-config.yaml",
+		ContentFrom: &bootstrapv1.FileSource{Secret: bootstrapv1.SecretFileSource{Name: "encryption", Key: "config"}},
+	}
+	admissionConfig := bootstrapv1.File{
+		Path:        "/etc/kubernetes/admission-config.yaml",
+		ContentFrom: &bootstrapv1.FileSource{ConfigMap: &bootstrapv1.ConfigMapFileSource{Name: "admission", Key: "config"}},
+	}
+	kcpWithFiles := func(files ...bootstrapv1.File) *controlplanev1.KubeadmControlPlane {
+		return &controlplanev1.KubeadmControlPlane{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "kcp"},
+			Spec: controlplanev1.KubeadmControlPlaneSpec{
+				KubeadmConfigSpec: bootstrapv1.KubeadmConfigSpec{Files: files},
+			},
+		}
+	}
+	secret := func(name, key, value string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Data:       map[string][]byte{key: []byte(value)},
+		}
+	}
+	configMap := func(name, key, value string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Data:       map[string]string{key: value},
+		}
+	}
+
+	t.Run("returns an empty hash if no file references a Secret or a ConfigMap", func(t *testing.T) {
+		g := NewWithT(t)
+		c := fake.NewClientBuilder().Build()
+		hash, err := FilesContentHash(ctx, c, kcpWithFiles(bootstrapv1.File{Path: "/etc/foo", Content: "foo"}))
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(hash).To(BeEmpty())
+	})
+
+	t.Run("returns ErrFilesContentNotFound if a referenced Secret does not exist", func(t *testing.T) {
+		g := NewWithT(t)
+		c := fake.NewClientBuilder().WithObjects(secret("encryption", "config", "bar")).Build()
+		_, err := FilesContentHash(ctx, c, kcpWithFiles(auditPolicy, encryptionConfig))
+		g.Expect(errors.Is(err, ErrFilesContentNotFound)).To(BeTrue())
+	})
+
+	t.Run("returns ErrFilesContentNotFound if a referenced Secret key does not exist", func(t *testing.T) {
+		g := NewWithT(t)
+		c := fake.NewClientBuilder().WithObjects(secret("audit", "other", "foo")).Build()
+		_, err := FilesContentHash(ctx, c, kcpWithFiles(auditPolicy))
+		g.Expect(errors.Is(err, ErrFilesContentNotFound)).To(BeTrue())
+	})
+
+	t.Run("returns ErrFilesContentNotFound if a referenced ConfigMap or ConfigMap key does not exist", func(t *testing.T) {
+		g := NewWithT(t)
+		c := fake.NewClientBuilder().Build()
+		_, err := FilesContentHash(ctx, c, kcpWithFiles(admissionConfig))
+		g.Expect(errors.Is(err, ErrFilesContentNotFound)).To(BeTrue())
+
+		c = fake.NewClientBuilder().WithObjects(configMap("admission", "other", "baz")).Build()
+		_, err = FilesContentHash(ctx, c, kcpWithFiles(admissionConfig))
+		g.Expect(errors.Is(err, ErrFilesContentNotFound)).To(BeTrue())
+	})
+
+	t.Run("the hash changes when the content of a referenced ConfigMap changes", func(t *testing.T) {
+		g := NewWithT(t)
+		c1 := fake.NewClientBuilder().WithObjects(configMap("admission", "config", "foo")).Build()
+		hash1, err := FilesContentHash(ctx, c1, kcpWithFiles(admissionConfig))
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(hash1).NotTo(BeEmpty())
+		c2 := fake.NewClientBuilder().WithObjects(configMap("admission", "config", "bar")).Build()
+		hash2, err := FilesContentHash(ctx, c2, kcpWithFiles(admissionConfig))
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(hash1).NotTo(Equal(hash2))
+	})
+
+	t.Run("the hash does not depend on the order of the files", func(t *testing.T) {
+		g := NewWithT(t)
+		c := fake.NewClientBuilder().WithObjects(secret("audit", "policy", "foo"), secret("encryption", "config", "bar")).Build()
+		hash1, err := FilesContentHash(ctx, c, kcpWithFiles(auditPolicy, encryptionConfig))
+		g.Expect(err).NotTo(HaveOccurred())
+		hash2, err := FilesContentHash(ctx, c, kcpWithFiles(encryptionConfig, auditPolicy))
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(hash1).NotTo(BeEmpty())
+		g.Expect(hash1).To(Equal(hash2))
+	})
+
+	t.Run("the hash changes when the content of a referenced Secret changes", func(t *testing.T) {
+		g := NewWithT(t)
+		c1 := fake.NewClientBuilder().WithObjects(secret("audit", "policy", "foo")).Build()
+		hash1, err := FilesContentHash(ctx, c1, kcpWithFiles(auditPolicy))
+		g.Expect(err).NotTo(HaveOccurred())
+		c2 := fake.NewClientBuilder().WithObjects(secret("audit", "policy", "bar")).Build()
+		hash2, err := FilesContentHash(ctx, c2, kcpWithFiles(auditPolicy))
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(hash1).NotTo(Equal(hash2))
+	})
+}
+
+func TestAPIServerFileVolumes(t *testing.T) {
+	auditPolicy := bootstrapv1.File{
+		Path:        "/etc/kubernetes/audit/policy.yaml",
+		ContentFrom: &bootstrapv1.FileSource{ConfigMap: &bootstrapv1.ConfigMapFileSource{Name: "audit", Key: "policy"}},
+	}
+	specWith := func(files []bootstrapv1.File, args map[string]string, volumes ...bootstrapv1.HostPathMount) *bootstrapv1.KubeadmConfigSpec {
+		return &bootstrapv1.KubeadmConfigSpec{
+			Files: files,
+			ClusterConfiguration: &bootstrapv1.ClusterConfiguration{
+				APIServer: bootstrapv1.APIServer{
+					ControlPlaneComponent: bootstrapv1.ControlPlaneComponent{
+						ExtraArgs:    args,
+						ExtraVolumes: volumes,
+					},
+				},
+			},
+		}
+	}
+
+	t.Run("mounts the files with content from a Secret or a ConfigMap passed to the API server", func(t *testing.T) {
+		g := NewWithT(t)
+		volumes := apiServerFileVolumes(specWith([]bootstrapv1.File{auditPolicy}, map[string]string{"audit-policy-file": auditPolicy.Path}))
+		g.Expect(volumes).To(HaveLen(1))
+		g.Expect(volumes[0].Name).To(HavePrefix("kcp-file-"))
+		g.Expect(volumes[0].HostPath).To(Equal(auditPolicy.Path))
+		g.Expect(volumes[0].MountPath).To(Equal(auditPolicy.Path))
+		g.Expect(volumes[0].ReadOnly).To(BeTrue())
+		g.Expect(volumes[0].PathType).To(Equal(corev1.HostPathFile))
+	})
+
+	t.Run("does not mount files not passed to the API server or with inline content", func(t *testing.T) {
+		g := NewWithT(t)
+		inline := bootstrapv1.File{Path: "/etc/kubernetes/inline.yaml", Content: "foo"}
+		volumes := apiServerFileVolumes(specWith([]bootstrapv1.File{auditPolicy, inline}, map[string]string{"foo": inline.Path}))
+		g.Expect(volumes).To(BeEmpty())
+	})
+
+	t.Run("does not mount files already mounted by extra volumes or by kubeadm", func(t *testing.T) {
+		g := NewWithT(t)
+		volumes := apiServerFileVolumes(specWith([]bootstrapv1.File{auditPolicy}, map[string]string{"audit-policy-file": auditPolicy.Path},
+			bootstrapv1.HostPathMount{Name: "audit", HostPath: "/etc/kubernetes/audit", MountPath: "/etc/kubernetes/audit"}))
+		g.Expect(volumes).To(BeEmpty())
+
+		pkiFile := bootstrapv1.File{
+			Path:        "/etc/kubernetes/pki/oidc-ca.crt",
+			ContentFrom: &bootstrapv1.FileSource{Secret: bootstrapv1.SecretFileSource{Name: "oidc", Key: "ca.crt"}},
+		}
+		volumes = apiServerFileVolumes(specWith([]bootstrapv1.File{pkiFile}, map[string]string{"oidc-ca-file": pkiFile.Path}))
+		g.Expect(volumes).To(BeEmpty())
+	})
+}
@@ -25,6 +25,7 @@ import (
 	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
 	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1alpha4"
 	"sigs.k8s.io/cluster-api/util/collections"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -41,6 +42,22 @@ func MatchesMachineSpec(infraConfigs map[string]*unstructured.Unstructured, mach
 	)
 }
 
+// HasHealthyControlPlaneComponents returns a filter to find all machines reporting all the given control plane
+// component conditions as true, e.g. all the static pods generated by kubeadm running and ready.
+func HasHealthyControlPlaneComponents(conditionTypes ...clusterv1.ConditionType) collections.Func {
+	return func(machine *clusterv1.Machine) bool {
+		if machine == nil {
+			return false
+		}
+		for _, conditionType := range conditionTypes {
+			if !conditions.IsTrue(machine, conditionType) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
 // MatchesTemplateClonedFrom returns a filter to find all machines that match a given KCP infra template.
 func MatchesTemplateClonedFrom(infraConfigs map[string]*unstructured.Unstructured, kcp *controlplanev1.KubeadmControlPlane) collections.Func {
 	return func(machine *clusterv1.Machine) bool {
@@ -76,6 +93,38 @@ func MatchesTemplateClonedFrom(infraConfigs map[string]*unstructured.Unstructure
 	}
 }
 
+// MatchesFilesContentHash returns a filter to find all machines created with the given content of the files
+// referenced by the KCP KubeadmConfigSpec.
+// NOTE: Machines without the KubeadmFilesContentHashAnnotation (machines either old or adopted) are considered as matching,
+// given that we don't have enough information to make a decision; the same applies if hash is empty, given that
+// removing all the files referencing Secrets or ConfigMaps is already detected as a change of the KubeadmConfigSpec,
+// while an unknown content of the files should not trigger a rollout.
+func MatchesFilesContentHash(hash string) collections.Func {
+	return func(machine *clusterv1.Machine) bool {
+		if machine == nil {
+			return false
+		}
+		if hash == "" {
+			return true
+		}
+		machineHash, ok := machine.GetAnnotations()[controlplanev1.KubeadmFilesContentHashAnnotation]
+		if !ok {
+			return true
+		}
+		return machineHash == hash
+	}
+}
+
+// HasFilesContentHash returns true if the machine has been created with a known content of the files
+// referenced by the KCP KubeadmConfigSpec.
+func HasFilesContentHash(machine *clusterv1.Machine) bool {
+	if machine == nil {
+		return false
+	}
+	_, ok := machine.GetAnnotations()[controlplanev1.KubeadmFilesContentHashAnnotation]
+	return ok
+}
+
 // MatchesKubeadmBootstrapConfig checks if machine's KubeadmConfigSpec is equivalent with KCP's KubeadmConfigSpec.
 func MatchesKubeadmBootstrapConfig(machineConfigs map[string]*bootstrapv1.KubeadmConfig, kcp *controlplanev1.KubeadmControlPlane) collections.Func {
 	return func(machine *clusterv1.Machine) bool {
@@ -139,7 +188,7 @@ func matchClusterConfiguration(kcp *controlplanev1.KubeadmControlPlane, machine
 	if machineClusterConfig == nil {
 		machineClusterConfig = &bootstrapv1.ClusterConfiguration{}
 	}
-	kcpLocalClusterConfiguration := kcp.Spec.KubeadmConfigSpec.ClusterConfiguration
+	kcpLocalClusterConfiguration := DesiredKubeadmConfigSpec(kcp).ClusterConfiguration
 	if kcpLocalClusterConfiguration == nil {
 		kcpLocalClusterConfiguration = &bootstrapv1.ClusterConfiguration{}
 	}
@@ -173,7 +222,7 @@ func matchInitOrJoinConfiguration(machineConfig *bootstrapv1.KubeadmConfig, kcp
 // mostly depending on the fact that the machine was the initial control plane node or a joining control plane node.
 // In this function we don't have such information, so we are making the KubeadmConfigSpec similar to the KubeadmConfig.
 func getAdjustedKcpConfig(kcp *controlplanev1.KubeadmControlPlane, machineConfig *bootstrapv1.KubeadmConfig) *bootstrapv1.KubeadmConfigSpec {
-	kcpConfig := kcp.Spec.KubeadmConfigSpec.DeepCopy()
+	kcpConfig := DesiredKubeadmConfigSpec(kcp)
 
 	// Machine's join configuration is nil when it is the first machine in the control plane.
 	if machineConfig.Spec.JoinConfiguration == nil {
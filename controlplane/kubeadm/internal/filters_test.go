@@ -26,6 +26,7 @@ import (
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
 	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/util/conditions"
 )
 
 func TestMatchClusterConfiguration(t *testing.T) {
@@ -103,6 +104,27 @@ func TestMatchClusterConfiguration(t *testing.T) {
 		}
 		g.Expect(matchClusterConfiguration(kcp, m)).To(BeTrue())
 	})
+	t.Run("Return false if the cloud provider migration is started", func(t *testing.T) {
+		g := NewWithT(t)
+		kcp := &controlplanev1.KubeadmControlPlane{
+			Spec: controlplanev1.KubeadmControlPlaneSpec{
+				KubeadmConfigSpec: bootstrapv1.KubeadmConfigSpec{
+					ClusterConfiguration: &bootstrapv1.ClusterConfiguration{
+						ClusterName: "foo",
+					},
+				},
+				CloudProviderMigration: &controlplanev1.CloudProviderMigration{},
+			},
+		}
+		m := &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					controlplanev1.KubeadmClusterConfigurationAnnotation: "{\n  \"clusterName\": \"foo\"\n}",
+				},
+			},
+		}
+		g.Expect(matchClusterConfiguration(kcp, m)).To(BeFalse())
+	})
 }
 
 func TestGetAdjustedKcpConfig(t *testing.T) {
@@ -1130,3 +1152,66 @@ func TestMatchesTemplateClonedFrom_WithClonedFromAnnotations(t *testing.T) {
 		})
 	}
 }
+
+func TestMatchesFilesContentHash(t *testing.T) {
+	machineWithHash := func(hash string) *clusterv1.Machine {
+		m := &clusterv1.Machine{}
+		if hash != "" {
+			m.SetAnnotations(map[string]string{controlplanev1.KubeadmFilesContentHashAnnotation: hash})
+		}
+		return m
+	}
+
+	t.Run("machine without the files content hash annotation should match (not enough information to make a decision)", func(t *testing.T) {
+		g := NewWithT(t)
+		g.Expect(MatchesFilesContentHash("sha256:foo")(machineWithHash(""))).To(BeTrue())
+	})
+	t.Run("machine should match if KCP does not reference any Secret or ConfigMap", func(t *testing.T) {
+		g := NewWithT(t)
+		g.Expect(MatchesFilesContentHash("")(machineWithHash("sha256:foo"))).To(BeTrue())
+	})
+	t.Run("machine with the same files content hash should match", func(t *testing.T) {
+		g := NewWithT(t)
+		g.Expect(MatchesFilesContentHash("sha256:foo")(machineWithHash("sha256:foo"))).To(BeTrue())
+	})
+	t.Run("machine with a different files content hash should not match", func(t *testing.T) {
+		g := NewWithT(t)
+		g.Expect(MatchesFilesContentHash("sha256:foo")(machineWithHash("sha256:bar"))).To(BeFalse())
+	})
+}
+
+func TestHasFilesContentHash(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(HasFilesContentHash(nil)).To(BeFalse())
+	g.Expect(HasFilesContentHash(&clusterv1.Machine{})).To(BeFalse())
+
+	m := &clusterv1.Machine{}
+	m.SetAnnotations(map[string]string{controlplanev1.KubeadmFilesContentHashAnnotation: "sha256:foo"})
+	g.Expect(HasFilesContentHash(m)).To(BeTrue())
+}
+
+func TestHasHealthyControlPlaneComponents(t *testing.T) {
+	conditionTypes := []clusterv1.ConditionType{
+		controlplanev1.MachineAPIServerPodHealthyCondition,
+		controlplanev1.MachineEtcdPodHealthyCondition,
+	}
+
+	t.Run("machine without control plane component conditions should not match", func(t *testing.T) {
+		g := NewWithT(t)
+		g.Expect(HasHealthyControlPlaneComponents(conditionTypes...)(&clusterv1.Machine{})).To(BeFalse())
+	})
+	t.Run("machine with an unhealthy control plane component should not match", func(t *testing.T) {
+		g := NewWithT(t)
+		m := &clusterv1.Machine{}
+		conditions.MarkTrue(m, controlplanev1.MachineAPIServerPodHealthyCondition)
+		conditions.MarkFalse(m, controlplanev1.MachineEtcdPodHealthyCondition, controlplanev1.PodProvisioningReason, clusterv1.ConditionSeverityInfo, "")
+		g.Expect(HasHealthyControlPlaneComponents(conditionTypes...)(m)).To(BeFalse())
+	})
+	t.Run("machine with all the control plane components healthy should match", func(t *testing.T) {
+		g := NewWithT(t)
+		m := &clusterv1.Machine{}
+		conditions.MarkTrue(m, controlplanev1.MachineAPIServerPodHealthyCondition)
+		conditions.MarkTrue(m, controlplanev1.MachineEtcdPodHealthyCondition)
+		g.Expect(HasHealthyControlPlaneComponents(conditionTypes...)(m)).To(BeTrue())
+	})
+}
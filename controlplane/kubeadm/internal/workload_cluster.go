@@ -104,9 +104,16 @@ type WorkloadCluster interface {
 	RemoveNodeFromKubeadmConfigMap(ctx context.Context, nodeName string, version semver.Version) error
 	ForwardEtcdLeadership(ctx context.Context, machine *clusterv1.Machine, leaderCandidate *clusterv1.Machine) error
 	AllowBootstrapTokensToGetNodes(ctx context.Context) error
+	AllowKubeletServingCertificateApproval(ctx context.Context) error
+	EnsureKubeletServingCertApprover(ctx context.Context, image string) error
+	IsExternalCloudControllerManagerRunning(ctx context.Context) (bool, error)
 
 	// State recovery tasks.
 	ReconcileEtcdMembers(ctx context.Context, nodeNames []string, version semver.Version) ([]string, error)
+
+	// Etcd learner mode tasks.
+	ReconcileEtcdLearnerMode(ctx context.Context, version semver.Version) (bool, error)
+	PromoteEtcdLearners(ctx context.Context) ([]string, error)
 }
 
 // Workload defines operations on workload clusters.
@@ -380,7 +387,10 @@ func (w *Workload) ClusterStatus(ctx context.Context) (ClusterStatus, error) {
 		Name:      kubeadmConfigKey,
 		Namespace: metav1.NamespaceSystem,
 	}
-	err = w.Client.Get(ctx, key, &corev1.ConfigMap{})
+	// NOTE: only the existence of the kubeadm config is checked, so its metadata is enough.
+	kubeadmConfig := &metav1.PartialObjectMetadata{}
+	kubeadmConfig.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("ConfigMap"))
+	err = w.Client.Get(ctx, key, kubeadmConfig)
 	// TODO: Consider if this should only return false if the error is IsNotFound.
 	// TODO: Consider adding a third state of 'unknown' when there is an error retrieving the config map.
 	status.HasKubeadmConfig = err == nil
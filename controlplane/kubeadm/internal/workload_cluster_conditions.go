@@ -29,6 +29,7 @@ import (
 	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1alpha4"
 	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal/etcd"
 	etcdutil "sigs.k8s.io/cluster-api/controlplane/kubeadm/internal/etcd/util"
+	"sigs.k8s.io/cluster-api/util/annotations"
 	"sigs.k8s.io/cluster-api/util/collections"
 	"sigs.k8s.io/cluster-api/util/conditions"
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
@@ -109,6 +110,11 @@ func (w *Workload) updateManagedEtcdConditions(ctx context.Context, controlPlane
 		}
 		defer etcdClient.Close()
 
+		// Report the version of the etcd member on the machine, so it is possible to track etcd upgrades during rollouts.
+		if etcdClient.Version != "" {
+			annotations.AddAnnotations(machine, map[string]string{controlplanev1.EtcdVersionAnnotation: etcdClient.Version})
+		}
+
 		// While creating a new client, forFirstAvailableNode retrieves the status for the endpoint; check if the endpoint has errors.
 		if len(etcdClient.Errors) > 0 {
 			conditions.MarkFalse(machine, controlplanev1.MachineEtcdMemberHealthyCondition, controlplanev1.EtcdMemberUnhealthyReason, clusterv1.ConditionSeverityError, "Etcd member status reports errors: %s", strings.Join(etcdClient.Errors, ", "))
@@ -166,6 +172,17 @@ func (w *Workload) updateManagedEtcdConditions(ctx context.Context, controlPlane
 		conditions.MarkTrue(machine, controlplanev1.MachineEtcdMemberHealthyCondition)
 	}
 
+	// Report the etcd members which are learners, so they are promoted without contacting etcd again.
+	if members != nil {
+		learners := []string{}
+		for _, member := range members {
+			if member.IsLearner {
+				learners = append(learners, member.Name)
+			}
+		}
+		controlPlane.SetEtcdLearners(learners)
+	}
+
 	// Make sure that the list of etcd members and machines is consistent.
 	kcpErrors = compareMachinesAndMembers(controlPlane, members, kcpErrors)
 
@@ -229,14 +246,7 @@ func compareMachinesAndMembers(controlPlane *ControlPlane, members []*etcd.Membe
 // components running in a static pod generated by kubeadm. This operation is best effort, in the sense that in case
 // of problems in retrieving the pod status, it sets the condition to Unknown state without returning any error.
 func (w *Workload) UpdateStaticPodConditions(ctx context.Context, controlPlane *ControlPlane) {
-	allMachinePodConditions := []clusterv1.ConditionType{
-		controlplanev1.MachineAPIServerPodHealthyCondition,
-		controlplanev1.MachineControllerManagerPodHealthyCondition,
-		controlplanev1.MachineSchedulerPodHealthyCondition,
-	}
-	if controlPlane.IsEtcdManaged() {
-		allMachinePodConditions = append(allMachinePodConditions, controlplanev1.MachineEtcdPodHealthyCondition)
-	}
+	allMachinePodConditions := controlPlane.ControlPlaneComponentsConditions()
 
 	// NOTE: this fun uses control plane nodes from the workload cluster as a source of truth for the current state.
 	controlPlaneNodes, err := w.getControlPlaneNodes(ctx)
@@ -47,6 +47,8 @@ func TestUpdateEtcdConditions(t *testing.T) {
 		injectEtcdClientGenerator etcdClientFor // This test is injecting a fake etcdClientGenerator because it is required to nodes with a controlled Status or to fail with a specific error.
 		expectedKCPCondition      *clusterv1.Condition
 		expectedMachineConditions map[string]clusterv1.Conditions
+		expectedEtcdVersions      map[string]string
+		expectedEtcdLearners      []string
 	}{
 		{
 			name: "if list nodes return an error should report all the conditions Unknown",
@@ -397,6 +399,7 @@ func TestUpdateEtcdConditions(t *testing.T) {
 					switch n[0] {
 					case "n1":
 						return &etcd.Client{
+							Version: "3.4.13",
 							EtcdClient: &fake2.FakeEtcdClient{
 								EtcdEndpoints: []string{},
 								MemberListResponse: &clientv3.MemberListResponse{
@@ -415,6 +418,7 @@ func TestUpdateEtcdConditions(t *testing.T) {
 						}, nil
 					case "n2":
 						return &etcd.Client{
+							Version: "3.5.0",
 							EtcdClient: &fake2.FakeEtcdClient{
 								EtcdEndpoints: []string{},
 								MemberListResponse: &clientv3.MemberListResponse{
@@ -437,6 +441,7 @@ func TestUpdateEtcdConditions(t *testing.T) {
 				},
 			},
 			expectedKCPCondition: conditions.TrueCondition(controlplanev1.EtcdClusterHealthyCondition),
+			expectedEtcdLearners: []string{},
 			expectedMachineConditions: map[string]clusterv1.Conditions{
 				"m1": {
 					*conditions.TrueCondition(controlplanev1.MachineEtcdMemberHealthyCondition),
@@ -445,6 +450,10 @@ func TestUpdateEtcdConditions(t *testing.T) {
 					*conditions.TrueCondition(controlplanev1.MachineEtcdMemberHealthyCondition),
 				},
 			},
+			expectedEtcdVersions: map[string]string{
+				"m1": "3.4.13",
+				"m2": "3.5.0",
+			},
 		},
 		{
 			name: "Eternal etcd should set a condition at KCP level",
@@ -482,9 +491,17 @@ func TestUpdateEtcdConditions(t *testing.T) {
 			if tt.expectedKCPCondition != nil {
 				g.Expect(*conditions.Get(tt.kcp, controlplanev1.EtcdClusterHealthyCondition)).To(conditions.MatchCondition(*tt.expectedKCPCondition))
 			}
+			if tt.expectedEtcdLearners != nil {
+				learners, ok := controlPane.EtcdLearners()
+				g.Expect(ok).To(BeTrue())
+				g.Expect(learners).To(Equal(tt.expectedEtcdLearners))
+			}
 			for _, m := range tt.machines {
 				g.Expect(tt.expectedMachineConditions).To(HaveKey(m.Name))
 				g.Expect(m.GetConditions()).To(conditions.MatchConditions(tt.expectedMachineConditions[m.Name]), "unexpected conditions for machine %s", m.Name)
+				if version, ok := tt.expectedEtcdVersions[m.Name]; ok {
+					g.Expect(m.GetAnnotations()).To(HaveKeyWithValue(controlplanev1.EtcdVersionAnnotation, version))
+				}
 			}
 		})
 	}
@@ -18,6 +18,7 @@ package internal
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/blang/semver"
 	"github.com/pkg/errors"
@@ -28,6 +29,22 @@ import (
 	etcdutil "sigs.k8s.io/cluster-api/controlplane/kubeadm/internal/etcd/util"
 )
 
+const (
+	// etcdLearnerModeFeatureGate is the kubeadm feature gate making kubeadm join add new etcd members as learners,
+	// which do not count toward quorum until they are promoted to voting members.
+	etcdLearnerModeFeatureGate = "EtcdLearnerMode"
+)
+
+var (
+	// Starting from v1.27.0 kubeadm can join etcd members as learners using the EtcdLearnerMode feature gate.
+	//
+	// NOTE: The following assumes that kubeadm version equals to Kubernetes version.
+	minKubernetesVersionEtcdLearnerMode = semver.MustParse("1.27.0")
+
+	// Learner members are supported starting from etcd v3.4.0.
+	minEtcdVersionLearnerMode = semver.MustParse("3.4.0")
+)
+
 type etcdClientFor interface {
 	forFirstAvailableNode(ctx context.Context, nodeNames []string) (*etcd.Client, error)
 	forLeader(ctx context.Context, nodeNames []string) (*etcd.Client, error)
@@ -228,3 +245,93 @@ func (w *Workload) EtcdMembers(ctx context.Context) ([]string, error) {
 	}
 	return names, nil
 }
+
+// ReconcileEtcdLearnerMode enables the EtcdLearnerMode feature gate in the kubeadm config map if both the kubeadm
+// version and the etcd version support joining etcd members as learners; it returns true if learner mode is in use.
+// NOTE: The feature gate is not changed if it has been explicitly set by the user.
+func (w *Workload) ReconcileEtcdLearnerMode(ctx context.Context, version semver.Version) (bool, error) {
+	if version.LT(minKubernetesVersionEtcdLearnerMode) {
+		return false, nil
+	}
+
+	nodes, err := w.getControlPlaneNodes(ctx)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to list control plane nodes")
+	}
+	nodeNames := make([]string, 0, len(nodes.Items))
+	for _, node := range nodes.Items {
+		nodeNames = append(nodeNames, node.Name)
+	}
+	etcdClient, err := w.etcdClientGenerator.forLeader(ctx, nodeNames)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to create etcd client")
+	}
+	defer etcdClient.Close()
+
+	etcdVersion, err := semver.ParseTolerant(etcdClient.Version)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to parse etcd version %q", etcdClient.Version)
+	}
+	if etcdVersion.LT(minEtcdVersionLearnerMode) {
+		return false, nil
+	}
+
+	enabled := true
+	if err := w.updateClusterConfiguration(ctx, func(c *bootstrapv1.ClusterConfiguration) {
+		if value, ok := c.FeatureGates[etcdLearnerModeFeatureGate]; ok {
+			enabled = value
+			return
+		}
+		if c.FeatureGates == nil {
+			c.FeatureGates = map[string]bool{}
+		}
+		c.FeatureGates[etcdLearnerModeFeatureGate] = true
+	}, version); err != nil {
+		return false, err
+	}
+	return enabled, nil
+}
+
+// PromoteEtcdLearners promotes to voting members all the etcd learners which are in sync with the leader,
+// and returns the names of the learners which are not yet in sync and thus can't be promoted yet.
+func (w *Workload) PromoteEtcdLearners(ctx context.Context) ([]string, error) {
+	nodes, err := w.getControlPlaneNodes(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list control plane nodes")
+	}
+	nodeNames := make([]string, 0, len(nodes.Items))
+	for _, node := range nodes.Items {
+		nodeNames = append(nodeNames, node.Name)
+	}
+	etcdClient, err := w.etcdClientGenerator.forLeader(ctx, nodeNames)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create etcd client")
+	}
+	defer etcdClient.Close()
+
+	members, err := etcdClient.Members(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list etcd members using etcd client")
+	}
+
+	learners := []string{}
+	errs := []error{}
+	for _, member := range members {
+		if !member.IsLearner {
+			continue
+		}
+
+		name := member.Name
+		if name == "" {
+			name = fmt.Sprintf("%d (Name not yet assigned)", member.ID)
+		}
+		if err := etcdClient.PromoteMember(ctx, member.ID); err != nil {
+			if errors.Is(err, etcd.ErrLearnerNotReady) {
+				learners = append(learners, name)
+				continue
+			}
+			errs = append(errs, errors.Wrapf(err, "failed to promote etcd learner %s", name))
+		}
+	}
+	return learners, kerrors.NewAggregate(errs)
+}
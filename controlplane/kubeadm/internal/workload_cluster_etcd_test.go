@@ -25,6 +25,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	. "github.com/onsi/gomega"
 	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
 	clientv3 "go.etcd.io/etcd/client/v3"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -690,6 +691,141 @@ func TestRemoveNodeFromKubeadmConfigMap(t *testing.T) {
 	}
 }
 
+func TestReconcileEtcdLearnerMode(t *testing.T) {
+	tests := []struct {
+		name                     string
+		version                  semver.Version
+		etcdVersion              string
+		clusterConfigurationData string
+		wantEnabled              bool
+		wantFeatureGate          string
+	}{
+		{
+			name:        "it should not enable learner mode for Kubernetes versions not supporting it",
+			version:     semver.MustParse("1.26.3"),
+			etcdVersion: "3.5.6",
+			clusterConfigurationData: yaml.Raw(`
+				apiVersion: kubeadm.k8s.io/v1beta3
+				kind: ClusterConfiguration
+				`),
+			wantEnabled: false,
+		},
+		{
+			name:        "it should not enable learner mode for etcd versions not supporting it",
+			version:     semver.MustParse("1.27.0"),
+			etcdVersion: "3.3.15",
+			clusterConfigurationData: yaml.Raw(`
+				apiVersion: kubeadm.k8s.io/v1beta3
+				kind: ClusterConfiguration
+				`),
+			wantEnabled: false,
+		},
+		{
+			name:        "it should enable learner mode when supported",
+			version:     semver.MustParse("1.27.0"),
+			etcdVersion: "3.5.6",
+			clusterConfigurationData: yaml.Raw(`
+				apiVersion: kubeadm.k8s.io/v1beta3
+				kind: ClusterConfiguration
+				`),
+			wantEnabled:     true,
+			wantFeatureGate: "EtcdLearnerMode: true",
+		},
+		{
+			name:        "it should preserve the feature gate explicitly disabled by the user",
+			version:     semver.MustParse("1.27.0"),
+			etcdVersion: "3.5.6",
+			clusterConfigurationData: yaml.Raw(`
+				apiVersion: kubeadm.k8s.io/v1beta3
+				featureGates:
+				  EtcdLearnerMode: false
+				kind: ClusterConfiguration
+				`),
+			wantEnabled:     false,
+			wantFeatureGate: "EtcdLearnerMode: false",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			fakeClient := fake.NewClientBuilder().WithObjects(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      kubeadmConfigKey,
+					Namespace: metav1.NamespaceSystem,
+				},
+				Data: map[string]string{
+					clusterConfigurationKey: tt.clusterConfigurationData,
+				},
+			}).Build()
+
+			w := &Workload{
+				Client: fakeClient,
+				etcdClientGenerator: &fakeEtcdClientGenerator{
+					forLeaderClient: &etcd.Client{
+						EtcdClient: &fake2.FakeEtcdClient{},
+						Version:    tt.etcdVersion,
+					},
+				},
+			}
+			enabled, err := w.ReconcileEtcdLearnerMode(ctx, tt.version)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(enabled).To(Equal(tt.wantEnabled))
+
+			var actualConfig corev1.ConfigMap
+			g.Expect(w.Client.Get(
+				ctx,
+				client.ObjectKey{Name: kubeadmConfigKey, Namespace: metav1.NamespaceSystem},
+				&actualConfig,
+			)).To(Succeed())
+			if tt.wantFeatureGate == "" {
+				g.Expect(actualConfig.Data[clusterConfigurationKey]).To(Equal(tt.clusterConfigurationData))
+				return
+			}
+			g.Expect(actualConfig.Data[clusterConfigurationKey]).To(ContainSubstring(tt.wantFeatureGate))
+		})
+	}
+}
+
+func TestPromoteEtcdLearners(t *testing.T) {
+	g := NewWithT(t)
+
+	fakeEtcdClient := &fake2.FakeEtcdClient{
+		MemberListResponse: &clientv3.MemberListResponse{
+			Header: &pb.ResponseHeader{},
+			Members: []*pb.Member{
+				{Name: "machine-1", ID: uint64(1)},
+				{Name: "machine-2", ID: uint64(2), IsLearner: true},
+			},
+		},
+		AlarmResponse: &clientv3.AlarmResponse{},
+	}
+	w := &Workload{
+		Client: &fakeClient{list: &corev1.NodeList{}},
+		etcdClientGenerator: &fakeEtcdClientGenerator{
+			forLeaderClient: &etcd.Client{EtcdClient: fakeEtcdClient},
+		},
+	}
+
+	learners, err := w.PromoteEtcdLearners(ctx)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(learners).To(BeEmpty())
+	g.Expect(fakeEtcdClient.PromotedMembers).To(Equal([]uint64{2}))
+
+	// Learners not yet in sync with the leader are reported and not promoted.
+	fakeEtcdClient.PromotedMembers = nil
+	fakeEtcdClient.MemberPromoteErr = rpctypes.ErrLearnerNotReady
+	learners, err = w.PromoteEtcdLearners(ctx)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(learners).To(Equal([]string{"machine-2"}))
+	g.Expect(fakeEtcdClient.PromotedMembers).To(BeEmpty())
+
+	// Other failures are returned as errors.
+	fakeEtcdClient.MemberPromoteErr = errors.New("failed to promote")
+	_, err = w.PromoteEtcdLearners(ctx)
+	g.Expect(err).To(HaveOccurred())
+}
+
 type fakeEtcdClientGenerator struct {
 	forNodesClient     *etcd.Client
 	forNodesClientFunc func([]string) (*etcd.Client, error)
@@ -22,9 +22,14 @@ import (
 
 	"github.com/blang/semver"
 	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/cluster-api/util/patch"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -43,6 +48,14 @@ const (
 
 	// KubeletConfigMapName defines base kubelet configuration ConfigMap name.
 	KubeletConfigMapName = "kubelet-config-%d.%d"
+
+	// KubeletServingCertApproverName defines the name of the ServiceAccount, ClusterRole and ClusterRoleBinding
+	// to be used by an approver of kubelet serving certificate signing requests.
+	KubeletServingCertApproverName = "kubeadm:kubelet-serving-cert-approver"
+
+	// KubeletServingCertApproverServiceAccountName defines the name of the ServiceAccount to be used by
+	// an approver of kubelet serving certificate signing requests.
+	KubeletServingCertApproverServiceAccountName = "kubelet-serving-cert-approver"
 )
 
 // EnsureResource creates a resoutce if the target resource doesn't exist. If the resource exists already, this function will ignore the resource instead.
@@ -100,6 +113,136 @@ func (w *Workload) AllowBootstrapTokensToGetNodes(ctx context.Context) error {
 	})
 }
 
+// AllowKubeletServingCertificateApproval creates the ServiceAccount and the RBAC rules required by an approver
+// of the kubelet serving certificate signing requests, which are not approved automatically by the kube-controller-manager.
+func (w *Workload) AllowKubeletServingCertificateApproval(ctx context.Context) error {
+	if err := w.EnsureResource(ctx, &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      KubeletServingCertApproverServiceAccountName,
+			Namespace: metav1.NamespaceSystem,
+		},
+	}); err != nil {
+		return err
+	}
+
+	if err := w.EnsureResource(ctx, &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: KubeletServingCertApproverName,
+		},
+		Rules: []rbacv1.PolicyRule{
+			{
+				Verbs:     []string{"get", "list", "watch"},
+				APIGroups: []string{certificatesv1.GroupName},
+				Resources: []string{"certificatesigningrequests"},
+			},
+			{
+				Verbs:     []string{"update"},
+				APIGroups: []string{certificatesv1.GroupName},
+				Resources: []string{"certificatesigningrequests/approval"},
+			},
+			{
+				Verbs:         []string{"approve"},
+				APIGroups:     []string{certificatesv1.GroupName},
+				Resources:     []string{"signers"},
+				ResourceNames: []string{certificatesv1.KubeletServingSignerName},
+			},
+		},
+	}); err != nil {
+		return err
+	}
+
+	return w.EnsureResource(ctx, &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: KubeletServingCertApproverName,
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "ClusterRole",
+			Name:     KubeletServingCertApproverName,
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      rbacv1.ServiceAccountKind,
+				Name:      KubeletServingCertApproverServiceAccountName,
+				Namespace: metav1.NamespaceSystem,
+			},
+		},
+	})
+}
+
+// EnsureKubeletServingCertApprover deploys an approver of the kubelet serving certificate signing requests using the
+// given image, running with the ServiceAccount created by AllowKubeletServingCertificateApproval; if the approver
+// is already deployed, its image is updated.
+func (w *Workload) EnsureKubeletServingCertApprover(ctx context.Context, image string) error {
+	desired := kubeletServingCertApproverDeployment(image)
+
+	deployment := &appsv1.Deployment{}
+	if err := w.Client.Get(ctx, client.ObjectKeyFromObject(desired), deployment); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to determine if %s deployment already exists", desired.Name)
+		}
+		if err := w.Client.Create(ctx, desired); err != nil {
+			return errors.Wrapf(err, "failed to create %s deployment", desired.Name)
+		}
+		return nil
+	}
+
+	containers := deployment.Spec.Template.Spec.Containers
+	if len(containers) == 1 && containers[0].Name == KubeletServingCertApproverServiceAccountName && containers[0].Image == image {
+		return nil
+	}
+
+	helper, err := patch.NewHelper(deployment, w.Client)
+	if err != nil {
+		return err
+	}
+	deployment.Spec.Template.Spec.Containers = desired.Spec.Template.Spec.Containers
+	return helper.Patch(ctx, deployment)
+}
+
+// kubeletServingCertApproverDeployment returns the Deployment of the approver of the kubelet serving certificate
+// signing requests. The approver runs on the control plane nodes using the host network, so it can approve the
+// requests before a CNI is installed in the workload cluster.
+func kubeletServingCertApproverDeployment(image string) *appsv1.Deployment {
+	labels := map[string]string{"app": KubeletServingCertApproverServiceAccountName}
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      KubeletServingCertApproverServiceAccountName,
+			Namespace: metav1.NamespaceSystem,
+			Labels:    labels,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: pointer.Int32Ptr(1),
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					ServiceAccountName: KubeletServingCertApproverServiceAccountName,
+					HostNetwork:        true,
+					PriorityClassName:  "system-cluster-critical",
+					NodeSelector:       map[string]string{labelNodeRoleControlPlane: ""},
+					Tolerations: []corev1.Toleration{
+						{
+							Key:    labelNodeRoleControlPlane,
+							Effect: corev1.TaintEffectNoSchedule,
+						},
+						{
+							Key:    "node.kubernetes.io/not-ready",
+							Effect: corev1.TaintEffectNoSchedule,
+						},
+					},
+					Containers: []corev1.Container{
+						{
+							Name:  KubeletServingCertApproverServiceAccountName,
+							Image: image,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
 func generateKubeletConfigName(version semver.Version) string {
 	return fmt.Sprintf(KubeletConfigMapName, version.Major, version.Minor)
 }
@@ -23,10 +23,12 @@ import (
 	. "github.com/onsi/gomega"
 
 	"github.com/blang/semver"
+	appsv1 "k8s.io/api/apps/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 func TestCluster_ReconcileKubeletRBACBinding_NoError(t *testing.T) {
@@ -155,3 +157,67 @@ func TestCluster_AllowBootstrapTokensToGetNodes_Error(t *testing.T) {
 		})
 	}
 }
+
+func TestCluster_AllowKubeletServingCertificateApproval(t *testing.T) {
+	tests := []struct {
+		name      string
+		client    ctrlclient.Client
+		expectErr bool
+	}{
+		{
+			name:   "service account, role binding and role don't exist",
+			client: &fakeClient{},
+		},
+		{
+			name: "create returns an already exists error",
+			client: &fakeClient{
+				createErr: apierrors.NewAlreadyExists(schema.GroupResource{}, ""),
+			},
+		},
+		{
+			name: "create returns an unexpected error",
+			client: &fakeClient{
+				createErr: errors.New(""),
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			c := &Workload{
+				Client: tt.client,
+			}
+			err := c.AllowKubeletServingCertificateApproval(ctx)
+			if tt.expectErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+		})
+	}
+}
+
+func TestCluster_EnsureKubeletServingCertApprover(t *testing.T) {
+	g := NewWithT(t)
+
+	c := &Workload{
+		Client: fake.NewClientBuilder().Build(),
+	}
+	key := ctrlclient.ObjectKey{Namespace: "kube-system", Name: KubeletServingCertApproverServiceAccountName}
+
+	// The approver is deployed with the given image.
+	g.Expect(c.EnsureKubeletServingCertApprover(ctx, "approver:v1")).To(Succeed())
+	deployment := &appsv1.Deployment{}
+	g.Expect(c.Client.Get(ctx, key, deployment)).To(Succeed())
+	g.Expect(deployment.Spec.Template.Spec.ServiceAccountName).To(Equal(KubeletServingCertApproverServiceAccountName))
+	g.Expect(deployment.Spec.Template.Spec.Containers).To(HaveLen(1))
+	g.Expect(deployment.Spec.Template.Spec.Containers[0].Image).To(Equal("approver:v1"))
+
+	// The image is updated when it changes.
+	g.Expect(c.EnsureKubeletServingCertApprover(ctx, "approver:v2")).To(Succeed())
+	g.Expect(c.Client.Get(ctx, key, deployment)).To(Succeed())
+	g.Expect(deployment.Spec.Template.Spec.Containers[0].Image).To(Equal("approver:v2"))
+}
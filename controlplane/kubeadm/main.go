@@ -65,19 +65,24 @@ func init() {
 }
 
 var (
-	metricsBindAddr                string
-	enableLeaderElection           bool
-	leaderElectionLeaseDuration    time.Duration
-	leaderElectionRenewDeadline    time.Duration
-	leaderElectionRetryPeriod      time.Duration
-	watchFilterValue               string
-	watchNamespace                 string
-	profilerAddress                string
-	kubeadmControlPlaneConcurrency int
-	syncPeriod                     time.Duration
-	webhookPort                    int
-	webhookCertDir                 string
-	healthAddr                     string
+	metricsBindAddr                 string
+	enableLeaderElection            bool
+	leaderElectionLeaseDuration     time.Duration
+	leaderElectionRenewDeadline     time.Duration
+	leaderElectionRetryPeriod       time.Duration
+	watchFilterValue                string
+	watchNamespace                  string
+	profilerAddress                 string
+	kubeadmControlPlaneConcurrency  int
+	syncPeriod                      time.Duration
+	webhookPort                     int
+	webhookCertDir                  string
+	healthAddr                      string
+	remoteClientQPS                 float32
+	remoteClientBurst               int
+	remoteClientTimeout             time.Duration
+	managementClusterName           string
+	kubeletServingCertApproverImage string
 )
 
 // InitFlags initializes the flags.
@@ -120,6 +125,21 @@ func InitFlags(fs *pflag.FlagSet) {
 
 	fs.StringVar(&healthAddr, "health-addr", ":9440",
 		"The address the health endpoint binds to.")
+
+	fs.Float32Var(&remoteClientQPS, "remote-client-qps", 5,
+		"Maximum queries per second from the controller manager to the API server of each workload cluster.")
+
+	fs.IntVar(&remoteClientBurst, "remote-client-burst", 10,
+		"Maximum burst of queries from the controller manager to the API server of each workload cluster.")
+
+	fs.DurationVar(&remoteClientTimeout, "remote-client-timeout", 10*time.Second,
+		"Timeout of the requests to the API server of each workload cluster (duration string)")
+
+	fs.StringVar(&managementClusterName, "management-cluster-name", "",
+		"Name identifying the management cluster in the User-Agent of the requests to the workload clusters.")
+
+	fs.StringVar(&kubeletServingCertApproverImage, "kubelet-serving-cert-approver-image", "",
+		"Image of the approver of kubelet serving certificate signing requests deployed in the workload clusters of the KubeadmControlPlanes setting rotateKubeletServerCertificates. If unspecified, the approver is not deployed.")
 }
 func main() {
 	rand.Seed(time.Now().UnixNano())
@@ -194,6 +214,11 @@ func setupReconcilers(ctx context.Context, mgr ctrl.Manager) {
 	// Set up a ClusterCacheTracker to provide to controllers
 	// requiring a connection to a remote cluster
 	tracker, err := remote.NewClusterCacheTracker(mgr, remote.ClusterCacheTrackerOptions{
+		ClientQPS:             remoteClientQPS,
+		ClientBurst:           remoteClientBurst,
+		ClientTimeout:         remoteClientTimeout,
+		ControllerName:        "capi-kubeadm-control-plane-controller-manager",
+		ManagementClusterName: managementClusterName,
 		Indexes: []remote.Index{
 			{
 				Object:       &corev1.Node{},
@@ -217,9 +242,10 @@ func setupReconcilers(ctx context.Context, mgr ctrl.Manager) {
 	}
 
 	if err := (&kubeadmcontrolplanecontrollers.KubeadmControlPlaneReconciler{
-		Client:           mgr.GetClient(),
-		Tracker:          tracker,
-		WatchFilterValue: watchFilterValue,
+		Client:                          mgr.GetClient(),
+		Tracker:                         tracker,
+		WatchFilterValue:                watchFilterValue,
+		KubeletServingCertApproverImage: kubeletServingCertApproverImage,
 	}).SetupWithManager(ctx, mgr, concurrency(kubeadmControlPlaneConcurrency)); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "KubeadmControlPlane")
 		os.Exit(1)
@@ -77,6 +77,13 @@ const (
 	// not result in a Node joining the cluster within a given timeout
 	// and that are managed by a MachineSet.
 	JoinClusterTimeoutMachineError = "JoinClusterTimeoutError"
+
+	// ProvisioningTimeoutMachineError indicates that the bootstrap data or the infrastructure
+	// for the Machine did not become ready within the Machine's ProvisioningTimeout.
+	//
+	// Example: the infrastructure provider keeps waiting for an instance that is never
+	// going to be created.
+	ProvisioningTimeoutMachineError MachineStatusError = "ProvisioningTimeoutError"
 )
 
 // ClusterStatusError defines errors states for Cluster objects.
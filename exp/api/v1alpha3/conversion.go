@@ -40,3 +40,8 @@ func Convert_v1alpha3_MachinePool_To_v1alpha4_MachinePool(in *MachinePool, out *
 
 	return nil
 }
+
+// Convert_v1alpha4_MachinePoolStatus_To_v1alpha3_MachinePoolStatus is an autogenerated conversion function.
+func Convert_v1alpha4_MachinePoolStatus_To_v1alpha3_MachinePoolStatus(in *v1alpha4.MachinePoolStatus, out *MachinePoolStatus, s conversion.Scope) error {
+	return autoConvert_v1alpha4_MachinePoolStatus_To_v1alpha3_MachinePoolStatus(in, out, s)
+}
@@ -28,3 +28,17 @@ const (
 	// to be ready.
 	WaitingForReplicasReadyReason = "WaitingForReplicasReady"
 )
+
+const (
+	// FailureDomainsValidCondition reports if the failure domains of a MachinePool are defined in the Cluster
+	// the MachinePool belongs to, and thus they can be passed to the infrastructure provider.
+	FailureDomainsValidCondition clusterv1.ConditionType = "FailureDomainsValid"
+
+	// InvalidFailureDomainsReason (Severity=Warning) documents a MachinePool referencing failure domains
+	// not defined in Cluster.Status.FailureDomains.
+	InvalidFailureDomainsReason = "InvalidFailureDomains"
+
+	// FailureDomainsNotSupportedReason (Severity=Warning) documents a MachinePool with failure domains whose
+	// infrastructure machine pool does not define spec.failureDomains.
+	FailureDomainsNotSupportedReason = "FailureDomainsNotSupported"
+)
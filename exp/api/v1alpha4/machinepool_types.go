@@ -26,6 +26,11 @@ import (
 const (
 	// MachinePoolFinalizer is used to ensure deletion of dependencies (nodes, infra).
 	MachinePoolFinalizer = "machinepool.cluster.x-k8s.io"
+
+	// ReplicasManagedByAnnotation is an annotation that indicates external (non-Cluster API) management of the
+	// MachinePool replicas, e.g. by an autoscaling group of the infrastructure provider; in this case the
+	// MachinePool controller sets spec.replicas to the number of replicas reported by the infrastructure provider.
+	ReplicasManagedByAnnotation = "cluster.x-k8s.io/replicas-managed-by"
 )
 
 // ANCHOR: MachinePoolSpec
@@ -56,6 +61,9 @@ type MachinePoolSpec struct {
 	ProviderIDList []string `json:"providerIDList,omitempty"`
 
 	// FailureDomains is the list of failure domains this MachinePool should be attached to.
+	// Each failure domain must be defined in the Cluster status; the list is passed to the
+	// infrastructure machine pool as spec.failureDomains, as a hint for spreading the machine instances.
+	// +optional
 	FailureDomains []string `json:"failureDomains,omitempty"`
 }
 
@@ -116,6 +124,17 @@ type MachinePoolStatus struct {
 	// +optional
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 
+	// FailureDomains is the distribution of the machine instances across failure domains,
+	// as reported by the infrastructure provider.
+	// +optional
+	FailureDomains []FailureDomainReplicas `json:"failureDomains,omitempty"`
+
+	// ProviderIDsToRemediate lists the provider IDs of the machine instances whose nodes are reported unhealthy by a
+	// MachineHealthCheck targeting the MachinePool; infrastructure providers are expected to delete or replace those
+	// instances, given that machine instances of a MachinePool do not have Machine objects to be remediated.
+	// +optional
+	ProviderIDsToRemediate []string `json:"providerIDsToRemediate,omitempty"`
+
 	// Conditions define the current service state of the MachinePool.
 	// +optional
 	Conditions clusterv1.Conditions `json:"conditions,omitempty"`
@@ -123,6 +142,15 @@ type MachinePoolStatus struct {
 
 // ANCHOR_END: MachinePoolStatus
 
+// FailureDomainReplicas is the number of machine instances of a MachinePool in a failure domain.
+type FailureDomainReplicas struct {
+	// Name is the name of the failure domain.
+	Name string `json:"name"`
+
+	// Replicas is the number of machine instances in the failure domain.
+	Replicas int32 `json:"replicas"`
+}
+
 // MachinePoolPhase is a string representation of a MachinePool Phase.
 //
 // This type is a high-level indicator of the status of the MachinePool as it is provisioned,
@@ -118,6 +118,27 @@ func (m *MachinePool) validate(old *MachinePool) error {
 		)
 	}
 
+	seenFailureDomains := map[string]bool{}
+	for i, fd := range m.Spec.FailureDomains {
+		fldPath := field.NewPath("spec", "failureDomains").Index(i)
+		if fd == "" {
+			allErrs = append(allErrs, field.Required(fldPath, "failure domain name must not be empty"))
+			continue
+		}
+		if seenFailureDomains[fd] {
+			allErrs = append(allErrs, field.Duplicate(fldPath, fd))
+		}
+		seenFailureDomains[fd] = true
+	}
+
+	// Auxiliary infrastructure is reconciled only for Machines, MachinePools do not create Machines from their template.
+	if len(m.Spec.Template.Spec.AuxiliaryInfrastructure) > 0 {
+		allErrs = append(
+			allErrs,
+			field.Forbidden(field.NewPath("spec", "template", "spec", "auxiliaryInfrastructure"), "is not supported on MachinePools"),
+		)
+	}
+
 	if old != nil && old.Spec.ClusterName != m.Spec.ClusterName {
 		allErrs = append(
 			allErrs,
@@ -99,6 +99,80 @@ func TestMachinePoolBootstrapValidation(t *testing.T) {
 	}
 }
 
+func TestMachinePoolFailureDomainsValidation(t *testing.T) {
+	tests := []struct {
+		name           string
+		failureDomains []string
+		expectErr      bool
+	}{
+		{
+			name:           "should not return error if failure domains are not set",
+			failureDomains: nil,
+			expectErr:      false,
+		},
+		{
+			name:           "should not return error if failure domains are unique",
+			failureDomains: []string{"fd1", "fd2"},
+			expectErr:      false,
+		},
+		{
+			name:           "should return error if a failure domain is empty",
+			failureDomains: []string{"fd1", ""},
+			expectErr:      true,
+		},
+		{
+			name:           "should return error if a failure domain is duplicated",
+			failureDomains: []string{"fd1", "fd2", "fd1"},
+			expectErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			m := &MachinePool{
+				Spec: MachinePoolSpec{
+					Template: clusterv1.MachineTemplateSpec{
+						Spec: clusterv1.MachineSpec{
+							Bootstrap: clusterv1.Bootstrap{DataSecretName: pointer.StringPtr("test")},
+						},
+					},
+					FailureDomains: tt.failureDomains,
+				},
+			}
+			if tt.expectErr {
+				g.Expect(m.ValidateCreate()).NotTo(Succeed())
+				g.Expect(m.ValidateUpdate(m)).NotTo(Succeed())
+			} else {
+				g.Expect(m.ValidateCreate()).To(Succeed())
+				g.Expect(m.ValidateUpdate(m)).To(Succeed())
+			}
+		})
+	}
+}
+
+func TestMachinePoolAuxiliaryInfrastructureValidation(t *testing.T) {
+	g := NewWithT(t)
+	m := &MachinePool{
+		Spec: MachinePoolSpec{
+			Template: clusterv1.MachineTemplateSpec{
+				Spec: clusterv1.MachineSpec{
+					Bootstrap: clusterv1.Bootstrap{DataSecretName: pointer.StringPtr("test")},
+					AuxiliaryInfrastructure: []clusterv1.AuxiliaryInfrastructure{
+						{Name: "nic1", Ref: &corev1.ObjectReference{Name: "nic1"}},
+					},
+				},
+			},
+		},
+	}
+	g.Expect(m.ValidateCreate()).NotTo(Succeed())
+	g.Expect(m.ValidateUpdate(m)).NotTo(Succeed())
+
+	m.Spec.Template.Spec.AuxiliaryInfrastructure = nil
+	g.Expect(m.ValidateCreate()).To(Succeed())
+	g.Expect(m.ValidateUpdate(m)).To(Succeed())
+}
+
 func TestMachinePoolNamespaceValidation(t *testing.T) {
 	tests := []struct {
 		name      string
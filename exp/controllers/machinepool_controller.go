@@ -140,6 +140,7 @@ func (r *MachinePoolReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 				clusterv1.BootstrapReadyCondition,
 				clusterv1.InfrastructureReadyCondition,
 				expv1.ReplicasReadyCondition,
+				expv1.FailureDomainsValidCondition,
 			),
 		)
 
@@ -154,6 +155,7 @@ func (r *MachinePoolReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 					clusterv1.BootstrapReadyCondition,
 					clusterv1.InfrastructureReadyCondition,
 					expv1.ReplicasReadyCondition,
+					expv1.FailureDomainsValidCondition,
 				}},
 			)
 		}
@@ -195,6 +197,7 @@ func (r *MachinePoolReconciler) reconcile(ctx context.Context, cluster *clusterv
 	phases := []func(context.Context, *clusterv1.Cluster, *expv1.MachinePool) (ctrl.Result, error){
 		r.reconcileBootstrap,
 		r.reconcileInfrastructure,
+		r.reconcileAutoscalerAnnotations,
 		r.reconcileNodeRefs,
 	}
 
@@ -0,0 +1,185 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/controllers/external"
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/util/annotations"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// Annotations read by the cluster autoscaler Cluster API provider.
+const (
+	// autoscalerMinSizeAnnotation and autoscalerMaxSizeAnnotation define the size of an autoscaled node group.
+	autoscalerMinSizeAnnotation = "cluster.x-k8s.io/cluster-api-autoscaler-node-group-min-size"
+	autoscalerMaxSizeAnnotation = "cluster.x-k8s.io/cluster-api-autoscaler-node-group-max-size"
+
+	// autoscalerCapacityAnnotationPrefix is the prefix of the annotations describing the Nodes of a node group
+	// scaled to zero, used by the cluster autoscaler to decide whether scaling the node group up helps pending Pods.
+	autoscalerCapacityAnnotationPrefix = "capacity.cluster-autoscaler.kubernetes.io/"
+	autoscalerCPUAnnotation            = autoscalerCapacityAnnotationPrefix + "cpu"
+	autoscalerMemoryAnnotation         = autoscalerCapacityAnnotationPrefix + "memory"
+	autoscalerEphemeralDiskAnnotation  = autoscalerCapacityAnnotationPrefix + "ephemeral-disk"
+	autoscalerMaxPodsAnnotation        = autoscalerCapacityAnnotationPrefix + "maxPods"
+	autoscalerGPUTypeAnnotation        = autoscalerCapacityAnnotationPrefix + "gpu-type"
+	autoscalerGPUCountAnnotation       = autoscalerCapacityAnnotationPrefix + "gpu-count"
+	autoscalerLabelsAnnotation         = autoscalerCapacityAnnotationPrefix + "labels"
+	autoscalerTaintsAnnotation         = autoscalerCapacityAnnotationPrefix + "taints"
+
+	// nvidiaGPUResourceName is the name of the resource of the NVIDIA GPUs.
+	nvidiaGPUResourceName = "nvidia.com/gpu"
+)
+
+// reconcileAutoscalerAnnotations sets on MachinePools autoscaled by the cluster autoscaler the annotations describing
+// the Nodes of the MachinePool, so the cluster autoscaler can scale the MachinePool from zero.
+//
+// The capacity of the Nodes is read from status.capacity of the infrastructure machine pool, if reported by the
+// infrastructure provider; the labels and the taints of the Nodes are read from the node registration options of
+// the bootstrap config, if any. Annotations for values which can't be computed are left untouched, so they can be
+// set by users.
+func (r *MachinePoolReconciler) reconcileAutoscalerAnnotations(ctx context.Context, _ *clusterv1.Cluster, mp *expv1.MachinePool) (ctrl.Result, error) {
+	if !isAutoscaled(mp) {
+		return ctrl.Result{}, nil
+	}
+
+	desired := map[string]string{}
+
+	infraConfig, err := external.Get(ctx, r.Client, &mp.Spec.Template.Spec.InfrastructureRef, mp.Namespace)
+	if err != nil {
+		if apierrors.IsNotFound(errors.Cause(err)) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	capacity, err := capacityAnnotations(infraConfig)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrapf(err, "failed to retrieve capacity from infrastructure provider for MachinePool %q in namespace %q", mp.Name, mp.Namespace)
+	}
+	for k, v := range capacity {
+		desired[k] = v
+	}
+
+	if mp.Spec.Template.Spec.Bootstrap.ConfigRef != nil {
+		bootstrapConfig, err := external.Get(ctx, r.Client, mp.Spec.Template.Spec.Bootstrap.ConfigRef, mp.Namespace)
+		if err != nil && !apierrors.IsNotFound(errors.Cause(err)) {
+			return ctrl.Result{}, err
+		}
+		if bootstrapConfig != nil {
+			nodeTemplate, err := nodeTemplateAnnotations(bootstrapConfig)
+			if err != nil {
+				return ctrl.Result{}, errors.Wrapf(err, "failed to retrieve node registration options from bootstrap provider for MachinePool %q in namespace %q", mp.Name, mp.Namespace)
+			}
+			for k, v := range nodeTemplate {
+				desired[k] = v
+			}
+		}
+	}
+
+	annotations.AddAnnotations(mp, desired)
+	return ctrl.Result{}, nil
+}
+
+// isAutoscaled returns true if the MachinePool is a node group of the cluster autoscaler.
+func isAutoscaled(mp *expv1.MachinePool) bool {
+	_, hasMin := mp.Annotations[autoscalerMinSizeAnnotation]
+	_, hasMax := mp.Annotations[autoscalerMaxSizeAnnotation]
+	return hasMin && hasMax
+}
+
+// capacityAnnotations returns the capacity annotations for the resources reported in status.capacity
+// of the infrastructure machine pool.
+func capacityAnnotations(infraConfig *unstructured.Unstructured) (map[string]string, error) {
+	capacity, found, err := unstructured.NestedStringMap(infraConfig.Object, "status", "capacity")
+	if err != nil || !found {
+		return nil, err
+	}
+
+	resourceAnnotations := map[corev1.ResourceName]string{
+		corev1.ResourceCPU:              autoscalerCPUAnnotation,
+		corev1.ResourceMemory:           autoscalerMemoryAnnotation,
+		corev1.ResourceEphemeralStorage: autoscalerEphemeralDiskAnnotation,
+		corev1.ResourcePods:             autoscalerMaxPodsAnnotation,
+		nvidiaGPUResourceName:           autoscalerGPUCountAnnotation,
+	}
+	out := map[string]string{}
+	for name, value := range capacity {
+		annotation, ok := resourceAnnotations[corev1.ResourceName(name)]
+		if !ok {
+			continue
+		}
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid capacity %q for resource %s", value, name)
+		}
+		out[annotation] = quantity.String()
+		if name == nvidiaGPUResourceName {
+			out[autoscalerGPUTypeAnnotation] = nvidiaGPUResourceName
+		}
+	}
+	return out, nil
+}
+
+// nodeTemplateAnnotations returns the labels and taints annotations for the node registration options of the
+// bootstrap config, as defined by spec.joinConfiguration.nodeRegistration for kubeadm based bootstrap providers.
+func nodeTemplateAnnotations(bootstrapConfig *unstructured.Unstructured) (map[string]string, error) {
+	out := map[string]string{}
+
+	nodeLabels, found, err := unstructured.NestedString(bootstrapConfig.Object, "spec", "joinConfiguration", "nodeRegistration", "kubeletExtraArgs", "node-labels")
+	if err != nil {
+		return nil, err
+	}
+	if found && nodeLabels != "" {
+		labels := strings.Split(nodeLabels, ",")
+		sort.Strings(labels)
+		out[autoscalerLabelsAnnotation] = strings.Join(labels, ",")
+	}
+
+	taints, found, err := unstructured.NestedSlice(bootstrapConfig.Object, "spec", "joinConfiguration", "nodeRegistration", "taints")
+	if err != nil {
+		return nil, err
+	}
+	if found && len(taints) > 0 {
+		values := []string{}
+		for _, t := range taints {
+			taint, ok := t.(map[string]interface{})
+			if !ok {
+				return nil, errors.Errorf("invalid taint %v", t)
+			}
+			key, _, _ := unstructured.NestedString(taint, "key")
+			value, _, _ := unstructured.NestedString(taint, "value")
+			effect, _, _ := unstructured.NestedString(taint, "effect")
+			if value != "" {
+				key = fmt.Sprintf("%s=%s", key, value)
+			}
+			values = append(values, fmt.Sprintf("%s:%s", key, effect))
+		}
+		out[autoscalerTaintsAnnotation] = strings.Join(values, ",")
+	}
+	return out, nil
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/pointer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1alpha4"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReconcileAutoscalerAnnotations(t *testing.T) {
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: metav1.NamespaceDefault},
+	}
+	newMachinePool := func(annotations map[string]string) *expv1.MachinePool {
+		return &expv1.MachinePool{
+			ObjectMeta: metav1.ObjectMeta{Name: "machinepool-test", Namespace: metav1.NamespaceDefault, Annotations: annotations},
+			Spec: expv1.MachinePoolSpec{
+				Replicas: pointer.Int32Ptr(0),
+				Template: clusterv1.MachineTemplateSpec{
+					Spec: clusterv1.MachineSpec{
+						Bootstrap: clusterv1.Bootstrap{
+							ConfigRef: &corev1.ObjectReference{
+								APIVersion: "bootstrap.cluster.x-k8s.io/v1alpha4",
+								Kind:       "BootstrapConfig",
+								Name:       "bootstrap-config1",
+							},
+						},
+						InfrastructureRef: corev1.ObjectReference{
+							APIVersion: "infrastructure.cluster.x-k8s.io/v1alpha4",
+							Kind:       "InfrastructureConfig",
+							Name:       "infra-config1",
+						},
+					},
+				},
+			},
+		}
+	}
+	infraConfig := &unstructured.Unstructured{Object: map[string]interface{}{
+		"kind":       "InfrastructureConfig",
+		"apiVersion": "infrastructure.cluster.x-k8s.io/v1alpha4",
+		"metadata": map[string]interface{}{
+			"name":      "infra-config1",
+			"namespace": metav1.NamespaceDefault,
+		},
+		"status": map[string]interface{}{
+			"capacity": map[string]interface{}{
+				"cpu":               "4",
+				"memory":            "16Gi",
+				"ephemeral-storage": "100Gi",
+				"pods":              "110",
+				"nvidia.com/gpu":    "1",
+				"example.com/foo":   "1",
+			},
+		},
+	}}
+	bootstrapConfig := &unstructured.Unstructured{Object: map[string]interface{}{
+		"kind":       "BootstrapConfig",
+		"apiVersion": "bootstrap.cluster.x-k8s.io/v1alpha4",
+		"metadata": map[string]interface{}{
+			"name":      "bootstrap-config1",
+			"namespace": metav1.NamespaceDefault,
+		},
+		"spec": map[string]interface{}{
+			"joinConfiguration": map[string]interface{}{
+				"nodeRegistration": map[string]interface{}{
+					"kubeletExtraArgs": map[string]interface{}{
+						"node-labels": "pool=gpu,env=prod",
+					},
+					"taints": []interface{}{
+						map[string]interface{}{"key": "gpu", "value": "true", "effect": "NoSchedule"},
+						map[string]interface{}{"key": "dedicated", "effect": "NoExecute"},
+					},
+				},
+			},
+		},
+	}}
+
+	t.Run("sets the annotations on autoscaled MachinePools", func(t *testing.T) {
+		g := NewWithT(t)
+
+		mp := newMachinePool(map[string]string{
+			autoscalerMinSizeAnnotation: "0",
+			autoscalerMaxSizeAnnotation: "5",
+			autoscalerCPUAnnotation:     "2",
+		})
+		r := &MachinePoolReconciler{
+			Client: fake.NewClientBuilder().WithObjects(mp, infraConfig.DeepCopy(), bootstrapConfig.DeepCopy()).Build(),
+		}
+
+		_, err := r.reconcileAutoscalerAnnotations(ctx, cluster, mp)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(mp.Annotations).To(Equal(map[string]string{
+			autoscalerMinSizeAnnotation:       "0",
+			autoscalerMaxSizeAnnotation:       "5",
+			autoscalerCPUAnnotation:           "4",
+			autoscalerMemoryAnnotation:        "16Gi",
+			autoscalerEphemeralDiskAnnotation: "100Gi",
+			autoscalerMaxPodsAnnotation:       "110",
+			autoscalerGPUCountAnnotation:      "1",
+			autoscalerGPUTypeAnnotation:       "nvidia.com/gpu",
+			autoscalerLabelsAnnotation:        "env=prod,pool=gpu",
+			autoscalerTaintsAnnotation:        "gpu=true:NoSchedule,dedicated:NoExecute",
+		}))
+	})
+
+	t.Run("leaves the annotations which can't be computed untouched", func(t *testing.T) {
+		g := NewWithT(t)
+
+		mp := newMachinePool(map[string]string{
+			autoscalerMinSizeAnnotation: "0",
+			autoscalerMaxSizeAnnotation: "5",
+			autoscalerCPUAnnotation:     "2",
+		})
+		mp.Spec.Template.Spec.Bootstrap.ConfigRef = nil
+		infra := infraConfig.DeepCopy()
+		unstructured.RemoveNestedField(infra.Object, "status", "capacity")
+		r := &MachinePoolReconciler{
+			Client: fake.NewClientBuilder().WithObjects(mp, infra).Build(),
+		}
+
+		_, err := r.reconcileAutoscalerAnnotations(ctx, cluster, mp)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(mp.Annotations).To(HaveKeyWithValue(autoscalerCPUAnnotation, "2"))
+		g.Expect(mp.Annotations).To(HaveLen(3))
+	})
+
+	t.Run("ignores MachinePools not autoscaled", func(t *testing.T) {
+		g := NewWithT(t)
+
+		mp := newMachinePool(nil)
+		r := &MachinePoolReconciler{
+			Client: fake.NewClientBuilder().WithObjects(mp, infraConfig.DeepCopy(), bootstrapConfig.DeepCopy()).Build(),
+		}
+
+		_, err := r.reconcileAutoscalerAnnotations(ctx, cluster, mp)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(mp.Annotations).To(BeEmpty())
+	})
+}
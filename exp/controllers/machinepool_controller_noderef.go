@@ -66,8 +66,9 @@ func (r *MachinePoolReconciler) reconcileNodeRefs(ctx context.Context, cluster *
 
 	log = log.WithValues("cluster", cluster.Name)
 
-	// Check that the MachinePool has valid ProviderIDList.
-	if len(mp.Spec.ProviderIDList) == 0 {
+	// Check that the MachinePool has valid ProviderIDList; a MachinePool scaled to zero has none.
+	scaledToZero := mp.Spec.Replicas != nil && *mp.Spec.Replicas == 0
+	if len(mp.Spec.ProviderIDList) == 0 && !scaledToZero {
 		log.V(2).Info("MachinePool doesn't have any ProviderIDs yet")
 		return ctrl.Result{}, nil
 	}
@@ -81,6 +82,16 @@ func (r *MachinePoolReconciler) reconcileNodeRefs(ctx context.Context, cluster *
 		return ctrl.Result{}, err
 	}
 
+	// If the MachinePool has been scaled to zero, all the Nodes are retired.
+	if len(mp.Spec.ProviderIDList) == 0 {
+		mp.Status.ReadyReplicas = 0
+		mp.Status.AvailableReplicas = 0
+		mp.Status.UnavailableReplicas = 0
+		mp.Status.NodeRefs = nil
+		conditions.MarkTrue(mp, expv1.ReplicasReadyCondition)
+		return ctrl.Result{}, nil
+	}
+
 	// Get the Node references.
 	nodeRefsResult, err := r.getNodeReferences(ctx, clusterClient, mp.Spec.ProviderIDList)
 	if err != nil {
@@ -155,7 +166,7 @@ func (r *MachinePoolReconciler) deleteRetiredNodes(ctx context.Context, c client
 			continue
 		}
 
-		nodeRefsMap[nodeProviderID.ID()] = node
+		nodeRefsMap[nodeProviderID.IndexKey()] = node
 	}
 	for _, providerID := range providerIDList {
 		pid, err := noderefutil.NewProviderID(providerID)
@@ -163,7 +174,7 @@ func (r *MachinePoolReconciler) deleteRetiredNodes(ctx context.Context, c client
 			log.V(2).Info("Failed to parse ProviderID, skipping", "err", err, "providerID", providerID)
 			continue
 		}
-		delete(nodeRefsMap, pid.ID())
+		delete(nodeRefsMap, pid.IndexKey())
 	}
 	for _, node := range nodeRefsMap {
 		if err := c.Delete(ctx, node); err != nil {
@@ -191,7 +202,7 @@ func (r *MachinePoolReconciler) getNodeReferences(ctx context.Context, c client.
 				continue
 			}
 
-			nodeRefsMap[nodeProviderID.ID()] = node
+			nodeRefsMap[nodeProviderID.IndexKey()] = node
 		}
 
 		if nodeList.Continue == "" {
@@ -206,7 +217,7 @@ func (r *MachinePoolReconciler) getNodeReferences(ctx context.Context, c client.
 			log.V(2).Info("Failed to parse ProviderID, skipping", "err", err, "providerID", providerID)
 			continue
 		}
-		if node, ok := nodeRefsMap[pid.ID()]; ok {
+		if node, ok := nodeRefsMap[pid.IndexKey()]; ok {
 			available++
 			if nodeIsReady(&node) {
 				ready++
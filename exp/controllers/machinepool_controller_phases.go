@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"strings"
 	"time"
 
 	"sigs.k8s.io/cluster-api/util"
@@ -37,7 +38,6 @@ import (
 	"sigs.k8s.io/cluster-api/util/annotations"
 	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/patch"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
@@ -107,27 +107,13 @@ func (r *MachinePoolReconciler) reconcileExternal(ctx context.Context, cluster *
 		return external.ReconcileOutput{Paused: true}, nil
 	}
 
-	// Initialize the patch helper.
-	patchHelper, err := patch.NewHelper(obj, r.Client)
-	if err != nil {
-		return external.ReconcileOutput{}, err
-	}
-
-	// Set external object ControllerReference to the MachinePool.
-	if err := controllerutil.SetControllerReference(m, obj, r.Client.Scheme()); err != nil {
-		return external.ReconcileOutput{}, err
-	}
-
-	// Set the Cluster label.
-	labels := obj.GetLabels()
-	if labels == nil {
-		labels = make(map[string]string)
-	}
-	labels[clusterv1.ClusterLabelName] = m.Spec.ClusterName
-	obj.SetLabels(labels)
-
-	// Always attempt to Patch the external object.
-	if err := patchHelper.Patch(ctx, obj); err != nil {
+	// Set the external object ControllerReference and Cluster label, and always attempt to Patch it.
+	if err := external.PatchExternal(ctx, &external.PatchExternalInput{
+		Client:      r.Client,
+		Object:      obj,
+		Owner:       m,
+		ClusterName: m.Spec.ClusterName,
+	}); err != nil {
 		return external.ReconcileOutput{}, err
 	}
 
@@ -212,7 +198,7 @@ func (r *MachinePoolReconciler) reconcileBootstrap(ctx context.Context, cluster
 	}
 
 	// Get and set the name of the secret containing the bootstrap data.
-	secretName, _, err := unstructured.NestedString(bootstrapConfig.Object, "status", "dataSecretName")
+	secretName, err := external.DataSecretNameFrom(bootstrapConfig)
 	if err != nil {
 		return ctrl.Result{}, errors.Wrapf(err, "failed to retrieve dataSecretName from bootstrap provider for MachinePool %q in namespace %q", m.Name, m.Namespace)
 	} else if secretName == "" {
@@ -254,6 +240,10 @@ func (r *MachinePoolReconciler) reconcileInfrastructure(ctx context.Context, clu
 		return ctrl.Result{}, nil
 	}
 
+	if err := r.reconcileFailureDomains(ctx, cluster, mp, infraConfig); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	ready, err := external.IsReady(infraConfig)
 	if err != nil {
 		return ctrl.Result{}, err
@@ -272,25 +262,41 @@ func (r *MachinePoolReconciler) reconcileInfrastructure(ctx context.Context, clu
 		return ctrl.Result{RequeueAfter: externalReadyWait}, nil
 	}
 
+	// Get and set Status.Replicas from the infrastructure provider.
+	err = util.UnstructuredUnmarshalField(infraConfig, &mp.Status.Replicas, "status", "replicas")
+	if err != nil && err != util.ErrUnstructuredFieldNotFound {
+		return ctrl.Result{}, errors.Wrapf(err, "failed to retrieve replicas from infrastructure provider for MachinePool %q in namespace %q", mp.Name, mp.Namespace)
+	}
+	replicasReported := err == nil
+
+	// If the replicas are managed externally, e.g. by an autoscaling group of the infrastructure provider,
+	// Spec.Replicas follows the infrastructure provider.
+	if replicasReported && isReplicasManagedExternally(mp) {
+		mp.Spec.Replicas = pointer.Int32Ptr(mp.Status.Replicas)
+	}
+
+	// A MachinePool scaled to zero has no instances, so there are no replicas and ProviderIDs to wait for.
+	scaledToZero := mp.Spec.Replicas != nil && *mp.Spec.Replicas == 0
+	if replicasReported && mp.Status.Replicas == 0 && !scaledToZero {
+		log.Info("Retrieved unset Status.Replicas from infrastructure provider")
+		return ctrl.Result{RequeueAfter: externalReadyWait}, nil
+	}
+
 	var providerIDList []string
 	// Get Spec.ProviderIDList from the infrastructure provider.
-	if err := util.UnstructuredUnmarshalField(infraConfig, &providerIDList, "spec", "providerIDList"); err != nil {
+	if err := util.UnstructuredUnmarshalField(infraConfig, &providerIDList, "spec", "providerIDList"); err != nil && err != util.ErrUnstructuredFieldNotFound {
 		return ctrl.Result{}, errors.Wrapf(err, "failed to retrieve data from infrastructure provider for MachinePool %q in namespace %q", mp.Name, mp.Namespace)
-	} else if len(providerIDList) == 0 {
+	} else if len(providerIDList) == 0 && !scaledToZero {
 		log.Info("Retrieved empty Spec.ProviderIDList from infrastructure provider")
 		return ctrl.Result{RequeueAfter: externalReadyWait}, nil
 	}
 
-	// Get and set Status.Replicas from the infrastructure provider.
-	err = util.UnstructuredUnmarshalField(infraConfig, &mp.Status.Replicas, "status", "replicas")
-	if err != nil {
-		if err != util.ErrUnstructuredFieldNotFound {
-			return ctrl.Result{}, errors.Wrapf(err, "failed to retrieve replicas from infrastructure provider for MachinePool %q in namespace %q", mp.Name, mp.Namespace)
-		}
-	} else if mp.Status.Replicas == 0 {
-		log.Info("Retrieved unset Status.Replicas from infrastructure provider")
-		return ctrl.Result{RequeueAfter: externalReadyWait}, nil
+	// Get and set Status.FailureDomains from the infrastructure provider, if reported.
+	var failureDomains []expv1.FailureDomainReplicas
+	if err := util.UnstructuredUnmarshalField(infraConfig, &failureDomains, "status", "failureDomains"); err != nil && err != util.ErrUnstructuredFieldNotFound {
+		return ctrl.Result{}, errors.Wrapf(err, "failed to retrieve failure domains from infrastructure provider for MachinePool %q in namespace %q", mp.Name, mp.Namespace)
 	}
+	mp.Status.FailureDomains = failureDomains
 
 	if !reflect.DeepEqual(mp.Spec.ProviderIDList, providerIDList) {
 		mp.Spec.ProviderIDList = providerIDList
@@ -301,3 +307,65 @@ func (r *MachinePoolReconciler) reconcileInfrastructure(ctx context.Context, clu
 
 	return ctrl.Result{}, nil
 }
+
+// isReplicasManagedExternally returns true if the MachinePool replicas are managed by an external entity.
+func isReplicasManagedExternally(mp *expv1.MachinePool) bool {
+	_, ok := mp.Annotations[expv1.ReplicasManagedByAnnotation]
+	return ok
+}
+
+// reconcileFailureDomains validates the MachinePool failure domains against the failure domains defined in the Cluster,
+// and passes them to the infrastructure machine pool as spec.failureDomains.
+// NOTE: Invalid failure domains are not passed to the infrastructure provider, which keeps using the last valid ones.
+// Failure domains are not passed to infrastructure machine pools not defining spec.failureDomains either, because the
+// API server would prune the field.
+func (r *MachinePoolReconciler) reconcileFailureDomains(ctx context.Context, cluster *clusterv1.Cluster, mp *expv1.MachinePool, infraConfig *unstructured.Unstructured) error {
+	if len(mp.Spec.FailureDomains) == 0 {
+		conditions.Delete(mp, expv1.FailureDomainsValidCondition)
+		return nil
+	}
+
+	invalid := []string{}
+	for _, fd := range mp.Spec.FailureDomains {
+		if _, ok := cluster.Status.FailureDomains[fd]; !ok {
+			invalid = append(invalid, fd)
+		}
+	}
+	if len(invalid) > 0 {
+		conditions.MarkFalse(mp, expv1.FailureDomainsValidCondition, expv1.InvalidFailureDomainsReason, clusterv1.ConditionSeverityWarning,
+			"Failure domains %s are not defined in Cluster %s", strings.Join(invalid, ", "), cluster.Name)
+		return nil
+	}
+
+	current, _, err := unstructured.NestedStringSlice(infraConfig.Object, "spec", "failureDomains")
+	if err != nil {
+		return errors.Wrapf(err, "failed to retrieve failure domains from infrastructure provider for MachinePool %q in namespace %q", mp.Name, mp.Namespace)
+	}
+	if reflect.DeepEqual(current, mp.Spec.FailureDomains) {
+		conditions.MarkTrue(mp, expv1.FailureDomainsValidCondition)
+		return nil
+	}
+
+	crd, err := util.GetCRDWithContract(ctx, r.Client, infraConfig.GroupVersionKind(), clusterv1.GroupVersion.String())
+	if err != nil {
+		return err
+	}
+	if !util.CRDVersionHasField(crd, infraConfig.GroupVersionKind().Version, "spec", "failureDomains") {
+		conditions.MarkFalse(mp, expv1.FailureDomainsValidCondition, expv1.FailureDomainsNotSupportedReason, clusterv1.ConditionSeverityWarning,
+			"%s %s does not support spec.failureDomains", infraConfig.GetKind(), infraConfig.GetName())
+		return nil
+	}
+	conditions.MarkTrue(mp, expv1.FailureDomainsValidCondition)
+
+	patchHelper, err := patch.NewHelper(infraConfig, r.Client)
+	if err != nil {
+		return err
+	}
+	if err := unstructured.SetNestedStringSlice(infraConfig.Object, mp.Spec.FailureDomains, "spec", "failureDomains"); err != nil {
+		return errors.Wrapf(err, "failed to set failure domains on infrastructure provider for MachinePool %q in namespace %q", mp.Name, mp.Namespace)
+	}
+	if err := patchHelper.Patch(ctx, infraConfig); err != nil {
+		return errors.Wrapf(err, "failed to patch failure domains on infrastructure provider for MachinePool %q in namespace %q", mp.Name, mp.Namespace)
+	}
+	return nil
+}
@@ -23,14 +23,17 @@ import (
 	. "github.com/onsi/gomega"
 
 	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/utils/pointer"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 	expv1 "sigs.k8s.io/cluster-api/exp/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/kubeconfig"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
@@ -888,6 +891,70 @@ func TestReconcileMachinePoolInfrastructure(t *testing.T) {
 				g.Expect(m.Status.InfrastructureReady).To(BeFalse())
 			},
 		},
+		{
+			name: "machinepool scaled to zero, no ProviderIDs",
+			machinepool: func() *expv1.MachinePool {
+				mp := defaultMachinePool.DeepCopy()
+				mp.Spec.Replicas = pointer.Int32Ptr(0)
+				mp.Spec.ProviderIDList = []string{"test://id-1"}
+				mp.Status.Replicas = 1
+				mp.Status.ReadyReplicas = 1
+				return mp
+			}(),
+			infraConfig: map[string]interface{}{
+				"kind":       "InfrastructureConfig",
+				"apiVersion": "infrastructure.cluster.x-k8s.io/v1alpha4",
+				"metadata": map[string]interface{}{
+					"name":      "infra-config1",
+					"namespace": "default",
+				},
+				"spec": map[string]interface{}{},
+				"status": map[string]interface{}{
+					"ready":    true,
+					"replicas": int64(0),
+				},
+			},
+			expectError: false,
+			expected: func(g *WithT, m *expv1.MachinePool) {
+				g.Expect(m.Status.InfrastructureReady).To(BeTrue())
+				g.Expect(m.Spec.ProviderIDList).To(BeEmpty())
+				g.Expect(m.Status.Replicas).To(Equal(int32(0)))
+				g.Expect(m.Status.ReadyReplicas).To(Equal(int32(0)))
+				g.Expect(m.Status.GetTypedPhase()).To(Equal(expv1.MachinePoolPhaseRunning))
+			},
+		},
+		{
+			name: "replicas managed externally, spec.replicas follows the infrastructure provider",
+			machinepool: func() *expv1.MachinePool {
+				mp := defaultMachinePool.DeepCopy()
+				mp.Annotations = map[string]string{expv1.ReplicasManagedByAnnotation: "external-autoscaler"}
+				return mp
+			}(),
+			infraConfig: map[string]interface{}{
+				"kind":       "InfrastructureConfig",
+				"apiVersion": "infrastructure.cluster.x-k8s.io/v1alpha4",
+				"metadata": map[string]interface{}{
+					"name":      "infra-config1",
+					"namespace": "default",
+				},
+				"spec": map[string]interface{}{
+					"providerIDList": []interface{}{
+						"test://id-1",
+						"test://id-2",
+						"test://id-3",
+					},
+				},
+				"status": map[string]interface{}{
+					"ready":    true,
+					"replicas": int64(3),
+				},
+			},
+			expectError: false,
+			expected: func(g *WithT, m *expv1.MachinePool) {
+				g.Expect(*m.Spec.Replicas).To(Equal(int32(3)))
+				g.Expect(m.Spec.ProviderIDList).To(HaveLen(3))
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -920,3 +987,155 @@ func TestReconcileMachinePoolInfrastructure(t *testing.T) {
 		})
 	}
 }
+
+func TestReconcileMachinePoolFailureDomains(t *testing.T) {
+	defaultCluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster",
+			Namespace: "default",
+		},
+		Status: clusterv1.ClusterStatus{
+			FailureDomains: clusterv1.FailureDomains{
+				"fd1": clusterv1.FailureDomainSpec{},
+				"fd2": clusterv1.FailureDomainSpec{},
+			},
+		},
+	}
+
+	infraConfig := map[string]interface{}{
+		"kind":       "InfrastructureConfig",
+		"apiVersion": "infrastructure.cluster.x-k8s.io/v1alpha4",
+		"metadata": map[string]interface{}{
+			"name":      "infra-config1",
+			"namespace": "default",
+		},
+		"spec": map[string]interface{}{
+			"providerIDList": []interface{}{
+				"test://id-1",
+				"test://id-2",
+			},
+		},
+		"status": map[string]interface{}{
+			"ready":    true,
+			"replicas": int64(2),
+			"failureDomains": []interface{}{
+				map[string]interface{}{"name": "fd1", "replicas": int64(1)},
+				map[string]interface{}{"name": "fd2", "replicas": int64(1)},
+			},
+		},
+	}
+
+	infraCRD := func(specProperties map[string]apiextensionsv1.JSONSchemaProps) *apiextensionsv1.CustomResourceDefinition {
+		return &apiextensionsv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "infrastructureconfigs.infrastructure.cluster.x-k8s.io",
+				Labels: map[string]string{clusterv1.GroupVersion.String(): "v1alpha4"},
+			},
+			Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+				Group: "infrastructure.cluster.x-k8s.io",
+				Names: apiextensionsv1.CustomResourceDefinitionNames{Kind: "InfrastructureConfig"},
+				Versions: []apiextensionsv1.CustomResourceDefinitionVersion{{
+					Name: "v1alpha4",
+					Schema: &apiextensionsv1.CustomResourceValidation{
+						OpenAPIV3Schema: &apiextensionsv1.JSONSchemaProps{
+							Properties: map[string]apiextensionsv1.JSONSchemaProps{
+								"spec": {Properties: specProperties},
+							},
+						},
+					},
+				}},
+			},
+		}
+	}
+
+	testCases := []struct {
+		name                 string
+		failureDomains       []string
+		infraCRD             *apiextensionsv1.CustomResourceDefinition
+		expectInfraDomains   []string
+		expectConditionValid *bool
+	}{
+		{
+			name:                 "no failure domains",
+			failureDomains:       nil,
+			infraCRD:             infraCRD(map[string]apiextensionsv1.JSONSchemaProps{"failureDomains": {}}),
+			expectInfraDomains:   nil,
+			expectConditionValid: nil,
+		},
+		{
+			name:                 "valid failure domains are passed to the infrastructure provider",
+			failureDomains:       []string{"fd1", "fd2"},
+			infraCRD:             infraCRD(map[string]apiextensionsv1.JSONSchemaProps{"failureDomains": {}}),
+			expectInfraDomains:   []string{"fd1", "fd2"},
+			expectConditionValid: pointer.BoolPtr(true),
+		},
+		{
+			name:                 "invalid failure domains are not passed to the infrastructure provider",
+			failureDomains:       []string{"fd1", "fd3"},
+			infraCRD:             infraCRD(map[string]apiextensionsv1.JSONSchemaProps{"failureDomains": {}}),
+			expectInfraDomains:   nil,
+			expectConditionValid: pointer.BoolPtr(false),
+		},
+		{
+			name:                 "failure domains are not passed to infrastructure providers not defining them",
+			failureDomains:       []string{"fd1", "fd2"},
+			infraCRD:             infraCRD(nil),
+			expectInfraDomains:   nil,
+			expectConditionValid: pointer.BoolPtr(false),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			machinepool := &expv1.MachinePool{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "machinepool-test",
+					Namespace: "default",
+				},
+				Spec: expv1.MachinePoolSpec{
+					Replicas: pointer.Int32Ptr(2),
+					Template: clusterv1.MachineTemplateSpec{
+						Spec: clusterv1.MachineSpec{
+							InfrastructureRef: corev1.ObjectReference{
+								APIVersion: "infrastructure.cluster.x-k8s.io/v1alpha4",
+								Kind:       "InfrastructureConfig",
+								Name:       "infra-config1",
+							},
+						},
+					},
+					FailureDomains: tc.failureDomains,
+				},
+			}
+
+			infra := &unstructured.Unstructured{Object: infraConfig}
+			c := fake.NewClientBuilder().WithObjects(machinepool, infra.DeepCopy(), tc.infraCRD).Build()
+			r := &MachinePoolReconciler{
+				Client: c,
+			}
+
+			_, err := r.reconcileInfrastructure(ctx, defaultCluster, machinepool)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			g.Expect(machinepool.Status.FailureDomains).To(Equal([]expv1.FailureDomainReplicas{
+				{Name: "fd1", Replicas: 1},
+				{Name: "fd2", Replicas: 1},
+			}))
+
+			if tc.expectConditionValid == nil {
+				g.Expect(conditions.Has(machinepool, expv1.FailureDomainsValidCondition)).To(BeFalse())
+			} else {
+				g.Expect(conditions.IsTrue(machinepool, expv1.FailureDomainsValidCondition)).To(Equal(*tc.expectConditionValid))
+			}
+
+			updatedInfra := &unstructured.Unstructured{}
+			updatedInfra.SetAPIVersion("infrastructure.cluster.x-k8s.io/v1alpha4")
+			updatedInfra.SetKind("InfrastructureConfig")
+			g.Expect(c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "infra-config1"}, updatedInfra)).To(Succeed())
+			infraDomains, _, err := unstructured.NestedStringSlice(updatedInfra.Object, "spec", "failureDomains")
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(infraDomains).To(Equal(tc.expectInfraDomains))
+		})
+	}
+}
@@ -0,0 +1,212 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/controllers/external"
+	ipamv1 "sigs.k8s.io/cluster-api/exp/ipam/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/annotations"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/cluster-api/util/predicates"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// +kubebuilder:rbac:groups=ipam.cluster.x-k8s.io,resources=ipaddressclaims;ipaddressclaims/status,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=ipam.cluster.x-k8s.io,resources=ipaddresses,verbs=get;list;watch
+
+// IPAddressClaimReconciler reconciles the lifecycle of IPAddressClaim objects created by infrastructure providers,
+// while the IP addresses are allocated by IPAM providers.
+type IPAddressClaimReconciler struct {
+	Client           client.Client
+	WatchFilterValue string
+}
+
+func (r *IPAddressClaimReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, options controller.Options) error {
+	_, err := ctrl.NewControllerManagedBy(mgr).
+		For(&ipamv1.IPAddressClaim{}).
+		Watches(
+			&source.Kind{Type: &ipamv1.IPAddress{}},
+			handler.EnqueueRequestsFromMapFunc(r.ipAddressToIPAddressClaim),
+		).
+		WithOptions(options).
+		WithEventFilter(predicates.ResourceNotPausedAndHasFilterLabel(ctrl.LoggerFrom(ctx), r.WatchFilterValue)).
+		Build(r)
+	if err != nil {
+		return errors.Wrap(err, "failed setting up with a controller manager")
+	}
+
+	return nil
+}
+
+func (r *IPAddressClaimReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, reterr error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	// Fetch the IPAddressClaim instance.
+	claim := &ipamv1.IPAddressClaim{}
+	if err := r.Client.Get(ctx, req.NamespacedName, claim); err != nil {
+		if apierrors.IsNotFound(err) {
+			// Object not found, return. Created objects are automatically garbage collected.
+			return ctrl.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		return ctrl.Result{}, err
+	}
+
+	// The IP address is released by the IPAM provider when the claim is deleted.
+	if !claim.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	// Initialize the patch helper.
+	patchHelper, err := patch.NewHelper(claim, r.Client)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	defer func() {
+		// Always attempt to patch the object and status after each reconciliation.
+		if err := patchHelper.Patch(ctx, claim, patch.WithOwnedConditions{Conditions: []clusterv1.ConditionType{
+			ipamv1.AddressAllocatedCondition,
+		}}); err != nil {
+			reterr = kerrors.NewAggregate([]error{reterr, err})
+		}
+	}()
+
+	// Link the claim to the Machine owning the infrastructure object the claim has been created for.
+	machine, err := r.getMachine(ctx, claim)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if machine != nil {
+		if claim.Labels == nil {
+			claim.Labels = map[string]string{}
+		}
+		claim.Labels[clusterv1.ClusterLabelName] = machine.Spec.ClusterName
+		claim.OwnerReferences = util.EnsureOwnerRef(claim.OwnerReferences, metav1.OwnerReference{
+			APIVersion: clusterv1.GroupVersion.String(),
+			Kind:       "Machine",
+			Name:       machine.Name,
+			UID:        machine.UID,
+		})
+	}
+
+	// Return early if the claim or the Cluster is paused, e.g. during clusterctl move.
+	if clusterName, ok := claim.Labels[clusterv1.ClusterLabelName]; ok {
+		cluster, err := util.GetClusterByName(ctx, r.Client, claim.Namespace, clusterName)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		if cluster != nil && annotations.IsPaused(cluster, claim) {
+			log.Info("Reconciliation is paused for this object")
+			return ctrl.Result{}, nil
+		}
+	}
+
+	return ctrl.Result{}, r.reconcileAddress(ctx, claim)
+}
+
+// getMachine returns the Machine owning the object the IPAddressClaim is controlled by, if any.
+func (r *IPAddressClaimReconciler) getMachine(ctx context.Context, claim *ipamv1.IPAddressClaim) (*clusterv1.Machine, error) {
+	// If the claim is already linked to the Machine, there is no need to look at the owner chain.
+	ownerMeta := claim.ObjectMeta
+	if !util.HasOwner(claim.OwnerReferences, clusterv1.GroupVersion.String(), []string{"Machine"}) {
+		ownerRef := metav1.GetControllerOf(claim)
+		if ownerRef == nil {
+			return nil, nil
+		}
+		owner, err := external.Get(ctx, r.Client, &corev1.ObjectReference{
+			APIVersion: ownerRef.APIVersion,
+			Kind:       ownerRef.Kind,
+			Name:       ownerRef.Name,
+		}, claim.Namespace)
+		if err != nil {
+			if apierrors.IsNotFound(errors.Cause(err)) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		ownerMeta = metav1.ObjectMeta{Namespace: owner.GetNamespace(), OwnerReferences: owner.GetOwnerReferences()}
+	}
+
+	machine, err := util.GetOwnerMachine(ctx, r.Client, ownerMeta)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "failed to get the Machine for IPAddressClaim %s", util.ObjectKey(claim))
+	}
+	return machine, nil
+}
+
+// reconcileAddress sets the AddressAllocatedCondition according to the IPAddress referenced by the claim.
+func (r *IPAddressClaimReconciler) reconcileAddress(ctx context.Context, claim *ipamv1.IPAddressClaim) error {
+	if claim.Status.AddressRef.Name == "" {
+		// NOTE: IPAM providers can report why an address can't be allocated, e.g. an exhausted pool, using the same condition;
+		// in this case the condition is preserved.
+		if !conditions.IsFalse(claim, ipamv1.AddressAllocatedCondition) {
+			conditions.MarkFalse(claim, ipamv1.AddressAllocatedCondition, ipamv1.WaitingForAddressReason, clusterv1.ConditionSeverityInfo, "")
+		}
+		return nil
+	}
+
+	address := &ipamv1.IPAddress{}
+	key := client.ObjectKey{Namespace: claim.Namespace, Name: claim.Status.AddressRef.Name}
+	if err := r.Client.Get(ctx, key, address); err != nil {
+		if apierrors.IsNotFound(err) {
+			conditions.MarkFalse(claim, ipamv1.AddressAllocatedCondition, ipamv1.AddressNotFoundReason, clusterv1.ConditionSeverityWarning, "IPAddress %s does not exist", key.Name)
+			return nil
+		}
+		return errors.Wrapf(err, "failed to get IPAddress %s", key)
+	}
+	if address.Spec.ClaimRef.Name != claim.Name {
+		conditions.MarkFalse(claim, ipamv1.AddressAllocatedCondition, ipamv1.AddressNotFoundReason, clusterv1.ConditionSeverityWarning, "IPAddress %s is allocated for IPAddressClaim %s", key.Name, address.Spec.ClaimRef.Name)
+		return nil
+	}
+
+	conditions.MarkTrue(claim, ipamv1.AddressAllocatedCondition)
+	return nil
+}
+
+// ipAddressToIPAddressClaim is a mapper function that maps an IPAddress to the IPAddressClaim it was allocated for.
+func (r *IPAddressClaimReconciler) ipAddressToIPAddressClaim(o client.Object) []ctrl.Request {
+	address, ok := o.(*ipamv1.IPAddress)
+	if !ok || address.Spec.ClaimRef.Name == "" {
+		return nil
+	}
+	return []ctrl.Request{
+		{
+			NamespacedName: client.ObjectKey{
+				Namespace: address.Namespace,
+				Name:      address.Spec.ClaimRef.Name,
+			},
+		},
+	}
+}
@@ -0,0 +1,164 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	ipamv1 "sigs.k8s.io/cluster-api/exp/ipam/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestIPAddressClaimReconciler(t *testing.T) {
+	ctx := context.Background()
+
+	scheme := runtime.NewScheme()
+	_ = clusterv1.AddToScheme(scheme)
+	_ = ipamv1.AddToScheme(scheme)
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: metav1.NamespaceDefault},
+	}
+	machine := &clusterv1.Machine{
+		TypeMeta:   metav1.TypeMeta{APIVersion: clusterv1.GroupVersion.String(), Kind: "Machine"},
+		ObjectMeta: metav1.ObjectMeta{Name: "test-machine", Namespace: metav1.NamespaceDefault, UID: "machine-uid"},
+		Spec:       clusterv1.MachineSpec{ClusterName: cluster.Name},
+	}
+	infraMachine := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"kind":       "InfrastructureMachine",
+			"apiVersion": "infrastructure.cluster.x-k8s.io/v1alpha4",
+			"metadata": map[string]interface{}{
+				"name":      "test-infra-machine",
+				"namespace": metav1.NamespaceDefault,
+				"uid":       "infra-machine-uid",
+			},
+		},
+	}
+	infraMachine.SetOwnerReferences([]metav1.OwnerReference{
+		{APIVersion: clusterv1.GroupVersion.String(), Kind: "Machine", Name: machine.Name, UID: machine.UID},
+	})
+
+	newClaim := func() *ipamv1.IPAddressClaim {
+		return &ipamv1.IPAddressClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-claim",
+				Namespace: metav1.NamespaceDefault,
+				OwnerReferences: []metav1.OwnerReference{
+					{
+						APIVersion: infraMachine.GetAPIVersion(),
+						Kind:       infraMachine.GetKind(),
+						Name:       infraMachine.GetName(),
+						UID:        infraMachine.GetUID(),
+						Controller: pointer.BoolPtr(true),
+					},
+				},
+			},
+			Spec: ipamv1.IPAddressClaimSpec{
+				PoolRef: corev1.TypedLocalObjectReference{APIGroup: pointer.StringPtr("ipam.example.com"), Kind: "Pool", Name: "test-pool"},
+			},
+		}
+	}
+	newAddress := func(claimName string) *ipamv1.IPAddress {
+		return &ipamv1.IPAddress{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-address", Namespace: metav1.NamespaceDefault},
+			Spec: ipamv1.IPAddressSpec{
+				ClaimRef: corev1.LocalObjectReference{Name: claimName},
+				PoolRef:  corev1.TypedLocalObjectReference{APIGroup: pointer.StringPtr("ipam.example.com"), Kind: "Pool", Name: "test-pool"},
+				Address:  "10.0.0.10",
+				Prefix:   24,
+			},
+		}
+	}
+
+	reconcile := func(g *WithT, objs ...client.Object) *ipamv1.IPAddressClaim {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+		r := &IPAddressClaimReconciler{Client: c}
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKey{Namespace: metav1.NamespaceDefault, Name: "test-claim"}})
+		g.Expect(err).NotTo(HaveOccurred())
+
+		claim := &ipamv1.IPAddressClaim{}
+		g.Expect(c.Get(ctx, client.ObjectKey{Namespace: metav1.NamespaceDefault, Name: "test-claim"}, claim)).To(Succeed())
+		return claim
+	}
+
+	t.Run("links the claim to the Machine owning the infrastructure machine", func(t *testing.T) {
+		g := NewWithT(t)
+
+		claim := reconcile(g, cluster.DeepCopy(), machine.DeepCopy(), infraMachine.DeepCopy(), newClaim())
+
+		g.Expect(claim.Labels).To(HaveKeyWithValue(clusterv1.ClusterLabelName, cluster.Name))
+		g.Expect(util.IsOwnedByObject(claim, machine)).To(BeTrue())
+		g.Expect(claim.OwnerReferences).To(HaveLen(2))
+	})
+
+	t.Run("waits for the IPAM provider to allocate an address", func(t *testing.T) {
+		g := NewWithT(t)
+
+		claim := reconcile(g, cluster.DeepCopy(), machine.DeepCopy(), infraMachine.DeepCopy(), newClaim())
+
+		g.Expect(conditions.IsFalse(claim, ipamv1.AddressAllocatedCondition)).To(BeTrue())
+		g.Expect(conditions.GetReason(claim, ipamv1.AddressAllocatedCondition)).To(Equal(ipamv1.WaitingForAddressReason))
+	})
+
+	t.Run("marks the address as allocated when the referenced address exists", func(t *testing.T) {
+		g := NewWithT(t)
+
+		claim := newClaim()
+		claim.Status.AddressRef.Name = "test-address"
+
+		claim = reconcile(g, cluster.DeepCopy(), machine.DeepCopy(), infraMachine.DeepCopy(), claim, newAddress("test-claim"))
+
+		g.Expect(conditions.IsTrue(claim, ipamv1.AddressAllocatedCondition)).To(BeTrue())
+	})
+
+	t.Run("reports addresses allocated for another claim", func(t *testing.T) {
+		g := NewWithT(t)
+
+		claim := newClaim()
+		claim.Status.AddressRef.Name = "test-address"
+
+		claim = reconcile(g, cluster.DeepCopy(), machine.DeepCopy(), infraMachine.DeepCopy(), claim, newAddress("another-claim"))
+
+		g.Expect(conditions.IsFalse(claim, ipamv1.AddressAllocatedCondition)).To(BeTrue())
+		g.Expect(conditions.GetReason(claim, ipamv1.AddressAllocatedCondition)).To(Equal(ipamv1.AddressNotFoundReason))
+	})
+
+	t.Run("does not reconcile the address when the cluster is paused", func(t *testing.T) {
+		g := NewWithT(t)
+
+		pausedCluster := cluster.DeepCopy()
+		pausedCluster.Spec.Paused = true
+
+		claim := reconcile(g, pausedCluster, machine.DeepCopy(), infraMachine.DeepCopy(), newClaim())
+
+		g.Expect(claim.Labels).To(HaveKeyWithValue(clusterv1.ClusterLabelName, cluster.Name))
+		g.Expect(conditions.Has(claim, ipamv1.AddressAllocatedCondition)).To(BeFalse())
+	})
+}
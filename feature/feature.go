@@ -44,6 +44,11 @@ const (
 	//
 	// alpha: v0.4
 	ClusterTopology featuregate.Feature = "ClusterTopology"
+
+	// IPAM is a feature gate for the IPAddressClaim and IPAddress functionality.
+	//
+	// alpha: v0.4
+	IPAM featuregate.Feature = "IPAM"
 )
 
 func init() {
@@ -57,4 +62,5 @@ var defaultClusterAPIFeatureGates = map[featuregate.Feature]featuregate.FeatureS
 	MachinePool:        {Default: false, PreRelease: featuregate.Alpha},
 	ClusterResourceSet: {Default: true, PreRelease: featuregate.Beta},
 	ClusterTopology:    {Default: false, PreRelease: featuregate.Alpha},
+	IPAM:               {Default: false, PreRelease: featuregate.Alpha},
 }
@@ -0,0 +1,206 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package webhooks implements admission webhooks shared by the Cluster API objects.
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// UnknownFieldsValidationMode defines how the objects applied with unknown fields are handled.
+type UnknownFieldsValidationMode string
+
+const (
+	// UnknownFieldsValidationIgnore admits the objects applied with unknown fields; the fields are
+	// silently pruned by the API server, which is the default behavior.
+	UnknownFieldsValidationIgnore = UnknownFieldsValidationMode("Ignore")
+
+	// UnknownFieldsValidationWarn admits the objects applied with unknown fields, returning a warning
+	// to the client for each of the fields.
+	UnknownFieldsValidationWarn = UnknownFieldsValidationMode("Warn")
+
+	// UnknownFieldsValidationDeny rejects the objects applied with unknown fields.
+	UnknownFieldsValidationDeny = UnknownFieldsValidationMode("Deny")
+)
+
+// UnknownFieldsValidationModes are the supported UnknownFieldsValidationMode values.
+var UnknownFieldsValidationModes = []UnknownFieldsValidationMode{
+	UnknownFieldsValidationIgnore,
+	UnknownFieldsValidationWarn,
+	UnknownFieldsValidationDeny,
+}
+
+// UnknownFieldsValidatorPath is the path the UnknownFieldsValidator is served at.
+const UnknownFieldsValidatorPath = "/validate-unknown-fields-cluster-x-k8s-io-v1alpha4"
+
+// +kubebuilder:webhook:verbs=create;update,path=/validate-unknown-fields-cluster-x-k8s-io-v1alpha4,mutating=false,failurePolicy=ignore,matchPolicy=Equivalent,groups=cluster.x-k8s.io,resources=clusters;clusterclasses;machines;machinesets;machinedeployments;machinehealthchecks,versions=v1alpha4,name=validation-unknown-fields.cluster.x-k8s.io,sideEffects=None,admissionReviewVersions=v1;v1beta1
+
+// UnknownFieldsValidator detects the fields of an applied manifest which are not defined by the schema of the
+// object, e.g. typos like spec.replcas, and which are otherwise silently pruned by the API server.
+//
+// The admitted object has already been pruned, so the validator compares it with the manifest recorded by
+// kubectl apply in the kubectl.kubernetes.io/last-applied-configuration annotation: the fields of the manifest
+// missing from the object have been pruned. Objects without the annotation, or applied using a different API
+// version, are not validated; server-side apply rejects unknown fields on its own.
+type UnknownFieldsValidator struct {
+	Mode UnknownFieldsValidationMode
+}
+
+var _ admission.Handler = &UnknownFieldsValidator{}
+
+// Handle validates the objects applied with unknown fields according to the validation mode.
+func (v *UnknownFieldsValidator) Handle(_ context.Context, req admission.Request) admission.Response {
+	if v.Mode == "" || v.Mode == UnknownFieldsValidationIgnore {
+		return admission.Allowed("")
+	}
+
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(req.Object.Raw); err != nil {
+		return admission.Errored(http.StatusBadRequest, errors.Wrap(err, "failed to decode the object"))
+	}
+	lastApplied := obj.GetAnnotations()[corev1.LastAppliedConfigAnnotation]
+	if lastApplied == "" {
+		return admission.Allowed("")
+	}
+
+	// On update, only a new manifest is validated; otherwise an object already applied with unknown fields
+	// would be rejected on any later update, including the updates made by the controllers.
+	if req.Operation == admissionv1.Update {
+		oldObj := &unstructured.Unstructured{}
+		if err := oldObj.UnmarshalJSON(req.OldObject.Raw); err != nil {
+			return admission.Errored(http.StatusBadRequest, errors.Wrap(err, "failed to decode the old object"))
+		}
+		if oldObj.GetAnnotations()[corev1.LastAppliedConfigAnnotation] == lastApplied {
+			return admission.Allowed("")
+		}
+	}
+
+	applied := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(lastApplied), &applied); err != nil {
+		// The annotation is managed by kubectl; if it can't be parsed there is nothing to validate.
+		return admission.Allowed("")
+	}
+	if applied["apiVersion"] != obj.GetAPIVersion() {
+		return admission.Allowed("")
+	}
+
+	fields := UnknownFields(applied, obj.Object)
+	if len(fields) == 0 {
+		return admission.Allowed("")
+	}
+
+	if v.Mode == UnknownFieldsValidationDeny {
+		return admission.Denied(fmt.Sprintf("unknown fields %s: the fields are not defined by the %s %s schema", strings.Join(fields, ", "), obj.GetAPIVersion(), obj.GetKind()))
+	}
+	resp := admission.Allowed("")
+	for _, field := range fields {
+		resp.Warnings = append(resp.Warnings, fmt.Sprintf("unknown field %s: the field is not defined by the %s %s schema and it has been dropped", field, obj.GetAPIVersion(), obj.GetKind()))
+	}
+	return resp
+}
+
+// UnknownFields returns the paths of the fields of the applied manifest which are missing from the object,
+// sorted; status, null values and list items not matching by position are ignored.
+// Zero values, e.g. paused: false or labels: {}, are ignored too, because the defaulting webhooks round-trip
+// the object through the Go types, dropping the zero values of the fields with omitempty.
+func UnknownFields(applied, obj map[string]interface{}) []string {
+	fields := unknownFields(applied, obj, "")
+	sort.Strings(fields)
+	return fields
+}
+
+func unknownFields(applied, obj map[string]interface{}, path string) []string {
+	fields := []string{}
+	for key, appliedValue := range applied {
+		fieldPath := key
+		if path != "" {
+			fieldPath = path + "." + key
+		}
+		if fieldPath == "status" || appliedValue == nil {
+			continue
+		}
+
+		value, ok := obj[key]
+		if !ok {
+			if !isZeroValue(appliedValue) {
+				fields = append(fields, fieldPath)
+			}
+			continue
+		}
+		fields = append(fields, unknownFieldsInValue(appliedValue, value, fieldPath)...)
+	}
+	return fields
+}
+
+func unknownFieldsInValue(applied, obj interface{}, path string) []string {
+	switch appliedValue := applied.(type) {
+	case map[string]interface{}:
+		if value, ok := obj.(map[string]interface{}); ok {
+			return unknownFields(appliedValue, value, path)
+		}
+	case []interface{}:
+		// Items are compared by position, given that the list can't be matched by key without the schema;
+		// lists changed by defaulting are ignored.
+		value, ok := obj.([]interface{})
+		if !ok || len(value) != len(appliedValue) {
+			return nil
+		}
+		fields := []string{}
+		for i := range appliedValue {
+			fields = append(fields, unknownFieldsInValue(appliedValue[i], value[i], fmt.Sprintf("%s[%d]", path, i))...)
+		}
+		return fields
+	}
+	return nil
+}
+
+// isZeroValue returns true if the value is a zero value, or a map containing only zero values.
+func isZeroValue(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case bool:
+		return !v
+	case string:
+		return v == ""
+	case float64:
+		return v == 0
+	case int64:
+		return v == 0
+	case []interface{}:
+		return len(v) == 0
+	case map[string]interface{}:
+		for _, item := range v {
+			if !isZeroValue(item) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
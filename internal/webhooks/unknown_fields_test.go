@@ -0,0 +1,182 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func TestUnknownFields(t *testing.T) {
+	tests := []struct {
+		name    string
+		applied string
+		obj     string
+		want    []string
+	}{
+		{
+			name:    "no unknown fields",
+			applied: `{"apiVersion":"cluster.x-k8s.io/v1alpha4","kind":"MachineDeployment","metadata":{"name":"md","creationTimestamp":null},"spec":{"replicas":3}}`,
+			obj:     `{"apiVersion":"cluster.x-k8s.io/v1alpha4","kind":"MachineDeployment","metadata":{"name":"md","creationTimestamp":"2021-08-01T00:00:00Z"},"spec":{"replicas":3,"minReadySeconds":0}}`,
+			want:    []string{},
+		},
+		{
+			name:    "unknown fields",
+			applied: `{"apiVersion":"cluster.x-k8s.io/v1alpha4","kind":"MachineDeployment","metadata":{"name":"md"},"spec":{"replcas":3,"template":{"spec":{"bootstrap":{"dataSecretName":"foo","typo":true}}}}}`,
+			obj:     `{"apiVersion":"cluster.x-k8s.io/v1alpha4","kind":"MachineDeployment","metadata":{"name":"md"},"spec":{"replicas":1,"template":{"spec":{"bootstrap":{"dataSecretName":"foo"}}}}}`,
+			want:    []string{"spec.replcas", "spec.template.spec.bootstrap.typo"},
+		},
+		{
+			name:    "unknown fields in list items",
+			applied: `{"spec":{"unhealthyConditions":[{"type":"Ready","status":"False","timeout":"5m"}]}}`,
+			obj:     `{"spec":{"unhealthyConditions":[{"type":"Ready","status":"False"}]}}`,
+			want:    []string{"spec.unhealthyConditions[0].timeout"},
+		},
+		{
+			name:    "ignores zero values dropped by the defaulting webhooks",
+			applied: `{"metadata":{"name":"c","labels":{}},"spec":{"paused":false,"clusterNetwork":{"serviceDomain":"","pods":{"cidrBlocks":[]}}}}`,
+			obj:     `{"metadata":{"name":"c"},"spec":{}}`,
+			want:    []string{},
+		},
+		{
+			name:    "ignores status",
+			applied: `{"spec":{},"status":{"foo":"bar"}}`,
+			obj:     `{"spec":{}}`,
+			want:    []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			applied := map[string]interface{}{}
+			g.Expect(json.Unmarshal([]byte(tt.applied), &applied)).To(Succeed())
+			obj := map[string]interface{}{}
+			g.Expect(json.Unmarshal([]byte(tt.obj), &obj)).To(Succeed())
+
+			g.Expect(UnknownFields(applied, obj)).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestUnknownFieldsValidator(t *testing.T) {
+	newObject := func(g *WithT, lastApplied string) runtime.RawExtension {
+		obj := map[string]interface{}{
+			"apiVersion": "cluster.x-k8s.io/v1alpha4",
+			"kind":       "MachineDeployment",
+			"metadata": map[string]interface{}{
+				"name":        "md",
+				"annotations": map[string]interface{}{corev1.LastAppliedConfigAnnotation: lastApplied},
+			},
+			"spec": map[string]interface{}{"replicas": 1},
+		}
+		raw, err := json.Marshal(obj)
+		g.Expect(err).NotTo(HaveOccurred())
+		return runtime.RawExtension{Raw: raw}
+	}
+	withTypo := `{"apiVersion":"cluster.x-k8s.io/v1alpha4","kind":"MachineDeployment","metadata":{"name":"md"},"spec":{"replcas":3}}`
+	withoutTypo := `{"apiVersion":"cluster.x-k8s.io/v1alpha4","kind":"MachineDeployment","metadata":{"name":"md"},"spec":{"replicas":1}}`
+	otherVersion := `{"apiVersion":"cluster.x-k8s.io/v1alpha3","kind":"MachineDeployment","metadata":{"name":"md"},"spec":{"replcas":3}}`
+
+	tests := []struct {
+		name         string
+		mode         UnknownFieldsValidationMode
+		operation    admissionv1.Operation
+		lastApplied  string
+		oldApplied   string
+		wantAllowed  bool
+		wantWarnings int
+	}{
+		{
+			name:        "ignore mode admits unknown fields",
+			mode:        UnknownFieldsValidationIgnore,
+			operation:   admissionv1.Create,
+			lastApplied: withTypo,
+			wantAllowed: true,
+		},
+		{
+			name:         "warn mode admits unknown fields with a warning",
+			mode:         UnknownFieldsValidationWarn,
+			operation:    admissionv1.Create,
+			lastApplied:  withTypo,
+			wantAllowed:  true,
+			wantWarnings: 1,
+		},
+		{
+			name:        "deny mode rejects unknown fields",
+			mode:        UnknownFieldsValidationDeny,
+			operation:   admissionv1.Create,
+			lastApplied: withTypo,
+			wantAllowed: false,
+		},
+		{
+			name:        "deny mode admits manifests without unknown fields",
+			mode:        UnknownFieldsValidationDeny,
+			operation:   admissionv1.Create,
+			lastApplied: withoutTypo,
+			wantAllowed: true,
+		},
+		{
+			name:        "deny mode rejects updates applying unknown fields",
+			mode:        UnknownFieldsValidationDeny,
+			operation:   admissionv1.Update,
+			lastApplied: withTypo,
+			oldApplied:  withoutTypo,
+			wantAllowed: false,
+		},
+		{
+			name:        "deny mode admits updates not changing the applied manifest",
+			mode:        UnknownFieldsValidationDeny,
+			operation:   admissionv1.Update,
+			lastApplied: withTypo,
+			oldApplied:  withTypo,
+			wantAllowed: true,
+		},
+		{
+			name:        "deny mode admits manifests applied using another API version",
+			mode:        UnknownFieldsValidationDeny,
+			operation:   admissionv1.Create,
+			lastApplied: otherVersion,
+			wantAllowed: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			req := admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+				Operation: tt.operation,
+				Object:    newObject(g, tt.lastApplied),
+			}}
+			if tt.operation == admissionv1.Update {
+				req.OldObject = newObject(g, tt.oldApplied)
+			}
+
+			v := &UnknownFieldsValidator{Mode: tt.mode}
+			resp := v.Handle(context.Background(), req)
+			g.Expect(resp.Allowed).To(Equal(tt.wantAllowed))
+			g.Expect(resp.Warnings).To(HaveLen(tt.wantWarnings))
+		})
+	}
+}
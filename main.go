@@ -44,12 +44,16 @@ import (
 	expv1old "sigs.k8s.io/cluster-api/exp/api/v1alpha3"
 	expv1 "sigs.k8s.io/cluster-api/exp/api/v1alpha4"
 	expcontrollers "sigs.k8s.io/cluster-api/exp/controllers"
+	ipamv1 "sigs.k8s.io/cluster-api/exp/ipam/api/v1alpha4"
+	ipamcontrollers "sigs.k8s.io/cluster-api/exp/ipam/controllers"
 	"sigs.k8s.io/cluster-api/feature"
+	"sigs.k8s.io/cluster-api/internal/webhooks"
 	"sigs.k8s.io/cluster-api/version"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -58,25 +62,36 @@ var (
 	setupLog = ctrl.Log.WithName("setup")
 
 	// flags.
-	metricsBindAddr               string
-	enableLeaderElection          bool
-	leaderElectionLeaseDuration   time.Duration
-	leaderElectionRenewDeadline   time.Duration
-	leaderElectionRetryPeriod     time.Duration
-	watchNamespace                string
-	watchFilterValue              string
-	profilerAddress               string
-	clusterConcurrency            int
-	machineConcurrency            int
-	machineSetConcurrency         int
-	machineDeploymentConcurrency  int
-	machinePoolConcurrency        int
-	clusterResourceSetConcurrency int
-	machineHealthCheckConcurrency int
-	syncPeriod                    time.Duration
-	webhookPort                   int
-	webhookCertDir                string
-	healthAddr                    string
+	metricsBindAddr                 string
+	enableLeaderElection            bool
+	leaderElectionLeaseDuration     time.Duration
+	leaderElectionRenewDeadline     time.Duration
+	leaderElectionRetryPeriod       time.Duration
+	watchNamespace                  string
+	watchFilterValue                string
+	profilerAddress                 string
+	clusterConcurrency              int
+	machineConcurrency              int
+	machineSetConcurrency           int
+	machineSetCreationBatchSize     int
+	machineSetCreationBatchInterval time.Duration
+	machineDeploymentConcurrency    int
+	machinePoolConcurrency          int
+	clusterResourceSetConcurrency   int
+	machineHealthCheckConcurrency   int
+	ipAddressClaimConcurrency       int
+	syncPeriod                      time.Duration
+	webhookPort                     int
+	webhookCertDir                  string
+	healthAddr                      string
+	remoteClientQPS                 float32
+	remoteClientBurst               int
+	remoteClientTimeout             time.Duration
+	managementClusterName           string
+	deprecatedAPIVersionsInterval   time.Duration
+	controlPlaneEndpointInterval    time.Duration
+	unknownFieldsValidation         string
+	orphanMachineCleanup            bool
 )
 
 func init() {
@@ -89,6 +104,7 @@ func init() {
 	_ = expv1.AddToScheme(scheme)
 	_ = addonsv1old.AddToScheme(scheme)
 	_ = addonsv1.AddToScheme(scheme)
+	_ = ipamv1.AddToScheme(scheme)
 	_ = apiextensionsv1.AddToScheme(scheme)
 	// +kubebuilder:scaffold:scheme
 }
@@ -128,6 +144,12 @@ func InitFlags(fs *pflag.FlagSet) {
 	fs.IntVar(&machineSetConcurrency, "machineset-concurrency", 10,
 		"Number of machine sets to process simultaneously")
 
+	fs.IntVar(&machineSetCreationBatchSize, "machineset-creation-batch-size", 0,
+		fmt.Sprintf("Maximum number of machines created at once by a machine set when scaling up, 0 means no limit. It can be overridden with the %s annotation.", clusterv1.MachineCreationBatchSizeAnnotation))
+
+	fs.DurationVar(&machineSetCreationBatchInterval, "machineset-creation-batch-interval", 10*time.Second,
+		fmt.Sprintf("Minimum interval between two batches of machine creations, jittered by up to 50%%, when creating machines in batches. It can be overridden with the %s annotation.", clusterv1.MachineCreationBatchIntervalAnnotation))
+
 	fs.IntVar(&machineDeploymentConcurrency, "machinedeployment-concurrency", 10,
 		"Number of machine deployments to process simultaneously")
 
@@ -140,6 +162,9 @@ func InitFlags(fs *pflag.FlagSet) {
 	fs.IntVar(&machineHealthCheckConcurrency, "machinehealthcheck-concurrency", 10,
 		"Number of machine health checks to process simultaneously")
 
+	fs.IntVar(&ipAddressClaimConcurrency, "ipaddressclaim-concurrency", 10,
+		"Number of ip address claims to process simultaneously")
+
 	fs.DurationVar(&syncPeriod, "sync-period", 10*time.Minute,
 		"The minimum interval at which watched resources are reconciled (e.g. 15m)")
 
@@ -149,9 +174,33 @@ func InitFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&webhookCertDir, "webhook-cert-dir", "/tmp/k8s-webhook-server/serving-certs/",
 		"Webhook cert dir, only used when webhook-port is specified.")
 
+	fs.StringVar(&unknownFieldsValidation, "unknown-fields-validation", string(webhooks.UnknownFieldsValidationIgnore),
+		fmt.Sprintf("How the Cluster API objects applied with fields not defined by their schema, which are otherwise silently dropped, are handled. Supported values: %v", webhooks.UnknownFieldsValidationModes))
+
 	fs.StringVar(&healthAddr, "health-addr", ":9440",
 		"The address the health endpoint binds to.")
 
+	fs.Float32Var(&remoteClientQPS, "remote-client-qps", 5,
+		"Maximum queries per second from the controller manager to the API server of each workload cluster.")
+
+	fs.IntVar(&remoteClientBurst, "remote-client-burst", 10,
+		"Maximum burst of queries from the controller manager to the API server of each workload cluster.")
+
+	fs.DurationVar(&remoteClientTimeout, "remote-client-timeout", 10*time.Second,
+		"Timeout of the requests to the API server of each workload cluster (duration string)")
+
+	fs.StringVar(&managementClusterName, "management-cluster-name", "",
+		"Name identifying the management cluster in the User-Agent of the requests to the workload clusters.")
+
+	fs.DurationVar(&deprecatedAPIVersionsInterval, "deprecated-api-versions-report-interval", 1*time.Hour,
+		"Interval at which the objects stored or applied using deprecated API versions are reported (duration string)")
+
+	fs.DurationVar(&controlPlaneEndpointInterval, "control-plane-endpoint-probe-interval", 1*time.Minute,
+		"Interval at which the control plane endpoint of each cluster is probed, 0 disables probing (duration string)")
+
+	fs.BoolVar(&orphanMachineCleanup, "orphan-machine-cleanup", false,
+		"Delete the machines whose Cluster does not exist anymore, together with their infrastructure and bootstrap objects. If disabled, those machines are only reported with the ClusterFound condition.")
+
 	feature.MutableGates.AddFlag(fs)
 }
 
@@ -238,7 +287,12 @@ func setupReconcilers(ctx context.Context, mgr ctrl.Manager) {
 	tracker, err := remote.NewClusterCacheTracker(
 		mgr,
 		remote.ClusterCacheTrackerOptions{
-			Log: ctrl.Log.WithName("remote").WithName("ClusterCacheTracker"),
+			Log:                   ctrl.Log.WithName("remote").WithName("ClusterCacheTracker"),
+			ClientQPS:             remoteClientQPS,
+			ClientBurst:           remoteClientBurst,
+			ClientTimeout:         remoteClientTimeout,
+			ControllerName:        "capi-controller-manager",
+			ManagementClusterName: managementClusterName,
 			Indexes: []remote.Index{
 				{
 					Object:       &corev1.Node{},
@@ -263,24 +317,29 @@ func setupReconcilers(ctx context.Context, mgr ctrl.Manager) {
 	}
 
 	if err := (&controllers.ClusterReconciler{
-		Client:           mgr.GetClient(),
-		WatchFilterValue: watchFilterValue,
+		Client:                            mgr.GetClient(),
+		WatchFilterValue:                  watchFilterValue,
+		ControlPlaneEndpointProbeInterval: controlPlaneEndpointInterval,
 	}).SetupWithManager(ctx, mgr, concurrency(clusterConcurrency)); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Cluster")
 		os.Exit(1)
 	}
 	if err := (&controllers.MachineReconciler{
-		Client:           mgr.GetClient(),
-		Tracker:          tracker,
-		WatchFilterValue: watchFilterValue,
+		Client:               mgr.GetClient(),
+		APIReader:            mgr.GetAPIReader(),
+		Tracker:              tracker,
+		WatchFilterValue:     watchFilterValue,
+		OrphanMachineCleanup: orphanMachineCleanup,
 	}).SetupWithManager(ctx, mgr, concurrency(machineConcurrency)); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Machine")
 		os.Exit(1)
 	}
 	if err := (&controllers.MachineSetReconciler{
-		Client:           mgr.GetClient(),
-		Tracker:          tracker,
-		WatchFilterValue: watchFilterValue,
+		Client:                mgr.GetClient(),
+		Tracker:               tracker,
+		WatchFilterValue:      watchFilterValue,
+		CreationBatchSize:     machineSetCreationBatchSize,
+		CreationBatchInterval: machineSetCreationBatchInterval,
 	}).SetupWithManager(ctx, mgr, concurrency(machineSetConcurrency)); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "MachineSet")
 		os.Exit(1)
@@ -321,6 +380,16 @@ func setupReconcilers(ctx context.Context, mgr ctrl.Manager) {
 		}
 	}
 
+	if feature.Gates.Enabled(feature.IPAM) {
+		if err := (&ipamcontrollers.IPAddressClaimReconciler{
+			Client:           mgr.GetClient(),
+			WatchFilterValue: watchFilterValue,
+		}).SetupWithManager(ctx, mgr, concurrency(ipAddressClaimConcurrency)); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "IPAddressClaim")
+			os.Exit(1)
+		}
+	}
+
 	if err := (&controllers.MachineHealthCheckReconciler{
 		Client:           mgr.GetClient(),
 		Tracker:          tracker,
@@ -329,6 +398,14 @@ func setupReconcilers(ctx context.Context, mgr ctrl.Manager) {
 		setupLog.Error(err, "unable to create controller", "controller", "MachineHealthCheck")
 		os.Exit(1)
 	}
+
+	if err := (&controllers.DeprecatedAPIVersionsReporter{
+		Client:   mgr.GetClient(),
+		Interval: deprecatedAPIVersionsInterval,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create reporter", "reporter", "DeprecatedAPIVersions")
+		os.Exit(1)
+	}
 }
 
 func setupWebhooks(mgr ctrl.Manager) {
@@ -379,6 +456,22 @@ func setupWebhooks(mgr ctrl.Manager) {
 		setupLog.Error(err, "unable to create webhook", "webhook", "MachineHealthCheck")
 		os.Exit(1)
 	}
+
+	mode := webhooks.UnknownFieldsValidationMode(unknownFieldsValidation)
+	if !isValidUnknownFieldsValidationMode(mode) {
+		setupLog.Error(fmt.Errorf("unsupported value %q", unknownFieldsValidation), "invalid --unknown-fields-validation flag")
+		os.Exit(1)
+	}
+	mgr.GetWebhookServer().Register(webhooks.UnknownFieldsValidatorPath, &webhook.Admission{Handler: &webhooks.UnknownFieldsValidator{Mode: mode}})
+}
+
+func isValidUnknownFieldsValidationMode(mode webhooks.UnknownFieldsValidationMode) bool {
+	for _, m := range webhooks.UnknownFieldsValidationModes {
+		if mode == m {
+			return true
+		}
+	}
+	return false
 }
 
 func concurrency(c int) controller.Options {
@@ -243,9 +243,10 @@ func Move(ctx context.Context, input MoveInput) {
 	clusterctlClient, log := getClusterctlClientWithLogger(input.ClusterctlConfigPath, "clusterctl-move.log", input.LogFolder)
 	defer log.Close()
 	options := clusterctlclient.MoveOptions{
-		FromKubeconfig: clusterctlclient.Kubeconfig{Path: input.FromKubeconfigPath, Context: ""},
-		ToKubeconfig:   clusterctlclient.Kubeconfig{Path: input.ToKubeconfigPath, Context: ""},
-		Namespace:      input.Namespace,
+		FromKubeconfig:  clusterctlclient.Kubeconfig{Path: input.FromKubeconfigPath, Context: ""},
+		ToKubeconfig:    clusterctlclient.Kubeconfig{Path: input.ToKubeconfigPath, Context: ""},
+		Namespace:       input.Namespace,
+		CreateNamespace: true,
 	}
 
 	Expect(clusterctlClient.Move(options)).To(Succeed(), "Failed to run clusterctl move")
@@ -37,6 +37,11 @@ func IsExternallyManaged(o metav1.Object) bool {
 	return hasAnnotation(o, clusterv1.ManagedByAnnotation)
 }
 
+// HasExternallyManagedEndpoint returns true if the object has the `managed-endpoint` annotation set to "false".
+func HasExternallyManagedEndpoint(o metav1.Object) bool {
+	return o.GetAnnotations()[clusterv1.ManagedEndpointAnnotation] == "false"
+}
+
 // HasPausedAnnotation returns true if the object has the `paused` annotation.
 func HasPausedAnnotation(o metav1.Object) bool {
 	return hasAnnotation(o, clusterv1.PausedAnnotation)
@@ -22,6 +22,7 @@ import (
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 )
 
 func TestAddAnnotations(t *testing.T) {
@@ -151,3 +152,44 @@ func TestAddAnnotations(t *testing.T) {
 		})
 	}
 }
+
+func TestHasExternallyManagedEndpoint(t *testing.T) {
+	var testcases = []struct {
+		name        string
+		annotations map[string]string
+		expected    bool
+	}{
+		{
+			name:        "should return false if the annotation is not set",
+			annotations: nil,
+			expected:    false,
+		},
+		{
+			name: "should return false if the annotation is set to true",
+			annotations: map[string]string{
+				clusterv1.ManagedEndpointAnnotation: "true",
+			},
+			expected: false,
+		},
+		{
+			name: "should return true if the annotation is set to false",
+			annotations: map[string]string{
+				clusterv1.ManagedEndpointAnnotation: "false",
+			},
+			expected: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			obj := &clusterv1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: tc.annotations,
+				},
+			}
+			g.Expect(HasExternallyManagedEndpoint(obj)).To(Equal(tc.expected))
+		})
+	}
+}
@@ -18,7 +18,12 @@ limitations under the License.
 package labels
 
 import (
+	"encoding/base64"
+	"fmt"
+	"hash/fnv"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 )
 
@@ -30,3 +35,19 @@ func HasWatchLabel(o metav1.Object, labelValue string) bool {
 	}
 	return val == labelValue
 }
+
+// MustFormatValue returns the passed value if it is a valid label value, e.g. an object name up to 63 characters;
+// otherwise it returns a hash of the value, so object names up to 253 characters can be used as label values.
+func MustFormatValue(str string) string {
+	if len(validation.IsValidLabelValue(str)) == 0 {
+		return str
+	}
+	hasher := fnv.New32a()
+	_, _ = hasher.Write([]byte(str))
+	return fmt.Sprintf("hash_%s_z", base64.RawURLEncoding.EncodeToString(hasher.Sum(nil)))
+}
+
+// MustEqualValue returns true if the label value matches the passed value, formatted with MustFormatValue.
+func MustEqualValue(str, labelValue string) bool {
+	return labelValue == MustFormatValue(str)
+}
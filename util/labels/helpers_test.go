@@ -17,11 +17,13 @@ limitations under the License.
 package labels
 
 import (
+	"strings"
 	"testing"
 
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 )
 
@@ -83,3 +85,16 @@ func TestHasWatchLabel(t *testing.T) {
 		})
 	}
 }
+
+func TestMustFormatValue(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(MustFormatValue("machine-1")).To(Equal("machine-1"))
+
+	long := strings.Repeat("a", 253)
+	formatted := MustFormatValue(long)
+	g.Expect(validation.IsValidLabelValue(formatted)).To(BeEmpty())
+	g.Expect(formatted).To(Equal(MustFormatValue(long)))
+	g.Expect(formatted).NotTo(Equal(MustFormatValue(strings.Repeat("b", 253))))
+	g.Expect(MustEqualValue(long, formatted)).To(BeTrue())
+}
@@ -46,9 +46,13 @@ const (
 
 	// APIServerEtcdClient is the secret name of user-supplied secret containing the apiserver-etcd-client key/cert.
 	APIServerEtcdClient Purpose = "apiserver-etcd-client"
+
+	// KubeadmCertificateKey is the secret name suffix for the key used to encrypt the control plane certificates
+	// uploaded to the kubeadm-certs Secret in the workload cluster.
+	KubeadmCertificateKey Purpose = "kubeadm-certificate-key"
 )
 
 var (
 	// allSecretPurposes defines a lists with all the secret suffix used by Cluster API.
-	allSecretPurposes = []Purpose{Kubeconfig, ClusterCA, EtcdCA, ServiceAccount, FrontProxyCA, APIServerEtcdClient}
+	allSecretPurposes = []Purpose{Kubeconfig, ClusterCA, EtcdCA, ServiceAccount, FrontProxyCA, APIServerEtcdClient, KubeadmCertificateKey}
 )
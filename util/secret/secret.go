@@ -18,11 +18,15 @@ package secret
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -48,6 +52,65 @@ func GetFromNamespacedName(ctx context.Context, c client.Reader, clusterName cli
 	return secret, nil
 }
 
+// Exists returns true if the specified Secret exists for the given cluster name and namespace.
+// Only the metadata of the Secret is retrieved, so Secrets can be checked for existence without
+// reading, or caching, their data.
+func Exists(ctx context.Context, c client.Reader, cluster client.ObjectKey, purpose Purpose) (bool, error) {
+	secret := &metav1.PartialObjectMetadata{}
+	secret.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("Secret"))
+	secretKey := client.ObjectKey{
+		Namespace: cluster.Namespace,
+		Name:      Name(cluster.Name, purpose),
+	}
+
+	if err := c.Get(ctx, secretKey, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// GetBootstrapData retrieves the bootstrap data stored in the bootstrap data secret with the given key,
+// reassembling it from its parts if the bootstrap data is split across multiple secrets.
+func GetBootstrapData(ctx context.Context, c client.Reader, key client.ObjectKey) ([]byte, error) {
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, key, secret); err != nil {
+		return nil, errors.Wrapf(err, "failed to retrieve bootstrap data secret %s", key)
+	}
+
+	parts, ok := secret.Data[clusterv1.BootstrapDataSecretPartsKey]
+	if !ok {
+		value, ok := secret.Data[clusterv1.BootstrapDataSecretValueKey]
+		if !ok {
+			return nil, errors.Errorf("bootstrap data secret %s has no %q nor %q key", key, clusterv1.BootstrapDataSecretValueKey, clusterv1.BootstrapDataSecretPartsKey)
+		}
+		return value, nil
+	}
+
+	partNames := []string{}
+	if err := json.Unmarshal(parts, &partNames); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the parts of bootstrap data secret %s", key)
+	}
+
+	data := []byte{}
+	for _, name := range partNames {
+		part := &corev1.Secret{}
+		partKey := client.ObjectKey{Namespace: key.Namespace, Name: name}
+		if err := c.Get(ctx, partKey, part); err != nil {
+			return nil, errors.Wrapf(err, "failed to retrieve part %s of bootstrap data secret %s", partKey, key)
+		}
+		value, ok := part.Data[clusterv1.BootstrapDataSecretValueKey]
+		if !ok {
+			return nil, errors.Errorf("part %s of bootstrap data secret %s has no %q key", partKey, key, clusterv1.BootstrapDataSecretValueKey)
+		}
+		data = append(data, value...)
+	}
+	return data, nil
+}
+
 // Name returns the name of the secret for a cluster.
 func Name(cluster string, suffix Purpose) string {
 	return fmt.Sprintf("%s-%s", cluster, suffix)
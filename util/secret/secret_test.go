@@ -17,9 +17,15 @@ limitations under the License.
 package secret
 
 import (
+	"context"
 	"testing"
 
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 func TestParseSecretName(t *testing.T) {
@@ -80,3 +86,83 @@ func TestParseSecretName(t *testing.T) {
 		})
 	}
 }
+
+func TestGetBootstrapData(t *testing.T) {
+	newSecret := func(name string, data map[string][]byte) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Data:       data,
+		}
+	}
+
+	tests := []struct {
+		name    string
+		objs    []client.Object
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "bootstrap data stored in a single secret",
+			objs: []client.Object{
+				newSecret("bootstrap", map[string][]byte{clusterv1.BootstrapDataSecretValueKey: []byte("data")}),
+			},
+			want: "data",
+		},
+		{
+			name: "bootstrap data split across multiple secrets",
+			objs: []client.Object{
+				newSecret("bootstrap", map[string][]byte{clusterv1.BootstrapDataSecretPartsKey: []byte(`["bootstrap-part-0","bootstrap-part-1"]`)}),
+				newSecret("bootstrap-part-0", map[string][]byte{clusterv1.BootstrapDataSecretValueKey: []byte("da")}),
+				newSecret("bootstrap-part-1", map[string][]byte{clusterv1.BootstrapDataSecretValueKey: []byte("ta")}),
+			},
+			want: "data",
+		},
+		{
+			name: "fails if a part is missing",
+			objs: []client.Object{
+				newSecret("bootstrap", map[string][]byte{clusterv1.BootstrapDataSecretPartsKey: []byte(`["bootstrap-part-0","bootstrap-part-1"]`)}),
+				newSecret("bootstrap-part-0", map[string][]byte{clusterv1.BootstrapDataSecretValueKey: []byte("da")}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "fails if the secret has no bootstrap data",
+			objs: []client.Object{
+				newSecret("bootstrap", map[string][]byte{}),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			c := fake.NewClientBuilder().WithObjects(tt.objs...).Build()
+			got, err := GetBootstrapData(context.Background(), c, client.ObjectKey{Namespace: "default", Name: "bootstrap"})
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(string(got)).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestExists(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster := client.ObjectKey{Namespace: metav1.NamespaceDefault, Name: "test"}
+	c := fake.NewClientBuilder().WithObjects(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: Name("test", Kubeconfig)},
+		Data:       map[string][]byte{"value": []byte("kubeconfig")},
+	}).Build()
+
+	exists, err := Exists(context.Background(), c, cluster, Kubeconfig)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(exists).To(BeTrue())
+
+	exists, err = Exists(context.Background(), c, cluster, ClusterCA)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(exists).To(BeFalse())
+}
@@ -442,6 +442,32 @@ func GetCRDWithContract(ctx context.Context, c client.Client, gvk schema.GroupVe
 	return nil, errors.Errorf("failed to find a CustomResourceDefinition for %v with contract %q", gvk, contract)
 }
 
+// CRDVersionHasField returns true if the schema of the given version of a CustomResourceDefinition defines the field
+// at the given path, or if it preserves unknown fields along the path; otherwise the API server prunes the field.
+func CRDVersionHasField(crd *apiextensionsv1.CustomResourceDefinition, version string, fields ...string) bool {
+	for _, v := range crd.Spec.Versions {
+		if v.Name != version {
+			continue
+		}
+		if v.Schema == nil || v.Schema.OpenAPIV3Schema == nil {
+			return false
+		}
+		props := *v.Schema.OpenAPIV3Schema
+		for _, field := range fields {
+			if props.XPreserveUnknownFields != nil && *props.XPreserveUnknownFields {
+				return true
+			}
+			p, ok := props.Properties[field]
+			if !ok {
+				return false
+			}
+			props = p
+		}
+		return true
+	}
+	return false
+}
+
 // GetCRDMetadataFromGVK retrieves a CustomResourceDefinition metadata from the API server using client-go's metadata only client.
 //
 // This function is greatly more efficient than GetCRDWithContract and should be preferred in most cases.
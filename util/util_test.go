@@ -23,11 +23,13 @@ import (
 	"github.com/blang/semver"
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -868,3 +870,74 @@ func TestRemoveOwnerRef(t *testing.T) {
 		})
 	}
 }
+
+func TestCRDVersionHasField(t *testing.T) {
+	crd := &apiextensionsv1.CustomResourceDefinition{
+		Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+			Versions: []apiextensionsv1.CustomResourceDefinitionVersion{
+				{
+					Name: "v1alpha4",
+					Schema: &apiextensionsv1.CustomResourceValidation{
+						OpenAPIV3Schema: &apiextensionsv1.JSONSchemaProps{
+							Properties: map[string]apiextensionsv1.JSONSchemaProps{
+								"spec": {
+									Properties: map[string]apiextensionsv1.JSONSchemaProps{
+										"failureDomains": {},
+									},
+								},
+								"status": {XPreserveUnknownFields: pointer.BoolPtr(true)},
+							},
+						},
+					},
+				},
+				{
+					Name: "v1alpha3",
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		version string
+		fields  []string
+		want    bool
+	}{
+		{
+			name:    "field defined in the schema",
+			version: "v1alpha4",
+			fields:  []string{"spec", "failureDomains"},
+			want:    true,
+		},
+		{
+			name:    "field not defined in the schema",
+			version: "v1alpha4",
+			fields:  []string{"spec", "deletePolicy"},
+			want:    false,
+		},
+		{
+			name:    "field preserved as unknown field",
+			version: "v1alpha4",
+			fields:  []string{"status", "failureDomains"},
+			want:    true,
+		},
+		{
+			name:    "version without schema",
+			version: "v1alpha3",
+			fields:  []string{"spec", "failureDomains"},
+			want:    false,
+		},
+		{
+			name:    "version not defined",
+			version: "v1beta1",
+			fields:  []string{"spec", "failureDomains"},
+			want:    false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(CRDVersionHasField(crd, tt.version, tt.fields...)).To(Equal(tt.want))
+		})
+	}
+}